@@ -1,15 +1,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/realtime"
 	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
 )
 
 // errBatchNotFound is returned when a batch update/delete targets a non-existent row.
@@ -54,10 +55,12 @@ func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Decode request body.
-	r.Body = http.MaxBytesReader(w, r.Body, httputil.MaxBodySize)
+	if !httputil.LimitBody(w, r) {
+		return
+	}
 	var req BatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		httputil.WriteDecodeError(w, err)
 		return
 	}
 
@@ -72,38 +75,32 @@ func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
 
 	// Validate all operations before starting the transaction.
 	for i, op := range req.Operations {
-		if err := validateBatchOp(tbl, op); err != nil {
+		if err := h.validateBatchOp(r.Context(), tbl, op); err != nil {
+			var ferr *fieldRuleError
+			if errors.As(err, &ferr) {
+				writeFieldRuleError(w, fmt.Sprintf("operation[%d]: validation failed", i), ferr)
+				return
+			}
 			writeErrorWithDoc(w, http.StatusBadRequest, fmt.Sprintf("operation[%d]: %s", i, err.Error()), docURL("/guide/api-reference#batch-operations"))
 			return
 		}
 	}
 
-	// Begin transaction with RLS context.
-	tx, err := h.pool.Begin(r.Context())
+	// Run all operations in one transaction with the RLS context set once.
+	tx, done, err := h.withTx(r.Context())
 	if err != nil {
 		h.logger.Error("batch: begin tx error", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
-	defer func() { _ = tx.Rollback(r.Context()) }()
 
-	// Set RLS session variables if JWT claims are present.
-	if claims := auth.ClaimsFromContext(r.Context()); claims != nil {
-		if err := auth.SetRLSContext(r.Context(), tx, claims); err != nil {
-			h.logger.Error("batch: rls setup error", "error", err)
-			writeError(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-	}
-
-	// Execute each operation within the transaction.
 	results := make([]BatchResult, len(req.Operations))
 	var events []*realtime.Event
 
 	for i, op := range req.Operations {
 		result, event, err := h.execBatchOp(r, tx, tbl, op)
 		if err != nil {
-			// Transaction will be rolled back by the deferred Rollback.
+			done(err)
 			if errors.Is(err, errBatchNotFound) {
 				writeError(w, http.StatusNotFound, err.Error())
 			} else if !mapPGError(w, err) {
@@ -119,36 +116,30 @@ func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Commit the transaction.
-	if err := tx.Commit(r.Context()); err != nil {
-		h.logger.Error("batch: commit error", "error", err)
-		writeError(w, http.StatusInternalServerError, "internal error")
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
 		return
 	}
 
 	// Publish events after successful commit.
 	for _, event := range events {
-		if h.hub != nil {
-			h.hub.Publish(event)
-		}
-		if h.dispatcher != nil {
-			h.dispatcher.Enqueue(event)
-		}
+		event.Tenant = tenant.FromContext(r.Context())
+		h.publish(event)
 	}
 
 	writeJSON(w, http.StatusOK, results)
 }
 
 // validateBatchOp validates a single batch operation before execution.
-func validateBatchOp(tbl *schema.Table, op BatchOperation) error {
+func (h *Handler) validateBatchOp(ctx context.Context, tbl *schema.Table, op BatchOperation) error {
 	switch op.Method {
 	case "create":
 		if len(op.Body) == 0 {
 			return fmt.Errorf("create requires a body")
 		}
-		if countKnownColumns(tbl, op.Body) == 0 {
-			return fmt.Errorf("no recognized columns in body")
-		}
+		return h.prepareBatchBody(ctx, tbl, op.Body, false)
 	case "update":
 		if op.ID == "" {
 			return fmt.Errorf("update requires an id")
@@ -156,9 +147,7 @@ func validateBatchOp(tbl *schema.Table, op BatchOperation) error {
 		if len(op.Body) == 0 {
 			return fmt.Errorf("update requires a body")
 		}
-		if countKnownColumns(tbl, op.Body) == 0 {
-			return fmt.Errorf("no recognized columns in body")
-		}
+		return h.prepareBatchBody(ctx, tbl, op.Body, true)
 	case "delete":
 		if op.ID == "" {
 			return fmt.Errorf("delete requires an id")
@@ -169,18 +158,33 @@ func validateBatchOp(tbl *schema.Table, op BatchOperation) error {
 	return nil
 }
 
+// prepareBatchBody validates a create or update body as for a
+// single-record write: managed values are dropped, and the rest is coerced
+// and checked against the field rules.
+func (h *Handler) prepareBatchBody(ctx context.Context, tbl *schema.Table, body map[string]any, update bool) error {
+	h.dropManagedValues(ctx, tbl, body)
+	if countKnownColumns(tbl, body) == 0 {
+		return fmt.Errorf("no recognized columns in body")
+	}
+	if err := prepareRecord(tbl, body, update); err != nil {
+		return err
+	}
+	if ferr := h.checkFieldRules(tbl, body); ferr != nil {
+		return ferr
+	}
+	return nil
+}
+
 // execBatchOp executes a single batch operation within a transaction.
 // Returns the result, an optional event for publish, and any error.
 func (h *Handler) execBatchOp(r *http.Request, q Querier, tbl *schema.Table, op BatchOperation) (BatchResult, *realtime.Event, error) {
 	switch op.Method {
 	case "create":
-		query, args := buildInsert(tbl, op.Body)
-		rows, err := q.Query(r.Context(), query, args...)
-		if err != nil {
+		h.setServerValues(r.Context(), tbl, op.Body, false)
+		if err := h.checkReferences(r.Context(), q, tbl, op.Body); err != nil {
 			return BatchResult{}, nil, err
 		}
-		record, err := scanRow(rows)
-		rows.Close()
+		record, err := h.insertRecord(r.Context(), q, tbl, op.Body)
 		if err != nil {
 			return BatchResult{}, nil, err
 		}
@@ -192,13 +196,11 @@ func (h *Handler) execBatchOp(r *http.Request, q Querier, tbl *schema.Table, op
 		if len(pkValues) != len(tbl.PrimaryKey) {
 			return BatchResult{}, nil, fmt.Errorf("invalid primary key for update")
 		}
-		query, args := buildUpdate(tbl, op.Body, pkValues)
-		rows, err := q.Query(r.Context(), query, args...)
-		if err != nil {
+		h.setServerValues(r.Context(), tbl, op.Body, true)
+		if err := h.checkReferences(r.Context(), q, tbl, op.Body); err != nil {
 			return BatchResult{}, nil, err
 		}
-		record, err := scanRow(rows)
-		rows.Close()
+		record, err := h.updateRecord(r.Context(), q, tbl, op.Body, pkValues)
 		if err != nil {
 			return BatchResult{}, nil, err
 		}
@@ -213,18 +215,13 @@ func (h *Handler) execBatchOp(r *http.Request, q Querier, tbl *schema.Table, op
 		if len(pkValues) != len(tbl.PrimaryKey) {
 			return BatchResult{}, nil, fmt.Errorf("invalid primary key for delete")
 		}
-		query, args := buildDelete(tbl, pkValues)
-		tag, err := q.Exec(r.Context(), query, args...)
+		record, err := h.deleteRecord(r.Context(), q, tbl, pkValues)
 		if err != nil {
 			return BatchResult{}, nil, err
 		}
-		if tag.RowsAffected() == 0 {
+		if record == nil {
 			return BatchResult{}, nil, fmt.Errorf("%w: %s", errBatchNotFound, op.ID)
 		}
-		record := make(map[string]any, len(tbl.PrimaryKey))
-		for i, pk := range tbl.PrimaryKey {
-			record[pk] = pkValues[i]
-		}
 		event := &realtime.Event{Action: "delete", Table: tbl.Name, Record: record}
 		return BatchResult{Status: http.StatusNoContent}, event, nil
 
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/allyourbase/ayb/internal/testutil"
@@ -80,6 +84,16 @@ func TestBatchCreateNoRecognizedColumns(t *testing.T) {
 	testutil.Contains(t, resp.Message, "no recognized columns")
 }
 
+func TestBatchCreateInvalidColumnValue(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+	body := `{"operations":[{"method":"create","body":{"email":"a@example.com"}},{"method":"create","body":{"id":"nope"}}]}`
+	w := doRequest(h, "POST", "/collections/users/batch", body)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	resp := decodeError(t, w)
+	testutil.Contains(t, resp.Message, "operation[1]: invalid value for column id")
+}
+
 // --- Validation: update ---
 
 func TestBatchUpdateMissingID(t *testing.T) {
@@ -257,7 +271,7 @@ func TestValidateBatchOp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			err := validateBatchOp(tbl, tt.op)
+			err := (&Handler{}).validateBatchOp(context.Background(), tbl, tt.op)
 			if tt.wantErr == "" {
 				testutil.NoError(t, err)
 			} else {
@@ -285,3 +299,29 @@ func TestBatchErrorIncludesIndex(t *testing.T) {
 
 // maxBatchSize enforcement is covered by TestBatchTooManyOperations
 // and TestBatchExactlyMaxBatchSizePassesSizeCheck.
+
+func TestBatchReferenceCheckSeesManagedValues(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	h.SetCheckReferences(true)
+	h.SetManagedFields(map[string]map[string]ManagedField{"posts": {
+		"author_id": {From: ManagedFromUserID, OnWrite: true},
+	}})
+	r := httptest.NewRequest(http.MethodPost, "/api/collections/posts/batch", nil).WithContext(userContext())
+
+	for _, op := range []BatchOperation{
+		{Method: "create", Body: map[string]any{"id": int64(1)}},
+		{Method: "update", ID: "1", Body: map[string]any{"id": int64(1)}},
+	} {
+		t.Run(op.Method, func(t *testing.T) {
+			// The server-set author_id is what gets checked.
+			q := &refQuerier{exists: false}
+			_, _, err := h.execBatchOp(r, q, referencingTable(), op)
+			var refErr *referenceError
+			testutil.True(t, errors.As(err, &refErr), "expected referenceError, got %v", err)
+			testutil.Equal(t, "author_id", refErr.field)
+			testutil.SliceLen(t, q.args, 1)
+			testutil.Equal(t, "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91", q.args[0][0].(string))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// BulkResponse is the body returned by filtered bulk updates and deletes.
+type BulkResponse struct {
+	Affected int `json:"affected"`
+}
+
+// handleBulkUpdate handles PATCH /collections/{table}?filter=...
+func (h *Handler) handleBulkUpdate(w http.ResponseWriter, r *http.Request) {
+	tbl := h.resolveTable(w, r)
+	if tbl == nil {
+		return
+	}
+	if !requireWriteScope(w, r) {
+		return
+	}
+	if !requireWritable(w, tbl) {
+		return
+	}
+	if !requirePK(w, tbl) {
+		return
+	}
+
+	if !h.requireNoHooks(w, tbl, HookUpdate) {
+		return
+	}
+
+	filter, ok := bulkFilter(w, r, tbl)
+	if !ok {
+		return
+	}
+
+	data, ok := h.decodeAndValidateBody(w, r, tbl, true)
+	if !ok {
+		return
+	}
+	h.setServerValues(r.Context(), tbl, data, true)
+
+	q, done, err := h.withWrite(r.Context(), tbl)
+	if err != nil {
+		h.logger.Error("rls setup error", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if err := h.checkReferences(r.Context(), q, tbl, data); err != nil {
+		done(err)
+		if !mapPGError(w, err) {
+			h.logger.Error("reference check error", "error", err, "table", tbl.Name)
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	query, args := buildBulkUpdate(tbl, data, filter)
+	records, err := execBulk(r.Context(), q, query, args)
+	if err != nil {
+		done(err)
+		if !mapPGError(w, err) {
+			h.logger.Error("bulk update error", "error", err, "table", tbl.Name)
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, BulkResponse{Affected: len(records)})
+	for _, record := range records {
+		h.publishEvent(r.Context(), "update", tbl.Name, record)
+	}
+}
+
+// handleBulkDelete handles DELETE /collections/{table}?filter=...
+func (h *Handler) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
+	tbl := h.resolveTable(w, r)
+	if tbl == nil {
+		return
+	}
+	if !requireWriteScope(w, r) {
+		return
+	}
+	if !requireWritable(w, tbl) {
+		return
+	}
+	if !requirePK(w, tbl) {
+		return
+	}
+
+	if !h.requireNoHooks(w, tbl, HookDelete) {
+		return
+	}
+
+	filter, ok := bulkFilter(w, r, tbl)
+	if !ok {
+		return
+	}
+
+	q, done, err := h.withWrite(r.Context(), tbl)
+	if err != nil {
+		h.logger.Error("rls setup error", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	query, args := buildBulkDelete(tbl, filter)
+	records, err := execBulk(r.Context(), q, query, args)
+	if err != nil {
+		done(err)
+		if !mapPGError(w, err) {
+			h.logger.Error("bulk delete error", "error", err, "table", tbl.Name)
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, BulkResponse{Affected: len(records)})
+	for _, record := range records {
+		h.publishEvent(r.Context(), "delete", tbl.Name, record)
+	}
+}
+
+// requireNoHooks writes a 409 response if tbl has hooks for action, which
+// run per record and so can't be applied to a filtered bulk write.
+func (h *Handler) requireNoHooks(w http.ResponseWriter, tbl *schema.Table, action string) bool {
+	if h.hooks.has(tbl.Name, action) {
+		writeErrorWithDoc(w, http.StatusConflict, "bulk "+action+" is not available on a table with "+action+" hooks",
+			docURL("/guide/api-reference#bulk-update-and-delete"))
+		return false
+	}
+	return true
+}
+
+// bulkFilter compiles the filter of a bulk write, writing a 400 response if
+// it is invalid. An empty filter would match every row, so it is only
+// accepted together with ?all=true.
+func bulkFilter(w http.ResponseWriter, r *http.Request, tbl *schema.Table) (compiledFilter, bool) {
+	q := r.URL.Query()
+	f, perr := compileFilterParam(tbl, q.Get("filter"))
+	if perr != nil {
+		writeErrorWithDoc(w, http.StatusBadRequest, perr.message, docURL(perr.docPath))
+		return compiledFilter{}, false
+	}
+	if f.sql == "" && q.Get("all") != "true" {
+		writeErrorWithDoc(w, http.StatusBadRequest, "filter is required; use all=true to write every row",
+			docURL("/guide/api-reference#bulk-update-and-delete"))
+		return compiledFilter{}, false
+	}
+	return f, true
+}
+
+// buildBulkUpdate builds an UPDATE of every row matching filter, returning
+// the updated rows. The filter's parameters come first, then the SET
+// values, so the filter SQL is used as compiled.
+func buildBulkUpdate(tbl *schema.Table, data map[string]any, filter compiledFilter) (string, []any) {
+	setClauses, setArgs, i, expected := buildSet(tbl, data, len(filter.args)+1)
+	args := append(append([]any{}, filter.args...), setArgs...)
+
+	var whereParts []string
+	if filter.sql != "" {
+		whereParts = append(whereParts, filter.sql)
+	}
+	if expected != nil {
+		whereParts = append(whereParts, fmt.Sprintf("%s = $%d", quoteIdent(versionColumnName), i))
+		args = append(args, expected)
+	}
+
+	return fmt.Sprintf("UPDATE %s SET %s%s RETURNING %s",
+		tableRef(tbl),
+		strings.Join(setClauses, ", "),
+		whereClause(whereParts),
+		buildColumnList(tbl, nil),
+	), args
+}
+
+// buildBulkDelete builds a DELETE of every row matching filter, returning
+// the primary keys of the deleted rows.
+func buildBulkDelete(tbl *schema.Table, filter compiledFilter) (string, []any) {
+	var whereParts []string
+	if filter.sql != "" {
+		whereParts = append(whereParts, filter.sql)
+	}
+	return fmt.Sprintf("DELETE FROM %s%s RETURNING %s",
+		tableRef(tbl), whereClause(whereParts), buildColumnList(tbl, tbl.PrimaryKey)), filter.args
+}
+
+// execBulk runs a bulk write with a RETURNING clause and returns the rows
+// it wrote.
+func execBulk(ctx context.Context, q Querier, query string, args []any) ([]map[string]any, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRows(rows)
+	rows.Close() // Close before the caller commits to avoid pgx "conn busy".
+	return records, err
+}
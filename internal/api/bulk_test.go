@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestBuildBulkDelete(t *testing.T) {
+	t.Parallel()
+	tbl := testTable()
+
+	f, err := compileFilter(tbl, "age>30 || name='x'")
+	testutil.NoError(t, err)
+	q, args := buildBulkDelete(tbl, f)
+	testutil.Equal(t, `DELETE FROM "public"."users" WHERE ("age" > $1 OR "name" = $2) RETURNING "id"`, q)
+	testutil.SliceLen(t, args, 2)
+
+	q, args = buildBulkDelete(tbl, compiledFilter{})
+	testutil.Equal(t, `DELETE FROM "public"."users" RETURNING "id"`, q)
+	testutil.SliceLen(t, args, 0)
+}
+
+func TestBuildBulkUpdate(t *testing.T) {
+	t.Parallel()
+	tbl := testTable()
+
+	f, err := compileFilter(tbl, "age>30")
+	testutil.NoError(t, err)
+	q, args := buildBulkUpdate(tbl, map[string]any{"name": "Bob"}, f)
+	// Filter parameters come first, so the compiled filter is used as is.
+	testutil.Equal(t, `UPDATE "public"."users" SET "name" = $2 WHERE "age" > $1 RETURNING *`, q)
+	testutil.SliceLen(t, args, 2)
+	testutil.Equal(t, "Bob", args[1].(string))
+}
+
+func TestBuildBulkUpdateVersioned(t *testing.T) {
+	t.Parallel()
+	tbl := versionedTable()
+
+	f, err := compileFilter(tbl, "body='a'")
+	testutil.NoError(t, err)
+	q, args := buildBulkUpdate(tbl, map[string]any{"body": "b", "version": int64(2)}, f)
+	testutil.Equal(t, `UPDATE "public"."notes" SET "body" = $2, "version" = "version" + 1 WHERE "body" = $1 AND "version" = $3 RETURNING *`, q)
+	testutil.SliceLen(t, args, 3)
+	testutil.Equal(t, int64(2), args[2].(int64))
+}
+
+func TestBulkWriteRequiresFilter(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+
+	for _, method := range []string{"DELETE", "PATCH"} {
+		w := doRequest(h, method, "/collections/users", `{"name":"x"}`)
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, decodeError(t, w).Message, "filter is required")
+
+		// A blank filter matches everything, so it needs all=true too.
+		w = doRequest(h, method, "/collections/users?filter=%20", `{"name":"x"}`)
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, decodeError(t, w).Message, "filter is required")
+
+		w = doRequest(h, method, "/collections/users?filter=nosuchcol%3D1", `{"name":"x"}`)
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, decodeError(t, w).Message, "invalid filter")
+	}
+}
+
+func TestBulkWriteOnViewNotAllowed(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+
+	w := doRequest(h, "DELETE", "/collections/logs?all=true", "")
+	testutil.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/google/uuid"
+)
+
+// coerceError reports a value that can't be converted to its column's type.
+type coerceError struct {
+	column  string
+	message string
+}
+
+func (e *coerceError) Error() string {
+	return fmt.Sprintf("invalid value for column %s: %s", e.column, e.message)
+}
+
+// timestampLayouts are the ISO 8601 forms accepted for date and timestamp
+// columns, tried in order.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// baseTypeName returns the lowercased column type with modifiers such as
+// (255) or (3) removed, e.g. "timestamp(3) with time zone" becomes
+// "timestamp with time zone".
+func baseTypeName(col *schema.Column) string {
+	base := strings.ToLower(col.TypeName)
+	for {
+		open := strings.Index(base, "(")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(base[open:], ")")
+		if end < 0 {
+			break
+		}
+		base = base[:open] + base[open+end+1:]
+	}
+	return strings.Join(strings.Fields(base), " ")
+}
+
+// coerceValue converts a filter or request body value to the Go type pgx
+// expects for col, so a bad value is reported against the column instead of
+// failing as a driver or database error. Booleans, integers, floats, numerics,
+// dates, timestamps, UUIDs, text and PostGIS geometries are handled; other
+// types, arrays, enums and JSON pass through unchanged. nil is always passed
+// through as NULL.
+func coerceValue(col *schema.Column, v any) (any, error) {
+	if v == nil || col.IsJSON || col.IsArray || col.IsEnum {
+		return v, nil
+	}
+	fail := func(kind string) (any, error) {
+		return nil, &coerceError{column: col.Name, message: fmt.Sprintf("%s is not a valid %s", describeValue(v), kind)}
+	}
+
+	if col.IsGeometry {
+		return coerceGeoJSON(col, v)
+	}
+
+	if isTextColumn(col) {
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case bool:
+			return strconv.FormatBool(x), nil
+		case int64:
+			return strconv.FormatInt(x, 10), nil
+		case float64:
+			return strconv.FormatFloat(x, 'f', -1, 64), nil
+		}
+		return fail("text value")
+	}
+
+	switch base := baseTypeName(col); col.JSONType {
+	case "boolean":
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(x)); err == nil {
+				return b, nil
+			}
+		}
+		return fail("boolean")
+
+	case "integer":
+		switch x := v.(type) {
+		case int64:
+			return x, nil
+		case float64:
+			if x == math.Trunc(x) && x >= math.MinInt64 && x < math.MaxInt64 {
+				return int64(x), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+		return fail("integer")
+
+	case "number":
+		if base == "money" {
+			return v, nil // accepts currency-formatted strings
+		}
+		switch x := v.(type) {
+		case int64, float64:
+			return x, nil
+		case string:
+			// Numeric strings are passed through so arbitrary-precision
+			// values aren't rounded to float64.
+			if _, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+				return strings.TrimSpace(x), nil
+			}
+		}
+		return fail("number")
+
+	default:
+		switch base {
+		case "date", "timestamp", "timestamp without time zone", "timestamptz", "timestamp with time zone":
+			if s, ok := v.(string); ok && validTimestamp(s) {
+				// PostgreSQL parses the string itself, keeping its own
+				// time zone rules for timestamp vs timestamptz.
+				return strings.TrimSpace(s), nil
+			}
+			return fail("ISO 8601 date or timestamp")
+		case "uuid":
+			if s, ok := v.(string); ok {
+				if _, err := uuid.Parse(strings.TrimSpace(s)); err == nil {
+					return strings.TrimSpace(s), nil
+				}
+			}
+			return fail("UUID")
+		}
+	}
+	return v, nil
+}
+
+// coerceGeoJSON accepts a GeoJSON geometry as an object or as JSON text and
+// returns it as text for ST_GeomFromGeoJSON.
+func coerceGeoJSON(col *schema.Column, v any) (any, error) {
+	var obj map[string]any
+	switch x := v.(type) {
+	case map[string]any:
+		obj = x
+	case string:
+		if err := json.Unmarshal([]byte(x), &obj); err != nil {
+			obj = nil
+		}
+	}
+	if typ, _ := obj["type"].(string); typ == "" {
+		return nil, &coerceError{column: col.Name, message: "expected a GeoJSON geometry object"}
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, &coerceError{column: col.Name, message: "expected a GeoJSON geometry object"}
+	}
+	return string(b), nil
+}
+
+// validTimestamp reports whether s is an ISO 8601 date or timestamp, or one
+// of PostgreSQL's infinity values.
+func validTimestamp(s string) bool {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "infinity") || strings.EqualFold(s, "-infinity") {
+		return true
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// describeValue renders v for an error message.
+func describeValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// coerceRecord coerces every known column in data in place. Unknown columns
+// are left alone; the query builders skip them.
+func coerceRecord(tbl *schema.Table, data map[string]any) error {
+	for name, v := range data {
+		col := tbl.ColumnByName(name)
+		if col == nil {
+			continue
+		}
+		cv, err := coerceValue(col, v)
+		if err != nil {
+			return err
+		}
+		data[name] = cv
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func coerceTestTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "events",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", TypeName: "uuid", JSONType: "string", IsPrimaryKey: true},
+			{Name: "title", TypeName: "character varying(200)", JSONType: "string"},
+			{Name: "seats", TypeName: "integer", JSONType: "integer"},
+			{Name: "price", TypeName: "numeric(10,2)", JSONType: "number"},
+			{Name: "ratio", TypeName: "double precision", JSONType: "number"},
+			{Name: "public", TypeName: "boolean", JSONType: "boolean"},
+			{Name: "starts_at", TypeName: "timestamp(3) with time zone", JSONType: "string"},
+			{Name: "day", TypeName: "date", JSONType: "string"},
+			{Name: "meta", TypeName: "jsonb", JSONType: "object", IsJSON: true},
+			{Name: "tags", TypeName: "text[]", JSONType: "array", IsArray: true},
+			{Name: "addr", TypeName: "inet", JSONType: "string"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestBaseTypeName(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"integer":                     "integer",
+		"character varying(255)":      "character varying",
+		"numeric(10,2)":               "numeric",
+		"timestamp(3) with time zone": "timestamp with time zone",
+		"TIMESTAMP WITHOUT TIME ZONE": "timestamp without time zone",
+	}
+	for in, want := range tests {
+		testutil.Equal(t, want, baseTypeName(&schema.Column{TypeName: in}))
+	}
+}
+
+func TestCoerceValue(t *testing.T) {
+	t.Parallel()
+	tbl := coerceTestTable()
+	tests := []struct {
+		column string
+		in     any
+		want   any
+	}{
+		{"seats", float64(12), int64(12)}, // JSON numbers decode as float64
+		{"seats", "12", int64(12)},
+		{"seats", int64(7), int64(7)},
+		{"price", "19.99", "19.99"}, // kept as text to preserve precision
+		{"price", float64(19.5), float64(19.5)},
+		{"ratio", int64(2), int64(2)},
+		{"public", true, true},
+		{"public", "false", false},
+		{"public", "t", true},
+		{"starts_at", "2026-03-01T09:30:00Z", "2026-03-01T09:30:00Z"},
+		{"starts_at", "2026-03-01T09:30:00.123+02:00", "2026-03-01T09:30:00.123+02:00"},
+		{"starts_at", "2026-03-01 09:30:00", "2026-03-01 09:30:00"},
+		{"starts_at", "infinity", "infinity"},
+		{"day", "2026-03-01", "2026-03-01"},
+		{"id", "8f14e45f-ceea-4e5a-9a3b-1c2d3e4f5a6b", "8f14e45f-ceea-4e5a-9a3b-1c2d3e4f5a6b"},
+		{"title", "hello", "hello"},
+		{"title", int64(42), "42"},
+		{"title", float64(1.5), "1.5"},
+		{"title", true, "true"},
+		{"seats", nil, nil},
+		{"addr", "10.0.0.1", "10.0.0.1"}, // unhandled types pass through
+	}
+	for _, tt := range tests {
+		got, err := coerceValue(tbl.ColumnByName(tt.column), tt.in)
+		testutil.NoError(t, err)
+		testutil.Equal(t, tt.want, got)
+	}
+
+	// JSON and array columns pass through untouched.
+	meta := map[string]any{"k": "v"}
+	got, err := coerceValue(tbl.ColumnByName("meta"), meta)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "v", got.(map[string]any)["k"])
+	tags := []any{"a", "b"}
+	got, err = coerceValue(tbl.ColumnByName("tags"), tags)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, got.([]any), 2)
+}
+
+func TestCoerceValueErrors(t *testing.T) {
+	t.Parallel()
+	tbl := coerceTestTable()
+	tests := []struct {
+		column string
+		in     any
+		msg    string
+	}{
+		{"seats", "abc", `"abc" is not a valid integer`},
+		{"seats", float64(1.5), "1.5 is not a valid integer"},
+		{"seats", true, "true is not a valid integer"},
+		{"price", "ten", `"ten" is not a valid number`},
+		{"price", false, "is not a valid number"},
+		{"public", "yes please", "is not a valid boolean"},
+		{"public", int64(1), "is not a valid boolean"},
+		{"starts_at", "next tuesday", "is not a valid ISO 8601 date or timestamp"},
+		{"starts_at", float64(1700000000), "is not a valid ISO 8601 date or timestamp"},
+		{"day", "03/01/2026", "is not a valid ISO 8601 date or timestamp"},
+		{"id", "not-a-uuid", "is not a valid UUID"},
+		{"id", int64(5), "is not a valid UUID"},
+		{"title", map[string]any{}, "is not a valid text value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			t.Parallel()
+			_, err := coerceValue(tbl.ColumnByName(tt.column), tt.in)
+			testutil.ErrorContains(t, err, "invalid value for column "+tt.column)
+			testutil.ErrorContains(t, err, tt.msg)
+		})
+	}
+}
+
+func TestCoerceRecord(t *testing.T) {
+	t.Parallel()
+	tbl := coerceTestTable()
+
+	data := map[string]any{"seats": float64(3), "public": "true", "unknown": "kept"}
+	testutil.NoError(t, coerceRecord(tbl, data))
+	testutil.Equal(t, int64(3), data["seats"].(int64))
+	testutil.Equal(t, true, data["public"].(bool))
+	testutil.Equal(t, "kept", data["unknown"].(string))
+
+	err := coerceRecord(tbl, map[string]any{"seats": "many"})
+	testutil.ErrorContains(t, err, "invalid value for column seats")
+}
+
+func TestParseFilterCoercesValues(t *testing.T) {
+	t.Parallel()
+	tbl := coerceTestTable()
+
+	_, args, err := parseFilter(tbl, "seats='12' AND public='true'")
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(12), args[0].(int64))
+	testutil.Equal(t, true, args[1].(bool))
+
+	_, args, err = parseFilter(tbl, "seats in ('1', 2) and day between '2026-01-01' and '2026-12-31'")
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(1), args[0].(int64))
+	testutil.Equal(t, int64(2), args[1].(int64))
+	testutil.Equal(t, "2026-01-01", args[2].(string))
+
+	// LIKE patterns are text regardless of the column type.
+	_, args, err = parseFilter(tbl, "seats ~ '1%'")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "1%", args[0].(string))
+
+	for input, msg := range map[string]string{
+		"seats='abc'":                   `"abc" is not a valid integer`,
+		"seats nin (1, 'x')":            `"x" is not a valid integer`,
+		"starts_at between 'a' and 'b'": "ISO 8601",
+		"id='123'":                      "UUID",
+	} {
+		_, _, err := parseFilter(tbl, input)
+		testutil.ErrorContains(t, err, msg)
+	}
+}
+
+func TestCoerceGeoJSON(t *testing.T) {
+	t.Parallel()
+	col := &schema.Column{Name: "location", TypeName: "geometry(Point,4326)", JSONType: "object", IsGeometry: true}
+
+	got, err := coerceValue(col, map[string]any{"type": "Point", "coordinates": []any{-122.4, 37.8}})
+	testutil.NoError(t, err)
+	testutil.Equal(t, `{"coordinates":[-122.4,37.8],"type":"Point"}`, got.(string))
+
+	got, err = coerceValue(col, `{"type":"Point","coordinates":[1,2]}`)
+	testutil.NoError(t, err)
+	testutil.Equal(t, `{"coordinates":[1,2],"type":"Point"}`, got.(string))
+
+	for _, bad := range []any{"POINT(1 2)", map[string]any{"coordinates": []any{1, 2}}, int64(3)} {
+		_, err := coerceValue(col, bad)
+		testutil.ErrorContains(t, err, "expected a GeoJSON geometry object")
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// CollectionStats is the estimated size of a collection, for capacity
+// planning. Partitioned tables report the sum of their partitions.
+type CollectionStats struct {
+	Schema        string     `json:"schema"`
+	Table         string     `json:"table"`
+	Kind          string     `json:"kind"`
+	EstimatedRows *int64     `json:"estimatedRows"` // pg_class.reltuples; null until first analyzed
+	TotalBytes    int64      `json:"totalBytes"`    // table, TOAST and indexes
+	TableBytes    int64      `json:"tableBytes"`    // table and TOAST
+	IndexBytes    int64      `json:"indexBytes"`
+	LastAnalyzed  *time.Time `json:"lastAnalyzed"` // latest manual or autovacuum ANALYZE
+}
+
+// collectionStatsQuery reads planner estimates and on-disk sizes rather than
+// counting rows, so it stays fast on large tables. $1 and $2 are parallel
+// arrays of schema and table names.
+const collectionStatsQuery = `
+SELECT t.schema, t.name,
+       sum(c.reltuples) FILTER (WHERE c.reltuples >= 0)::bigint,
+       COALESCE(sum(pg_total_relation_size(c.oid)), 0)::bigint,
+       COALESCE(sum(pg_indexes_size(c.oid)), 0)::bigint,
+       max(GREATEST(s.last_analyze, s.last_autoanalyze))
+FROM unnest($1::text[], $2::text[]) AS t(schema, name)
+JOIN pg_class r ON r.oid = to_regclass(quote_ident(t.schema) || '.' || quote_ident(t.name))
+JOIN pg_class c ON c.oid = r.oid
+	OR (r.relkind = 'p' AND c.oid IN (SELECT relid FROM pg_partition_tree(r.oid) WHERE isleaf))
+LEFT JOIN pg_stat_all_tables s ON s.relid = c.oid
+GROUP BY t.schema, t.name`
+
+// CollectionStats returns size estimates for the tables, partitioned tables
+// and materialized views in the schema cache, largest first. Views are
+// skipped since they store nothing.
+func (h *Handler) CollectionStats(ctx context.Context) ([]CollectionStats, error) {
+	sc := h.schema.Get()
+	if sc == nil {
+		return []CollectionStats{}, nil
+	}
+	var schemas, names []string
+	kinds := make(map[string]string)
+	for key, tbl := range sc.Tables {
+		if tbl.Kind == "view" {
+			continue
+		}
+		schemas = append(schemas, tbl.Schema)
+		names = append(names, tbl.Name)
+		kinds[key] = tbl.Kind
+	}
+
+	rows, err := h.pool.Query(ctx, collectionStatsQuery, schemas, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []CollectionStats{}
+	for rows.Next() {
+		var s CollectionStats
+		var totalBytes, indexBytes int64
+		if err := rows.Scan(&s.Schema, &s.Table, &s.EstimatedRows, &totalBytes, &indexBytes, &s.LastAnalyzed); err != nil {
+			return nil, err
+		}
+		s.Kind = kinds[s.Schema+"."+s.Table]
+		s.TotalBytes, s.IndexBytes, s.TableBytes = totalBytes, indexBytes, totalBytes-indexBytes
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(stats, func(a, b CollectionStats) int {
+		return cmp.Or(
+			cmp.Compare(b.TotalBytes, a.TotalBytes),
+			cmp.Compare(a.Schema, b.Schema),
+			cmp.Compare(a.Table, b.Table),
+		)
+	})
+	return stats, nil
+}
+
+// HandleCollectionStats handles GET /admin/collections/stats.
+func (h *Handler) HandleCollectionStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.CollectionStats(r.Context())
+	if err != nil {
+		h.logger.Error("collection stats error", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": stats,
+		"count": len(stats),
+	})
+}
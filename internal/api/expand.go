@@ -8,6 +8,7 @@ import (
 
 	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
 )
 
 const maxExpandDepth = 2
@@ -77,6 +78,7 @@ func expandRelation(ctx context.Context, pool Querier, sc *schema.SchemaCache, t
 	if relTable == nil {
 		return
 	}
+	relTable = tenant.Table(ctx, relTable)
 
 	// Check API key table restrictions for the related table.
 	if err := auth.CheckTableScope(claims, relTable.Name); err != nil {
@@ -108,7 +110,7 @@ func collectUniqueValues(records []map[string]any, col string) []any {
 	return values
 }
 
-// fetchRelated runs a batch SELECT * FROM relTable WHERE targetCol IN (...values).
+// fetchRelated runs a batch SELECT FROM relTable WHERE targetCol IN (...values).
 // Returns the matching rows, or nil on error (errors are logged, not returned).
 func fetchRelated(ctx context.Context, pool Querier, relTable *schema.Table, targetCol string, values []any, logger *slog.Logger, relName string) []map[string]any {
 	placeholders := make([]string, len(values))
@@ -116,7 +118,8 @@ func fetchRelated(ctx context.Context, pool Querier, relTable *schema.Table, tar
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s IN (%s)",
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s)",
+		buildColumnList(relTable, nil),
 		tableRef(relTable),
 		quoteIdent(targetCol),
 		strings.Join(placeholders, ", "),
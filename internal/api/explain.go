@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// ExplainResponse is the query plan of a list request.
+type ExplainResponse struct {
+	Query string          `json:"query"`
+	Plan  json.RawMessage `json:"plan"` // EXPLAIN (FORMAT JSON) output
+}
+
+// HandleExplain handles GET /admin/collections/{table}/explain. It accepts the
+// query parameters of GET /collections/{table} and returns the plan of the
+// page query that list request would run, without running it. With
+// analyze=true the query is executed (EXPLAIN ANALYZE) in a transaction that
+// is rolled back, so the plan includes actual row counts and timings.
+//
+// The caller is expected to be an admin: the plan is for the database role
+// itself, with no RLS context set.
+func (h *Handler) HandleExplain(w http.ResponseWriter, r *http.Request) {
+	tbl := h.resolveTable(w, r)
+	if tbl == nil {
+		return
+	}
+	opts, ok := h.listOptsFromRequest(w, r, tbl)
+	if !ok {
+		return
+	}
+
+	query, args, _, _ := buildList(tbl, opts)
+	plan, err := h.explain(r.Context(), query, args, r.URL.Query().Get("analyze") == "true")
+	if err != nil {
+		h.writeListError(w, tbl, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ExplainResponse{Query: query, Plan: plan})
+}
+
+// explain returns the JSON plan of query.
+func (h *Handler) explain(ctx context.Context, query string, args []any, analyze bool) (json.RawMessage, error) {
+	var plan []byte
+	if !analyze {
+		err := h.pool.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&plan)
+		return plan, err
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+	if err := setStatementTimeout(ctx, tx); err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&plan)
+	return plan, err
+}
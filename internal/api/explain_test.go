@@ -0,0 +1,33 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/go-chi/chi/v5"
+)
+
+func testExplainHandler() http.Handler {
+	h := NewHandler(nil, testCacheHolder(testSchema()), slog.Default(), nil, nil)
+	r := chi.NewRouter()
+	r.Get("/admin/collections/{table}/explain", h.HandleExplain)
+	return r
+}
+
+func TestExplainCollectionNotFound(t *testing.T) {
+	t.Parallel()
+	w := doRequest(testExplainHandler(), "GET", "/admin/collections/nonexistent/explain", "")
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "collection not found")
+}
+
+func TestExplainInvalidFilter(t *testing.T) {
+	t.Parallel()
+	w := doRequest(testExplainHandler(), "GET", "/admin/collections/users/explain?filter=((broken", "")
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	resp := decodeError(t, w)
+	testutil.Contains(t, resp.Message, "invalid filter")
+	testutil.Contains(t, resp.DocURL, "/guide/api-reference#filter-syntax")
+}
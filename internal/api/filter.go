@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -12,12 +14,26 @@ import (
 // parseFilter parses a filter expression string and returns parameterized SQL.
 // Example: "status='active' && age>25" → ("status" = $1 AND "age" > $2), ["active", 25]
 func parseFilter(tbl *schema.Table, input string) (string, []any, error) {
+	f, err := compileFilter(tbl, input)
+	return f.sql, f.args, err
+}
+
+// compiledFilter is a parsed filter expression.
+type compiledFilter struct {
+	sql     string
+	args    []any
+	columns []string // columns compared directly (not via a JSON path or near), in order of first use
+}
+
+// compileFilter is parseFilter that also reports the columns the filter
+// compares, for slow query index suggestions.
+func compileFilter(tbl *schema.Table, input string) (compiledFilter, error) {
 	tokens, err := tokenize(input)
 	if err != nil {
-		return "", nil, err
+		return compiledFilter{}, err
 	}
 	if len(tokens) == 0 {
-		return "", nil, nil
+		return compiledFilter{}, nil
 	}
 
 	p := &parser{
@@ -29,15 +45,14 @@ func parseFilter(tbl *schema.Table, input string) (string, []any, error) {
 
 	node, err := p.parseExpression()
 	if err != nil {
-		return "", nil, err
+		return compiledFilter{}, err
 	}
 
 	if p.pos < len(p.tokens) {
-		return "", nil, fmt.Errorf("unexpected token at position %d: %s", p.pos, p.tokens[p.pos].value)
+		return compiledFilter{}, fmt.Errorf("unexpected token at position %d: %s", p.pos, p.tokens[p.pos].value)
 	}
 
-	sql := node.toSQL()
-	return sql, p.args, nil
+	return compiledFilter{sql: node.toSQL(), args: p.args, columns: p.columns}, nil
 }
 
 // Token types
@@ -56,6 +71,7 @@ const (
 	tokLParen                  // (
 	tokRParen                  // )
 	tokComma                   // ,
+	tokArrow                   // ->, ->> (JSON path)
 )
 
 type token struct {
@@ -114,6 +130,18 @@ func tokenize(input string) ([]token, error) {
 			continue
 		}
 
+		// JSON path operators.
+		if i+2 < len(runes) && string(runes[i:i+3]) == "->>" {
+			tokens = append(tokens, token{tokArrow, "->>"})
+			i += 3
+			continue
+		}
+		if i+1 < len(runes) && string(runes[i:i+2]) == "->" {
+			tokens = append(tokens, token{tokArrow, "->"})
+			i += 2
+			continue
+		}
+
 		// Two-char operators.
 		if i+1 < len(runes) {
 			two := string(runes[i : i+2])
@@ -233,33 +261,64 @@ func (n *comparisonNode) toSQL() string {
 type inNode struct {
 	column    string
 	paramRefs []string
+	negate    bool // NOT IN
 }
 
 func (n *inNode) toSQL() string {
-	return n.column + " IN (" + strings.Join(n.paramRefs, ", ") + ")"
+	op := " IN ("
+	if n.negate {
+		op = " NOT IN ("
+	}
+	return n.column + op + strings.Join(n.paramRefs, ", ") + ")"
 }
 
-type isNullNode struct {
-	column string
-	isNull bool
+// isNode is "column IS [NOT] NULL|TRUE|FALSE". The keyword comes from a fixed
+// set, never from user text, so it needs no parameter.
+type isNode struct {
+	column  string
+	keyword string
+	not     bool
 }
 
-func (n *isNullNode) toSQL() string {
-	if n.isNull {
-		return n.column + " IS NULL"
+func (n *isNode) toSQL() string {
+	if n.not {
+		return n.column + " IS NOT " + n.keyword
 	}
-	return n.column + " IS NOT NULL"
+	return n.column + " IS " + n.keyword
+}
+
+type betweenNode struct {
+	column    string
+	low, high string // param refs
+}
+
+func (n *betweenNode) toSQL() string {
+	return n.column + " BETWEEN " + n.low + " AND " + n.high
+}
+
+// nearNode matches geometries within a distance in meters of a WGS 84 point.
+// The column is compared as geography, so geometry columns are expected to
+// use SRID 4326.
+type nearNode struct {
+	column           string
+	lng, lat, radius string // param refs
+}
+
+func (n *nearNode) toSQL() string {
+	return "ST_DWithin(" + n.column + "::geography, ST_SetSRID(ST_MakePoint(" +
+		n.lng + ", " + n.lat + "), 4326)::geography, " + n.radius + ")"
 }
 
 const maxFilterDepth = 50 // max nesting depth for parenthesized expressions
 
 // parser is a recursive descent parser for filter expressions.
 type parser struct {
-	tokens []token
-	pos    int
-	tbl    *schema.Table
-	args   []any
-	depth  int
+	tokens  []token
+	pos     int
+	tbl     *schema.Table
+	args    []any
+	depth   int
+	columns []string // see compiledFilter.columns
 }
 
 func (p *parser) peek() *token {
@@ -280,6 +339,15 @@ func (p *parser) addArg(val any) string {
 	return fmt.Sprintf("$%d", len(p.args))
 }
 
+// addValue coerces val to col's type and binds it as the next parameter.
+func (p *parser) addValue(col *schema.Column, val any) (string, error) {
+	cv, err := coerceValue(col, val)
+	if err != nil {
+		return "", err
+	}
+	return p.addArg(cv), nil
+}
+
 // expression = and_expr
 func (p *parser) parseExpression() (filterNode, error) {
 	return p.parseOrExpr()
@@ -362,7 +430,29 @@ func (p *parser) parsePrimary() (filterNode, error) {
 	return p.parseComparison()
 }
 
-// comparison = identifier op value | identifier "IN" "(" value ("," value)* ")"
+// comparisonOps maps the symbolic and keyword binary operators to SQL.
+var comparisonOps = map[string]string{
+	"=": "=", "eq": "=",
+	"!=": "!=", "neq": "!=",
+	">": ">", "gt": ">",
+	">=": ">=", "gte": ">=",
+	"<": "<", "lt": "<",
+	"<=": "<=", "lte": "<=",
+	// ~ and !~ are kept for PocketBase compatibility.
+	"~": "LIKE", "like": "LIKE", "ilike": "ILIKE",
+	"!~": "NOT LIKE",
+}
+
+// comparison = target op value
+//
+//	| target ("IN" | "nin") "(" value ("," value)* ")"
+//	| target "is" ["not"] (null | true | false)
+//	| target "between" value "and" value
+//	| identifier ".near" "=" lng "," lat "," radius_m
+//
+// target = identifier (("->" | "->>") (string | integer))*
+//
+// Keyword operators are case-insensitive.
 func (p *parser) parseComparison() (filterNode, error) {
 	t := p.peek()
 	if t == nil || t.kind != tokIdent {
@@ -370,56 +460,42 @@ func (p *parser) parseComparison() (filterNode, error) {
 	}
 	ident := p.advance()
 
-	// Validate column against schema.
-	col := p.tbl.ColumnByName(ident.value)
-	if col == nil {
-		return nil, fmt.Errorf("unknown column: %s", ident.value)
+	if name, ok := strings.CutSuffix(ident.value, ".near"); ok {
+		return p.parseNear(name)
 	}
-	quotedCol := quoteIdent(ident.value)
-
-	// Check for IN.
-	next := p.peek()
-	if next != nil && next.kind == tokIn {
-		p.advance() // consume IN
 
-		lp := p.peek()
-		if lp == nil || lp.kind != tokLParen {
-			return nil, fmt.Errorf("expected '(' after IN")
-		}
-		p.advance()
+	target, err := p.parseTarget(ident.value)
+	if err != nil {
+		return nil, err
+	}
 
-		var paramRefs []string
-		for {
-			val, err := p.parseValue()
-			if err != nil {
-				return nil, err
-			}
-			ref := p.addArg(val)
-			paramRefs = append(paramRefs, ref)
+	opTok := p.peek()
+	if opTok == nil || (opTok.kind != tokOp && opTok.kind != tokIn && opTok.kind != tokIdent) {
+		return nil, fmt.Errorf("expected operator after column %s", ident.value)
+	}
+	p.advance()
+	op := opTok.value
+	if opTok.kind != tokOp {
+		op = strings.ToLower(op)
+	}
 
-			next := p.peek()
-			if next == nil {
-				return nil, fmt.Errorf("expected ')' to close IN list")
-			}
-			if next.kind == tokRParen {
-				p.advance()
-				break
-			}
-			if next.kind != tokComma {
-				return nil, fmt.Errorf("expected ',' or ')' in IN list")
-			}
-			p.advance()
+	switch op {
+	case "in", "nin":
+		lhs, paramRefs, err := p.parseValueList(target, op)
+		if err != nil {
+			return nil, err
 		}
-
-		return &inNode{column: quotedCol, paramRefs: paramRefs}, nil
+		return &inNode{column: lhs, paramRefs: paramRefs, negate: op == "nin"}, nil
+	case "is":
+		return p.parseIs(target)
+	case "between":
+		return p.parseBetween(target)
 	}
 
-	// Regular comparison operator.
-	opTok := p.peek()
-	if opTok == nil || opTok.kind != tokOp {
-		return nil, fmt.Errorf("expected operator after column %s", ident.value)
+	sqlOp, ok := comparisonOps[op]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q after column %s", opTok.value, ident.value)
 	}
-	op := p.advance()
 
 	// Parse value.
 	val, err := p.parseValue()
@@ -429,27 +505,252 @@ func (p *parser) parseComparison() (filterNode, error) {
 
 	// Handle null comparisons specially.
 	if val == nil {
-		switch op.value {
+		switch sqlOp {
 		case "=":
-			return &isNullNode{column: quotedCol, isNull: true}, nil
+			return &isNode{column: target.expr, keyword: "NULL"}, nil
 		case "!=":
-			return &isNullNode{column: quotedCol, isNull: false}, nil
+			return &isNode{column: target.expr, keyword: "NULL", not: true}, nil
 		default:
 			return nil, fmt.Errorf("null can only be compared with = or !=")
 		}
 	}
 
-	// Map ~ and !~ to LIKE/NOT LIKE (PocketBase compatibility).
-	sqlOp := op.value
-	switch op.value {
-	case "~":
-		sqlOp = "LIKE"
-	case "!~":
-		sqlOp = "NOT LIKE"
+	// LIKE patterns are text whatever the column type, so they aren't coerced.
+	if sqlOp == "LIKE" || sqlOp == "NOT LIKE" || sqlOp == "ILIKE" {
+		if target.path && !target.asText {
+			return nil, fmt.Errorf("use ->> to match a JSON path with %s", sqlOp)
+		}
+		return &comparisonNode{column: target.expr, op: sqlOp, paramRef: p.addArg(val)}, nil
+	}
+	lhs, ref, err := p.bindValue(target, val)
+	if err != nil {
+		return nil, err
+	}
+	return &comparisonNode{column: lhs, op: sqlOp, paramRef: ref}, nil
+}
+
+// filterTarget is the left-hand side of a comparison: a column, or a path
+// into a jsonb column.
+type filterTarget struct {
+	col    *schema.Column
+	expr   string
+	path   bool // JSON path into col
+	asText bool // path ends with ->>, yielding text rather than jsonb
+}
+
+// parseTarget validates the column name and parses any JSON path after it.
+// Path keys are bound as parameters like values.
+func (p *parser) parseTarget(name string) (*filterTarget, error) {
+	col := p.tbl.ColumnByName(name)
+	if col == nil {
+		return nil, fmt.Errorf("unknown column: %s", name)
+	}
+	target := &filterTarget{col: col, expr: quoteIdent(name)}
+
+	for {
+		arrow := p.peek()
+		if arrow == nil || arrow.kind != tokArrow {
+			break
+		}
+		if !isJSONB(col) {
+			return nil, fmt.Errorf("JSON paths require a jsonb column: %s", name)
+		}
+		if target.asText {
+			return nil, fmt.Errorf("->> must be the last step of a JSON path")
+		}
+		p.advance()
+
+		key := p.peek()
+		if key == nil || (key.kind != tokString && key.kind != tokNumber) {
+			return nil, fmt.Errorf("expected a quoted key or array index after %s", arrow.value)
+		}
+		p.advance()
+		var ref string
+		if key.kind == tokString {
+			ref = p.addArg(key.value) + "::text"
+		} else {
+			idx, err := strconv.Atoi(key.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON array index: %s", key.value)
+			}
+			ref = p.addArg(idx) + "::int"
+		}
+		target.expr += arrow.value + ref
+		target.path = true
+		target.asText = arrow.value == "->>"
+	}
+	if target.path {
+		target.expr = "(" + target.expr + ")"
+	} else if !slices.Contains(p.columns, col.Name) {
+		p.columns = append(p.columns, col.Name)
+	}
+	return target, nil
+}
+
+// bindValue binds val for comparison with target and returns the left-hand
+// expression to compare it against. Column values are coerced to the column
+// type. A ->> path is cast to numeric or boolean to match a number or
+// boolean value; a -> path is compared as jsonb.
+func (p *parser) bindValue(target *filterTarget, val any) (lhs, ref string, err error) {
+	switch {
+	case !target.path:
+		ref, err = p.addValue(target.col, val)
+		return target.expr, ref, err
+	case !target.asText:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", "", err
+		}
+		return target.expr, p.addArg(string(b)) + "::jsonb", nil
+	}
+	switch val.(type) {
+	case int64, float64:
+		return target.expr + "::numeric", p.addArg(val), nil
+	case bool:
+		return target.expr + "::boolean", p.addArg(val), nil
+	}
+	return target.expr, p.addArg(val), nil
+}
+
+// parseValueList parses the parenthesized value list of an in/nin operator
+// and returns the left-hand expression and the parameter references.
+func (p *parser) parseValueList(target *filterTarget, op string) (string, []string, error) {
+	lp := p.peek()
+	if lp == nil || lp.kind != tokLParen {
+		return "", nil, fmt.Errorf("expected '(' after %s", strings.ToUpper(op))
+	}
+	p.advance()
+
+	var lhs string
+	var paramRefs []string
+	for {
+		val, err := p.parseValue()
+		if err != nil {
+			return "", nil, err
+		}
+		l, ref, err := p.bindValue(target, val)
+		if err != nil {
+			return "", nil, err
+		}
+		if lhs != "" && l != lhs {
+			return "", nil, fmt.Errorf("%s list values must all be the same type", strings.ToUpper(op))
+		}
+		lhs = l
+		paramRefs = append(paramRefs, ref)
+
+		next := p.peek()
+		if next == nil {
+			return "", nil, fmt.Errorf("expected ')' to close %s list", strings.ToUpper(op))
+		}
+		if next.kind == tokRParen {
+			p.advance()
+			return lhs, paramRefs, nil
+		}
+		if next.kind != tokComma {
+			return "", nil, fmt.Errorf("expected ',' or ')' in %s list", strings.ToUpper(op))
+		}
+		p.advance()
+	}
+}
+
+// parseIs parses the operand of "is": [not] null, true or false.
+func (p *parser) parseIs(target *filterTarget) (filterNode, error) {
+	node := &isNode{column: target.expr}
+	if t := p.peek(); t != nil && t.kind == tokIdent && strings.EqualFold(t.value, "not") {
+		p.advance()
+		node.not = true
+	}
+	t := p.peek()
+	if t == nil || (t.kind != tokNull && t.kind != tokBool) {
+		return nil, fmt.Errorf("IS must be followed by null, true or false")
+	}
+	p.advance()
+	node.keyword = strings.ToUpper(t.value)
+	if target.path && t.kind == tokBool {
+		node.column += "::boolean"
+	}
+	return node, nil
+}
+
+// parseBetween parses the "low and high" bounds of a between operator.
+func (p *parser) parseBetween(target *filterTarget) (filterNode, error) {
+	low, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t == nil || t.kind != tokAnd {
+		return nil, fmt.Errorf("expected AND in BETWEEN")
+	}
+	p.advance()
+	high, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if low == nil || high == nil {
+		return nil, fmt.Errorf("BETWEEN bounds cannot be null")
+	}
+	lhs, lowRef, err := p.bindValue(target, low)
+	if err != nil {
+		return nil, err
+	}
+	highLHS, highRef, err := p.bindValue(target, high)
+	if err != nil {
+		return nil, err
+	}
+	if highLHS != lhs {
+		return nil, fmt.Errorf("BETWEEN bounds must be the same type")
+	}
+	return &betweenNode{column: lhs, low: lowRef, high: highRef}, nil
+}
+
+// parseNear parses the "=lng,lat,radius_m" operand of a geometry column's
+// near filter, which matches rows within radius_m meters of the point.
+func (p *parser) parseNear(name string) (filterNode, error) {
+	col := p.tbl.ColumnByName(name)
+	if col == nil {
+		return nil, fmt.Errorf("unknown column: %s", name)
+	}
+	if !col.IsGeometry {
+		return nil, fmt.Errorf("near requires a PostGIS geometry or geography column: %s", name)
+	}
+	if t := p.peek(); t == nil || t.kind != tokOp || t.value != "=" {
+		return nil, fmt.Errorf("expected '=' after %s.near", name)
+	}
+	p.advance()
+
+	var nums [3]float64
+	for i := range nums {
+		if i > 0 {
+			if t := p.peek(); t == nil || t.kind != tokComma {
+				return nil, fmt.Errorf("near expects lng,lat,radius_m")
+			}
+			p.advance()
+		}
+		t := p.peek()
+		if t == nil || t.kind != tokNumber {
+			return nil, fmt.Errorf("near expects lng,lat,radius_m")
+		}
+		p.advance()
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", t.value)
+		}
+		nums[i] = f
+	}
+	lng, lat, radius := nums[0], nums[1], nums[2]
+	if lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("near point out of range: longitude must be within ±180 and latitude within ±90")
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("near radius must not be negative")
 	}
 
-	ref := p.addArg(val)
-	return &comparisonNode{column: quotedCol, op: sqlOp, paramRef: ref}, nil
+	return &nearNode{
+		column: quoteIdent(name),
+		lng:    p.addArg(lng),
+		lat:    p.addArg(lat),
+		radius: p.addArg(radius),
+	}, nil
 }
 
 // parseValue parses a literal value token.
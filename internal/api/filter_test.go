@@ -438,3 +438,220 @@ func TestFilterDepthAtLimit(t *testing.T) {
 	testutil.NoError(t, err)
 	testutil.True(t, len(sql) > 0, "filter at depth limit should produce SQL")
 }
+
+func TestParseFilterOperators(t *testing.T) {
+	t.Parallel()
+	tbl := filterTestTable()
+	tests := []struct {
+		input string
+		sql   string
+		args  []any
+	}{
+		{"age eq 30", `"age" = $1`, []any{int64(30)}},
+		{"age neq 30", `"age" != $1`, []any{int64(30)}},
+		{"age gt 30", `"age" > $1`, []any{int64(30)}},
+		{"age gte 30", `"age" >= $1`, []any{int64(30)}},
+		{"age lt 30", `"age" < $1`, []any{int64(30)}},
+		{"age lte 30", `"age" <= $1`, []any{int64(30)}},
+		{"age GTE 30", `"age" >= $1`, []any{int64(30)}},
+		{"name like 'A%'", `"name" LIKE $1`, []any{"A%"}},
+		{"name LIKE 'A%'", `"name" LIKE $1`, []any{"A%"}},
+		{"name ilike 'a%'", `"name" ILIKE $1`, []any{"a%"}},
+		{"status in ('a', 'b')", `"status" IN ($1, $2)`, []any{"a", "b"}},
+		{"status nin ('a', 'b')", `"status" NOT IN ($1, $2)`, []any{"a", "b"}},
+		{"name is null", `"name" IS NULL`, nil},
+		{"name is not null", `"name" IS NOT NULL`, nil},
+		{"active is true", `"active" IS TRUE`, nil},
+		{"active IS FALSE", `"active" IS FALSE`, nil},
+		{"name eq null", `"name" IS NULL`, nil},
+		{"age between 18 and 65", `"age" BETWEEN $1 AND $2`, []any{int64(18), int64(65)}},
+		{"age between 18 and 65 and status='a'", `("age" BETWEEN $1 AND $2 AND "status" = $3)`, []any{int64(18), int64(65), "a"}},
+		{"(age lt 18 or age gt 65) and active is true", `(("age" < $1 OR "age" > $2) AND "active" IS TRUE)`, []any{int64(18), int64(65)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			sql, args, err := parseFilter(tbl, tt.input)
+			testutil.NoError(t, err)
+			testutil.Equal(t, tt.sql, sql)
+			testutil.SliceLen(t, args, len(tt.args))
+			for i, want := range tt.args {
+				testutil.Equal(t, want, args[i])
+			}
+		})
+	}
+}
+
+func TestParseFilterOperatorErrors(t *testing.T) {
+	t.Parallel()
+	tbl := filterTestTable()
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"age foo 30", "unknown operator"},
+		{"age gt null", "null can only be compared"},
+		{"name is 'x'", "IS must be followed by"},
+		{"age between 1", "expected AND in BETWEEN"},
+		{"age between null and 5", "cannot be null"},
+		{"status nin 'a'", "expected '(' after NIN"},
+		{"status in ('a'", "to close IN list"},
+		{"missing eq 1", "unknown column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := parseFilter(tbl, tt.input)
+			testutil.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseFilterInjection(t *testing.T) {
+	t.Parallel()
+	tbl := filterTestTable()
+
+	// Values are always bound, never spliced into the SQL.
+	sql, args, err := parseFilter(tbl, `name eq 'x\'; DROP TABLE users; --'`)
+	testutil.NoError(t, err)
+	testutil.Equal(t, `"name" = $1`, sql)
+	testutil.Equal(t, "x'; DROP TABLE users; --", args[0].(string))
+
+	sql, args, err = parseFilter(tbl, `name like '%\' OR 1=1 --'`)
+	testutil.NoError(t, err)
+	testutil.Equal(t, `"name" LIKE $1`, sql)
+	testutil.Equal(t, "%' OR 1=1 --", args[0].(string))
+
+	// Anything that isn't a known column, operator, or literal is rejected.
+	for _, input := range []string{
+		`name" = 'x' OR "1`,
+		`1=1`,
+		`name eq 'x'; DROP TABLE users`,
+		`name is null; DROP TABLE users`,
+		`name is (SELECT 1)`,
+		`pg_sleep(10) eq 1`,
+	} {
+		_, _, err := parseFilter(tbl, input)
+		testutil.NotNil(t, err)
+	}
+}
+
+func TestParseFilterNear(t *testing.T) {
+	t.Parallel()
+	tbl := geoTable()
+
+	sql, args, err := parseFilter(tbl, "location.near=-122.4194,37.7749,500 && name='cafe'")
+	testutil.NoError(t, err)
+	testutil.Equal(t,
+		`(ST_DWithin("location"::geography, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography, $3) AND "name" = $4)`, sql)
+	testutil.SliceLen(t, args, 4)
+	testutil.Equal(t, -122.4194, args[0].(float64))
+	testutil.Equal(t, 37.7749, args[1].(float64))
+	testutil.Equal(t, 500.0, args[2].(float64))
+
+	for input, msg := range map[string]string{
+		"name.near=1,2,3":        "requires a PostGIS geometry",
+		"missing.near=1,2,3":     "unknown column",
+		"location.near=1,2":      "near expects lng,lat,radius_m",
+		"location.near>1,2,3":    "expected '='",
+		"location.near=181,0,10": "out of range",
+		"location.near=0,-91,10": "out of range",
+		"location.near=0,0,-1":   "must not be negative",
+		"location.near='1,2,3'":  "near expects lng,lat,radius_m",
+	} {
+		_, _, err := parseFilter(tbl, input)
+		testutil.ErrorContains(t, err, msg)
+	}
+
+	// Without PostGIS, geometry columns aren't flagged and near is rejected.
+	_, _, err = parseFilter(filterTestTable(), "name.near=1,2,3")
+	testutil.ErrorContains(t, err, "requires a PostGIS geometry")
+}
+
+func TestParseFilterJSONPath(t *testing.T) {
+	t.Parallel()
+	tbl := jsonbTable()
+
+	tests := []struct {
+		name  string
+		input string
+		sql   string
+		args  []any
+	}{
+		{"text", "data->>'status'='active'",
+			`("data"->>$1::text) = $2`, []any{"status", "active"}},
+		{"nested", "data->'meta'->>'status'='active'",
+			`("data"->$1::text->>$2::text) = $3`, []any{"meta", "status", "active"}},
+		{"array index", "data->'tags'->>0='go'",
+			`("data"->$1::text->>$2::int) = $3`, []any{"tags", 0, "go"}},
+		{"numeric", "data->>'score'>=10",
+			`("data"->>$1::text)::numeric >= $2`, []any{"score", int64(10)}},
+		{"boolean", "data->>'done'=true",
+			`("data"->>$1::text)::boolean = $2`, []any{"done", true}},
+		{"jsonb", "data->'meta'='{}'",
+			`("data"->$1::text) = $2::jsonb`, []any{"meta", `"{}"`}},
+		{"null", "data->>'status'=null",
+			`("data"->>$1::text) IS NULL`, []any{"status"}},
+		{"is true", "data->>'done' is true",
+			`("data"->>$1::text)::boolean IS TRUE`, []any{"done"}},
+		{"like", "data->>'status' ilike 'act%'",
+			`("data"->>$1::text) ILIKE $2`, []any{"status", "act%"}},
+		{"in", "data->>'status' in ('a','b')",
+			`("data"->>$1::text) IN ($2, $3)`, []any{"status", "a", "b"}},
+		{"between", "data->>'score' between 1 and 5",
+			`("data"->>$1::text)::numeric BETWEEN $2 AND $3`, []any{"score", int64(1), int64(5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sql, args, err := parseFilter(tbl, tt.input)
+			testutil.NoError(t, err)
+			testutil.Equal(t, tt.sql, sql)
+			testutil.SliceLen(t, args, len(tt.args))
+			for i := range tt.args {
+				testutil.Equal(t, tt.args[i], args[i])
+			}
+		})
+	}
+}
+
+func TestParseFilterJSONPathErrors(t *testing.T) {
+	t.Parallel()
+	tbl := jsonbTable()
+
+	for input, msg := range map[string]string{
+		"name->>'x'='y'":               "require a jsonb column",
+		"doc->>'x'='y'":                "require a jsonb column",
+		"data->>'a'->>'b'='y'":         "must be the last step",
+		"data->x='y'":                  "expected a quoted key",
+		"data->>1.5='y'":               "invalid JSON array index",
+		"data->'meta' like 'x%'":       "use ->>",
+		"data->>'v' in (1,'a')":        "same type",
+		"data->>'v' between 1 and 'z'": "same type",
+	} {
+		_, _, err := parseFilter(tbl, input)
+		testutil.ErrorContains(t, err, msg)
+	}
+}
+
+func TestParseFilterJSONPathInjection(t *testing.T) {
+	t.Parallel()
+	// Path keys are bound as parameters, never spliced into the SQL.
+	sql, args, err := parseFilter(jsonbTable(), `data->>'x\'); DROP TABLE users; --'='y'`)
+	testutil.NoError(t, err)
+	testutil.Equal(t, `("data"->>$1::text) = $2`, sql)
+	testutil.Equal(t, "x'); DROP TABLE users; --", args[0].(string))
+}
+
+func TestCompileFilterColumns(t *testing.T) {
+	t.Parallel()
+	f, err := compileFilter(filterTestTable(), "status='active' && (age>=18 || status='admin') && name IS NOT NULL")
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, f.args, 3)
+	testutil.Equal(t, "status,age,name", strings.Join(f.columns, ","))
+
+	// JSON path comparisons can't use a plain index on the column.
+	f, err = compileFilter(jsonbTable(), "data->>'status'='active' && name='x'")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "name", strings.Join(f.columns, ","))
+}
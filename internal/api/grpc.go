@@ -0,0 +1,720 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GRPCServiceName is the fully-qualified name of the collections gRPC service.
+const GRPCServiceName = "ayb.v1.Collections"
+
+// grpcFile describes the collections service. It is built from a descriptor
+// at init rather than generated from a .proto, since rows travel as
+// google.protobuf.Struct and one service covers every table. It is registered
+// globally so server reflection (grpcurl, Postman) can describe it.
+var grpcFile = mustRegisterGRPCFile()
+
+// APIKeyValidator validates API keys presented to the gRPC gateway.
+// *auth.Service satisfies it.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, plaintext string) (*auth.Claims, error)
+}
+
+// GRPCServer serves generic collection CRUD over gRPC for service-to-service
+// callers. Every call must carry an API key in the "authorization" metadata
+// ("Bearer ayb_..."); its scope and RLS context apply exactly as over REST.
+type GRPCServer struct {
+	h           *Handler
+	keys        APIKeyValidator
+	logger      *slog.Logger
+	srv         *grpc.Server
+	maintenance func() Maintenance // nil means never in maintenance
+	stmtTimeout time.Duration      // 0 means none
+}
+
+// Maintenance is the server's maintenance mode state as the gRPC gateway
+// sees it.
+type Maintenance struct {
+	Enabled    bool
+	AllowReads bool
+	Message    string
+	RetryAfter int // seconds
+}
+
+// NewGRPCServer creates a gRPC server backed by the same query builders and
+// RLS handling as h.
+func NewGRPCServer(h *Handler, keys APIKeyValidator, logger *slog.Logger) *GRPCServer {
+	s := &GRPCServer{h: h, keys: keys, logger: logger}
+	s.srv = grpc.NewServer(grpc.ChainUnaryInterceptor(s.maintenanceGate, s.authenticate))
+	s.srv.RegisterService(s.serviceDesc(), s)
+	reflection.Register(s.srv)
+	return s
+}
+
+// SetMaintenance makes calls consult state for maintenance mode, which gates
+// them like the public REST API: writes fail with Unavailable, and so do
+// reads unless the state allows them.
+func (s *GRPCServer) SetMaintenance(state func() Maintenance) {
+	s.maintenance = state
+}
+
+// SetStatementTimeout runs each call's queries with Postgres
+// statement_timeout set to d, as WithStatementTimeout does for REST
+// requests. 0 disables it.
+func (s *GRPCServer) SetStatementTimeout(d time.Duration) {
+	s.stmtTimeout = d
+}
+
+// Serve accepts connections on lis until Stop or GracefulStop is called,
+// after which it returns nil.
+func (s *GRPCServer) Serve(lis net.Listener) error {
+	if err := s.srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// GracefulStop stops accepting new calls and waits for in-flight calls to finish.
+func (s *GRPCServer) GracefulStop() {
+	s.srv.GracefulStop()
+}
+
+// Stop closes all connections immediately.
+func (s *GRPCServer) Stop() {
+	s.srv.Stop()
+}
+
+// authenticate is a unary interceptor that requires an API key and attaches
+// its claims, and the statement timeout, to the context. JWTs and OAuth tokens are rejected: the gateway
+// is for service-to-service use only.
+func (s *GRPCServer) authenticate(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || !auth.IsAPIKey(token) || auth.IsOAuthAccessToken(token) {
+		return nil, status.Error(codes.Unauthenticated, "an API key is required")
+	}
+	claims, err := s.keys.ValidateAPIKey(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired api key")
+	}
+	ctx = auth.ContextWithClaims(ctx, claims)
+	if s.stmtTimeout > 0 {
+		ctx = WithStatementTimeout(ctx, s.stmtTimeout)
+	}
+	return handler(ctx, req)
+}
+
+// maintenanceGate is a unary interceptor that rejects calls while
+// maintenance mode is on, sending the Retry-After as "retry-after" metadata.
+func (s *GRPCServer) maintenanceGate(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if s.maintenance == nil {
+		return handler(ctx, req)
+	}
+	st := s.maintenance()
+	if !st.Enabled || (st.AllowReads && isGRPCRead(info.FullMethod)) {
+		return handler(ctx, req)
+	}
+	msg := st.Message
+	if msg == "" {
+		msg = "service is under maintenance"
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(st.RetryAfter)))
+	return nil, status.Error(codes.Unavailable, msg)
+}
+
+// isGRPCRead reports whether fullMethod only reads rows.
+func isGRPCRead(fullMethod string) bool {
+	switch strings.TrimPrefix(fullMethod, "/"+GRPCServiceName+"/") {
+	case "List", "Get":
+		return true
+	}
+	return false
+}
+
+func (s *GRPCServer) serviceDesc() *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: GRPCServiceName,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			s.method("List", "ListRequest", s.list),
+			s.method("Get", "GetRequest", s.get),
+			s.method("Create", "CreateRequest", s.create),
+			s.method("Update", "UpdateRequest", s.update),
+			s.method("Delete", "DeleteRequest", s.delete),
+		},
+		Metadata: grpcFile.Path(),
+	}
+}
+
+// method adapts a call taking a dynamic request message to a grpc.MethodDesc.
+func (s *GRPCServer) method(name, input string, call func(context.Context, protoreflect.Message) (proto.Message, error)) grpc.MethodDesc {
+	desc := grpcFile.Messages().ByName(protoreflect.Name(input))
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := dynamicpb.NewMessage(desc)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return call(ctx, req.(*dynamicpb.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: s, FullMethod: "/" + GRPCServiceName + "/" + name}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
+
+func (s *GRPCServer) list(ctx context.Context, in protoreflect.Message) (proto.Message, error) {
+	tbl, err := s.resolveTable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, perr := newListOpts(tbl, s.h.pageSizeFor(tbl.Name), listParams{
+		page:      int(msgInt(in, "page")),
+		perPage:   int(msgInt(in, "per_page")),
+		skipTotal: msgBool(in, "skip_total"),
+		fields:    msgStrings(in, "fields"),
+		sort:      msgString(in, "sort"),
+		filter:    msgString(in, "filter"),
+		search:    msgString(in, "search"),
+	})
+	if perr != nil {
+		return nil, status.Error(codes.InvalidArgument, perr.message)
+	}
+
+	q, done, err := s.h.withRLSContext(ctx)
+	if err != nil {
+		return nil, s.internal("rls setup error", err, tbl)
+	}
+	start := time.Now()
+	resp, err := fetchList(ctx, q, tbl, opts)
+	s.h.observeList(tbl, opts, time.Since(start))
+	if err = done(err); err != nil {
+		return nil, s.queryError("list error", err, tbl)
+	}
+
+	out, err := listResponseMessage(resp)
+	if err != nil {
+		return nil, s.internal("encode error", err, tbl)
+	}
+	return out, nil
+}
+
+// listResponseMessage converts a ListResponse to an ayb.v1.ListResponse message.
+func listResponseMessage(resp *ListResponse) (proto.Message, error) {
+	out := dynamicpb.NewMessage(grpcFile.Messages().ByName("ListResponse"))
+	setField(out, "page", protoreflect.ValueOfInt32(int32(resp.Page)))
+	setField(out, "per_page", protoreflect.ValueOfInt32(int32(resp.PerPage)))
+	setField(out, "total_items", protoreflect.ValueOfInt64(int64(resp.TotalItems)))
+	setField(out, "total_pages", protoreflect.ValueOfInt32(int32(resp.TotalPages)))
+	items := out.Mutable(out.Descriptor().Fields().ByName("items")).List()
+	for _, rec := range resp.Items {
+		st, err := recordToStruct(rec)
+		if err != nil {
+			return nil, err
+		}
+		items.Append(protoreflect.ValueOfMessage(st.ProtoReflect()))
+	}
+	return out, nil
+}
+
+func (s *GRPCServer) get(ctx context.Context, in protoreflect.Message) (proto.Message, error) {
+	tbl, pkValues, err := s.resolveRecord(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	query, args := buildSelectOne(tbl, msgStrings(in, "fields"), pkValues)
+	record, err := s.queryOne(ctx, tbl, query, args, "query error")
+	if err != nil {
+		return nil, err
+	}
+	return s.recordResponse(record, tbl)
+}
+
+func (s *GRPCServer) create(ctx context.Context, in protoreflect.Message) (proto.Message, error) {
+	tbl, err := s.resolveWritableTable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.msgRecord(ctx, in, tbl, false)
+	if err != nil {
+		return nil, err
+	}
+	s.h.setServerValues(ctx, tbl, data, false)
+	q, done, err := s.h.withWrite(ctx, tbl)
+	if err != nil {
+		return nil, s.internal("rls setup error", err, tbl)
+	}
+	err = s.h.checkReferences(ctx, q, tbl, data)
+	var record map[string]any
+	if err == nil {
+		record, err = s.h.insertRecord(ctx, q, tbl, data)
+	}
+	if err = done(err); err != nil {
+		return nil, s.queryError("insert error", err, tbl)
+	}
+	s.h.publishEvent(ctx, "create", tbl.Name, record)
+	return s.recordResponse(record, tbl)
+}
+
+func (s *GRPCServer) update(ctx context.Context, in protoreflect.Message) (proto.Message, error) {
+	tbl, err := s.resolveWritableTable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	pkValues, err := recordPK(in, tbl)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.msgRecord(ctx, in, tbl, true)
+	if err != nil {
+		return nil, err
+	}
+	s.h.setServerValues(ctx, tbl, data, true)
+	q, done, err := s.h.withWrite(ctx, tbl)
+	if err != nil {
+		return nil, s.internal("rls setup error", err, tbl)
+	}
+	err = s.h.checkReferences(ctx, q, tbl, data)
+	var record map[string]any
+	if err == nil {
+		record, err = s.h.updateRecord(ctx, q, tbl, data, pkValues)
+	}
+	if err = done(err); err != nil {
+		return nil, s.queryError("update error", err, tbl)
+	}
+	if record == nil {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	s.h.publishEvent(ctx, "update", tbl.Name, record)
+	return s.recordResponse(record, tbl)
+}
+
+func (s *GRPCServer) delete(ctx context.Context, in protoreflect.Message) (proto.Message, error) {
+	tbl, err := s.resolveWritableTable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	pkValues, err := recordPK(in, tbl)
+	if err != nil {
+		return nil, err
+	}
+	q, done, err := s.h.withWrite(ctx, tbl)
+	if err != nil {
+		return nil, s.internal("rls setup error", err, tbl)
+	}
+	record, err := s.h.deleteRecord(ctx, q, tbl, pkValues)
+	if err = done(err); err != nil {
+		return nil, s.queryError("delete error", err, tbl)
+	}
+	if record == nil {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	s.h.publishEvent(ctx, "delete", tbl.Name, record)
+	return &emptypb.Empty{}, nil
+}
+
+// queryOne runs a single-row SELECT under the caller's RLS context.
+func (s *GRPCServer) queryOne(ctx context.Context, tbl *schema.Table, query string, args []any, op string) (map[string]any, error) {
+	q, done, err := s.h.withRLSContext(ctx)
+	if err != nil {
+		return nil, s.internal("rls setup error", err, tbl)
+	}
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		done(err)
+		return nil, s.queryError(op, err, tbl)
+	}
+	record, err := scanRow(rows)
+	rows.Close() // Close before done() to avoid pgx "conn busy" on commit.
+	if err := done(err); err != nil {
+		return nil, s.queryError(op, err, tbl)
+	}
+	if record == nil {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	return record, nil
+}
+
+func (s *GRPCServer) recordResponse(record map[string]any, tbl *schema.Table) (proto.Message, error) {
+	st, err := recordToStruct(record)
+	if err != nil {
+		return nil, s.internal("encode error", err, tbl)
+	}
+	return st, nil
+}
+
+// resolveTable looks up the request's table and enforces the API key's table scope.
+func (s *GRPCServer) resolveTable(ctx context.Context, in protoreflect.Message) (*schema.Table, error) {
+	sc := s.h.schema.Get()
+	if sc == nil {
+		return nil, status.Error(codes.Unavailable, "schema cache not ready")
+	}
+	name := msgString(in, "table")
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "table is required")
+	}
+	tbl := sc.TableByName(name)
+	if tbl == nil {
+		return nil, status.Error(codes.NotFound, "collection not found: "+name)
+	}
+	if err := auth.CheckTableScope(auth.ClaimsFromContext(ctx), name); err != nil {
+		return nil, status.Error(codes.PermissionDenied, "api key does not have access to table: "+name)
+	}
+	return tbl, nil
+}
+
+// resolveRecord resolves the table and primary key for a single-record read.
+func (s *GRPCServer) resolveRecord(ctx context.Context, in protoreflect.Message) (*schema.Table, []string, error) {
+	tbl, err := s.resolveTable(ctx, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkValues, err := recordPK(in, tbl)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tbl, pkValues, nil
+}
+
+// resolveWritableTable resolves the table for a write and checks the API key's
+// write scope and that the relation accepts writes.
+func (s *GRPCServer) resolveWritableTable(ctx context.Context, in protoreflect.Message) (*schema.Table, error) {
+	tbl, err := s.resolveTable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.CheckWriteScope(auth.ClaimsFromContext(ctx)); err != nil {
+		return nil, status.Error(codes.PermissionDenied, "api key scope does not permit write operations")
+	}
+	if !isWritable(tbl) {
+		return nil, status.Error(codes.FailedPrecondition, "write operations not allowed on "+tbl.Kind)
+	}
+	return tbl, nil
+}
+
+// hookCode maps the HTTP status of a HookError to a gRPC code.
+func hookCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.InvalidArgument
+	}
+}
+
+func (s *GRPCServer) internal(msg string, err error, tbl *schema.Table) error {
+	s.logger.Error("grpc "+msg, "error", err, "table", tbl.Name)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// queryError maps a database error to a gRPC status, mirroring mapPGError.
+func (s *GRPCServer) queryError(msg string, err error, tbl *schema.Table) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Error(codes.NotFound, "record not found")
+	}
+	if errors.Is(err, errVersionConflict) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+	var hookErr *HookError
+	if errors.As(err, &hookErr) {
+		return status.Error(hookCode(hookErr.Status), hookErr.Message)
+	}
+	var refErr *referenceError
+	if errors.As(err, &refErr) {
+		return status.Error(codes.InvalidArgument, "foreign key violation: "+refErr.field+": "+refErr.Error())
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "P0001":
+			return status.Error(codes.InvalidArgument, pgErr.Message)
+		case "23505":
+			return status.Error(codes.AlreadyExists, "unique constraint violation: "+pgErr.ConstraintName)
+		case "23503":
+			return status.Error(codes.InvalidArgument, "foreign key violation: "+pgErr.ConstraintName)
+		case "23502":
+			return status.Error(codes.InvalidArgument, "missing required value: "+pgErr.ColumnName)
+		case "23514":
+			return status.Error(codes.InvalidArgument, "check constraint violation: "+pgErr.ConstraintName)
+		case "22P02":
+			return status.Error(codes.InvalidArgument, friendlyTypeError(pgErr.Message))
+		case "42501":
+			return status.Error(codes.PermissionDenied, "insufficient permissions")
+		case "57014":
+			return status.Error(codes.DeadlineExceeded, "statement timed out")
+		}
+	}
+	return s.internal(msg, err, tbl)
+}
+
+// recordPK extracts and validates the "id" field against the table's primary key.
+func recordPK(in protoreflect.Message, tbl *schema.Table) ([]string, error) {
+	if len(tbl.PrimaryKey) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "table has no primary key")
+	}
+	id := msgString(in, "id")
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	pkValues := parsePKValues(id, len(tbl.PrimaryKey))
+	if len(pkValues) != len(tbl.PrimaryKey) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid primary key: expected %d values", len(tbl.PrimaryKey))
+	}
+	return pkValues, nil
+}
+
+// msgRecord decodes the "record" Struct field and checks that it names at
+// least one column of tbl and passes the field rules. update is true for
+// updates; see prepareRecord.
+func (s *GRPCServer) msgRecord(ctx context.Context, in protoreflect.Message, tbl *schema.Table, update bool) (map[string]any, error) {
+	fd := in.Descriptor().Fields().ByName("record")
+	if !in.Has(fd) {
+		return nil, status.Error(codes.InvalidArgument, "record is required")
+	}
+	// The dynamic sub-message is wire-compatible with structpb.Struct.
+	raw, err := proto.Marshal(in.Get(fd).Message().Interface())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid record")
+	}
+	var st structpb.Struct
+	if err := proto.Unmarshal(raw, &st); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid record")
+	}
+	data := st.AsMap()
+	if len(data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty record")
+	}
+	s.h.dropManagedValues(ctx, tbl, data)
+	if countKnownColumns(tbl, data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no recognized columns in record")
+	}
+	if err := prepareRecord(tbl, data, update); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if ferr := s.h.checkFieldRules(tbl, data); ferr != nil {
+		return nil, status.Error(codes.InvalidArgument, ferr.Error())
+	}
+	return data, nil
+}
+
+// recordToStruct converts a scanned row to a Struct via its JSON encoding, so
+// gRPC callers see exactly the values the REST API returns.
+func recordToStruct(record map[string]any) (*structpb.Struct, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	st := &structpb.Struct{}
+	if err := protojson.Unmarshal(raw, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func msgString(m protoreflect.Message, name protoreflect.Name) string {
+	return m.Get(m.Descriptor().Fields().ByName(name)).String()
+}
+
+func msgInt(m protoreflect.Message, name protoreflect.Name) int64 {
+	return m.Get(m.Descriptor().Fields().ByName(name)).Int()
+}
+
+func msgBool(m protoreflect.Message, name protoreflect.Name) bool {
+	return m.Get(m.Descriptor().Fields().ByName(name)).Bool()
+}
+
+func msgStrings(m protoreflect.Message, name protoreflect.Name) []string {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || !m.Has(fd) {
+		return nil
+	}
+	list := m.Get(fd).List()
+	out := make([]string, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if v := strings.TrimSpace(list.Get(i).String()); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func setField(m protoreflect.Message, name protoreflect.Name, v protoreflect.Value) {
+	m.Set(m.Descriptor().Fields().ByName(name), v)
+}
+
+// mustRegisterGRPCFile builds the ayb/v1/collections.proto descriptor:
+//
+//	service Collections {
+//	  rpc List(ListRequest) returns (ListResponse);
+//	  rpc Get(GetRequest) returns (google.protobuf.Struct);
+//	  rpc Create(CreateRequest) returns (google.protobuf.Struct);
+//	  rpc Update(UpdateRequest) returns (google.protobuf.Struct);
+//	  rpc Delete(DeleteRequest) returns (google.protobuf.Empty);
+//	}
+func mustRegisterGRPCFile() protoreflect.FileDescriptor {
+	const (
+		structType = ".google.protobuf.Struct"
+		emptyType  = ".google.protobuf.Empty"
+	)
+	scalar := func(name string, num int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(protoJSONName(name)),
+			Number:   proto.Int32(num),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	message := func(name string, num int32, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := scalar(name, num, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+		f.TypeName = proto.String(typeName)
+		return f
+	}
+	const (
+		tString = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		tInt32  = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		tInt64  = descriptorpb.FieldDescriptorProto_TYPE_INT64
+		tBool   = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+	)
+	msg := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	rpc := func(name, in, out string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{Name: proto.String(name), InputType: proto.String(in), OutputType: proto.String(out)}
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("ayb/v1/collections.proto"),
+		Package:    proto.String("ayb.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/struct.proto", "google/protobuf/empty.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			msg("ListRequest",
+				scalar("table", 1, tString),
+				scalar("filter", 2, tString),
+				scalar("sort", 3, tString),
+				scalar("search", 4, tString),
+				scalar("page", 5, tInt32),
+				scalar("per_page", 6, tInt32),
+				scalar("skip_total", 7, tBool),
+				repeated(scalar("fields", 8, tString)),
+			),
+			msg("ListResponse",
+				scalar("page", 1, tInt32),
+				scalar("per_page", 2, tInt32),
+				scalar("total_items", 3, tInt64),
+				scalar("total_pages", 4, tInt32),
+				repeated(message("items", 5, structType)),
+			),
+			msg("GetRequest",
+				scalar("table", 1, tString),
+				scalar("id", 2, tString),
+				repeated(scalar("fields", 3, tString)),
+			),
+			msg("CreateRequest",
+				scalar("table", 1, tString),
+				message("record", 2, structType),
+			),
+			msg("UpdateRequest",
+				scalar("table", 1, tString),
+				scalar("id", 2, tString),
+				message("record", 3, structType),
+			),
+			msg("DeleteRequest",
+				scalar("table", 1, tString),
+				scalar("id", 2, tString),
+			),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Collections"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				rpc("List", ".ayb.v1.ListRequest", ".ayb.v1.ListResponse"),
+				rpc("Get", ".ayb.v1.GetRequest", structType),
+				rpc("Create", ".ayb.v1.CreateRequest", structType),
+				rpc("Update", ".ayb.v1.UpdateRequest", structType),
+				rpc("Delete", ".ayb.v1.DeleteRequest", emptyType),
+			},
+		}},
+	}
+
+	// Reference the well-known types so their files are linked in and
+	// registered before the dependency lookup below.
+	_ = structpb.File_google_protobuf_struct_proto
+	_ = emptypb.File_google_protobuf_empty_proto
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("building grpc descriptor: %v", err))
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(fd); err != nil {
+		panic(fmt.Sprintf("registering grpc descriptor: %v", err))
+	}
+	return fd
+}
+
+// protoJSONName returns the lowerCamelCase JSON name protoc assigns to a field.
+func protoJSONName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const testAPIKey = "ayb_0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// fakeKeys accepts testAPIKey and returns claims.
+type fakeKeys struct {
+	claims *auth.Claims
+}
+
+func (f fakeKeys) ValidateAPIKey(_ context.Context, plaintext string) (*auth.Claims, error) {
+	if plaintext != testAPIKey {
+		return nil, errors.New("invalid api key")
+	}
+	return f.claims, nil
+}
+
+// grpcTestClient starts a GRPCServer over an in-memory listener with no
+// database and returns a client connection.
+func grpcTestClient(t *testing.T, claims *auth.Claims) *grpc.ClientConn {
+	t.Helper()
+	return dialGRPC(t, newGRPCTestServer(claims))
+}
+
+func newGRPCTestServer(claims *auth.Claims) *GRPCServer {
+	h := NewHandler(nil, testCacheHolder(testSchema()), slog.Default(), nil, nil)
+	return NewGRPCServer(h, fakeKeys{claims: claims}, slog.Default())
+}
+
+// dialGRPC serves srv over an in-memory listener and returns a client
+// connection to it.
+func dialGRPC(t *testing.T, srv *GRPCServer) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	testutil.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// grpcRequest builds a dynamic request message from protojson.
+func grpcRequest(t *testing.T, msgName, js string) *dynamicpb.Message {
+	t.Helper()
+	m := dynamicpb.NewMessage(grpcFile.Messages().ByName(protoreflect.Name(msgName)))
+	testutil.NoError(t, protojson.Unmarshal([]byte(js), m))
+	return m
+}
+
+func invoke(t *testing.T, conn *grpc.ClientConn, token, method, msgName, js string) error {
+	t.Helper()
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+	var out structpb.Struct
+	return conn.Invoke(ctx, "/"+GRPCServiceName+"/"+method, grpcRequest(t, msgName, js), &out)
+}
+
+func TestGRPCDescriptorRegistered(t *testing.T) {
+	t.Parallel()
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(GRPCServiceName)
+	testutil.NoError(t, err)
+	svc, ok := d.(protoreflect.ServiceDescriptor)
+	testutil.True(t, ok, "expected a service descriptor")
+	testutil.Equal(t, 5, svc.Methods().Len())
+	testutil.Equal(t, protoreflect.FullName("google.protobuf.Struct"), svc.Methods().ByName("Get").Output().FullName())
+	testutil.Equal(t, protoreflect.FullName("google.protobuf.Empty"), svc.Methods().ByName("Delete").Output().FullName())
+	testutil.Equal(t, "perPage", grpcFile.Messages().ByName("ListRequest").Fields().ByName("per_page").JSONName())
+}
+
+func TestGRPCAuthRequired(t *testing.T) {
+	t.Parallel()
+	conn := grpcTestClient(t, &auth.Claims{APIKeyScope: auth.ScopeFullAccess})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing", ""},
+		{"jwt", "eyJhbGciOiJIUzI1NiJ9.e30.sig"},
+		{"oauth access token", "ayb_at_0123456789abcdef"},
+		{"unknown api key", "ayb_nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := invoke(t, conn, tt.token, "Get", "GetRequest", `{"table":"users","id":"1"}`)
+			testutil.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+func TestGRPCRequestValidation(t *testing.T) {
+	t.Parallel()
+	conn := grpcTestClient(t, &auth.Claims{APIKeyScope: auth.ScopeFullAccess})
+
+	tests := []struct {
+		name, method, msg, body string
+		code                    codes.Code
+		contains                string
+	}{
+		{"missing table", "Get", "GetRequest", `{"id":"1"}`, codes.InvalidArgument, "table is required"},
+		{"unknown table", "Get", "GetRequest", `{"table":"nope","id":"1"}`, codes.NotFound, "collection not found"},
+		{"missing id", "Get", "GetRequest", `{"table":"users"}`, codes.InvalidArgument, "id is required"},
+		{"no primary key", "Get", "GetRequest", `{"table":"nopk","id":"1"}`, codes.FailedPrecondition, "no primary key"},
+		{"write to view", "Create", "CreateRequest", `{"table":"logs","record":{"message":"x"}}`, codes.FailedPrecondition, "not allowed on view"},
+		{"missing record", "Create", "CreateRequest", `{"table":"users"}`, codes.InvalidArgument, "record is required"},
+		{"unknown columns", "Create", "CreateRequest", `{"table":"users","record":{"bogus":1}}`, codes.InvalidArgument, "no recognized columns"},
+		{"invalid filter", "List", "ListRequest", `{"table":"users","filter":"nope = 1"}`, codes.InvalidArgument, "invalid filter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := invoke(t, conn, testAPIKey, tt.method, tt.msg, tt.body)
+			testutil.Equal(t, tt.code, status.Code(err))
+			testutil.Contains(t, status.Convert(err).Message(), tt.contains)
+		})
+	}
+}
+
+func TestGRPCScopes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("readonly key cannot write", func(t *testing.T) {
+		conn := grpcTestClient(t, &auth.Claims{APIKeyScope: auth.ScopeReadOnly})
+		err := invoke(t, conn, testAPIKey, "Delete", "DeleteRequest", `{"table":"users","id":"1"}`)
+		testutil.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("table restriction", func(t *testing.T) {
+		conn := grpcTestClient(t, &auth.Claims{APIKeyScope: auth.ScopeFullAccess, AllowedTables: []string{"logs"}})
+		err := invoke(t, conn, testAPIKey, "Get", "GetRequest", `{"table":"users","id":"1"}`)
+		testutil.Equal(t, codes.PermissionDenied, status.Code(err))
+		testutil.Contains(t, status.Convert(err).Message(), "does not have access to table: users")
+	})
+}
+
+func TestGRPCDanglingReference(t *testing.T) {
+	t.Parallel()
+	h := &Handler{checkRefs: true}
+	s := &GRPCServer{h: h, logger: slog.Default()}
+	tbl := referencingTable()
+	err := h.checkReferences(context.Background(), &refQuerier{exists: false}, tbl, map[string]any{"author_id": int64(9)})
+	err = s.queryError("insert error", err, tbl)
+	testutil.Equal(t, codes.InvalidArgument, status.Code(err))
+	testutil.Contains(t, status.Convert(err).Message(), "author_id")
+	testutil.Contains(t, status.Convert(err).Message(), "referenced users not found")
+}
+
+func TestGRPCMaintenance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		state  Maintenance
+		method string
+		msg    string
+		body   string
+		code   codes.Code
+	}{
+		{"write blocked", Maintenance{Enabled: true, RetryAfter: 60}, "Delete", "DeleteRequest", `{"table":"users","id":"1"}`, codes.Unavailable},
+		{"read blocked", Maintenance{Enabled: true, RetryAfter: 60}, "Get", "GetRequest", `{"table":"users","id":"1"}`, codes.Unavailable},
+		{"write blocked with reads allowed", Maintenance{Enabled: true, AllowReads: true, RetryAfter: 60}, "Create", "CreateRequest", `{"table":"users","record":{"email":"a@b.c"}}`, codes.Unavailable},
+		// Reads reach the handler, which rejects the missing id.
+		{"read allowed", Maintenance{Enabled: true, AllowReads: true, RetryAfter: 60}, "Get", "GetRequest", `{"table":"users"}`, codes.InvalidArgument},
+		{"disabled", Maintenance{}, "Delete", "DeleteRequest", `{"table":"users"}`, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newGRPCTestServer(&auth.Claims{APIKeyScope: auth.ScopeFullAccess})
+			srv.SetMaintenance(func() Maintenance { return tt.state })
+			conn := dialGRPC(t, srv)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testAPIKey)
+			var header metadata.MD
+			var out structpb.Struct
+			err := conn.Invoke(ctx, "/"+GRPCServiceName+"/"+tt.method, grpcRequest(t, tt.msg, tt.body), &out, grpc.Header(&header))
+			testutil.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.Unavailable {
+				testutil.Equal(t, "service is under maintenance", status.Convert(err).Message())
+				testutil.SliceLen(t, header.Get("retry-after"), 1)
+				testutil.Equal(t, "60", header.Get("retry-after")[0])
+			}
+		})
+	}
+}
+
+func TestGRPCStatementTimeout(t *testing.T) {
+	t.Parallel()
+	srv := newGRPCTestServer(&auth.Claims{APIKeyScope: auth.ScopeFullAccess})
+	srv.SetStatementTimeout(5 * time.Second)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+testAPIKey))
+	var got time.Duration
+	_, err := srv.authenticate(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		got = StatementTimeout(ctx)
+		return nil, nil
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, 5*time.Second, got)
+}
+
+func TestListResponseMessage(t *testing.T) {
+	t.Parallel()
+	msg, err := listResponseMessage(&ListResponse{
+		Page: 2, PerPage: 10, TotalItems: 11, TotalPages: 2,
+		Items: []map[string]any{{"id": "a", "n": 1}, {"id": "b", "tags": []any{"x"}}},
+	})
+	testutil.NoError(t, err)
+
+	js, err := protojson.Marshal(msg)
+	testutil.NoError(t, err)
+	var got struct {
+		Page       int              `json:"page"`
+		PerPage    int              `json:"perPage"`
+		TotalItems string           `json:"totalItems"` // int64 is a JSON string in protojson
+		Items      []map[string]any `json:"items"`
+	}
+	testutil.NoError(t, json.Unmarshal(js, &got))
+	testutil.Equal(t, 2, got.Page)
+	testutil.Equal(t, 10, got.PerPage)
+	testutil.Equal(t, "11", got.TotalItems)
+	testutil.SliceLen(t, got.Items, 2)
+	testutil.Equal(t, "a", got.Items[0]["id"])
+	testutil.Equal(t, 1.0, got.Items[0]["n"])
+	testutil.Equal(t, "x", got.Items[1]["tags"].([]any)[0])
+}
+
+func TestProtoJSONName(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "table", protoJSONName("table"))
+	testutil.Equal(t, "skipTotal", protoJSONName("skip_total"))
+	testutil.Equal(t, "totalPages", protoJSONName("total_pages"))
+}
@@ -3,17 +3,20 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/realtime"
 	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -39,8 +42,33 @@ type Handler struct {
 	logger     *slog.Logger
 	hub        *realtime.Hub // nil when realtime is unused
 	dispatcher EventSink     // nil when webhooks are unused
+	txWrites   bool          // run every write in a transaction, even without claims
+	pageSize   PageSize
+	tablePages map[string]PageSize // per-table overrides, keyed by table name
+	idemTTL    time.Duration       // how long Idempotency-Key responses are kept; 0 ignores the header
+	checkRefs  bool                // look up foreign key references before writing
+
+	slowQueryMin time.Duration // list queries this slow are logged; 0 disables
+	slowQueries  slowQueryLog
+
+	autoTimestamps   bool            // set created_at and updated_at on writes
+	manualTimestamps map[string]bool // tables left out of autoTimestamps, keyed by name
+
+	hooks         hookRegistry
+	fieldRules    map[string]map[string]FieldRule    // keyed by table, then column
+	managedFields map[string]map[string]ManagedField // keyed by table, then column
 }
 
+// PageSize bounds perPage on list requests: Default applies when perPage is
+// omitted, and larger values are clamped to Max.
+type PageSize struct {
+	Default int
+	Max     int
+}
+
+// defaultPageSize applies until SetPageSizes is called.
+var defaultPageSize = PageSize{Default: 20, Max: 500}
+
 // NewHandler creates a new API handler.
 func NewHandler(pool *pgxpool.Pool, schemaCache *schema.CacheHolder, logger *slog.Logger, hub *realtime.Hub, dispatcher EventSink) *Handler {
 	return &Handler{
@@ -49,7 +77,49 @@ func NewHandler(pool *pgxpool.Pool, schemaCache *schema.CacheHolder, logger *slo
 		logger:     logger,
 		hub:        hub,
 		dispatcher: dispatcher,
+		pageSize:   defaultPageSize,
+	}
+}
+
+// SetTransactionalWrites makes every mutating request run in a single
+// transaction, even when no JWT claims are present, so a failure at any point
+// up to and including commit leaves no partial writes.
+func (h *Handler) SetTransactionalWrites(on bool) {
+	h.txWrites = on
+}
+
+// SetIdempotencyKeyTTL enables the Idempotency-Key header on create and batch
+// requests, keeping each key's response for ttl. Zero disables it.
+func (h *Handler) SetIdempotencyKeyTTL(ttl time.Duration) {
+	h.idemTTL = ttl
+}
+
+// SetPageSizes sets the list page sizes. Zero fields in global keep the
+// built-in defaults, and zero fields in a table override fall back to global.
+func (h *Handler) SetPageSizes(global PageSize, tables map[string]PageSize) {
+	h.pageSize = defaultPageSize
+	h.pageSize = h.withOverride(global)
+	h.tablePages = tables
+}
+
+// pageSizeFor returns the effective page sizes for table. The default never
+// exceeds the maximum.
+func (h *Handler) pageSizeFor(table string) PageSize {
+	ps := h.withOverride(h.tablePages[table])
+	ps.Default = min(ps.Default, ps.Max)
+	return ps
+}
+
+// withOverride returns h.pageSize with the non-zero fields of o applied.
+func (h *Handler) withOverride(o PageSize) PageSize {
+	ps := h.pageSize
+	if o.Default > 0 {
+		ps.Default = o.Default
+	}
+	if o.Max > 0 {
+		ps.Max = o.Max
 	}
+	return ps
 }
 
 // API limits to prevent abuse and overflow.
@@ -67,8 +137,11 @@ func (h *Handler) Routes() chi.Router {
 
 	r.Route("/collections/{table}", func(r chi.Router) {
 		r.Get("/", h.handleList)
-		r.Post("/", h.handleCreate)
-		r.Post("/batch", h.handleBatch)
+		r.With(h.idempotent).Post("/", h.handleCreate)
+		r.Patch("/", h.handleBulkUpdate)
+		r.Delete("/", h.handleBulkDelete)
+		r.With(h.idempotent).Post("/batch", h.handleBatch)
+		r.Get("/schema", h.handleSchema)
 		r.Get("/{id}", h.handleRead)
 		r.Patch("/{id}", h.handleUpdate)
 		r.Delete("/{id}", h.handleDelete)
@@ -82,38 +155,74 @@ func (h *Handler) Routes() chi.Router {
 // withRLS returns a Querier for executing database operations. When JWT claims
 // are present in the request context, it begins a transaction, sets RLS session
 // variables, and returns the tx. The caller must invoke the returned cleanup
-// function when done (commits the tx on success, rolls back on error).
+// function when done: it rolls back and returns the given error if non-nil,
+// otherwise commits and returns any commit error.
 // When no claims are present, returns the pool directly with a no-op cleanup.
-func (h *Handler) withRLS(r *http.Request) (Querier, func(error), error) {
-	claims := auth.ClaimsFromContext(r.Context())
-	if claims == nil {
-		return h.pool, func(error) {}, nil
+func (h *Handler) withRLS(r *http.Request) (Querier, func(error) error, error) {
+	return h.withRLSContext(r.Context())
+}
+
+// withRLSContext is withRLS for callers without an *http.Request (e.g. the
+// gRPC gateway); claims are read from ctx. A statement timeout set with
+// WithStatementTimeout also needs a transaction to apply to.
+func (h *Handler) withRLSContext(ctx context.Context) (Querier, func(error) error, error) {
+	if auth.ClaimsFromContext(ctx) == nil && StatementTimeout(ctx) == 0 && tenant.FromContext(ctx) == "" {
+		return h.pool, func(err error) error { return err }, nil
+	}
+	return h.withTx(ctx)
+}
+
+// withWrite is withRLS for mutating requests on tbl. With transactional
+// writes enabled, or hooks registered on tbl, the request always runs in a
+// transaction, claims or not.
+func (h *Handler) withWrite(ctx context.Context, tbl *schema.Table) (Querier, func(error) error, error) {
+	if h.txWrites || h.hooks.has(tbl.Name, "") {
+		return h.withTx(ctx)
 	}
+	return h.withRLSContext(ctx)
+}
 
-	tx, err := h.pool.Begin(r.Context())
+// withTx begins a transaction and sets the statement timeout and, when JWT
+// claims are present, the RLS context and tenant search_path once for
+// everything that runs in it.
+// The settings are transaction-local, so they end with the commit or
+// rollback. The cleanup function behaves as for withRLS.
+func (h *Handler) withTx(ctx context.Context) (Querier, func(error) error, error) {
+	tx, err := h.pool.Begin(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if err := auth.SetRLSContext(r.Context(), tx, claims); err != nil {
-		_ = tx.Rollback(r.Context())
+	if err := setStatementTimeout(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, nil, err
+	}
+	if err := auth.SetRLSContext(ctx, tx, auth.ClaimsFromContext(ctx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, nil, err
+	}
+	if err := tenant.SetSearchPath(ctx, tx); err != nil {
+		_ = tx.Rollback(ctx)
 		return nil, nil, err
 	}
 
-	done := func(queryErr error) {
+	done := func(queryErr error) error {
 		if queryErr != nil {
-			_ = tx.Rollback(r.Context())
-		} else {
-			if err := tx.Commit(r.Context()); err != nil {
-				h.logger.Error("tx commit failed", "error", err)
-			}
+			_ = tx.Rollback(ctx)
+			return queryErr
+		}
+		if err := tx.Commit(ctx); err != nil {
+			h.logger.Error("tx commit failed", "error", err)
+			return err
 		}
+		return nil
 	}
 	return tx, done, nil
 }
 
 // resolveTable looks up the table in the schema cache, validates it exists,
-// and checks API key table scope restrictions.
+// and checks API key table scope restrictions. In schema-per-tenant mode the
+// table is redirected to the request's tenant schema.
 func (h *Handler) resolveTable(w http.ResponseWriter, r *http.Request) *schema.Table {
 	sc := h.schema.Get()
 	if sc == nil {
@@ -134,7 +243,7 @@ func (h *Handler) resolveTable(w http.ResponseWriter, r *http.Request) *schema.T
 		return nil
 	}
 
-	return tbl
+	return tenant.Table(r.Context(), tbl)
 }
 
 // requireWriteScope checks that the current API key scope permits write operations.
@@ -148,13 +257,20 @@ func requireWriteScope(w http.ResponseWriter, r *http.Request) bool {
 
 // requireWritable checks that the table supports write operations (not a view).
 func requireWritable(w http.ResponseWriter, tbl *schema.Table) bool {
-	if tbl.Kind != "table" && tbl.Kind != "partitioned_table" {
+	if !isWritable(tbl) {
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
 		writeError(w, http.StatusMethodNotAllowed, "write operations not allowed on "+tbl.Kind)
 		return false
 	}
 	return true
 }
 
+// isWritable reports whether tbl accepts INSERT/UPDATE/DELETE (views and
+// materialized views do not).
+func isWritable(tbl *schema.Table) bool {
+	return tbl.Kind == "table" || tbl.Kind == "partitioned_table"
+}
+
 // requirePK checks that the table has a primary key for write operations.
 func requirePK(w http.ResponseWriter, tbl *schema.Table) bool {
 	if len(tbl.PrimaryKey) == 0 {
@@ -240,12 +356,16 @@ func (h *Handler) handleRead(w http.ResponseWriter, r *http.Request) {
 }
 
 // decodeAndValidateBody reads, decodes, and validates a JSON request body against the table schema.
+// update is true for updates, which may use JSON path keys and must carry the
+// expected version on versioned tables.
 // Returns the decoded data and true on success. On failure, writes an error response and returns nil, false.
-func decodeAndValidateBody(w http.ResponseWriter, r *http.Request, tbl *schema.Table) (map[string]any, bool) {
-	r.Body = http.MaxBytesReader(w, r.Body, httputil.MaxBodySize)
+func (h *Handler) decodeAndValidateBody(w http.ResponseWriter, r *http.Request, tbl *schema.Table, update bool) (map[string]any, bool) {
+	if !httputil.LimitBody(w, r) {
+		return nil, false
+	}
 	var data map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		httputil.WriteDecodeError(w, err)
 		return nil, false
 	}
 
@@ -254,11 +374,28 @@ func decodeAndValidateBody(w http.ResponseWriter, r *http.Request, tbl *schema.T
 		return nil, false
 	}
 
+	h.dropManagedValues(r.Context(), tbl, data)
 	if countKnownColumns(tbl, data) == 0 {
 		writeError(w, http.StatusBadRequest, "no recognized columns in request body")
 		return nil, false
 	}
 
+	if err := prepareRecord(tbl, data, update); err != nil {
+		var ce *coerceError
+		if errors.As(err, &ce) {
+			writeFieldErrorWithDocURL(w, http.StatusBadRequest, "invalid value", ce.column,
+				"invalid_type", ce.message, docURL("/guide/api-reference#error-format"))
+			return nil, false
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	if ferr := h.checkFieldRules(tbl, data); ferr != nil {
+		writeFieldRuleError(w, "validation failed", ferr)
+		return nil, false
+	}
+
 	return data, true
 }
 
@@ -275,44 +412,46 @@ func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, ok := decodeAndValidateBody(w, r, tbl)
+	data, ok := h.decodeAndValidateBody(w, r, tbl, false)
 	if !ok {
 		return
 	}
+	h.setServerValues(r.Context(), tbl, data, false)
 
-	query, args := buildInsert(tbl, data)
-
-	q, done, err := h.withRLS(r)
+	q, done, err := h.withWrite(r.Context(), tbl)
 	if err != nil {
 		h.logger.Error("rls setup error", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	rows, err := q.Query(r.Context(), query, args...)
-	if err != nil {
+	if err := h.checkReferences(r.Context(), q, tbl, data); err != nil {
 		done(err)
 		if !mapPGError(w, err) {
-			h.logger.Error("insert error", "error", err, "table", tbl.Name)
+			h.logger.Error("reference check error", "error", err, "table", tbl.Name)
 			writeError(w, http.StatusInternalServerError, "internal error")
 		}
 		return
 	}
 
-	record, err := scanRow(rows)
-	rows.Close() // Close before done() to avoid pgx "conn busy" on commit.
+	record, err := h.insertRecord(r.Context(), q, tbl, data)
 	if err != nil {
 		done(err)
 		if !mapPGError(w, err) {
-			h.logger.Error("scan error", "error", err, "table", tbl.Name)
+			h.logger.Error("insert error", "error", err, "table", tbl.Name)
 			writeError(w, http.StatusInternalServerError, "internal error")
 		}
 		return
 	}
 
-	done(nil)
-	writeJSON(w, http.StatusCreated, record)
-	h.publishEvent("create", tbl.Name, record)
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	writeRecord(w, r, http.StatusCreated, record)
+	h.publishEvent(r.Context(), "create", tbl.Name, record)
 }
 
 // handleUpdate handles PATCH /collections/{table}/{id}
@@ -336,36 +475,33 @@ func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, ok := decodeAndValidateBody(w, r, tbl)
+	data, ok := h.decodeAndValidateBody(w, r, tbl, true)
 	if !ok {
 		return
 	}
+	h.setServerValues(r.Context(), tbl, data, true)
 
-	query, args := buildUpdate(tbl, data, pkValues)
-
-	q, done, err := h.withRLS(r)
+	q, done, err := h.withWrite(r.Context(), tbl)
 	if err != nil {
 		h.logger.Error("rls setup error", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	rows, err := q.Query(r.Context(), query, args...)
-	if err != nil {
+	if err := h.checkReferences(r.Context(), q, tbl, data); err != nil {
 		done(err)
 		if !mapPGError(w, err) {
-			h.logger.Error("update error", "error", err, "table", tbl.Name)
+			h.logger.Error("reference check error", "error", err, "table", tbl.Name)
 			writeError(w, http.StatusInternalServerError, "internal error")
 		}
 		return
 	}
 
-	record, err := scanRow(rows)
-	rows.Close() // Close before done() to avoid pgx "conn busy" on commit.
+	record, err := h.updateRecord(r.Context(), q, tbl, data, pkValues)
 	if err != nil {
 		done(err)
 		if !mapPGError(w, err) {
-			h.logger.Error("scan error", "error", err, "table", tbl.Name)
+			h.logger.Error("update error", "error", err, "table", tbl.Name)
 			writeError(w, http.StatusInternalServerError, "internal error")
 		}
 		return
@@ -376,9 +512,14 @@ func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	done(nil)
-	writeJSON(w, http.StatusOK, record)
-	h.publishEvent("update", tbl.Name, record)
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+	writeRecord(w, r, http.StatusOK, record)
+	h.publishEvent(r.Context(), "update", tbl.Name, record)
 }
 
 // handleDelete handles DELETE /collections/{table}/{id}
@@ -402,16 +543,14 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query, args := buildDelete(tbl, pkValues)
-
-	q, done, err := h.withRLS(r)
+	q, done, err := h.withWrite(r.Context(), tbl)
 	if err != nil {
 		h.logger.Error("rls setup error", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	tag, err := q.Exec(r.Context(), query, args...)
+	record, err := h.deleteRecord(r.Context(), q, tbl, pkValues)
 	if err != nil {
 		done(err)
 		if !mapPGError(w, err) {
@@ -421,21 +560,22 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if tag.RowsAffected() == 0 {
+	if record == nil {
 		done(nil)
 		writeError(w, http.StatusNotFound, "record not found")
 		return
 	}
 
-	done(nil)
+	if err := done(nil); err != nil {
+		if !mapPGError(w, err) {
+			writeError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 
 	// Publish delete event with PK values.
-	record := make(map[string]any, len(tbl.PrimaryKey))
-	for i, pk := range tbl.PrimaryKey {
-		record[pk] = pkValues[i]
-	}
-	h.publishEvent("delete", tbl.Name, record)
+	h.publishEvent(r.Context(), "delete", tbl.Name, record)
 }
 
 // handleList handles GET /collections/{table}
@@ -445,149 +585,234 @@ func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	q := r.URL.Query()
+	opts, ok := h.listOptsFromRequest(w, r, tbl)
+	if !ok {
+		return
+	}
 
-	// Parse pagination.
-	page, _ := strconv.Atoi(q.Get("page"))
+	querier, done, err := h.withRLS(r)
+	if err != nil {
+		h.logger.Error("rls setup error", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	// HEAD reports the count in X-Total-Count without fetching the page.
+	if r.Method == http.MethodHead {
+		totalItems := -1
+		if !opts.skipTotal {
+			start := time.Now()
+			totalItems, err = countList(r.Context(), querier, tbl, opts)
+			h.observeList(tbl, opts, time.Since(start))
+		}
+		done(err)
+		if err != nil {
+			h.writeListError(w, tbl, err)
+			return
+		}
+		if totalItems >= 0 {
+			w.Header().Set("X-Total-Count", strconv.Itoa(totalItems))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	start := time.Now()
+	resp, err := fetchList(r.Context(), querier, tbl, opts)
+	h.observeList(tbl, opts, time.Since(start))
+	if err != nil {
+		done(err)
+		h.writeListError(w, tbl, err)
+		return
+	}
+
+	// Handle expand if requested.
+	if expandParam := r.URL.Query().Get("expand"); expandParam != "" && len(resp.Items) > 0 {
+		sc := h.schema.Get()
+		if sc != nil {
+			expandRecords(r.Context(), querier, sc, tbl, resp.Items, expandParam, h.logger)
+		}
+	}
+
+	done(nil)
+	if resp.TotalItems >= 0 {
+		w.Header().Set("X-Total-Count", strconv.Itoa(resp.TotalItems))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeListError writes the response for a failed list query.
+func (h *Handler) writeListError(w http.ResponseWriter, tbl *schema.Table, err error) {
+	if !mapPGError(w, err) {
+		h.logger.Error("list error", "error", err, "table", tbl.Name)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// listParams holds the caller-supplied list options shared by the REST and
+// gRPC list endpoints, before validation.
+type listParams struct {
+	page      int
+	perPage   int
+	skipTotal bool
+	fields    []string
+	sort      string
+	filter    string
+	search    string
+}
+
+// listParamError describes an invalid list parameter. docPath points at the
+// documentation section for that parameter.
+type listParamError struct {
+	message string
+	docPath string
+}
+
+// newListOpts validates list parameters and compiles the filter, search and
+// sort expressions. Pagination is clamped to the API limits and to the
+// table's page sizes.
+func newListOpts(tbl *schema.Table, sizes PageSize, p listParams) (listOpts, *listParamError) {
+	page := p.page
 	if page < 1 {
 		page = 1
 	}
 	if page > maxPage {
 		page = maxPage
 	}
-	perPage, _ := strconv.Atoi(q.Get("perPage"))
+	perPage := p.perPage
 	if perPage < 1 {
-		perPage = 20
+		perPage = sizes.Default
 	}
-	if perPage > 500 {
-		perPage = 500
+	if perPage > sizes.Max {
+		perPage = sizes.Max
 	}
-	skipTotal := q.Get("skipTotal") == "true"
-
-	// Parse fields.
-	fields := parseFields(r)
 
-	// Parse sort.
-	sortSQL := parseSortSQL(tbl, q.Get("sort"))
+	opts := listOpts{
+		page:      page,
+		perPage:   perPage,
+		skipTotal: p.skipTotal,
+		fields:    p.fields,
+		sortSQL:   parseSortSQL(tbl, p.sort),
+	}
 
-	// Parse filter.
-	var filterSQL string
-	var filterArgs []any
-	if filterStr := q.Get("filter"); filterStr != "" {
-		if len(filterStr) > maxFilterLen {
-			writeErrorWithDoc(w, http.StatusBadRequest, "filter expression too long", docURL("/guide/api-reference#filter-syntax"))
-			return
-		}
-		var err error
-		filterSQL, filterArgs, err = parseFilter(tbl, filterStr)
-		if err != nil {
-			writeErrorWithDoc(w, http.StatusBadRequest, "invalid filter: "+err.Error(), docURL("/guide/api-reference#filter-syntax"))
-			return
-		}
+	f, perr := compileFilterParam(tbl, p.filter)
+	if perr != nil {
+		return listOpts{}, perr
 	}
+	opts.filterSQL, opts.filterArgs, opts.filterColumns = f.sql, f.args, f.columns
 
-	// Parse search (full-text search).
-	var searchSQL, searchRank string
-	var searchArgs []any
-	if searchStr := strings.TrimSpace(q.Get("search")); searchStr != "" {
+	if searchStr := strings.TrimSpace(p.search); searchStr != "" {
 		if len(searchStr) > maxSearchLen {
-			writeErrorWithDoc(w, http.StatusBadRequest, "search term too long", docURL("/guide/api-reference#full-text-search"))
-			return
+			return listOpts{}, &listParamError{"search term too long", "/guide/api-reference#full-text-search"}
 		}
 		// Search arg index starts after all filter args.
-		argOffset := len(filterArgs) + 1
+		argOffset := len(opts.filterArgs) + 1
 		var err error
-		searchSQL, searchRank, searchArgs, err = buildSearchSQL(tbl, searchStr, argOffset)
+		opts.searchSQL, opts.searchRank, opts.searchArgs, err = buildSearchSQL(tbl, searchStr, argOffset)
 		if err != nil {
-			writeErrorWithDoc(w, http.StatusBadRequest, "search not supported: "+err.Error(), docURL("/guide/api-reference#full-text-search"))
-			return
+			return listOpts{}, &listParamError{"search not supported: " + err.Error(), "/guide/api-reference#full-text-search"}
 		}
 	}
 
-	opts := listOpts{
-		page:       page,
-		perPage:    perPage,
-		skipTotal:  skipTotal,
-		fields:     fields,
-		sortSQL:    sortSQL,
-		filterSQL:  filterSQL,
-		filterArgs: filterArgs,
-		searchSQL:  searchSQL,
-		searchRank: searchRank,
-		searchArgs: searchArgs,
-	}
-
-	dataQuery, dataArgs, countQuery, countArgs := buildList(tbl, opts)
+	return opts, nil
+}
 
-	querier, done, err := h.withRLS(r)
+// compileFilterParam validates and compiles a filter query parameter. An
+// empty filter compiles to an empty SQL condition.
+func compileFilterParam(tbl *schema.Table, filter string) (compiledFilter, *listParamError) {
+	if filter == "" {
+		return compiledFilter{}, nil
+	}
+	if len(filter) > maxFilterLen {
+		return compiledFilter{}, &listParamError{"filter expression too long", "/guide/api-reference#filter-syntax"}
+	}
+	f, err := compileFilter(tbl, filter)
 	if err != nil {
-		h.logger.Error("rls setup error", "error", err)
-		writeError(w, http.StatusInternalServerError, "internal error")
-		return
+		return compiledFilter{}, &listParamError{"invalid filter: " + err.Error(), "/guide/api-reference#filter-syntax"}
+	}
+	return f, nil
+}
+
+// listOptsFromRequest builds the list options from the query parameters of a
+// list request, writing a 400 response if they are invalid.
+func (h *Handler) listOptsFromRequest(w http.ResponseWriter, r *http.Request, tbl *schema.Table) (listOpts, bool) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	perPage, _ := strconv.Atoi(q.Get("perPage"))
+	opts, perr := newListOpts(tbl, h.pageSizeFor(tbl.Name), listParams{
+		page:      page,
+		perPage:   perPage,
+		skipTotal: q.Get("skipTotal") == "true" || q.Get("count") == "false",
+		fields:    parseFields(r),
+		sort:      q.Get("sort"),
+		filter:    q.Get("filter"),
+		search:    q.Get("search"),
+	})
+	if perr != nil {
+		writeErrorWithDoc(w, http.StatusBadRequest, perr.message, docURL(perr.docPath))
+		return listOpts{}, false
 	}
+	return opts, true
+}
 
-	// Get total count (unless skipTotal).
+// fetchList runs the count query (unless skipTotal) and the page query for a
+// list request. TotalItems and TotalPages are -1 when the count is skipped.
+func fetchList(ctx context.Context, q Querier, tbl *schema.Table, opts listOpts) (*ListResponse, error) {
 	totalItems := -1
 	totalPages := -1
-	if !skipTotal {
-		err := querier.QueryRow(r.Context(), countQuery, countArgs...).Scan(&totalItems)
-		if err != nil {
-			done(err)
-			h.logger.Error("count error", "error", err, "table", tbl.Name)
-			writeError(w, http.StatusInternalServerError, "internal error")
-			return
+	if !opts.skipTotal {
+		var err error
+		if totalItems, err = countList(ctx, q, tbl, opts); err != nil {
+			return nil, err
 		}
-		totalPages = int(math.Ceil(float64(totalItems) / float64(perPage)))
+		totalPages = int(math.Ceil(float64(totalItems) / float64(opts.perPage)))
 	}
 
-	// Get data rows.
-	rows, err := querier.Query(r.Context(), dataQuery, dataArgs...)
+	dataQuery, dataArgs, _, _ := buildList(tbl, opts)
+	rows, err := q.Query(ctx, dataQuery, dataArgs...)
 	if err != nil {
-		done(err)
-		if !mapPGError(w, err) {
-			h.logger.Error("list error", "error", err, "table", tbl.Name)
-			writeError(w, http.StatusInternalServerError, "internal error")
-		}
-		return
+		return nil, err
 	}
-
 	items, err := scanRows(rows)
-	rows.Close() // Close before done() to avoid pgx "conn busy" on commit.
+	rows.Close() // Close before the caller commits to avoid pgx "conn busy".
 	if err != nil {
-		done(err)
-		h.logger.Error("scan error", "error", err, "table", tbl.Name)
-		writeError(w, http.StatusInternalServerError, "internal error")
-		return
-	}
-
-	// Handle expand if requested.
-	if expandParam := q.Get("expand"); expandParam != "" && len(items) > 0 {
-		sc := h.schema.Get()
-		if sc != nil {
-			expandRecords(r.Context(), querier, sc, tbl, items, expandParam, h.logger)
-		}
+		return nil, err
 	}
 
-	done(nil)
-	writeJSON(w, http.StatusOK, ListResponse{
-		Page:       page,
-		PerPage:    perPage,
+	return &ListResponse{
+		Page:       opts.page,
+		PerPage:    opts.perPage,
 		TotalItems: totalItems,
 		TotalPages: totalPages,
 		Items:      items,
-	})
+	}, nil
 }
 
-// publishEvent sends a realtime event to the hub and webhook dispatcher.
-func (h *Handler) publishEvent(action, table string, record map[string]any) {
-	if h.hub == nil && h.dispatcher == nil {
-		return
+// countList returns the number of rows matching a list request's filter and
+// search.
+func countList(ctx context.Context, q Querier, tbl *schema.Table, opts listOpts) (int, error) {
+	_, _, countQuery, countArgs := buildList(tbl, opts)
+	var total int
+	if err := q.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("counting rows: %w", err)
 	}
-	event := &realtime.Event{
+	return total, nil
+}
+
+// publishEvent sends a realtime event for a change made in ctx's tenant, if
+// any, to the hub and webhook dispatcher.
+func (h *Handler) publishEvent(ctx context.Context, action, table string, record map[string]any) {
+	h.publish(&realtime.Event{
 		Action: action,
 		Table:  table,
 		Record: record,
-	}
+		Tenant: tenant.FromContext(ctx),
+	})
+}
+
+// publish sends event to the hub and webhook dispatcher.
+func (h *Handler) publish(event *realtime.Event) {
 	if h.hub != nil {
 		h.hub.Publish(event)
 	}
@@ -596,10 +821,12 @@ func (h *Handler) publishEvent(action, table string, record map[string]any) {
 	}
 }
 
-// countKnownColumns returns the number of keys in data that match a column in the table schema.
+// countKnownColumns returns the number of keys in data that match a column in
+// the table schema, directly or as the column of a JSON path key.
 func countKnownColumns(tbl *schema.Table, data map[string]any) int {
 	n := 0
-	for col := range data {
+	for key := range data {
+		col, _, _ := parseJSONPatchKey(key)
 		if tbl.ColumnByName(col) != nil {
 			n++
 		}
@@ -73,11 +73,11 @@ func doRequest(handler http.Handler, method, path string, body string) *httptest
 	return w
 }
 
-func decodeError(t *testing.T, w *httptest.ResponseRecorder) httputil.ErrorResponse {
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) httputil.ErrorBody {
 	t.Helper()
 	var resp httputil.ErrorResponse
 	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
-	return resp
+	return resp.Error
 }
 
 // --- Schema not ready ---
@@ -154,6 +154,7 @@ func TestDeleteOnViewNotAllowed(t *testing.T) {
 	h := testHandler(testSchema())
 	w := doRequest(h, "DELETE", "/collections/logs/1", "")
 	testutil.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
 	resp := decodeError(t, w)
 	testutil.Contains(t, resp.Message, "write operations not allowed")
 }
@@ -207,6 +208,26 @@ func TestCreateInvalidJSON(t *testing.T) {
 	testutil.Contains(t, resp.Message, "invalid JSON body")
 }
 
+func TestCreateBodyTooLarge(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+	body := `{"email":"` + strings.Repeat("x", httputil.MaxBodySize) + `"}`
+
+	// Both a declared and an unknown length over server.body_limit are refused.
+	for _, path := range []string{"/collections/users", "/collections/users/batch"} {
+		w := doRequest(h, "POST", path, body)
+		testutil.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		r := httptest.NewRequest("POST", path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.ContentLength = -1
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		testutil.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		testutil.Contains(t, decodeError(t, w).Message, "request body too large")
+	}
+}
+
 func TestCreateNoRecognizedColumns(t *testing.T) {
 	t.Parallel()
 	h := testHandler(testSchema())
@@ -216,6 +237,32 @@ func TestCreateNoRecognizedColumns(t *testing.T) {
 	testutil.Contains(t, resp.Message, "no recognized columns")
 }
 
+func TestCreateInvalidColumnValue(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+	w := doRequest(h, "POST", "/collections/users", `{"id":"not-a-uuid","email":"a@example.com"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	resp := decodeError(t, w)
+	testutil.Equal(t, httputil.CodeValidationFailed, resp.Code)
+	testutil.Equal(t, "invalid value", resp.Message)
+	field, ok := resp.Details["id"].(map[string]any)
+	testutil.True(t, ok, "expected field error for id")
+	testutil.Equal(t, "invalid_type", field["code"].(string))
+	testutil.Contains(t, field["message"].(string), "is not a valid UUID")
+}
+
+func TestJSONPathKeyOnlyOnJSONBUpdate(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+	w := doRequest(h, "POST", "/collections/users", `{"email->x":"a"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "only supported on update")
+
+	w = doRequest(h, "PATCH", "/collections/users/123", `{"email->x":"a"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "is not jsonb")
+}
+
 func TestUpdateEmptyBody(t *testing.T) {
 	t.Parallel()
 	h := testHandler(testSchema())
@@ -311,8 +358,8 @@ func TestErrorResponseIsJSON(t *testing.T) {
 	var resp httputil.ErrorResponse
 	err := json.NewDecoder(w.Body).Decode(&resp)
 	testutil.NoError(t, err)
-	testutil.Equal(t, http.StatusNotFound, resp.Code)
-	testutil.Contains(t, resp.Message, "not found")
+	testutil.Equal(t, httputil.CodeNotFound, resp.Error.Code)
+	testutil.Contains(t, resp.Error.Message, "not found")
 }
 
 // --- API key scope enforcement ---
@@ -454,6 +501,43 @@ func TestParseSortSQLMaxFieldsLimit(t *testing.T) {
 	testutil.Equal(t, maxSortFields, clauseCount)
 }
 
+func TestPageSizeFor(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	testutil.Equal(t, PageSize{Default: 20, Max: 500}, h.pageSizeFor("posts"))
+
+	h.SetPageSizes(PageSize{Default: 25, Max: 100}, map[string]PageSize{
+		"events": {Default: 200, Max: 2000},
+		"logs":   {Max: 10},
+	})
+	testutil.Equal(t, PageSize{Default: 25, Max: 100}, h.pageSizeFor("posts"))
+	testutil.Equal(t, PageSize{Default: 200, Max: 2000}, h.pageSizeFor("events"))
+	// The default never exceeds a lower table max.
+	testutil.Equal(t, PageSize{Default: 10, Max: 10}, h.pageSizeFor("logs"))
+
+	// Zero global fields keep the built-in defaults.
+	h.SetPageSizes(PageSize{Max: 50}, nil)
+	testutil.Equal(t, PageSize{Default: 20, Max: 50}, h.pageSizeFor("posts"))
+}
+
+func TestNewListOptsPageSize(t *testing.T) {
+	t.Parallel()
+	sizes := PageSize{Default: 25, Max: 100}
+	for _, tc := range []struct {
+		perPage, want int
+	}{
+		{0, 25},
+		{-3, 25},
+		{40, 40},
+		{100, 100},
+		{10000, 100},
+	} {
+		opts, perr := newListOpts(testTable(), sizes, listParams{perPage: tc.perPage})
+		testutil.Nil(t, perr)
+		testutil.Equal(t, tc.want, opts.perPage)
+	}
+}
+
 // maxPage clamping is exercised via integration tests.
 // The constant-arithmetic assertions that were here tested nothing behavioral.
 
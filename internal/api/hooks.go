@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// HookTiming says whether a hook runs before or after the write.
+type HookTiming string
+
+const (
+	HookBefore HookTiming = "before"
+	HookAfter  HookTiming = "after"
+)
+
+// Hook actions, matching the realtime event actions.
+const (
+	HookCreate = "create"
+	HookUpdate = "update"
+	HookDelete = "delete"
+)
+
+// HookEvent describes the write a hook is called for.
+type HookEvent struct {
+	Table  string
+	Action string // HookCreate, HookUpdate or HookDelete
+
+	// Key holds the primary key values of the record being updated or
+	// deleted, keyed by column. It is nil for creates.
+	Key map[string]any
+
+	// Record is the request body in before-create and before-update hooks,
+	// which may modify it; the written row in after-create and after-update
+	// hooks; and the primary key values in delete hooks.
+	Record map[string]any
+
+	// Tx is the request's transaction. Writes made through it commit or
+	// roll back together with the record.
+	Tx Querier
+}
+
+// HookFunc is a table hook. Returning an error aborts the write and rolls
+// back the transaction: a *HookError sets the response status, PostgreSQL
+// errors are reported as for the write itself, and any other error is a
+// 400 with the error's message.
+type HookFunc func(ctx context.Context, e *HookEvent) error
+
+// HookError rejects a write from a hook with a 4xx status.
+type HookError struct {
+	Status  int
+	Message string
+}
+
+func (e *HookError) Error() string { return e.Message }
+
+type hookKey struct {
+	timing HookTiming
+	action string
+	table  string
+}
+
+// hookRegistry holds the hooks added with Handler.AddHook.
+type hookRegistry struct {
+	mu     sync.RWMutex
+	hooks  map[hookKey][]HookFunc
+	tables map[string]map[string]bool // table -> actions with hooks
+}
+
+// AddHook registers fn to run on table before or after each create, update
+// or delete made through the collections API, batch requests and gRPC.
+// Hooks run in registration order, inside the request's transaction.
+// Filtered bulk writes are rejected on tables with hooks for the action.
+func (h *Handler) AddHook(timing HookTiming, action, table string, fn HookFunc) {
+	r := &h.hooks
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hooks == nil {
+		r.hooks = make(map[hookKey][]HookFunc)
+		r.tables = make(map[string]map[string]bool)
+	}
+	k := hookKey{timing, action, table}
+	r.hooks[k] = append(r.hooks[k], fn)
+	if r.tables[table] == nil {
+		r.tables[table] = make(map[string]bool)
+	}
+	r.tables[table][action] = true
+}
+
+// has reports whether table has hooks for action, or for any action when
+// action is "".
+func (r *hookRegistry) has(table, action string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if action == "" {
+		return len(r.tables[table]) > 0
+	}
+	return r.tables[table][action]
+}
+
+// run calls the hooks registered for timing, action and table in order,
+// stopping at the first error.
+func (r *hookRegistry) run(ctx context.Context, q Querier, timing HookTiming, action string, tbl *schema.Table, key, record map[string]any) error {
+	r.mu.RLock()
+	fns := r.hooks[hookKey{timing, action, tbl.Name}]
+	r.mu.RUnlock()
+	if len(fns) == 0 {
+		return nil
+	}
+
+	e := &HookEvent{Table: tbl.Name, Action: action, Key: key, Record: record, Tx: q}
+	for _, fn := range fns {
+		if err := fn(ctx, e); err != nil {
+			return hookErr(err)
+		}
+	}
+	return nil
+}
+
+// hookErr normalizes an error returned by a hook: PostgreSQL errors and
+// *HookErrors with a 4xx status pass through, and anything else becomes a
+// 400 with the error's message.
+func hookErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return err
+	}
+	var he *HookError
+	if errors.As(err, &he) && he.Status >= 400 && he.Status < 500 {
+		return he
+	}
+	return &HookError{Status: http.StatusBadRequest, Message: err.Error()}
+}
+
+// pkRecord returns pkValues keyed by primary key column.
+func pkRecord(tbl *schema.Table, pkValues []string) map[string]any {
+	record := make(map[string]any, len(tbl.PrimaryKey))
+	for i, col := range tbl.PrimaryKey {
+		record[col] = pkValues[i]
+	}
+	return record
+}
+
+// insertRecord inserts data into tbl, running the table's create hooks
+// around the insert.
+func (h *Handler) insertRecord(ctx context.Context, q Querier, tbl *schema.Table, data map[string]any) (map[string]any, error) {
+	if err := h.hooks.run(ctx, q, HookBefore, HookCreate, tbl, nil, data); err != nil {
+		return nil, err
+	}
+	query, args := buildInsert(tbl, data)
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	record, err := scanRow(rows)
+	rows.Close() // Close before the next query to avoid pgx "conn busy".
+	if err != nil {
+		return nil, err
+	}
+	if err := h.hooks.run(ctx, q, HookAfter, HookCreate, tbl, nil, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// updateRecord applies data to the record of tbl identified by pkValues,
+// running the table's update hooks around the update. It returns nil if
+// there is no such record.
+func (h *Handler) updateRecord(ctx context.Context, q Querier, tbl *schema.Table, data map[string]any, pkValues []string) (map[string]any, error) {
+	key := pkRecord(tbl, pkValues)
+	if err := h.hooks.run(ctx, q, HookBefore, HookUpdate, tbl, key, data); err != nil {
+		return nil, err
+	}
+	record, err := execUpdate(ctx, q, tbl, data, pkValues)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if err := h.hooks.run(ctx, q, HookAfter, HookUpdate, tbl, key, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// deleteRecord deletes the record of tbl identified by pkValues, running the
+// table's delete hooks around the delete. It returns the deleted record's
+// primary key values, or nil if there is no such record.
+func (h *Handler) deleteRecord(ctx context.Context, q Querier, tbl *schema.Table, pkValues []string) (map[string]any, error) {
+	key := pkRecord(tbl, pkValues)
+	if err := h.hooks.run(ctx, q, HookBefore, HookDelete, tbl, key, key); err != nil {
+		return nil, err
+	}
+	query, args := buildDelete(tbl, pkValues)
+	tag, err := q.Exec(ctx, query, args...)
+	if err != nil || tag.RowsAffected() == 0 {
+		return nil, err
+	}
+	if err := h.hooks.run(ctx, q, HookAfter, HookDelete, tbl, key, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHookErrNormalizes(t *testing.T) {
+	t.Parallel()
+
+	var he *HookError
+	testutil.True(t, errors.As(hookErr(errors.New("title is required")), &he))
+	testutil.Equal(t, http.StatusBadRequest, he.Status)
+	testutil.Equal(t, "title is required", he.Message)
+
+	testutil.True(t, errors.As(hookErr(&HookError{Status: http.StatusForbidden, Message: "locked"}), &he))
+	testutil.Equal(t, http.StatusForbidden, he.Status)
+
+	// Only 4xx statuses are honored.
+	testutil.True(t, errors.As(hookErr(&HookError{Status: http.StatusInternalServerError, Message: "x"}), &he))
+	testutil.Equal(t, http.StatusBadRequest, he.Status)
+
+	// PostgreSQL errors are mapped as for the write itself.
+	pgErr := &pgconn.PgError{Code: "23505"}
+	testutil.True(t, hookErr(pgErr) == error(pgErr), "pg errors pass through")
+}
+
+func TestHookRegistryRunsInOrder(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	tbl := testTable()
+
+	var calls []string
+	h.AddHook(HookBefore, HookCreate, "users", func(_ context.Context, e *HookEvent) error {
+		calls = append(calls, "first")
+		e.Record["name"] = "set by hook"
+		return nil
+	})
+	h.AddHook(HookBefore, HookCreate, "users", func(_ context.Context, e *HookEvent) error {
+		calls = append(calls, "second:"+e.Record["name"].(string))
+		return &HookError{Status: http.StatusConflict, Message: "stop"}
+	})
+	h.AddHook(HookBefore, HookCreate, "users", func(context.Context, *HookEvent) error {
+		calls = append(calls, "third")
+		return nil
+	})
+
+	testutil.True(t, h.hooks.has("users", ""))
+	testutil.True(t, h.hooks.has("users", HookCreate))
+	testutil.False(t, h.hooks.has("users", HookDelete))
+	testutil.False(t, h.hooks.has("posts", ""))
+
+	err := h.hooks.run(context.Background(), nil, HookBefore, HookCreate, tbl, nil, map[string]any{})
+	testutil.ErrorContains(t, err, "stop")
+	testutil.SliceLen(t, calls, 2)
+	testutil.Equal(t, "second:set by hook", calls[1])
+
+	// Hooks for other timings and tables don't run.
+	testutil.NoError(t, h.hooks.run(context.Background(), nil, HookAfter, HookCreate, tbl, nil, map[string]any{}))
+	testutil.SliceLen(t, calls, 2)
+}
+
+func TestBulkWriteRejectedWithHooks(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, testCacheHolder(testSchema()), slog.Default(), nil, nil)
+	h.AddHook(HookAfter, HookDelete, "users", func(context.Context, *HookEvent) error { return nil })
+	routes := h.Routes()
+
+	w := doRequest(routes, "DELETE", "/collections/users?all=true", "")
+	testutil.Equal(t, http.StatusConflict, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "delete hooks")
+
+	// Updates have no hooks, so they reach filter validation.
+	w = doRequest(routes, "PATCH", "/collections/users", `{"name":"x"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHookErrorGRPCCode(t *testing.T) {
+	t.Parallel()
+	s := &GRPCServer{logger: slog.Default()}
+	err := s.queryError("insert error", &HookError{Status: http.StatusForbidden, Message: "no"}, testTable())
+	testutil.Equal(t, codes.PermissionDenied, status.Code(err))
+	testutil.Equal(t, "no", status.Convert(err).Message())
+
+	testutil.Equal(t, codes.InvalidArgument, hookCode(http.StatusBadRequest))
+	testutil.Equal(t, codes.Aborted, hookCode(http.StatusConflict))
+}
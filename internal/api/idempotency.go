@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxIdempotencyKeyLen bounds the Idempotency-Key header.
+const maxIdempotencyKeyLen = 255
+
+// storedResponse is an _ayb_idempotency_keys row claimed by an earlier
+// request. status is 0 while that request is still running.
+type storedResponse struct {
+	requestHash string
+	status      int
+	contentType string
+	body        []byte
+}
+
+// idempotent makes a create or batch request safe to retry. The first request
+// with an Idempotency-Key claims the key for the caller and runs; its response
+// is stored, and later requests with the same key and body replay it without
+// running again. Keys are scoped per user, so callers can't see each other's
+// responses. A 5xx response releases the key so the request can be retried.
+func (h *Handler) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || h.idemTTL <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeError(w, http.StatusBadRequest,
+				fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLen))
+			return
+		}
+
+		if !httputil.LimitBody(w, r) {
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if httputil.IsBodyTooLarge(err) {
+				httputil.WriteBodyTooLarge(w)
+				return
+			}
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := ""
+		if claims := auth.ClaimsFromContext(r.Context()); claims != nil {
+			userID = claims.Subject
+		}
+		hash := requestHash(r, body)
+
+		// The key outlives the request: a client that disconnects mid-request
+		// must not leave it claimed.
+		ctx := context.WithoutCancel(r.Context())
+		stored, err := h.claimIdempotencyKey(ctx, userID, key, hash)
+		if err != nil {
+			h.logger.Error("idempotency key claim error", "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if stored != nil {
+			switch {
+			case stored.requestHash != hash:
+				writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			case stored.status == 0:
+				writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+			default:
+				if stored.contentType != "" {
+					w.Header().Set("Content-Type", stored.contentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.status)
+				_, _ = w.Write(stored.body)
+			}
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if err := h.finishIdempotencyKey(ctx, userID, key, rec); err != nil {
+			h.logger.Error("idempotency key store error", "error", err)
+		}
+	})
+}
+
+// requestHash identifies a request by its method, path and body, so a key
+// reused for a different request is detected.
+func requestHash(r *http.Request, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// claimIdempotencyKey claims key for userID, replacing an expired claim. The
+// primary key makes concurrent retries race for a single row. It returns nil
+// when the caller now holds the key, or the row of the request that does.
+func (h *Handler) claimIdempotencyKey(ctx context.Context, userID, key, hash string) (*storedResponse, error) {
+	var claimed bool
+	err := h.pool.QueryRow(ctx,
+		`INSERT INTO _ayb_idempotency_keys (user_id, key, request_hash, expires_at)
+		 VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+		 ON CONFLICT (user_id, key) DO UPDATE
+		 SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = '',
+		     response = NULL, created_at = now(), expires_at = EXCLUDED.expires_at
+		 WHERE _ayb_idempotency_keys.expires_at <= now()
+		 RETURNING true`,
+		userID, key, hash, h.idemTTL.Seconds()).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("claiming idempotency key: %w", err)
+	}
+
+	var stored storedResponse
+	var status *int
+	err = h.pool.QueryRow(ctx,
+		`SELECT request_hash, status_code, content_type, COALESCE(response, '')
+		 FROM _ayb_idempotency_keys WHERE user_id = $1 AND key = $2`,
+		userID, key).Scan(&stored.requestHash, &status, &stored.contentType, &stored.body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released by a failed request since the claim; report it as in
+		// progress so the client retries.
+		return &storedResponse{requestHash: hash}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading idempotency key: %w", err)
+	}
+	if status != nil {
+		stored.status = *status
+	}
+	return &stored, nil
+}
+
+// finishIdempotencyKey stores the response for a claimed key, or releases
+// the key after a server error so a retry runs the request again.
+func (h *Handler) finishIdempotencyKey(ctx context.Context, userID, key string, rec *responseRecorder) error {
+	if rec.status >= http.StatusInternalServerError {
+		_, err := h.pool.Exec(ctx,
+			`DELETE FROM _ayb_idempotency_keys WHERE user_id = $1 AND key = $2`, userID, key)
+		return err
+	}
+	_, err := h.pool.Exec(ctx,
+		`UPDATE _ayb_idempotency_keys SET status_code = $3, content_type = $4, response = $5
+		 WHERE user_id = $1 AND key = $2`,
+		userID, key, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+	return err
+}
+
+// responseRecorder captures the status and body a handler writes while
+// passing them through.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestRequestHash(t *testing.T) {
+	t.Parallel()
+	req := func(path string) *http.Request { return httptest.NewRequest("POST", path, nil) }
+
+	h := requestHash(req("/collections/authors/"), []byte(`{"name":"a"}`))
+	testutil.Equal(t, h, requestHash(req("/collections/authors/"), []byte(`{"name":"a"}`)))
+	testutil.NotEqual(t, h, requestHash(req("/collections/authors/"), []byte(`{"name":"b"}`)))
+	testutil.NotEqual(t, h, requestHash(req("/collections/posts/"), []byte(`{"name":"a"}`)))
+}
+
+func TestIdempotencyKeyIgnoredWhenDisabled(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+
+	// With no TTL set the header is ignored and no store is needed.
+	r := httptest.NewRequest("POST", "/collections/nonexistent", strings.NewReader(`{"x":1}`))
+	r.Header.Set("Idempotency-Key", "k")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, testCacheHolder(testSchema()), testutil.DiscardLogger(), nil, nil)
+	h.SetIdempotencyKeyTTL(time.Hour)
+
+	r := httptest.NewRequest("POST", "/collections/users", strings.NewReader(`{"name":"a"}`))
+	r.Header.Set("Idempotency-Key", strings.Repeat("k", maxIdempotencyKeyLen+1))
+	w := httptest.NewRecorder()
+	h.Routes().ServeHTTP(w, r)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "Idempotency-Key must be at most")
+}
+
+func TestResponseRecorder(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	rec.WriteHeader(http.StatusCreated)
+	_, _ = rec.Write([]byte(`{"id":1}`))
+
+	testutil.Equal(t, http.StatusCreated, rec.status)
+	testutil.Equal(t, `{"id":1}`, rec.body.String())
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, `{"id":1}`, w.Body.String())
+}
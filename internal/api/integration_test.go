@@ -4,18 +4,29 @@ package api_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/allyourbase/ayb/internal/api"
 	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/migrations"
 	"github.com/allyourbase/ayb/internal/schema"
 	"github.com/allyourbase/ayb/internal/server"
 	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/google/uuid"
 )
 
 var sharedPG *testutil.PGContainer
@@ -120,6 +131,16 @@ func jsonNum(t *testing.T, v any) float64 {
 	return f
 }
 
+// parseError returns the body of an {"error": {...}} response.
+func parseError(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	body, ok := parseJSON(t, w)["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected error envelope, got: %s", w.Body.String())
+	}
+	return body
+}
+
 func jsonStr(t *testing.T, v any) string {
 	t.Helper()
 	s, ok := v.(string)
@@ -193,6 +214,39 @@ func TestListPaginationPage2(t *testing.T) {
 	testutil.Equal(t, "Bob Post", jsonStr(t, items[0]["title"]))
 }
 
+func TestListPageSizeLimits(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Server.MaxPageSize = 2
+	cfg.Server.TablePageSizes = map[string]config.TablePageSize{"authors": {DefaultPageSize: 1}}
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	// An oversized request is clamped to max_page_size.
+	w := doRequest(t, srv, "GET", "/api/collections/posts/?perPage=10000", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	body := parseJSON(t, w)
+	testutil.Equal(t, 2.0, jsonNum(t, body["perPage"]))
+	testutil.Equal(t, 2.0, jsonNum(t, body["totalPages"]))
+	testutil.Equal(t, 2, len(jsonItems(t, body)))
+
+	// Search is clamped the same way.
+	w = doRequest(t, srv, "GET", "/api/collections/posts/?search=post&perPage=10000", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 2.0, jsonNum(t, parseJSON(t, w)["perPage"]))
+
+	// A table's default_page_size applies when perPage is omitted.
+	w = doRequest(t, srv, "GET", "/api/collections/authors/", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	body = parseJSON(t, w)
+	testutil.Equal(t, 1.0, jsonNum(t, body["perPage"]))
+	testutil.Equal(t, 1, len(jsonItems(t, body)))
+}
+
 func TestListSkipTotal(t *testing.T) {
 	ctx := context.Background()
 	srv, _ := setupTestServer(t, ctx)
@@ -208,6 +262,85 @@ func TestListSkipTotal(t *testing.T) {
 	testutil.Equal(t, 3, len(items))
 }
 
+func TestListTotalCountHeader(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "GET", "/api/collections/posts/", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "3", w.Header().Get("X-Total-Count"))
+
+	// The count matches the filtered set, not the page.
+	w = doRequest(t, srv, "GET", "/api/collections/posts/?filter=status%3D'published'&perPage=1", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	body := parseJSON(t, w)
+	testutil.Equal(t, 2.0, jsonNum(t, body["totalItems"]))
+	testutil.Equal(t, 1, len(jsonItems(t, body)))
+
+	w = doRequest(t, srv, "GET", "/api/collections/posts/?filter=status%3D'published'&perPage=100", nil)
+	testutil.Equal(t, 2, len(jsonItems(t, parseJSON(t, w))))
+}
+
+func TestListGzipEncoded(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx,
+		`INSERT INTO posts (title, body) SELECT 'Post ' || i, repeat('body text ', 20) FROM generate_series(1, 100) i`)
+	testutil.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/collections/posts/?perPage=100", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	testutil.Equal(t, "103", w.Header().Get("X-Total-Count"))
+
+	zr, err := gzip.NewReader(w.Body)
+	testutil.NoError(t, err)
+	var body map[string]any
+	testutil.NoError(t, json.NewDecoder(zr).Decode(&body))
+	testutil.Equal(t, 100, len(jsonItems(t, body)))
+}
+
+func TestListCountFalse(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "GET", "/api/collections/posts/?count=false", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "", w.Header().Get("X-Total-Count"))
+
+	body := parseJSON(t, w)
+	testutil.Equal(t, -1.0, jsonNum(t, body["totalItems"]))
+	testutil.Equal(t, 3, len(jsonItems(t, body)))
+}
+
+func TestListHead(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "HEAD", "/api/collections/posts/?filter=status%3D'published'", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	testutil.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	testutil.Equal(t, 0, w.Body.Len())
+
+	w = doRequest(t, srv, "HEAD", "/api/collections/posts/?count=false", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "", w.Header().Get("X-Total-Count"))
+
+	w = doRequest(t, srv, "OPTIONS", "/api/collections/posts/", nil)
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, "GET, HEAD, POST, PATCH, DELETE, OPTIONS", w.Header().Get("Allow"))
+
+	w = doRequest(t, srv, "PUT", "/api/collections/posts/1", nil)
+	testutil.StatusCode(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, PATCH, DELETE, OPTIONS", w.Header().Get("Allow"))
+}
+
 func TestListWithSort(t *testing.T) {
 	ctx := context.Background()
 	srv, _ := setupTestServer(t, ctx)
@@ -258,6 +391,35 @@ func TestListWithFilter(t *testing.T) {
 	}
 }
 
+func TestTypedValueCoercion(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	// Strings are coerced to the column type in filters and bodies.
+	w := doRequest(t, srv, "GET", "/api/collections/posts/?filter=author_id%3D'2'", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 1, len(jsonItems(t, parseJSON(t, w))))
+
+	w = doRequest(t, srv, "GET", "/api/collections/posts/?filter=created_at+gt+'2000-01-01T00:00:00Z'", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 3, len(jsonItems(t, parseJSON(t, w))))
+
+	w = doRequest(t, srv, "POST", "/api/collections/posts/",
+		map[string]any{"title": "Typed", "author_id": "2", "created_at": "2026-01-02T03:04:05Z"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, 2.0, jsonNum(t, parseJSON(t, w)["author_id"]))
+
+	// Bad values are a 400 naming the column, not a database error.
+	w = doRequest(t, srv, "GET", "/api/collections/posts/?filter=author_id%3D'abc'", nil)
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "invalid value for column author_id")
+
+	w = doRequest(t, srv, "POST", "/api/collections/posts/",
+		map[string]any{"title": "Bad", "created_at": "yesterday"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "not a valid ISO 8601 date or timestamp")
+}
+
 func TestListWithFilterAnd(t *testing.T) {
 	ctx := context.Background()
 	srv, _ := setupTestServer(t, ctx)
@@ -303,6 +465,22 @@ func TestReadRecord(t *testing.T) {
 	testutil.Equal(t, "First Post", jsonStr(t, body["title"]))
 }
 
+func TestTrailingSlashEquivalent(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	for _, path := range []string{"/api/collections/posts", "/api/collections/posts/"} {
+		w := doRequest(t, srv, "GET", path, nil)
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+		testutil.Equal(t, 3, len(jsonItems(t, parseJSON(t, w))))
+	}
+	for _, path := range []string{"/api/collections/posts/1", "/api/collections/posts/1/"} {
+		w := doRequest(t, srv, "GET", path, nil)
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+		testutil.Equal(t, "First Post", jsonStr(t, parseJSON(t, w)["title"]))
+	}
+}
+
 func TestReadRecordNotFound(t *testing.T) {
 	ctx := context.Background()
 	srv, _ := setupTestServer(t, ctx)
@@ -353,6 +531,108 @@ func TestCreateRecordInvalidJSON(t *testing.T) {
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 }
 
+// runMigrations creates the _ayb_ system tables, which resetAndSeedDB drops.
+func runMigrations(t *testing.T, ctx context.Context) {
+	t.Helper()
+	runner := migrations.NewRunner(sharedPG.Pool, testutil.DiscardLogger())
+	testutil.NoError(t, runner.Bootstrap(ctx))
+	_, err := runner.Run(ctx)
+	testutil.NoError(t, err)
+}
+
+func doIdempotentRequest(t *testing.T, srv *server.Server, path, key string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateRecordIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+	runMigrations(t, ctx)
+
+	data := map[string]any{"name": "Charlie"}
+	first := doIdempotentRequest(t, srv, "/api/collections/authors/", "create-charlie", data)
+	testutil.StatusCode(t, http.StatusCreated, first.Code)
+	testutil.Equal(t, "", first.Header().Get("Idempotent-Replayed"))
+
+	// The retry replays the stored response without inserting again.
+	retry := doIdempotentRequest(t, srv, "/api/collections/authors/", "create-charlie", data)
+	testutil.StatusCode(t, http.StatusCreated, retry.Code)
+	testutil.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	testutil.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	testutil.Equal(t, first.Body.String(), retry.Body.String())
+
+	var count int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM authors WHERE name = 'Charlie'`).Scan(&count))
+	testutil.Equal(t, 1, count)
+
+	// Reusing the key for a different request is rejected.
+	w := doIdempotentRequest(t, srv, "/api/collections/authors/", "create-charlie", map[string]any{"name": "Dana"})
+	testutil.StatusCode(t, http.StatusUnprocessableEntity, w.Code)
+
+	// An expired key runs the request again.
+	_, err := pg.Pool.Exec(ctx, `UPDATE _ayb_idempotency_keys SET expires_at = now() - interval '1 second'`)
+	testutil.NoError(t, err)
+	w = doIdempotentRequest(t, srv, "/api/collections/authors/", "create-charlie", data)
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "", w.Header().Get("Idempotent-Replayed"))
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM authors WHERE name = 'Charlie'`).Scan(&count))
+	testutil.Equal(t, 2, count)
+}
+
+func TestCreateRecordIdempotencyKeyConcurrent(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+	runMigrations(t, ctx)
+
+	const n = 8
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := doIdempotentRequest(t, srv, "/api/collections/authors/", "concurrent", map[string]any{"name": "Eve"})
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	// Requests that lost the race either replay the response or are told
+	// the first is still in progress; only one row is inserted.
+	for _, code := range codes {
+		testutil.True(t, code == http.StatusCreated || code == http.StatusConflict, "unexpected status %d", code)
+	}
+	var count int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM authors WHERE name = 'Eve'`).Scan(&count))
+	testutil.Equal(t, 1, count)
+}
+
+func TestBatchIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+	runMigrations(t, ctx)
+
+	batch := map[string]any{"operations": []map[string]any{
+		{"method": "create", "body": map[string]any{"name": "newtag"}},
+	}}
+	first := doIdempotentRequest(t, srv, "/api/collections/tags/batch", "batch-1", batch)
+	testutil.StatusCode(t, http.StatusOK, first.Code)
+	retry := doIdempotentRequest(t, srv, "/api/collections/tags/batch", "batch-1", batch)
+	testutil.StatusCode(t, http.StatusOK, retry.Code)
+	testutil.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+
+	var count int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM tags WHERE name = 'newtag'`).Scan(&count))
+	testutil.Equal(t, 1, count)
+}
+
 func TestCreateRecordEmptyBody(t *testing.T) {
 	ctx := context.Background()
 	srv, _ := setupTestServer(t, ctx)
@@ -370,7 +650,7 @@ func TestCreateRecordNotNullViolation(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/authors/", data)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	body := parseJSON(t, w)
+	body := parseError(t, w)
 	testutil.Contains(t, jsonStr(t, body["message"]), "missing required")
 }
 
@@ -383,7 +663,7 @@ func TestCreateRecordUniqueViolation(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/tags/", data)
 	testutil.StatusCode(t, http.StatusConflict, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, jsonStr(t, resp["message"]), "unique constraint violation")
 }
 
@@ -441,6 +721,59 @@ func TestDeleteRecordNotFound(t *testing.T) {
 	testutil.StatusCode(t, http.StatusNotFound, w.Code)
 }
 
+func TestBulkDeleteByFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "DELETE", "/api/collections/posts?filter=status%3D'published'", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 2.0, jsonNum(t, parseJSON(t, w)["affected"]))
+
+	// Only the matching rows are gone.
+	var titles []string
+	rows, err := pg.Pool.Query(ctx, "SELECT title FROM posts ORDER BY id")
+	testutil.NoError(t, err)
+	for rows.Next() {
+		var title string
+		testutil.NoError(t, rows.Scan(&title))
+		titles = append(titles, title)
+	}
+	testutil.NoError(t, rows.Err())
+	testutil.SliceLen(t, titles, 1)
+	testutil.Equal(t, "Second Post", titles[0])
+}
+
+func TestBulkUpdateByFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "PATCH", "/api/collections/posts?filter=author_id%3D1", map[string]any{"status": "archived"})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 2.0, jsonNum(t, parseJSON(t, w)["affected"]))
+
+	var archived int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM posts WHERE status = 'archived'").Scan(&archived))
+	testutil.Equal(t, 2, archived)
+	var status string
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT status FROM posts WHERE author_id = 2").Scan(&status))
+	testutil.Equal(t, "published", status)
+}
+
+func TestBulkWriteRequiresFilter(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "DELETE", "/api/collections/tags", nil)
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	var count int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM tags").Scan(&count))
+	testutil.Equal(t, 3, count)
+
+	w = doRequest(t, srv, "DELETE", "/api/collections/tags?all=true", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 3.0, jsonNum(t, parseJSON(t, w)["affected"]))
+}
+
 // --- Expand tests ---
 
 func TestReadWithExpand(t *testing.T) {
@@ -567,7 +900,7 @@ func TestCreateAllUnknownColumns(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/authors/", data)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	body := parseJSON(t, w)
+	body := parseError(t, w)
 	testutil.Contains(t, jsonStr(t, body["message"]), "no recognized columns")
 }
 
@@ -579,7 +912,7 @@ func TestUpdateAllUnknownColumns(t *testing.T) {
 	w := doRequest(t, srv, "PATCH", "/api/collections/posts/1", data)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	body := parseJSON(t, w)
+	body := parseError(t, w)
 	testutil.Contains(t, jsonStr(t, body["message"]), "no recognized columns")
 }
 
@@ -612,6 +945,7 @@ func TestViewReadOnly(t *testing.T) {
 	data := map[string]any{"title": "test"}
 	w = doRequest(t, srv, "POST", "/api/collections/active_posts/", data)
 	testutil.StatusCode(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
 }
 
 // --- Error format tests ---
@@ -623,8 +957,8 @@ func TestErrorResponseFormat(t *testing.T) {
 	w := doRequest(t, srv, "GET", "/api/collections/nonexistent/", nil)
 	testutil.StatusCode(t, http.StatusNotFound, w.Code)
 
-	body := parseJSON(t, w)
-	testutil.Equal(t, 404.0, jsonNum(t, body["code"]))
+	body := parseError(t, w)
+	testutil.Equal(t, "not_found", jsonStr(t, body["code"]))
 	msg, ok := body["message"].(string)
 	testutil.True(t, ok, "expected message to be a string")
 	testutil.Contains(t, msg, "not found")
@@ -1106,7 +1440,7 @@ func TestRPCFunctionThatRaisesException(t *testing.T) {
 	// P0001 (RAISE EXCEPTION) is mapped to 400 Bad Request by mapPGError.
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	body := parseJSON(t, w)
+	body := parseError(t, w)
 	testutil.Contains(t, jsonStr(t, body["message"]), "intentional error")
 }
 
@@ -1171,7 +1505,7 @@ func TestCheckConstraintViolation(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/products/", body)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, resp["message"].(string), "check constraint violation")
 }
 
@@ -1184,7 +1518,7 @@ func TestInvalidTypeValue(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/posts/", body)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, resp["message"].(string), "invalid integer value")
 }
 
@@ -1196,7 +1530,7 @@ func TestDeleteWithForeignKeyViolation(t *testing.T) {
 	w := doRequest(t, srv, "DELETE", "/api/collections/authors/1", nil)
 	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, resp["message"].(string), "foreign key violation")
 }
 
@@ -1213,7 +1547,7 @@ func TestBatchUpdateNotFoundReturns404(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/authors/batch", batch)
 	testutil.StatusCode(t, http.StatusNotFound, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, resp["message"].(string), "record not found")
 }
 
@@ -1230,7 +1564,7 @@ func TestBatchDeleteNotFoundReturns404(t *testing.T) {
 	w := doRequest(t, srv, "POST", "/api/collections/authors/batch", batch)
 	testutil.StatusCode(t, http.StatusNotFound, w.Code)
 
-	resp := parseJSON(t, w)
+	resp := parseError(t, w)
 	testutil.Contains(t, resp["message"].(string), "record not found")
 }
 
@@ -1256,6 +1590,202 @@ func TestBatchNotFoundRollsBack(t *testing.T) {
 	testutil.Equal(t, 0, count)
 }
 
+func TestTransactionalWritesRollBackOnCommitFailure(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	// The FK is only checked at commit, so the INSERT itself succeeds and the
+	// failure surfaces from the statement that ends the transaction.
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE comments (
+			id SERIAL PRIMARY KEY,
+			post_id INTEGER REFERENCES posts(id) DEFERRABLE INITIALLY DEFERRED,
+			body TEXT
+		);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Database.TransactionalWrites = true
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	w := doRequest(t, srv, "POST", "/api/collections/comments/",
+		map[string]any{"post_id": 99999, "body": "orphan"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "foreign key")
+
+	var count int
+	err = pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM comments").Scan(&count)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, count)
+
+	// A valid write still commits.
+	w = doRequest(t, srv, "POST", "/api/collections/comments/",
+		map[string]any{"post_id": 1, "body": "hello"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	err = pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM comments").Scan(&count)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 1, count)
+}
+
+func TestPreferReturnMinimal(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	doPrefer := func(method, path string, body any, prefer string) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		if prefer != "" {
+			req.Header.Set("Prefer", prefer)
+		}
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	// Minimal create: 204, no body, row written.
+	w := doPrefer("POST", "/api/collections/authors/", map[string]any{"name": "Minimal"}, "return=minimal")
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, 0, w.Body.Len())
+	testutil.Equal(t, "return=minimal", w.Header().Get("Preference-Applied"))
+	var count int
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM authors WHERE name = 'Minimal'").Scan(&count))
+	testutil.Equal(t, 1, count)
+
+	// Default create returns the row.
+	w = doPrefer("POST", "/api/collections/authors/", map[string]any{"name": "Full"}, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "Full", jsonStr(t, parseJSON(t, w)["name"]))
+
+	// Minimal update.
+	w = doPrefer("PATCH", "/api/collections/authors/1", map[string]any{"name": "Alicia"}, "return=minimal")
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, 0, w.Body.Len())
+	var name string
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT name FROM authors WHERE id = 1").Scan(&name))
+	testutil.Equal(t, "Alicia", name)
+
+	// Explicit representation returns the row.
+	w = doPrefer("PATCH", "/api/collections/authors/1", map[string]any{"name": "Alice"}, "return=representation")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "Alice", jsonStr(t, parseJSON(t, w)["name"]))
+}
+
+func TestCheckReferences(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Database.CheckReferences = true
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	// Dangling reference on create is reported against the column.
+	w := doRequest(t, srv, "POST", "/api/collections/posts/",
+		map[string]any{"title": "Orphan", "author_id": 99999})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	body := parseError(t, w)
+	testutil.Equal(t, "foreign key violation", jsonStr(t, body["message"]))
+	field, ok := body["details"].(map[string]any)["author_id"].(map[string]any)
+	testutil.True(t, ok, "expected author_id in details, got %v", body["details"])
+	testutil.Equal(t, "foreign_key_violation", jsonStr(t, field["code"]))
+	testutil.Equal(t, "referenced authors not found", jsonStr(t, field["message"]))
+
+	// Valid and NULL references are written.
+	w = doRequest(t, srv, "POST", "/api/collections/posts/",
+		map[string]any{"title": "Valid", "author_id": 2})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	w = doRequest(t, srv, "POST", "/api/collections/posts/",
+		map[string]any{"title": "Anonymous", "author_id": nil})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+
+	// Dangling reference on update.
+	w = doRequest(t, srv, "PATCH", "/api/collections/posts/1",
+		map[string]any{"author_id": 99999})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "referenced authors not found")
+	w = doRequest(t, srv, "PATCH", "/api/collections/posts/1",
+		map[string]any{"author_id": 2})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+
+	// A dangling reference in a batch rolls the whole batch back.
+	w = doRequest(t, srv, "POST", "/api/collections/posts/batch", map[string]any{
+		"operations": []map[string]any{
+			{"method": "create", "body": map[string]any{"title": "Batch ok", "author_id": 1}},
+			{"method": "create", "body": map[string]any{"title": "Batch orphan", "author_id": 99999}},
+		},
+	})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "referenced authors not found")
+
+	var count int
+	err := pg.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM posts WHERE title LIKE 'Batch%' OR title = 'Orphan'").Scan(&count)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, count)
+}
+
+func TestPostGISGeometryColumns(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	if _, err := pg.Pool.Exec(ctx, `CREATE EXTENSION IF NOT EXISTS postgis`); err != nil {
+		t.Skipf("postgis not available: %v", err)
+	}
+	t.Cleanup(func() { _, _ = pg.Pool.Exec(ctx, `DROP EXTENSION IF EXISTS postgis CASCADE`) })
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE places (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL,
+			location geometry(Point, 4326)
+		);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	testutil.True(t, ch.Get().HasPostGIS, "postgis should be detected")
+	srv := server.New(config.Default(), logger, ch, pg.Pool, nil, nil)
+
+	// GeoJSON in, GeoJSON out.
+	for _, p := range []struct {
+		name     string
+		lng, lat float64
+	}{
+		{"Ferry Building", -122.3937, 37.7955},
+		{"Coit Tower", -122.4058, 37.8024},
+		{"Golden Gate Bridge", -122.4783, 37.8199},
+	} {
+		w := doRequest(t, srv, "POST", "/api/collections/places/", map[string]any{
+			"name":     p.name,
+			"location": map[string]any{"type": "Point", "coordinates": []float64{p.lng, p.lat}},
+		})
+		testutil.StatusCode(t, http.StatusCreated, w.Code)
+		loc, ok := parseJSON(t, w)["location"].(map[string]any)
+		testutil.True(t, ok, "location should be a GeoJSON object")
+		testutil.Equal(t, "Point", jsonStr(t, loc["type"]))
+	}
+
+	// Within 1.5km of the Ferry Building: itself and Coit Tower.
+	w := doRequest(t, srv, "GET", "/api/collections/places/?filter=location.near%3D-122.3937,37.7955,1500&sort=id", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	items := jsonItems(t, parseJSON(t, w))
+	testutil.Equal(t, 2, len(items))
+	testutil.Equal(t, "Ferry Building", jsonStr(t, items[0]["name"]))
+
+	w = doRequest(t, srv, "POST", "/api/collections/places/",
+		map[string]any{"name": "Nowhere", "location": "POINT(0 0)"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "GeoJSON")
+}
+
 func TestRPCFunctionReturningNULL(t *testing.T) {
 	ctx := context.Background()
 	srv, pg := setupTestServer(t, ctx)
@@ -1281,3 +1811,492 @@ func TestRPCFunctionReturningNULL(t *testing.T) {
 	// A function returning NULL should produce a JSON null response.
 	testutil.Equal(t, "null\n", w.Body.String())
 }
+
+func TestJSONBPathFilterAndPartialUpdate(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE docs (
+			id SERIAL PRIMARY KEY,
+			data JSONB
+		);
+		INSERT INTO docs (data) VALUES
+			('{"meta": {"status": "active", "owner": "ann"}, "score": 7}'),
+			('{"meta": {"status": "archived", "owner": "bob"}, "score": 12}'),
+			(NULL);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	srv := server.New(config.Default(), logger, ch, pg.Pool, nil, nil)
+
+	// Nested path filter.
+	w := doRequest(t, srv, "GET", "/api/collections/docs/?filter="+url.QueryEscape("data->'meta'->>'status'='active'"), nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	items := jsonItems(t, parseJSON(t, w))
+	testutil.Equal(t, 1, len(items))
+	testutil.Equal(t, 1.0, jsonNum(t, items[0]["id"]))
+
+	// ->> compared with a number is compared numerically.
+	w = doRequest(t, srv, "GET", "/api/collections/docs/?filter="+url.QueryEscape("data->>'score'>9"), nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	items = jsonItems(t, parseJSON(t, w))
+	testutil.Equal(t, 1, len(items))
+	testutil.Equal(t, 2.0, jsonNum(t, items[0]["id"]))
+
+	// A partial update merges into the document, keeping sibling keys.
+	w = doRequest(t, srv, "PATCH", "/api/collections/docs/1", map[string]any{
+		"data->meta->status": "done",
+		"data->score":        8,
+	})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	data := parseJSON(t, w)["data"].(map[string]any)
+	meta := data["meta"].(map[string]any)
+	testutil.Equal(t, "done", jsonStr(t, meta["status"]))
+	testutil.Equal(t, "ann", jsonStr(t, meta["owner"]))
+	testutil.Equal(t, 8.0, jsonNum(t, data["score"]))
+
+	// A nested path whose parent key doesn't exist creates the parent.
+	w = doRequest(t, srv, "PATCH", "/api/collections/docs/2", map[string]any{"data->settings->theme": "dark"})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	data = parseJSON(t, w)["data"].(map[string]any)
+	testutil.Equal(t, "dark", jsonStr(t, data["settings"].(map[string]any)["theme"]))
+	testutil.Equal(t, "bob", jsonStr(t, data["meta"].(map[string]any)["owner"]))
+
+	// So does one into a NULL column.
+	w = doRequest(t, srv, "PATCH", "/api/collections/docs/3", map[string]any{"data->a->b->c": true})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	data = parseJSON(t, w)["data"].(map[string]any)
+	testutil.Equal(t, true, data["a"].(map[string]any)["b"].(map[string]any)["c"].(bool))
+
+	// Path keys on a non-jsonb column are rejected.
+	w = doRequest(t, srv, "PATCH", "/api/collections/posts/1", map[string]any{"title->x": "y"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "is not jsonb")
+}
+
+func TestOptimisticConcurrencyVersion(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE notes (
+			id SERIAL PRIMARY KEY,
+			body TEXT NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1
+		);
+		INSERT INTO notes (body) VALUES ('draft');
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	srv := server.New(config.Default(), logger, ch, pg.Pool, nil, nil)
+
+	// Reads return the current version.
+	w := doRequest(t, srv, "GET", "/api/collections/notes/1", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 1.0, jsonNum(t, parseJSON(t, w)["version"]))
+
+	// Two writers that both read version 1 update concurrently: one wins and
+	// the other gets 409 instead of silently overwriting it.
+	codes := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := doRequest(t, srv, "PATCH", "/api/collections/notes/1",
+				map[string]any{"body": fmt.Sprintf("edit %d", i), "version": 1})
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+	sort.Ints(codes)
+	testutil.Equal(t, http.StatusOK, codes[0])
+	testutil.Equal(t, http.StatusConflict, codes[1])
+
+	w = doRequest(t, srv, "GET", "/api/collections/notes/1", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 2.0, jsonNum(t, parseJSON(t, w)["version"]))
+
+	// Retrying with the current version succeeds and increments it.
+	w = doRequest(t, srv, "PATCH", "/api/collections/notes/1", map[string]any{"body": "final", "version": 2})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 3.0, jsonNum(t, parseJSON(t, w)["version"]))
+
+	// A missing version is rejected, and a missing record is still a 404.
+	w = doRequest(t, srv, "PATCH", "/api/collections/notes/1", map[string]any{"body": "x"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	w = doRequest(t, srv, "PATCH", "/api/collections/notes/999", map[string]any{"body": "x", "version": 1})
+	testutil.StatusCode(t, http.StatusNotFound, w.Code)
+}
+
+func TestRequestTimeoutCancelsQuery(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE FUNCTION slow_sleep() RETURNS VOID AS $$
+			SELECT pg_sleep(10);
+		$$ LANGUAGE SQL;
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Server.RequestTimeout = 1
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	start := time.Now()
+	w := doRequest(t, srv, "POST", "/api/rpc/slow_sleep", nil)
+	testutil.StatusCode(t, http.StatusGatewayTimeout, w.Code)
+	testutil.True(t, time.Since(start) < 5*time.Second, "request should end at the timeout, not when the query finishes")
+	body := parseError(t, w)
+	testutil.Equal(t, "request timed out", jsonStr(t, body["message"]))
+
+	// The query itself must have been cancelled, not left running.
+	var running int
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		err := pg.Pool.QueryRow(ctx, `
+			SELECT count(*) FROM pg_stat_activity
+			WHERE state = 'active' AND query LIKE '%slow_sleep%' AND pid <> pg_backend_pid()
+		`).Scan(&running)
+		testutil.NoError(t, err)
+		if running == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	testutil.Equal(t, 0, running)
+}
+
+func TestStatementTimeoutAbortsQuery(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE FUNCTION slow_lookup() RETURNS VOID AS $$
+			SELECT pg_sleep(10);
+		$$ LANGUAGE SQL;
+		CREATE FUNCTION monthly_report() RETURNS VOID AS $$
+			SELECT pg_sleep(1.5);
+		$$ LANGUAGE SQL;
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Server.StatementTimeout = 1
+	cfg.Server.RouteStatementTimeouts = map[string]int{"/api/rpc/monthly_report": 5}
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	// With no request timeout, Postgres itself aborts the query.
+	start := time.Now()
+	w := doRequest(t, srv, "POST", "/api/rpc/slow_lookup", nil)
+	testutil.StatusCode(t, http.StatusGatewayTimeout, w.Code)
+	testutil.True(t, time.Since(start) < 5*time.Second, "query should be aborted at the statement timeout")
+	testutil.Equal(t, "statement timed out", jsonStr(t, parseError(t, w)["message"]))
+
+	// The route override gives the report room to finish.
+	w = doRequest(t, srv, "POST", "/api/rpc/monthly_report", nil)
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+
+	// The timeout is transaction-local and doesn't leak onto pooled
+	// connections.
+	var setting string
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SHOW statement_timeout").Scan(&setting))
+	testutil.Equal(t, "0", setting)
+}
+
+func TestMatchFilterAgainstEventRecord(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	h := api.NewHandler(pg.Pool, ch, logger, nil, nil)
+
+	// Records as the API publishes them after a write.
+	published := map[string]any{"id": 1, "title": "First Post", "status": "published", "created_at": time.Now()}
+	draft := map[string]any{"id": 2, "title": "Second Post", "status": "draft", "created_at": time.Now()}
+
+	const filter = "status='published' && created_at>'2020-01-01'"
+	ok, err := h.MatchFilter(ctx, "posts", filter, published)
+	testutil.NoError(t, err)
+	testutil.True(t, ok, "published post should match")
+
+	ok, err = h.MatchFilter(ctx, "posts", filter, draft)
+	testutil.NoError(t, err)
+	testutil.False(t, ok, "draft post should not match")
+
+	// A delete event carries only the key, so other columns are NULL.
+	ok, err = h.MatchFilter(ctx, "posts", filter, map[string]any{"id": 1})
+	testutil.NoError(t, err)
+	testutil.False(t, ok, "key-only record should not match a non-key filter")
+
+	// The record is evaluated as given, not re-read from the table.
+	var stored string
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT status FROM posts WHERE id = 2").Scan(&stored))
+	testutil.Equal(t, "draft", stored)
+	ok, err = h.MatchFilter(ctx, "posts", "status='published'", map[string]any{"id": 2, "status": "published"})
+	testutil.NoError(t, err)
+	testutil.True(t, ok, "record values should be used")
+}
+
+func TestExplainListQuery(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := setupTestServer(t, ctx)
+
+	w := doRequest(t, srv, "GET", "/api/admin/collections/posts/explain?filter="+url.QueryEscape("status='published'")+"&sort=-id", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var resp api.ExplainResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Contains(t, resp.Query, "ORDER BY")
+	var plan []map[string]any
+	testutil.NoError(t, json.Unmarshal(resp.Plan, &plan))
+	testutil.SliceLen(t, plan, 1)
+	testutil.NotNil(t, plan[0]["Plan"])
+	_, analyzed := plan[0]["Execution Time"]
+	testutil.False(t, analyzed, "plain EXPLAIN should not execute the query")
+
+	w = doRequest(t, srv, "GET", "/api/admin/collections/posts/explain?analyze=true", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	plan = nil
+	testutil.NoError(t, json.Unmarshal(resp.Plan, &plan))
+	_, analyzed = plan[0]["Execution Time"]
+	testutil.True(t, analyzed, "analyze=true should include execution time")
+
+	w = doRequest(t, srv, "GET", "/api/admin/collections/posts/explain?filter=nosuchcol%3D1", nil)
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCollectionStats(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE events (id SERIAL PRIMARY KEY, payload TEXT);
+		INSERT INTO events (payload) SELECT 'event ' || g FROM generate_series(1, 500) g;
+		ANALYZE events;
+		CREATE VIEW recent_events AS SELECT * FROM events;
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	srv := server.New(config.Default(), logger, ch, pg.Pool, nil, nil)
+
+	w := doRequest(t, srv, "GET", "/api/admin/collections/stats", nil)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var resp struct {
+		Items []api.CollectionStats `json:"items"`
+		Count int                   `json:"count"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, len(resp.Items), resp.Count)
+
+	byTable := make(map[string]api.CollectionStats)
+	for _, s := range resp.Items {
+		byTable[s.Table] = s
+	}
+	_, hasView := byTable["recent_events"]
+	testutil.False(t, hasView, "views store no data and should be skipped")
+
+	events, ok := byTable["events"]
+	testutil.True(t, ok, "stats should include the created table")
+	testutil.Equal(t, "table", events.Kind)
+	testutil.NotNil(t, events.EstimatedRows)
+	testutil.Equal(t, int64(500), *events.EstimatedRows)
+	testutil.True(t, events.IndexBytes > 0, "the primary key index should have a size")
+	testutil.Equal(t, events.TotalBytes, events.TableBytes+events.IndexBytes)
+	testutil.NotNil(t, events.LastAnalyzed)
+}
+
+func TestAutoTimestamps(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE notes (
+			id SERIAL PRIMARY KEY,
+			body TEXT,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ
+		);
+		CREATE TABLE audit (
+			id SERIAL PRIMARY KEY,
+			created_at TIMESTAMPTZ
+		);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Database.ManualTimestampTables = []string{"audit"}
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	// Create sets both columns, ignoring the client's created_at.
+	w := doRequest(t, srv, "POST", "/api/collections/notes/",
+		map[string]any{"body": "a", "created_at": "2001-01-01T00:00:00Z"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	var created, updated time.Time
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT created_at, updated_at FROM notes WHERE id = 1").Scan(&created, &updated))
+	testutil.True(t, time.Since(created) < time.Minute, "created_at should be now, got %v", created)
+	testutil.Equal(t, created, updated)
+
+	// Update moves updated_at and keeps created_at.
+	time.Sleep(10 * time.Millisecond)
+	w = doRequest(t, srv, "PATCH", "/api/collections/notes/1",
+		map[string]any{"body": "b", "created_at": "2001-01-01T00:00:00Z"})
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var created2, updated2 time.Time
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT created_at, updated_at FROM notes WHERE id = 1").Scan(&created2, &updated2))
+	testutil.Equal(t, created, created2)
+	testutil.True(t, updated2.After(updated), "updated_at should advance, got %v then %v", updated, updated2)
+
+	// Manual tables keep the client's value.
+	w = doRequest(t, srv, "POST", "/api/collections/audit/",
+		map[string]any{"created_at": "2001-01-01T00:00:00Z"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	var audited time.Time
+	testutil.NoError(t, pg.Pool.QueryRow(ctx, "SELECT created_at FROM audit WHERE id = 1").Scan(&audited))
+	testutil.Equal(t, 2001, audited.Year())
+}
+
+func TestGeneratedUUIDKey(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	_, err := pg.Pool.Exec(ctx, `
+		CREATE TABLE items (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			name TEXT
+		);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	srv := server.New(config.Default(), logger, ch, pg.Pool, nil, nil)
+
+	// Omitted or null id: the database generates one and it is returned.
+	for _, body := range []map[string]any{{"name": "a"}, {"id": nil, "name": "b"}} {
+		w := doRequest(t, srv, "POST", "/api/collections/items/", body)
+		testutil.StatusCode(t, http.StatusCreated, w.Code)
+		_, err := uuid.Parse(jsonStr(t, parseJSON(t, w)["id"]))
+		testutil.NoError(t, err)
+	}
+
+	// A client-supplied UUID is kept, so retrying the create conflicts
+	// instead of adding a second row.
+	const id = "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91"
+	w := doRequest(t, srv, "POST", "/api/collections/items/", map[string]any{"id": id, "name": "c"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, id, jsonStr(t, parseJSON(t, w)["id"]))
+	w = doRequest(t, srv, "POST", "/api/collections/items/", map[string]any{"id": id, "name": "c"})
+	testutil.StatusCode(t, http.StatusConflict, w.Code)
+
+	w = doRequest(t, srv, "POST", "/api/collections/items/", map[string]any{"id": "not-a-uuid", "name": "d"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "is not a valid UUID")
+}
+
+func TestTableHooks(t *testing.T) {
+	ctx := context.Background()
+	srv, pg := setupTestServer(t, ctx)
+
+	srv.OnBeforeCreate("authors", func(_ context.Context, e *api.HookEvent) error {
+		if e.Record["name"] == "Mallory" {
+			return &api.HookError{Status: http.StatusUnprocessableEntity, Message: "name is not allowed"}
+		}
+		e.Record["name"] = strings.TrimSpace(e.Record["name"].(string))
+		return nil
+	})
+	var hookID any
+	srv.OnAfterCreate("authors", func(ctx context.Context, e *api.HookEvent) error {
+		hookID = e.Record["id"]
+		// Writes through the request transaction commit with the record.
+		_, err := e.Tx.Exec(ctx, "INSERT INTO tags (name) VALUES ($1)", e.Record["name"])
+		return err
+	})
+	srv.OnAfterCreate("tags", func(context.Context, *api.HookEvent) error {
+		return errors.New("tags are read-only")
+	})
+
+	countRows := func(query string, args ...any) int {
+		t.Helper()
+		var n int
+		testutil.NoError(t, pg.Pool.QueryRow(ctx, query, args...).Scan(&n))
+		return n
+	}
+
+	// A before-hook rejects the create, so nothing is written.
+	w := doRequest(t, srv, "POST", "/api/collections/authors/", map[string]any{"name": "Mallory"})
+	testutil.StatusCode(t, http.StatusUnprocessableEntity, w.Code)
+	testutil.Contains(t, w.Body.String(), "name is not allowed")
+	testutil.Equal(t, 2, countRows("SELECT count(*) FROM authors"))
+
+	// The before-hook's change is written, and the after-hook sees the id.
+	w = doRequest(t, srv, "POST", "/api/collections/authors/", map[string]any{"name": "  Carol "})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	body := parseJSON(t, w)
+	testutil.Equal(t, "Carol", jsonStr(t, body["name"]))
+	testutil.NotNil(t, hookID)
+	testutil.Equal(t, fmt.Sprint(body["id"]), fmt.Sprint(hookID))
+	testutil.Equal(t, 1, countRows("SELECT count(*) FROM tags WHERE name = 'Carol'"))
+
+	// An after-hook error rolls the write back.
+	w = doRequest(t, srv, "POST", "/api/collections/tags/", map[string]any{"name": "hooked"})
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "tags are read-only")
+	testutil.Equal(t, 0, countRows("SELECT count(*) FROM tags WHERE name = 'hooked'"))
+
+	// Filtered bulk writes can't run per-record hooks, so they are refused.
+	w = doRequest(t, srv, "PATCH", "/api/collections/authors/?all=true", map[string]any{"name": "x"})
+	testutil.StatusCode(t, http.StatusConflict, w.Code)
+}
+
+func TestFieldRulesIntegration(t *testing.T) {
+	ctx := context.Background()
+	_, pg := setupTestServer(t, ctx)
+
+	minLen := 3
+	cfg := config.Default()
+	cfg.Database.FieldRules = map[string]map[string]config.FieldRule{
+		"posts": {
+			"title":  {MinLength: &minLen},
+			"status": {Enum: []string{"draft", "published"}},
+		},
+	}
+	testutil.NoError(t, cfg.Validate())
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(pg.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	srv := server.New(cfg, logger, ch, pg.Pool, nil, nil)
+
+	w := doRequest(t, srv, "POST", "/api/collections/posts/", map[string]any{"title": "Hi", "status": "live"})
+	testutil.StatusCode(t, http.StatusUnprocessableEntity, w.Code)
+	details := parseError(t, w)["details"].(map[string]any)
+	testutil.Equal(t, "min_length", jsonStr(t, details["title"].(map[string]any)["code"]))
+	testutil.Equal(t, "enum", jsonStr(t, details["status"].(map[string]any)["code"]))
+
+	w = doRequest(t, srv, "POST", "/api/collections/posts/", map[string]any{"title": "Hello", "status": "published"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "Hello", jsonStr(t, parseJSON(t, w)["title"]))
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// jsonPathSep separates a JSONB column from the keys of a partial update in
+// a request body key, e.g. "data->settings->theme".
+const jsonPathSep = "->"
+
+// isJSONB reports whether col is a jsonb column. JSON path filters and
+// partial updates are only supported on jsonb.
+func isJSONB(col *schema.Column) bool {
+	return col.IsJSON && !col.IsArray && baseTypeName(col) == "jsonb"
+}
+
+// parseJSONPatchKey splits a "column->key->key" body key. ok is false for
+// plain column names.
+func parseJSONPatchKey(key string) (column string, path []string, ok bool) {
+	parts := strings.Split(key, jsonPathSep)
+	if len(parts) < 2 {
+		return key, nil, false
+	}
+	return parts[0], parts[1:], true
+}
+
+// validateJSONPatches checks the JSON path keys in data. They must name a
+// jsonb column, have no empty keys, and not be combined with a write of the
+// whole column. They are only accepted on update (allowed true).
+func validateJSONPatches(tbl *schema.Table, data map[string]any, allowed bool) error {
+	for key := range data {
+		name, path, ok := parseJSONPatchKey(key)
+		if !ok {
+			continue
+		}
+		if !allowed {
+			return fmt.Errorf("%s: JSON path keys are only supported on update", key)
+		}
+		col := tbl.ColumnByName(name)
+		if col == nil {
+			return fmt.Errorf("%s: unknown column %s", key, name)
+		}
+		if !isJSONB(col) {
+			return fmt.Errorf("%s: column %s is not jsonb", key, name)
+		}
+		for _, k := range path {
+			if k == "" {
+				return fmt.Errorf("%s: empty key in JSON path", key)
+			}
+		}
+		if _, whole := data[name]; whole {
+			return fmt.Errorf("%s: cannot update %s and a path within it in one request", key, name)
+		}
+	}
+	return nil
+}
+
+// prepareRecord validates and coerces a create or update body in place.
+// JSON path keys are only allowed on update, and an update to a versioned
+// table must carry the expected version. A create may leave out a generated
+// uuid key; see omitGeneratedKey.
+func prepareRecord(tbl *schema.Table, data map[string]any, update bool) error {
+	if err := validateJSONPatches(tbl, data, update); err != nil {
+		return err
+	}
+	if !update {
+		omitGeneratedKey(tbl, data)
+	}
+	if err := coerceRecord(tbl, data); err != nil {
+		return err
+	}
+	if update {
+		return requireVersion(tbl, data)
+	}
+	return nil
+}
+
+// jsonPatchSets returns one SET clause per jsonb column with path keys in
+// data, each applying jsonb_set for every path so sibling keys are kept.
+// jsonb_set only creates the last key of a path, so missing (or null)
+// parent objects of a nested path are created first. Parameters are
+// numbered from next; the new next index is returned. Keys must have been
+// checked by validateJSONPatches.
+func jsonPatchSets(data map[string]any, next int) (sets []string, args []any, _ int) {
+	byColumn := map[string][]string{}
+	for key := range data {
+		if name, _, ok := parseJSONPatchKey(key); ok {
+			byColumn[name] = append(byColumn[name], key)
+		}
+	}
+	columns := make([]string, 0, len(byColumn))
+	for name := range byColumn {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+
+	for _, name := range columns {
+		keys := byColumn[name]
+		sort.Strings(keys) // parents before children, deterministically
+		expr := "COALESCE(" + quoteIdent(name) + ", '{}'::jsonb)"
+		for _, key := range keys {
+			_, path, _ := parseJSONPatchKey(key)
+			value, _ := json.Marshal(data[key])
+			if len(path) == 1 {
+				expr = fmt.Sprintf("jsonb_set(%s, $%d::text[], $%d::jsonb, true)", expr, next, next+1)
+				args = append(args, path, string(value))
+				next += 2
+				continue
+			}
+			// The current value is bound once as v, since each parent
+			// is looked up in it.
+			set := "v"
+			for i := 1; i < len(path); i++ {
+				set = fmt.Sprintf("jsonb_set(%s, $%d::text[], COALESCE(NULLIF(v #> $%d::text[], 'null'::jsonb), '{}'::jsonb), true)", set, next, next)
+				args = append(args, path[:i])
+				next++
+			}
+			set = fmt.Sprintf("jsonb_set(%s, $%d::text[], $%d::jsonb, true)", set, next, next+1)
+			args = append(args, path, string(value))
+			next += 2
+			expr = fmt.Sprintf("(SELECT %s FROM (SELECT %s AS v) AS cur)", set, expr)
+		}
+		sets = append(sets, quoteIdent(name)+" = "+expr)
+	}
+	return sets, args, next
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func jsonbTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "docs",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", Position: 1, TypeName: "integer", JSONType: "integer", IsPrimaryKey: true},
+			{Name: "name", Position: 2, TypeName: "text", JSONType: "string"},
+			{Name: "data", Position: 3, TypeName: "jsonb", JSONType: "object", IsJSON: true},
+			{Name: "doc", Position: 4, TypeName: "json", JSONType: "object", IsJSON: true},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestParseJSONPatchKey(t *testing.T) {
+	t.Parallel()
+	col, path, ok := parseJSONPatchKey("data->meta->status")
+	testutil.True(t, ok, "path key should parse")
+	testutil.Equal(t, "data", col)
+	testutil.SliceLen(t, path, 2)
+	testutil.Equal(t, "meta", path[0])
+	testutil.Equal(t, "status", path[1])
+
+	_, _, ok = parseJSONPatchKey("data")
+	testutil.False(t, ok, "plain column is not a path key")
+}
+
+func TestValidateJSONPatches(t *testing.T) {
+	t.Parallel()
+	tbl := jsonbTable()
+
+	testutil.NoError(t, validateJSONPatches(tbl, map[string]any{"name": "a", "data->meta->x": 1}, true))
+	testutil.NoError(t, validateJSONPatches(tbl, map[string]any{"data": map[string]any{}}, false))
+
+	for msg, data := range map[string]map[string]any{
+		"only supported on update": {"data->x": 1},
+	} {
+		testutil.ErrorContains(t, validateJSONPatches(tbl, data, false), msg)
+	}
+	for msg, data := range map[string]map[string]any{
+		"unknown column":       {"missing->x": 1},
+		"is not jsonb":         {"doc->x": 1},
+		"empty key":            {"data->->x": 1},
+		"and a path within it": {"data": map[string]any{}, "data->x": 1},
+	} {
+		testutil.ErrorContains(t, validateJSONPatches(tbl, data, true), msg)
+	}
+}
+
+func TestBuildUpdateJSONPatch(t *testing.T) {
+	t.Parallel()
+	tbl := jsonbTable()
+
+	data := map[string]any{"name": "Bob", "data->meta->status": "done", "data->count": 3.0}
+	q, args := buildUpdate(tbl, data, []string{"1"})
+	// Each path is applied with jsonb_set to the current value, so sibling
+	// keys are preserved. A nested path first creates its missing parents,
+	// looked up in the current value bound as v.
+	testutil.Contains(t, q, `SET "name" = $1, "data" = (SELECT jsonb_set(jsonb_set(v, $4::text[], COALESCE(NULLIF(v #> $4::text[], 'null'::jsonb), '{}'::jsonb), true), $5::text[], $6::jsonb, true) FROM (SELECT jsonb_set(COALESCE("data", '{}'::jsonb), $2::text[], $3::jsonb, true) AS v) AS cur)`)
+	testutil.Contains(t, q, `"id" = $7`)
+	testutil.SliceLen(t, args, 7)
+	testutil.Equal(t, "Bob", args[0].(string))
+	testutil.Equal(t, "count", args[1].([]string)[0])
+	testutil.Equal(t, "3", args[2].(string))
+	testutil.SliceLen(t, args[3].([]string), 1)
+	testutil.Equal(t, "meta", args[3].([]string)[0])
+	testutil.SliceLen(t, args[4].([]string), 2)
+	testutil.Equal(t, `"done"`, args[5].(string))
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// jsonSchemaDialect is the JSON Schema draft of the documents served by
+// GET /collections/{table}/schema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+func (h *Handler) handleSchema(w http.ResponseWriter, r *http.Request) {
+	tbl := h.resolveTable(w, r)
+	if tbl == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.jsonSchema(tbl))
+}
+
+// jsonSchema returns a JSON Schema document describing a record of tbl as
+// written to it. Columns the database or the server fills in are optional,
+// server-managed columns are read-only, and field rules become keywords.
+func (h *Handler) jsonSchema(tbl *schema.Table) map[string]any {
+	managed := h.managedFields[tbl.Name]
+	rules := h.fieldRules[tbl.Name]
+
+	properties := make(map[string]any, len(tbl.Columns))
+	required := []string{}
+	for _, col := range tbl.Columns {
+		prop := columnJSONSchema(col)
+		if rule, ok := rules[col.Name]; ok {
+			rule.addJSONSchema(prop, col)
+		}
+		f, isManaged := managed[col.Name]
+		if isManaged && !f.Default {
+			prop["readOnly"] = true
+		}
+		properties[col.Name] = prop
+		if !col.OptionalOnInsert() && !isManaged {
+			required = append(required, col.Name)
+		}
+	}
+
+	doc := map[string]any{
+		"$schema":    jsonSchemaDialect,
+		"title":      tbl.Name,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if tbl.Comment != "" {
+		doc["description"] = tbl.Comment
+	}
+	return doc
+}
+
+// columnJSONSchema maps a column to the schema of its values. Nullable
+// columns also accept null.
+func columnJSONSchema(col *schema.Column) map[string]any {
+	var prop map[string]any
+	if col.IsArray {
+		elem := strings.TrimSuffix(col.TypeName, "[]")
+		prop = map[string]any{"type": "array", "items": scalarJSONSchema(elem, schema.ColumnJSONType(elem))}
+	} else {
+		prop = scalarJSONSchema(col.TypeName, col.JSONType)
+	}
+	if col.IsEnum && len(col.EnumValues) > 0 {
+		prop["enum"] = enumJSONSchema(col.EnumValues, col.IsNullable)
+	}
+	if col.IsNullable {
+		prop["type"] = []string{prop["type"].(string), "null"}
+	}
+	if col.Comment != "" {
+		prop["description"] = col.Comment
+	}
+	return prop
+}
+
+// scalarJSONSchema maps a type name and its JSON type to a schema, with a
+// format for the string types that have one. An email format comes from a
+// domain named email.
+func scalarJSONSchema(typeName, jsonType string) map[string]any {
+	prop := map[string]any{"type": jsonType}
+	if jsonType != "string" {
+		return prop
+	}
+	switch base := baseTypeName(&schema.Column{TypeName: typeName}); {
+	case strings.HasPrefix(base, "timestamp"):
+		prop["format"] = "date-time"
+	case base == "date":
+		prop["format"] = "date"
+	case strings.HasPrefix(base, "time"):
+		prop["format"] = "time"
+	case base == "uuid":
+		prop["format"] = "uuid"
+	case base == "email" || strings.HasSuffix(base, ".email"):
+		prop["format"] = "email"
+	}
+	return prop
+}
+
+// addJSONSchema adds the keywords for r to prop, the schema of col. Each
+// rule maps to the keywords of the values it's checked against.
+func (r FieldRule) addJSONSchema(prop map[string]any, col *schema.Column) {
+	switch {
+	case isNumericColumn(col):
+		if r.Min != nil {
+			prop["minimum"] = *r.Min
+		}
+		if r.Max != nil {
+			prop["maximum"] = *r.Max
+		}
+	case col.IsArray:
+		if r.MinLength != nil {
+			prop["minItems"] = *r.MinLength
+		}
+		if r.MaxLength != nil {
+			prop["maxItems"] = *r.MaxLength
+		}
+	case col.JSONType == "string":
+		if r.MinLength != nil {
+			prop["minLength"] = *r.MinLength
+		}
+		if r.MaxLength != nil {
+			prop["maxLength"] = *r.MaxLength
+		}
+		if r.Pattern != nil {
+			prop["pattern"] = r.Pattern.String()
+		}
+		if r.Enum != nil {
+			prop["enum"] = enumJSONSchema(r.Enum, col.IsNullable)
+		}
+	}
+}
+
+// enumJSONSchema returns the values of an enum keyword, with null for
+// nullable columns.
+func enumJSONSchema(values []string, nullable bool) []any {
+	enum := make([]any, 0, len(values)+1)
+	for _, v := range values {
+		enum = append(enum, v)
+	}
+	if nullable {
+		enum = append(enum, nil)
+	}
+	return enum
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func assertJSONSchema(t *testing.T, want string, got []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	testutil.NoError(t, json.Compact(&buf, []byte(want)))
+	var gotBuf bytes.Buffer
+	testutil.NoError(t, json.Compact(&gotBuf, got))
+	testutil.Equal(t, buf.String(), gotBuf.String())
+}
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+	tbl := &schema.Table{
+		Schema:  "public",
+		Name:    "members",
+		Kind:    "table",
+		Comment: "Club members",
+		Columns: []*schema.Column{
+			{Name: "id", TypeName: "uuid", JSONType: "string", IsPrimaryKey: true, DefaultExpr: "gen_random_uuid()"},
+			{Name: "email", TypeName: "email", JSONType: "string"},
+			{Name: "name", TypeName: "character varying(100)", JSONType: "string", Comment: "Display name"},
+			{Name: "age", TypeName: "integer", JSONType: "integer", IsNullable: true},
+			{Name: "born", TypeName: "date", JSONType: "string", IsNullable: true},
+			{Name: "role", TypeName: "member_role", JSONType: "string", IsEnum: true, EnumValues: []string{"admin", "member"}, DefaultExpr: "'member'::member_role"},
+			{Name: "tags", TypeName: "text[]", JSONType: "array", IsArray: true, IsNullable: true},
+			{Name: "settings", TypeName: "jsonb", JSONType: "object", IsJSON: true},
+			{Name: "owner_id", TypeName: "uuid", JSONType: "string"},
+			{Name: "created_at", TypeName: "timestamp with time zone", JSONType: "string", DefaultExpr: "now()"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	h.SetManagedFields(map[string]map[string]ManagedField{"members": {
+		"owner_id": {From: ManagedFromUserID},
+	}})
+
+	got, err := json.Marshal(h.jsonSchema(tbl))
+	testutil.NoError(t, err)
+	assertJSONSchema(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"description": "Club members",
+		"properties": {
+			"age": {"type": ["integer", "null"]},
+			"born": {"format": "date", "type": ["string", "null"]},
+			"created_at": {"format": "date-time", "type": "string"},
+			"email": {"format": "email", "type": "string"},
+			"id": {"format": "uuid", "type": "string"},
+			"name": {"description": "Display name", "type": "string"},
+			"owner_id": {"format": "uuid", "readOnly": true, "type": "string"},
+			"role": {"enum": ["admin", "member"], "type": "string"},
+			"settings": {"type": "object"},
+			"tags": {"items": {"type": "string"}, "type": ["array", "null"]}
+		},
+		"required": ["email", "name", "settings"],
+		"title": "members",
+		"type": "object"
+	}`, got)
+}
+
+func TestJSONSchemaFieldRules(t *testing.T) {
+	t.Parallel()
+	h := productRulesHandler()
+
+	got, err := json.Marshal(h.jsonSchema(productsTable())["properties"])
+	testutil.NoError(t, err)
+	assertJSONSchema(t, `{
+		"id": {"type": "integer"},
+		"name": {"maxLength": 10, "minLength": 3, "type": "string"},
+		"price": {"maximum": 999.99, "minimum": 0, "type": "number"},
+		"sku": {"pattern": "^[A-Z]{3}-\\d+$", "type": "string"},
+		"status": {"enum": ["draft", "live"], "type": "string"},
+		"stock": {"minimum": 0, "type": "integer"},
+		"tags": {"items": {"type": "string"}, "maxItems": 2, "type": "array"}
+	}`, got)
+}
+
+func TestHandleSchema(t *testing.T) {
+	t.Parallel()
+	h := testHandler(testSchema())
+
+	w := doRequest(h, "GET", "/collections/users/schema", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var doc map[string]any
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	testutil.Equal(t, "users", doc["title"])
+	testutil.Equal(t, 3, len(doc["properties"].(map[string]any)))
+
+	w = doRequest(h, "GET", "/collections/missing/schema", "")
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "collection not found")
+}
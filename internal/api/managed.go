@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// Sources of ManagedField.From.
+const (
+	ManagedFromUserID    = "user_id"
+	ManagedFromUserEmail = "user_email"
+	ManagedFromNow       = "now"
+)
+
+// ManagedField sets a column's value on collection writes. The value is
+// Value, or taken from the request when From is set.
+type ManagedField struct {
+	Value   any
+	From    string // ManagedFromUserID, ManagedFromUserEmail or ManagedFromNow
+	OnWrite bool   // set on updates too, not just creates
+	Default bool   // only fill the column in when a create omits it
+}
+
+// SetManagedFields sets the server-managed column values of writes through
+// the collections API, batch requests and gRPC, keyed by table and then
+// column.
+func (h *Handler) SetManagedFields(fields map[string]map[string]ManagedField) {
+	h.managedFields = fields
+}
+
+// fromUser reports whether f is set from the authenticated user.
+func (f ManagedField) fromUser() bool {
+	return f.From == ManagedFromUserID || f.From == ManagedFromUserEmail
+}
+
+// keepsCallerValue reports whether f leaves the caller's value alone: it
+// is a default, or it is set from the user and the request has no claims,
+// as with the admin token.
+func (f ManagedField) keepsCallerValue(claims *auth.Claims) bool {
+	return f.Default || (f.fromUser() && claims == nil)
+}
+
+// dropManagedValues removes the client-supplied values of tbl's managed
+// columns from a create or update body, before it is validated.
+func (h *Handler) dropManagedValues(ctx context.Context, tbl *schema.Table, data map[string]any) {
+	claims := auth.ClaimsFromContext(ctx)
+	for name, f := range h.managedFields[tbl.Name] {
+		if !f.keepsCallerValue(claims) {
+			delete(data, name)
+		}
+	}
+}
+
+// setManagedValues sets tbl's managed columns in a validated create or
+// update body. Defaults only fill in columns a create omits.
+func (h *Handler) setManagedValues(ctx context.Context, tbl *schema.Table, data map[string]any, update bool) {
+	claims := auth.ClaimsFromContext(ctx)
+	for name, f := range h.managedFields[tbl.Name] {
+		if tbl.ColumnByName(name) == nil || (update && !f.OnWrite) {
+			continue
+		}
+		if _, sent := data[name]; sent && f.keepsCallerValue(claims) {
+			continue
+		}
+		if f.fromUser() && claims == nil {
+			continue
+		}
+		data[name] = managedValue(f, claims)
+	}
+}
+
+// managedValue returns the value f sets. A claim that's empty, such as the
+// subject of a client credentials token, is NULL.
+func managedValue(f ManagedField, claims *auth.Claims) any {
+	var v string
+	switch f.From {
+	case ManagedFromNow:
+		return time.Now().UTC()
+	case ManagedFromUserID:
+		v = claims.Subject
+	case ManagedFromUserEmail:
+		v = claims.Email
+	default:
+		return f.Value
+	}
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+// setServerValues sets the columns the server manages in a validated create
+// or update body: timestamps and managed fields.
+func (h *Handler) setServerValues(ctx context.Context, tbl *schema.Table, data map[string]any, update bool) {
+	h.stampTimestamps(tbl, data, update)
+	h.setManagedValues(ctx, tbl, data, update)
+}
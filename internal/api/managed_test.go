@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func notesTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "notes",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", TypeName: "integer", IsPrimaryKey: true},
+			{Name: "owner_id", TypeName: "uuid"},
+			{Name: "edited_by", TypeName: "text"},
+			{Name: "source", TypeName: "text"},
+			{Name: "visibility", TypeName: "text"},
+			{Name: "body", TypeName: "text"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func managedNotesHandler() *Handler {
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	h.SetManagedFields(map[string]map[string]ManagedField{"notes": {
+		"owner_id":   {From: ManagedFromUserID},
+		"edited_by":  {From: ManagedFromUserEmail, OnWrite: true},
+		"source":     {Value: "api"},
+		"visibility": {Value: "private", Default: true},
+		"missing":    {Value: "x"},
+	}})
+	return h
+}
+
+func userContext() context.Context {
+	claims := &auth.Claims{Email: "ann@example.com"}
+	claims.Subject = "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91"
+	return auth.ContextWithClaims(context.Background(), claims)
+}
+
+// applyManaged runs the managed-field steps of a write as the handlers do.
+func applyManaged(h *Handler, ctx context.Context, data map[string]any, update bool) {
+	h.dropManagedValues(ctx, notesTable(), data)
+	h.setManagedValues(ctx, notesTable(), data, update)
+}
+
+func TestManagedFieldsOnCreate(t *testing.T) {
+	t.Parallel()
+	h := managedNotesHandler()
+
+	data := map[string]any{"body": "hi", "owner_id": "someone-else", "source": "client", "edited_by": "eve"}
+	applyManaged(h, userContext(), data, false)
+	testutil.Equal(t, "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91", data["owner_id"].(string))
+	testutil.Equal(t, "ann@example.com", data["edited_by"].(string))
+	testutil.Equal(t, "api", data["source"].(string))
+	testutil.Equal(t, "private", data["visibility"].(string))
+	testutil.Equal(t, "hi", data["body"].(string))
+	_, ok := data["missing"]
+	testutil.False(t, ok, "columns the table lacks are skipped")
+
+	// Defaults only fill in omitted columns.
+	data = map[string]any{"body": "hi", "visibility": "public"}
+	applyManaged(h, userContext(), data, false)
+	testutil.Equal(t, "public", data["visibility"].(string))
+}
+
+func TestManagedFieldsOnUpdate(t *testing.T) {
+	t.Parallel()
+	h := managedNotesHandler()
+
+	data := map[string]any{"body": "edit", "owner_id": "someone-else", "visibility": "public", "edited_by": "eve"}
+	applyManaged(h, userContext(), data, true)
+	_, ok := data["owner_id"]
+	testutil.False(t, ok, "create-only fields are dropped from updates")
+	_, ok = data["source"]
+	testutil.False(t, ok, "create-only fields aren't set on updates")
+	testutil.Equal(t, "ann@example.com", data["edited_by"].(string))
+	testutil.Equal(t, "public", data["visibility"].(string))
+}
+
+func TestManagedFieldsWithoutClaims(t *testing.T) {
+	t.Parallel()
+	h := managedNotesHandler()
+
+	// Callers without claims, such as the admin token, may set user fields.
+	data := map[string]any{"body": "hi", "owner_id": "chosen-by-admin", "source": "client"}
+	applyManaged(h, context.Background(), data, false)
+	testutil.Equal(t, "chosen-by-admin", data["owner_id"].(string))
+	testutil.Equal(t, "api", data["source"].(string))
+	_, ok := data["edited_by"]
+	testutil.False(t, ok, "user fields are left unset without claims")
+}
+
+func TestManagedFieldValues(t *testing.T) {
+	t.Parallel()
+	claims := &auth.Claims{}
+	testutil.Nil(t, managedValue(ManagedField{From: ManagedFromUserID}, claims))
+	testutil.Equal(t, int64(3), managedValue(ManagedField{Value: int64(3)}, claims).(int64))
+	now := managedValue(ManagedField{From: ManagedFromNow}, claims).(time.Time)
+	testutil.True(t, time.Since(now) < time.Minute, "now should be the current time")
+}
+
+func TestManagedFieldsOnlyInBody(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, testCacheHolder(testSchema()), slog.Default(), nil, nil)
+	h.SetManagedFields(map[string]map[string]ManagedField{"users": {"email": {From: ManagedFromUserEmail}}})
+	routes := h.Routes()
+
+	// A body holding only managed columns has nothing left to write.
+	w := doRequestWithClaims(routes, "POST", "/collections/users", `{"email":"x@example.com"}`, &auth.Claims{})
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "no recognized columns")
+}
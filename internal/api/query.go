@@ -27,7 +27,8 @@ func buildSelectOne(tbl *schema.Table, fields []string, pkValues []string) (stri
 	return q, args
 }
 
-// buildInsert builds an INSERT ... RETURNING * statement.
+// buildInsert builds an INSERT ... RETURNING statement. With no known
+// columns in data every column takes its default.
 func buildInsert(tbl *schema.Table, data map[string]any) (string, []any) {
 	columns := make([]string, 0, len(data))
 	placeholders := make([]string, 0, len(data))
@@ -39,33 +40,26 @@ func buildInsert(tbl *schema.Table, data map[string]any) (string, []any) {
 			continue // skip unknown columns
 		}
 		columns = append(columns, quoteIdent(col))
-		placeholders = append(placeholders, fmt.Sprintf("$%d", i))
+		placeholders = append(placeholders, valueExpr(tbl.ColumnByName(col), i))
 		args = append(args, val)
 		i++
 	}
 
-	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING *",
+	if len(columns) == 0 {
+		return fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s", tableRef(tbl), buildColumnList(tbl, nil)), args
+	}
+	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
 		tableRef(tbl),
 		strings.Join(columns, ", "),
 		strings.Join(placeholders, ", "),
+		buildColumnList(tbl, nil),
 	)
 	return q, args
 }
 
-// buildUpdate builds an UPDATE ... SET ... WHERE pk = ... RETURNING * statement.
+// buildUpdate builds an UPDATE ... SET ... WHERE pk = ... RETURNING statement.
 func buildUpdate(tbl *schema.Table, data map[string]any, pkValues []string) (string, []any) {
-	setClauses := make([]string, 0, len(data))
-	args := make([]any, 0, len(data)+len(tbl.PrimaryKey))
-
-	i := 1
-	for col, val := range data {
-		if tbl.ColumnByName(col) == nil {
-			continue
-		}
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", quoteIdent(col), i))
-		args = append(args, val)
-		i++
-	}
+	setClauses, args, i, expected := buildSet(tbl, data, 1)
 
 	// Build PK where clause starting at current param index.
 	whereParts := make([]string, len(tbl.PrimaryKey))
@@ -74,15 +68,53 @@ func buildUpdate(tbl *schema.Table, data map[string]any, pkValues []string) (str
 		args = append(args, pkValues[j])
 		i++
 	}
+	if expected != nil {
+		whereParts = append(whereParts, fmt.Sprintf("%s = $%d", quoteIdent(versionColumnName), i))
+		args = append(args, expected)
+	}
 
-	q := fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING *",
+	q := fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING %s",
 		tableRef(tbl),
 		strings.Join(setClauses, ", "),
 		strings.Join(whereParts, " AND "),
+		buildColumnList(tbl, nil),
 	)
 	return q, args
 }
 
+// buildSet builds the SET clauses for an update of data, with parameters
+// numbered from next, and returns the next free index. On a versioned table
+// the body's version is the expected current version: the column is
+// incremented rather than set, and the expected value is returned for the
+// caller's WHERE clause. It is nil otherwise.
+func buildSet(tbl *schema.Table, data map[string]any, next int) (sets []string, args []any, _ int, expected any) {
+	version := versionColumn(tbl)
+	expected, versioned := data[versionColumnName]
+	versioned = versioned && version != nil
+	if !versioned {
+		expected = nil
+	}
+
+	for col, val := range data {
+		if tbl.ColumnByName(col) == nil || (versioned && col == version.Name) {
+			continue
+		}
+		sets = append(sets, quoteIdent(col)+" = "+valueExpr(tbl.ColumnByName(col), next))
+		args = append(args, val)
+		next++
+	}
+
+	// Partial jsonb updates ("data->key": value).
+	patchSets, patchArgs, next := jsonPatchSets(data, next)
+	sets = append(sets, patchSets...)
+	args = append(args, patchArgs...)
+
+	if versioned {
+		sets = append(sets, quoteIdent(version.Name)+" = "+quoteIdent(version.Name)+" + 1")
+	}
+	return sets, args, next, expected
+}
+
 // buildDelete builds a DELETE ... WHERE pk = ... statement.
 func buildDelete(tbl *schema.Table, pkValues []string) (string, []any) {
 	where, args := buildPKWhere(tbl, pkValues)
@@ -101,24 +133,69 @@ func buildPKWhere(tbl *schema.Table, pkValues []string) (string, []any) {
 	return strings.Join(parts, " AND "), args
 }
 
-// buildColumnList builds the column selection for SELECT queries.
-// If fields is empty, returns "*".
-func buildColumnList(tbl *schema.Table, fields []string) string {
-	if len(fields) == 0 {
-		return "*"
+// whereClause joins conditions into a WHERE clause, or returns "" for none.
+func whereClause(parts []string) string {
+	if len(parts) == 0 {
+		return ""
 	}
+	return " WHERE " + strings.Join(parts, " AND ")
+}
+
+// buildColumnList builds the column selection for SELECT and RETURNING.
+// If fields is empty, returns "*", or every column when the table has
+// geometry columns, since those must be converted to GeoJSON.
+func buildColumnList(tbl *schema.Table, fields []string) string {
 	quoted := make([]string, 0, len(fields))
 	for _, f := range fields {
-		if tbl.ColumnByName(f) != nil {
-			quoted = append(quoted, quoteIdent(f))
+		if col := tbl.ColumnByName(f); col != nil {
+			quoted = append(quoted, selectExpr(col))
 		}
 	}
-	if len(quoted) == 0 {
+	if len(quoted) > 0 {
+		return strings.Join(quoted, ", ")
+	}
+	if !hasGeometry(tbl) {
 		return "*"
 	}
+	for _, col := range tbl.Columns {
+		quoted = append(quoted, selectExpr(col))
+	}
 	return strings.Join(quoted, ", ")
 }
 
+// hasGeometry reports whether tbl has any PostGIS geometry columns.
+func hasGeometry(tbl *schema.Table) bool {
+	for _, col := range tbl.Columns {
+		if col.IsGeometry {
+			return true
+		}
+	}
+	return false
+}
+
+// selectExpr is the select-list expression for col. Geometry columns are
+// returned as GeoJSON objects rather than raw WKB.
+func selectExpr(col *schema.Column) string {
+	q := quoteIdent(col.Name)
+	if col.IsGeometry {
+		return "ST_AsGeoJSON(" + q + ")::jsonb AS " + q
+	}
+	return q
+}
+
+// valueExpr is the expression for bind parameter n written to col. Geometry
+// columns take GeoJSON text.
+func valueExpr(col *schema.Column, n int) string {
+	ref := fmt.Sprintf("$%d", n)
+	if !col.IsGeometry {
+		return ref
+	}
+	if baseTypeName(col) == "geography" {
+		return "ST_GeomFromGeoJSON(" + ref + ")::geography"
+	}
+	return "ST_GeomFromGeoJSON(" + ref + ")"
+}
+
 // buildList builds a SELECT query for listing records with pagination, sort, and optional filter/search.
 func buildList(tbl *schema.Table, opts listOpts) (dataQuery string, dataArgs []any, countQuery string, countArgs []any) {
 	cols := buildColumnList(tbl, opts.fields)
@@ -138,14 +215,11 @@ func buildList(tbl *schema.Table, opts listOpts) (dataQuery string, dataArgs []a
 		allWhereArgs = append(allWhereArgs, opts.searchArgs...)
 	}
 
-	whereClause := ""
-	if len(whereParts) > 0 {
-		whereClause = " WHERE " + strings.Join(whereParts, " AND ")
-	}
+	where := whereClause(whereParts)
 
 	// Count query (unless skipTotal).
 	if !opts.skipTotal {
-		countQuery = fmt.Sprintf("SELECT COUNT(*) FROM %s%s", ref, whereClause)
+		countQuery = fmt.Sprintf("SELECT COUNT(*) FROM %s%s", ref, where)
 		countArgs = append([]any{}, allWhereArgs...)
 	}
 
@@ -161,7 +235,7 @@ func buildList(tbl *schema.Table, opts listOpts) (dataQuery string, dataArgs []a
 	argIdx := len(allWhereArgs) + 1
 
 	dataQuery = fmt.Sprintf("SELECT %s FROM %s%s%s LIMIT $%d OFFSET $%d",
-		cols, ref, whereClause, orderClause, argIdx, argIdx+1)
+		cols, ref, where, orderClause, argIdx, argIdx+1)
 	dataArgs = append(append([]any{}, allWhereArgs...), opts.perPage, offset)
 
 	return
@@ -169,16 +243,17 @@ func buildList(tbl *schema.Table, opts listOpts) (dataQuery string, dataArgs []a
 
 // listOpts holds the parsed query parameters for a list request.
 type listOpts struct {
-	page       int
-	perPage    int
-	skipTotal  bool
-	fields     []string
-	sortSQL    string
-	filterSQL  string
-	filterArgs []any
-	searchSQL  string // FTS WHERE clause
-	searchRank string // FTS ts_rank() expression for ORDER BY
-	searchArgs []any  // search term parameter
+	page          int
+	perPage       int
+	skipTotal     bool
+	fields        []string
+	sortSQL       string
+	filterSQL     string
+	filterArgs    []any
+	filterColumns []string // compiledFilter.columns
+	searchSQL     string   // FTS WHERE clause
+	searchRank    string   // FTS ts_rank() expression for ORDER BY
+	searchArgs    []any    // search term parameter
 }
 
 // parsePKValues splits a composite primary key value from the URL.
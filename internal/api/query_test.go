@@ -229,8 +229,11 @@ func TestBuildListWithSort(t *testing.T) {
 		sortSQL: `"name" ASC, "age" DESC`,
 	}
 
-	dataQ, _, _, _ := buildList(tbl, opts)
+	dataQ, _, countQ, _ := buildList(tbl, opts)
 	testutil.Contains(t, dataQ, `ORDER BY "name" ASC, "age" DESC`)
+	// The count covers the whole filtered set, so it needs no ordering or paging.
+	testutil.False(t, strings.Contains(countQ, "ORDER BY"), "count query should not sort")
+	testutil.False(t, strings.Contains(countQ, "LIMIT"), "count query should not paginate")
 }
 
 func TestParsePKValues(t *testing.T) {
@@ -247,3 +250,45 @@ func TestParsePKValues(t *testing.T) {
 	testutil.Equal(t, "10", vals[0])
 	testutil.Equal(t, "20", vals[1])
 }
+
+func geoTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "places",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", Position: 1, TypeName: "integer", IsPrimaryKey: true},
+			{Name: "name", Position: 2, TypeName: "text"},
+			{Name: "location", Position: 3, TypeName: "geometry(Point,4326)", JSONType: "object", IsGeometry: true},
+			{Name: "area", Position: 4, TypeName: "geography(Polygon,4326)", JSONType: "object", IsGeometry: true},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestBuildColumnListGeometry(t *testing.T) {
+	t.Parallel()
+	tbl := geoTable()
+
+	testutil.Equal(t,
+		`"id", "name", ST_AsGeoJSON("location")::jsonb AS "location", ST_AsGeoJSON("area")::jsonb AS "area"`,
+		buildColumnList(tbl, nil))
+	testutil.Equal(t, `"id", ST_AsGeoJSON("location")::jsonb AS "location"`,
+		buildColumnList(tbl, []string{"id", "location"}))
+	// Tables without geometry keep SELECT *.
+	testutil.Equal(t, "*", buildColumnList(testTable(), nil))
+}
+
+func TestBuildInsertGeometry(t *testing.T) {
+	t.Parallel()
+	tbl := geoTable()
+
+	q, args := buildInsert(tbl, map[string]any{"location": `{"type":"Point","coordinates":[1,2]}`})
+	testutil.Contains(t, q, `("location") VALUES (ST_GeomFromGeoJSON($1))`)
+	testutil.Contains(t, q, `RETURNING "id", "name", ST_AsGeoJSON("location")::jsonb AS "location"`)
+	testutil.SliceLen(t, args, 1)
+
+	q, _ = buildUpdate(tbl, map[string]any{"area": `{"type":"Polygon","coordinates":[]}`}, []string{"7"})
+	testutil.Contains(t, q, `SET "area" = ST_GeomFromGeoJSON($1)::geography WHERE "id" = $2`)
+	testutil.Contains(t, q, `ST_AsGeoJSON("area")::jsonb AS "area"`)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// ValidateFilter reports whether filter is a valid collection filter for
+// table, for callers such as webhooks that store a filter to evaluate later.
+func (h *Handler) ValidateFilter(table, filter string) error {
+	tbl, err := h.tableByName(table)
+	if err != nil {
+		return err
+	}
+	_, _, err = parseFilter(tbl, filter)
+	return err
+}
+
+// MatchFilter reports whether record, a row of table as published in a
+// realtime event, matches filter. PostgreSQL evaluates the filter against the
+// record itself rather than the stored row, so the result reflects the row
+// as it was when the event was published. Columns missing from record, such
+// as the non-key columns of a delete event, are NULL.
+func (h *Handler) MatchFilter(ctx context.Context, table, filter string, record map[string]any) (bool, error) {
+	tbl, err := h.tableByName(table)
+	if err != nil {
+		return false, err
+	}
+	where, args, err := parseFilter(tbl, filter)
+	if err != nil {
+		return false, err
+	}
+	if where == "" {
+		return true, nil
+	}
+	query, args, err := buildRecordMatch(tbl, where, args, record)
+	if err != nil {
+		return false, err
+	}
+	var ok bool
+	if err := h.pool.QueryRow(ctx, query, args...).Scan(&ok); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// tableByName looks up table in the current schema cache.
+func (h *Handler) tableByName(table string) (*schema.Table, error) {
+	sc := h.schema.Get()
+	if sc == nil {
+		return nil, fmt.Errorf("schema cache not ready")
+	}
+	tbl := sc.TableByName(table)
+	if tbl == nil {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+	return tbl, nil
+}
+
+// buildRecordMatch builds a query returning whether record matches the
+// compiled filter where, whose parameters are filterArgs. The record is
+// expanded to a row of tbl with jsonb_populate_record; geometry columns,
+// which records carry as GeoJSON, are converted separately.
+func buildRecordMatch(tbl *schema.Table, where string, filterArgs []any, record map[string]any) (string, []any, error) {
+	plain := make(map[string]any, len(record))
+	for name, v := range record {
+		if col := tbl.ColumnByName(name); col != nil && !col.IsGeometry {
+			plain[name] = v
+		}
+	}
+	b, err := json.Marshal(plain)
+	if err != nil {
+		return "", nil, fmt.Errorf("encoding record: %w", err)
+	}
+	args := append(append([]any{}, filterArgs...), string(b))
+	recordRef := len(args)
+
+	cols := make([]string, 0, len(tbl.Columns))
+	for _, col := range tbl.Columns {
+		q := quoteIdent(col.Name)
+		if !col.IsGeometry {
+			cols = append(cols, "p."+q)
+			continue
+		}
+		var geoJSON any
+		if v := record[col.Name]; v != nil {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", nil, fmt.Errorf("encoding %s: %w", col.Name, err)
+			}
+			geoJSON = string(b)
+		}
+		args = append(args, geoJSON)
+		cols = append(cols, valueExpr(col, len(args))+" AS "+q)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM (SELECT %s FROM jsonb_populate_record(NULL::%s, $%d::jsonb) AS p) AS r WHERE %s)",
+		strings.Join(cols, ", "), tableRef(tbl), recordRef, where)
+	return query, args, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestBuildRecordMatch(t *testing.T) {
+	t.Parallel()
+	tbl := filterTestTable()
+	where, args, err := parseFilter(tbl, "status='published' && age>18")
+	testutil.NoError(t, err)
+
+	query, args, err := buildRecordMatch(tbl, where, args, map[string]any{
+		"id": float64(7), "status": "published", "unknown": "dropped",
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t,
+		`SELECT EXISTS (SELECT 1 FROM (SELECT p."id", p."name", p."email", p."age", p."status", p."active" `+
+			`FROM jsonb_populate_record(NULL::"public"."users", $3::jsonb) AS p) AS r WHERE `+where+`)`,
+		query)
+	testutil.SliceLen(t, args, 3)
+
+	var record map[string]any
+	testutil.NoError(t, json.Unmarshal([]byte(args[2].(string)), &record))
+	testutil.MapLen(t, record, 2)
+	testutil.Equal(t, "published", record["status"].(string))
+}
+
+func TestBuildRecordMatchGeometry(t *testing.T) {
+	t.Parallel()
+	tbl := geoTable()
+	point := map[string]any{"type": "Point", "coordinates": []any{1.5, 2.5}}
+
+	query, args, err := buildRecordMatch(tbl, `"name" = $1`, []any{"home"}, map[string]any{
+		"id": float64(1), "name": "home", "location": point,
+	})
+	testutil.NoError(t, err)
+	testutil.Contains(t, query, `SELECT p."id", p."name", ST_GeomFromGeoJSON($3) AS "location", `+
+		`ST_GeomFromGeoJSON($4)::geography AS "area" FROM jsonb_populate_record(NULL::"public"."places", $2::jsonb)`)
+	testutil.SliceLen(t, args, 4)
+	// Geometries are passed as GeoJSON text, not inside the record.
+	testutil.Equal(t, `{"id":1,"name":"home"}`, args[1].(string))
+	testutil.Equal(t, `{"coordinates":[1.5,2.5],"type":"Point"}`, args[2].(string))
+	testutil.Nil(t, args[3])
+}
+
+func TestValidateFilter(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, testCacheHolder(&schema.SchemaCache{
+		Tables: map[string]*schema.Table{"public.users": filterTestTable()},
+	}), testutil.DiscardLogger(), nil, nil)
+
+	testutil.NoError(t, h.ValidateFilter("users", "status='published'"))
+	testutil.ErrorContains(t, h.ValidateFilter("users", "missing='x'"), "missing")
+	testutil.ErrorContains(t, h.ValidateFilter("posts", "status='published'"), "unknown table posts")
+
+	// An empty filter matches without a query.
+	ok, err := h.MatchFilter(context.Background(), "users", "", map[string]any{"id": 1})
+	testutil.NoError(t, err)
+	testutil.True(t, ok, "empty filter should match")
+}
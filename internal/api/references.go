@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
+)
+
+// referenceError reports a foreign key value whose referenced row doesn't
+// exist or isn't visible to the caller.
+type referenceError struct {
+	field string // the referencing column; columns joined by "," for composite keys
+	table string // the referenced table
+}
+
+func (e *referenceError) Error() string {
+	return "referenced " + e.table + " not found"
+}
+
+// SetCheckReferences makes creates and updates look up each row referenced
+// through a foreign key before writing, so a dangling reference is reported
+// against its column instead of as a constraint violation. The lookup runs
+// in the request's RLS context, so rows the caller can't read count as
+// missing and existence of hidden rows can't be probed.
+func (h *Handler) SetCheckReferences(on bool) {
+	h.checkRefs = on
+}
+
+// checkReferences verifies, when enabled, that every foreign key fully set
+// in data references an existing row. Keys with a NULL column are skipped,
+// as Postgres does not enforce them, and so are keys only partly present in
+// data, which are left to the constraint itself.
+func (h *Handler) checkReferences(ctx context.Context, q Querier, tbl *schema.Table, data map[string]any) error {
+	if !h.checkRefs {
+		return nil
+	}
+	for _, fk := range tbl.ForeignKeys {
+		refSchema := tenant.Schema(ctx, fk.ReferencedSchema)
+		values, ok := referenceValues(fk, data)
+		if !ok || selfReference(tbl, refSchema, fk, data) {
+			continue
+		}
+		query := buildReferenceCheck(refSchema, fk)
+		var exists bool
+		if err := q.QueryRow(ctx, query, values...).Scan(&exists); err != nil {
+			return fmt.Errorf("checking reference %s: %w", fk.ConstraintName, err)
+		}
+		if !exists {
+			return &referenceError{field: strings.Join(fk.Columns, ","), table: fk.ReferencedTable}
+		}
+	}
+	return nil
+}
+
+// referenceValues returns data's values for fk's columns, or false when any
+// is missing or NULL.
+func referenceValues(fk *schema.ForeignKey, data map[string]any) ([]any, bool) {
+	values := make([]any, len(fk.Columns))
+	for i, col := range fk.Columns {
+		v, ok := data[col]
+		if !ok || v == nil {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// selfReference reports whether fk points at the row being written, as when
+// a new row references its own primary key. That row doesn't exist until the
+// write, so the constraint is left to check it.
+func selfReference(tbl *schema.Table, refSchema string, fk *schema.ForeignKey, data map[string]any) bool {
+	if refSchema != tbl.Schema || fk.ReferencedTable != tbl.Name {
+		return false
+	}
+	for i, col := range fk.ReferencedColumns {
+		v, ok := data[col]
+		if !ok || !reflect.DeepEqual(v, data[fk.Columns[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildReferenceCheck builds the existence query for fk's referenced row in
+// refSchema, with one parameter per key column.
+func buildReferenceCheck(refSchema string, fk *schema.ForeignKey) string {
+	conds := make([]string, len(fk.ReferencedColumns))
+	for i, col := range fk.ReferencedColumns {
+		conds[i] = fmt.Sprintf("%s = $%d", quoteIdent(col), i+1)
+	}
+	return fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s.%s WHERE %s)",
+		quoteIdent(refSchema), quoteIdent(fk.ReferencedTable), strings.Join(conds, " AND "))
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// refQuerier answers reference checks with exists and records each query.
+type refQuerier struct {
+	exists  bool
+	queries []string
+	args    [][]any
+}
+
+func (q *refQuerier) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected Query")
+}
+
+func (q *refQuerier) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	q.queries = append(q.queries, sql)
+	q.args = append(q.args, args)
+	return refRow{exists: q.exists}
+}
+
+func (q *refQuerier) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected Exec")
+}
+
+type refRow struct{ exists bool }
+
+func (r refRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = r.exists
+	return nil
+}
+
+func referencingTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "posts",
+		Columns: []*schema.Column{
+			{Name: "id"}, {Name: "author_id"}, {Name: "parent_id"},
+		},
+		PrimaryKey: []string{"id"},
+		ForeignKeys: []*schema.ForeignKey{
+			{ConstraintName: "posts_author_id_fkey", Columns: []string{"author_id"},
+				ReferencedSchema: "public", ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+			{ConstraintName: "posts_parent_id_fkey", Columns: []string{"parent_id"},
+				ReferencedSchema: "public", ReferencedTable: "posts", ReferencedColumns: []string{"id"}},
+		},
+	}
+}
+
+func TestCheckReferencesDisabled(t *testing.T) {
+	t.Parallel()
+	h := &Handler{}
+	q := &refQuerier{}
+	err := h.checkReferences(context.Background(), q, referencingTable(), map[string]any{"author_id": 9})
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, q.queries, 0)
+}
+
+func TestCheckReferencesFound(t *testing.T) {
+	t.Parallel()
+	h := &Handler{checkRefs: true}
+	q := &refQuerier{exists: true}
+	err := h.checkReferences(context.Background(), q, referencingTable(), map[string]any{"author_id": int64(1)})
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, q.queries, 1)
+	testutil.Equal(t, `SELECT EXISTS (SELECT 1 FROM "public"."users" WHERE "id" = $1)`, q.queries[0])
+	testutil.Equal(t, int64(1), q.args[0][0].(int64))
+}
+
+func TestCheckReferencesMissing(t *testing.T) {
+	t.Parallel()
+	h := &Handler{checkRefs: true}
+	q := &refQuerier{exists: false}
+	err := h.checkReferences(context.Background(), q, referencingTable(), map[string]any{"author_id": int64(9)})
+	var refErr *referenceError
+	testutil.True(t, errors.As(err, &refErr), "expected referenceError, got %v", err)
+	testutil.Equal(t, "author_id", refErr.field)
+	testutil.Equal(t, "referenced users not found", refErr.Error())
+}
+
+func TestCheckReferencesSkips(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data map[string]any
+	}{
+		{"column not written", map[string]any{"id": int64(1)}},
+		{"null reference", map[string]any{"author_id": nil}},
+		{"row references itself", map[string]any{"id": int64(5), "parent_id": int64(5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := &Handler{checkRefs: true}
+			q := &refQuerier{exists: false}
+			testutil.NoError(t, h.checkReferences(context.Background(), q, referencingTable(), tt.data))
+			testutil.SliceLen(t, q.queries, 0)
+		})
+	}
+}
+
+func TestBuildReferenceCheckComposite(t *testing.T) {
+	t.Parallel()
+	fk := &schema.ForeignKey{
+		Columns:           []string{"org_id", "member_id"},
+		ReferencedSchema:  "app",
+		ReferencedTable:   "members",
+		ReferencedColumns: []string{"org_id", "id"},
+	}
+	testutil.Equal(t,
+		`SELECT EXISTS (SELECT 1 FROM "app"."members" WHERE "org_id" = $1 AND "id" = $2)`,
+		buildReferenceCheck("app", fk))
+
+	values, ok := referenceValues(fk, map[string]any{"org_id": "o1", "member_id": "m1"})
+	testutil.True(t, ok, "both columns present")
+	testutil.SliceLen(t, values, 2)
+
+	_, ok = referenceValues(fk, map[string]any{"org_id": "o1"})
+	testutil.False(t, ok, "partial key should be skipped")
+}
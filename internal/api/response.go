@@ -22,25 +22,45 @@ type ListResponse struct {
 // Package-level aliases for the shared HTTP helpers so existing call sites
 // within this package continue to compile without changes.
 var (
-	writeJSON         = httputil.WriteJSON
-	writeError        = httputil.WriteError
-	writeErrorWithDoc = httputil.WriteErrorWithDocURL
-	docURL            = httputil.DocURL
+	writeJSON                 = httputil.WriteJSON
+	writeError                = httputil.WriteError
+	writeErrorWithDoc         = httputil.WriteErrorWithDocURL
+	writeFieldErrorWithDocURL = httputil.WriteFieldErrorWithDocURL
+	docURL                    = httputil.DocURL
 )
 
-// WriteFieldErrorWithDocURL writes an error response with field-level validation detail and a doc URL.
-func writeFieldErrorWithDocURL(w http.ResponseWriter, status int, message string, field, fieldCode, fieldMsg, docURL string) {
-	httputil.WriteJSON(w, status, httputil.ErrorResponse{
-		Code:    status,
-		Message: message,
-		Data: map[string]any{
-			field: map[string]string{
-				"code":    fieldCode,
-				"message": fieldMsg,
-			},
-		},
-		DocURL: docURL,
-	})
+// preferReturn returns the return preference of a PostgREST-style Prefer
+// header ("minimal" or "representation"), or "" when none was sent. When
+// several are sent, the last one wins.
+func preferReturn(r *http.Request) string {
+	var ret string
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "return") {
+				continue
+			}
+			switch v := strings.ToLower(strings.TrimSpace(value)); v {
+			case "minimal", "representation":
+				ret = v
+			}
+		}
+	}
+	return ret
+}
+
+// writeRecord writes the row a create or update returned with status. With
+// Prefer: return=minimal it responds 204 with no body instead.
+func writeRecord(w http.ResponseWriter, r *http.Request, status int, record map[string]any) {
+	ret := preferReturn(r)
+	if ret != "" {
+		w.Header().Set("Preference-Applied", "return="+ret)
+	}
+	if ret == "minimal" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, status, record)
 }
 
 // mapPGError converts a pgx/pgconn error to an appropriate HTTP response.
@@ -54,14 +74,31 @@ func mapPGError(w http.ResponseWriter, err error) bool {
 		writeError(w, http.StatusNotFound, "record not found")
 		return true
 	}
+	if errors.Is(err, errVersionConflict) {
+		writeErrorWithDoc(w, http.StatusConflict, err.Error(), docURL("/guide/api-reference#optimistic-concurrency"))
+		return true
+	}
+
+	var hookErr *HookError
+	if errors.As(err, &hookErr) {
+		writeError(w, hookErr.Status, hookErr.Message)
+		return true
+	}
+
+	constraintDoc := docURL("/guide/api-reference#error-format")
+
+	var refErr *referenceError
+	if errors.As(err, &refErr) {
+		writeFieldErrorWithDocURL(w, http.StatusBadRequest, "foreign key violation",
+			refErr.field, "foreign_key_violation", refErr.Error(), constraintDoc)
+		return true
+	}
 
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return false
 	}
 
-	constraintDoc := docURL("/guide/api-reference#error-format")
-
 	switch pgErr.Code {
 	case "P0001": // raise_exception — user-defined exceptions from PL/pgSQL RAISE EXCEPTION
 		writeError(w, http.StatusBadRequest, pgErr.Message)
@@ -81,6 +118,8 @@ func mapPGError(w http.ResponseWriter, err error) bool {
 		writeErrorWithDoc(w, http.StatusBadRequest, friendlyTypeError(pgErr.Message), constraintDoc)
 	case "42501": // insufficient_privilege — raised by RLS WITH CHECK policy violations
 		writeError(w, http.StatusForbidden, "insufficient permissions")
+	case "57014": // query_canceled — raised when statement_timeout expires
+		writeError(w, http.StatusGatewayTimeout, "statement timed out")
 	default:
 		return false
 	}
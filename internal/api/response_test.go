@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -21,6 +22,7 @@ func TestMapPGError(t *testing.T) {
 		name       string
 		err        error
 		wantCode   int
+		wantErr    string
 		wantMsg    string
 		wantDocURL string
 		wantResult bool // true if mapPGError handled the error
@@ -34,6 +36,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "ErrNoRows returns 404",
 			err:        pgx.ErrNoRows,
 			wantCode:   http.StatusNotFound,
+			wantErr:    httputil.CodeNotFound,
 			wantMsg:    "record not found",
 			wantResult: true,
 		},
@@ -41,6 +44,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "raise_exception returns 400",
 			err:        &pgconn.PgError{Code: "P0001", Message: "age must be positive"},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeBadRequest,
 			wantMsg:    "age must be positive",
 			wantResult: true,
 		},
@@ -48,6 +52,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "unique_violation returns 409 with doc_url",
 			err:        &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key", Detail: "Key (email)=(a@b.com) already exists."},
 			wantCode:   http.StatusConflict,
+			wantErr:    httputil.CodeConflict,
 			wantMsg:    "unique constraint violation",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -56,14 +61,33 @@ func TestMapPGError(t *testing.T) {
 			name:       "foreign_key_violation returns 400 with doc_url",
 			err:        &pgconn.PgError{Code: "23503", ConstraintName: "posts_author_id_fkey", Detail: "Key (author_id)=(999) is not present in table users."},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeValidationFailed,
 			wantMsg:    "foreign key violation",
 			wantDocURL: constraintDoc,
 			wantResult: true,
 		},
+		{
+			name:       "failed reference check returns 400 like foreign_key_violation",
+			err:        fmt.Errorf("wrapped: %w", &referenceError{field: "author_id", table: "users"}),
+			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeValidationFailed,
+			wantMsg:    "foreign key violation",
+			wantDocURL: constraintDoc,
+			wantResult: true,
+		},
+		{
+			name:       "hook rejection returns the hook's status",
+			err:        fmt.Errorf("wrapped: %w", &HookError{Status: http.StatusUnprocessableEntity, Message: "signups are closed"}),
+			wantCode:   http.StatusUnprocessableEntity,
+			wantErr:    httputil.CodeValidationFailed,
+			wantMsg:    "signups are closed",
+			wantResult: true,
+		},
 		{
 			name:       "not_null_violation returns 400 with doc_url",
 			err:        &pgconn.PgError{Code: "23502", ColumnName: "title", Message: "null value in column \"title\""},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeValidationFailed,
 			wantMsg:    "missing required value",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -72,6 +96,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "check_violation returns 400 with doc_url",
 			err:        &pgconn.PgError{Code: "23514", ConstraintName: "positive_price", Detail: "Failing row contains (-1)."},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeValidationFailed,
 			wantMsg:    "check constraint violation",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -80,6 +105,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "invalid_text_representation uuid returns friendly hint with doc_url",
 			err:        &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type uuid: "4234234"`},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeBadRequest,
 			wantMsg:    "invalid uuid value \u2014 expected format: 550e8400-e29b-41d4-a716-446655440000",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -88,6 +114,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "invalid_text_representation integer returns friendly hint with doc_url",
 			err:        &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type integer: "abc"`},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeBadRequest,
 			wantMsg:    "invalid integer value \u2014 expected a whole number, e.g. 42",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -96,6 +123,7 @@ func TestMapPGError(t *testing.T) {
 			name:       "invalid_text_representation unknown type falls back with doc_url",
 			err:        &pgconn.PgError{Code: "22P02", Message: "invalid input syntax for type sometype"},
 			wantCode:   http.StatusBadRequest,
+			wantErr:    httputil.CodeBadRequest,
 			wantMsg:    "invalid value: invalid input syntax for type sometype",
 			wantDocURL: constraintDoc,
 			wantResult: true,
@@ -104,9 +132,18 @@ func TestMapPGError(t *testing.T) {
 			name:       "insufficient_privilege (42501) returns 403 — RLS WITH CHECK violation",
 			err:        &pgconn.PgError{Code: "42501", Message: "new row violates row-level security policy for table \"polls\""},
 			wantCode:   http.StatusForbidden,
+			wantErr:    httputil.CodeForbidden,
 			wantMsg:    "insufficient permissions",
 			wantResult: true,
 		},
+		{
+			name:       "query_canceled (57014) returns 504 — statement_timeout expired",
+			err:        &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"},
+			wantCode:   http.StatusGatewayTimeout,
+			wantErr:    httputil.CodeTimeout,
+			wantMsg:    "statement timed out",
+			wantResult: true,
+		},
 		{
 			name:       "unhandled PG error code returns false",
 			err:        &pgconn.PgError{Code: "42P01", Message: "relation does not exist"},
@@ -132,9 +169,9 @@ func TestMapPGError(t *testing.T) {
 				var resp httputil.ErrorResponse
 				err := json.NewDecoder(w.Body).Decode(&resp)
 				testutil.NoError(t, err)
-				testutil.Equal(t, tt.wantCode, resp.Code)
-				testutil.Equal(t, tt.wantMsg, resp.Message)
-				testutil.Equal(t, tt.wantDocURL, resp.DocURL)
+				testutil.Equal(t, tt.wantErr, resp.Error.Code)
+				testutil.Equal(t, tt.wantMsg, resp.Error.Message)
+				testutil.Equal(t, tt.wantDocURL, resp.Error.DocURL)
 			}
 		})
 	}
@@ -190,3 +227,52 @@ func TestFriendlyTypeError(t *testing.T) {
 		})
 	}
 }
+
+func TestPreferReturn(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{"absent", nil, ""},
+		{"minimal", []string{"return=minimal"}, "minimal"},
+		{"representation", []string{"return=representation"}, "representation"},
+		{"case and spacing", []string{" Return = Minimal "}, "minimal"},
+		{"among other preferences", []string{"count=exact, return=minimal"}, "minimal"},
+		{"last wins", []string{"return=minimal", "return=representation"}, "representation"},
+		{"unknown value ignored", []string{"return=headers-only"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			for _, h := range tt.headers {
+				r.Header.Add("Prefer", h)
+			}
+			testutil.Equal(t, tt.want, preferReturn(r))
+		})
+	}
+}
+
+func TestWriteRecordPreferMinimal(t *testing.T) {
+	t.Parallel()
+	record := map[string]any{"id": float64(1)}
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Prefer", "return=minimal")
+	w := httptest.NewRecorder()
+	writeRecord(w, r, http.StatusCreated, record)
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, 0, w.Body.Len())
+	testutil.Equal(t, "return=minimal", w.Header().Get("Preference-Applied"))
+
+	r = httptest.NewRequest(http.MethodPost, "/", nil)
+	w = httptest.NewRecorder()
+	writeRecord(w, r, http.StatusCreated, record)
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "", w.Header().Get("Preference-Applied"))
+	var got map[string]any
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	testutil.Equal(t, float64(1), got["id"].(float64))
+}
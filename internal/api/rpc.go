@@ -9,6 +9,7 @@ import (
 
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -25,9 +26,11 @@ func (h *Handler) handleRPC(w http.ResponseWriter, r *http.Request) {
 	// Decode JSON body as named arguments (empty body = no args).
 	var args map[string]any
 	if r.ContentLength > 0 {
-		r.Body = http.MaxBytesReader(w, r.Body, httputil.MaxBodySize)
+		if !httputil.LimitBody(w, r) {
+			return
+		}
 		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			httputil.WriteDecodeError(w, err)
 			return
 		}
 	}
@@ -110,7 +113,9 @@ func (h *Handler) handleRPC(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, record)
 }
 
-// resolveFunction looks up the function in the schema cache and validates it exists.
+// resolveFunction looks up the function in the schema cache and validates it
+// exists. In schema-per-tenant mode the function is redirected to the
+// request's tenant schema.
 func (h *Handler) resolveFunction(w http.ResponseWriter, r *http.Request) *schema.Function {
 	sc := h.schema.Get()
 	if sc == nil {
@@ -124,7 +129,7 @@ func (h *Handler) resolveFunction(w http.ResponseWriter, r *http.Request) *schem
 		writeError(w, http.StatusNotFound, "function not found: "+funcName)
 		return nil
 	}
-	return fn
+	return tenant.Function(r.Context(), fn)
 }
 
 // buildRPCCall generates the SQL and args for calling a function.
@@ -254,6 +254,7 @@ func TestRPCErrorResponseIsJSON(t *testing.T) {
 
 	var resp map[string]any
 	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
-	testutil.Equal(t, float64(404), resp["code"].(float64))
-	testutil.Contains(t, resp["message"].(string), "function")
+	body := resp["error"].(map[string]any)
+	testutil.Equal(t, "not_found", body["code"].(string))
+	testutil.Contains(t, body["message"].(string), "function")
 }
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// FieldRule validates the values written to one column. Nil fields don't
+// apply.
+type FieldRule struct {
+	MinLength *int // characters of a string, or elements of an array
+	MaxLength *int
+	Pattern   *regexp.Regexp // strings must match
+	Min       *float64       // numbers must be at least Min
+	Max       *float64
+	Enum      []string // allowed values, compared as text
+}
+
+// SetFieldRules sets the rules checked against values written through the
+// collections API, batch requests and gRPC, keyed by table and then column.
+func (h *Handler) SetFieldRules(rules map[string]map[string]FieldRule) {
+	h.fieldRules = rules
+}
+
+// ruleViolation is a value that breaks a rule. Rule is the config key of the
+// rule, e.g. "min_length".
+type ruleViolation struct {
+	field   string
+	rule    string
+	message string
+}
+
+// fieldRuleError reports the values in a write that break field rules, one
+// per column, sorted by column.
+type fieldRuleError struct {
+	violations []ruleViolation
+}
+
+func (e *fieldRuleError) Error() string {
+	parts := make([]string, len(e.violations))
+	for i, v := range e.violations {
+		parts[i] = v.field + " " + v.message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// checkFieldRules checks the values in data against the field rules of tbl.
+// Null values and absent columns are left to the schema. It returns nil if
+// every value passes.
+func (h *Handler) checkFieldRules(tbl *schema.Table, data map[string]any) *fieldRuleError {
+	rules := h.fieldRules[tbl.Name]
+	if len(rules) == 0 {
+		return nil
+	}
+	var violations []ruleViolation
+	for name, rule := range rules {
+		v, ok := data[name]
+		col := tbl.ColumnByName(name)
+		if !ok || v == nil || col == nil {
+			continue
+		}
+		if r, msg := rule.check(col, v); r != "" {
+			violations = append(violations, ruleViolation{field: name, rule: r, message: msg})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].field < violations[j].field })
+	return &fieldRuleError{violations: violations}
+}
+
+// check returns the first rule v breaks and a message describing it, or ""
+// if v passes. Rules that don't apply to v's type are skipped.
+func (r FieldRule) check(col *schema.Column, v any) (rule, message string) {
+	if r.Enum != nil {
+		if text, ok := enumText(v); ok && !slices.Contains(r.Enum, text) {
+			return "enum", "must be one of: " + strings.Join(r.Enum, ", ")
+		}
+	}
+
+	if n, unit, ok := valueLength(v); ok && !isNumericColumn(col) {
+		if r.MinLength != nil && n < *r.MinLength {
+			return "min_length", fmt.Sprintf("must have at least %d %s", *r.MinLength, unit)
+		}
+		if r.MaxLength != nil && n > *r.MaxLength {
+			return "max_length", fmt.Sprintf("must have at most %d %s", *r.MaxLength, unit)
+		}
+	}
+
+	if s, ok := v.(string); ok && r.Pattern != nil && !isNumericColumn(col) && !r.Pattern.MatchString(s) {
+		return "pattern", "must match " + r.Pattern.String()
+	}
+
+	if f, ok := numericValue(col, v); ok {
+		if r.Min != nil && f < *r.Min {
+			return "min", "must be at least " + strconv.FormatFloat(*r.Min, 'f', -1, 64)
+		}
+		if r.Max != nil && f > *r.Max {
+			return "max", "must be at most " + strconv.FormatFloat(*r.Max, 'f', -1, 64)
+		}
+	}
+	return "", ""
+}
+
+// enumText returns the text of a scalar value for comparison with an enum.
+func enumText(v any) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// valueLength returns the length of a string in characters or of an array
+// in elements, and the unit for error messages.
+func valueLength(v any) (int, string, bool) {
+	switch x := v.(type) {
+	case string:
+		return utf8.RuneCountInString(x), "characters", true
+	case []any:
+		return len(x), "items", true
+	}
+	return 0, "", false
+}
+
+// isNumericColumn reports whether col holds numbers.
+func isNumericColumn(col *schema.Column) bool {
+	return col.JSONType == "integer" || col.JSONType == "number"
+}
+
+// numericValue returns the value of a number written to a numeric column.
+// Numeric strings, which coerceValue keeps for arbitrary precision, are
+// parsed too.
+func numericValue(col *schema.Column, v any) (float64, bool) {
+	if !isNumericColumn(col) {
+		return 0, false
+	}
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// writeFieldRuleError writes a 422 response with a details entry per value
+// that broke a rule, keyed by column, in the same shape as other field
+// errors.
+func writeFieldRuleError(w http.ResponseWriter, message string, e *fieldRuleError) {
+	details := make(map[string]any, len(e.violations))
+	for _, v := range e.violations {
+		details[v.field] = httputil.FieldError{Code: v.rule, Message: v.message}
+	}
+	httputil.WriteErrorBody(w, http.StatusUnprocessableEntity, httputil.ErrorBody{
+		Code:    httputil.CodeValidationFailed,
+		Message: message,
+		Details: details,
+		DocURL:  docURL("/guide/api-reference#field-validation"),
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func productsTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "products",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", TypeName: "integer", JSONType: "integer", IsPrimaryKey: true},
+			{Name: "name", TypeName: "text", JSONType: "string"},
+			{Name: "sku", TypeName: "text", JSONType: "string"},
+			{Name: "status", TypeName: "text", JSONType: "string"},
+			{Name: "tags", TypeName: "text[]", JSONType: "array", IsArray: true},
+			{Name: "price", TypeName: "numeric", JSONType: "number"},
+			{Name: "stock", TypeName: "integer", JSONType: "integer"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func productRulesHandler() *Handler {
+	n := func(v int) *int { return &v }
+	f := func(v float64) *float64 { return &v }
+	h := NewHandler(nil, nil, slog.Default(), nil, nil)
+	h.SetFieldRules(map[string]map[string]FieldRule{"products": {
+		"name":   {MinLength: n(3), MaxLength: n(10)},
+		"sku":    {Pattern: regexp.MustCompile(`^[A-Z]{3}-\d+$`)},
+		"status": {Enum: []string{"draft", "live"}},
+		"tags":   {MaxLength: n(2)},
+		"price":  {Min: f(0), Max: f(999.99)},
+		"stock":  {Min: f(0)},
+	}})
+	return h
+}
+
+func TestFieldRules(t *testing.T) {
+	t.Parallel()
+	h := productRulesHandler()
+	tbl := productsTable()
+
+	tests := []struct {
+		name     string
+		data     map[string]any
+		wantRule string
+		wantMsg  string
+	}{
+		{name: "min_length", data: map[string]any{"name": "ab"}, wantRule: "min_length", wantMsg: "must have at least 3 characters"},
+		{name: "max_length counts characters", data: map[string]any{"name": "ééééééééééé"}, wantRule: "max_length", wantMsg: "must have at most 10 characters"},
+		{name: "max_length of array", data: map[string]any{"tags": []any{"a", "b", "c"}}, wantRule: "max_length", wantMsg: "must have at most 2 items"},
+		{name: "pattern", data: map[string]any{"sku": "abc-1"}, wantRule: "pattern", wantMsg: `must match ^[A-Z]{3}-\d+$`},
+		{name: "enum", data: map[string]any{"status": "archived"}, wantRule: "enum", wantMsg: "must be one of: draft, live"},
+		{name: "min", data: map[string]any{"stock": int64(-1)}, wantRule: "min", wantMsg: "must be at least 0"},
+		{name: "max", data: map[string]any{"price": 1000.5}, wantRule: "max", wantMsg: "must be at most 999.99"},
+		{name: "max of numeric string", data: map[string]any{"price": "1000.00"}, wantRule: "max", wantMsg: "must be at most 999.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ferr := h.checkFieldRules(tbl, tt.data)
+			testutil.NotNil(t, ferr)
+			testutil.SliceLen(t, ferr.violations, 1)
+			testutil.Equal(t, tt.wantRule, ferr.violations[0].rule)
+			testutil.Equal(t, tt.wantMsg, ferr.violations[0].message)
+		})
+	}
+}
+
+func TestFieldRulesPass(t *testing.T) {
+	t.Parallel()
+	h := productRulesHandler()
+
+	ferr := h.checkFieldRules(productsTable(), map[string]any{
+		"name":   "Widget",
+		"sku":    "WID-42",
+		"status": "live",
+		"tags":   []any{"new"},
+		"price":  "19.99",
+		"stock":  int64(0),
+	})
+	testutil.True(t, ferr == nil, "valid payload should pass")
+
+	// Null values and absent columns are left to the schema.
+	ferr = h.checkFieldRules(productsTable(), map[string]any{"name": nil})
+	testutil.True(t, ferr == nil, "null value should be skipped")
+}
+
+func TestFieldRulesReportsEveryField(t *testing.T) {
+	t.Parallel()
+	h := productRulesHandler()
+
+	ferr := h.checkFieldRules(productsTable(), map[string]any{"status": "x", "name": "x", "stock": int64(5)})
+	testutil.NotNil(t, ferr)
+	testutil.SliceLen(t, ferr.violations, 2)
+	testutil.Equal(t, "name", ferr.violations[0].field)
+	testutil.Equal(t, "status", ferr.violations[1].field)
+	testutil.Equal(t, "validation failed: name must have at least 3 characters; status must be one of: draft, live", ferr.Error())
+}
+
+func TestFieldRulesRejectWrites(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(nil, testCacheHolder(testSchema()), slog.Default(), nil, nil)
+	n := 3
+	h.SetFieldRules(map[string]map[string]FieldRule{"users": {"name": {MinLength: &n}}})
+	routes := h.Routes()
+
+	w := doRequest(routes, "POST", "/collections/users", `{"name":"al"}`)
+	testutil.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	resp := decodeError(t, w)
+	testutil.Equal(t, "validation failed", resp.Message)
+	field := resp.Details["name"].(map[string]any)
+	testutil.Equal(t, "min_length", field["code"].(string))
+	testutil.Equal(t, "must have at least 3 characters", field["message"].(string))
+
+	w = doRequest(routes, "POST", "/collections/users/batch",
+		`{"operations":[{"method":"create","body":{"name":"alice"}},{"method":"update","id":"1","body":{"name":"b"}}]}`)
+	testutil.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	testutil.Equal(t, "operation[1]: validation failed", decodeError(t, w).Message)
+}
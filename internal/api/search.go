@@ -23,12 +23,7 @@ func isTextColumn(col *schema.Column) bool {
 	if col.IsJSON || col.IsArray || col.IsEnum {
 		return false
 	}
-	// Normalize: strip modifiers like (255).
-	base := strings.ToLower(col.TypeName)
-	if idx := strings.Index(base, "("); idx > 0 {
-		base = strings.TrimSpace(base[:idx])
-	}
-	return textColumnTypes[base]
+	return textColumnTypes[baseTypeName(col)]
 }
 
 // textColumns returns the names of all text columns in a table.
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// slowQueryLogSize caps the slow list queries kept for index suggestions;
+// older ones are dropped first.
+const slowQueryLogSize = 1000
+
+// slowQuery is a list query that ran for at least the slow query threshold.
+type slowQuery struct {
+	schema, table string
+	columns       []string // compiledFilter.columns
+}
+
+// slowQueryLog is a fixed-size ring of the most recent slow queries.
+type slowQueryLog struct {
+	mu      sync.Mutex
+	entries []slowQuery
+	next    int // index the next entry overwrites once entries is full
+}
+
+func (l *slowQueryLog) add(q slowQuery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) < slowQueryLogSize {
+		l.entries = append(l.entries, q)
+		return
+	}
+	l.entries[l.next] = q
+	l.next = (l.next + 1) % slowQueryLogSize
+}
+
+func (l *slowQueryLog) snapshot() []slowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.entries)
+}
+
+// SetSlowQueryThreshold logs list queries that run for at least d and keeps
+// them for SuggestIndexes. 0 (the default) disables slow query logging.
+func (h *Handler) SetSlowQueryThreshold(d time.Duration) {
+	h.slowQueryMin = d
+}
+
+// observeList logs a list query that took elapsed if it was slow.
+func (h *Handler) observeList(tbl *schema.Table, opts listOpts, elapsed time.Duration) {
+	if h.slowQueryMin <= 0 || elapsed < h.slowQueryMin {
+		return
+	}
+	h.logger.Warn("slow query", "table", tbl.Name, "duration_ms", elapsed.Milliseconds(),
+		"filter_columns", opts.filterColumns)
+	h.slowQueries.add(slowQuery{schema: tbl.Schema, table: tbl.Name, columns: opts.filterColumns})
+}
+
+// IndexSuggestion is a candidate index on a column that logged slow queries
+// filter on and that no existing index starts with.
+type IndexSuggestion struct {
+	Schema      string `json:"schema"`
+	Table       string `json:"table"`
+	Column      string `json:"column"`
+	SlowQueries int    `json:"slowQueries"` // logged slow queries filtering on Column
+	SQL         string `json:"sql"`         // suggested CREATE INDEX statement
+}
+
+// SuggestIndexes returns index suggestions for the logged slow queries, most
+// frequent first. Columns already leading an index in the schema cache are
+// skipped. It is advisory only: nothing is created.
+func (h *Handler) SuggestIndexes() []IndexSuggestion {
+	var tables map[string]*schema.Table
+	if sc := h.schema.Get(); sc != nil {
+		tables = sc.Tables
+	}
+
+	type key struct{ schema, table, column string }
+	counts := make(map[key]int)
+	for _, q := range h.slowQueries.snapshot() {
+		for _, col := range q.columns {
+			counts[key{q.schema, q.table, col}]++
+		}
+	}
+
+	suggestions := make([]IndexSuggestion, 0, len(counts))
+	for k, n := range counts {
+		tbl := tables[k.schema+"."+k.table]
+		if tbl == nil || tbl.ColumnByName(k.column) == nil || hasLeadingIndex(tbl, k.column) {
+			continue
+		}
+		suggestions = append(suggestions, IndexSuggestion{
+			Schema:      k.schema,
+			Table:       k.table,
+			Column:      k.column,
+			SlowQueries: n,
+			SQL:         "CREATE INDEX ON " + tableRef(tbl) + " (" + quoteIdent(k.column) + ")",
+		})
+	}
+	slices.SortFunc(suggestions, func(a, b IndexSuggestion) int {
+		return cmp.Or(
+			cmp.Compare(b.SlowQueries, a.SlowQueries),
+			cmp.Compare(a.Schema, b.Schema),
+			cmp.Compare(a.Table, b.Table),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+	return suggestions
+}
+
+// hasLeadingIndex reports whether an index on tbl has column as its first key
+// column, so it can serve filters on column alone.
+func hasLeadingIndex(tbl *schema.Table, column string) bool {
+	for _, idx := range tbl.Indexes {
+		if len(idx.Columns) > 0 && (idx.Columns[0] == column || idx.Columns[0] == quoteIdent(column)) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleIndexSuggestions handles GET /admin/stats/index-suggestions.
+func (h *Handler) HandleIndexSuggestions(w http.ResponseWriter, r *http.Request) {
+	suggestions := h.SuggestIndexes()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": suggestions,
+		"count": len(suggestions),
+	})
+}
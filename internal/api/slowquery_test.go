@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func slowQueryTestHandler() *Handler {
+	sc := &schema.SchemaCache{
+		Tables: map[string]*schema.Table{
+			"public.posts": {
+				Schema: "public",
+				Name:   "posts",
+				Kind:   "table",
+				Columns: []*schema.Column{
+					{Name: "id", TypeName: "integer"},
+					{Name: "author_id", TypeName: "integer"},
+					{Name: "status", TypeName: "text"},
+					{Name: "title", TypeName: "text"},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []*schema.Index{
+					{Name: "posts_pkey", IsPrimary: true, Columns: []string{"id"}},
+					{Name: "posts_status_title", Columns: []string{"status", "title"}},
+				},
+			},
+		},
+		Schemas: []string{"public"},
+	}
+	h := NewHandler(nil, testCacheHolder(sc), slog.Default(), nil, nil)
+	h.SetSlowQueryThreshold(100 * time.Millisecond)
+	return h
+}
+
+func TestSlowQueryLogKeepsMostRecent(t *testing.T) {
+	t.Parallel()
+	var l slowQueryLog
+	for i := range slowQueryLogSize + 5 {
+		l.add(slowQuery{table: fmt.Sprintf("t%d", i)})
+	}
+
+	entries := l.snapshot()
+	testutil.SliceLen(t, entries, slowQueryLogSize)
+	tables := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		tables[e.table] = true
+	}
+	testutil.False(t, tables["t4"], "oldest entries should be dropped")
+	testutil.True(t, tables["t5"], "t5 should be kept")
+	testutil.True(t, tables[fmt.Sprintf("t%d", slowQueryLogSize+4)], "newest entry should be kept")
+}
+
+func TestObserveListThreshold(t *testing.T) {
+	t.Parallel()
+	h := slowQueryTestHandler()
+	tbl := h.schema.Get().Tables["public.posts"]
+	opts := listOpts{filterColumns: []string{"author_id"}}
+
+	h.observeList(tbl, opts, 50*time.Millisecond)
+	testutil.SliceLen(t, h.slowQueries.snapshot(), 0)
+	h.observeList(tbl, opts, 100*time.Millisecond)
+	testutil.SliceLen(t, h.slowQueries.snapshot(), 1)
+
+	h.SetSlowQueryThreshold(0)
+	h.observeList(tbl, opts, time.Minute)
+	testutil.SliceLen(t, h.slowQueries.snapshot(), 1)
+}
+
+func TestSuggestIndexes(t *testing.T) {
+	t.Parallel()
+	h := slowQueryTestHandler()
+	for _, cols := range [][]string{
+		{"author_id", "status"},
+		{"author_id"},
+		{"author_id", "title"},
+		{"id"},
+	} {
+		h.slowQueries.add(slowQuery{schema: "public", table: "posts", columns: cols})
+	}
+	// Tables and columns no longer in the schema are ignored.
+	h.slowQueries.add(slowQuery{schema: "public", table: "dropped", columns: []string{"x"}})
+	h.slowQueries.add(slowQuery{schema: "public", table: "posts", columns: []string{"gone"}})
+
+	got := h.SuggestIndexes()
+	// status and id lead an index. title is only the second column of one.
+	testutil.SliceLen(t, got, 2)
+	testutil.Equal(t, "author_id", got[0].Column)
+	testutil.Equal(t, 3, got[0].SlowQueries)
+	testutil.Equal(t, `CREATE INDEX ON "public"."posts" ("author_id")`, got[0].SQL)
+	testutil.Equal(t, "title", got[1].Column)
+	testutil.Equal(t, 1, got[1].SlowQueries)
+}
+
+func TestHandleIndexSuggestionsEmpty(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	slowQueryTestHandler().HandleIndexSuggestions(w, httptest.NewRequest("GET", "/", nil))
+
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Items []IndexSuggestion `json:"items"`
+		Count int               `json:"count"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.NotNil(t, resp.Items)
+	testutil.Equal(t, 0, resp.Count)
+}
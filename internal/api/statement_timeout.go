@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns a context in which API requests run their
+// queries in a transaction with Postgres statement_timeout set to d, so the
+// database aborts a query that outlives it.
+func WithStatementTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, d)
+}
+
+// StatementTimeout returns the statement timeout set on ctx, shortened to
+// the time left before ctx's deadline; 0 means none.
+func StatementTimeout(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	if d <= 0 {
+		return 0
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		d = min(d, time.Until(deadline))
+	}
+	// statement_timeout is in whole milliseconds, and 0 would disable it.
+	return max(d, time.Millisecond)
+}
+
+// setStatementTimeout applies ctx's statement timeout, if any, to tx. Like
+// the RLS settings it is transaction-local.
+func setStatementTimeout(ctx context.Context, tx pgx.Tx) error {
+	d := StatementTimeout(ctx)
+	if d == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, "SET LOCAL statement_timeout = "+strconv.FormatInt(d.Milliseconds(), 10))
+	return err
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestStatementTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testutil.Equal(t, time.Duration(0), StatementTimeout(ctx))
+	testutil.Equal(t, 5*time.Second, StatementTimeout(WithStatementTimeout(ctx, 5*time.Second)))
+	testutil.Equal(t, time.Duration(0), StatementTimeout(WithStatementTimeout(ctx, 0)))
+
+	// The time left before the request deadline caps the timeout.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	got := StatementTimeout(WithStatementTimeout(deadlineCtx, time.Minute))
+	testutil.True(t, got > time.Second && got <= 2*time.Second, "got %s, want at most 2s", got)
+
+	// A passed deadline still yields a positive timeout, since 0 disables it.
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	testutil.Equal(t, time.Millisecond, StatementTimeout(WithStatementTimeout(expired, time.Minute)))
+}
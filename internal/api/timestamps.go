@@ -0,0 +1,60 @@
+package api
+
+import (
+	"time"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// Columns managed by the server when auto timestamps are on.
+const (
+	createdAtColumnName = "created_at"
+	updatedAtColumnName = "updated_at"
+)
+
+// SetAutoTimestamps makes creates and updates set a table's created_at and
+// updated_at columns, replacing client-supplied values. Tables named in
+// manualTables are left alone, for schemas that maintain the columns with
+// triggers.
+func (h *Handler) SetAutoTimestamps(on bool, manualTables []string) {
+	h.autoTimestamps = on
+	h.manualTimestamps = make(map[string]bool, len(manualTables))
+	for _, name := range manualTables {
+		h.manualTimestamps[name] = true
+	}
+}
+
+// timestampColumn returns tbl's column called name if it is a timestamptz
+// column, or nil.
+func timestampColumn(tbl *schema.Table, name string) *schema.Column {
+	col := tbl.ColumnByName(name)
+	if col == nil || col.IsArray {
+		return nil
+	}
+	switch baseTypeName(col) {
+	case "timestamptz", "timestamp with time zone":
+		return col
+	}
+	return nil
+}
+
+// stampTimestamps sets tbl's managed timestamp columns in a validated create
+// or update body, when enabled. A create sets both created_at and updated_at
+// to the current time; an update sets updated_at and drops any created_at,
+// so a row's creation time can't be rewritten.
+func (h *Handler) stampTimestamps(tbl *schema.Table, data map[string]any, update bool) {
+	if !h.autoTimestamps || h.manualTimestamps[tbl.Name] {
+		return
+	}
+	now := time.Now().UTC()
+	if col := timestampColumn(tbl, createdAtColumnName); col != nil {
+		if update {
+			delete(data, col.Name)
+		} else {
+			data[col.Name] = now
+		}
+	}
+	if col := timestampColumn(tbl, updatedAtColumnName); col != nil {
+		data[col.Name] = now
+	}
+}
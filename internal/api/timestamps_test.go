@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func timestampedTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "posts",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", Position: 1, TypeName: "integer", IsPrimaryKey: true},
+			{Name: "title", Position: 2, TypeName: "text"},
+			{Name: "created_at", Position: 3, TypeName: "timestamp with time zone"},
+			{Name: "updated_at", Position: 4, TypeName: "timestamp(3) with time zone"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestStampTimestampsCreate(t *testing.T) {
+	t.Parallel()
+	h := &Handler{}
+	h.SetAutoTimestamps(true, nil)
+
+	before := time.Now()
+	data := map[string]any{"title": "a", "created_at": "2001-01-01T00:00:00Z"}
+	h.stampTimestamps(timestampedTable(), data, false)
+	created, ok := data["created_at"].(time.Time)
+	testutil.True(t, ok, "created_at should be replaced, got %v", data["created_at"])
+	testutil.False(t, created.Before(before), "created_at should be the current time")
+	testutil.Equal(t, created, data["updated_at"].(time.Time))
+}
+
+func TestStampTimestampsUpdate(t *testing.T) {
+	t.Parallel()
+	h := &Handler{}
+	h.SetAutoTimestamps(true, nil)
+
+	data := map[string]any{"title": "a", "created_at": "2001-01-01T00:00:00Z"}
+	h.stampTimestamps(timestampedTable(), data, true)
+	_, hasCreated := data["created_at"]
+	testutil.False(t, hasCreated, "an update must not rewrite created_at")
+	_, ok := data["updated_at"].(time.Time)
+	testutil.True(t, ok, "updated_at should be set")
+}
+
+func TestStampTimestampsSkipped(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		on     bool
+		manual []string
+		tbl    *schema.Table
+	}{
+		{"disabled", false, nil, timestampedTable()},
+		{"manual table", true, []string{"posts"}, timestampedTable()},
+		{"not timestamptz", true, nil, &schema.Table{Name: "logs", Columns: []*schema.Column{
+			{Name: "created_at", TypeName: "timestamp without time zone"},
+			{Name: "updated_at", TypeName: "text"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := &Handler{}
+			h.SetAutoTimestamps(tt.on, tt.manual)
+			data := map[string]any{"created_at": "2001-01-01T00:00:00Z"}
+			h.stampTimestamps(tt.tbl, data, false)
+			testutil.Equal(t, "2001-01-01T00:00:00Z", data["created_at"].(string))
+			_, hasUpdated := data["updated_at"]
+			testutil.False(t, hasUpdated, "updated_at should not be set")
+		})
+	}
+}
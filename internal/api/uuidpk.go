@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// generatedUUIDKey returns tbl's primary key column if it is a single uuid
+// column with a default, such as gen_random_uuid(), so the database can
+// generate it on insert. It returns nil otherwise.
+func generatedUUIDKey(tbl *schema.Table) *schema.Column {
+	if len(tbl.PrimaryKey) != 1 {
+		return nil
+	}
+	col := tbl.ColumnByName(tbl.PrimaryKey[0])
+	if col == nil || col.IsArray || col.DefaultExpr == "" || baseTypeName(col) != "uuid" {
+		return nil
+	}
+	return col
+}
+
+// omitGeneratedKey drops a null or empty id from a create body when the
+// database generates the table's uuid key, so the default applies instead
+// of inserting NULL. A client-supplied UUID is kept and written as given.
+func omitGeneratedKey(tbl *schema.Table, data map[string]any) {
+	col := generatedUUIDKey(tbl)
+	if col == nil {
+		return
+	}
+	if v, ok := data[col.Name]; ok && (v == nil || v == "") {
+		delete(data, col.Name)
+	}
+}
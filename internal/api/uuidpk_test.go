@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func uuidKeyTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "items",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", Position: 1, TypeName: "uuid", JSONType: "string", IsPrimaryKey: true, DefaultExpr: "gen_random_uuid()"},
+			{Name: "name", Position: 2, TypeName: "text", JSONType: "string"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestGeneratedUUIDKey(t *testing.T) {
+	t.Parallel()
+	testutil.NotNil(t, generatedUUIDKey(uuidKeyTable()))
+
+	noDefault := uuidKeyTable()
+	noDefault.Columns[0].DefaultExpr = ""
+	testutil.Nil(t, generatedUUIDKey(noDefault))
+	// A serial key has a default but isn't a uuid.
+	testutil.Nil(t, generatedUUIDKey(testTable()))
+}
+
+func TestPrepareRecordGeneratedKey(t *testing.T) {
+	t.Parallel()
+	tbl := uuidKeyTable()
+
+	for _, id := range []any{nil, ""} {
+		data := map[string]any{"id": id, "name": "a"}
+		testutil.NoError(t, prepareRecord(tbl, data, false))
+		_, ok := data["id"]
+		testutil.False(t, ok, "empty id %v should be left to the default", id)
+	}
+
+	data := map[string]any{"id": "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91", "name": "a"}
+	testutil.NoError(t, prepareRecord(tbl, data, false))
+	testutil.Equal(t, "5f0c6b1e-7d2a-4c1e-9a57-0d3f4b8e2c91", data["id"].(string))
+
+	testutil.ErrorContains(t, prepareRecord(tbl, map[string]any{"id": "nope"}, false), "is not a valid UUID")
+}
+
+func TestBuildInsertDefaultValues(t *testing.T) {
+	t.Parallel()
+	q, args := buildInsert(uuidKeyTable(), map[string]any{})
+	testutil.Equal(t, `INSERT INTO "public"."items" DEFAULT VALUES RETURNING *`, q)
+	testutil.SliceLen(t, args, 0)
+}
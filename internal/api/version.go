@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// versionColumnName is the column used for optimistic concurrency control.
+const versionColumnName = "version"
+
+// errVersionConflict is returned when an update's expected version no longer
+// matches the stored record.
+var errVersionConflict = errors.New("version conflict: the record was modified by another request")
+
+// versionColumn returns tbl's optimistic concurrency column, an integer column
+// named "version", or nil if the table has none.
+func versionColumn(tbl *schema.Table) *schema.Column {
+	col := tbl.ColumnByName(versionColumnName)
+	if col == nil || col.IsArray || col.JSONType != "integer" {
+		return nil
+	}
+	return col
+}
+
+// requireVersion checks that an update to a versioned table carries the
+// version the client last read.
+func requireVersion(tbl *schema.Table, data map[string]any) error {
+	col := versionColumn(tbl)
+	if col == nil {
+		return nil
+	}
+	if v, ok := data[col.Name]; !ok || v == nil {
+		return fmt.Errorf("%s is required to update this record", col.Name)
+	}
+	return nil
+}
+
+// execUpdate runs the update built by buildUpdate on q and returns the
+// updated record, or nil if no record matches pkValues. On a versioned table
+// a record that exists under another version is errVersionConflict.
+func execUpdate(ctx context.Context, q Querier, tbl *schema.Table, data map[string]any, pkValues []string) (map[string]any, error) {
+	query, args := buildUpdate(tbl, data, pkValues)
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	record, err := scanRow(rows)
+	rows.Close() // Close before the next query to avoid pgx "conn busy".
+	if err != nil || record != nil || versionColumn(tbl) == nil {
+		return record, err
+	}
+
+	where, whereArgs := buildPKWhere(tbl, pkValues)
+	var exists bool
+	err = q.QueryRow(ctx, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s)", tableRef(tbl), where),
+		whereArgs...).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errVersionConflict
+	}
+	return nil, nil
+}
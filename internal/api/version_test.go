@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func versionedTable() *schema.Table {
+	return &schema.Table{
+		Schema: "public",
+		Name:   "notes",
+		Kind:   "table",
+		Columns: []*schema.Column{
+			{Name: "id", Position: 1, TypeName: "integer", JSONType: "integer", IsPrimaryKey: true},
+			{Name: "body", Position: 2, TypeName: "text", JSONType: "string"},
+			{Name: "version", Position: 3, TypeName: "integer", JSONType: "integer"},
+		},
+		PrimaryKey: []string{"id"},
+	}
+}
+
+func TestVersionColumn(t *testing.T) {
+	t.Parallel()
+	testutil.NotNil(t, versionColumn(versionedTable()))
+	testutil.Nil(t, versionColumn(testTable()))
+
+	// Only an integer column named version counts.
+	tbl := versionedTable()
+	tbl.Columns[2] = &schema.Column{Name: "version", TypeName: "text", JSONType: "string"}
+	testutil.Nil(t, versionColumn(tbl))
+}
+
+func TestBuildUpdateVersioned(t *testing.T) {
+	t.Parallel()
+	tbl := versionedTable()
+
+	q, args := buildUpdate(tbl, map[string]any{"body": "hi", "version": int64(3)}, []string{"7"})
+	testutil.Contains(t, q, `SET "body" = $1, "version" = "version" + 1 WHERE "id" = $2 AND "version" = $3`)
+	testutil.SliceLen(t, args, 3)
+	testutil.Equal(t, "hi", args[0].(string))
+	testutil.Equal(t, "7", args[1].(string))
+	testutil.Equal(t, int64(3), args[2].(int64))
+}
+
+func TestRequireVersion(t *testing.T) {
+	t.Parallel()
+	tbl := versionedTable()
+
+	testutil.NoError(t, prepareRecord(tbl, map[string]any{"body": "hi", "version": 1.0}, true))
+	testutil.ErrorContains(t, prepareRecord(tbl, map[string]any{"body": "hi"}, true), "version is required")
+	testutil.ErrorContains(t, prepareRecord(tbl, map[string]any{"body": "hi", "version": nil}, true), "version is required")
+	// Creates and unversioned tables don't need one.
+	testutil.NoError(t, prepareRecord(tbl, map[string]any{"body": "hi"}, false))
+	testutil.NoError(t, prepareRecord(testTable(), map[string]any{"name": "Bob"}, true))
+}
+
+func TestUpdateVersionedRequiresVersion(t *testing.T) {
+	t.Parallel()
+	tbl := versionedTable()
+	h := testHandler(&schema.SchemaCache{Tables: map[string]*schema.Table{"public.notes": tbl}})
+
+	w := doRequest(h, "PATCH", "/collections/notes/1", `{"body":"hi"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, decodeError(t, w).Message, "version is required")
+}
@@ -280,6 +280,10 @@ func (s *Service) ValidateAPIKey(ctx context.Context, plaintext string) (*Claims
 		Email:         email,
 		APIKeyScope:   scope,
 		AllowedTables: allowedTables,
+		principal:     PrincipalAPIKey,
+	}
+	if expiresAt != nil {
+		claims.ExpiresAt = jwt.NewNumericDate(*expiresAt)
 	}
 	applyAppRateLimitClaims(claims, appID, appRateLimitRPS, appRateLimitWindow)
 	return claims, nil
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"net/http"
-	"strconv"
 	"sync"
 	"time"
 
@@ -87,16 +86,14 @@ func (arl *AppRateLimiter) Middleware(next http.Handler) http.Handler {
 
 		allowed, remaining, resetTime := arl.allow(claims.AppID, claims.AppRateLimitRPS, window)
 
-		w.Header().Set("X-App-RateLimit-Limit", strconv.Itoa(claims.AppRateLimitRPS))
-		w.Header().Set("X-App-RateLimit-Remaining", strconv.Itoa(remaining))
-		w.Header().Set("X-App-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+		// The app budget is the only limit on these routes, so it is also
+		// reported under the standard names.
+		for _, prefix := range []string{"X-RateLimit-", "X-App-RateLimit-"} {
+			setRateLimitHeaders(w.Header(), prefix, claims.AppRateLimitRPS, remaining, resetTime)
+		}
 
 		if !allowed {
-			retryAfter := int(time.Until(resetTime).Seconds()) + 1
-			if retryAfter < 1 {
-				retryAfter = 1
-			}
-			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			setRetryAfter(w.Header(), resetTime)
 			httputil.WriteError(w, http.StatusTooManyRequests, "app rate limit exceeded")
 			return
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -156,6 +157,37 @@ func TestAppRateLimiterMiddlewareHeaders(t *testing.T) {
 	testutil.True(t, w.Header().Get("X-App-RateLimit-Reset") != "", "should set reset header")
 }
 
+func TestAppRateLimiterMiddlewareStandardHeaders(t *testing.T) {
+	t.Parallel()
+	arl := NewAppRateLimiter()
+	defer arl.Stop()
+
+	handler := arl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	claims := &Claims{AppID: "app-standard", AppRateLimitRPS: 3, AppRateLimitWindow: 60}
+	ctx := context.WithValue(context.Background(), ctxKey{}, claims)
+
+	for _, want := range []string{"2", "1", "0"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		testutil.Equal(t, http.StatusOK, w.Code)
+		testutil.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+		testutil.Equal(t, want, w.Header().Get("X-RateLimit-Remaining"))
+		testutil.Equal(t, w.Header().Get("X-App-RateLimit-Reset"), w.Header().Get("X-RateLimit-Reset"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusTooManyRequests, w.Code)
+	testutil.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	testutil.NoError(t, err)
+	testutil.True(t, retryAfter > 0 && retryAfter <= 61, "Retry-After should be 1-61, got %d", retryAfter)
+}
+
 func TestAppRateLimiterIsolation(t *testing.T) {
 	t.Parallel()
 	arl := NewAppRateLimiter()
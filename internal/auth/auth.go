@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -30,11 +31,14 @@ var (
 	ErrInvalidCredentials  = errors.New("invalid email or password")
 	ErrEmailTaken          = errors.New("email already registered")
 	ErrValidation          = errors.New("validation error")
+	ErrDisposableEmail     = fmt.Errorf("%w: disposable email addresses are not allowed", ErrValidation)
 	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
 	ErrInvalidResetToken   = errors.New("invalid or expired reset token")
 	ErrInvalidVerifyToken  = errors.New("invalid or expired verification token")
 	ErrUserNotFound        = errors.New("user not found")
+	ErrAccountDisabled     = errors.New("account is disabled")
 	ErrDailyLimitExceeded  = errors.New("daily SMS limit exceeded")
+	ErrResendTooSoon       = errors.New("SMS code requested too recently")
 	ErrInvalidSMSCode      = errors.New("invalid or expired SMS code")
 	ErrInvalidPhoneNumber  = sms.ErrInvalidPhoneNumber
 )
@@ -51,23 +55,48 @@ const (
 	argonKeyLen  = 32
 )
 
+// bcrypt work factor. A var so tests can lower it for speed.
+var bcryptCost = bcrypt.DefaultCost
+
+// bcrypt ignores input past 72 bytes, so longer passwords are rejected.
+const bcryptMaxPassword = 72
+
+// Password hashing algorithms accepted by SetPasswordHash and CreateUser.
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
 // Service handles user registration, login, and JWT operations.
 type Service struct {
-	pool         *pgxpool.Pool
-	jwtSecret    []byte
-	jwtSecretMu  sync.RWMutex
-	tokenDur     time.Duration
-	refreshDur   time.Duration
-	minPwLen     int // minimum password length (default 8)
-	logger       *slog.Logger
-	mailer       mailer.Mailer // nil = email features disabled
-	appName      string        // used in email templates
-	baseURL      string        // public base URL for action links
-	magicLinkDur time.Duration // 0 = use default (10 min)
+	pool             *pgxpool.Pool
+	jwtSecret        []byte
+	prevSecret       []byte // secret replaced by RotateJWTSecret; still validates until prevUntil
+	prevUntil        time.Time
+	jwtSecretMu      sync.RWMutex
+	tokenDur         time.Duration
+	refreshDur       time.Duration
+	rememberDur      time.Duration   // refresh lifetime for "remember me" sessions; 0 = refreshDur
+	jwtIssuer        string          // iss claim; "" = not stamped or checked
+	jwtAudience      string          // aud claim; "" = not stamped or checked
+	rlsClaims        []string        // JWT claims mapped to ayb.<name> RLS settings
+	tenantClaim      string          // JWT claim naming the tenant; "" = tenants off
+	metadataKeys     []string        // profile metadata keys users may set; empty = none
+	minPwLen         int             // minimum password length (default 8)
+	pwHash           string          // algorithm for new password hashes; "" = argon2id
+	normalizeEmails  bool            // canonicalize addresses of known mail providers
+	blockedDomains   map[string]bool // blocked disposable email domains; nil = blocking off
+	logger           *slog.Logger
+	mailer           mailer.Mailer // nil = email features disabled
+	appName          string        // used in email templates
+	baseURL          string        // public base URL for action links
+	magicLinkDur     time.Duration // 0 = use default (10 min)
 	smsProvider      sms.Provider  // nil = SMS features disabled
 	smsConfig        sms.Config
 	oauthProviderCfg OAuthProviderModeConfig
 	emailTplSvc      EmailTemplateRenderer // nil = use legacy hardcoded templates
+	events           EventPublisher        // nil = auth events disabled
+	cookies          *CookieConfig         // nil = tokens in the JSON body only
 }
 
 // EmailTemplateRenderer renders email templates by key with variable substitution.
@@ -86,16 +115,20 @@ func (s *Service) SetEmailTemplateService(svc EmailTemplateRenderer) {
 
 // legacyRenderFuncs maps template keys to their legacy render functions.
 var legacyRenderFuncs = map[string]func(mailer.TemplateData) (string, string, error){
-	"auth.password_reset":     mailer.RenderPasswordReset,
-	"auth.email_verification": mailer.RenderVerification,
-	"auth.magic_link":         mailer.RenderMagicLink,
+	"auth.password_reset":       mailer.RenderPasswordReset,
+	"auth.email_verification":   mailer.RenderVerification,
+	"auth.magic_link":           mailer.RenderMagicLink,
+	"auth.email_change":         mailer.RenderEmailChange,
+	"auth.registration_attempt": mailer.RenderRegistrationAttempt,
 }
 
 // legacySubjects maps template keys to their default subjects.
 var legacySubjects = map[string]string{
-	"auth.password_reset":     mailer.DefaultPasswordResetSubject,
-	"auth.email_verification": mailer.DefaultVerificationSubject,
-	"auth.magic_link":         mailer.DefaultMagicLinkSubject,
+	"auth.password_reset":       mailer.DefaultPasswordResetSubject,
+	"auth.email_verification":   mailer.DefaultVerificationSubject,
+	"auth.magic_link":           mailer.DefaultMagicLinkSubject,
+	"auth.email_change":         mailer.DefaultEmailChangeSubject,
+	"auth.registration_attempt": mailer.DefaultRegistrationAttemptSubject,
 }
 
 // renderAuthEmail renders an email using the template service if available,
@@ -122,23 +155,61 @@ func (s *Service) renderAuthEmail(ctx context.Context, key string, vars map[stri
 
 // User represents a registered user (without password hash).
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID          string         `json:"id"`
+	Email       string         `json:"email"`
+	Phone       string         `json:"phone,omitempty"`
+	DisplayName string         `json:"displayName,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	// PendingEmail is an address the user asked to change to that hasn't
+	// been confirmed yet. Only set by UserByID.
+	PendingEmail string    `json:"pendingEmail,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
 }
 
 // Claims are the JWT claims issued by AYB.
 type Claims struct {
 	jwt.RegisteredClaims
-	Email         string   `json:"email"`
-	APIKeyScope   string   `json:"apiKeyScope,omitempty"`   // "*", "readonly", "readwrite"; empty for JWT
-	AllowedTables []string `json:"allowedTables,omitempty"` // empty = all tables
+	Email              string   `json:"email"`
+	APIKeyScope        string   `json:"apiKeyScope,omitempty"`        // "*", "readonly", "readwrite"; empty for JWT
+	AllowedTables      []string `json:"allowedTables,omitempty"`      // empty = all tables
 	AppID              string   `json:"appId,omitempty"`              // set when API key is app-scoped
 	AppRateLimitRPS    int      `json:"appRateLimitRps,omitempty"`    // app's configured RPS limit (0 = unlimited)
 	AppRateLimitWindow int      `json:"appRateLimitWindow,omitempty"` // app's rate limit window in seconds
-	MFAPending         bool     `json:"mfa_pending,omitempty"`
+	Purpose            string   `json:"purpose,omitempty"`            // "mfa_challenge" on MFA challenge tokens; empty on access tokens
+	RememberMe         bool     `json:"remember_me,omitempty"`        // carried on MFA challenge tokens to the final session
+
+	// rlsSettings maps claim names to the ayb.<name> values SetRLSContext
+	// applies. Filled by ValidateToken for the claims set via SetRLSClaims.
+	rlsSettings map[string]string
+	// tenant is the value of the claim set via SetTenantClaim.
+	tenant string
+	// principal is the kind of credential the claims came from; empty for
+	// user JWTs.
+	principal string
+}
+
+// Tenant returns the tenant the token belongs to, or "" if it names none or
+// no tenant claim is configured.
+func (c *Claims) Tenant() string {
+	return c.tenant
+}
+
+// Principal types reported by Claims.PrincipalType.
+const (
+	PrincipalUser   = "user"
+	PrincipalAPIKey = "api_key"
+	PrincipalOAuth  = "oauth"
+)
+
+// PrincipalType reports how the caller authenticated: PrincipalUser for a
+// user JWT, PrincipalAPIKey for an API key, or PrincipalOAuth for an OAuth
+// access token.
+func (c *Claims) PrincipalType() string {
+	if c.principal == "" {
+		return PrincipalUser
+	}
+	return c.principal
 }
 
 // API key scope constants.
@@ -196,34 +267,40 @@ func NewService(pool *pgxpool.Pool, jwtSecret string, tokenDuration, refreshDura
 
 // Register creates a new user and returns the user, an access token, and a refresh token.
 func (s *Service) Register(ctx context.Context, email, password string) (*User, string, string, error) {
-	email = strings.ToLower(strings.TrimSpace(email))
-	if err := validateEmail(email); err != nil {
-		return nil, "", "", err
-	}
-	if err := validatePassword(password, s.minPwLen); err != nil {
+	user, err := s.registerUser(ctx, email, password)
+	if err != nil {
 		return nil, "", "", err
 	}
+	return s.issueTokens(ctx, user, false)
+}
 
-	hash, err := hashPassword(password)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("hashing password: %w", err)
+// RegisterHidingConflicts creates a new user like Register, but issues no
+// tokens and doesn't reveal whether the email was already registered: for an
+// existing account it emails the owner that someone tried to sign up with
+// their address, with a password reset link, and returns nil.
+func (s *Service) RegisterHidingConflicts(ctx context.Context, email, password string) error {
+	_, err := s.registerUser(ctx, email, password)
+	if errors.Is(err, ErrEmailTaken) {
+		s.sendRegistrationAttemptEmail(ctx, s.canonicalEmail(email))
+		return nil
 	}
+	return err
+}
 
-	var user User
-	err = s.pool.QueryRow(ctx,
-		`INSERT INTO _ayb_users (email, password_hash) VALUES ($1, $2)
-		 RETURNING id, email, created_at, updated_at`,
-		email, hash,
-	).Scan(&user.ID, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+// registerUser creates a password user and sends their verification email.
+// Addresses at blocked disposable domains get ErrDisposableEmail.
+func (s *Service) registerUser(ctx context.Context, email, password string) (*User, error) {
+	email = s.canonicalEmail(email)
+	if s.isDisposableEmail(email) {
+		return nil, ErrDisposableEmail
+	}
+	user, err := CreateUser(ctx, s.pool, email, password, s.minPwLen, s.pwHash)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return nil, "", "", ErrEmailTaken
-		}
-		return nil, "", "", fmt.Errorf("inserting user: %w", err)
+		return nil, err
 	}
 
 	s.logger.Info("user registered", "user_id", user.ID, "email", user.Email)
+	s.publishEvent(EventSignup, user.ID)
 
 	// Send verification email (best-effort, don't block registration).
 	if s.mailer != nil {
@@ -231,13 +308,39 @@ func (s *Service) Register(ctx context.Context, email, password string) (*User,
 			s.logger.Error("failed to send verification email on register", "error", err)
 		}
 	}
+	return user, nil
+}
 
-	return s.issueTokens(ctx, &user)
+// sendRegistrationAttemptEmail tells the owner of email that someone tried to
+// register with it (best-effort).
+func (s *Service) sendRegistrationAttemptEmail(ctx context.Context, email string) {
+	if s.mailer == nil {
+		s.logger.Info("registration attempt for existing email not sent: no mailer configured")
+		return
+	}
+	var userID string
+	if err := s.pool.QueryRow(ctx,
+		`SELECT id FROM _ayb_users WHERE LOWER(email) = $1`, email,
+	).Scan(&userID); err != nil {
+		s.logger.Error("failed to look up user for registration attempt email", "error", err)
+		return
+	}
+	actionURL, err := s.passwordResetURL(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to create password reset link", "error", err, "user_id", userID)
+		return
+	}
+	s.sendAuthEmail(ctx, email, "auth.registration_attempt", actionURL)
 }
 
 // Login authenticates a user and returns the user, an access token, and a refresh token.
-func (s *Service) Login(ctx context.Context, email, password string) (*User, string, string, error) {
-	email = strings.ToLower(strings.TrimSpace(email))
+// When rememberMe is true the session lives for the remember-me duration
+// instead of the regular refresh token duration.
+func (s *Service) Login(ctx context.Context, email, password string, rememberMe bool) (*User, string, string, error) {
+	email = s.canonicalEmail(email)
+	if err := validateEmail(email); err != nil {
+		return nil, "", "", err
+	}
 
 	var user User
 	var hash string
@@ -261,61 +364,124 @@ func (s *Service) Login(ctx context.Context, email, password string) (*User, str
 		return nil, "", "", ErrInvalidCredentials
 	}
 
-	// Progressive re-hash: upgrade bcrypt/firebase-scrypt hashes to argon2id on successful login.
-	if isBcryptHash(hash) || strings.HasPrefix(hash, "$firebase-scrypt$") {
-		if err := s.upgradePasswordHash(ctx, user.ID, password); err != nil {
-			s.logger.Error("failed to upgrade password hash", "user_id", user.ID, "error", err)
+	// Progressive re-hash: move hashes made with another algorithm (including
+	// imported bcrypt and firebase-scrypt hashes) to the configured one while
+	// the plaintext is at hand.
+	if s.needsRehash(hash, password) {
+		if err := s.rehashPassword(ctx, user.ID, password); err != nil {
+			s.logger.Error("failed to rehash password", "user_id", user.ID, "error", err)
 		}
 	}
 
-	// If user has MFA enrolled, return a pending token instead of full tokens.
+	return s.completeLogin(ctx, &user, rememberMe)
+}
+
+// completeLogin finishes a login once the first factor has been checked.
+// Disabled accounts get ErrAccountDisabled. Users enrolled in MFA get an MFA
+// challenge token in place of the access token and an empty refresh token;
+// everyone else gets a new session.
+func (s *Service) completeLogin(ctx context.Context, user *User, rememberMe bool) (*User, string, string, error) {
+	if err := s.checkAccountEnabled(ctx, user.ID); err != nil {
+		return nil, "", "", err
+	}
+
 	hasMFA, err := s.HasSMSMFA(ctx, user.ID)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("checking MFA enrollment: %w", err)
 	}
 	if hasMFA {
-		pendingToken, err := s.generateMFAPendingToken(&user)
+		pendingToken, err := s.generateMFAChallengeToken(user, rememberMe)
 		if err != nil {
 			return nil, "", "", fmt.Errorf("generating MFA pending token: %w", err)
 		}
-		return &user, pendingToken, "", nil
+		return user, pendingToken, "", nil
 	}
 
-	return s.issueTokens(ctx, &user)
+	return s.issueTokens(ctx, user, rememberMe)
 }
 
-// ValidateToken parses and validates a JWT token string.
+// ValidateToken parses and validates an access token. When an issuer or
+// audience is configured (see SetJWTClaims), tokens must carry a matching
+// iss and aud. Tokens signed with the secret replaced by RotateJWTSecret are
+// accepted until its overlap window ends. MFA challenge tokens are rejected
+// with ErrMFAChallengeToken.
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
+	claims, token, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose == tokenPurposeMFAChallenge {
+		return nil, ErrMFAChallengeToken
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token: unexpected purpose")
+	}
+	if s.jwtAudience != "" && !slices.Contains(claims.Audience, s.jwtAudience) {
+		return nil, errors.New("invalid token: token has invalid audience")
+	}
+	if len(s.rlsClaims) > 0 {
+		settings, err := rlsClaimSettings(token.Raw, s.rlsClaims)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+		claims.rlsSettings = settings
+	}
+	if s.tenantClaim != "" {
+		values, err := rlsClaimSettings(token.Raw, []string{s.tenantClaim})
+		if err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+		claims.tenant = values[s.tenantClaim]
+	}
+	return claims, nil
+}
+
+// parseToken verifies a token's signature, expiry and issuer. Callers check
+// its purpose and audience.
+func (s *Service) parseToken(tokenString string) (*Claims, *jwt.Token, error) {
 	s.jwtSecretMu.RLock()
-	secret := s.jwtSecret
+	keys := jwt.VerificationKeySet{Keys: []jwt.VerificationKey{s.jwtSecret}}
+	if s.prevSecret != nil && time.Now().Before(s.prevUntil) {
+		keys.Keys = append(keys.Keys, s.prevSecret)
+	}
 	s.jwtSecretMu.RUnlock()
 
+	var opts []jwt.ParserOption
+	if s.jwtIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.jwtIssuer))
+	}
+
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return secret, nil
-	})
+		return keys, nil
+	}, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, nil, fmt.Errorf("invalid token: %w", err)
 	}
 	if !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, nil, errors.New("invalid token")
 	}
-	return claims, nil
+	return claims, token, nil
 }
 
-// UserByID fetches a user by ID.
+// UserByID fetches a user by ID, with their profile and any pending email change.
 func (s *Service) UserByID(ctx context.Context, id string) (*User, error) {
 	var user User
 	err := s.pool.QueryRow(ctx,
-		`SELECT id, email, COALESCE(phone, ''), created_at, updated_at FROM _ayb_users WHERE id = $1`,
+		`SELECT u.id, u.email, COALESCE(u.phone, ''), u.display_name, u.metadata,
+		        COALESCE(c.new_email, ''), u.created_at, u.updated_at
+		 FROM _ayb_users u
+		 LEFT JOIN _ayb_email_changes c ON c.user_id = u.id AND c.expires_at > NOW()
+		 WHERE u.id = $1`,
 		id,
-	).Scan(&user.ID, &user.Email, &user.Phone, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Email, &user.Phone, &user.DisplayName, &user.Metadata,
+		&user.PendingEmail, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("querying user: %w", err)
 	}
@@ -323,20 +489,36 @@ func (s *Service) UserByID(ctx context.Context, id string) (*User, error) {
 }
 
 func (s *Service) generateToken(user *User) (string, error) {
+	rc, err := s.registeredClaims(user.ID, s.tokenDur)
+	if err != nil {
+		return "", err
+	}
+	return s.signToken(&Claims{RegisteredClaims: rc, Email: user.Email})
+}
+
+// registeredClaims returns the standard claims for a token issued now to
+// subject and valid for dur, stamped with the configured issuer and audience.
+func (s *Service) registeredClaims(subject string, dur time.Duration) (jwt.RegisteredClaims, error) {
 	now := time.Now()
 	jti := make([]byte, 16)
 	if _, err := rand.Read(jti); err != nil {
-		return "", fmt.Errorf("generating jti: %w", err)
+		return jwt.RegisteredClaims{}, fmt.Errorf("generating jti: %w", err)
 	}
-	claims := &Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   user.ID,
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenDur)),
-			ID:        hex.EncodeToString(jti),
-		},
-		Email: user.Email,
+	rc := jwt.RegisteredClaims{
+		Issuer:    s.jwtIssuer,
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(dur)),
+		ID:        hex.EncodeToString(jti),
 	}
+	if s.jwtAudience != "" {
+		rc.Audience = jwt.ClaimStrings{s.jwtAudience}
+	}
+	return rc, nil
+}
+
+// signToken signs claims with the current JWT secret (HS256).
+func (s *Service) signToken(claims *Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	s.jwtSecretMu.RLock()
 	secret := s.jwtSecret
@@ -349,21 +531,40 @@ func (s *Service) IssueTestToken(userID, email string) (string, error) {
 	return s.generateToken(&User{ID: userID, Email: email})
 }
 
-// RotateJWTSecret generates a new random JWT secret, invalidating all existing tokens.
-func (s *Service) RotateJWTSecret() (string, error) {
+// RotateJWTSecret generates a new random JWT secret for signing tokens. Tokens
+// signed with the current secret keep validating for overlap, so live
+// sessions survive the rotation; an overlap of 0 invalidates them at once.
+// A secret replaced by an earlier rotation stops validating immediately.
+func (s *Service) RotateJWTSecret(overlap time.Duration) (string, error) {
 	secret := make([]byte, 32)
 	if _, err := rand.Read(secret); err != nil {
 		return "", fmt.Errorf("generating secret: %w", err)
 	}
 	hex := fmt.Sprintf("%x", secret)
 	s.jwtSecretMu.Lock()
+	s.prevSecret, s.prevUntil = nil, time.Time{}
+	if overlap > 0 {
+		s.prevSecret, s.prevUntil = s.jwtSecret, time.Now().Add(overlap)
+	}
 	s.jwtSecret = []byte(hex)
 	s.jwtSecretMu.Unlock()
 	return hex, nil
 }
 
-// hashPassword hashes a password using argon2id and returns a PHC-format string.
-func hashPassword(password string) (string, error) {
+// hashPassword hashes a password with the given algorithm; "" means argon2id.
+func hashPassword(password, algorithm string) (string, error) {
+	if algorithm == PasswordHashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("bcrypt hash: %w", err)
+		}
+		return string(hash), nil
+	}
+	return hashArgon2id(password)
+}
+
+// hashArgon2id hashes a password using argon2id and returns a PHC-format string.
+func hashArgon2id(password string) (string, error) {
 	salt := make([]byte, argonSaltLen)
 	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("generating salt: %w", err)
@@ -378,6 +579,11 @@ func hashPassword(password string) (string, error) {
 	), nil
 }
 
+// hashPassword hashes a password with the service's configured algorithm.
+func (s *Service) hashPassword(password string) (string, error) {
+	return hashPassword(password, s.pwHash)
+}
+
 // verifyPassword checks a password against a stored hash.
 // Supports argon2id (PHC format) and bcrypt ($2a$/$2b$/$2y$).
 func verifyPassword(encoded, password string) (bool, error) {
@@ -403,6 +609,18 @@ func verifyPassword(encoded, password string) (bool, error) {
 	return false, fmt.Errorf("unsupported hash format")
 }
 
+// hashAlgorithm returns the algorithm a stored hash was made with, or "" for
+// formats AYB only verifies, such as imported firebase-scrypt hashes.
+func hashAlgorithm(hash string) string {
+	switch {
+	case isBcryptHash(hash):
+		return PasswordHashBcrypt
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return PasswordHashArgon2id
+	}
+	return ""
+}
+
 // isBcryptHash returns true if the hash string is a bcrypt hash.
 func isBcryptHash(hash string) bool {
 	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
@@ -450,10 +668,20 @@ func verifyFirebaseScrypt(encoded, password string) (bool, error) {
 	return fbmigrate.VerifyFirebaseScrypt(password, salt, passwordHash, signerKey, saltSep, rounds, memCost)
 }
 
-// upgradePasswordHash re-hashes the password with argon2id and updates the database.
-// Called after successful bcrypt login to progressively migrate to the stronger algorithm.
-func (s *Service) upgradePasswordHash(ctx context.Context, userID, password string) error {
-	newHash, err := hashPassword(password)
+// needsRehash reports whether a stored hash was made with an algorithm
+// other than the configured one and password can be hashed with it.
+func (s *Service) needsRehash(hash, password string) bool {
+	algorithm := s.pwHash
+	if algorithm == "" {
+		algorithm = PasswordHashArgon2id
+	}
+	return hashAlgorithm(hash) != algorithm && checkPasswordHashable(password, algorithm) == nil
+}
+
+// rehashPassword re-hashes the password with the configured algorithm and
+// updates the database. Called after a successful login.
+func (s *Service) rehashPassword(ctx context.Context, userID, password string) error {
+	newHash, err := s.hashPassword(password)
 	if err != nil {
 		return fmt.Errorf("hashing password: %w", err)
 	}
@@ -464,26 +692,11 @@ func (s *Service) upgradePasswordHash(ctx context.Context, userID, password stri
 	if err != nil {
 		return fmt.Errorf("updating password hash: %w", err)
 	}
-	s.logger.Info("upgraded password hash to argon2id", "user_id", userID)
-	return nil
-}
-
-func validateEmail(email string) error {
-	if email == "" {
-		return fmt.Errorf("%w: email is required", ErrValidation)
-	}
-	atIdx := strings.Index(email, "@")
-	if atIdx < 1 {
-		return fmt.Errorf("%w: invalid email format", ErrValidation)
-	}
-	domain := email[atIdx+1:]
-	if !strings.Contains(domain, ".") {
-		return fmt.Errorf("%w: invalid email format", ErrValidation)
-	}
+	s.logger.Info("rehashed password", "user_id", userID, "algorithm", hashAlgorithm(newHash))
 	return nil
 }
 
-func validatePassword(password string, minLen int) error {
+func validatePassword(password string, minLen int, algorithm string) error {
 	if len(password) == 0 {
 		return fmt.Errorf("%w: password is required", ErrValidation)
 	}
@@ -493,6 +706,14 @@ func validatePassword(password string, minLen int) error {
 	if len(password) < minLen {
 		return fmt.Errorf("%w: password must be at least %d characters", ErrValidation, minLen)
 	}
+	return checkPasswordHashable(password, algorithm)
+}
+
+// checkPasswordHashable rejects passwords too long for algorithm to hash.
+func checkPasswordHashable(password, algorithm string) error {
+	if algorithm == PasswordHashBcrypt && len(password) > bcryptMaxPassword {
+		return fmt.Errorf("%w: password must be at most %d bytes", ErrValidation, bcryptMaxPassword)
+	}
 	return nil
 }
 
@@ -502,24 +723,30 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*User,
 	hash := hashToken(refreshToken)
 
 	var sessionID, userID string
+	var rememberMe, disabled bool
 	err := s.pool.QueryRow(ctx,
-		`SELECT id, user_id FROM _ayb_sessions
-		 WHERE token_hash = $1 AND expires_at > NOW()`,
+		`SELECT s.id, s.user_id, s.remember_me, u.disabled_at IS NOT NULL
+		 FROM _ayb_sessions s JOIN _ayb_users u ON u.id = s.user_id
+		 WHERE s.token_hash = $1 AND s.expires_at > NOW()`,
 		hash,
-	).Scan(&sessionID, &userID)
+	).Scan(&sessionID, &userID, &rememberMe, &disabled)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, "", "", ErrInvalidRefreshToken
 		}
 		return nil, "", "", fmt.Errorf("querying session: %w", err)
 	}
+	if disabled {
+		return nil, "", "", ErrAccountDisabled
+	}
 
 	user, err := s.UserByID(ctx, userID)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("looking up user: %w", err)
 	}
 
-	// Rotate: generate new refresh token and update the session row.
+	// Rotate: generate new refresh token and slide the session's expiry
+	// forward by its own duration.
 	raw := make([]byte, refreshTokenBytes)
 	if _, err := rand.Read(raw); err != nil {
 		return nil, "", "", fmt.Errorf("generating refresh token: %w", err)
@@ -529,7 +756,7 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*User,
 
 	_, err = s.pool.Exec(ctx,
 		`UPDATE _ayb_sessions SET token_hash = $1, expires_at = $2 WHERE id = $3`,
-		newHash, time.Now().Add(s.refreshDur), sessionID,
+		newHash, time.Now().Add(s.sessionDuration(rememberMe)), sessionID,
 	)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("rotating session: %w", err)
@@ -547,29 +774,35 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*User,
 // Idempotent — returns nil even if the token doesn't match any session.
 func (s *Service) Logout(ctx context.Context, refreshToken string) error {
 	hash := hashToken(refreshToken)
-	_, err := s.pool.Exec(ctx,
-		`DELETE FROM _ayb_sessions WHERE token_hash = $1`, hash,
-	)
+	var userID string
+	err := s.pool.QueryRow(ctx,
+		`DELETE FROM _ayb_sessions WHERE token_hash = $1 RETURNING user_id`, hash,
+	).Scan(&userID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
 		return fmt.Errorf("deleting session: %w", err)
 	}
+	s.publishEvent(EventLogout, userID)
 	return nil
 }
 
 const refreshTokenBytes = 32
 
-// CreateUser creates a user without issuing tokens.
-// Used by CLI commands that need to bootstrap users before the server starts.
-func CreateUser(ctx context.Context, pool *pgxpool.Pool, email, password string, minPasswordLength int) (*User, error) {
+// CreateUser creates a user without issuing tokens, hashing the password
+// with passwordHash ("" means argon2id). Used by CLI commands that need to
+// bootstrap users before the server starts.
+func CreateUser(ctx context.Context, pool *pgxpool.Pool, email, password string, minPasswordLength int, passwordHash string) (*User, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
 	if err := validateEmail(email); err != nil {
 		return nil, err
 	}
-	if err := validatePassword(password, minPasswordLength); err != nil {
+	if err := validatePassword(password, minPasswordLength, passwordHash); err != nil {
 		return nil, err
 	}
 
-	hash, err := hashPassword(password)
+	hash, err := hashPassword(password, passwordHash)
 	if err != nil {
 		return nil, fmt.Errorf("hashing password: %w", err)
 	}
@@ -600,6 +833,46 @@ func (s *Service) SetMailer(m mailer.Mailer, appName, baseURL string) {
 	s.baseURL = strings.TrimRight(baseURL, "/")
 }
 
+// SetJWTClaims sets the issuer (iss) and audience (aud) stamped into issued
+// JWTs. Non-empty values are also required to match in ValidateToken, so
+// resource servers sharing the secret can reject tokens meant for others.
+func (s *Service) SetJWTClaims(issuer, audience string) {
+	s.jwtIssuer = issuer
+	s.jwtAudience = audience
+}
+
+// SetRLSClaims sets the JWT claims copied into ayb.<name> Postgres settings
+// for RLS policies, alongside ayb.user_id and ayb.user_email.
+func (s *Service) SetRLSClaims(names []string) {
+	s.rlsClaims = names
+}
+
+// SetTenantClaim sets the JWT claim that names the tenant a token belongs
+// to in schema-per-tenant mode. See Claims.Tenant.
+func (s *Service) SetTenantClaim(name string) {
+	s.tenantClaim = name
+}
+
+// SetEmailNormalization makes addresses from known mail providers
+// canonical wherever users sign up or sign in, so subaddresses and Gmail
+// dot variants of one mailbox map to a single account.
+func (s *Service) SetEmailNormalization(on bool) {
+	s.normalizeEmails = on
+}
+
+// SetPasswordHash sets the algorithm new password hashes use:
+// PasswordHashArgon2id (the default) or PasswordHashBcrypt. Hashes made with
+// the other algorithm still verify and are rehashed at the next login.
+func (s *Service) SetPasswordHash(algorithm string) {
+	s.pwHash = algorithm
+}
+
+// SetRememberMeDuration sets the refresh token lifetime for sessions created
+// with rememberMe. Zero falls back to the regular refresh token duration.
+func (s *Service) SetRememberMeDuration(d time.Duration) {
+	s.rememberDur = d
+}
+
 // SetSMSProvider sets the SMS provider for phone-based auth flows.
 func (s *Service) SetSMSProvider(p sms.Provider) {
 	s.smsProvider = p
@@ -628,7 +901,7 @@ func (s *Service) RequestPasswordReset(ctx context.Context, email string) error
 	if s.mailer == nil {
 		return nil
 	}
-	email = strings.ToLower(strings.TrimSpace(email))
+	email = s.canonicalEmail(email)
 
 	var userID string
 	err := s.pool.QueryRow(ctx,
@@ -639,31 +912,48 @@ func (s *Service) RequestPasswordReset(ctx context.Context, email string) error
 		return nil
 	}
 
+	actionURL, err := s.passwordResetURL(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.sendAuthEmail(ctx, email, "auth.password_reset", actionURL)
+	return nil
+}
+
+// passwordResetURL creates a password reset token for the user, replacing
+// any earlier one, and returns the link that redeems it.
+func (s *Service) passwordResetURL(ctx context.Context, userID string) (string, error) {
 	// Delete any existing reset tokens for this user.
 	_, _ = s.pool.Exec(ctx, `DELETE FROM _ayb_password_resets WHERE user_id = $1`, userID)
 
 	// Generate token.
 	raw := make([]byte, resetTokenBytes)
 	if _, err := rand.Read(raw); err != nil {
-		return fmt.Errorf("generating reset token: %w", err)
+		return "", fmt.Errorf("generating reset token: %w", err)
 	}
 	plaintext := base64.RawURLEncoding.EncodeToString(raw)
 	hash := hashToken(plaintext)
 
-	_, err = s.pool.Exec(ctx,
+	_, err := s.pool.Exec(ctx,
 		`INSERT INTO _ayb_password_resets (user_id, token_hash, expires_at)
 		 VALUES ($1, $2, $3)`,
 		userID, hash, time.Now().Add(resetTokenExpiry),
 	)
 	if err != nil {
-		return fmt.Errorf("inserting reset token: %w", err)
+		return "", fmt.Errorf("inserting reset token: %w", err)
 	}
+	return s.baseURL + "/auth/password-reset/confirm?token=" + plaintext, nil
+}
 
-	actionURL := s.baseURL + "/auth/password-reset/confirm?token=" + plaintext
+// sendAuthEmail renders the auth email template key with actionURL and sends
+// it to email. Failures are logged, not returned, so a mail outage can't
+// reveal whether an account exists.
+func (s *Service) sendAuthEmail(ctx context.Context, email, key, actionURL string) {
 	vars := map[string]string{"AppName": s.appName, "ActionURL": actionURL}
-	subject, html, text, err := s.renderAuthEmail(ctx, "auth.password_reset", vars)
+	subject, html, text, err := s.renderAuthEmail(ctx, key, vars)
 	if err != nil {
-		return fmt.Errorf("rendering reset email: %w", err)
+		s.logger.Error("failed to render auth email", "error", err, "template", key)
+		return
 	}
 
 	if err := s.mailer.Send(ctx, &mailer.Message{
@@ -672,14 +962,13 @@ func (s *Service) RequestPasswordReset(ctx context.Context, email string) error
 		HTML:    html,
 		Text:    text,
 	}); err != nil {
-		s.logger.Error("failed to send password reset email", "error", err, "email", email)
+		s.logger.Error("failed to send auth email", "error", err, "template", key, "email", email)
 	}
-	return nil
 }
 
 // ConfirmPasswordReset validates the token and sets a new password.
 func (s *Service) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
-	if err := validatePassword(newPassword, s.minPwLen); err != nil {
+	if err := validatePassword(newPassword, s.minPwLen, s.pwHash); err != nil {
 		return err
 	}
 
@@ -698,7 +987,7 @@ func (s *Service) ConfirmPasswordReset(ctx context.Context, token, newPassword s
 		return fmt.Errorf("querying reset token: %w", err)
 	}
 
-	newHash, err := hashPassword(newPassword)
+	newHash, err := s.hashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("hashing new password: %w", err)
 	}
@@ -723,6 +1012,7 @@ func (s *Service) ConfirmPasswordReset(ctx context.Context, token, newPassword s
 	}
 
 	s.logger.Info("password reset completed", "user_id", userID)
+	s.publishEvent(EventPasswordReset, userID)
 	return nil
 }
 
@@ -803,11 +1093,12 @@ func (s *Service) ConfirmEmail(ctx context.Context, token string) error {
 
 // AdminUser is a user record with additional fields visible only to admins.
 type AdminUser struct {
-	ID            string    `json:"id"`
-	Email         string    `json:"email"`
-	EmailVerified bool      `json:"emailVerified"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	EmailVerified bool       `json:"emailVerified"`
+	DisabledAt    *time.Time `json:"disabledAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 // UserListResult is a paginated list of admin users.
@@ -846,7 +1137,7 @@ func (s *Service) ListUsers(ctx context.Context, page, perPage int, search strin
 		}
 
 		dbRows, err := s.pool.Query(ctx,
-			`SELECT id, email, email_verified, created_at, updated_at
+			`SELECT id, email, email_verified, disabled_at, created_at, updated_at
 			 FROM _ayb_users WHERE email ILIKE $1
 			 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
 			pattern, perPage, offset,
@@ -858,7 +1149,7 @@ func (s *Service) ListUsers(ctx context.Context, page, perPage int, search strin
 
 		for dbRows.Next() {
 			var u AdminUser
-			if err := dbRows.Scan(&u.ID, &u.Email, &u.EmailVerified, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			if err := dbRows.Scan(&u.ID, &u.Email, &u.EmailVerified, &u.DisabledAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
 				return nil, fmt.Errorf("scanning user: %w", err)
 			}
 			rows = append(rows, u)
@@ -875,7 +1166,7 @@ func (s *Service) ListUsers(ctx context.Context, page, perPage int, search strin
 		}
 
 		dbRows, err := s.pool.Query(ctx,
-			`SELECT id, email, email_verified, created_at, updated_at
+			`SELECT id, email, email_verified, disabled_at, created_at, updated_at
 			 FROM _ayb_users
 			 ORDER BY created_at DESC LIMIT $1 OFFSET $2`,
 			perPage, offset,
@@ -887,7 +1178,7 @@ func (s *Service) ListUsers(ctx context.Context, page, perPage int, search strin
 
 		for dbRows.Next() {
 			var u AdminUser
-			if err := dbRows.Scan(&u.ID, &u.Email, &u.EmailVerified, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			if err := dbRows.Scan(&u.ID, &u.Email, &u.EmailVerified, &u.DisabledAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
 				return nil, fmt.Errorf("scanning user: %w", err)
 			}
 			rows = append(rows, u)
@@ -921,37 +1212,14 @@ func (s *Service) ListUsers(ctx context.Context, page, perPage int, search strin
 // escalation. We must detach keys from the user's apps before the cascade can
 // proceed.
 func (s *Service) DeleteUser(ctx context.Context, id string) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return fmt.Errorf("beginning transaction: %w", err)
-	}
-	defer tx.Rollback(ctx) //nolint:errcheck
-
-	// Revoke active app-scoped keys and detach all keys from the user's apps.
-	// This satisfies the ON DELETE RESTRICT FK on api_keys.app_id so that the
-	// subsequent CASCADE delete of _ayb_apps rows can succeed.
-	_, err = tx.Exec(ctx,
-		`UPDATE _ayb_api_keys
-		 SET revoked_at = COALESCE(revoked_at, NOW()), app_id = NULL
-		 WHERE app_id IN (SELECT id FROM _ayb_apps WHERE owner_user_id = $1)`, id)
-	if err != nil {
-		return fmt.Errorf("detaching app keys before user delete: %w", err)
-	}
-
-	result, err := tx.Exec(ctx,
-		`DELETE FROM _ayb_users WHERE id = $1`, id,
-	)
+	n, err := s.deleteUsers(ctx, "id = $1", id)
 	if err != nil {
-		return fmt.Errorf("deleting user: %w", err)
+		return err
 	}
-	if result.RowsAffected() == 0 {
+	if n == 0 {
 		return ErrUserNotFound
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("committing user delete: %w", err)
-	}
-
 	s.logger.Info("user deleted by admin", "user_id", id)
 	return nil
 }
@@ -962,7 +1230,16 @@ func hashToken(token string) string {
 	return hex.EncodeToString(h[:])
 }
 
-func (s *Service) createSession(ctx context.Context, userID string) (string, error) {
+// sessionDuration returns how long a refresh token lives (and how far each
+// rotation extends it) for a session with the given remember-me choice.
+func (s *Service) sessionDuration(rememberMe bool) time.Duration {
+	if rememberMe && s.rememberDur > 0 {
+		return s.rememberDur
+	}
+	return s.refreshDur
+}
+
+func (s *Service) createSession(ctx context.Context, userID string, rememberMe bool) (string, error) {
 	raw := make([]byte, refreshTokenBytes)
 	if _, err := rand.Read(raw); err != nil {
 		return "", fmt.Errorf("generating refresh token: %w", err)
@@ -971,9 +1248,9 @@ func (s *Service) createSession(ctx context.Context, userID string) (string, err
 	hash := hashToken(plaintext)
 
 	_, err := s.pool.Exec(ctx,
-		`INSERT INTO _ayb_sessions (user_id, token_hash, expires_at)
-		 VALUES ($1, $2, $3)`,
-		userID, hash, time.Now().Add(s.refreshDur),
+		`INSERT INTO _ayb_sessions (user_id, token_hash, expires_at, remember_me)
+		 VALUES ($1, $2, $3, $4)`,
+		userID, hash, time.Now().Add(s.sessionDuration(rememberMe)), rememberMe,
 	)
 	if err != nil {
 		return "", fmt.Errorf("inserting session: %w", err)
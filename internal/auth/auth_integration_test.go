@@ -10,7 +10,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +25,7 @@ import (
 	"github.com/allyourbase/ayb/internal/server"
 	"github.com/allyourbase/ayb/internal/sms"
 	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 var sharedPG *testutil.PGContainer
@@ -134,6 +138,43 @@ func TestRegisterSuccess(t *testing.T) {
 	testutil.True(t, resp.User["id"].(string) != "", "should have user id")
 }
 
+func TestRegisterDisabledAtRuntime(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+
+	creds := map[string]string{"email": "existing@example.com", "password": "password123"}
+	w := doJSON(t, srv, "POST", "/api/auth/register", creds, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+
+	next := config.Default()
+	next.Auth.Enabled = true
+	next.Auth.JWTSecret = testJWTSecret
+	next.Auth.RegistrationEnabled = false
+	testutil.Equal(t, "auth.registration_enabled", strings.Join(srv.ApplyRuntimeConfig(next), ","))
+
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "new@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusForbidden, w.Code)
+	testutil.Contains(t, w.Body.String(), "registration is disabled")
+
+	// Existing users can still log in.
+	w = doJSON(t, srv, "POST", "/api/auth/login", creds, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.True(t, parseAuthResp(t, w).Token != "", "login should return a token")
+
+	// Password login can be turned off independently.
+	next.Auth.RegistrationEnabled = true
+	next.Auth.PasswordLoginEnabled = false
+	srv.ApplyRuntimeConfig(next)
+	w = doJSON(t, srv, "POST", "/api/auth/login", creds, "")
+	testutil.StatusCode(t, http.StatusForbidden, w.Code)
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "new@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+}
+
 func TestRegisterDuplicateEmail(t *testing.T) {
 	ctx := context.Background()
 	srv := setupAuthServer(t, ctx)
@@ -147,6 +188,50 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 	testutil.StatusCode(t, http.StatusConflict, w.Code)
 }
 
+func TestRegisterHidingConflicts(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Auth.HideRegConflicts = true
+	authSvc := newAuthService()
+	mail := &recordingMailer{}
+	authSvc.SetMailer(mail, "TestApp", "http://localhost:8090/api")
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	body := map[string]string{"email": "hidden@example.com", "password": "password123"}
+	first := doJSON(t, srv, "POST", "/api/auth/register", body, "")
+	testutil.StatusCode(t, http.StatusCreated, first.Code)
+	testutil.False(t, strings.Contains(first.Body.String(), "token"), "tokens issued in hidden mode: %s", first.Body.String())
+
+	// The duplicate gets the same answer; the owner gets an email instead.
+	second := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "Hidden@Example.com", "password": "another-password",
+	}, "")
+	testutil.StatusCode(t, http.StatusCreated, second.Code)
+	testutil.Equal(t, first.Body.String(), second.Body.String())
+
+	testutil.SliceLen(t, mail.sent, 2) // verification email, then the attempt notice
+	notice := mail.sent[1]
+	testutil.Equal(t, "hidden@example.com", notice.To)
+	testutil.Equal(t, "Someone tried to sign up with your email", notice.Subject)
+	m := emailedToken.FindStringSubmatch(notice.Text)
+	testutil.SliceLen(t, m, 2)
+
+	// The original password still works, and the emailed link resets it.
+	w := doJSON(t, srv, "POST", "/api/auth/login", body, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.NoError(t, authSvc.ConfirmPasswordReset(ctx, m[1], "new-password123"))
+
+	// Validation errors are still reported.
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{"email": "bad", "password": "password123"}, "")
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+}
+
 func TestRegisterDuplicateEmailCaseInsensitive(t *testing.T) {
 	ctx := context.Background()
 	srv := setupAuthServer(t, ctx)
@@ -163,6 +248,80 @@ func TestRegisterDuplicateEmailCaseInsensitive(t *testing.T) {
 	testutil.StatusCode(t, http.StatusConflict, w.Code)
 }
 
+func TestRegisterNormalizedEmail(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	authSvc := newAuthService()
+	authSvc.SetEmailNormalization(true)
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "Jane.Doe+shop@googlemail.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "janedoe@gmail.com", parseAuthResp(t, w).User["email"].(string))
+
+	// Another spelling of the same mailbox is a duplicate.
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "janedoe+other@gmail.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusConflict, w.Code)
+
+	// Any spelling logs in.
+	w = doJSON(t, srv, "POST", "/api/auth/login", map[string]string{
+		"email": "jane.doe@gmail.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+}
+
+func TestRegisterWithoutNormalizationKeepsSubaddresses(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+
+	for _, email := range []string{"jane.doe@gmail.com", "janedoe@gmail.com", "jane.doe+shop@gmail.com"} {
+		w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+			"email": email, "password": "password123",
+		}, "")
+		testutil.StatusCode(t, http.StatusCreated, w.Code)
+		testutil.Equal(t, email, parseAuthResp(t, w).User["email"].(string))
+	}
+}
+
+func TestRegisterDisposableEmailBlocked(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	authSvc := newAuthService()
+	authSvc.SetDisposableEmailBlocking(true, nil, nil)
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "spammer@mailinator.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "disposable email addresses are not allowed")
+
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "someone@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+
+	var count int
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, `SELECT count(*) FROM _ayb_users`).Scan(&count))
+	testutil.Equal(t, 1, count)
+}
+
 // --- Login tests ---
 
 func TestLoginSuccess(t *testing.T) {
@@ -211,6 +370,53 @@ func TestLoginNonexistentEmail(t *testing.T) {
 	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestLoginInvalidEmail(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+
+	w := doJSON(t, srv, "POST", "/api/auth/login", map[string]string{
+		"email": "not an email", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "invalid email format")
+}
+
+func storedPasswordHash(t *testing.T, ctx context.Context, email string) string {
+	t.Helper()
+	var hash string
+	err := sharedPG.Pool.QueryRow(ctx,
+		"SELECT password_hash FROM _ayb_users WHERE email = $1", email).Scan(&hash)
+	testutil.NoError(t, err)
+	return hash
+}
+
+func TestLoginRehashesBcryptToArgon2id(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	svc := newAuthService()
+	svc.SetPasswordHash(auth.PasswordHashBcrypt)
+	_, _, _, err := svc.Register(ctx, "rehash@example.com", "password123")
+	testutil.NoError(t, err)
+	testutil.True(t, strings.HasPrefix(storedPasswordHash(t, ctx, "rehash@example.com"), "$2a$"),
+		"registration should use the configured bcrypt algorithm")
+
+	// Logging in while bcrypt is still configured keeps the hash.
+	_, _, _, err = svc.Login(ctx, "rehash@example.com", "password123", false)
+	testutil.NoError(t, err)
+	testutil.True(t, strings.HasPrefix(storedPasswordHash(t, ctx, "rehash@example.com"), "$2a$"),
+		"hash should not change while the algorithm is unchanged")
+
+	svc.SetPasswordHash(auth.PasswordHashArgon2id)
+	_, _, _, err = svc.Login(ctx, "rehash@example.com", "password123", false)
+	testutil.NoError(t, err)
+	testutil.True(t, strings.HasPrefix(storedPasswordHash(t, ctx, "rehash@example.com"), "$argon2id$"),
+		"login should rehash to argon2id")
+
+	_, _, _, err = svc.Login(ctx, "rehash@example.com", "password123", false)
+	testutil.NoError(t, err)
+}
+
 // --- /me endpoint tests ---
 
 func TestMeWithRegisterToken(t *testing.T) {
@@ -304,6 +510,60 @@ func TestCollectionEndpointRequiresAuth(t *testing.T) {
 	testutil.StatusCode(t, http.StatusOK, w.Code)
 }
 
+func TestPublicReadTable(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	_, err := sharedPG.Pool.Exec(ctx, `
+		CREATE TABLE authors (id SERIAL PRIMARY KEY, email TEXT NOT NULL);
+		CREATE TABLE posts (
+			id SERIAL PRIMARY KEY,
+			title TEXT NOT NULL,
+			author_id INTEGER REFERENCES authors(id)
+		);
+		INSERT INTO authors (email) VALUES ('writer@example.com');
+		INSERT INTO posts (title, author_id) VALUES ('hello', 1);
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Auth.PublicReadTables = []string{"posts"}
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, newAuthService(), nil)
+
+	// The public table is served without a token.
+	w := doJSON(t, srv, "GET", "/api/collections/posts/", nil, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "hello")
+	w = doJSON(t, srv, "GET", "/api/collections/posts/1", nil, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+
+	// Expanding into a table that isn't public is skipped.
+	w = doJSON(t, srv, "GET", "/api/collections/posts/1?expand=author_id", nil, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.False(t, strings.Contains(w.Body.String(), "writer@example.com"), "expanded a private table: %s", w.Body.String())
+
+	// Writes, and reads of other tables, still need a token.
+	w = doJSON(t, srv, "POST", "/api/collections/posts/", map[string]any{"title": "spam"}, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+	w = doJSON(t, srv, "DELETE", "/api/collections/posts/1", nil, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+	w = doJSON(t, srv, "GET", "/api/collections/authors/", nil, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+
+	// A signed-in user writes as usual.
+	w = doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "public-read@example.com", "password": "password123",
+	}, "")
+	resp := parseAuthResp(t, w)
+	w = doJSON(t, srv, "POST", "/api/collections/posts/", map[string]any{"title": "second"}, resp.Token)
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+}
+
 // --- RLS enforcement ---
 
 func TestRLSEnforcement(t *testing.T) {
@@ -381,6 +641,147 @@ func TestRLSEnforcement(t *testing.T) {
 	testutil.Equal(t, "user2 note", list2.Items[0]["content"])
 }
 
+func TestManagedOwnerID(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	_, err := sharedPG.Pool.Exec(ctx, `
+		CREATE TABLE notes (
+			id SERIAL PRIMARY KEY,
+			owner_id TEXT NOT NULL,
+			content TEXT NOT NULL
+		);
+		ALTER TABLE notes ENABLE ROW LEVEL SECURITY;
+		ALTER TABLE notes FORCE ROW LEVEL SECURITY;
+		CREATE POLICY notes_owner ON notes
+			USING (owner_id = current_setting('ayb.user_id', true));
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Database.ManagedFields = map[string]map[string]config.ManagedField{
+		"notes": {"owner_id": {From: config.ManagedFromUserID}},
+	}
+	testutil.NoError(t, cfg.Validate())
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, newAuthService(), nil)
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "owner@example.com", "password": "password123",
+	}, "")
+	owner := parseAuthResp(t, w)
+	ownerID := owner.User["id"].(string)
+
+	// The owner is the JWT subject, whatever the body says.
+	for _, body := range []map[string]any{
+		{"content": "mine"},
+		{"content": "spoofed", "owner_id": "someone-else"},
+	} {
+		w = doJSON(t, srv, "POST", "/api/collections/notes/", body, owner.Token)
+		testutil.StatusCode(t, http.StatusCreated, w.Code)
+		var note map[string]any
+		testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+		testutil.Equal(t, ownerID, note["owner_id"].(string))
+	}
+
+	// Updates can't hand the note to someone else.
+	var id int
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, "SELECT min(id) FROM notes").Scan(&id))
+	w = doJSON(t, srv, "PATCH", fmt.Sprintf("/api/collections/notes/%d", id),
+		map[string]any{"content": "edited", "owner_id": "someone-else"}, owner.Token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var stored string
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, "SELECT owner_id FROM notes WHERE id = $1", id).Scan(&stored))
+	testutil.Equal(t, ownerID, stored)
+}
+
+func TestRLSEnforcementWithClaimContext(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	// Rows are visible only when both tenant and org claims match.
+	_, err := sharedPG.Pool.Exec(ctx, `
+		CREATE TABLE docs (
+			id SERIAL PRIMARY KEY,
+			tenant_id TEXT NOT NULL,
+			org_id TEXT NOT NULL,
+			content TEXT NOT NULL
+		);
+		ALTER TABLE docs ENABLE ROW LEVEL SECURITY;
+		ALTER TABLE docs FORCE ROW LEVEL SECURITY;
+		CREATE POLICY docs_tenant ON docs
+			USING (tenant_id = current_setting('ayb.tenant_id', true)
+			   AND org_id = current_setting('ayb.org_id', true));
+		INSERT INTO docs (tenant_id, org_id, content) VALUES
+			('acme', '1', 'acme org 1'),
+			('acme', '2', 'acme org 2'),
+			('globex', '1', 'globex org 1');
+	`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	authSvc := newAuthService()
+	authSvc.SetRLSClaims([]string{"tenant_id", "org_id"})
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	tokenWith := func(extra jwt.MapClaims) string {
+		claims := jwt.MapClaims{
+			"sub":   "00000000-0000-0000-0000-000000000001",
+			"email": "tenant@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			claims[k] = v
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+		testutil.NoError(t, err)
+		return token
+	}
+	listDocs := func(token string) []string {
+		w := doJSON(t, srv, "GET", "/api/collections/docs/", nil, token)
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+		var list struct {
+			Items []map[string]any `json:"items"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+			t.Fatalf("parsing docs response: %v (body: %s)", err, w.Body.String())
+		}
+		var contents []string
+		for _, item := range list.Items {
+			contents = append(contents, item["content"].(string))
+		}
+		return contents
+	}
+
+	got := listDocs(tokenWith(jwt.MapClaims{"tenant_id": "acme", "org_id": 2}))
+	testutil.SliceLen(t, got, 1)
+	testutil.Equal(t, "acme org 2", got[0])
+
+	got = listDocs(tokenWith(jwt.MapClaims{"tenant_id": "globex", "org_id": "1"}))
+	testutil.SliceLen(t, got, 1)
+	testutil.Equal(t, "globex org 1", got[0])
+
+	// A token without the claims must not inherit a previous request's
+	// settings from a pooled connection.
+	testutil.SliceLen(t, listDocs(tokenWith(nil)), 0)
+
+	var leaked string
+	err = sharedPG.Pool.QueryRow(ctx, "SELECT COALESCE(current_setting('ayb.tenant_id', true), '')").Scan(&leaked)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "", leaked)
+}
+
 // --- Refresh token tests ---
 
 func setupAuthServerWithRefreshDur(t *testing.T, ctx context.Context, refreshDur time.Duration) *server.Server {
@@ -446,6 +847,57 @@ func TestRefreshTokenExpired(t *testing.T) {
 	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestRememberMeSessionOutlivesRegular(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	svc := auth.NewService(sharedPG.Pool, testJWTSecret, time.Hour, 50*time.Millisecond, 8, testutil.DiscardLogger())
+	svc.SetRememberMeDuration(time.Hour)
+
+	_, _, _, err := svc.Register(ctx, "remember@example.com", "password123")
+	testutil.NoError(t, err)
+
+	_, _, regular, err := svc.Login(ctx, "remember@example.com", "password123", false)
+	testutil.NoError(t, err)
+	_, _, remembered, err := svc.Login(ctx, "remember@example.com", "password123", true)
+	testutil.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, _, _, err = svc.RefreshToken(ctx, regular)
+	testutil.True(t, errors.Is(err, auth.ErrInvalidRefreshToken), "regular session should have expired")
+
+	_, _, rotated, err := svc.RefreshToken(ctx, remembered)
+	testutil.NoError(t, err)
+
+	// Rotation keeps the session's own duration rather than falling back
+	// to the short global one.
+	time.Sleep(100 * time.Millisecond)
+	_, _, _, err = svc.RefreshToken(ctx, rotated)
+	testutil.NoError(t, err)
+}
+
+func TestLoginRememberMeOverHTTP(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "remember-http@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+
+	w = doJSON(t, srv, "POST", "/api/auth/login", map[string]any{
+		"email": "remember-http@example.com", "password": "password123", "rememberMe": true,
+	}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+
+	var remembered int
+	err := sharedPG.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM _ayb_sessions WHERE remember_me`).Scan(&remembered)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 1, remembered)
+}
+
 func TestLogout(t *testing.T) {
 	ctx := context.Background()
 	srv := setupAuthServer(t, ctx)
@@ -468,6 +920,323 @@ func TestLogout(t *testing.T) {
 	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestCookieModeLoginRefreshLogout(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Auth.Cookies.Enabled = true
+	cfg.Auth.Cookies.AccessToken = true
+	authSvc := newAuthService()
+	authSvc.SetCookieConfig(&auth.CookieConfig{AccessToken: true, SameSite: "lax", Secure: true})
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	_, _, _, err := authSvc.Register(ctx, "cookies@example.com", "password123")
+	testutil.NoError(t, err)
+
+	// Login sets the cookies and keeps the tokens out of the body.
+	w := doJSON(t, srv, "POST", "/api/auth/login", map[string]string{
+		"email": "cookies@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var body struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refreshToken"`
+		CSRFToken    string `json:"csrfToken"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	testutil.Equal(t, "", body.Token)
+	testutil.Equal(t, "", body.RefreshToken)
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	refresh, access, csrf := cookies[auth.RefreshTokenCookie], cookies[auth.AccessTokenCookie], cookies[auth.CSRFCookie]
+	testutil.NotNil(t, refresh)
+	testutil.NotNil(t, access)
+	testutil.NotNil(t, csrf)
+	testutil.True(t, refresh.HttpOnly && refresh.Secure, "refresh cookie must be httpOnly and Secure")
+	testutil.Equal(t, http.SameSiteLaxMode, refresh.SameSite)
+	testutil.Equal(t, body.CSRFToken, csrf.Value)
+
+	send := func(method, path string, withCSRF bool, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if withCSRF {
+			req.Header.Set(auth.CSRFHeader, csrf.Value)
+		}
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	// The access cookie authenticates API requests.
+	w = send("GET", "/api/auth/me", false, access)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "cookies@example.com")
+
+	// A cross-site POST riding on the cookies is rejected, on collections as
+	// on auth routes; the same request with a Bearer token is not a CSRF risk.
+	for _, path := range []string{"/api/collections/notes", "/api/auth/verify/resend"} {
+		w = send("POST", path, false, access, csrf)
+		testutil.StatusCode(t, http.StatusForbidden, w.Code)
+		testutil.Contains(t, w.Body.String(), "CSRF")
+	}
+	w = doJSON(t, srv, "POST", "/api/collections/notes", map[string]string{}, access.Value)
+	testutil.True(t, w.Code != http.StatusForbidden, "bearer request should skip the CSRF check, got %d", w.Code)
+
+	// Refresh reads the cookie, requires the CSRF token, and rotates.
+	w = send("POST", "/api/auth/refresh", false, refresh, csrf)
+	testutil.StatusCode(t, http.StatusForbidden, w.Code)
+	w = send("POST", "/api/auth/refresh", true, refresh, csrf)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var rotated *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == auth.RefreshTokenCookie {
+			rotated = c
+		}
+	}
+	testutil.NotNil(t, rotated)
+	testutil.True(t, rotated.Value != refresh.Value, "refresh token should rotate")
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	csrf = &http.Cookie{Name: auth.CSRFCookie, Value: body.CSRFToken}
+
+	// Logout revokes the session and clears the cookies.
+	w = send("POST", "/api/auth/logout", true, rotated, csrf)
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	cleared := map[string]bool{}
+	for _, c := range w.Result().Cookies() {
+		if c.MaxAge < 0 {
+			cleared[c.Name] = true
+		}
+	}
+	testutil.True(t, cleared[auth.RefreshTokenCookie] && cleared[auth.AccessTokenCookie] && cleared[auth.CSRFCookie],
+		"logout should clear all token cookies, got %v", cleared)
+	_, _, _, err = authSvc.RefreshToken(ctx, rotated.Value)
+	testutil.True(t, errors.Is(err, auth.ErrInvalidRefreshToken), "expected ErrInvalidRefreshToken, got %v", err)
+
+	// The body-based flow still works in cookie mode: a refresh token sent
+	// in the body is rotated in the body, without setting cookies.
+	_, _, refreshToken, err := authSvc.Login(ctx, "cookies@example.com", "password123", false)
+	testutil.NoError(t, err)
+	w = doJSON(t, srv, "POST", "/api/auth/refresh", map[string]string{"refreshToken": refreshToken}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.SliceLen(t, w.Result().Cookies(), 0)
+	body.Token, body.RefreshToken, body.CSRFToken = "", "", ""
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	testutil.True(t, body.Token != "", "body refresh should return the access token")
+	testutil.True(t, body.RefreshToken != "" && body.RefreshToken != refreshToken,
+		"body refresh should return a rotated refresh token")
+	testutil.Equal(t, "", body.CSRFToken)
+
+	w = doJSON(t, srv, "POST", "/api/auth/logout", map[string]string{"refreshToken": body.RefreshToken}, "")
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	_, _, _, err = authSvc.RefreshToken(ctx, body.RefreshToken)
+	testutil.True(t, errors.Is(err, auth.ErrInvalidRefreshToken), "expected ErrInvalidRefreshToken, got %v", err)
+}
+
+// --- Admin user management tests ---
+
+func TestDisabledUserCannotLogin(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+	svc := newAuthService()
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "disabled@example.com", "password": "password123",
+	}, "")
+	resp := parseAuthResp(t, w)
+	userID := resp.User["id"].(string)
+
+	testutil.NoError(t, svc.SetUserDisabled(ctx, userID, true))
+
+	// Login with the right password is refused with a distinct error; a wrong
+	// password still gets the generic one.
+	_, _, _, err := svc.Login(ctx, "disabled@example.com", "password123", false)
+	testutil.True(t, errors.Is(err, auth.ErrAccountDisabled), "expected ErrAccountDisabled, got %v", err)
+	_, _, _, err = svc.Login(ctx, "disabled@example.com", "wrong-password", false)
+	testutil.True(t, errors.Is(err, auth.ErrInvalidCredentials), "expected ErrInvalidCredentials, got %v", err)
+	w = doJSON(t, srv, "POST", "/api/auth/login", map[string]string{
+		"email": "disabled@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusForbidden, w.Code)
+	testutil.Contains(t, w.Body.String(), "account is disabled")
+
+	// Disabling signed the user out.
+	w = doJSON(t, srv, "POST", "/api/auth/refresh", map[string]string{
+		"refreshToken": resp.RefreshToken,
+	}, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+
+	list, err := svc.ListUsers(ctx, 1, 20, "disabled@")
+	testutil.NoError(t, err)
+	testutil.NotNil(t, list.Items[0].DisabledAt)
+
+	// Re-enabling restores login.
+	testutil.NoError(t, svc.SetUserDisabled(ctx, userID, false))
+	_, _, _, err = svc.Login(ctx, "disabled@example.com", "password123", false)
+	testutil.NoError(t, err)
+
+	err = svc.SetUserDisabled(ctx, "00000000-0000-0000-0000-000000000099", true)
+	testutil.True(t, errors.Is(err, auth.ErrUserNotFound), "expected ErrUserNotFound, got %v", err)
+}
+
+func TestDisabledUserRefreshRejected(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+
+	user, _, refreshToken, err := svc.Register(ctx, "disabled-refresh@example.com", "password123")
+	testutil.NoError(t, err)
+
+	// A session that survives disabling (e.g. created concurrently) still
+	// can't be refreshed.
+	_, err = sharedPG.Pool.Exec(ctx, `UPDATE _ayb_users SET disabled_at = NOW() WHERE id = $1`, user.ID)
+	testutil.NoError(t, err)
+	_, _, _, err = svc.RefreshToken(ctx, refreshToken)
+	testutil.True(t, errors.Is(err, auth.ErrAccountDisabled), "expected ErrAccountDisabled, got %v", err)
+}
+
+func TestLogoutUserRevokesRefreshTokens(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+
+	user, _, first, err := svc.Register(ctx, "force-logout@example.com", "password123")
+	testutil.NoError(t, err)
+	_, _, second, err := svc.Login(ctx, "force-logout@example.com", "password123", false)
+	testutil.NoError(t, err)
+
+	n, err := svc.LogoutUser(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(2), n)
+	for _, token := range []string{first, second} {
+		_, _, _, err = svc.RefreshToken(ctx, token)
+		testutil.True(t, errors.Is(err, auth.ErrInvalidRefreshToken), "expected ErrInvalidRefreshToken, got %v", err)
+	}
+
+	// The user isn't disabled and can log in again.
+	_, _, _, err = svc.Login(ctx, "force-logout@example.com", "password123", false)
+	testutil.NoError(t, err)
+
+	_, err = svc.LogoutUser(ctx, "00000000-0000-0000-0000-000000000099")
+	testutil.True(t, errors.Is(err, auth.ErrUserNotFound), "expected ErrUserNotFound, got %v", err)
+}
+
+// recordingPublisher collects published auth events.
+type recordingPublisher struct{ events []auth.Event }
+
+func (p *recordingPublisher) PublishAuthEvent(event auth.Event) {
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) types() []string {
+	var types []string
+	for _, e := range p.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestAuthEventsPublished(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+	pub := &recordingPublisher{}
+	svc.SetEventPublisher(pub)
+
+	user, _, refresh, err := svc.Register(ctx, "events@example.com", "password123")
+	testutil.NoError(t, err)
+	testutil.NoError(t, svc.Logout(ctx, refresh))
+	// Logging out an unknown session publishes nothing.
+	testutil.NoError(t, svc.Logout(ctx, refresh))
+	_, _, _, err = svc.Login(ctx, "events@example.com", "password123", false)
+	testutil.NoError(t, err)
+	_, _, _, err = svc.Login(ctx, "events@example.com", "wrong-password", false)
+	testutil.True(t, errors.Is(err, auth.ErrInvalidCredentials), "expected ErrInvalidCredentials, got %v", err)
+	_, err = svc.LogoutUser(ctx, user.ID)
+	testutil.NoError(t, err)
+
+	testutil.Equal(t, strings.Join([]string{auth.EventSignup, auth.EventLogin, auth.EventLogout, auth.EventLogin, auth.EventLogout}, ","),
+		strings.Join(pub.types(), ","))
+	for _, e := range pub.events {
+		testutil.Equal(t, user.ID, e.UserID)
+		testutil.False(t, e.Timestamp.IsZero(), "event %s has no timestamp", e.Type)
+	}
+}
+
+func TestExportUser(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+
+	user, _, _, err := svc.Register(ctx, "export@example.com", "password123")
+	testutil.NoError(t, err)
+	_, _, _, err = svc.Login(ctx, "export@example.com", "password123", true)
+	testutil.NoError(t, err)
+	_, _, err = svc.CreateAPIKey(ctx, user.ID, "ci")
+	testutil.NoError(t, err)
+	_, err = sharedPG.Pool.Exec(ctx,
+		`INSERT INTO _ayb_oauth_accounts (user_id, provider, provider_user_id, email, name)
+		 VALUES ($1, 'github', 'gh-42', 'export@example.com', 'Ex Port')`, user.ID)
+	testutil.NoError(t, err)
+
+	export, err := svc.ExportUser(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, user.ID, export.User.ID)
+	testutil.Equal(t, "export@example.com", export.User.Email)
+	testutil.False(t, export.User.EmailVerified, "expected unverified email")
+	testutil.SliceLen(t, export.OAuthAccounts, 1)
+	testutil.Equal(t, "github", export.OAuthAccounts[0].Provider)
+	testutil.Equal(t, "gh-42", export.OAuthAccounts[0].ProviderUserID)
+	testutil.SliceLen(t, export.APIKeys, 1)
+	testutil.Equal(t, "ci", export.APIKeys[0].Name)
+	testutil.SliceLen(t, export.MFA, 0)
+	testutil.SliceLen(t, export.Sessions, 2)
+	testutil.True(t, export.Sessions[1].RememberMe, "expected remember-me session")
+
+	_, err = svc.ExportUser(ctx, "00000000-0000-0000-0000-000000000099")
+	testutil.True(t, errors.Is(err, auth.ErrUserNotFound), "expected ErrUserNotFound, got %v", err)
+}
+
+func TestDeleteUsersByFilter(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+
+	for _, email := range []string{"keep@example.com", "drop1@spam.test", "drop2@spam.test"} {
+		_, _, _, err := svc.Register(ctx, email, "password123")
+		testutil.NoError(t, err)
+	}
+	_, err := sharedPG.Pool.Exec(ctx, `UPDATE _ayb_users SET email_verified = true WHERE email = 'drop2@spam.test'`)
+	testutil.NoError(t, err)
+
+	_, err = svc.DeleteUsers(ctx, auth.UserFilter{})
+	testutil.True(t, errors.Is(err, auth.ErrValidation), "expected ErrValidation, got %v", err)
+
+	filter := auth.UserFilter{Search: "@spam.test", Unverified: true}
+	n, err := svc.CountUsers(ctx, filter)
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(1), n)
+	n, err = svc.DeleteUsers(ctx, filter)
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(1), n)
+
+	list, err := svc.ListUsers(ctx, 1, 20, "")
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, list.TotalItems)
+
+	n, err = svc.DeleteUsers(ctx, auth.UserFilter{CreatedBefore: time.Now().Add(time.Minute)})
+	testutil.NoError(t, err)
+	testutil.Equal(t, int64(2), n)
+}
+
 // --- OAuth integration tests ---
 
 func TestOAuthLoginNewUser(t *testing.T) {
@@ -631,6 +1400,7 @@ func TestOAuthHandlerFullFlowMocked(t *testing.T) {
 		"google": {Enabled: true, ClientID: "test-id", ClientSecret: "test-secret"},
 	}
 	cfg.Auth.OAuthRedirectURL = "http://localhost:5173/callback"
+	cfg.Auth.OAuthRedirectURLs = []string{"https://app.example.com/callback"}
 
 	svc := newAuthService()
 	srv := server.New(cfg, logger, ch, sharedPG.Pool, svc, nil)
@@ -683,6 +1453,122 @@ func TestOAuthHandlerFullFlowMocked(t *testing.T) {
 	).Scan(&count)
 	testutil.NoError(t, err)
 	testutil.Equal(t, 1, count)
+
+	// A login may choose an allowlisted redirect and carry a deep link.
+	req = httptest.NewRequest(http.MethodGet,
+		"/api/auth/oauth/google?redirect=https%3A%2F%2Fapp.example.com%2Fcallback&returnTo=%2Fprojects%2F7", nil)
+	req.Host = "localhost:8090"
+	w = httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	testutil.StatusCode(t, http.StatusTemporaryRedirect, w.Code)
+	authURL, err := url.Parse(w.Header().Get("Location"))
+	testutil.NoError(t, err)
+
+	req = httptest.NewRequest(http.MethodGet,
+		"/api/auth/oauth/google/callback?code=test-code&state="+url.QueryEscape(authURL.Query().Get("state")), nil)
+	req.Host = "localhost:8090"
+	w = httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	testutil.StatusCode(t, http.StatusTemporaryRedirect, w.Code)
+	dest, err := url.Parse(w.Header().Get("Location"))
+	testutil.NoError(t, err)
+	testutil.Equal(t, "https://app.example.com/callback", dest.Scheme+"://"+dest.Host+dest.Path)
+	fragment, err := url.ParseQuery(dest.Fragment)
+	testutil.NoError(t, err)
+	testutil.True(t, fragment.Get("token") != "", "fragment should carry the access token")
+	testutil.Equal(t, "/projects/7", fragment.Get("returnTo"))
+}
+
+func TestOAuthLoginStoresProviderProfile(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	locale := "en"
+	fakeProvider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if err := json.NewEncoder(w).Encode(map[string]string{
+				"access_token": "fake-access-token",
+			}); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		case "/userinfo":
+			if err := json.NewEncoder(w).Encode(map[string]any{
+				"id":             "profile-1",
+				"email":          "profile@example.com",
+				"name":           "Profile User",
+				"picture":        "https://example.com/avatar.png",
+				"locale":         locale,
+				"verified_email": true,
+			}); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeProvider.Close()
+
+	auth.SetProviderURLs("google", auth.OAuthProviderConfig{
+		AuthURL:     "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:    fakeProvider.URL + "/token",
+		UserInfoURL: fakeProvider.URL + "/userinfo",
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+	defer auth.ResetProviderURLs("google")
+
+	svc := newAuthService()
+	client := auth.OAuthClientConfig{ClientID: "test-id", ClientSecret: "test-secret"}
+	info, err := auth.ExchangeCode(ctx, "google", client, "code", "http://localhost/callback")
+	testutil.NoError(t, err)
+	user, token, _, err := svc.OAuthLogin(ctx, "google", info)
+	testutil.NoError(t, err)
+
+	identities, err := svc.OAuthIdentities(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, identities, 1)
+	var profile map[string]any
+	testutil.NoError(t, json.Unmarshal(identities[0].Profile, &profile))
+	testutil.Equal(t, "profile-1", profile["id"].(string))
+	testutil.Equal(t, "https://example.com/avatar.png", profile["picture"].(string))
+	testutil.Equal(t, "en", profile["locale"].(string))
+	testutil.Equal(t, true, profile["verified_email"].(bool))
+
+	// The first link fills in the user's avatar.
+	stored, err := svc.UserByID(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "https://example.com/avatar.png", stored.Metadata["avatar_url"].(string))
+
+	// Later logins refresh the stored profile.
+	locale = "fr"
+	info, err = auth.ExchangeCode(ctx, "google", client, "code", "http://localhost/callback")
+	testutil.NoError(t, err)
+	_, _, _, err = svc.OAuthLogin(ctx, "google", info)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, svc, nil)
+
+	w := doJSON(t, srv, "GET", "/api/auth/me/identities", nil, token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var listed []struct {
+		Provider       string         `json:"provider"`
+		ProviderUserID string         `json:"providerUserId"`
+		Profile        map[string]any `json:"profile"`
+	}
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&listed))
+	testutil.SliceLen(t, listed, 1)
+	testutil.Equal(t, "google", listed[0].Provider)
+	testutil.Equal(t, "profile-1", listed[0].ProviderUserID)
+	testutil.Equal(t, "fr", listed[0].Profile["locale"].(string))
+
+	w = doJSON(t, srv, "GET", "/api/auth/me/identities", nil, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
 }
 
 // splitQuery splits a URL's query string into key=value pairs.
@@ -812,6 +1698,167 @@ func TestRefreshTokenRejectedAfterExpiry(t *testing.T) {
 	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
 }
 
+// --- Profile tests ---
+
+// recordingMailer keeps sent messages so tests can follow emailed links.
+type recordingMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var emailedToken = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+func TestUpdateMeProfileOverHTTP(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+
+	w := doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
+		"email": "profile@example.com", "password": "password123",
+	}, "")
+	resp := parseAuthResp(t, w)
+
+	w = doJSON(t, srv, "PATCH", "/api/auth/me", map[string]any{"displayName": "  Ann  "}, resp.Token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var user map[string]any
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	testutil.Equal(t, "Ann", user["displayName"].(string))
+	testutil.Equal(t, "profile@example.com", user["email"].(string))
+
+	// No metadata keys are allowed by default.
+	w = doJSON(t, srv, "PATCH", "/api/auth/me", map[string]any{"metadata": map[string]any{"locale": "en"}}, resp.Token)
+	testutil.StatusCode(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateProfileMetadataMerge(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	authSvc := newAuthService()
+	authSvc.SetProfileMetadataKeys([]string{"locale", "avatar_url"})
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "meta@example.com", "password123", 8, "")
+	testutil.NoError(t, err)
+
+	updated, err := authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{
+		Metadata: map[string]any{"locale": "en", "avatar_url": "https://example.com/a.png"},
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, "en", updated.Metadata["locale"].(string))
+
+	// Keys are merged; null removes one.
+	updated, err = authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{
+		Metadata: map[string]any{"locale": "fr", "avatar_url": nil},
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, "fr", updated.Metadata["locale"].(string))
+	_, ok := updated.Metadata["avatar_url"]
+	testutil.False(t, ok, "avatar_url should be removed")
+
+	_, err = authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{
+		Metadata: map[string]any{"role": "admin"},
+	})
+	testutil.ErrorContains(t, err, `metadata key "role" is not allowed`)
+}
+
+func TestEmailChangeRequiresConfirmation(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	authSvc := newAuthService()
+	mail := &recordingMailer{}
+	authSvc.SetMailer(mail, "TestApp", "http://localhost:8090/api")
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "old@example.com", "password123", 8, "")
+	testutil.NoError(t, err)
+
+	// Requesting the change leaves the email as is.
+	newEmail := "new@example.com"
+	updated, err := authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{Email: &newEmail})
+	testutil.NoError(t, err)
+	testutil.Equal(t, "old@example.com", updated.Email)
+	testutil.Equal(t, newEmail, updated.PendingEmail)
+	_, _, _, err = authSvc.Login(ctx, newEmail, "password123", false)
+	testutil.ErrorContains(t, err, "invalid email or password")
+
+	// The confirmation goes to the new address only.
+	testutil.SliceLen(t, mail.sent, 1)
+	testutil.Equal(t, newEmail, mail.sent[0].To)
+	m := emailedToken.FindStringSubmatch(mail.sent[0].Text)
+	testutil.SliceLen(t, m, 2)
+
+	testutil.True(t, errors.Is(authSvc.ConfirmEmailChange(ctx, "not-the-token"), auth.ErrInvalidEmailChangeToken), "token should be rejected")
+	current, err := authSvc.UserByID(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "old@example.com", current.Email)
+
+	testutil.NoError(t, authSvc.ConfirmEmailChange(ctx, m[1]))
+	current, err = authSvc.UserByID(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, newEmail, current.Email)
+	testutil.Equal(t, "", current.PendingEmail)
+	var verified bool
+	err = sharedPG.Pool.QueryRow(ctx,
+		`SELECT email_verified FROM _ayb_users WHERE id = $1`, user.ID,
+	).Scan(&verified)
+	testutil.NoError(t, err)
+	testutil.True(t, verified, "confirmed email should be verified")
+
+	// The token is single use.
+	testutil.True(t, errors.Is(authSvc.ConfirmEmailChange(ctx, m[1]), auth.ErrInvalidEmailChangeToken), "token should be rejected")
+}
+
+func TestEmailChangeExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	authSvc := newAuthService()
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "old@example.com", "password123", 8, "")
+	testutil.NoError(t, err)
+
+	token := "expired-email-change-token"
+	_, err = sharedPG.Pool.Exec(ctx,
+		`INSERT INTO _ayb_email_changes (user_id, new_email, token_hash, expires_at)
+		 VALUES ($1, $2, $3, $4)`,
+		user.ID, "new@example.com", auth.HashTokenForTest(token), time.Now().Add(-time.Minute),
+	)
+	testutil.NoError(t, err)
+
+	testutil.True(t, errors.Is(authSvc.ConfirmEmailChange(ctx, token), auth.ErrInvalidEmailChangeToken), "token should be rejected")
+	current, err := authSvc.UserByID(ctx, user.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "old@example.com", current.Email)
+}
+
+func TestEmailChangeRejectsTakenEmail(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	authSvc := newAuthService()
+	mail := &recordingMailer{}
+	authSvc.SetMailer(mail, "TestApp", "http://localhost:8090/api")
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "old@example.com", "password123", 8, "")
+	testutil.NoError(t, err)
+	_, err = auth.CreateUser(ctx, sharedPG.Pool, "taken@example.com", "password123", 8, "")
+	testutil.NoError(t, err)
+
+	taken := "Taken@example.com"
+	_, err = authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{Email: &taken})
+	testutil.True(t, errors.Is(err, auth.ErrEmailTaken), "taken email should be rejected")
+	testutil.SliceLen(t, mail.sent, 0)
+
+	// An address registered after the request can't be claimed on confirm.
+	later := "later@example.com"
+	_, err = authSvc.UpdateProfile(ctx, user.ID, auth.ProfileUpdate{Email: &later})
+	testutil.NoError(t, err)
+	_, err = auth.CreateUser(ctx, sharedPG.Pool, later, "password123", 8, "")
+	testutil.NoError(t, err)
+	m := emailedToken.FindStringSubmatch(mail.sent[0].Text)
+	testutil.SliceLen(t, m, 2)
+	testutil.True(t, errors.Is(authSvc.ConfirmEmailChange(ctx, m[1]), auth.ErrEmailTaken), "taken email should be rejected")
+}
+
 // --- Verification token tests ---
 
 func TestVerificationTokenReuse(t *testing.T) {
@@ -821,7 +1868,7 @@ func TestVerificationTokenReuse(t *testing.T) {
 	authSvc := newAuthService()
 
 	// Create a user.
-	user, err := auth.CreateUser(ctx, sharedPG.Pool, "verify@example.com", "password123", 8)
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "verify@example.com", "password123", 8, "")
 	testutil.NoError(t, err)
 
 	// Manually insert a verification token (simulating SendVerificationEmail).
@@ -858,7 +1905,7 @@ func TestVerificationTokenExpiry(t *testing.T) {
 	authSvc := newAuthService()
 
 	// Create a user.
-	user, err := auth.CreateUser(ctx, sharedPG.Pool, "expired@example.com", "password123", 8)
+	user, err := auth.CreateUser(ctx, sharedPG.Pool, "expired@example.com", "password123", 8, "")
 	testutil.NoError(t, err)
 
 	// Insert an expired verification token.
@@ -948,6 +1995,50 @@ func TestAPIKeyCreateWithScope(t *testing.T) {
 	testutil.Equal(t, "readonly-key", resp.APIKey.Name)
 }
 
+func TestWhoamiUserAndAPIKey(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+	token := registerAndGetToken(t, srv, "whoami@example.com")
+
+	type whoami struct {
+		Type          string   `json:"type"`
+		Subject       string   `json:"subject"`
+		Email         string   `json:"email"`
+		Scope         string   `json:"scope"`
+		AllowedTables []string `json:"allowedTables"`
+		ExpiresAt     *string  `json:"expiresAt"`
+	}
+
+	w := doJSON(t, srv, "GET", "/api/auth/whoami", nil, token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var user whoami
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+	testutil.Equal(t, "user", user.Type)
+	testutil.Equal(t, "whoami@example.com", user.Email)
+	testutil.Equal(t, "*", user.Scope)
+	testutil.NotNil(t, user.ExpiresAt)
+
+	w = doJSON(t, srv, "POST", "/api/auth/api-keys/", map[string]any{
+		"name": "whoami-key", "scope": "readonly", "allowedTables": []string{"posts"},
+	}, token)
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	var created struct {
+		Key string `json:"key"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = doJSON(t, srv, "GET", "/api/auth/whoami", nil, created.Key)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var key whoami
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+	testutil.Equal(t, "api_key", key.Type)
+	testutil.Equal(t, user.Subject, key.Subject)
+	testutil.Equal(t, "readonly", key.Scope)
+	testutil.SliceLen(t, key.AllowedTables, 1)
+	// The key was created without an expiry.
+	testutil.Nil(t, key.ExpiresAt)
+}
+
 func TestAPIKeyCreateInvalidScope(t *testing.T) {
 	ctx := context.Background()
 	srv := setupAuthServer(t, ctx)
@@ -1111,6 +2202,42 @@ func TestAPIKeyIsolationBetweenUsers(t *testing.T) {
 	testutil.StatusCode(t, http.StatusNotFound, w.Code)
 }
 
+func TestAPIKeyBasicAuth(t *testing.T) {
+	ctx := context.Background()
+	srv := setupAuthServer(t, ctx)
+	token := registerAndGetToken(t, srv, "apikey-basic@example.com")
+
+	w := doJSON(t, srv, "POST", "/api/auth/api-keys/", map[string]string{"name": "tooling"}, token)
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	var createResp struct {
+		Key    string `json:"key"`
+		APIKey struct {
+			ID string `json:"id"`
+		} `json:"apiKey"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &createResp))
+
+	me := func(username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	w = me(createResp.Key, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "apikey-basic@example.com")
+
+	// Only an empty password is accepted.
+	testutil.StatusCode(t, http.StatusUnauthorized, me(createResp.Key, "x").Code)
+
+	// A revoked key fails the same way it does as a Bearer token.
+	w = doJSON(t, srv, "DELETE", "/api/auth/api-keys/"+createResp.APIKey.ID, nil, token)
+	testutil.StatusCode(t, http.StatusNoContent, w.Code)
+	testutil.StatusCode(t, http.StatusUnauthorized, me(createResp.Key, "").Code)
+}
+
 // --- Magic link integration tests ---
 
 func setupMagicLinkServer(t *testing.T, ctx context.Context) *server.Server {
@@ -1444,6 +2571,38 @@ func TestSMSFullFlow_ExistingUser(t *testing.T) {
 	testutil.Equal(t, first.ID, second.ID)
 }
 
+func TestSMSFullFlow_AlphanumericCode(t *testing.T) {
+	svc, capture := setupSMSService(t)
+	svc.SetSMSConfig(sms.Config{
+		CodeLength:       8,
+		CodeAlphabet:     "alphanumeric",
+		Expiry:           5 * time.Minute,
+		MaxAttempts:      3,
+		AllowedCountries: []string{"US"},
+		TestPhoneNumbers: map[string]string{"+15550001234": "tEsT-CoDe"},
+	})
+	ctx := t.Context()
+
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+14155552671"))
+	sent := capture.LastCode()
+	testutil.Equal(t, 9, len(sent)) // grouped as XXXX-XXXX
+	code := strings.ReplaceAll(sent, "-", "")
+	for _, c := range code {
+		testutil.True(t, strings.ContainsRune("23456789ABCDEFGHJKMNPQRSTUVWXYZ", c), "unexpected character %c", c)
+	}
+
+	// Codes are matched case-insensitively, with or without the grouping.
+	user, _, _, err := svc.ConfirmSMSCode(ctx, "+14155552671", strings.ToLower(code))
+	testutil.NoError(t, err)
+	testutil.Equal(t, "+14155552671", user.Phone)
+
+	// Test phone numbers keep their predetermined code.
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+15550001234"))
+	testutil.SliceLen(t, capture.Calls, 1)
+	_, _, _, err = svc.ConfirmSMSCode(ctx, "+15550001234", "TESTCODE")
+	testutil.NoError(t, err)
+}
+
 func TestSMSCode_ConsumedAfterUse(t *testing.T) {
 	svc, capture := setupSMSService(t)
 	ctx := t.Context()
@@ -1531,6 +2690,36 @@ func TestSMSCode_NewRequestDeletesOldCode(t *testing.T) {
 	testutil.Equal(t, 1, count)
 }
 
+func TestSMSCode_ResendCooldown(t *testing.T) {
+	svc, capture := setupSMSService(t)
+	ctx := t.Context()
+	svc.SetSMSConfig(sms.Config{
+		CodeLength:       6,
+		Expiry:           5 * time.Minute,
+		MaxAttempts:      3,
+		ResendCooldown:   30 * time.Second,
+		AllowedCountries: []string{"US", "CA"},
+		TestPhoneNumbers: map[string]string{"+15005550006": "123456"},
+	})
+
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+14155552671"))
+	err := svc.RequestSMSCode(ctx, "+14155552671")
+	testutil.True(t, errors.Is(err, auth.ErrResendTooSoon), "back-to-back request should be throttled")
+	testutil.SliceLen(t, capture.Calls, 1)
+
+	// The first code must survive the throttled request.
+	_, _, _, err = svc.ConfirmSMSCode(ctx, "+14155552671", capture.LastCode())
+	testutil.NoError(t, err)
+
+	// Cooldown is per phone.
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+16135550123"))
+	testutil.SliceLen(t, capture.Calls, 2)
+
+	// Test phone numbers are exempt.
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+15005550006"))
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+15005550006"))
+}
+
 func TestSMS_GeoBlock(t *testing.T) {
 	svc, capture := setupSMSService(t)
 	ctx := t.Context()
@@ -1549,6 +2738,35 @@ func TestSMS_GeoBlock(t *testing.T) {
 	testutil.Equal(t, 0, count)
 }
 
+func TestSMS_GeoBlock_Blocklist(t *testing.T) {
+	svc, capture := setupSMSService(t)
+	ctx := t.Context()
+
+	svc.SetSMSConfig(sms.Config{
+		CodeLength:       6,
+		Expiry:           5 * time.Minute,
+		MaxAttempts:      3,
+		BlockedCountries: []string{"GB"},
+	})
+
+	// UK number — blocked.
+	err := svc.RequestSMSCode(ctx, "+442079460958")
+	testutil.NoError(t, err) // no error returned (anti-enumeration)
+	testutil.SliceLen(t, capture.Calls, 0)
+
+	var count int
+	err = svc.DB().QueryRow(ctx,
+		`SELECT COUNT(*) FROM _ayb_sms_codes WHERE phone = $1`, "+442079460958",
+	).Scan(&count)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, count)
+
+	// Indian number — not on the blocklist, so allowed even though it would
+	// fall outside the default US/CA allowlist.
+	testutil.NoError(t, svc.RequestSMSCode(ctx, "+919876543210"))
+	testutil.SliceLen(t, capture.Calls, 1)
+}
+
 func TestSMS_DailyLimitCircuitBreaker(t *testing.T) {
 	svc, _ := setupSMSService(t)
 	ctx := t.Context()
@@ -1847,11 +3065,15 @@ func TestConfirmSMSMFAEnrollment_Success(t *testing.T) {
 	ctx := t.Context()
 	user := registerTestUser(t, svc)
 
+	pub := &recordingPublisher{}
+	svc.SetEventPublisher(pub)
+
 	testutil.NoError(t, svc.EnrollSMSMFA(ctx, user.ID, "+14155552671"))
 	code := capture.LastCode()
 
 	err := svc.ConfirmSMSMFAEnrollment(ctx, user.ID, "+14155552671", code)
 	testutil.NoError(t, err)
+	testutil.Equal(t, auth.EventMFAEnrolled, strings.Join(pub.types(), ","))
 
 	// Verify enrollment is now enabled with enrolled_at set.
 	var enabled bool
@@ -1903,6 +3125,17 @@ func enrollMFA(t *testing.T, svc *auth.Service, capture *sms.CaptureProvider, us
 	capture.Reset()
 }
 
+// mfaChallenge logs in as the user created by registerTestUser, who must be
+// enrolled in MFA, and returns the claims of the MFA challenge token.
+func mfaChallenge(t *testing.T, svc *auth.Service) *auth.Claims {
+	t.Helper()
+	_, token, _, err := svc.Login(t.Context(), "mfa-test@example.com", "password123", false)
+	testutil.NoError(t, err)
+	claims, err := svc.ValidateMFAChallengeToken(token)
+	testutil.NoError(t, err)
+	return claims
+}
+
 func TestChallengeSMSMFA_Success(t *testing.T) {
 	svc, capture := setupMFAService(t)
 	ctx := t.Context()
@@ -1910,7 +3143,7 @@ func TestChallengeSMSMFA_Success(t *testing.T) {
 	enrollMFA(t, svc, capture, user.ID)
 
 	// Challenge should send an OTP to the enrolled phone.
-	err := svc.ChallengeSMSMFA(ctx, user.ID)
+	err := svc.ChallengeSMSMFA(ctx, mfaChallenge(t, svc))
 	testutil.NoError(t, err)
 
 	testutil.SliceLen(t, capture.Calls, 1)
@@ -1925,20 +3158,21 @@ func TestVerifySMSMFA_Success(t *testing.T) {
 	enrollMFA(t, svc, capture, user.ID)
 
 	// Challenge to get OTP.
-	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, user.ID))
+	challenge := mfaChallenge(t, svc)
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, challenge))
 	code := capture.LastCode()
 
 	// Verify with correct code should issue full tokens.
-	returnedUser, accessToken, refreshToken, err := svc.VerifySMSMFA(ctx, user.ID, code)
+	returnedUser, accessToken, refreshToken, err := svc.VerifySMSMFA(ctx, challenge, code)
 	testutil.NoError(t, err)
 	testutil.Equal(t, user.ID, returnedUser.ID)
 	testutil.True(t, accessToken != "", "should return access token")
 	testutil.True(t, refreshToken != "", "should return refresh token")
 
-	// The access token should be a normal (non-MFA-pending) token.
+	// The access token should be a normal (non-challenge) token.
 	claims, err := svc.ValidateToken(accessToken)
 	testutil.NoError(t, err)
-	testutil.False(t, claims.MFAPending, "verified token should not have MFAPending")
+	testutil.Equal(t, "", claims.Purpose)
 	testutil.Equal(t, user.ID, claims.Subject)
 }
 
@@ -1948,14 +3182,54 @@ func TestVerifySMSMFA_WrongCode(t *testing.T) {
 	user := registerTestUser(t, svc)
 	enrollMFA(t, svc, capture, user.ID)
 
-	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, user.ID))
+	challenge := mfaChallenge(t, svc)
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, challenge))
 
-	_, _, _, err := svc.VerifySMSMFA(ctx, user.ID, "000000")
+	_, _, _, err := svc.VerifySMSMFA(ctx, challenge, "000000")
 	testutil.True(t, err != nil, "expected error for wrong code")
 	testutil.True(t, errors.Is(err, auth.ErrInvalidSMSCode),
 		"expected ErrInvalidSMSCode, got %v", err)
 }
 
+func TestVerifySMSMFA_ExhaustedAttemptsLockChallenge(t *testing.T) {
+	svc, capture := setupMFAService(t)
+	ctx := t.Context()
+	user := registerTestUser(t, svc)
+	enrollMFA(t, svc, capture, user.ID)
+
+	// Each code allows MaxAttempts (3) tries; asking for a new code doesn't
+	// reset the challenge token's own failure count (5).
+	challenge := mfaChallenge(t, svc)
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, challenge))
+	for i := 0; i < 3; i++ {
+		_, _, _, err := svc.VerifySMSMFA(ctx, challenge, "000000")
+		testutil.True(t, errors.Is(err, auth.ErrInvalidSMSCode), "attempt %d: got %v", i+1, err)
+	}
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, challenge))
+	code := capture.LastCode()
+	_, _, _, err := svc.VerifySMSMFA(ctx, challenge, "000000")
+	testutil.True(t, errors.Is(err, auth.ErrInvalidSMSCode), "got %v", err)
+	_, _, _, err = svc.VerifySMSMFA(ctx, challenge, "000000")
+	testutil.True(t, errors.Is(err, auth.ErrMFAChallengeLocked), "expected ErrMFAChallengeLocked, got %v", err)
+
+	// The challenge is dead: the code sent with it was discarded, and even a
+	// correct code or a new challenge is refused.
+	var pending int
+	testutil.NoError(t, svc.DB().QueryRow(ctx,
+		`SELECT COUNT(*) FROM _ayb_sms_codes WHERE phone = '+14155552671'`).Scan(&pending))
+	testutil.Equal(t, 0, pending)
+	_, _, _, err = svc.VerifySMSMFA(ctx, challenge, code)
+	testutil.True(t, errors.Is(err, auth.ErrMFAChallengeLocked), "expected ErrMFAChallengeLocked, got %v", err)
+	testutil.True(t, errors.Is(svc.ChallengeSMSMFA(ctx, challenge), auth.ErrMFAChallengeLocked),
+		"expected new challenge to be refused")
+
+	// Restarting login issues a fresh challenge that works.
+	fresh := mfaChallenge(t, svc)
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, fresh))
+	_, _, _, err = svc.VerifySMSMFA(ctx, fresh, capture.LastCode())
+	testutil.NoError(t, err)
+}
+
 func TestHasSMSMFA_NotEnrolled(t *testing.T) {
 	svc, _ := setupMFAService(t)
 	ctx := t.Context()
@@ -2002,16 +3276,16 @@ func TestLogin_WithMFA_ReturnsPendingToken(t *testing.T) {
 	enrollMFA(t, svc, capture, user.ID)
 
 	// Login should return a pending token, not a full token.
-	returnedUser, accessToken, refreshToken, err := svc.Login(ctx, "mfa-login@example.com", "password123")
+	returnedUser, accessToken, refreshToken, err := svc.Login(ctx, "mfa-login@example.com", "password123", false)
 	testutil.NoError(t, err)
 
 	// The returned user should still be present.
 	testutil.Equal(t, user.ID, returnedUser.ID)
 
-	// The access token should have MFAPending=true.
-	claims, err := svc.ValidateToken(accessToken)
+	// The access token should be an MFA challenge token.
+	claims, err := svc.ValidateMFAChallengeToken(accessToken)
 	testutil.NoError(t, err)
-	testutil.True(t, claims.MFAPending, "Login with MFA enrolled should return MFA pending token")
+	testutil.Equal(t, "mfa_challenge", claims.Purpose)
 
 	// No refresh token should be issued for MFA pending login.
 	testutil.True(t, refreshToken == "", "Login with MFA should not return refresh token")
@@ -2027,19 +3301,19 @@ func TestLogin_WithMFA_FullFlowEndToEnd(t *testing.T) {
 	enrollMFA(t, svc, capture, user.ID)
 
 	// Login -> get pending token.
-	_, pendingToken, _, err := svc.Login(ctx, "mfa-e2e@example.com", "password123")
+	_, pendingToken, _, err := svc.Login(ctx, "mfa-e2e@example.com", "password123", false)
 	testutil.NoError(t, err)
 
-	pendingClaims, err := svc.ValidateToken(pendingToken)
+	pendingClaims, err := svc.ValidateMFAChallengeToken(pendingToken)
 	testutil.NoError(t, err)
-	testutil.True(t, pendingClaims.MFAPending, "should be MFA pending")
+	testutil.Equal(t, "mfa_challenge", pendingClaims.Purpose)
 
 	// Challenge -> get OTP.
-	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, user.ID))
+	testutil.NoError(t, svc.ChallengeSMSMFA(ctx, pendingClaims))
 	code := capture.LastCode()
 
 	// Verify -> get full tokens.
-	verifiedUser, fullToken, fullRefresh, err := svc.VerifySMSMFA(ctx, user.ID, code)
+	verifiedUser, fullToken, fullRefresh, err := svc.VerifySMSMFA(ctx, pendingClaims, code)
 	testutil.NoError(t, err)
 	testutil.Equal(t, user.ID, verifiedUser.ID)
 	testutil.True(t, fullToken != "", "should return full access token")
@@ -2048,7 +3322,7 @@ func TestLogin_WithMFA_FullFlowEndToEnd(t *testing.T) {
 	// Full token should NOT be MFA pending.
 	fullClaims, err := svc.ValidateToken(fullToken)
 	testutil.NoError(t, err)
-	testutil.False(t, fullClaims.MFAPending, "full token should not be MFA pending")
+	testutil.Equal(t, "", fullClaims.Purpose)
 }
 
 func TestLogin_WithoutMFA_ReturnsNormalTokens(t *testing.T) {
@@ -2060,14 +3334,14 @@ func TestLogin_WithoutMFA_ReturnsNormalTokens(t *testing.T) {
 	testutil.NoError(t, err)
 
 	// Login should return normal tokens (no MFA pending).
-	_, accessToken, refreshToken, err := svc.Login(ctx, "no-mfa@example.com", "password123")
+	_, accessToken, refreshToken, err := svc.Login(ctx, "no-mfa@example.com", "password123", false)
 	testutil.NoError(t, err)
 	testutil.True(t, accessToken != "", "should return access token")
 	testutil.True(t, refreshToken != "", "should return refresh token")
 
 	claims, err := svc.ValidateToken(accessToken)
 	testutil.NoError(t, err)
-	testutil.False(t, claims.MFAPending, "non-MFA user should get normal token")
+	testutil.Equal(t, "", claims.Purpose)
 }
 
 // --- MFA gating on alternative login methods (Step 7 remaining) ---
@@ -2098,10 +3372,10 @@ func TestConfirmMagicLink_WithMFA_ReturnsPendingToken(t *testing.T) {
 	testutil.NoError(t, err)
 	testutil.Equal(t, user.ID, returnedUser.ID)
 
-	// Access token should have MFAPending=true.
-	claims, err := svc.ValidateToken(accessToken)
+	// Access token should be an MFA challenge token.
+	claims, err := svc.ValidateMFAChallengeToken(accessToken)
 	testutil.NoError(t, err)
-	testutil.True(t, claims.MFAPending, "ConfirmMagicLink with MFA enrolled should return MFA pending token")
+	testutil.Equal(t, "mfa_challenge", claims.Purpose)
 
 	// No refresh token should be issued.
 	testutil.True(t, refreshToken == "", "ConfirmMagicLink with MFA should not return refresh token")
@@ -2128,10 +3402,10 @@ func TestConfirmSMSCode_WithMFA_ReturnsPendingToken(t *testing.T) {
 	testutil.NoError(t, err)
 	testutil.Equal(t, user.ID, returnedUser.ID)
 
-	// Access token should have MFAPending=true.
-	claims, err := svc.ValidateToken(accessToken)
+	// Access token should be an MFA challenge token.
+	claims, err := svc.ValidateMFAChallengeToken(accessToken)
 	testutil.NoError(t, err)
-	testutil.True(t, claims.MFAPending, "ConfirmSMSCode with MFA enrolled should return MFA pending token")
+	testutil.Equal(t, "mfa_challenge", claims.Purpose)
 
 	// No refresh token should be issued.
 	testutil.True(t, refreshToken == "", "ConfirmSMSCode with MFA should not return refresh token")
@@ -2384,6 +3658,31 @@ func TestHandleLogin_WithMFA_ReturnsMFAResponse(t *testing.T) {
 	testutil.False(t, hasRefresh, "MFA response should not include refreshToken")
 }
 
+func TestMFAToken_RejectedOutsideMFAEndpoints(t *testing.T) {
+	srv, _, capture := setupMFAServer(t)
+	token, _ := registerForMFA(t, srv, "mfa-scope@example.com")
+	enrollMFAViaHTTP(t, srv, capture, token)
+
+	w := doJSON(t, srv, "POST", "/api/auth/login", map[string]string{
+		"email": "mfa-scope@example.com", "password": "password123",
+	}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	mfaToken := resp["mfa_token"].(string)
+
+	w = doJSON(t, srv, "GET", "/api/auth/me", nil, mfaToken)
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+	testutil.Contains(t, w.Body.String(), "MFA verification required")
+
+	w = doJSON(t, srv, "POST", "/api/auth/api-keys", map[string]string{"name": "k"}, mfaToken)
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+
+	// It is still good for the challenge.
+	w = doJSON(t, srv, "POST", "/api/auth/mfa/sms/challenge", nil, mfaToken)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+}
+
 func TestHandleLogin_WithoutMFA_ReturnsNormalResponse(t *testing.T) {
 	srv, _, _ := setupMFAServer(t)
 	doJSON(t, srv, "POST", "/api/auth/register", map[string]string{
@@ -2488,6 +3787,7 @@ func setupSMSHealthServer(t *testing.T) (*server.Server, *sms.CaptureProvider) {
 	cfg.Auth.Enabled = true
 	cfg.Auth.JWTSecret = testJWTSecret
 	cfg.Auth.SMSEnabled = true
+	cfg.Auth.SMSPrices = map[string]float64{"US": 0.01}
 	cfg.Admin.Password = testAdminPassword
 
 	authSvc := newAuthService()
@@ -2499,12 +3799,26 @@ func setupSMSHealthServer(t *testing.T) (*server.Server, *sms.CaptureProvider) {
 		MaxAttempts:      3,
 		DailyLimit:       0,
 		AllowedCountries: []string{"US", "CA"},
+		StatusSecret:     testSMSStatusSecret,
 	})
 
 	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
 	return srv, capture
 }
 
+const testSMSStatusSecret = "sms-status-test-secret"
+
+// postSMSStatus sends a delivery receipt signed with secret.
+func postSMSStatus(t *testing.T, srv *server.Server, secret, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/auth/sms/status", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sms.SignatureHeader, sms.Sign(secret, []byte(body)))
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	return w
+}
+
 func adminLoginForSMS(t *testing.T, srv *server.Server) string {
 	t.Helper()
 	w := doJSON(t, srv, "POST", "/api/admin/auth", map[string]string{
@@ -2589,6 +3903,94 @@ func TestAdminSMSHealth_NoData(t *testing.T) {
 	testutil.Equal(t, float64(0), today["conversion_rate"].(float64))
 }
 
+func TestSMSDeliveryReceipts_ReportedInHealth(t *testing.T) {
+	srv, _ := setupSMSHealthServer(t)
+	token := adminLoginForSMS(t, srv)
+
+	// More receipts than the auth rate limit (10/min) allows: the status
+	// endpoint must not be throttled per IP.
+	for i := 0; i < 9; i++ {
+		w := postSMSStatus(t, srv, testSMSStatusSecret, fmt.Sprintf(`{"message_id":"m%d","status":"delivered"}`, i))
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+	}
+	for _, status := range []string{"failed", "undelivered", "UNDELIVERED", "sent"} {
+		w := postSMSStatus(t, srv, testSMSStatusSecret, `{"message_id":"x","status":"`+status+`"}`)
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+	}
+
+	// Wrongly signed receipts are rejected and not counted.
+	w := postSMSStatus(t, srv, "wrong-secret", `{"message_id":"y","status":"failed"}`)
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+
+	w = doJSON(t, srv, "GET", "/api/admin/sms/health", nil, token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var resp map[string]any
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	today := resp["today"].(map[string]any)
+	testutil.Equal(t, float64(9), today["delivered"].(float64))
+	testutil.Equal(t, float64(3), today["undelivered"].(float64))
+	testutil.Equal(t, float64(25), today["delivery_failure_rate"].(float64))
+	testutil.Equal(t, "high delivery failure rate", resp["delivery_warning"])
+
+	month := resp["last_30d"].(map[string]any)
+	testutil.Equal(t, float64(9), month["delivered"].(float64))
+}
+
+func TestAdminSMSHealth_CountryBreakdownAndCost(t *testing.T) {
+	srv, capture := setupSMSHealthServer(t)
+	token := adminLoginForSMS(t, srv)
+
+	// US: sent and confirmed. CA: sent, wrong code entered.
+	w := doJSON(t, srv, "POST", "/api/auth/sms", map[string]string{"phone": "+14155552671"}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	w = doJSON(t, srv, "POST", "/api/auth/sms/confirm", map[string]string{
+		"phone": "+14155552671", "code": capture.LastCode(),
+	}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	w = doJSON(t, srv, "POST", "/api/auth/sms", map[string]string{"phone": "+16135550123"}, "")
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	w = doJSON(t, srv, "POST", "/api/auth/sms/confirm", map[string]string{
+		"phone": "+16135550123", "code": "000000",
+	}, "")
+	testutil.StatusCode(t, http.StatusUnauthorized, w.Code)
+
+	w = doJSON(t, srv, "GET", "/api/admin/sms/health", nil, token)
+	testutil.StatusCode(t, http.StatusOK, w.Code)
+	var resp struct {
+		Today struct {
+			Sent          int      `json:"sent"`
+			EstimatedCost *float64 `json:"estimated_cost"`
+		} `json:"today"`
+		Countries []struct {
+			Country       string   `json:"country"`
+			Sent          int      `json:"sent"`
+			Confirmed     int      `json:"confirmed"`
+			Failed        int      `json:"failed"`
+			EstimatedCost *float64 `json:"estimated_cost"`
+		} `json:"countries"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	testutil.Equal(t, 2, resp.Today.Sent)
+	testutil.NotNil(t, resp.Today.EstimatedCost)
+	testutil.Equal(t, 0.01, *resp.Today.EstimatedCost) // only US is priced
+
+	testutil.SliceLen(t, resp.Countries, 2)
+	byCountry := map[string]int{}
+	for i, c := range resp.Countries {
+		byCountry[c.Country] = i
+	}
+	us := resp.Countries[byCountry["US"]]
+	testutil.Equal(t, 1, us.Sent)
+	testutil.Equal(t, 1, us.Confirmed)
+	testutil.NotNil(t, us.EstimatedCost)
+	ca := resp.Countries[byCountry["CA"]]
+	testutil.Equal(t, 1, ca.Sent)
+	testutil.Equal(t, 1, ca.Failed)
+	testutil.Nil(t, ca.EstimatedCost)
+}
+
 func TestAdminSMSHealth_RequiresAdminAuth(t *testing.T) {
 	srv, _ := setupSMSHealthServer(t)
 
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"encoding/hex"
+	"errors"
 	"strings"
 	"sync"
 	"testing"
@@ -19,11 +20,12 @@ func init() {
 	// Production params (64 MiB, 3 iterations) take ~250ms per hash.
 	argonMemory = 1024 // 1 MiB
 	argonTime = 1
+	bcryptCost = bcrypt.MinCost
 }
 
 func TestHashAndVerifyPassword(t *testing.T) {
 	t.Parallel()
-	hash, err := hashPassword("mypassword123")
+	hash, err := hashPassword("mypassword123", "")
 	testutil.NoError(t, err)
 	testutil.True(t, len(hash) > 0, "hash should not be empty")
 	testutil.Contains(t, hash, "$argon2id$")
@@ -35,7 +37,7 @@ func TestHashAndVerifyPassword(t *testing.T) {
 
 func TestVerifyPasswordWrong(t *testing.T) {
 	t.Parallel()
-	hash, err := hashPassword("mypassword123")
+	hash, err := hashPassword("mypassword123", "")
 	testutil.NoError(t, err)
 
 	ok, err := verifyPassword(hash, "wrongpassword")
@@ -83,6 +85,58 @@ func TestVerifyBcrypt2bPrefix(t *testing.T) {
 	testutil.True(t, ok, "$2b$ prefix should verify")
 }
 
+func TestHashPasswordBcrypt(t *testing.T) {
+	t.Parallel()
+	hash, err := hashPassword("mypassword123", PasswordHashBcrypt)
+	testutil.NoError(t, err)
+	testutil.Equal(t, PasswordHashBcrypt, hashAlgorithm(hash))
+
+	ok, err := verifyPassword(hash, "mypassword123")
+	testutil.NoError(t, err)
+	testutil.True(t, ok, "correct password should verify")
+}
+
+func TestValidatePasswordBcryptLimit(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", bcryptMaxPassword+1)
+	testutil.NoError(t, validatePassword(long, 8, PasswordHashArgon2id))
+	testutil.NoError(t, validatePassword(long[:bcryptMaxPassword], 8, PasswordHashBcrypt))
+	testutil.ErrorContains(t, validatePassword(long, 8, PasswordHashBcrypt), "at most 72 bytes")
+}
+
+func TestNeedsRehash(t *testing.T) {
+	t.Parallel()
+	argonHash, err := hashPassword("mypassword123", PasswordHashArgon2id)
+	testutil.NoError(t, err)
+	bcryptHash, err := hashPassword("mypassword123", PasswordHashBcrypt)
+	testutil.NoError(t, err)
+	const scryptHash = "$firebase-scrypt$key$sep$salt$8$14$hash"
+	long := strings.Repeat("x", bcryptMaxPassword+1)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		hash      string
+		password  string
+		want      bool
+	}{
+		{"argon2id default", "", argonHash, "mypassword123", false},
+		{"bcrypt to default", "", bcryptHash, "mypassword123", true},
+		{"bcrypt to argon2id", PasswordHashArgon2id, bcryptHash, "mypassword123", true},
+		{"argon2id to bcrypt", PasswordHashBcrypt, argonHash, "mypassword123", true},
+		{"bcrypt kept", PasswordHashBcrypt, bcryptHash, "mypassword123", false},
+		{"firebase-scrypt always", PasswordHashArgon2id, scryptHash, "mypassword123", true},
+		{"too long for bcrypt", PasswordHashBcrypt, argonHash, long, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &Service{pwHash: tt.algorithm}
+			testutil.Equal(t, tt.want, svc.needsRehash(tt.hash, tt.password))
+		})
+	}
+}
+
 func TestIsBcryptHash(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -186,6 +240,134 @@ func TestValidateTokenWrongSigningMethod(t *testing.T) {
 	testutil.ErrorContains(t, err, "unexpected signing method")
 }
 
+func TestTokenIssuerAndAudience(t *testing.T) {
+	t.Parallel()
+	svc := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	svc.SetJWTClaims("https://api.example.com", "my-app")
+
+	token, err := svc.generateToken(&User{ID: "test-id", Email: "test@example.com"})
+	testutil.NoError(t, err)
+
+	claims, err := svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "https://api.example.com", claims.Issuer)
+	testutil.SliceLen(t, claims.Audience, 1)
+	testutil.Equal(t, "my-app", claims.Audience[0])
+}
+
+func TestValidateTokenWrongAudience(t *testing.T) {
+	t.Parallel()
+	issuer := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	issuer.SetJWTClaims("https://api.example.com", "other-app")
+	token, err := issuer.generateToken(&User{ID: "test-id", Email: "test@example.com"})
+	testutil.NoError(t, err)
+
+	verifier := &Service{jwtSecret: []byte(testSecret)}
+	verifier.SetJWTClaims("https://api.example.com", "my-app")
+	_, err = verifier.ValidateToken(token)
+	testutil.ErrorContains(t, err, "token has invalid audience")
+}
+
+func TestValidateTokenWrongIssuer(t *testing.T) {
+	t.Parallel()
+	issuer := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	issuer.SetJWTClaims("https://evil.example.com", "")
+	token, err := issuer.generateToken(&User{ID: "test-id", Email: "test@example.com"})
+	testutil.NoError(t, err)
+
+	verifier := &Service{jwtSecret: []byte(testSecret)}
+	verifier.SetJWTClaims("https://api.example.com", "")
+	_, err = verifier.ValidateToken(token)
+	testutil.ErrorContains(t, err, "token has invalid issuer")
+}
+
+func TestValidateTokenMissingAudienceRejected(t *testing.T) {
+	// Tokens minted before an audience was configured carry no aud and must
+	// not be accepted once one is required.
+	t.Parallel()
+	legacy := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	token, err := legacy.generateToken(&User{ID: "test-id", Email: "test@example.com"})
+	testutil.NoError(t, err)
+
+	verifier := &Service{jwtSecret: []byte(testSecret)}
+	verifier.SetJWTClaims("", "my-app")
+	_, err = verifier.ValidateToken(token)
+	testutil.ErrorContains(t, err, "invalid token")
+}
+
+func TestMFAChallengeTokenIssuerAndAudience(t *testing.T) {
+	t.Parallel()
+	svc := &Service{jwtSecret: []byte(testSecret)}
+	svc.SetJWTClaims("https://api.example.com", "my-app")
+
+	token, err := svc.generateMFAChallengeToken(&User{ID: "test-id", Email: "test@example.com"}, false)
+	testutil.NoError(t, err)
+	claims, err := svc.ValidateMFAChallengeToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "https://api.example.com", claims.Issuer)
+	// The challenge token has an audience of its own, never the app's.
+	testutil.SliceLen(t, claims.Audience, 1)
+	testutil.Equal(t, mfaChallengeAudience, claims.Audience[0])
+
+	_, err = svc.ValidateToken(token)
+	testutil.True(t, errors.Is(err, ErrMFAChallengeToken), "challenge token accepted as access token")
+}
+
+func TestValidateTokenRLSClaims(t *testing.T) {
+	t.Parallel()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":       "test-id",
+		"email":     "test@example.com",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "acme",
+		"org_id":    42,
+		"roles":     []string{"admin", "billing"},
+		"ignored":   "not configured",
+	}).SignedString([]byte(testSecret))
+	testutil.NoError(t, err)
+
+	svc := &Service{jwtSecret: []byte(testSecret)}
+	claims, err := svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, len(claims.rlsSettings))
+
+	svc.SetRLSClaims([]string{"tenant_id", "org_id", "roles", "region"})
+	claims, err = svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 4, len(claims.rlsSettings))
+	testutil.Equal(t, "acme", claims.rlsSettings["tenant_id"])
+	testutil.Equal(t, "42", claims.rlsSettings["org_id"])
+	testutil.Equal(t, `["admin","billing"]`, claims.rlsSettings["roles"])
+	testutil.Equal(t, "", claims.rlsSettings["region"]) // missing claim
+}
+
+func TestValidateTokenTenantClaim(t *testing.T) {
+	t.Parallel()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":    "test-id",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"org_id": "acme",
+	}).SignedString([]byte(testSecret))
+	testutil.NoError(t, err)
+
+	svc := &Service{jwtSecret: []byte(testSecret)}
+	claims, err := svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "", claims.Tenant())
+
+	svc.SetTenantClaim("org_id")
+	claims, err = svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "acme", claims.Tenant())
+	// The tenant claim isn't an RLS setting unless configured as one.
+	testutil.Equal(t, 0, len(claims.rlsSettings))
+
+	svc.SetTenantClaim("tenant_id")
+	claims, err = svc.ValidateToken(token)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "", claims.Tenant())
+}
+
 func TestValidateTokenWrongSecret(t *testing.T) {
 	t.Parallel()
 	svc1 := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
@@ -199,33 +381,6 @@ func TestValidateTokenWrongSecret(t *testing.T) {
 	testutil.ErrorContains(t, err, "invalid token")
 }
 
-func TestValidateEmail(t *testing.T) {
-	t.Parallel()
-	tests := []struct {
-		name    string
-		email   string
-		wantErr string
-	}{
-		{"valid", "user@example.com", ""},
-		{"valid subdomain", "user@mail.example.com", ""},
-		{"empty", "", "email is required"},
-		{"no at", "userexample.com", "invalid email format"},
-		{"no domain dot", "user@example", "invalid email format"},
-		{"at at start", "@example.com", "invalid email format"},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			err := validateEmail(tt.email)
-			if tt.wantErr == "" {
-				testutil.NoError(t, err)
-			} else {
-				testutil.ErrorContains(t, err, tt.wantErr)
-			}
-		})
-	}
-}
-
 func TestValidatePassword(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -248,7 +403,7 @@ func TestValidatePassword(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			err := validatePassword(tt.password, tt.minLen)
+			err := validatePassword(tt.password, tt.minLen, "")
 			if tt.wantErr == "" {
 				testutil.NoError(t, err)
 			} else {
@@ -260,9 +415,9 @@ func TestValidatePassword(t *testing.T) {
 
 func TestPasswordHashUniqueSalt(t *testing.T) {
 	t.Parallel()
-	h1, err := hashPassword("same-password")
+	h1, err := hashPassword("same-password", "")
 	testutil.NoError(t, err)
-	h2, err := hashPassword("same-password")
+	h2, err := hashPassword("same-password", "")
 	testutil.NoError(t, err)
 	testutil.NotEqual(t, h1, h2)
 }
@@ -304,7 +459,7 @@ func TestRotateJWTSecretChangesSecret(t *testing.T) {
 	testutil.NoError(t, err)
 
 	// Rotate secret.
-	newSecret, err := svc.RotateJWTSecret()
+	newSecret, err := svc.RotateJWTSecret(0)
 	testutil.NoError(t, err)
 	testutil.Equal(t, 64, len(newSecret))
 
@@ -324,13 +479,65 @@ func TestRotateJWTSecretChangesSecret(t *testing.T) {
 	testutil.Equal(t, "test-id", claims.Subject)
 }
 
+func TestRotateJWTSecretOverlapWindow(t *testing.T) {
+	t.Parallel()
+	svc := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	user := &User{ID: "test-id", Email: "test@example.com"}
+	oldToken, err := svc.generateToken(user)
+	testutil.NoError(t, err)
+
+	_, err = svc.RotateJWTSecret(time.Minute)
+	testutil.NoError(t, err)
+
+	// Within the window the old token still validates.
+	claims, err := svc.ValidateToken(oldToken)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "test-id", claims.Subject)
+
+	// New tokens are signed with the new secret only.
+	newToken, err := svc.generateToken(user)
+	testutil.NoError(t, err)
+	_, err = jwt.Parse(newToken, func(*jwt.Token) (any, error) { return []byte(testSecret), nil })
+	testutil.NotNil(t, err)
+
+	// After the window the old token is rejected.
+	svc.jwtSecretMu.Lock()
+	svc.prevUntil = time.Now().Add(-time.Second)
+	svc.jwtSecretMu.Unlock()
+	_, err = svc.ValidateToken(oldToken)
+	testutil.ErrorContains(t, err, "invalid token")
+
+	_, err = svc.ValidateToken(newToken)
+	testutil.NoError(t, err)
+}
+
+func TestRotateJWTSecretKeepsOnlyOnePreviousSecret(t *testing.T) {
+	t.Parallel()
+	svc := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
+	user := &User{ID: "test-id", Email: "test@example.com"}
+	oldest, err := svc.generateToken(user)
+	testutil.NoError(t, err)
+
+	_, err = svc.RotateJWTSecret(time.Minute)
+	testutil.NoError(t, err)
+	previous, err := svc.generateToken(user)
+	testutil.NoError(t, err)
+	_, err = svc.RotateJWTSecret(time.Minute)
+	testutil.NoError(t, err)
+
+	_, err = svc.ValidateToken(previous)
+	testutil.NoError(t, err)
+	_, err = svc.ValidateToken(oldest)
+	testutil.ErrorContains(t, err, "invalid token")
+}
+
 func TestRotateJWTSecretProducesDifferentSecrets(t *testing.T) {
 	t.Parallel()
 	svc := &Service{jwtSecret: []byte(testSecret), tokenDur: time.Hour}
 
-	s1, err := svc.RotateJWTSecret()
+	s1, err := svc.RotateJWTSecret(0)
 	testutil.NoError(t, err)
-	s2, err := svc.RotateJWTSecret()
+	s2, err := svc.RotateJWTSecret(0)
 	testutil.NoError(t, err)
 	testutil.NotEqual(t, s1, s2)
 }
@@ -356,7 +563,7 @@ func TestRotateJWTSecretConcurrentSafe(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			for j := 0; j < 10; j++ {
-				_, _ = svc.RotateJWTSecret()
+				_, _ = svc.RotateJWTSecret(0)
 			}
 		}()
 	}
@@ -460,6 +667,17 @@ func TestValidateTokenBoundaryConditions(t *testing.T) {
 }
 
 // TestHashTokenDeterministic verifies that hashToken produces deterministic output.
+func TestSessionDuration(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	testutil.Equal(t, 7*24*time.Hour, svc.sessionDuration(false))
+	testutil.Equal(t, 7*24*time.Hour, svc.sessionDuration(true)) // unset falls back
+
+	svc.SetRememberMeDuration(30 * 24 * time.Hour)
+	testutil.Equal(t, 7*24*time.Hour, svc.sessionDuration(false))
+	testutil.Equal(t, 30*24*time.Hour, svc.sessionDuration(true))
+}
+
 func TestHashTokenDeterministic(t *testing.T) {
 	t.Parallel()
 	h1 := hashToken("test-token-value")
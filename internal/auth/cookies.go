@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// Cookie names and the CSRF header used in cookie mode.
+const (
+	RefreshTokenCookie = "ayb_refresh_token"
+	AccessTokenCookie  = "ayb_access_token"
+	CSRFCookie         = "ayb_csrf_token"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// refreshCookiePath limits the refresh token cookie to the auth endpoints,
+// the only ones that read it.
+const refreshCookiePath = "/api/auth"
+
+// ErrInvalidCSRFToken is returned when a request authenticated by cookie is
+// state-changing and doesn't carry the matching CSRF token.
+var ErrInvalidCSRFToken = errors.New("missing or invalid CSRF token")
+
+// CookieConfig enables cookie mode: the refresh token (and optionally the
+// access token) is set as an httpOnly cookie instead of returned in the JSON
+// body, so browser apps never hold it in script-readable storage.
+type CookieConfig struct {
+	AccessToken bool   // also deliver the access token as a cookie
+	SameSite    string // "lax", "strict" or "none"
+	Secure      bool
+}
+
+func (c *CookieConfig) sameSite() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// SetCookieConfig enables cookie mode. Nil disables it. The body-based flow
+// keeps working either way: refresh and logout still accept a refresh token
+// in the body, and the middleware still accepts Authorization headers.
+func (s *Service) SetCookieConfig(cfg *CookieConfig) {
+	s.cookies = cfg
+}
+
+// setTokenCookies sets the session cookies for a freshly issued token pair
+// and returns the CSRF token the client must echo in CSRFHeader.
+func (s *Service) setTokenCookies(w http.ResponseWriter, accessToken, refreshToken string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generating CSRF token: %w", err)
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	// The cookie may outlive a non-remember-me session; the server still
+	// rejects the expired token.
+	maxAge := int(max(s.sessionDuration(true), s.sessionDuration(false)).Seconds())
+	s.setCookie(w, RefreshTokenCookie, refreshToken, refreshCookiePath, maxAge, true)
+	s.setCookie(w, CSRFCookie, csrfToken, "/", maxAge, false)
+	if s.cookies.AccessToken {
+		s.setCookie(w, AccessTokenCookie, accessToken, "/", int(s.tokenDur.Seconds()), true)
+	}
+	return csrfToken, nil
+}
+
+// clearTokenCookies expires the session cookies.
+func (s *Service) clearTokenCookies(w http.ResponseWriter) {
+	s.setCookie(w, RefreshTokenCookie, "", refreshCookiePath, -1, true)
+	s.setCookie(w, CSRFCookie, "", "/", -1, false)
+	if s.cookies.AccessToken {
+		s.setCookie(w, AccessTokenCookie, "", "/", -1, true)
+	}
+}
+
+func (s *Service) setCookie(w http.ResponseWriter, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: httpOnly,
+		Secure:   s.cookies.Secure,
+		SameSite: s.cookies.sameSite(),
+	})
+}
+
+// cookieValue returns the named cookie's value, if cookie mode is enabled and
+// the request carries it.
+func (s *Service) cookieValue(r *http.Request, name string) (string, bool) {
+	if s.cookies == nil {
+		return "", false
+	}
+	c, err := r.Cookie(name)
+	if err != nil || c.Value == "" {
+		return "", false
+	}
+	return c.Value, true
+}
+
+// RequireCSRF returns middleware enforcing the double-submit CSRF check in
+// cookie mode. A state-changing request authenticated by a session cookie
+// must echo the CSRF cookie in CSRFHeader, or it is rejected with 403.
+// Requests with an Authorization credential are exempt: a browser never
+// attaches one to a cross-site request on its own.
+func RequireCSRF(svc *Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if svc.usesSessionCookie(r) {
+				if err := checkCSRF(r); err != nil {
+					httputil.WriteError(w, http.StatusForbidden, err.Error())
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// usesSessionCookie reports whether r would be authenticated by a session
+// cookie: it has no Authorization credential, and carries the access token
+// cookie or the refresh token cookie that refresh and logout read.
+func (s *Service) usesSessionCookie(r *http.Request) bool {
+	if s.cookies == nil {
+		return false
+	}
+	if _, ok := extractCredential(r); ok {
+		return false
+	}
+	if _, ok := s.cookieValue(r, RefreshTokenCookie); ok {
+		return true
+	}
+	_, ok := s.cookieValue(r, AccessTokenCookie)
+	return ok && s.cookies.AccessToken
+}
+
+// checkCSRF verifies the double-submit CSRF token of a request authenticated
+// by cookie: safe methods pass, others must echo the CSRF cookie in
+// CSRFHeader.
+func checkCSRF(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	c, err := r.Cookie(CSRFCookie)
+	header := r.Header.Get(CSRFHeader)
+	if err != nil || c.Value == "" || header == "" ||
+		subtle.ConstantTimeCompare([]byte(c.Value), []byte(header)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func newCookieTestService(accessToken bool) *Service {
+	svc := newTestService()
+	svc.SetCookieConfig(&CookieConfig{AccessToken: accessToken, SameSite: "strict", Secure: true})
+	return svc
+}
+
+func responseCookies(w *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	return cookies
+}
+
+func TestWriteAuthResponseBodyMode(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(newTestService(), testutil.DiscardLogger())
+	w := httptest.NewRecorder()
+	h.writeAuthResponse(w, http.StatusOK, &User{ID: "u1"}, "access", "refresh")
+
+	testutil.SliceLen(t, w.Result().Cookies(), 0)
+	var resp authResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, "access", resp.Token)
+	testutil.Equal(t, "refresh", resp.RefreshToken)
+	testutil.Equal(t, "", resp.CSRFToken)
+}
+
+func TestWriteAuthResponseCookieMode(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(false)
+	svc.SetRememberMeDuration(30 * 24 * time.Hour)
+	h := NewHandler(svc, testutil.DiscardLogger())
+	w := httptest.NewRecorder()
+	h.writeAuthResponse(w, http.StatusOK, &User{ID: "u1"}, "access", "refresh")
+
+	var resp authResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, "access", resp.Token)
+	testutil.Equal(t, "", resp.RefreshToken)
+	testutil.True(t, resp.CSRFToken != "", "expected a CSRF token in the body")
+
+	cookies := responseCookies(w)
+	refresh := cookies[RefreshTokenCookie]
+	testutil.NotNil(t, refresh)
+	testutil.Equal(t, "refresh", refresh.Value)
+	testutil.Equal(t, "/api/auth", refresh.Path)
+	testutil.True(t, refresh.HttpOnly, "refresh cookie must be httpOnly")
+	testutil.True(t, refresh.Secure, "refresh cookie must be Secure")
+	testutil.Equal(t, http.SameSiteStrictMode, refresh.SameSite)
+	testutil.Equal(t, int((30 * 24 * time.Hour).Seconds()), refresh.MaxAge)
+
+	csrf := cookies[CSRFCookie]
+	testutil.NotNil(t, csrf)
+	testutil.Equal(t, resp.CSRFToken, csrf.Value)
+	testutil.False(t, csrf.HttpOnly, "CSRF cookie must be readable by scripts")
+
+	_, ok := cookies[AccessTokenCookie]
+	testutil.False(t, ok, "access token cookie set without access_token")
+}
+
+func TestWriteAuthResponseAccessTokenCookie(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(true)
+	h := NewHandler(svc, testutil.DiscardLogger())
+	w := httptest.NewRecorder()
+	h.writeAuthResponse(w, http.StatusOK, &User{ID: "u1"}, "access", "refresh")
+
+	var resp authResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, "", resp.Token)
+	testutil.False(t, strings.Contains(w.Body.String(), `"token"`), "access token leaked into body: %s", w.Body.String())
+
+	access := responseCookies(w)[AccessTokenCookie]
+	testutil.NotNil(t, access)
+	testutil.Equal(t, "access", access.Value)
+	testutil.Equal(t, "/", access.Path)
+	testutil.True(t, access.HttpOnly, "access cookie must be httpOnly")
+	testutil.Equal(t, int(svc.tokenDur.Seconds()), access.MaxAge)
+}
+
+func TestWriteTokenResponseBodyInCookieMode(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(newCookieTestService(true), testutil.DiscardLogger())
+	w := httptest.NewRecorder()
+	h.writeTokenResponse(w, http.StatusOK, &User{ID: "u1"}, "access", "refresh", false)
+
+	testutil.SliceLen(t, w.Result().Cookies(), 0)
+	var resp authResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, "access", resp.Token)
+	testutil.Equal(t, "refresh", resp.RefreshToken)
+	testutil.Equal(t, "", resp.CSRFToken)
+}
+
+func TestClearTokenCookies(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(true)
+	w := httptest.NewRecorder()
+	svc.clearTokenCookies(w)
+
+	cookies := responseCookies(w)
+	for _, name := range []string{RefreshTokenCookie, AccessTokenCookie, CSRFCookie} {
+		c := cookies[name]
+		testutil.NotNil(t, c)
+		testutil.Equal(t, "", c.Value)
+		testutil.True(t, c.MaxAge < 0, "%s should be expired, MaxAge=%d", name, c.MaxAge)
+	}
+}
+
+func TestCheckCSRF(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		wantOK bool
+	}{
+		{"safe method needs no token", http.MethodGet, "", "", true},
+		{"matching token", http.MethodPost, "abc", "abc", true},
+		{"missing header", http.MethodPost, "abc", "", false},
+		{"missing cookie", http.MethodPost, "", "abc", false},
+		{"mismatch", http.MethodDelete, "abc", "abd", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+			err := checkCSRF(req)
+			testutil.Equal(t, tt.wantOK, err == nil)
+		})
+	}
+}
+
+func TestHandleRefreshCookieRequiresCSRF(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(false)
+	router := RequireCSRF(svc)(NewHandler(svc, testutil.DiscardLogger()).Routes())
+
+	for _, path := range []string{"/refresh", "/logout"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.AddCookie(&http.Cookie{Name: RefreshTokenCookie, Value: "refresh"})
+		req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: "csrf"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusForbidden, w.Code)
+		testutil.Contains(t, w.Body.String(), "CSRF")
+	}
+}
+
+func TestHandleRefreshIgnoresCookieOutsideCookieMode(t *testing.T) {
+	t.Parallel()
+	router := NewHandler(newTestService(), testutil.DiscardLogger()).Routes()
+
+	req := httptest.NewRequest(http.MethodPost, "/refresh", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: RefreshTokenCookie, Value: "refresh"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "refreshToken is required")
+}
+
+func TestRequireAuthAccessTokenCookie(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(true)
+	token := generateTestToken(t, svc, "user-1", "test@example.com")
+	var gotClaims *Claims
+	handler := RequireAuth(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: token})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.NotNil(t, gotClaims)
+	testutil.Equal(t, "user-1", gotClaims.Subject)
+}
+
+func TestRequireCSRF(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(true)
+	handler := RequireCSRF(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		cookie string // session cookie sent, if any
+		bearer bool
+		csrf   string // X-CSRF-Token header
+		want   int
+	}{
+		{"cross-site POST with access cookie", http.MethodPost, AccessTokenCookie, false, "", http.StatusForbidden},
+		{"cross-site POST with refresh cookie", http.MethodPost, RefreshTokenCookie, false, "", http.StatusForbidden},
+		{"mismatched token", http.MethodPatch, AccessTokenCookie, false, "wrong", http.StatusForbidden},
+		{"matching token", http.MethodDelete, AccessTokenCookie, false, "csrf", http.StatusOK},
+		{"safe method", http.MethodGet, AccessTokenCookie, false, "", http.StatusOK},
+		{"bearer request is exempt", http.MethodPost, AccessTokenCookie, true, "", http.StatusOK},
+		{"no session cookie", http.MethodPost, "", false, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header.Set("Origin", "https://evil.example")
+			req.AddCookie(&http.Cookie{Name: CSRFCookie, Value: "csrf"})
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: tt.cookie, Value: "session"})
+			}
+			if tt.bearer {
+				req.Header.Set("Authorization", "Bearer tok")
+			}
+			if tt.csrf != "" {
+				req.Header.Set(CSRFHeader, tt.csrf)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			testutil.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestRequireCSRFOutsideCookieMode(t *testing.T) {
+	t.Parallel()
+	handler := RequireCSRF(newTestService())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: "session"})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireAuthIgnoresAccessCookieWhenDisabled(t *testing.T) {
+	t.Parallel()
+	svc := newCookieTestService(false)
+	token := generateTestToken(t, svc, "user-1", "test@example.com")
+	handler := RequireAuth(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: token})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed disposable_domains.txt
+var disposableDomainList string
+
+// defaultDisposableDomains returns the shipped list of disposable email
+// domains.
+func defaultDisposableDomains() []string {
+	var domains []string
+	for _, line := range strings.Split(disposableDomainList, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			domains = append(domains, line)
+		}
+	}
+	return domains
+}
+
+// SetDisposableEmailBlocking rejects registrations, and drops magic link
+// requests, for addresses at disposable email domains: the shipped list plus
+// extra, minus exceptions. Subdomains of a blocked domain are blocked too.
+// Disabled blocking ignores both lists.
+func (s *Service) SetDisposableEmailBlocking(enabled bool, extra, exceptions []string) {
+	if !enabled {
+		s.blockedDomains = nil
+		return
+	}
+	s.blockedDomains = make(map[string]bool)
+	for _, d := range append(defaultDisposableDomains(), extra...) {
+		s.blockedDomains[strings.ToLower(d)] = true
+	}
+	for _, d := range exceptions {
+		delete(s.blockedDomains, strings.ToLower(d))
+	}
+}
+
+// isDisposableEmail reports whether email, lowercased, is at a blocked
+// disposable domain or a subdomain of one.
+func (s *Service) isDisposableEmail(email string) bool {
+	if len(s.blockedDomains) == 0 {
+		return false
+	}
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	for domain := email[at+1:]; domain != ""; {
+		if s.blockedDomains[domain] {
+			return true
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/mailer"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestDefaultDisposableDomains(t *testing.T) {
+	t.Parallel()
+	domains := defaultDisposableDomains()
+	testutil.True(t, len(domains) > 0, "shipped list should not be empty")
+	seen := map[string]bool{}
+	for _, d := range domains {
+		testutil.Equal(t, strings.ToLower(d), d)
+		testutil.False(t, strings.ContainsAny(d, "# @"), "bad entry %q", d)
+		testutil.False(t, seen[d], "duplicate entry %q", d)
+		seen[d] = true
+	}
+}
+
+func TestIsDisposableEmail(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	svc.SetDisposableEmailBlocking(true, []string{"Spam.Example"}, []string{"maildrop.cc"})
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"user@mailinator.com", true},
+		{"user@yopmail.com", true},
+		{"user@inbox.mailinator.com", true}, // subdomain of a listed domain
+		{"user@spam.example", true},         // added by config
+		{"user@example.com", false},
+		{"user@gmail.com", false},
+		{"user@notmailinator.com", false},
+		{"user@maildrop.cc", false}, // exempted by config
+		{"not-an-email", false},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, svc.isDisposableEmail(tt.email))
+	}
+
+	// Blocking is off until enabled, and turning it off drops both lists.
+	off := newTestService()
+	testutil.False(t, off.isDisposableEmail("user@mailinator.com"), "blocking should be off by default")
+	svc.SetDisposableEmailBlocking(false, []string{"spam.example"}, nil)
+	testutil.False(t, svc.isDisposableEmail("user@spam.example"), "disabled blocking should ignore extra domains")
+}
+
+func TestHandleRegisterDisposableEmail(t *testing.T) {
+	t.Parallel()
+	for _, hide := range []bool{false, true} {
+		svc := newTestService()
+		svc.SetDisposableEmailBlocking(true, nil, nil)
+		h := NewHandler(svc, testutil.DiscardLogger())
+		h.SetHideRegistrationConflicts(hide)
+
+		req := httptest.NewRequest(http.MethodPost, "/register",
+			strings.NewReader(`{"email":"Someone@Mailinator.com","password":"12345678"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.Routes().ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), "disposable email addresses are not allowed")
+	}
+}
+
+type sentMail struct{ to []string }
+
+func (m *sentMail) Send(_ context.Context, msg *mailer.Message) error {
+	m.to = append(m.to, msg.To)
+	return nil
+}
+
+func TestRequestMagicLinkDisposableEmailDropped(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	svc.SetDisposableEmailBlocking(true, nil, nil)
+	mail := &sentMail{}
+	svc.SetMailer(mail, "TestApp", "http://localhost:8090/api")
+
+	// Dropped before any database work, and reported as success like any
+	// other request so the caller learns nothing.
+	testutil.NoError(t, svc.RequestMagicLink(context.Background(), "someone@yopmail.com"))
+	testutil.SliceLen(t, mail.to, 0)
+}
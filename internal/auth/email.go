@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// RFC 5321 limits on an address and its local part.
+const (
+	maxEmailLen      = 254
+	maxEmailLocalLen = 64
+	maxDomainLabel   = 63
+)
+
+// validateEmail accepts addresses of the form local@domain, where local is
+// a dot-atom (RFC 5322 without quoted strings or comments) and domain is a
+// hostname with at least two labels. Non-ASCII letters and digits are
+// allowed for internationalized addresses.
+func validateEmail(email string) error {
+	if email == "" {
+		return fmt.Errorf("%w: email is required", ErrValidation)
+	}
+	if len(email) > maxEmailLen {
+		return fmt.Errorf("%w: email must be at most %d characters", ErrValidation, maxEmailLen)
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || !validEmailLocal(local) || !validEmailDomain(domain) {
+		return fmt.Errorf("%w: invalid email format", ErrValidation)
+	}
+	return nil
+}
+
+// validEmailLocal reports whether local is a dot-atom of at most 64 bytes.
+func validEmailLocal(local string) bool {
+	if local == "" || len(local) > maxEmailLocalLen {
+		return false
+	}
+	for _, atom := range strings.Split(local, ".") {
+		if atom == "" {
+			return false // leading, trailing, or consecutive dot
+		}
+		for _, r := range atom {
+			if !isAtext(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isAtext reports whether r may appear unquoted in a local part.
+func isAtext(r rune) bool {
+	if r > unicode.MaxASCII {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		strings.ContainsRune("!#$%&'*+/=?^_`{|}~-", r)
+}
+
+// validEmailDomain reports whether domain is a hostname with at least two
+// labels and a top-level label that isn't all digits.
+func validEmailDomain(domain string) bool {
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > maxDomainLabel ||
+			strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return false
+			}
+		}
+	}
+	return strings.ContainsFunc(labels[len(labels)-1], unicode.IsLetter)
+}
+
+// emailProvider describes which parts of an address a mail provider ignores
+// when delivering. Every listed provider delivers user+tag@ to user@.
+type emailProvider struct {
+	domain      string // canonical domain; "" keeps the one given
+	ignoresDots bool   // dots in the local part are ignored
+}
+
+// emailProviders are the providers normalizeEmail knows, by domain.
+var emailProviders = map[string]emailProvider{
+	"gmail.com":      {ignoresDots: true},
+	"googlemail.com": {domain: "gmail.com", ignoresDots: true},
+	"outlook.com":    {},
+	"hotmail.com":    {},
+	"live.com":       {},
+	"icloud.com":     {},
+	"me.com":         {},
+	"fastmail.com":   {},
+	"protonmail.com": {},
+	"proton.me":      {},
+}
+
+// normalizeEmail maps a lowercase address at a known provider to the
+// mailbox it delivers to: without a +tag, without dots where the provider
+// ignores them, and at the provider's canonical domain. Addresses at other
+// domains are returned unchanged, since what they ignore isn't known.
+func normalizeEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	p, known := emailProviders[domain]
+	if !ok || !known {
+		return email
+	}
+	if tag := strings.IndexByte(local, '+'); tag > 0 {
+		local = local[:tag]
+	}
+	if p.ignoresDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if p.domain != "" {
+		domain = p.domain
+	}
+	if local == "" {
+		return email
+	}
+	return local + "@" + domain
+}
+
+// canonicalEmail returns email trimmed and lowercased and, with email
+// normalization enabled, normalized for its provider. Addresses are stored
+// and looked up in this form.
+func (s *Service) canonicalEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if s.normalizeEmails {
+		email = normalizeEmail(email)
+	}
+	return email
+}
@@ -31,7 +31,7 @@ func TestRenderAuthEmail_UnknownKeyLegacy(t *testing.T) {
 func TestRenderAuthEmail_TemplateServiceUsed_AllKeys(t *testing.T) {
 	t.Parallel()
 
-	keys := []string{"auth.password_reset", "auth.email_verification", "auth.magic_link"}
+	keys := []string{"auth.password_reset", "auth.email_verification", "auth.magic_link", "auth.email_change", "auth.registration_attempt"}
 	for _, key := range keys {
 		key := key
 		t.Run(key, func(t *testing.T) {
@@ -95,6 +95,7 @@ func TestRenderAuthEmail_AllKeysLegacyPath(t *testing.T) {
 		{"auth.password_reset", mailer.DefaultPasswordResetSubject},
 		{"auth.email_verification", mailer.DefaultVerificationSubject},
 		{"auth.magic_link", mailer.DefaultMagicLinkSubject},
+		{"auth.registration_attempt", mailer.DefaultRegistrationAttemptSubject},
 	}
 
 	for _, tc := range tests {
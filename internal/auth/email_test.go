@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestValidateEmail(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		email   string
+		wantErr string
+	}{
+		{"valid", "user@example.com", ""},
+		{"valid subdomain", "user@mail.example.com", ""},
+		{"plus tag", "user+tag@example.com", ""},
+		{"dotted local", "first.last@example.com", ""},
+		{"special atext", "o'brien_x!#$%&*/=?^`{|}~-@example.com", ""},
+		{"hyphenated domain", "user@my-company.co.uk", ""},
+		{"numeric label", "user@123.example.com", ""},
+		{"internationalized", "jörg@bücher.de", ""},
+		{"max local length", strings.Repeat("a", 64) + "@example.com", ""},
+		{"empty", "", "email is required"},
+		{"too long", "user@" + strings.Repeat("a", 250) + ".com", "email must be at most 254 characters"},
+		{"no at", "userexample.com", "invalid email format"},
+		{"no domain dot", "user@example", "invalid email format"},
+		{"at at start", "@example.com", "invalid email format"},
+		{"two ats", "user@host@example.com", "invalid email format"},
+		{"empty domain", "user@", "invalid email format"},
+		{"leading dot", ".user@example.com", "invalid email format"},
+		{"trailing dot", "user.@example.com", "invalid email format"},
+		{"consecutive dots", "first..last@example.com", "invalid email format"},
+		{"space in local", "first last@example.com", "invalid email format"},
+		{"quoted local", `"user"@example.com`, "invalid email format"},
+		{"comma", "a,b@example.com", "invalid email format"},
+		{"local too long", strings.Repeat("a", 65) + "@example.com", "invalid email format"},
+		{"empty domain label", "user@example..com", "invalid email format"},
+		{"domain trailing dot", "user@example.com.", "invalid email format"},
+		{"label leading hyphen", "user@-example.com", "invalid email format"},
+		{"label trailing hyphen", "user@example-.com", "invalid email format"},
+		{"underscore in domain", "user@ex_ample.com", "invalid email format"},
+		{"numeric tld", "user@192.168.0.1", "invalid email format"},
+		{"ip literal", "user@[192.168.0.1]", "invalid email format"},
+		{"label too long", "user@" + strings.Repeat("a", 64) + ".com", "invalid email format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateEmail(tt.email)
+			if tt.wantErr == "" {
+				testutil.NoError(t, err)
+			} else {
+				testutil.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"jane.doe@gmail.com", "janedoe@gmail.com"},
+		{"jane.doe+shop@gmail.com", "janedoe@gmail.com"},
+		{"j.a.n.e+a+b@googlemail.com", "jane@gmail.com"},
+		{"jane.doe+news@outlook.com", "jane.doe@outlook.com"},
+		{"jane+x@icloud.com", "jane@icloud.com"},
+		{"jane+x@proton.me", "jane@proton.me"},
+		// Unknown providers may treat dots and tags as significant.
+		{"jane.doe+shop@example.com", "jane.doe+shop@example.com"},
+		{"jane.doe@mail.gmail.com", "jane.doe@mail.gmail.com"},
+		// A local part that is only a tag is left alone.
+		{"+tag@gmail.com", "+tag@gmail.com"},
+		{"not-an-email", "not-an-email"},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, normalizeEmail(tt.email))
+	}
+}
+
+func TestCanonicalEmail(t *testing.T) {
+	t.Parallel()
+	s := &Service{}
+	testutil.Equal(t, "jane.doe+shop@gmail.com", s.canonicalEmail("  Jane.Doe+Shop@Gmail.com "))
+
+	s.SetEmailNormalization(true)
+	testutil.Equal(t, "janedoe@gmail.com", s.canonicalEmail("  Jane.Doe+Shop@Gmail.com "))
+}
@@ -0,0 +1,40 @@
+package auth
+
+import "time"
+
+// Auth event types published to the EventPublisher.
+const (
+	EventSignup        = "auth.signup"
+	EventLogin         = "auth.login"
+	EventLogout        = "auth.logout"
+	EventPasswordReset = "auth.password_reset"
+	EventMFAEnrolled   = "auth.mfa_enrolled"
+)
+
+// Event is an auth event for downstream systems such as analytics or a CRM.
+// It deliberately carries no secrets or personal data beyond the user ID.
+type Event struct {
+	Type      string    `json:"type"`
+	UserID    string    `json:"userId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventPublisher receives auth events. PublishAuthEvent is called on the
+// request path, so it must not block; delivery is best-effort.
+type EventPublisher interface {
+	PublishAuthEvent(event Event)
+}
+
+// SetEventPublisher enables auth events. Nil disables them.
+func (s *Service) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
+// publishEvent sends an event of the given type for userID, if events are
+// enabled.
+func (s *Service) publishEvent(eventType, userID string) {
+	if s.events == nil {
+		return
+	}
+	s.events.PublishAuthEvent(Event{Type: eventType, UserID: userID, Timestamp: time.Now().UTC()})
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UserExport is everything AYB stores about a user, for answering data
+// access requests. Secrets (password hash, token hashes, key hashes) are
+// never included.
+type UserExport struct {
+	ExportedAt    time.Time           `json:"exportedAt"`
+	User          ExportedUser        `json:"user"`
+	OAuthAccounts []OAuthIdentity     `json:"oauthAccounts"`
+	APIKeys       []APIKey            `json:"apiKeys"`
+	MFA           []ExportedMFAMethod `json:"mfa"`
+	Sessions      []ExportedSession   `json:"sessions"`
+}
+
+// ExportedUser is the user's profile along with the account state only
+// admins otherwise see.
+type ExportedUser struct {
+	User
+	EmailVerified bool       `json:"emailVerified"`
+	DisabledAt    *time.Time `json:"disabledAt,omitempty"`
+}
+
+// ExportedMFAMethod is an MFA method the user has enrolled in or started
+// enrolling in.
+type ExportedMFAMethod struct {
+	Method     string     `json:"method"`
+	Phone      string     `json:"phone,omitempty"`
+	Enabled    bool       `json:"enabled"`
+	EnrolledAt *time.Time `json:"enrolledAt,omitempty"`
+}
+
+// ExportedSession is one of the user's refresh-token sessions.
+type ExportedSession struct {
+	ID         string    `json:"id"`
+	RememberMe bool      `json:"rememberMe"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// ExportUser gathers the user's profile, linked OAuth accounts, API key
+// metadata, MFA methods and sessions into one document.
+func (s *Service) ExportUser(ctx context.Context, id string) (*UserExport, error) {
+	user, err := s.UserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	export := &UserExport{
+		ExportedAt: time.Now().UTC(),
+		User:       ExportedUser{User: *user},
+		MFA:        []ExportedMFAMethod{},
+		Sessions:   []ExportedSession{},
+	}
+	err = s.pool.QueryRow(ctx,
+		`SELECT email_verified, disabled_at FROM _ayb_users WHERE id = $1`, id,
+	).Scan(&export.User.EmailVerified, &export.User.DisabledAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying account state: %w", err)
+	}
+
+	if export.APIKeys, err = s.ListAPIKeys(ctx, id); err != nil {
+		return nil, err
+	}
+	if export.APIKeys == nil {
+		export.APIKeys = []APIKey{}
+	}
+
+	if export.OAuthAccounts, err = s.OAuthIdentities(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT method, COALESCE(phone, ''), enabled, enrolled_at
+		 FROM _ayb_user_mfa WHERE user_id = $1 ORDER BY method`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying MFA methods: %w", err)
+	}
+	for rows.Next() {
+		var m ExportedMFAMethod
+		if err := rows.Scan(&m.Method, &m.Phone, &m.Enabled, &m.EnrolledAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning MFA method: %w", err)
+		}
+		export.MFA = append(export.MFA, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating MFA methods: %w", err)
+	}
+
+	rows, err = s.pool.Query(ctx,
+		`SELECT id, remember_me, created_at, expires_at
+		 FROM _ayb_sessions WHERE user_id = $1 ORDER BY created_at`, id)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+	for rows.Next() {
+		var sess ExportedSession
+		if err := rows.Scan(&sess.ID, &sess.RememberMe, &sess.CreatedAt, &sess.ExpiresAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+		export.Sessions = append(export.Sessions, sess)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating sessions: %w", err)
+	}
+
+	return export, nil
+}
@@ -6,7 +6,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/httputil"
@@ -42,9 +44,14 @@ type Handler struct {
 	oauthHTTPClient   *http.Client
 	oauthStateStore   *OAuthStateStore
 	oauthRedirectURL  string
+	oauthRedirectURLs []string       // other URLs a login may choose with ?redirect=
 	oauthPublisher    OAuthPublisher // nil when realtime hub not available
 	magicLinkEnabled  bool
 	smsEnabled        bool
+	hideRegConflicts  bool
+	// Changed at runtime, so atomic; the zero values leave both enabled.
+	registrationOff  atomic.Bool
+	passwordLoginOff atomic.Bool
 }
 
 // NewHandler creates a new auth handler.
@@ -88,6 +95,12 @@ func (h *Handler) SetOAuthRedirectURL(u string) {
 	h.oauthRedirectURL = u
 }
 
+// SetOAuthRedirectURLs sets the other URLs a login may ask, with the
+// redirect query parameter, to return to instead of the OAuth redirect URL.
+func (h *Handler) SetOAuthRedirectURLs(urls []string) {
+	h.oauthRedirectURLs = urls
+}
+
 // SetOAuthPublisher sets the realtime hub for publishing OAuth results to SSE clients.
 func (h *Handler) SetOAuthPublisher(pub OAuthPublisher) {
 	h.oauthPublisher = pub
@@ -103,6 +116,24 @@ func (h *Handler) SetSMSEnabled(enabled bool) {
 	h.smsEnabled = enabled
 }
 
+// SetHideRegistrationConflicts makes register answer a duplicate email like
+// a new one, without issuing tokens, so it can't be used to find accounts.
+func (h *Handler) SetHideRegistrationConflicts(hide bool) {
+	h.hideRegConflicts = hide
+}
+
+// SetRegistrationEnabled enables or disables POST /register. It is safe to
+// call while serving requests.
+func (h *Handler) SetRegistrationEnabled(enabled bool) {
+	h.registrationOff.Store(!enabled)
+}
+
+// SetPasswordLoginEnabled enables or disables email/password login through
+// POST /login. It is safe to call while serving requests.
+func (h *Handler) SetPasswordLoginEnabled(enabled bool) {
+	h.passwordLoginOff.Store(!enabled)
+}
+
 // Routes returns a chi.Router with auth endpoints mounted.
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
@@ -111,11 +142,14 @@ func (h *Handler) Routes() chi.Router {
 	r.Post("/refresh", h.handleRefresh)
 	r.Post("/logout", h.handleLogout)
 	r.With(RequireAuth(h.auth)).Get("/me", h.handleMe)
+	r.With(RequireAuth(h.auth)).Patch("/me", h.handleUpdateMe)
 	r.With(RequireAuth(h.auth)).Delete("/me", h.handleDeleteMe)
+	r.With(RequireAuth(h.auth)).Get("/me/identities", h.handleListIdentities)
 	r.Post("/password-reset", h.handlePasswordReset)
 	r.Post("/password-reset/confirm", h.handlePasswordResetConfirm)
 	r.Post("/verify", h.handleVerifyEmail)
 	r.With(RequireAuth(h.auth)).Post("/verify/resend", h.handleResendVerification)
+	r.Post("/email-change/confirm", h.handleEmailChangeConfirm)
 	r.Post("/magic-link", h.handleMagicLinkRequest)
 	r.Post("/magic-link/confirm", h.handleMagicLinkConfirm)
 	r.Get("/oauth/{provider}", h.handleOAuthRedirect)
@@ -126,6 +160,7 @@ func (h *Handler) Routes() chi.Router {
 	r.With(RequireAuth(h.auth)).Post("/authorize/consent", h.handleOAuthConsent)
 	r.Post("/sms", h.handleSMSRequest)
 	r.Post("/sms/confirm", h.handleSMSConfirm)
+	r.With(h.requireSMSEnabled).Post("/sms/validate", h.handleSMSValidate)
 
 	// MFA endpoints — gated behind smsEnabled check before auth middleware.
 	r.Route("/mfa/sms", func(mfa chi.Router) {
@@ -152,50 +187,106 @@ type authRequest struct {
 	Password string `json:"password"`
 }
 
+type loginRequest struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	RememberMe bool   `json:"rememberMe"`
+}
+
 type refreshRequest struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
 type authResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refreshToken"`
+	Token        string `json:"token,omitempty"`        // omitted when set as a cookie
+	RefreshToken string `json:"refreshToken,omitempty"` // omitted in cookie mode
+	CSRFToken    string `json:"csrfToken,omitempty"`    // cookie mode only
 	User         *User  `json:"user"`
 }
 
+// writeAuthResponse writes a freshly issued token pair. In cookie mode the
+// tokens go into cookies and the body carries the CSRF token instead.
+func (h *Handler) writeAuthResponse(w http.ResponseWriter, status int, user *User, accessToken, refreshToken string) {
+	h.writeTokenResponse(w, status, user, accessToken, refreshToken, h.auth.cookies != nil)
+}
+
+// writeTokenResponse writes a freshly issued token pair into cookies when
+// asCookies is set (cookie mode only), otherwise into the body.
+func (h *Handler) writeTokenResponse(w http.ResponseWriter, status int, user *User, accessToken, refreshToken string, asCookies bool) {
+	resp := authResponse{Token: accessToken, RefreshToken: refreshToken, User: user}
+	if asCookies {
+		csrfToken, err := h.auth.setTokenCookies(w, accessToken, refreshToken)
+		if err != nil {
+			h.logger.Error("setting token cookies", "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		resp.RefreshToken = ""
+		resp.CSRFToken = csrfToken
+		if h.auth.cookies.AccessToken {
+			resp.Token = ""
+		}
+	}
+	httputil.WriteJSON(w, status, resp)
+}
+
 func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if h.registrationOff.Load() {
+		httputil.WriteErrorWithDocURL(w, http.StatusForbidden, "registration is disabled",
+			"https://allyourbase.io/guide/authentication")
+		return
+	}
 	var req authRequest
 	if !decodeBody(w, r, &req) {
 		return
 	}
 
+	if h.hideRegConflicts {
+		if err := h.auth.RegisterHidingConflicts(r.Context(), req.Email, req.Password); err != nil {
+			h.writeRegisterError(w, err)
+			return
+		}
+		httputil.WriteJSON(w, http.StatusCreated, map[string]string{"message": "registration received; log in to continue"})
+		return
+	}
+
 	user, token, refreshToken, err := h.auth.Register(r.Context(), req.Email, req.Password)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrValidation):
-			// Strip the "validation error: " sentinel prefix from user-facing message.
-			msg := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
-			httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
-				"https://allyourbase.io/guide/authentication")
-		case errors.Is(err, ErrEmailTaken):
-			httputil.WriteErrorWithDocURL(w, http.StatusConflict, "email already registered",
-				"https://allyourbase.io/guide/authentication")
-		default:
-			h.logger.Error("register error", "error", err)
-			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
-		}
+		h.writeRegisterError(w, err)
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusCreated, authResponse{Token: token, RefreshToken: refreshToken, User: user})
+	h.writeAuthResponse(w, http.StatusCreated, user, token, refreshToken)
+}
+
+func (h *Handler) writeRegisterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrValidation):
+		// Strip the "validation error: " sentinel prefix from user-facing message.
+		msg := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
+			"https://allyourbase.io/guide/authentication")
+	case errors.Is(err, ErrEmailTaken):
+		httputil.WriteErrorWithDocURL(w, http.StatusConflict, "email already registered",
+			"https://allyourbase.io/guide/authentication")
+	default:
+		h.logger.Error("register error", "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+	}
 }
 
 func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
-	var req authRequest
+	if h.passwordLoginOff.Load() {
+		httputil.WriteErrorWithDocURL(w, http.StatusForbidden, "password login is disabled; sign in another way",
+			"https://allyourbase.io/guide/authentication")
+		return
+	}
+	var req loginRequest
 	if !decodeBody(w, r, &req) {
 		return
 	}
 
-	user, token, refreshToken, err := h.auth.Login(r.Context(), req.Email, req.Password)
+	user, token, refreshToken, err := h.auth.Login(r.Context(), req.Email, req.Password, req.RememberMe)
 	if err != nil {
 		if errors.Is(err, ErrInvalidCredentials) {
 			httputil.WriteErrorWithDocURL(w, http.StatusUnauthorized,
@@ -203,6 +294,16 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 				"https://allyourbase.io/guide/authentication")
 			return
 		}
+		if errors.Is(err, ErrAccountDisabled) {
+			httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+			return
+		}
+		if errors.Is(err, ErrValidation) {
+			msg := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+			httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
+				"https://allyourbase.io/guide/authentication")
+			return
+		}
 		h.logger.Error("login error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -217,7 +318,7 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, authResponse{Token: token, RefreshToken: refreshToken, User: user})
+	h.writeAuthResponse(w, http.StatusOK, user, token, refreshToken)
 }
 
 func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
@@ -237,6 +338,106 @@ func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusOK, user)
 }
 
+// handleListIdentities returns the OAuth identities linked to the caller,
+// with the profile each provider returned at the latest login.
+func (h *Handler) handleListIdentities(w http.ResponseWriter, r *http.Request) {
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil {
+		httputil.WriteError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+
+	identities, err := h.auth.OAuthIdentities(r.Context(), claims.Subject)
+	if err != nil {
+		h.logger.Error("list identities error", "error", err, "user_id", claims.Subject)
+		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, identities)
+}
+
+type updateMeRequest struct {
+	DisplayName *string        `json:"displayName"`
+	Email       *string        `json:"email"`
+	Metadata    map[string]any `json:"metadata"`
+}
+
+// handleUpdateMe updates the caller's own profile. A new email is only
+// applied once confirmed through the link sent to it (see
+// handleEmailChangeConfirm), so a stolen session can't take over the account
+// by redirecting its email.
+func (h *Handler) handleUpdateMe(w http.ResponseWriter, r *http.Request) {
+	claims := ClaimsFromContext(r.Context())
+	if claims == nil {
+		httputil.WriteError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	if err := CheckWriteScope(claims); err != nil {
+		httputil.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	var req updateMeRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	user, err := h.auth.UpdateProfile(r.Context(), claims.Subject, ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Metadata:    req.Metadata,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrValidation):
+			msg := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+			httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
+				"https://allyourbase.io/guide/authentication")
+		case errors.Is(err, ErrEmailTaken):
+			httputil.WriteErrorWithDocURL(w, http.StatusConflict, "email already registered",
+				"https://allyourbase.io/guide/authentication")
+		case errors.Is(err, ErrEmailChangeUnavailable):
+			httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("profile update error", "error", err, "user_id", claims.Subject)
+			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) handleEmailChangeConfirm(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Token == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	err := h.auth.ConfirmEmailChange(r.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmailChangeToken):
+			httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, "invalid or expired email change token",
+				"https://allyourbase.io/guide/authentication")
+		case errors.Is(err, ErrEmailTaken):
+			httputil.WriteErrorWithDocURL(w, http.StatusConflict, "email already registered",
+				"https://allyourbase.io/guide/authentication")
+		default:
+			h.logger.Error("email change confirmation error", "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+		}
+		return
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]string{"message": "email changed"})
+}
+
 func (h *Handler) handleDeleteMe(w http.ResponseWriter, r *http.Request) {
 	claims := ClaimsFromContext(r.Context())
 	if claims == nil {
@@ -254,48 +455,72 @@ func (h *Handler) handleDeleteMe(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+// refreshTokenFromRequest returns the refresh token from the cookie in cookie
+// mode, or else from the request body; fromCookie reports which. It writes
+// the error response and returns false if there is none.
+func (h *Handler) refreshTokenFromRequest(w http.ResponseWriter, r *http.Request) (token string, fromCookie, ok bool) {
+	if token, ok := h.auth.cookieValue(r, RefreshTokenCookie); ok {
+		return token, true, true
+	}
 	var req refreshRequest
 	if !decodeBody(w, r, &req) {
-		return
+		return "", false, false
 	}
 	if req.RefreshToken == "" {
 		httputil.WriteError(w, http.StatusBadRequest, "refreshToken is required")
+		return "", false, false
+	}
+	return req.RefreshToken, false, true
+}
+
+// handleRefresh rotates a session's tokens. The new pair goes back the way
+// the refresh token came: a cookie refresh rotates the cookies, and a body
+// refresh gets its tokens in the body even in cookie mode, so clients that
+// keep the refresh token themselves keep working.
+func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	token, fromCookie, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.auth.RefreshToken(r.Context(), req.RefreshToken)
+	user, accessToken, refreshToken, err := h.auth.RefreshToken(r.Context(), token)
 	if err != nil {
 		if errors.Is(err, ErrInvalidRefreshToken) {
+			if fromCookie {
+				h.auth.clearTokenCookies(w)
+			}
 			httputil.WriteErrorWithDocURL(w, http.StatusUnauthorized,
 				"invalid or expired refresh token",
 				"https://allyourbase.io/guide/authentication")
 			return
 		}
+		if errors.Is(err, ErrAccountDisabled) {
+			httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+			return
+		}
 		h.logger.Error("refresh error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, authResponse{Token: accessToken, RefreshToken: refreshToken, User: user})
+	h.writeTokenResponse(w, http.StatusOK, user, accessToken, refreshToken, fromCookie)
 }
 
 func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
-	var req refreshRequest
-	if !decodeBody(w, r, &req) {
-		return
-	}
-	if req.RefreshToken == "" {
-		httputil.WriteError(w, http.StatusBadRequest, "refreshToken is required")
+	token, _, ok := h.refreshTokenFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	if err := h.auth.Logout(r.Context(), req.RefreshToken); err != nil {
+	if err := h.auth.Logout(r.Context(), token); err != nil {
 		h.logger.Error("logout error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
+	if h.auth.cookies != nil {
+		h.auth.clearTokenCookies(w)
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -471,6 +696,10 @@ func (h *Handler) handleMagicLinkConfirm(w http.ResponseWriter, r *http.Request)
 				"https://allyourbase.io/guide/authentication#magic-link")
 			return
 		}
+		if errors.Is(err, ErrAccountDisabled) {
+			httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+			return
+		}
 		h.logger.Error("magic link confirm error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -484,7 +713,7 @@ func (h *Handler) handleMagicLinkConfirm(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, authResponse{Token: accessToken, RefreshToken: refreshToken, User: user})
+	h.writeAuthResponse(w, http.StatusOK, user, accessToken, refreshToken)
 }
 
 func (h *Handler) handleOAuthRedirect(w http.ResponseWriter, r *http.Request) {
@@ -496,6 +725,15 @@ func (h *Handler) handleOAuthRedirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject a redirect off the allowlist here, before the provider is
+	// involved, so tokens can never be sent to it.
+	target, err := h.oauthTargetFromRequest(r)
+	if err != nil {
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, err.Error(),
+			"https://allyourbase.io/guide/authentication#redirects")
+		return
+	}
+
 	// If state is provided and corresponds to an active SSE client, use it
 	// directly (popup flow). Otherwise, generate a new state token.
 	state := r.URL.Query().Get("state")
@@ -504,8 +742,7 @@ func (h *Handler) handleOAuthRedirect(w http.ResponseWriter, r *http.Request) {
 		// so the callback can validate it the same way.
 		h.oauthStateStore.RegisterExternalState(state)
 	} else {
-		var err error
-		state, err = h.oauthStateStore.Generate()
+		state, err = h.oauthStateStore.generate(target)
 		if err != nil {
 			h.logger.Error("OAuth state generation error", "error", err)
 			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
@@ -524,6 +761,30 @@ func (h *Handler) handleOAuthRedirect(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
 }
 
+// maxReturnToLen bounds the deep link carried through an OAuth login.
+const maxReturnToLen = 2048
+
+// oauthTargetFromRequest reads where an OAuth login should return from the
+// redirect and returnTo query parameters. redirect must be the OAuth
+// redirect URL or one of the allowlisted URLs, matched exactly. returnTo
+// must be a path, so an app that navigates to it stays on its own origin.
+func (h *Handler) oauthTargetFromRequest(r *http.Request) (oauthTarget, error) {
+	q := r.URL.Query()
+	target := oauthTarget{redirect: q.Get("redirect"), returnTo: q.Get("returnTo")}
+	if target.redirect != "" && target.redirect != h.oauthRedirectURL && !slices.Contains(h.oauthRedirectURLs, target.redirect) {
+		return oauthTarget{}, errors.New("redirect URL is not allowed")
+	}
+	if rt := target.returnTo; rt != "" {
+		if len(rt) > maxReturnToLen {
+			return oauthTarget{}, fmt.Errorf("returnTo must be at most %d characters", maxReturnToLen)
+		}
+		if !strings.HasPrefix(rt, "/") || strings.HasPrefix(rt, "//") || strings.Contains(rt, `\`) {
+			return oauthTarget{}, errors.New("returnTo must be a path, like /settings")
+		}
+	}
+	return target, nil
+}
+
 func (h *Handler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 	provider := chi.URLParam(r, "provider")
 	client, ok := h.oauthClients[provider]
@@ -554,7 +815,8 @@ func (h *Handler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 	// Validate CSRF state.
 	state := r.URL.Query().Get("state")
 	isSSEClient := h.oauthPublisher != nil && h.oauthPublisher.HasClient(state)
-	if !h.oauthStateStore.Validate(state) {
+	target, ok := h.oauthStateStore.consume(state)
+	if !ok {
 		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, "invalid or expired OAuth state",
 			"https://allyourbase.io/guide/authentication#oauth")
 		return
@@ -593,6 +855,15 @@ func (h *Handler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 
 	// Find or create user + issue tokens.
 	user, accessToken, refreshToken, err := h.auth.OAuthLogin(r.Context(), provider, info)
+	if errors.Is(err, ErrAccountDisabled) {
+		if isSSEClient {
+			h.oauthPublisher.PublishOAuth(state, &OAuthEvent{Error: "account is disabled"})
+			h.writeOAuthCompletePage(w)
+			return
+		}
+		httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+		return
+	}
 	if err != nil {
 		h.logger.Error("OAuth login error", "provider", provider, "error", err)
 		if isSSEClient {
@@ -625,23 +896,27 @@ func (h *Handler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If a redirect URL is configured, redirect with tokens in hash fragment.
-	if h.oauthRedirectURL != "" {
+	// If a redirect URL is configured or was chosen from the allowlist,
+	// redirect with tokens in hash fragment.
+	dest := h.oauthRedirectURL
+	if target.redirect != "" {
+		dest = target.redirect
+	}
+	if dest != "" {
+		fragment := url.Values{
+			"token":        {accessToken},
+			"refreshToken": {refreshToken},
+		}
 		if isMFAPending {
-			fragment := url.Values{
+			fragment = url.Values{
 				"mfa_pending": {"true"},
 				"mfa_token":   {accessToken},
 			}
-			dest := h.oauthRedirectURL + "#" + fragment.Encode()
-			http.Redirect(w, r, dest, http.StatusTemporaryRedirect)
-			return
 		}
-		fragment := url.Values{
-			"token":        {accessToken},
-			"refreshToken": {refreshToken},
+		if target.returnTo != "" {
+			fragment.Set("returnTo", target.returnTo)
 		}
-		dest := h.oauthRedirectURL + "#" + fragment.Encode()
-		http.Redirect(w, r, dest, http.StatusTemporaryRedirect)
+		http.Redirect(w, r, dest+"#"+fragment.Encode(), http.StatusTemporaryRedirect)
 		return
 	}
 
@@ -653,11 +928,7 @@ func (h *Handler) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	httputil.WriteJSON(w, http.StatusOK, authResponse{
-		Token:        accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	})
+	h.writeAuthResponse(w, http.StatusOK, user, accessToken, refreshToken)
 }
 
 // oauthCompletePage is served in the popup after OAuth completes.
@@ -84,6 +84,35 @@ func TestHandleLoginValidation(t *testing.T) {
 	testutil.Contains(t, w.Body.String(), "invalid JSON body")
 }
 
+func TestHandleDisabledAuthMethods(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(newTestService(), testutil.DiscardLogger())
+	h.SetRegistrationEnabled(false)
+	h.SetPasswordLoginEnabled(false)
+	router := h.Routes()
+
+	for path, wantMsg := range map[string]string{
+		"/register": "registration is disabled",
+		"/login":    "password login is disabled",
+	} {
+		req := httptest.NewRequest(http.MethodPost, path,
+			strings.NewReader(`{"email":"a@example.com","password":"password123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		testutil.Equal(t, http.StatusForbidden, w.Code)
+		testutil.Contains(t, w.Body.String(), wantMsg)
+	}
+
+	// Re-enabling registration falls through to normal validation.
+	h.SetRegistrationEnabled(true)
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"bad","password":"password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestHandleMeWithoutToken(t *testing.T) {
 	t.Parallel()
 	svc := newTestService()
@@ -97,6 +126,22 @@ func TestHandleMeWithoutToken(t *testing.T) {
 	testutil.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestHandleRegisterHidingConflictsValidation(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	h := NewHandler(svc, testutil.DiscardLogger())
+	h.SetHideRegistrationConflicts(true)
+	router := h.Routes()
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"notanemail","password":"12345678"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "invalid email format")
+}
+
 func TestHandleRegisterMalformedJSON(t *testing.T) {
 	t.Parallel()
 	svc := newTestService()
@@ -120,8 +165,8 @@ func TestHandleRegisterBodyTooLarge(t *testing.T) {
 
 	// Valid JSON structure that exceeds MaxBodySize. Without body-size
 	// enforcement this would parse as valid JSON and proceed to validation
-	// (returning "invalid email format" or similar). The MaxBytesReader
-	// truncates the read so json.Decode fails with "invalid JSON body".
+	// (returning "invalid email format" or similar). It is refused with 413
+	// before being read.
 	padding := bytes.Repeat([]byte("a"), httputil.MaxBodySize)
 	largeBody := append([]byte(`{"email":"`), padding...)
 	largeBody = append(largeBody, []byte(`@example.com","password":"12345678"}`)...)
@@ -130,8 +175,8 @@ func TestHandleRegisterBodyTooLarge(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	testutil.Equal(t, http.StatusBadRequest, w.Code)
-	testutil.Contains(t, w.Body.String(), "invalid JSON body")
+	testutil.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	testutil.Contains(t, w.Body.String(), "request body too large")
 }
 
 // TestAuthResponseFormat removed — tested json.Marshal on a struct literal without
@@ -294,6 +339,63 @@ func TestHandleDeleteMeRouteRegistered(t *testing.T) {
 	testutil.Contains(t, w.Body.String(), "missing or invalid authorization")
 }
 
+func TestHandleUpdateMeWithoutToken(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	h := NewHandler(svc, testutil.DiscardLogger())
+	router := h.Routes()
+
+	req := httptest.NewRequest(http.MethodPatch, "/me", strings.NewReader(`{"displayName":"Ann"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHandleUpdateMeValidation(t *testing.T) {
+	// Validation fails before the user is looked up, so no DB is needed.
+	t.Parallel()
+	svc := newTestService()
+	svc.SetProfileMetadataKeys([]string{"locale"})
+	h := NewHandler(svc, testutil.DiscardLogger())
+	router := h.Routes()
+	token := generateTestToken(t, svc, "user-1", "test@example.com")
+
+	for _, tc := range []struct {
+		body    string
+		wantMsg string
+	}{
+		{`{"metadata":{"role":"admin"}}`, `metadata key \"role\" is not allowed`},
+		{`{"displayName":"` + strings.Repeat("a", 101) + `"}`, "displayName must be at most 100 characters"},
+		{`{bad json`, "invalid JSON body"},
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/me", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), tc.wantMsg)
+	}
+}
+
+func TestHandleEmailChangeConfirmMissingToken(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	h := NewHandler(svc, testutil.DiscardLogger())
+	router := h.Routes()
+
+	req := httptest.NewRequest(http.MethodPost, "/email-change/confirm", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "token is required")
+}
+
 func TestHandlePasswordResetAlwaysReturns200(t *testing.T) {
 	// Even with no DB pool (will fail internally), password-reset
 	// should always return 200 to prevent email enumeration.
@@ -41,10 +41,14 @@ func (s *Service) RequestMagicLink(ctx context.Context, email string) error {
 	if s.mailer == nil {
 		return nil
 	}
-	email = strings.ToLower(strings.TrimSpace(email))
+	email = s.canonicalEmail(email)
 	if err := validateEmail(email); err != nil {
 		return nil // don't leak validation errors
 	}
+	if s.isDisposableEmail(email) {
+		s.logger.Info("magic link not sent: disposable email domain")
+		return nil
+	}
 
 	// Delete any existing magic link tokens for this email.
 	_, _ = s.pool.Exec(ctx, `DELETE FROM _ayb_magic_links WHERE email = $1`, email)
@@ -120,7 +124,7 @@ func (s *Service) ConfirmMagicLink(ctx context.Context, token string) (*User, st
 		if _, err := rand.Read(randomPW); err != nil {
 			return nil, "", "", fmt.Errorf("generating random password: %w", err)
 		}
-		pwHash, err := hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
+		pwHash, err := s.hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
 		if err != nil {
 			return nil, "", "", fmt.Errorf("hashing placeholder password: %w", err)
 		}
@@ -147,6 +151,7 @@ func (s *Service) ConfirmMagicLink(ctx context.Context, token string) (*User, st
 			}
 		} else {
 			s.logger.Info("user registered via magic link", "user_id", user.ID, "email", email)
+			s.publishEvent(EventSignup, user.ID)
 		}
 	} else if err != nil {
 		return nil, "", "", fmt.Errorf("querying user: %w", err)
@@ -159,18 +164,5 @@ func (s *Service) ConfirmMagicLink(ctx context.Context, token string) (*User, st
 		user.ID,
 	)
 
-	// If user has MFA enrolled, return a pending token instead of full tokens.
-	hasMFA, err := s.HasSMSMFA(ctx, user.ID)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("checking MFA enrollment: %w", err)
-	}
-	if hasMFA {
-		pendingToken, err := s.generateMFAPendingToken(&user)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("generating MFA pending token: %w", err)
-		}
-		return &user, pendingToken, "", nil
-	}
-
-	return s.issueTokens(ctx, &user)
+	return s.completeLogin(ctx, &user, false)
 }
@@ -2,19 +2,23 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type ctxKey struct{}
 
 // RequireAuth returns middleware that rejects requests without a valid JWT or API key.
+// See requestCredential for where the credential may be presented.
 func RequireAuth(svc *Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token, ok := extractBearerToken(r)
+			token, ok := requestCredential(svc, r)
 			if !ok {
 				httputil.WriteErrorWithDocURL(w, http.StatusUnauthorized,
 					"missing or invalid authorization header",
@@ -23,6 +27,10 @@ func RequireAuth(svc *Service) func(http.Handler) http.Handler {
 			}
 
 			claims, err := validateTokenOrAPIKey(r.Context(), svc, token)
+			if errors.Is(err, ErrMFAChallengeToken) {
+				httputil.WriteError(w, http.StatusUnauthorized, "MFA verification required")
+				return
+			}
 			if err != nil {
 				httputil.WriteErrorWithDocURL(w, http.StatusUnauthorized,
 					"invalid or expired token",
@@ -30,11 +38,7 @@ func RequireAuth(svc *Service) func(http.Handler) http.Handler {
 				return
 			}
 
-			if claims.MFAPending {
-				httputil.WriteError(w, http.StatusUnauthorized, "MFA verification required")
-				return
-			}
-
+			httputil.SetAccessLogSubject(r.Context(), claims.Subject)
 			ctx := context.WithValue(r.Context(), ctxKey{}, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -46,8 +50,9 @@ func RequireAuth(svc *Service) func(http.Handler) http.Handler {
 func OptionalAuth(svc *Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if token, ok := extractBearerToken(r); ok {
-				if claims, err := validateTokenOrAPIKey(r.Context(), svc, token); err == nil && !claims.MFAPending {
+			if token, ok := requestCredential(svc, r); ok {
+				if claims, err := validateTokenOrAPIKey(r.Context(), svc, token); err == nil {
+					httputil.SetAccessLogSubject(r.Context(), claims.Subject)
 					ctx := context.WithValue(r.Context(), ctxKey{}, claims)
 					r = r.WithContext(ctx)
 				}
@@ -57,8 +62,8 @@ func OptionalAuth(svc *Service) func(http.Handler) http.Handler {
 	}
 }
 
-// RequireMFAPending returns middleware that accepts only MFA pending tokens.
-// This is the inverse of RequireAuth — it requires mfa_pending: true.
+// RequireMFAPending returns middleware that accepts only MFA challenge tokens
+// (see ValidateMFAChallengeToken). This is the inverse of RequireAuth.
 func RequireMFAPending(svc *Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -68,8 +73,8 @@ func RequireMFAPending(svc *Service) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims, err := svc.ValidateToken(token)
-			if err != nil || !claims.MFAPending {
+			claims, err := svc.ValidateMFAChallengeToken(token)
+			if err != nil {
 				httputil.WriteError(w, http.StatusUnauthorized, "no MFA challenge pending")
 				return
 			}
@@ -126,6 +131,10 @@ func oauthTokenInfoToClaims(info *OAuthTokenInfo) *Claims {
 		AppID:              info.AppID,
 		AppRateLimitRPS:    info.AppRateLimitRPS,
 		AppRateLimitWindow: info.AppRateLimitWindowSeconds,
+		principal:          PrincipalOAuth,
+	}
+	if !info.ExpiresAt.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(info.ExpiresAt)
 	}
 	if info.UserID != nil {
 		claims.Subject = *info.UserID
@@ -166,3 +175,70 @@ func CheckTableScope(claims *Claims, table string) error {
 func extractBearerToken(r *http.Request) (string, bool) {
 	return httputil.ExtractBearerToken(r)
 }
+
+// HasCredential reports whether r presents a credential RequireAuth would
+// check: an Authorization header or, in cookie mode, the access token cookie.
+func HasCredential(svc *Service, r *http.Request) bool {
+	_, ok := requestCredential(svc, r)
+	return ok
+}
+
+// PublicReadClaims returns the claims of an unauthenticated caller reading
+// public tables: read-only and limited to those tables, so related tables
+// that aren't public can't be expanded. RLS applies as for any caller, with
+// an empty user ID.
+func PublicReadClaims(tables []string) *Claims {
+	return &Claims{APIKeyScope: ScopeReadOnly, AllowedTables: tables}
+}
+
+// requestCredential returns the credential presented with r: the
+// Authorization header (see extractCredential), or else the access token
+// cookie in cookie mode. Cookie-authenticated requests are CSRF-checked by
+// RequireCSRF, which the server mounts in front of the API.
+func requestCredential(svc *Service, r *http.Request) (string, bool) {
+	if token, ok := extractCredential(r); ok {
+		return token, true
+	}
+	if svc.cookies == nil || !svc.cookies.AccessToken {
+		return "", false
+	}
+	return svc.cookieValue(r, AccessTokenCookie)
+}
+
+// extractCredential returns the token presented in the Authorization header:
+// a Bearer token, or an API key sent as the HTTP Basic username with an empty
+// password, for tools that only speak Basic auth. Bearer wins if a request
+// carries both.
+func extractCredential(r *http.Request) (string, bool) {
+	headers := r.Header.Values("Authorization")
+	for _, header := range headers {
+		if token, ok := httputil.ParseBearerToken(header); ok {
+			return token, true
+		}
+	}
+	for _, header := range headers {
+		if key, ok := basicAuthAPIKey(header); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// basicAuthAPIKey parses a Basic Authorization header value whose username
+// is an API key and whose password is empty. OAuth access tokens are not
+// accepted this way.
+func basicAuthAPIKey(header string) (string, bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", false
+	}
+	key, password, ok := strings.Cut(string(decoded), ":")
+	if !ok || password != "" || !IsAPIKey(key) || IsOAuthAccessToken(key) {
+		return "", false
+	}
+	return key, true
+}
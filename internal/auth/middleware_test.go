@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -170,7 +171,7 @@ func TestOptionalAuth_MFAPendingToken_TreatedAsUnauthenticated(t *testing.T) {
 	svc := newTestService()
 	user := &User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"}
 
-	token, err := svc.generateMFAPendingToken(user)
+	token, err := svc.generateMFAChallengeToken(user, false)
 	testutil.NoError(t, err)
 
 	var gotClaims *Claims
@@ -208,7 +209,7 @@ func TestRequireAuthMissingHeaderDocURL(t *testing.T) {
 
 	var resp httputil.ErrorResponse
 	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
-	testutil.Equal(t, "https://allyourbase.io/guide/authentication", resp.DocURL)
+	testutil.Equal(t, "https://allyourbase.io/guide/authentication", resp.Error.DocURL)
 }
 
 func TestRequireAuthExpiredTokenDocURL(t *testing.T) {
@@ -231,7 +232,7 @@ func TestRequireAuthExpiredTokenDocURL(t *testing.T) {
 
 	var resp httputil.ErrorResponse
 	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
-	testutil.Equal(t, "https://allyourbase.io/guide/authentication", resp.DocURL)
+	testutil.Equal(t, "https://allyourbase.io/guide/authentication", resp.Error.DocURL)
 }
 
 func TestValidateTokenOrAPIKeyOAuthWithNilPoolReturnsError(t *testing.T) {
@@ -268,6 +269,61 @@ func TestRequireAuthAPIKeyWithNilPoolReturnsUnauthorized(t *testing.T) {
 	testutil.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func basicAuthHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func TestExtractCredential(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+		wantOK  bool
+	}{
+		{"bearer", []string{"Bearer tok"}, "tok", true},
+		{"basic api key", []string{basicAuthHeader("ayb_deadbeef", "")}, "ayb_deadbeef", true},
+		{"basic scheme is case-insensitive", []string{"basic " + base64.StdEncoding.EncodeToString([]byte("ayb_deadbeef:"))}, "ayb_deadbeef", true},
+		{"basic with password", []string{basicAuthHeader("ayb_deadbeef", "secret")}, "", false},
+		{"basic non-key username", []string{basicAuthHeader("alice", "")}, "", false},
+		{"basic oauth access token", []string{basicAuthHeader("ayb_at_deadbeef", "")}, "", false},
+		{"basic malformed base64", []string{"Basic !!!"}, "", false},
+		{"basic without colon", []string{"Basic " + base64.StdEncoding.EncodeToString([]byte("ayb_deadbeef"))}, "", false},
+		{"bearer wins over basic", []string{basicAuthHeader("ayb_basic", ""), "Bearer ayb_bearer"}, "ayb_bearer", true},
+		{"missing", nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, h := range tt.headers {
+				req.Header.Add("Authorization", h)
+			}
+			got, ok := extractCredential(req)
+			testutil.Equal(t, tt.wantOK, ok)
+			testutil.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequireAuthBasicNonKeyRejected(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	handler := RequireAuth(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// A JWT is only accepted as a Bearer token.
+	token := generateTestToken(t, svc, "user-1", "test@example.com")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", basicAuthHeader(token, ""))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+	testutil.Contains(t, w.Body.String(), "missing or invalid authorization header")
+}
+
 // --- OAuth scope enforcement via Claims conversion ---
 
 func TestOAuthReadonlyScopeDeniesWriteViaClaimsConversion(t *testing.T) {
@@ -403,3 +459,17 @@ func TestOAuthTokenInfoToClaimsIncludesAppRateLimitFields(t *testing.T) {
 	testutil.Equal(t, 1, len(claims.AllowedTables))
 	testutil.Equal(t, "posts", claims.AllowedTables[0])
 }
+
+func TestPrincipalType(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	claims, err := validateTokenOrAPIKey(context.Background(), svc, generateTestToken(t, svc, "user-1", "a@example.com"))
+	testutil.NoError(t, err)
+	testutil.Equal(t, PrincipalUser, claims.PrincipalType())
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	claims = oauthTokenInfoToClaims(&OAuthTokenInfo{Scope: ScopeReadOnly, ExpiresAt: expires})
+	testutil.Equal(t, PrincipalOAuth, claims.PrincipalType())
+	testutil.NotNil(t, claims.ExpiresAt)
+	testutil.True(t, claims.ExpiresAt.Time.Equal(expires), "oauth expiry should carry over, got %v", claims.ExpiresAt)
+}
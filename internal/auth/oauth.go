@@ -100,25 +100,58 @@ type OAuthUserInfo struct {
 	ProviderUserID string
 	Email          string
 	Name           string
+	AvatarURL      string
+	// Profile is the provider's userinfo response, stored as returned.
+	Profile json.RawMessage
+}
+
+// OAuthIdentity is an OAuth provider identity linked to a user, with the
+// profile the provider returned at the latest login.
+type OAuthIdentity struct {
+	Provider       string          `json:"provider"`
+	ProviderUserID string          `json:"providerUserId"`
+	Email          string          `json:"email,omitempty"`
+	Name           string          `json:"name,omitempty"`
+	Profile        json.RawMessage `json:"profile"`
+	CreatedAt      time.Time       `json:"createdAt"`
 }
 
 // OAuthStateStore manages CSRF state tokens with TTL-based expiry.
 type OAuthStateStore struct {
 	mu     sync.Mutex
-	states map[string]time.Time
+	states map[string]oauthState
 	ttl    time.Duration
 }
 
+// oauthState is a pending OAuth login.
+type oauthState struct {
+	expires time.Time
+	target  oauthTarget
+}
+
+// oauthTarget is where a login returns once the callback issues tokens:
+// redirect, an allowlisted URL, replaces the configured redirect URL, and
+// returnTo is passed back to the app in the fragment.
+type oauthTarget struct {
+	redirect string
+	returnTo string
+}
+
 // NewOAuthStateStore creates a state store with the given TTL.
 func NewOAuthStateStore(ttl time.Duration) *OAuthStateStore {
 	return &OAuthStateStore{
-		states: make(map[string]time.Time),
+		states: make(map[string]oauthState),
 		ttl:    ttl,
 	}
 }
 
 // Generate creates a new cryptographic state token and stores it.
 func (s *OAuthStateStore) Generate() (string, error) {
+	return s.generate(oauthTarget{})
+}
+
+// generate creates a new state token carrying the login's target.
+func (s *OAuthStateStore) generate(target oauthTarget) (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
 		return "", fmt.Errorf("generating state: %w", err)
@@ -129,12 +162,12 @@ func (s *OAuthStateStore) Generate() (string, error) {
 	defer s.mu.Unlock()
 	// Prune expired entries opportunistically.
 	now := time.Now()
-	for k, exp := range s.states {
-		if now.After(exp) {
+	for k, st := range s.states {
+		if now.After(st.expires) {
 			delete(s.states, k)
 		}
 	}
-	s.states[token] = now.Add(s.ttl)
+	s.states[token] = oauthState{expires: now.Add(s.ttl), target: target}
 	return token, nil
 }
 
@@ -145,19 +178,29 @@ func (s *OAuthStateStore) Generate() (string, error) {
 func (s *OAuthStateStore) RegisterExternalState(state string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.states[state] = time.Now().Add(s.ttl)
+	s.states[state] = oauthState{expires: time.Now().Add(s.ttl)}
 }
 
 // Validate checks and consumes a state token (one-time use).
 func (s *OAuthStateStore) Validate(token string) bool {
+	_, ok := s.consume(token)
+	return ok
+}
+
+// consume validates and consumes a state token, returning the target of
+// its login.
+func (s *OAuthStateStore) consume(token string) (oauthTarget, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	exp, ok := s.states[token]
+	st, ok := s.states[token]
 	if !ok {
-		return false
+		return oauthTarget{}, false
 	}
 	delete(s.states, token)
-	return time.Now().Before(exp)
+	if !time.Now().Before(st.expires) {
+		return oauthTarget{}, false
+	}
+	return st.target, true
 }
 
 // AuthorizationURL builds the URL to redirect the user to the OAuth provider.
@@ -276,9 +319,10 @@ func fetchUserInfo(ctx context.Context, provider, userInfoURL, accessToken strin
 
 func parseGoogleUser(body []byte) (*OAuthUserInfo, error) {
 	var u struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
-		Name  string `json:"name"`
+		ID      string `json:"id"`
+		Email   string `json:"email"`
+		Name    string `json:"name"`
+		Picture string `json:"picture"`
 	}
 	if err := json.Unmarshal(body, &u); err != nil {
 		return nil, fmt.Errorf("parsing Google user: %w", err)
@@ -290,15 +334,18 @@ func parseGoogleUser(body []byte) (*OAuthUserInfo, error) {
 		ProviderUserID: u.ID,
 		Email:          u.Email,
 		Name:           u.Name,
+		AvatarURL:      u.Picture,
+		Profile:        body,
 	}, nil
 }
 
 func parseGitHubUser(ctx context.Context, body []byte, accessToken string, httpClient *http.Client) (*OAuthUserInfo, error) {
 	var u struct {
-		ID    int    `json:"id"`
-		Login string `json:"login"`
-		Email string `json:"email"`
-		Name  string `json:"name"`
+		ID        int    `json:"id"`
+		Login     string `json:"login"`
+		Email     string `json:"email"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
 	}
 	if err := json.Unmarshal(body, &u); err != nil {
 		return nil, fmt.Errorf("parsing GitHub user: %w", err)
@@ -327,6 +374,8 @@ func parseGitHubUser(ctx context.Context, body []byte, accessToken string, httpC
 		ProviderUserID: fmt.Sprintf("%d", u.ID),
 		Email:          email,
 		Name:           name,
+		AvatarURL:      u.AvatarURL,
+		Profile:        body,
 	}, nil
 }
 
@@ -366,14 +415,17 @@ func fetchGitHubPrimaryEmail(ctx context.Context, accessToken string, httpClient
 }
 
 // OAuthLogin finds or creates a user from an OAuth identity and returns
-// the user with access + refresh tokens.
+// the user with access + refresh tokens. The provider profile in info is
+// stored on the linked identity at every login.
 func (s *Service) OAuthLogin(ctx context.Context, provider string, info *OAuthUserInfo) (*User, string, string, error) {
-	// 1. Check if this OAuth identity is already linked.
+	// 1. Check if this OAuth identity is already linked, refreshing its profile.
 	var userID string
 	err := s.pool.QueryRow(ctx,
-		`SELECT user_id FROM _ayb_oauth_accounts
-		 WHERE provider = $1 AND provider_user_id = $2`,
-		provider, info.ProviderUserID,
+		`UPDATE _ayb_oauth_accounts
+		 SET provider_profile = COALESCE($3, provider_profile)
+		 WHERE provider = $1 AND provider_user_id = $2
+		 RETURNING user_id`,
+		provider, info.ProviderUserID, info.Profile,
 	).Scan(&userID)
 
 	if err == nil {
@@ -386,7 +438,7 @@ func (s *Service) OAuthLogin(ctx context.Context, provider string, info *OAuthUs
 
 	// 2. No link. Check if a user with this email exists.
 	if info.Email != "" {
-		email := strings.ToLower(info.Email)
+		email := s.canonicalEmail(info.Email)
 		err := s.pool.QueryRow(ctx,
 			`SELECT id FROM _ayb_users WHERE LOWER(email) = $1`, email,
 		).Scan(&userID)
@@ -404,7 +456,7 @@ func (s *Service) OAuthLogin(ctx context.Context, provider string, info *OAuthUs
 	}
 
 	// 3. Create a new user and link the OAuth account.
-	email := strings.ToLower(info.Email)
+	email := s.canonicalEmail(info.Email)
 	if email == "" {
 		// Generate a placeholder email for users without email (rare).
 		email = fmt.Sprintf("%s+%s@oauth.local", provider, info.ProviderUserID)
@@ -415,7 +467,7 @@ func (s *Service) OAuthLogin(ctx context.Context, provider string, info *OAuthUs
 	if _, err := rand.Read(randomPW); err != nil {
 		return nil, "", "", fmt.Errorf("generating random password: %w", err)
 	}
-	hash, err := hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
+	hash, err := s.hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
 	if err != nil {
 		return nil, "", "", fmt.Errorf("hashing placeholder password: %w", err)
 	}
@@ -450,52 +502,81 @@ func (s *Service) OAuthLogin(ctx context.Context, provider string, info *OAuthUs
 	}
 
 	s.logger.Info("user registered via OAuth", "user_id", user.ID, "provider", provider)
-	return s.issueTokens(ctx, &user)
+	s.publishEvent(EventSignup, user.ID)
+	return s.issueTokens(ctx, &user, false)
 }
 
+// linkOAuthAccount links an OAuth identity to the user. On the first link,
+// the provider's avatar becomes the user's metadata.avatar_url unless one is
+// already set.
 func (s *Service) linkOAuthAccount(ctx context.Context, userID, provider string, info *OAuthUserInfo) error {
-	_, err := s.pool.Exec(ctx,
-		`INSERT INTO _ayb_oauth_accounts (user_id, provider, provider_user_id, email, name)
-		 VALUES ($1, $2, $3, $4, $5)
+	tag, err := s.pool.Exec(ctx,
+		`INSERT INTO _ayb_oauth_accounts (user_id, provider, provider_user_id, email, name, provider_profile)
+		 VALUES ($1, $2, $3, $4, $5, COALESCE($6, '{}'::jsonb))
 		 ON CONFLICT (provider, provider_user_id) DO NOTHING`,
-		userID, provider, info.ProviderUserID, info.Email, info.Name,
+		userID, provider, info.ProviderUserID, info.Email, info.Name, info.Profile,
 	)
 	if err != nil {
 		return fmt.Errorf("linking OAuth account: %w", err)
 	}
+	if tag.RowsAffected() == 0 || info.AvatarURL == "" {
+		return nil
+	}
+	_, err = s.pool.Exec(ctx,
+		`UPDATE _ayb_users
+		 SET metadata = metadata || jsonb_build_object('avatar_url', $2::text), updated_at = NOW()
+		 WHERE id = $1 AND NOT metadata ? 'avatar_url'`,
+		userID, info.AvatarURL,
+	)
+	if err != nil {
+		return fmt.Errorf("setting avatar from OAuth profile: %w", err)
+	}
 	return nil
 }
 
-func (s *Service) loginByID(ctx context.Context, userID string) (*User, string, string, error) {
-	user, err := s.UserByID(ctx, userID)
+// OAuthIdentities returns the OAuth identities linked to the user, oldest
+// first.
+func (s *Service) OAuthIdentities(ctx context.Context, userID string) ([]OAuthIdentity, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT provider, provider_user_id, COALESCE(email, ''), COALESCE(name, ''), provider_profile, created_at
+		 FROM _ayb_oauth_accounts WHERE user_id = $1 ORDER BY created_at`, userID)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("looking up user: %w", err)
+		return nil, fmt.Errorf("querying oauth accounts: %w", err)
 	}
+	defer rows.Close()
 
-	// If user has MFA enrolled, return a pending token instead of full tokens.
-	hasMFA, err := s.HasSMSMFA(ctx, userID)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("checking MFA enrollment: %w", err)
-	}
-	if hasMFA {
-		pendingToken, err := s.generateMFAPendingToken(user)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("generating MFA pending token: %w", err)
+	identities := []OAuthIdentity{}
+	for rows.Next() {
+		var id OAuthIdentity
+		if err := rows.Scan(&id.Provider, &id.ProviderUserID, &id.Email, &id.Name, &id.Profile, &id.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning oauth account: %w", err)
 		}
-		return user, pendingToken, "", nil
+		identities = append(identities, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating oauth accounts: %w", err)
+	}
+	return identities, nil
+}
+
+func (s *Service) loginByID(ctx context.Context, userID string) (*User, string, string, error) {
+	user, err := s.UserByID(ctx, userID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("looking up user: %w", err)
 	}
 
-	return s.issueTokens(ctx, user)
+	return s.completeLogin(ctx, user, false)
 }
 
-func (s *Service) issueTokens(ctx context.Context, user *User) (*User, string, string, error) {
+func (s *Service) issueTokens(ctx context.Context, user *User, rememberMe bool) (*User, string, string, error) {
 	token, err := s.generateToken(user)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("generating token: %w", err)
 	}
-	refreshToken, err := s.createSession(ctx, user.ID)
+	refreshToken, err := s.createSession(ctx, user.ID, rememberMe)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("creating session: %w", err)
 	}
+	s.publishEvent(EventLogin, user.ID)
 	return user, token, refreshToken, nil
 }
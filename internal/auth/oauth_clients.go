@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -95,6 +96,8 @@ var (
 	ErrOAuthClientNameRequired        = errors.New("oauth client name is required")
 	ErrOAuthAppRequired               = errors.New("app_id is required for oauth client")
 	ErrOAuthClientPublicSecretRotator = errors.New("cannot regenerate secret for public client")
+	ErrOAuthRedirectURIRequired       = errors.New("at least one redirect URI is required")
+	ErrOAuthServiceAccountRedirectURI = errors.New("service accounts have no redirect URIs")
 )
 
 // --- Generators ---
@@ -170,7 +173,7 @@ func VerifyClientSecret(secret, hash string) bool {
 // Rules: HTTPS required (except localhost), no query params, no fragments, no wildcards, exact match.
 func ValidateRedirectURIs(uris []string) error {
 	if len(uris) == 0 {
-		return fmt.Errorf("at least one redirect URI is required")
+		return ErrOAuthRedirectURIRequired
 	}
 	for _, raw := range uris {
 		if raw == "" {
@@ -201,6 +204,33 @@ func ValidateRedirectURIs(uris []string) error {
 	return nil
 }
 
+// ValidateClientRedirectURIs validates the redirect URIs of a client of the
+// given type. A confidential client registered without any is a service
+// account, which can only use the client_credentials grant.
+func ValidateClientRedirectURIs(clientType string, uris []string) error {
+	if clientType == OAuthClientTypeConfidential && len(uris) == 0 {
+		return nil
+	}
+	return ValidateRedirectURIs(uris)
+}
+
+// IsServiceAccount reports whether the client is a service account: a
+// confidential client without redirect URIs, for server-to-server use.
+func (c *OAuthClient) IsServiceAccount() bool {
+	return c.ClientType == OAuthClientTypeConfidential && len(c.RedirectURIs) == 0
+}
+
+// ServiceAccountScope is the scope of tokens issued to a service account
+// that doesn't request one: the broadest of its configured scopes.
+func ServiceAccountScope(scopes []string) string {
+	for _, scope := range []string{ScopeFullAccess, ScopeReadWrite} {
+		if slices.Contains(scopes, scope) {
+			return scope
+		}
+	}
+	return ScopeReadOnly
+}
+
 // MatchRedirectURI checks if a redirect URI exactly matches one of the registered URIs.
 func MatchRedirectURI(uri string, registered []string) bool {
 	for _, r := range registered {
@@ -279,12 +309,15 @@ func (s *Service) RegisterOAuthClient(ctx context.Context, appID, name, clientTy
 	if err := ValidateClientType(clientType); err != nil {
 		return "", nil, err
 	}
-	if err := ValidateRedirectURIs(redirectURIs); err != nil {
+	if err := ValidateClientRedirectURIs(clientType, redirectURIs); err != nil {
 		return "", nil, err
 	}
 	if err := ValidateOAuthScopes(scopes); err != nil {
 		return "", nil, err
 	}
+	if redirectURIs == nil {
+		redirectURIs = []string{} // the column is NOT NULL
+	}
 
 	clientID, err := GenerateClientID()
 	if err != nil {
@@ -460,12 +493,15 @@ func (s *Service) ListOAuthClients(ctx context.Context, page, perPage int) (*OAu
 
 // UpdateOAuthClient updates a non-revoked OAuth client's name, redirect URIs, and scopes.
 // Client type and app association are immutable — use delete + recreate to change those.
+// Likewise a service account keeps no redirect URIs, and other clients keep at least one.
 func (s *Service) UpdateOAuthClient(ctx context.Context, clientID, name string, redirectURIs, scopes []string) (*OAuthClient, error) {
 	if name == "" {
 		return nil, ErrOAuthClientNameRequired
 	}
-	if err := ValidateRedirectURIs(redirectURIs); err != nil {
-		return nil, err
+	if len(redirectURIs) > 0 {
+		if err := ValidateRedirectURIs(redirectURIs); err != nil {
+			return nil, err
+		}
 	}
 	if err := ValidateOAuthScopes(scopes); err != nil {
 		return nil, err
@@ -479,6 +515,15 @@ func (s *Service) UpdateOAuthClient(ctx context.Context, clientID, name string,
 	if existing.RevokedAt != nil {
 		return nil, ErrOAuthClientRevoked
 	}
+	if existing.IsServiceAccount() && len(redirectURIs) > 0 {
+		return nil, ErrOAuthServiceAccountRedirectURI
+	}
+	if !existing.IsServiceAccount() && len(redirectURIs) == 0 {
+		return nil, ErrOAuthRedirectURIRequired
+	}
+	if redirectURIs == nil {
+		redirectURIs = []string{} // the column is NOT NULL
+	}
 
 	var client OAuthClient
 	err = s.pool.QueryRow(ctx,
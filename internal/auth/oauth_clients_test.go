@@ -3,6 +3,7 @@ package auth
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"strings"
 	"testing"
 
@@ -140,6 +141,24 @@ func TestValidateRedirectURIs(t *testing.T) {
 	}
 }
 
+func TestValidateClientRedirectURIs(t *testing.T) {
+	t.Parallel()
+	testutil.NoError(t, ValidateClientRedirectURIs(OAuthClientTypeConfidential, nil))
+	testutil.NoError(t, ValidateClientRedirectURIs(OAuthClientTypeConfidential, []string{"https://example.com/cb"}))
+	testutil.True(t, errors.Is(ValidateClientRedirectURIs(OAuthClientTypePublic, nil), ErrOAuthRedirectURIRequired),
+		"public clients need a redirect URI")
+	testutil.True(t, ValidateClientRedirectURIs(OAuthClientTypeConfidential, []string{"http://example.com/cb"}) != nil,
+		"listed URIs are still validated")
+}
+
+func TestOAuthClientIsServiceAccount(t *testing.T) {
+	t.Parallel()
+	testutil.True(t, (&OAuthClient{ClientType: OAuthClientTypeConfidential}).IsServiceAccount(), "confidential without redirect URIs")
+	testutil.False(t, (&OAuthClient{ClientType: OAuthClientTypeConfidential, RedirectURIs: []string{"https://example.com/cb"}}).IsServiceAccount(),
+		"confidential with redirect URIs")
+	testutil.False(t, (&OAuthClient{ClientType: OAuthClientTypePublic}).IsServiceAccount(), "public client")
+}
+
 func TestMatchRedirectURI(t *testing.T) {
 	t.Parallel()
 	registered := []string{
@@ -225,6 +244,13 @@ func TestIsScopeSubset(t *testing.T) {
 	}
 }
 
+func TestServiceAccountScope(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, ServiceAccountScope([]string{"readonly"}), "readonly")
+	testutil.Equal(t, ServiceAccountScope([]string{"readonly", "readwrite"}), "readwrite")
+	testutil.Equal(t, ServiceAccountScope([]string{"readwrite", "*"}), "*")
+}
+
 // --- OAuthClient struct ---
 
 func TestOAuthClientTypeConstants(t *testing.T) {
@@ -391,6 +391,7 @@ type OAuthTokenInfo struct {
 	AppID                     string // resolved from the OAuth client's app_id
 	AppRateLimitRPS           int    // app's configured RPS limit (0 = unlimited)
 	AppRateLimitWindowSeconds int    // app's rate limit window in seconds (0 = default)
+	ExpiresAt                 time.Time
 }
 
 // ValidateOAuthToken validates an opaque OAuth access token.
@@ -445,6 +446,7 @@ func (s *Service) ValidateOAuthToken(ctx context.Context, token string) (*OAuthT
 		AppID:                     appID,
 		AppRateLimitRPS:           appRateLimitRPS,
 		AppRateLimitWindowSeconds: appRateLimitWindow,
+		ExpiresAt:                 tok.ExpiresAt,
 	}, nil
 }
 
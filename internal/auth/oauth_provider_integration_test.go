@@ -4,8 +4,12 @@ package auth_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -239,6 +243,66 @@ func TestOAuthClientCredentialsGrant(t *testing.T) {
 	testutil.Equal(t, "readwrite", info.Scope)
 }
 
+func TestServiceAccountClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+	svc := newAuthService()
+
+	user, _, _, err := svc.Register(ctx, "service-owner@example.com", "password123")
+	testutil.NoError(t, err)
+	app, err := svc.CreateApp(ctx, "backend", "Backend jobs", user.ID)
+	testutil.NoError(t, err)
+	secret, client, err := svc.RegisterOAuthClient(ctx, app.ID, "nightly-sync", auth.OAuthClientTypeConfidential, nil, []string{"readonly"})
+	testutil.NoError(t, err)
+	testutil.True(t, client.IsServiceAccount(), "a confidential client without redirect URIs is a service account")
+
+	router := auth.NewHandler(svc, testutil.DiscardLogger()).Routes()
+	requestToken := func(form url.Values) *httptest.ResponseRecorder {
+		form.Set("grant_type", "client_credentials")
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client.ClientID, secret)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without a scope, the token gets the account's configured scope.
+	w := requestToken(url.Values{})
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var resp auth.OAuthTokenResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, "readonly", resp.Scope)
+	testutil.Equal(t, "", resp.RefreshToken)
+	testutil.True(t, resp.ExpiresIn > 0, "token should expire")
+
+	// Scopes beyond the configured ones are refused.
+	w = requestToken(url.Values{"scope": {"readwrite"}})
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), auth.OAuthErrInvalidScope)
+
+	// The token reads but can't write.
+	var gotClaims *auth.Claims
+	handler := auth.RequireAuth(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims = auth.ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.AccessToken)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.True(t, gotClaims.IsReadAllowed(), "readonly scope should allow reads")
+	testutil.False(t, gotClaims.IsWriteAllowed(), "readonly scope should deny writes")
+
+	// A service account can't gain redirect URIs, nor other clients lose theirs.
+	_, err = svc.UpdateOAuthClient(ctx, client.ClientID, "nightly-sync", []string{"https://example.com/callback"}, []string{"readonly"})
+	testutil.True(t, errors.Is(err, auth.ErrOAuthServiceAccountRedirectURI), "service account should keep no redirect URIs")
+	other, _, err := svc.RegisterOAuthClient(ctx, app.ID, "web", auth.OAuthClientTypePublic, nil, []string{"readonly"})
+	testutil.True(t, errors.Is(err, auth.ErrOAuthRedirectURIRequired), "public clients need redirect URIs")
+	testutil.Equal(t, "", other)
+}
+
 // --- Refresh Token Rotation ---
 
 func TestOAuthRefreshTokenRotation(t *testing.T) {
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,12 +77,14 @@ func TestAuthorizationURLUnsupported(t *testing.T) {
 
 func TestParseGoogleUser(t *testing.T) {
 	t.Parallel()
-	body := `{"id":"12345","email":"user@gmail.com","name":"Test User"}`
+	body := `{"id":"12345","email":"user@gmail.com","name":"Test User","picture":"https://example.com/a.png"}`
 	info, err := parseGoogleUser([]byte(body))
 	testutil.NoError(t, err)
 	testutil.Equal(t, "12345", info.ProviderUserID)
 	testutil.Equal(t, "user@gmail.com", info.Email)
 	testutil.Equal(t, "Test User", info.Name)
+	testutil.Equal(t, "https://example.com/a.png", info.AvatarURL)
+	testutil.Equal(t, body, string(info.Profile))
 }
 
 func TestParseGoogleUserMissingID(t *testing.T) {
@@ -92,12 +96,14 @@ func TestParseGoogleUserMissingID(t *testing.T) {
 
 func TestParseGitHubUser(t *testing.T) {
 	t.Parallel()
-	body := `{"id":42,"login":"octocat","email":"octocat@github.com","name":"The Octocat"}`
+	body := `{"id":42,"login":"octocat","email":"octocat@github.com","name":"The Octocat","avatar_url":"https://example.com/o.png"}`
 	info, err := parseGitHubUser(context.Background(), []byte(body), "unused-token", oauthHTTPClient)
 	testutil.NoError(t, err)
 	testutil.Equal(t, "42", info.ProviderUserID)
 	testutil.Equal(t, "octocat@github.com", info.Email)
 	testutil.Equal(t, "The Octocat", info.Name)
+	testutil.Equal(t, "https://example.com/o.png", info.AvatarURL)
+	testutil.Equal(t, body, string(info.Profile))
 }
 
 func TestParseGitHubUserFallbackLoginAsName(t *testing.T) {
@@ -148,6 +154,87 @@ func TestHandleOAuthRedirectConfiguredProvider(t *testing.T) {
 	testutil.Contains(t, loc, "state=")
 }
 
+func redirectAllowlistHandler() *Handler {
+	h := NewHandler(newTestService(), testutil.DiscardLogger())
+	h.SetOAuthProvider("google", OAuthClientConfig{ClientID: "test-id", ClientSecret: "test-secret"})
+	h.SetOAuthRedirectURL("http://localhost:5173/oauth-callback")
+	h.SetOAuthRedirectURLs([]string{"https://app.example.com/oauth-callback"})
+	return h
+}
+
+func TestHandleOAuthRedirectRejectsUnlistedRedirect(t *testing.T) {
+	t.Parallel()
+	h := redirectAllowlistHandler()
+	router := h.Routes()
+
+	for _, redirect := range []string{
+		"https://evil.example.com/oauth-callback",
+		"https://app.example.com/oauth-callback/../steal",
+		"https://app.example.com/oauth-callback?x=1",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/oauth/google?redirect="+url.QueryEscape(redirect), nil)
+		req.Host = "localhost:8090"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), "redirect URL is not allowed")
+		testutil.Equal(t, "", w.Header().Get("Location"))
+	}
+
+	// No login was started, so there is no state a callback could complete.
+	h.oauthStateStore.mu.Lock()
+	defer h.oauthStateStore.mu.Unlock()
+	testutil.Equal(t, 0, len(h.oauthStateStore.states))
+}
+
+func TestHandleOAuthRedirectCarriesTarget(t *testing.T) {
+	t.Parallel()
+	h := redirectAllowlistHandler()
+	router := h.Routes()
+
+	for _, redirect := range []string{"https://app.example.com/oauth-callback", "http://localhost:5173/oauth-callback"} {
+		q := url.Values{"redirect": {redirect}, "returnTo": {"/projects/7?tab=members"}}
+		req := httptest.NewRequest(http.MethodGet, "/oauth/google?"+q.Encode(), nil)
+		req.Host = "localhost:8090"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		testutil.Equal(t, http.StatusTemporaryRedirect, w.Code)
+
+		loc, err := url.Parse(w.Header().Get("Location"))
+		testutil.NoError(t, err)
+		target, ok := h.oauthStateStore.consume(loc.Query().Get("state"))
+		testutil.True(t, ok, "state should be stored")
+		testutil.Equal(t, redirect, target.redirect)
+		testutil.Equal(t, "/projects/7?tab=members", target.returnTo)
+	}
+}
+
+func TestHandleOAuthRedirectValidatesReturnTo(t *testing.T) {
+	t.Parallel()
+	h := redirectAllowlistHandler()
+	router := h.Routes()
+
+	tests := []struct {
+		returnTo string
+		wantMsg  string
+	}{
+		{returnTo: "https://evil.example.com/", wantMsg: "returnTo must be a path"},
+		{returnTo: "//evil.example.com/", wantMsg: "returnTo must be a path"},
+		{returnTo: `/\evil.example.com/`, wantMsg: "returnTo must be a path"},
+		{returnTo: "/" + strings.Repeat("a", maxReturnToLen), wantMsg: "returnTo must be at most"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/oauth/google?returnTo="+url.QueryEscape(tt.returnTo), nil)
+		req.Host = "localhost:8090"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), tt.wantMsg)
+	}
+}
+
 func TestHandleOAuthCallbackMissingState(t *testing.T) {
 	t.Parallel()
 	svc := newTestService()
@@ -105,7 +105,12 @@ func (h *Handler) handleOAuthTokenClientCredentialsGrant(r *http.Request, client
 		return nil, NewOAuthError(OAuthErrUnauthorizedClient, "client_credentials is only allowed for confidential clients")
 	}
 
+	// Service accounts are issued their configured scope unless they ask
+	// for a narrower one.
 	scope := r.PostForm.Get("scope")
+	if scope == "" && client.IsServiceAccount() {
+		scope = ServiceAccountScope(client.Scopes)
+	}
 	if scope == "" {
 		return nil, NewOAuthError(OAuthErrInvalidScope, "scope is required")
 	}
@@ -531,9 +531,10 @@ func TestOAuthTokenClientCredentialsMissingScope(t *testing.T) {
 	t.Parallel()
 	h, fake := newOAuthTokenTestHandler()
 	fake.validateClient = &OAuthClient{
-		ClientID:   "ayb_cid_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
-		ClientType: OAuthClientTypeConfidential,
-		Scopes:     []string{"readonly"},
+		ClientID:     "ayb_cid_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		ClientType:   OAuthClientTypeConfidential,
+		RedirectURIs: []string{"https://example.com/callback"},
+		Scopes:       []string{"readonly"},
 	}
 
 	form := url.Values{
@@ -551,6 +552,36 @@ func TestOAuthTokenClientCredentialsMissingScope(t *testing.T) {
 	testutil.Equal(t, OAuthErrInvalidScope, oe.Code)
 }
 
+func TestOAuthTokenClientCredentialsServiceAccountDefaultScope(t *testing.T) {
+	t.Parallel()
+	h, fake := newOAuthTokenTestHandler()
+	fake.validateClient = &OAuthClient{
+		ClientID:   "ayb_cid_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		ClientType: OAuthClientTypeConfidential,
+		Scopes:     []string{"readonly", "readwrite"},
+	}
+	fake.clientCredentialsResp = &OAuthTokenResponse{
+		AccessToken: "ayb_at_service1",
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+		Scope:       "readwrite",
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"ayb_cid_aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+		"client_secret": {"secret-123"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.Routes().ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 1, fake.clientCredentialsCalls)
+	testutil.Equal(t, "readwrite", fake.lastClientCredentialsScope)
+}
+
 func TestOAuthTokenClientCredentialsScopeNotSubset(t *testing.T) {
 	t.Parallel()
 	h, fake := newOAuthTokenTestHandler()
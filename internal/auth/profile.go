@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/allyourbase/ayb/internal/mailer"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrInvalidEmailChangeToken is returned when an email change token is invalid or expired.
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+	// ErrEmailChangeUnavailable is returned when an email change is requested
+	// but no mailer is configured to confirm the new address.
+	ErrEmailChangeUnavailable = errors.New("email changes require email delivery to be configured")
+)
+
+const (
+	emailChangeTokenBytes = 32
+	emailChangeExpiry     = 24 * time.Hour
+	maxDisplayNameLen     = 100
+)
+
+// ProfileUpdate is a self-service change to a user's profile. Nil fields are
+// left unchanged.
+type ProfileUpdate struct {
+	DisplayName *string
+	// Email starts an email change: the address is only switched once the
+	// link sent to it is confirmed. The current address cancels a pending change.
+	Email *string
+	// Metadata is merged into the stored metadata; a nil value removes the key.
+	// Only keys set with SetProfileMetadataKeys are accepted.
+	Metadata map[string]any
+}
+
+// SetProfileMetadataKeys sets the metadata keys users may set on their own
+// profile. Empty disallows self-service metadata.
+func (s *Service) SetProfileMetadataKeys(keys []string) {
+	s.metadataKeys = keys
+}
+
+// UpdateProfile applies a self-service profile update and returns the updated
+// user. The update is validated in full before anything is changed.
+func (s *Service) UpdateProfile(ctx context.Context, userID string, upd ProfileUpdate) (*User, error) {
+	var displayName *string
+	if upd.DisplayName != nil {
+		name := strings.TrimSpace(*upd.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLen {
+			return nil, fmt.Errorf("%w: displayName must be at most %d characters", ErrValidation, maxDisplayNameLen)
+		}
+		displayName = &name
+	}
+
+	set := map[string]any{}
+	remove := []string{} // nil would encode as NULL and clear the metadata
+	for key, value := range upd.Metadata {
+		if !slices.Contains(s.metadataKeys, key) {
+			return nil, fmt.Errorf("%w: metadata key %q is not allowed", ErrValidation, key)
+		}
+		if value == nil {
+			remove = append(remove, key)
+		} else {
+			set[key] = value
+		}
+	}
+
+	current, err := s.UserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var newEmail string
+	if upd.Email != nil {
+		newEmail = s.canonicalEmail(*upd.Email)
+		if err := validateEmail(newEmail); err != nil {
+			return nil, err
+		}
+		if newEmail != strings.ToLower(current.Email) {
+			if s.mailer == nil {
+				return nil, ErrEmailChangeUnavailable
+			}
+			var taken bool
+			if err := s.pool.QueryRow(ctx,
+				`SELECT EXISTS (SELECT 1 FROM _ayb_users WHERE LOWER(email) = $1)`, newEmail,
+			).Scan(&taken); err != nil {
+				return nil, fmt.Errorf("checking email: %w", err)
+			}
+			if taken {
+				return nil, ErrEmailTaken
+			}
+		}
+	}
+
+	if displayName != nil || len(upd.Metadata) > 0 {
+		_, err := s.pool.Exec(ctx,
+			`UPDATE _ayb_users
+			 SET display_name = COALESCE($2, display_name),
+			     metadata = (metadata || $3::jsonb) - $4::text[],
+			     updated_at = NOW()
+			 WHERE id = $1`,
+			userID, displayName, set, remove,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("updating profile: %w", err)
+		}
+	}
+
+	if upd.Email != nil {
+		if newEmail == strings.ToLower(current.Email) {
+			if _, err := s.pool.Exec(ctx, `DELETE FROM _ayb_email_changes WHERE user_id = $1`, userID); err != nil {
+				return nil, fmt.Errorf("cancelling email change: %w", err)
+			}
+		} else if err := s.requestEmailChange(ctx, userID, newEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.UserByID(ctx, userID)
+}
+
+// requestEmailChange records a pending change to newEmail, replacing any
+// earlier one, and emails a confirmation link to the new address.
+func (s *Service) requestEmailChange(ctx context.Context, userID, newEmail string) error {
+	raw := make([]byte, emailChangeTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("generating email change token: %w", err)
+	}
+	plaintext := base64.RawURLEncoding.EncodeToString(raw)
+
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO _ayb_email_changes (user_id, new_email, token_hash, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET new_email = EXCLUDED.new_email, token_hash = EXCLUDED.token_hash,
+		     expires_at = EXCLUDED.expires_at, created_at = NOW()`,
+		userID, newEmail, hashToken(plaintext), time.Now().Add(emailChangeExpiry),
+	)
+	if err != nil {
+		return fmt.Errorf("inserting email change: %w", err)
+	}
+
+	actionURL := s.baseURL + "/auth/email-change/confirm?token=" + plaintext
+	vars := map[string]string{"AppName": s.appName, "ActionURL": actionURL}
+	subject, html, text, err := s.renderAuthEmail(ctx, "auth.email_change", vars)
+	if err != nil {
+		return fmt.Errorf("rendering email change email: %w", err)
+	}
+
+	if err := s.mailer.Send(ctx, &mailer.Message{
+		To:      newEmail,
+		Subject: subject,
+		HTML:    html,
+		Text:    text,
+	}); err != nil {
+		s.logger.Error("failed to send email change confirmation", "error", err, "user_id", userID)
+	}
+	s.logger.Info("email change requested", "user_id", userID)
+	return nil
+}
+
+// ConfirmEmailChange switches the user's email to the address the token was
+// sent to, which the confirmation also verifies. The token is single use.
+func (s *Service) ConfirmEmailChange(ctx context.Context, token string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var userID, newEmail string
+	err = tx.QueryRow(ctx,
+		`DELETE FROM _ayb_email_changes
+		 WHERE token_hash = $1 AND expires_at > NOW()
+		 RETURNING user_id, new_email`,
+		hashToken(token),
+	).Scan(&userID, &newEmail)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidEmailChangeToken
+		}
+		return fmt.Errorf("querying email change token: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE _ayb_users SET email = $2, email_verified = true, updated_at = NOW() WHERE id = $1`,
+		userID, newEmail,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrEmailTaken
+		}
+		return fmt.Errorf("updating email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing email change: %w", err)
+	}
+
+	s.logger.Info("email changed", "user_id", userID)
+	return nil
+}
@@ -1,10 +1,8 @@
 package auth
 
 import (
-	"net"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -42,6 +40,21 @@ func (rl *RateLimiter) Stop() {
 	close(rl.stop)
 }
 
+// Limit returns the number of requests allowed per window per IP.
+func (rl *RateLimiter) Limit() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.limit
+}
+
+// SetLimit changes the number of requests allowed per window per IP. It
+// applies from the next request; requests already counted stay in the window.
+func (rl *RateLimiter) SetLimit(limit int) {
+	rl.mu.Lock()
+	rl.limit = limit
+	rl.mu.Unlock()
+}
+
 // Allow checks whether the given IP is within the rate limit.
 // Returns allowed (bool), remaining (int), resetTime (time.Time).
 func (rl *RateLimiter) Allow(ip string) (allowed bool, remaining int, resetTime time.Time) {
@@ -78,16 +91,10 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 		allowed, remaining, resetTime := rl.Allow(ip)
 
 		// Always set rate limit headers (even on success)
-		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.limit))
-		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+		setRateLimitHeaders(w.Header(), "X-RateLimit-", rl.Limit(), remaining, resetTime)
 
 		if !allowed {
-			retryAfter := int(time.Until(resetTime).Seconds()) + 1 // round up
-			if retryAfter < 1 {
-				retryAfter = 1
-			}
-			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			setRetryAfter(w.Header(), resetTime)
 			httputil.WriteErrorWithDocURL(w, http.StatusTooManyRequests, "too many requests",
 				"https://allyourbase.io/guide/authentication")
 			return
@@ -96,6 +103,24 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// setRateLimitHeaders sets the Limit, Remaining, and Reset (Unix seconds)
+// headers under prefix, e.g. "X-RateLimit-".
+func setRateLimitHeaders(h http.Header, prefix string, limit, remaining int, reset time.Time) {
+	h.Set(prefix+"Limit", strconv.Itoa(limit))
+	h.Set(prefix+"Remaining", strconv.Itoa(remaining))
+	h.Set(prefix+"Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+// setRetryAfter sets Retry-After to the whole seconds until reset, rounded up
+// and at least 1.
+func setRetryAfter(h http.Header, reset time.Time) {
+	retryAfter := int(time.Until(reset).Seconds()) + 1 // round up
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	h.Set("Retry-After", strconv.Itoa(retryAfter))
+}
+
 // pruneTimestamps removes timestamps older than cutoff from a visitor in place.
 func pruneTimestamps(v *visitor, cutoff time.Time) {
 	valid := v.timestamps[:0]
@@ -128,36 +153,9 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// clientIP returns the client address for rate limiting. The server's
+// client-IP middleware has already resolved trusted proxy headers into
+// r.RemoteAddr, so forwarding headers are not consulted here.
 func clientIP(r *http.Request) string {
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		host = r.RemoteAddr
-	}
-
-	// Only trust proxy headers when the direct connection is from a
-	// private/loopback address (i.e. the request came through a reverse proxy).
-	// Without this check, any client can spoof X-Forwarded-For to bypass rate limits.
-	if isPrivateIP(host) {
-		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-			ip := xff
-			if i := strings.IndexByte(xff, ','); i >= 0 {
-				ip = xff[:i]
-			}
-			return strings.TrimSpace(ip)
-		}
-		if xri := r.Header.Get("X-Real-IP"); xri != "" {
-			return strings.TrimSpace(xri)
-		}
-	}
-
-	return host
-}
-
-// isPrivateIP checks whether an IP string is a private/loopback address.
-func isPrivateIP(ip string) bool {
-	parsed := net.ParseIP(ip)
-	if parsed == nil {
-		return false
-	}
-	return parsed.IsLoopback() || parsed.IsPrivate()
+	return httputil.RemoteIP(r)
 }
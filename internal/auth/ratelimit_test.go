@@ -37,6 +37,26 @@ func TestRateLimiterAllow(t *testing.T) {
 	testutil.Equal(t, 2, remaining)
 }
 
+func TestRateLimiterSetLimit(t *testing.T) {
+	t.Parallel()
+	rl := NewRateLimiter(3, time.Minute)
+	defer rl.Stop()
+
+	allowed, _, _ := rl.Allow("1.2.3.4")
+	testutil.True(t, allowed, "first request")
+
+	// Lowering the limit applies to the next request.
+	rl.SetLimit(1)
+	testutil.Equal(t, 1, rl.Limit())
+	allowed, _, _ = rl.Allow("1.2.3.4")
+	testutil.False(t, allowed, "request over the new limit rejected")
+
+	rl.SetLimit(5)
+	allowed, remaining, _ := rl.Allow("1.2.3.4")
+	testutil.True(t, allowed, "request under the raised limit")
+	testutil.Equal(t, 3, remaining)
+}
+
 func TestRateLimiterWindowExpiry(t *testing.T) {
 	t.Parallel()
 	rl := NewRateLimiter(2, 20*time.Millisecond)
@@ -141,56 +161,15 @@ func TestRateLimiterMiddlewareHeaders(t *testing.T) {
 
 // --- clientIP tests ---
 
-func TestClientIPFromXForwardedForTrustedProxy(t *testing.T) {
-	// XFF is trusted when RemoteAddr is a private/loopback IP (behind proxy).
+func TestClientIPIgnoresForwardingHeaders(t *testing.T) {
+	// Proxy headers are resolved by the server middleware before the rate
+	// limiter runs; the limiter must not re-read them.
 	t.Parallel()
-
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.RemoteAddr = "127.0.0.1:12345"
 	req.Header.Set("X-Forwarded-For", "203.0.113.50")
-	testutil.Equal(t, "203.0.113.50", clientIP(req))
-}
-
-func TestClientIPFromXForwardedForMultipleTrustedProxy(t *testing.T) {
-	t.Parallel()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "10.0.0.1:12345"
-	req.Header.Set("X-Forwarded-For", "203.0.113.50, 70.41.3.18, 150.172.238.178")
-	testutil.Equal(t, "203.0.113.50", clientIP(req))
-}
-
-func TestClientIPFromXForwardedForTrimsWhitespace(t *testing.T) {
-	t.Parallel()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "192.168.1.1:12345"
-	req.Header.Set("X-Forwarded-For", "  203.0.113.50 , 70.41.3.18")
-	testutil.Equal(t, "203.0.113.50", clientIP(req))
-}
-
-func TestClientIPFromXRealIPTrustedProxy(t *testing.T) {
-	t.Parallel()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "127.0.0.1:12345"
 	req.Header.Set("X-Real-IP", "198.51.100.1")
-	testutil.Equal(t, "198.51.100.1", clientIP(req))
-}
-
-func TestClientIPIgnoresXFFFromPublicIP(t *testing.T) {
-	// XFF should be ignored when RemoteAddr is a public IP (direct connection).
-	t.Parallel()
-
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "203.0.113.1:12345"
-	req.Header.Set("X-Forwarded-For", "10.0.0.99")
-	testutil.Equal(t, "203.0.113.1", clientIP(req))
-}
-
-func TestClientIPIgnoresXRealIPFromPublicIP(t *testing.T) {
-	t.Parallel()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.RemoteAddr = "198.51.100.5:12345"
-	req.Header.Set("X-Real-IP", "10.0.0.99")
-	testutil.Equal(t, "198.51.100.5", clientIP(req))
+	testutil.Equal(t, "127.0.0.1", clientIP(req))
 }
 
 func TestClientIPFromRemoteAddr(t *testing.T) {
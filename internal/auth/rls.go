@@ -1,10 +1,16 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -34,6 +40,62 @@ func rlsStatements(claims *Claims) (roleSQL, userIDSQL, emailSQL string) {
 	return
 }
 
+// rlsClaimStatements returns the SET LOCAL statements for claims mapped to
+// ayb.<name> settings (see Service.SetRLSClaims), ordered by name.
+func rlsClaimStatements(claims *Claims) []string {
+	names := make([]string, 0, len(claims.rlsSettings))
+	for name := range claims.rlsSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stmts := make([]string, len(names))
+	for i, name := range names {
+		stmts[i] = "SET LOCAL ayb." + quoteIdent(name) + " = '" + escapeLiteral(claims.rlsSettings[name]) + "'"
+	}
+	return stmts
+}
+
+// rlsClaimSettings extracts the named claims from a JWT's payload as setting
+// values. Strings are used as-is, numbers and booleans in their JSON form,
+// arrays and objects as JSON; a missing claim maps to "".
+func rlsClaimSettings(rawToken string, names []string) (map[string]string, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	payload, err := jwt.NewParser().DecodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var all map[string]any
+	if err := dec.Decode(&all); err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	settings := make(map[string]string, len(names))
+	for _, name := range names {
+		switch v := all[name].(type) {
+		case nil:
+			settings[name] = ""
+		case string:
+			settings[name] = v
+		case json.Number:
+			settings[name] = v.String()
+		case bool:
+			settings[name] = strconv.FormatBool(v)
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("encoding claim %q: %w", name, err)
+			}
+			settings[name] = string(b)
+		}
+	}
+	return settings, nil
+}
+
 // SetRLSContext switches to the authenticated role and sets Postgres session
 // variables for RLS policies within the given transaction. Uses SET LOCAL
 // and set_config(..., true), both scoped to the current transaction.
@@ -42,6 +104,10 @@ func rlsStatements(claims *Claims) (roleSQL, userIDSQL, emailSQL string) {
 //
 //	CREATE POLICY user_owns_row ON posts
 //	    USING (author_id::text = current_setting('ayb.user_id', true));
+//
+// Claims configured with Service.SetRLSClaims are set the same way, as
+// ayb.<claim name>. Being transaction-local, none of these settings outlive
+// the request's transaction on a pooled connection.
 func SetRLSContext(ctx context.Context, tx pgx.Tx, claims *Claims) error {
 	if claims == nil {
 		return nil
@@ -64,5 +130,11 @@ func SetRLSContext(ctx context.Context, tx pgx.Tx, claims *Claims) error {
 		return fmt.Errorf("setting ayb.user_email: %w", err)
 	}
 
+	for _, stmt := range rlsClaimStatements(claims) {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("setting claim context: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -196,3 +196,27 @@ func TestRLSStatements(t *testing.T) {
 		})
 	}
 }
+
+func TestRLSClaimStatements(t *testing.T) {
+	t.Parallel()
+	claims := &Claims{rlsSettings: map[string]string{
+		"tenant_id": "acme",
+		"org_id":    "o'1; DROP TABLE users; --",
+	}}
+	stmts := rlsClaimStatements(claims)
+	testutil.SliceLen(t, stmts, 2)
+	// Sorted by name; values escaped.
+	testutil.Equal(t, `SET LOCAL ayb."org_id" = 'o''1; DROP TABLE users; --'`, stmts[0])
+	testutil.Equal(t, `SET LOCAL ayb."tenant_id" = 'acme'`, stmts[1])
+
+	testutil.SliceLen(t, rlsClaimStatements(&Claims{}), 0)
+}
+
+func TestRLSClaimSettingsMalformed(t *testing.T) {
+	t.Parallel()
+	_, err := rlsClaimSettings("not-a-jwt", []string{"tenant_id"})
+	testutil.ErrorContains(t, err, "malformed token")
+
+	_, err = rlsClaimSettings("a.!!!.c", []string{"tenant_id"})
+	testutil.ErrorContains(t, err, "decoding payload")
+}
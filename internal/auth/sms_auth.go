@@ -4,10 +4,15 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/sms"
@@ -15,17 +20,55 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-// generateOTP produces an N-digit numeric string using crypto/rand.
-func generateOTP(length int) (string, error) {
-	digits := make([]byte, length)
-	for i := range digits {
-		n, err := rand.Int(rand.Reader, big.NewInt(10))
+// OTP alphabets. The alphanumeric one leaves out characters that are easily
+// mistaken for each other (0/O, 1/I/L).
+const (
+	otpNumericAlphabet      = "0123456789"
+	otpAlphanumericAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+)
+
+// generateOTP produces a string of length characters drawn from alphabet
+// using crypto/rand.
+func generateOTP(length int, alphabet string) (string, error) {
+	size := big.NewInt(int64(len(alphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, size)
 		if err != nil {
-			return "", fmt.Errorf("generating OTP digit: %w", err)
+			return "", fmt.Errorf("generating OTP character: %w", err)
 		}
-		digits[i] = '0' + byte(n.Int64())
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// otpAlphabet returns the alphabet configured for generated OTPs.
+func (s *Service) otpAlphabet() string {
+	if s.smsConfig.CodeAlphabet == "alphanumeric" {
+		return otpAlphanumericAlphabet
+	}
+	return otpNumericAlphabet
+}
+
+// normalizeOTP puts a code in the form it is hashed in. Alphanumeric codes
+// are matched case-insensitively and may be entered with the grouping
+// separators they are sent with; numeric codes are compared as given.
+func (s *Service) normalizeOTP(code string) string {
+	if s.smsConfig.CodeAlphabet != "alphanumeric" {
+		return code
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// formatOTP groups an alphanumeric code of six or more characters into two
+// halves joined by "-" for display, e.g. "ABC-DEF". Numeric codes are
+// returned unchanged.
+func (s *Service) formatOTP(code string) string {
+	if s.smsConfig.CodeAlphabet != "alphanumeric" || len(code) < 6 {
+		return code
 	}
-	return string(digits), nil
+	half := (len(code) + 1) / 2
+	return code[:half] + "-" + code[half:]
 }
 
 // normalizePhone delegates to sms.NormalizePhone for E.164 normalization.
@@ -43,6 +86,11 @@ func isAllowedCountry(phone string, allowed []string) bool {
 	return sms.IsAllowedCountry(phone, allowed)
 }
 
+// isBlockedCountry delegates to sms.IsBlockedCountry for country blocklist checks.
+func isBlockedCountry(phone string, blocked []string) bool {
+	return sms.IsBlockedCountry(phone, blocked)
+}
+
 // RequestSMSCode sends an OTP to the given phone number.
 func (s *Service) RequestSMSCode(ctx context.Context, phone string) error {
 	if s.smsProvider == nil {
@@ -61,10 +109,15 @@ func (s *Service) RequestSMSCode(ctx context.Context, phone string) error {
 		return ErrInvalidPhoneNumber
 	}
 
-	if !isAllowedCountry(phone, s.smsConfig.AllowedCountries) {
+	if !isAllowedCountry(phone, s.smsConfig.AllowedCountries) || isBlockedCountry(phone, s.smsConfig.BlockedCountries) {
 		return nil // anti-enumeration: silently ignore blocked countries
 	}
 
+	// Checked before the daily count so throttled requests don't consume quota.
+	if err := s.checkResendCooldown(ctx, phone); err != nil {
+		return err
+	}
+
 	// Check daily limit.
 	if s.smsConfig.DailyLimit > 0 {
 		var count int
@@ -88,6 +141,7 @@ func (s *Service) RequestSMSCode(ctx context.Context, phone string) error {
 	if err != nil {
 		s.logger.Error("SMS daily count increment error", "error", err)
 	}
+	s.incrementSMSCountryStat(ctx, phone, "count")
 
 	// Generate OTP, store it, and send via SMS provider.
 	if err := s.sendOTPToPhone(ctx, phone, "Your code is: "); err != nil {
@@ -111,10 +165,12 @@ func (s *Service) ConfirmSMSCode(ctx context.Context, phone, code string) (*User
 
 	if err := s.validateSMSCodeForPhone(ctx, phone, code); err != nil {
 		s.incrementSMSStat(ctx, "fail_count")
+		s.incrementSMSCountryStat(ctx, phone, "fail_count")
 		return nil, "", "", err
 	}
 
 	s.incrementSMSStat(ctx, "confirm_count")
+	s.incrementSMSCountryStat(ctx, phone, "confirm_count")
 
 	// Find or create user by phone.
 	var user User
@@ -128,7 +184,7 @@ func (s *Service) ConfirmSMSCode(ctx context.Context, phone, code string) (*User
 		if _, err := rand.Read(randomPW); err != nil {
 			return nil, "", "", fmt.Errorf("generating random password: %w", err)
 		}
-		pwHash, err := hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
+		pwHash, err := s.hashPassword(base64.RawURLEncoding.EncodeToString(randomPW))
 		if err != nil {
 			return nil, "", "", fmt.Errorf("hashing placeholder password: %w", err)
 		}
@@ -156,25 +212,13 @@ func (s *Service) ConfirmSMSCode(ctx context.Context, phone, code string) (*User
 			}
 		} else {
 			s.logger.Info("user registered via SMS", "user_id", user.ID, "phone", phone)
+			s.publishEvent(EventSignup, user.ID)
 		}
 	} else if err != nil {
 		return nil, "", "", fmt.Errorf("querying user: %w", err)
 	}
 
-	// If user has MFA enrolled, return a pending token instead of full tokens.
-	hasMFA, err := s.HasSMSMFA(ctx, user.ID)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("checking MFA enrollment: %w", err)
-	}
-	if hasMFA {
-		pendingToken, err := s.generateMFAPendingToken(&user)
-		if err != nil {
-			return nil, "", "", fmt.Errorf("generating MFA pending token: %w", err)
-		}
-		return &user, pendingToken, "", nil
-	}
-
-	return s.issueTokens(ctx, &user)
+	return s.completeLogin(ctx, &user, false)
 }
 
 // --- Handler types and methods ---
@@ -183,6 +227,14 @@ type smsRequest struct {
 	Phone string `json:"phone"`
 }
 
+type smsValidateResponse struct {
+	Valid    bool   `json:"valid"`
+	Phone    string `json:"phone,omitempty"` // E.164
+	Country  string `json:"country,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Landline bool   `json:"landline"`
+}
+
 type smsConfirmRequest struct {
 	Phone string `json:"phone"`
 	Code  string `json:"code"`
@@ -209,8 +261,14 @@ func (h *Handler) handleSMSRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Always return 200 to prevent phone enumeration.
+	// Always return 200 to prevent phone enumeration. The resend cooldown is
+	// the exception: it applies to any phone that just received a code, so it
+	// reveals nothing about accounts and clients need it to pace retries.
 	if err := h.auth.RequestSMSCode(r.Context(), req.Phone); err != nil {
+		if errors.Is(err, ErrResendTooSoon) {
+			h.writeResendTooSoon(w)
+			return
+		}
 		if errors.Is(err, ErrDailyLimitExceeded) {
 			h.logger.Warn("SMS daily limit exceeded")
 		} else {
@@ -223,6 +281,97 @@ func (h *Handler) handleSMSRequest(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleSMSValidate reports whether a phone number passes the same validation
+// as handleSMSRequest, without sending anything, so clients can check input
+// before requesting a code. Invalid numbers are a 200 with valid=false.
+func (h *Handler) handleSMSValidate(w http.ResponseWriter, r *http.Request) {
+	var req smsRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Phone == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "phone is required")
+		return
+	}
+
+	info, err := sms.ParsePhone(req.Phone)
+	if err != nil {
+		httputil.WriteJSON(w, http.StatusOK, smsValidateResponse{})
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, smsValidateResponse{
+		Valid:    true,
+		Phone:    info.E164,
+		Country:  info.Country,
+		Type:     info.Type,
+		Landline: info.Landline(),
+	})
+}
+
+// writeResendTooSoon responds 429 with a Retry-After of the full cooldown,
+// an upper bound on how long the client has to wait.
+func (h *Handler) writeResendTooSoon(w http.ResponseWriter) {
+	retryAfter := int(math.Ceil(h.auth.smsConfig.ResendCooldown.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	httputil.WriteErrorWithDocURL(w, http.StatusTooManyRequests,
+		"please wait before requesting another code",
+		"https://allyourbase.io/guide/authentication#sms")
+}
+
+type smsStatusRequest struct {
+	MessageID string `json:"message_id"`
+	Status    string `json:"status"`
+	ErrorCode string `json:"error_code"`
+}
+
+// maxSMSStatusBody caps delivery receipt bodies, which are read in full
+// before the signature check.
+const maxSMSStatusBody = 64 << 10
+
+// HandleSMSStatus handles POST /api/auth/sms/status, a provider delivery
+// receipt signed with auth.sms_status_secret (hex HMAC-SHA256 of the body in
+// the X-Webhook-Signature header). It is mounted by the server outside the
+// per-IP auth rate limiter because receipts arrive in bursts from a few
+// provider addresses.
+func (h *Handler) HandleSMSStatus(w http.ResponseWriter, r *http.Request) {
+	secret := h.auth.smsConfig.StatusSecret
+	if !h.smsEnabled || secret == "" {
+		httputil.WriteErrorWithDocURL(w, http.StatusNotFound, "SMS delivery receipts are not enabled",
+			"https://allyourbase.io/guide/authentication#sms")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSMSStatusBody))
+	if err != nil {
+		httputil.WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if !sms.VerifySignature(secret, body, r.Header.Get(sms.SignatureHeader)) {
+		httputil.WriteError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	var req smsStatusRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Status == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "status is required")
+		return
+	}
+
+	h.auth.RecordSMSDeliveryStatus(r.Context(), req.Status)
+	if smsDeliveryColumn(req.Status) == "undelivered_count" {
+		h.logger.Warn("SMS not delivered", "message_id", req.MessageID, "status", req.Status, "error_code", req.ErrorCode)
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]string{})
+}
+
 func (h *Handler) handleSMSConfirm(w http.ResponseWriter, r *http.Request) {
 	if !h.smsEnabled {
 		httputil.WriteErrorWithDocURL(w, http.StatusNotFound, "SMS authentication is not enabled",
@@ -249,6 +398,10 @@ func (h *Handler) handleSMSConfirm(w http.ResponseWriter, r *http.Request) {
 			httputil.WriteError(w, http.StatusUnauthorized, "invalid or expired SMS code")
 			return
 		}
+		if errors.Is(err, ErrAccountDisabled) {
+			httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+			return
+		}
 		h.logger.Error("SMS confirm error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -262,18 +415,57 @@ func (h *Handler) handleSMSConfirm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, authResponse{
-		Token:        accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	})
+	h.writeAuthResponse(w, http.StatusOK, user, accessToken, refreshToken)
+}
+
+// incrementSMSCountryStat increments a stat column (count, confirm_count or
+// fail_count) in _ayb_sms_country_counts for today and the phone's country.
+func (s *Service) incrementSMSCountryStat(ctx context.Context, phone, column string) {
+	country := phoneCountry(phone)
+	if country == "" {
+		country = unknownSMSCountry
+	}
+	// column is always a compile-time constant, never user input.
+	query := fmt.Sprintf(
+		`INSERT INTO _ayb_sms_country_counts (date, country, %s) VALUES (CURRENT_DATE, $1, 1)
+		 ON CONFLICT (date, country) DO UPDATE SET %s = _ayb_sms_country_counts.%s + 1`,
+		column, column, column,
+	)
+	if _, err := s.pool.Exec(ctx, query, country); err != nil {
+		s.logger.Error("SMS country stat increment error", "column", column, "error", err)
+	}
+}
+
+// unknownSMSCountry is recorded for phones whose country cannot be determined.
+const unknownSMSCountry = "unknown"
+
+// RecordSMSDeliveryStatus counts a provider delivery receipt against today's
+// SMS stats. "delivered" and "failed"/"undelivered" are counted; intermediate
+// statuses such as "queued" or "sent" are ignored.
+func (s *Service) RecordSMSDeliveryStatus(ctx context.Context, status string) {
+	if column := smsDeliveryColumn(status); column != "" {
+		s.incrementSMSStat(ctx, column)
+	}
+}
+
+// smsDeliveryColumn maps a receipt status to its daily-count column, or ""
+// for statuses that are not final.
+func smsDeliveryColumn(status string) string {
+	switch strings.ToLower(status) {
+	case "delivered":
+		return "delivered_count"
+	case "failed", "undelivered":
+		return "undelivered_count"
+	}
+	return ""
 }
 
-// incrementSMSStat increments a stat column (confirm_count or fail_count) in
-// _ayb_sms_daily_counts for today. Uses upsert in case no row exists yet.
+// incrementSMSStat increments a stat column (confirm_count, fail_count,
+// delivered_count or undelivered_count) in _ayb_sms_daily_counts for today.
+// Uses upsert in case no row exists yet.
 func (s *Service) incrementSMSStat(ctx context.Context, column string) {
-	// column is always a compile-time constant ("confirm_count" or "fail_count"),
-	// never user input, so string interpolation is safe here.
+	// column is always a compile-time constant, never user input, so string
+	// interpolation is safe here.
 	query := fmt.Sprintf(
 		`INSERT INTO _ayb_sms_daily_counts (date, count, confirm_count, fail_count)
 		 VALUES (CURRENT_DATE, 0, 0, 0)
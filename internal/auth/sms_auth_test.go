@@ -6,7 +6,9 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/allyourbase/ayb/internal/sms"
 	"github.com/allyourbase/ayb/internal/testutil"
 )
 
@@ -15,7 +17,7 @@ import (
 func TestGenerateOTP(t *testing.T) {
 	t.Parallel()
 	for length := 4; length <= 8; length++ {
-		code, err := generateOTP(length)
+		code, err := generateOTP(length, otpNumericAlphabet)
 		testutil.NoError(t, err)
 		testutil.Equal(t, length, len(code))
 		for _, c := range code {
@@ -28,13 +30,49 @@ func TestGenerateOTPIsRandom(t *testing.T) {
 	t.Parallel()
 	seen := make(map[string]struct{})
 	for i := 0; i < 100; i++ {
-		code, err := generateOTP(6)
+		code, err := generateOTP(6, otpNumericAlphabet)
 		testutil.NoError(t, err)
 		seen[code] = struct{}{}
 	}
 	testutil.True(t, len(seen) > 50, "OTPs should not repeat heavily, got %d unique out of 100", len(seen))
 }
 
+func TestGenerateOTPAlphanumeric(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	svc.SetSMSConfig(sms.Config{CodeAlphabet: "alphanumeric"})
+	for i := 0; i < 50; i++ {
+		code, err := generateOTP(6, svc.otpAlphabet())
+		testutil.NoError(t, err)
+		testutil.Equal(t, 6, len(code))
+		for _, c := range code {
+			testutil.True(t, strings.ContainsRune(otpAlphanumericAlphabet, c), "unexpected character %c", c)
+		}
+	}
+	testutil.False(t, strings.ContainsAny(otpAlphanumericAlphabet, "01ILO"), "alphabet contains look-alike characters")
+}
+
+func TestOTPAlphabetDefaultsToNumeric(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	testutil.Equal(t, otpNumericAlphabet, svc.otpAlphabet())
+	testutil.Equal(t, "123456", svc.formatOTP("123456"))
+	testutil.Equal(t, "123 456", svc.normalizeOTP("123 456"))
+}
+
+func TestAlphanumericOTPFormatting(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	svc.SetSMSConfig(sms.Config{CodeAlphabet: "alphanumeric"})
+	testutil.Equal(t, "AB3D", svc.formatOTP("AB3D"))
+	testutil.Equal(t, "AB3-DEF", svc.formatOTP("AB3DEF"))
+	testutil.Equal(t, "AB3D-EFG", svc.formatOTP("AB3DEFG"))
+	// A code is accepted as sent, in any case and with or without grouping.
+	for _, entered := range []string{"AB3DEF", "AB3-DEF", "ab3-def", " ab3 def "} {
+		testutil.Equal(t, "AB3DEF", svc.normalizeOTP(entered))
+	}
+}
+
 // --- Phone normalization ---
 
 func TestNormalizePhone(t *testing.T) {
@@ -296,6 +334,120 @@ func TestHandleSMSRequest_MalformedJSON_Returns400(t *testing.T) {
 	testutil.Contains(t, w.Body.String(), "invalid JSON body")
 }
 
+func TestHandleSMSValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		enabled  bool
+		body     string
+		code     int
+		contains []string
+	}{
+		{"disabled", false, `{"phone":"+14155552671"}`, http.StatusNotFound, []string{"not enabled"}},
+		{"missing phone", true, `{}`, http.StatusBadRequest, []string{"phone is required"}},
+		{"invalid", true, `{"phone":"+449999999999"}`, http.StatusOK, []string{`"valid":false`}},
+		{"mobile", true, `{"phone":"+44 7400 123456"}`, http.StatusOK,
+			[]string{`"valid":true`, `"phone":"+447400123456"`, `"country":"GB"`, `"type":"mobile"`, `"landline":false`}},
+		{"landline", true, `{"phone":"+442079460958"}`, http.StatusOK,
+			[]string{`"type":"landline"`, `"landline":true`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newSMSHandler(tt.enabled).Routes()
+			req := httptest.NewRequest(http.MethodPost, "/sms/validate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			testutil.Equal(t, tt.code, w.Code)
+			for _, s := range tt.contains {
+				testutil.Contains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestCheckResendCooldown_SkipsWithoutQuerying(t *testing.T) {
+	// The service has no pool, so any query would panic.
+	t.Parallel()
+	svc := newTestService()
+	testutil.NoError(t, svc.checkResendCooldown(t.Context(), "+14155552671"))
+
+	svc.SetSMSConfig(sms.Config{
+		ResendCooldown:   30 * time.Second,
+		TestPhoneNumbers: map[string]string{"+15550000000": "123456"},
+	})
+	testutil.NoError(t, svc.checkResendCooldown(t.Context(), "+15550000000"))
+}
+
+func TestWriteResendTooSoon(t *testing.T) {
+	t.Parallel()
+	h := newSMSHandler(true)
+	h.auth.SetSMSConfig(sms.Config{ResendCooldown: 1500 * time.Millisecond})
+
+	w := httptest.NewRecorder()
+	h.writeResendTooSoon(w)
+
+	testutil.Equal(t, http.StatusTooManyRequests, w.Code)
+	testutil.Equal(t, "2", w.Header().Get("Retry-After"))
+	testutil.Contains(t, w.Body.String(), "please wait")
+}
+
+func newSMSStatusRequest(secret, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/sms/status", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(sms.SignatureHeader, sms.Sign(secret, []byte(body)))
+	}
+	return req
+}
+
+func TestHandleSMSStatus(t *testing.T) {
+	t.Parallel()
+	const secret = "status-secret"
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		secret   string // configured StatusSecret
+		signWith string
+		body     string
+		code     int
+		contains string
+	}{
+		{"sms disabled", false, secret, secret, `{"status":"sent"}`, http.StatusNotFound, "not enabled"},
+		{"no status secret", true, "", secret, `{"status":"sent"}`, http.StatusNotFound, "not enabled"},
+		{"unsigned", true, secret, "", `{"status":"sent"}`, http.StatusUnauthorized, "invalid signature"},
+		{"wrong secret", true, secret, "other", `{"status":"sent"}`, http.StatusUnauthorized, "invalid signature"},
+		{"malformed JSON", true, secret, secret, `{bad`, http.StatusBadRequest, "invalid JSON body"},
+		{"missing status", true, secret, secret, `{"message_id":"m1"}`, http.StatusBadRequest, "status is required"},
+		// Intermediate statuses are acknowledged without touching the database.
+		{"intermediate status", true, secret, secret, `{"message_id":"m1","status":"sent"}`, http.StatusOK, "{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newSMSHandler(tt.enabled)
+			h.auth.SetSMSConfig(sms.Config{StatusSecret: tt.secret})
+
+			w := httptest.NewRecorder()
+			h.HandleSMSStatus(w, newSMSStatusRequest(tt.signWith, tt.body))
+
+			testutil.Equal(t, tt.code, w.Code)
+			testutil.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+}
+
+func TestSMSDeliveryColumn(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "delivered_count", smsDeliveryColumn("delivered"))
+	testutil.Equal(t, "delivered_count", smsDeliveryColumn("DELIVERED"))
+	testutil.Equal(t, "undelivered_count", smsDeliveryColumn("failed"))
+	testutil.Equal(t, "undelivered_count", smsDeliveryColumn("undelivered"))
+	testutil.Equal(t, "", smsDeliveryColumn("queued"))
+	testutil.Equal(t, "", smsDeliveryColumn("sent"))
+}
+
 // --- SMS confirm handler ---
 
 func TestHandleSMSConfirm_Disabled_Returns404(t *testing.T) {
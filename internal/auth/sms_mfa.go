@@ -2,10 +2,9 @@ package auth
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/sms"
@@ -16,31 +15,53 @@ import (
 
 var ErrMFAAlreadyEnrolled = errors.New("SMS MFA already enrolled")
 
-const mfaPendingTokenDur = 5 * time.Minute
-
-// generateMFAPendingToken issues a short-lived JWT (5 min) with MFAPending: true.
-// This token grants access only to the MFA challenge/verify endpoints, not normal routes.
-func (s *Service) generateMFAPendingToken(user *User) (string, error) {
-	now := time.Now()
-	jti := make([]byte, 16)
-	if _, err := rand.Read(jti); err != nil {
-		return "", fmt.Errorf("generating jti: %w", err)
-	}
-	claims := &Claims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   user.ID,
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(mfaPendingTokenDur)),
-			ID:        hex.EncodeToString(jti),
-		},
-		Email:      user.Email,
-		MFAPending: true,
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	s.jwtSecretMu.RLock()
-	secret := s.jwtSecret
-	s.jwtSecretMu.RUnlock()
-	return token.SignedString(secret)
+// ErrMFAChallengeToken is returned by ValidateToken for an MFA challenge
+// token, which is only accepted by the MFA challenge and verify endpoints.
+var ErrMFAChallengeToken = errors.New("MFA verification required")
+
+// ErrMFAChallengeLocked is returned when an MFA challenge token has used up
+// its verification attempts. The login has to be restarted.
+var ErrMFAChallengeLocked = errors.New("too many failed MFA attempts")
+
+const (
+	mfaPendingTokenDur = 5 * time.Minute
+
+	// tokenPurposeMFAChallenge is the purpose claim of MFA challenge tokens.
+	tokenPurposeMFAChallenge = "mfa_challenge"
+	// mfaChallengeAudience is the aud of MFA challenge tokens, so resource
+	// servers that check aud never take one for an access token.
+	mfaChallengeAudience = "ayb:mfa_challenge"
+	// mfaChallengeMaxFailures is how many wrong codes one challenge token may
+	// submit, across all codes sent with it, before it is rejected.
+	mfaChallengeMaxFailures = 5
+)
+
+// generateMFAChallengeToken issues the short-lived (5 min) token returned as
+// mfa_token after the first factor of an MFA-enrolled login. It carries a
+// purpose and audience of its own: ValidateToken rejects it, so it only
+// grants access to the MFA challenge/verify endpoints, where verify exchanges
+// it for full tokens. rememberMe is carried through so the session issued
+// after verification honors it.
+func (s *Service) generateMFAChallengeToken(user *User, rememberMe bool) (string, error) {
+	rc, err := s.registeredClaims(user.ID, mfaPendingTokenDur)
+	if err != nil {
+		return "", err
+	}
+	rc.Audience = jwt.ClaimStrings{mfaChallengeAudience}
+	return s.signToken(&Claims{RegisteredClaims: rc, Purpose: tokenPurposeMFAChallenge, RememberMe: rememberMe})
+}
+
+// ValidateMFAChallengeToken validates a token issued by
+// generateMFAChallengeToken. Access tokens are rejected.
+func (s *Service) ValidateMFAChallengeToken(tokenString string) (*Claims, error) {
+	claims, _, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != tokenPurposeMFAChallenge || !slices.Contains(claims.Audience, mfaChallengeAudience) {
+		return nil, errors.New("invalid token: not an MFA challenge token")
+	}
+	return claims, nil
 }
 
 // HasSMSMFA checks whether a user has an enabled SMS MFA enrollment.
@@ -72,6 +93,10 @@ func (s *Service) EnrollSMSMFA(ctx context.Context, userID, phone string) error
 		return ErrMFAAlreadyEnrolled
 	}
 
+	if err := s.checkResendCooldown(ctx, phone); err != nil {
+		return err
+	}
+
 	// Upsert the enrollment row (disabled until confirmed).
 	_, err = s.pool.Exec(ctx,
 		`INSERT INTO _ayb_user_mfa (user_id, method, phone, enabled)
@@ -110,35 +135,105 @@ func (s *Service) ConfirmSMSMFAEnrollment(ctx context.Context, userID, phone, co
 		return fmt.Errorf("no MFA enrollment found for user")
 	}
 
+	s.publishEvent(EventMFAEnrolled, userID)
 	return nil
 }
 
-// ChallengeSMSMFA sends an OTP to the user's enrolled MFA phone number.
-func (s *Service) ChallengeSMSMFA(ctx context.Context, userID string) error {
-	phone, err := s.mfaEnrolledPhone(ctx, userID)
+// ChallengeSMSMFA sends an OTP to the enrolled MFA phone number of the user
+// the challenge token was issued to.
+func (s *Service) ChallengeSMSMFA(ctx context.Context, challenge *Claims) error {
+	if err := s.checkMFAChallenge(ctx, challenge); err != nil {
+		return err
+	}
+	phone, err := s.mfaEnrolledPhone(ctx, challenge.Subject)
 	if err != nil {
 		return err
 	}
+	if err := s.checkResendCooldown(ctx, phone); err != nil {
+		return err
+	}
 	return s.sendOTPToPhone(ctx, phone, "Your verification code is: ")
 }
 
-// VerifySMSMFA verifies the MFA challenge OTP and issues full tokens.
-func (s *Service) VerifySMSMFA(ctx context.Context, userID, code string) (*User, string, string, error) {
-	phone, err := s.mfaEnrolledPhone(ctx, userID)
+// VerifySMSMFA verifies the MFA challenge OTP and issues full tokens, honoring
+// the remember-me choice carried on the challenge token. Each wrong code counts
+// against the token; once it reaches mfaChallengeMaxFailures the pending code
+// is discarded and ErrMFAChallengeLocked is returned from then on.
+func (s *Service) VerifySMSMFA(ctx context.Context, challenge *Claims, code string) (*User, string, string, error) {
+	if err := s.checkMFAChallenge(ctx, challenge); err != nil {
+		return nil, "", "", err
+	}
+	phone, err := s.mfaEnrolledPhone(ctx, challenge.Subject)
 	if err != nil {
 		return nil, "", "", err
 	}
 
 	if err := s.validateSMSCodeForPhone(ctx, phone, code); err != nil {
+		if errors.Is(err, ErrInvalidSMSCode) {
+			if lockErr := s.recordMFAChallengeFailure(ctx, challenge, phone); lockErr != nil {
+				return nil, "", "", lockErr
+			}
+		}
 		return nil, "", "", err
 	}
 
-	user, err := s.UserByID(ctx, userID)
+	if err := s.checkAccountEnabled(ctx, challenge.Subject); err != nil {
+		return nil, "", "", err
+	}
+	user, err := s.UserByID(ctx, challenge.Subject)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("looking up user: %w", err)
 	}
 
-	return s.issueTokens(ctx, user)
+	return s.issueTokens(ctx, user, challenge.RememberMe)
+}
+
+// checkMFAChallenge returns ErrMFAChallengeLocked if the challenge token has
+// reached the failure limit.
+func (s *Service) checkMFAChallenge(ctx context.Context, challenge *Claims) error {
+	var locked bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM _ayb_mfa_challenge_failures WHERE token_id = $1 AND failures >= $2)`,
+		challenge.ID, mfaChallengeMaxFailures,
+	).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("checking MFA challenge failures: %w", err)
+	}
+	if locked {
+		return ErrMFAChallengeLocked
+	}
+	return nil
+}
+
+// recordMFAChallengeFailure counts a wrong code against the challenge token.
+// When the token reaches the failure limit, the code pending for phone is
+// deleted and ErrMFAChallengeLocked is returned.
+func (s *Service) recordMFAChallengeFailure(ctx context.Context, challenge *Claims, phone string) error {
+	// Rows for expired tokens are no longer needed.
+	_, _ = s.pool.Exec(ctx, `DELETE FROM _ayb_mfa_challenge_failures WHERE expires_at < NOW()`)
+
+	expiresAt := time.Now().Add(mfaPendingTokenDur)
+	if challenge.ExpiresAt != nil {
+		expiresAt = challenge.ExpiresAt.Time
+	}
+	var failures int
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO _ayb_mfa_challenge_failures (token_id, failures, expires_at)
+		 VALUES ($1, 1, $2)
+		 ON CONFLICT (token_id) DO UPDATE SET failures = _ayb_mfa_challenge_failures.failures + 1
+		 RETURNING failures`,
+		challenge.ID, expiresAt,
+	).Scan(&failures)
+	if err != nil {
+		return fmt.Errorf("recording MFA challenge failure: %w", err)
+	}
+	if failures < mfaChallengeMaxFailures {
+		return nil
+	}
+
+	_, _ = s.pool.Exec(ctx, `DELETE FROM _ayb_sms_codes WHERE phone = $1`, phone)
+	s.logger.Warn("MFA challenge locked after too many failed attempts", "user_id", challenge.Subject)
+	return ErrMFAChallengeLocked
 }
 
 // mfaEnrolledPhone looks up the enrolled MFA phone for a user.
@@ -159,7 +254,7 @@ func (s *Service) mfaEnrolledPhone(ctx context.Context, userID string) (string,
 
 // storeOTPCode hashes the code and stores it in _ayb_sms_codes for the given phone.
 func (s *Service) storeOTPCode(ctx context.Context, phone, code string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(s.normalizeOTP(code)), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("hashing OTP: %w", err)
 	}
@@ -180,6 +275,30 @@ func (s *Service) storeOTPCode(ctx context.Context, phone, code string) error {
 	return nil
 }
 
+// checkResendCooldown returns ErrResendTooSoon if a code was sent to phone
+// within the configured resend cooldown. Test phone numbers are exempt.
+func (s *Service) checkResendCooldown(ctx context.Context, phone string) error {
+	if s.smsConfig.ResendCooldown <= 0 {
+		return nil
+	}
+	if _, ok := s.smsConfig.TestPhoneNumbers[phone]; ok {
+		return nil
+	}
+	var recent bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM _ayb_sms_codes
+		 WHERE phone = $1 AND created_at > NOW() - make_interval(secs => $2))`,
+		phone, s.smsConfig.ResendCooldown.Seconds(),
+	).Scan(&recent)
+	if err != nil {
+		return fmt.Errorf("checking SMS resend cooldown: %w", err)
+	}
+	if recent {
+		return ErrResendTooSoon
+	}
+	return nil
+}
+
 // sendOTPToPhone generates an OTP, stores it in _ayb_sms_codes, and sends it via SMS.
 // The msgPrefix is prepended to the OTP code in the SMS body.
 // For test phone numbers (configured in sms.Config.TestPhoneNumbers), the predetermined
@@ -194,7 +313,7 @@ func (s *Service) sendOTPToPhone(ctx context.Context, phone, msgPrefix string) e
 	if codeLen < 4 {
 		codeLen = 6
 	}
-	otp, err := generateOTP(codeLen)
+	otp, err := generateOTP(codeLen, s.otpAlphabet())
 	if err != nil {
 		return fmt.Errorf("generating OTP: %w", err)
 	}
@@ -204,7 +323,7 @@ func (s *Service) sendOTPToPhone(ctx context.Context, phone, msgPrefix string) e
 	}
 
 	if s.smsProvider != nil {
-		if _, err := s.smsProvider.Send(ctx, phone, msgPrefix+otp); err != nil {
+		if _, err := s.smsProvider.Send(ctx, phone, msgPrefix+s.formatOTP(otp)); err != nil {
 			return fmt.Errorf("sending OTP: %w", err)
 		}
 	}
@@ -236,7 +355,7 @@ func (s *Service) validateSMSCodeForPhone(ctx context.Context, phone, code strin
 		return fmt.Errorf("querying SMS code: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(codeHash), []byte(code)); err != nil {
+	if err := bcrypt.CompareHashAndPassword([]byte(codeHash), []byte(s.normalizeOTP(code))); err != nil {
 		_, _ = s.pool.Exec(ctx,
 			`UPDATE _ayb_sms_codes SET attempts = attempts + 1 WHERE id = $1`, codeID)
 		var newAttempts int
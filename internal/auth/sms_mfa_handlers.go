@@ -53,6 +53,8 @@ func (h *Handler) handleMFAEnroll(w http.ResponseWriter, r *http.Request) {
 			httputil.WriteError(w, http.StatusBadRequest, "invalid phone number format")
 		case errors.Is(err, ErrMFAAlreadyEnrolled):
 			httputil.WriteError(w, http.StatusConflict, "SMS MFA already enrolled")
+		case errors.Is(err, ErrResendTooSoon):
+			h.writeResendTooSoon(w)
 		default:
 			h.logger.Error("MFA enroll error", "error", err)
 			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
@@ -119,7 +121,15 @@ func (h *Handler) handleMFAChallenge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.auth.ChallengeSMSMFA(r.Context(), claims.Subject); err != nil {
+	if err := h.auth.ChallengeSMSMFA(r.Context(), claims); err != nil {
+		if errors.Is(err, ErrResendTooSoon) {
+			h.writeResendTooSoon(w)
+			return
+		}
+		if errors.Is(err, ErrMFAChallengeLocked) {
+			writeMFAChallengeLocked(w)
+			return
+		}
 		h.logger.Error("MFA challenge error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
@@ -152,20 +162,30 @@ func (h *Handler) handleMFAVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.auth.VerifySMSMFA(r.Context(), claims.Subject, req.Code)
+	user, accessToken, refreshToken, err := h.auth.VerifySMSMFA(r.Context(), claims, req.Code)
 	if err != nil {
 		if errors.Is(err, ErrInvalidSMSCode) {
 			httputil.WriteError(w, http.StatusUnauthorized, "invalid or expired code")
 			return
 		}
+		if errors.Is(err, ErrMFAChallengeLocked) {
+			writeMFAChallengeLocked(w)
+			return
+		}
+		if errors.Is(err, ErrAccountDisabled) {
+			httputil.WriteError(w, http.StatusForbidden, "account is disabled")
+			return
+		}
 		h.logger.Error("MFA verify error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, authResponse{
-		Token:        accessToken,
-		RefreshToken: refreshToken,
-		User:         user,
-	})
+	h.writeAuthResponse(w, http.StatusOK, user, accessToken, refreshToken)
+}
+
+// writeMFAChallengeLocked responds to a request made with an MFA challenge
+// token that has used up its verification attempts.
+func writeMFAChallengeLocked(w http.ResponseWriter) {
+	httputil.WriteError(w, http.StatusUnauthorized, "too many failed attempts; sign in again")
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,15 +18,14 @@ func TestGenerateMFAPendingToken(t *testing.T) {
 	svc := newTestService()
 	user := &User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"}
 
-	token, err := svc.generateMFAPendingToken(user)
+	token, err := svc.generateMFAChallengeToken(user, false)
 	testutil.NoError(t, err)
 	testutil.True(t, token != "", "token should not be empty")
 
-	claims, err := svc.ValidateToken(token)
+	claims, err := svc.ValidateMFAChallengeToken(token)
 	testutil.NoError(t, err)
 	testutil.Equal(t, user.ID, claims.Subject)
-	testutil.Equal(t, user.Email, claims.Email)
-	testutil.True(t, claims.MFAPending, "MFAPending should be true")
+	testutil.Equal(t, tokenPurposeMFAChallenge, claims.Purpose)
 	testutil.NotNil(t, claims.ExpiresAt)
 	testutil.NotNil(t, claims.IssuedAt)
 
@@ -37,12 +37,30 @@ func TestGenerateMFAPendingToken(t *testing.T) {
 		"MFA pending token should expire in ~5 min, got %v", dur)
 }
 
+func TestMFAPendingTokenCarriesRememberMe(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	user := &User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"}
+
+	token, err := svc.generateMFAChallengeToken(user, true)
+	testutil.NoError(t, err)
+	claims, err := svc.ValidateMFAChallengeToken(token)
+	testutil.NoError(t, err)
+	testutil.True(t, claims.RememberMe, "RememberMe should survive the MFA step")
+
+	token, err = svc.generateMFAChallengeToken(user, false)
+	testutil.NoError(t, err)
+	claims, err = svc.ValidateMFAChallengeToken(token)
+	testutil.NoError(t, err)
+	testutil.False(t, claims.RememberMe, "RememberMe should default to false")
+}
+
 func TestMFAPendingToken_RejectedByRequireAuth(t *testing.T) {
 	t.Parallel()
 	svc := newTestService()
 	user := &User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"}
 
-	token, err := svc.generateMFAPendingToken(user)
+	token, err := svc.generateMFAChallengeToken(user, false)
 	testutil.NoError(t, err)
 
 	called := false
@@ -59,8 +77,48 @@ func TestMFAPendingToken_RejectedByRequireAuth(t *testing.T) {
 	testutil.Equal(t, http.StatusUnauthorized, w.Code)
 	testutil.False(t, called, "handler should not be called for MFA pending token")
 
-	var resp map[string]any
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
-	msg, _ := resp["message"].(string)
-	testutil.Contains(t, msg, "MFA verification required")
+	testutil.Contains(t, resp.Error.Message, "MFA verification required")
+}
+
+func TestValidateMFAChallengeTokenRejectsAccessToken(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	token, err := svc.generateToken(&User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"})
+	testutil.NoError(t, err)
+
+	_, err = svc.ValidateMFAChallengeToken(token)
+	testutil.ErrorContains(t, err, "not an MFA challenge token")
+}
+
+func TestMFAChallengeTokenRejectedOnMe(t *testing.T) {
+	t.Parallel()
+	svc := newTestService()
+	h := NewHandler(svc, testutil.DiscardLogger())
+	h.SetSMSEnabled(true)
+	router := h.Routes()
+
+	token, err := svc.generateMFAChallengeToken(&User{ID: "550e8400-e29b-41d4-a716-446655440000", Email: "mfa@example.com"}, false)
+	testutil.NoError(t, err)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/me"},
+		{http.MethodPatch, "/me"},
+		{http.MethodDelete, "/me"},
+		{http.MethodPost, "/verify/resend"},
+		{http.MethodPost, "/mfa/sms/enroll"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		testutil.Equal(t, http.StatusUnauthorized, w.Code)
+		testutil.Contains(t, w.Body.String(), "MFA verification required")
+	}
 }
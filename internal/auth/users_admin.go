@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// checkAccountEnabled returns ErrAccountDisabled if the user has been
+// disabled by an admin.
+func (s *Service) checkAccountEnabled(ctx context.Context, userID string) error {
+	var disabled bool
+	err := s.pool.QueryRow(ctx,
+		`SELECT disabled_at IS NOT NULL FROM _ayb_users WHERE id = $1`, userID,
+	).Scan(&disabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("checking account status: %w", err)
+	}
+	if disabled {
+		return ErrAccountDisabled
+	}
+	return nil
+}
+
+// SetUserDisabled disables or re-enables a user. A disabled user keeps their
+// data but can't log in or refresh a session; disabling also signs them out
+// everywhere, as LogoutUser does. Access tokens already issued stay valid
+// until they expire.
+func (s *Service) SetUserDisabled(ctx context.Context, id string, disabled bool) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	result, err := tx.Exec(ctx,
+		`UPDATE _ayb_users
+		 SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, NOW()) END, updated_at = NOW()
+		 WHERE id = $1`,
+		id, disabled,
+	)
+	if err != nil {
+		return fmt.Errorf("updating user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	if disabled {
+		if _, err := revokeUserSessions(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing user update: %w", err)
+	}
+
+	if disabled {
+		s.logger.Info("user disabled by admin", "user_id", id)
+	} else {
+		s.logger.Info("user enabled by admin", "user_id", id)
+	}
+	return nil
+}
+
+// LogoutUser signs a user out everywhere by revoking all their refresh
+// tokens: their sessions and any OAuth refresh tokens issued to third-party
+// clients on their behalf. It returns the number of sessions revoked.
+func (s *Service) LogoutUser(ctx context.Context, id string) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM _ayb_users WHERE id = $1)`, id,
+	).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("querying user: %w", err)
+	}
+	if !exists {
+		return 0, ErrUserNotFound
+	}
+	n, err := revokeUserSessions(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing logout: %w", err)
+	}
+
+	s.logger.Info("user logged out by admin", "user_id", id, "sessions", n)
+	s.publishEvent(EventLogout, id)
+	return n, nil
+}
+
+// revokeUserSessions deletes the user's sessions and revokes their OAuth
+// tokens, returning the number of sessions deleted.
+func revokeUserSessions(ctx context.Context, tx pgx.Tx, userID string) (int64, error) {
+	result, err := tx.Exec(ctx, `DELETE FROM _ayb_sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("deleting sessions: %w", err)
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE _ayb_oauth_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("revoking OAuth tokens: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// UserFilter selects users for DeleteUsers. Set fields are combined with AND;
+// at least one must be set.
+type UserFilter struct {
+	Search        string    // email contains (case-insensitive)
+	Disabled      bool      // only disabled users
+	Unverified    bool      // only users whose email is not verified
+	CreatedBefore time.Time // only users created before this time
+}
+
+// where returns the SQL condition selecting the filtered users, with its
+// arguments numbered from $1.
+func (f UserFilter) where() (string, []any) {
+	var conds []string
+	var args []any
+	if f.Search != "" {
+		args = append(args, "%"+f.Search+"%")
+		conds = append(conds, fmt.Sprintf("email ILIKE $%d", len(args)))
+	}
+	if f.Disabled {
+		conds = append(conds, "disabled_at IS NOT NULL")
+	}
+	if f.Unverified {
+		conds = append(conds, "NOT email_verified")
+	}
+	if !f.CreatedBefore.IsZero() {
+		args = append(args, f.CreatedBefore)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// CountUsers returns how many users match filter, i.e. how many DeleteUsers
+// would delete.
+func (s *Service) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	where, args := filter.where()
+	if where == "" {
+		return 0, fmt.Errorf("%w: at least one filter is required", ErrValidation)
+	}
+	var n int64
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM _ayb_users WHERE `+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting users: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteUsers deletes every user matching filter, as DeleteUser does for one
+// user, and returns how many were deleted.
+func (s *Service) DeleteUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	where, args := filter.where()
+	if where == "" {
+		return 0, fmt.Errorf("%w: at least one filter is required", ErrValidation)
+	}
+	n, err := s.deleteUsers(ctx, where, args...)
+	if err != nil {
+		return 0, err
+	}
+	s.logger.Info("users deleted by admin", "count", n)
+	return n, nil
+}
+
+// deleteUsers deletes the users matching the SQL condition where in one
+// transaction and returns how many were deleted.
+func (s *Service) deleteUsers(ctx context.Context, where string, args ...any) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Revoke active app-scoped keys and detach all keys from the users' apps.
+	// This satisfies the ON DELETE RESTRICT FK on api_keys.app_id so that the
+	// subsequent CASCADE delete of _ayb_apps rows can succeed.
+	_, err = tx.Exec(ctx,
+		`UPDATE _ayb_api_keys
+		 SET revoked_at = COALESCE(revoked_at, NOW()), app_id = NULL
+		 WHERE app_id IN (SELECT id FROM _ayb_apps
+		                  WHERE owner_user_id IN (SELECT id FROM _ayb_users WHERE `+where+`))`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("detaching app keys before user delete: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM _ayb_users WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("deleting users: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing user delete: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestUserFilterWhere(t *testing.T) {
+	t.Parallel()
+	where, args := UserFilter{}.where()
+	testutil.Equal(t, "", where)
+	testutil.SliceLen(t, args, 0)
+
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = UserFilter{Search: "example.com", Disabled: true, Unverified: true, CreatedBefore: before}.where()
+	testutil.Equal(t, "email ILIKE $1 AND disabled_at IS NOT NULL AND NOT email_verified AND created_at < $2", where)
+	testutil.SliceLen(t, args, 2)
+	testutil.Equal(t, any("%example.com%"), args[0])
+	testutil.Equal(t, any(before), args[1])
+
+	where, args = UserFilter{CreatedBefore: before}.where()
+	testutil.Equal(t, "created_at < $1", where)
+	testutil.SliceLen(t, args, 1)
+}
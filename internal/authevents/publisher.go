@@ -0,0 +1,162 @@
+// Package authevents delivers auth events (signups, logins, ...) to a
+// configured webhook, directly or through the job queue.
+package authevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/webhooks"
+)
+
+// JobType is the job queue type of auth event deliveries.
+const JobType = "auth_event_delivery"
+
+const (
+	queueSize  = 256
+	maxRetries = 3
+)
+
+// defaultBackoff holds the retry delays for direct delivery.
+var defaultBackoff = [maxRetries]time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	25 * time.Second,
+}
+
+// JobQueue enqueues jobs. jobs.Service satisfies this interface.
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload json.RawMessage, opts jobs.EnqueueOpts) (*jobs.Job, error)
+}
+
+// Publisher implements auth.EventPublisher. Events are queued in memory and
+// handed off by a background worker, so publishing never blocks the auth
+// request. With a job queue set each event becomes a job, and the queue's
+// retries apply; otherwise the worker POSTs the event itself.
+type Publisher struct {
+	url     string
+	secret  string
+	client  *http.Client
+	logger  *slog.Logger
+	jobs    JobQueue // optional — nil delivers directly
+	queue   chan auth.Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+	backoff [maxRetries]time.Duration // per-instance; tests override without touching globals
+}
+
+// NewPublisher creates a Publisher delivering to url, signing with secret if
+// it isn't empty, and starts its background worker.
+func NewPublisher(url, secret string, logger *slog.Logger) *Publisher {
+	p := &Publisher{
+		url:     url,
+		secret:  secret,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  logger,
+		queue:   make(chan auth.Event, queueSize),
+		done:    make(chan struct{}),
+		backoff: defaultBackoff,
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+// SetJobQueue routes events through the job queue. Register Deliver as the
+// JobType handler on the same queue. Call before publishing starts.
+func (p *Publisher) SetJobQueue(q JobQueue) {
+	p.jobs = q
+}
+
+// PublishAuthEvent queues event for delivery.
+// Non-blocking: drops the event if the queue is full.
+func (p *Publisher) PublishAuthEvent(event auth.Event) {
+	select {
+	case p.queue <- event:
+	default:
+		p.logger.Warn("auth event queue full, dropping event", "type", event.Type, "user_id", event.UserID)
+	}
+}
+
+// Close signals the worker to stop and waits for it to finish. Events still
+// queued are dropped.
+func (p *Publisher) Close() {
+	close(p.done)
+	p.wg.Wait()
+}
+
+func (p *Publisher) run() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.done:
+			return
+		case event := <-p.queue:
+			p.handle(event)
+		}
+	}
+}
+
+func (p *Publisher) handle(event auth.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		p.logger.Error("failed to marshal auth event", "error", err)
+		return
+	}
+
+	if p.jobs != nil {
+		if _, err := p.jobs.Enqueue(context.Background(), JobType, payload, jobs.EnqueueOpts{}); err != nil {
+			p.logger.Error("failed to enqueue auth event", "type", event.Type, "error", err)
+		}
+		return
+	}
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-p.done:
+				return
+			case <-time.After(p.backoff[attempt]):
+			}
+		}
+		err := p.Deliver(context.Background(), payload)
+		if err == nil {
+			return
+		}
+		p.logger.Warn("auth event delivery failed", "type", event.Type, "attempt", attempt+1, "error", err)
+	}
+	p.logger.Error("auth event delivery exhausted retries", "type", event.Type, "user_id", event.UserID)
+}
+
+// Deliver POSTs one event payload to the webhook. It is the JobType job
+// handler.
+func (p *Publisher) Deliver(ctx context.Context, payload json.RawMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secret != "" {
+		req.Header.Set("X-AYB-Signature", webhooks.Sign(p.secret, payload))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package authevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/allyourbase/ayb/internal/webhooks"
+)
+
+type received struct {
+	body      []byte
+	signature string
+}
+
+// hookServer records the requests it receives, failing the first failures.
+func hookServer(t *testing.T, failures int) (*httptest.Server, chan received) {
+	t.Helper()
+	ch := make(chan received, 10)
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		fail := failures > 0
+		failures--
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		ch <- received{body: body, signature: r.Header.Get("X-AYB-Signature")}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func waitReceived(t *testing.T, ch chan received) received {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+		return received{}
+	}
+}
+
+func TestPublisherDeliversSignedEvent(t *testing.T) {
+	t.Parallel()
+	srv, ch := hookServer(t, 1)
+	p := NewPublisher(srv.URL, "s3cret", testutil.DiscardLogger())
+	p.backoff = [maxRetries]time.Duration{0, 10 * time.Millisecond, 10 * time.Millisecond}
+	defer p.Close()
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	p.PublishAuthEvent(auth.Event{Type: auth.EventLogin, UserID: "u1", Timestamp: ts})
+
+	r := waitReceived(t, ch)
+	testutil.Equal(t, webhooks.Sign("s3cret", r.body), r.signature)
+	var got map[string]any
+	testutil.NoError(t, json.Unmarshal(r.body, &got))
+	testutil.Equal(t, "auth.login", got["type"].(string))
+	testutil.Equal(t, "u1", got["userId"].(string))
+	testutil.Equal(t, "2026-01-02T03:04:05Z", got["timestamp"].(string))
+}
+
+func TestPublisherUnsignedWithoutSecret(t *testing.T) {
+	t.Parallel()
+	srv, ch := hookServer(t, 0)
+	p := NewPublisher(srv.URL, "", testutil.DiscardLogger())
+	defer p.Close()
+
+	p.PublishAuthEvent(auth.Event{Type: auth.EventSignup, UserID: "u1"})
+	testutil.Equal(t, "", waitReceived(t, ch).signature)
+}
+
+type fakeJobQueue struct {
+	mu   sync.Mutex
+	jobs []json.RawMessage
+	typ  string
+}
+
+func (q *fakeJobQueue) Enqueue(_ context.Context, jobType string, payload json.RawMessage, _ jobs.EnqueueOpts) (*jobs.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.typ = jobType
+	q.jobs = append(q.jobs, payload)
+	return &jobs.Job{Type: jobType, Payload: payload}, nil
+}
+
+func (q *fakeJobQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+func TestPublisherEnqueuesJobs(t *testing.T) {
+	t.Parallel()
+	srv, ch := hookServer(t, 0)
+	p := NewPublisher(srv.URL, "s3cret", testutil.DiscardLogger())
+	q := &fakeJobQueue{}
+	p.SetJobQueue(q)
+	defer p.Close()
+
+	p.PublishAuthEvent(auth.Event{Type: auth.EventLogout, UserID: "u1"})
+	deadline := time.Now().Add(5 * time.Second)
+	for q.len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	testutil.Equal(t, 1, q.len())
+	testutil.Equal(t, JobType, q.typ)
+	select {
+	case <-ch:
+		t.Fatal("event delivered directly despite job queue")
+	default:
+	}
+
+	// The job handler delivers the payload.
+	testutil.NoError(t, p.Deliver(context.Background(), q.jobs[0]))
+	r := waitReceived(t, ch)
+	testutil.Equal(t, string(q.jobs[0]), string(r.body))
+	testutil.Equal(t, webhooks.Sign("s3cret", r.body), r.signature)
+}
+
+func TestDeliverReportsFailure(t *testing.T) {
+	t.Parallel()
+	srv, _ := hookServer(t, 1)
+	p := NewPublisher(srv.URL, "", testutil.DiscardLogger())
+	defer p.Close()
+
+	err := p.Deliver(context.Background(), json.RawMessage(`{}`))
+	testutil.ErrorContains(t, err, "status 503")
+}
+
+func TestPublishDoesNotBlockWhenQueueFull(t *testing.T) {
+	t.Parallel()
+	// No worker: nothing drains the queue.
+	p := &Publisher{logger: testutil.DiscardLogger(), queue: make(chan auth.Event, 1)}
+	done := make(chan struct{})
+	go func() {
+		p.PublishAuthEvent(auth.Event{Type: auth.EventLogin})
+		p.PublishAuthEvent(auth.Event{Type: auth.EventLogin})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PublishAuthEvent blocked on a full queue")
+	}
+}
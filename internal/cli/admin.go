@@ -79,6 +79,7 @@ func runAdminCreate(cmd *cobra.Command, args []string) error {
 		URL:      dbURL,
 		MaxConns: 5,
 		MinConns: 1,
+		SSL:      databaseSSL(cfg),
 	}, logger)
 	if err != nil {
 		return fmt.Errorf("connecting to database: %w", err)
@@ -94,7 +95,7 @@ func runAdminCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("running migrations: %w", err)
 	}
 
-	user, err := auth.CreateUser(ctx, pool.DB(), email, password, cfg.Auth.MinPasswordLength)
+	user, err := auth.CreateUser(ctx, pool.DB(), email, password, cfg.Auth.MinPasswordLength, cfg.Auth.PasswordHash)
 	if err != nil {
 		return fmt.Errorf("creating user: %w", err)
 	}
@@ -68,6 +68,9 @@ func runAPIKeysList(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var result struct {
 		Items []struct {
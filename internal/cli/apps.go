@@ -66,6 +66,9 @@ func runAppsList(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var result struct {
 		Items []struct {
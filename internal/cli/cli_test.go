@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/testutil"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestSetVersion(t *testing.T) {
@@ -177,6 +180,35 @@ func TestMigrateCreateGeneratesFile(t *testing.T) {
 	}
 }
 
+func TestMigrateCreateReversible(t *testing.T) {
+	migrDir := filepath.Join(t.TempDir(), "migrations")
+	defer migrateCreateCmd.Flags().Set("reversible", "false")
+
+	captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"migrate", "create", "add_posts", "--reversible", "--migrations-dir", migrDir})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	entries, err := os.ReadDir(migrDir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 migration file, got %d", len(entries))
+	}
+	data, err := os.ReadFile(filepath.Join(migrDir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("read migration: %v", err)
+	}
+	for _, section := range []string{"-- +up", "-- +down"} {
+		if !strings.Contains(string(data), section) {
+			t.Fatalf("expected %q section in migration, got %q", section, data)
+		}
+	}
+}
+
 // captureStderr captures stderr output from the given function.
 func captureStderr(t *testing.T, fn func()) string {
 	t.Helper()
@@ -644,7 +676,7 @@ func TestUsersSubcommands(t *testing.T) {
 	for _, cmd := range usersCmd.Commands() {
 		found[cmd.Name()] = true
 	}
-	for _, name := range []string{"list", "delete"} {
+	for _, name := range []string{"list", "delete", "disable", "enable", "logout", "export", "bulk-delete"} {
 		if !found[name] {
 			t.Errorf("expected users subcommand %q", name)
 		}
@@ -906,8 +938,10 @@ func TestInitRejectsInvalidTemplate(t *testing.T) {
 	if !strings.Contains(err.Error(), "unknown template") {
 		t.Fatalf("expected 'unknown template' error, got %q", err.Error())
 	}
-	if !strings.Contains(err.Error(), "react") {
-		t.Fatalf("expected available templates listed in error, got %q", err.Error())
+	for _, name := range []string{"react", "next", "svelte", "express", "plain"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected template %q listed in error, got %q", name, err.Error())
+		}
 	}
 }
 
@@ -1000,6 +1034,39 @@ func TestInitDefaultsToReactTemplate(t *testing.T) {
 	}
 }
 
+func TestInitGeneratesFrameworkTemplates(t *testing.T) {
+	resetJSONFlag()
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer os.Chdir(origDir)
+	t.Cleanup(func() { initCmd.Flags().Set("template", "react") })
+
+	tests := []struct {
+		template string
+		files    []string
+	}{
+		{"next", []string{"next.config.js", "src/app/page.tsx", "src/app/login/page.tsx"}},
+		{"svelte", []string{"svelte.config.js", "src/routes/+page.svelte", "src/routes/login/+page.svelte"}},
+	}
+	for _, tt := range tests {
+		name := tt.template + "-app"
+		captureStdout(t, func() {
+			rootCmd.SetArgs([]string{"init", name, "--template", tt.template})
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("init --template %s: %v", tt.template, err)
+			}
+		})
+		for _, file := range append([]string{"ayb.toml", "schema.sql", "src/lib/ayb.ts", "CLAUDE.md"}, tt.files...) {
+			if _, err := os.Stat(filepath.Join(tmpDir, name, file)); err != nil {
+				t.Fatalf("%s template: expected file %q: %v", tt.template, file, err)
+			}
+		}
+	}
+}
+
 // --- MCP command tests ---
 
 // --- DB command tests ---
@@ -1016,7 +1083,7 @@ func TestDBSubcommands(t *testing.T) {
 		t.Fatal("db command not found")
 	}
 
-	expected := map[string]bool{"backup": true, "restore": true}
+	expected := map[string]bool{"backup": true, "restore": true, "upgrade-embedded": true, "maintenance": true}
 	for _, sub := range dbCommand.Commands() {
 		delete(expected, sub.Name())
 	}
@@ -1054,6 +1121,23 @@ func TestDBBackupInvalidFormat(t *testing.T) {
 	}
 }
 
+func TestDBUpgradeEmbeddedRequiresManagedPostgres(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+	t.Setenv("AYB_DATABASE_URL", "postgresql://localhost/db")
+
+	rootCmd.SetArgs([]string{"db", "upgrade-embedded"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected error with database.url set")
+	}
+	if !strings.Contains(err.Error(), "managed PostgreSQL") {
+		t.Fatalf("expected managed PostgreSQL error, got: %v", err)
+	}
+}
+
 func TestDBRestoreRequiresArg(t *testing.T) {
 	rootCmd.SetArgs([]string{"db", "restore"})
 	err := rootCmd.Execute()
@@ -2251,7 +2335,7 @@ func TestSQLCommandConnectionError(t *testing.T) {
 
 func TestQueryCommandFlagDefinitions(t *testing.T) {
 	flags := queryCmd.Flags()
-	stringFlags := []string{"filter", "sort", "fields", "expand", "admin-token", "url"}
+	stringFlags := []string{"sort", "fields", "expand", "admin-token", "url", "output-file"}
 	for _, name := range stringFlags {
 		f := flags.Lookup(name)
 		if f == nil {
@@ -2262,6 +2346,16 @@ func TestQueryCommandFlagDefinitions(t *testing.T) {
 			t.Errorf("flag %q should be string, got %s", name, f.Value.Type())
 		}
 	}
+	for _, name := range []string{"filter", "or"} {
+		f := flags.Lookup(name)
+		if f == nil {
+			t.Errorf("expected flag %q on query command", name)
+			continue
+		}
+		if f.Value.Type() != "stringArray" {
+			t.Errorf("flag %q should be stringArray, got %s", name, f.Value.Type())
+		}
+	}
 	intFlags := []string{"page", "limit"}
 	for _, name := range intFlags {
 		f := flags.Lookup(name)
@@ -2339,7 +2433,7 @@ func TestSchemaCommandConnectionError(t *testing.T) {
 
 func TestWebhooksCreateFlagDefinitions(t *testing.T) {
 	flags := webhooksCreateCmd.Flags()
-	stringFlags := []string{"webhook-url", "events", "tables", "secret"}
+	stringFlags := []string{"webhook-url", "events", "tables", "secret", "include-columns", "exclude-columns", "filter"}
 	for _, name := range stringFlags {
 		f := flags.Lookup(name)
 		if f == nil {
@@ -2447,6 +2541,42 @@ func TestUsersNoRunWithoutSubcommand(t *testing.T) {
 	}
 }
 
+func TestListCommandsRecordFormats(t *testing.T) {
+	resetJSONFlag()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AYB_ADMIN_TOKEN", "tok")
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("output", "table") })
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/admin/users":
+			fmt.Fprint(w, `{"items":[{"id":"u1","email":"a@example.com","emailVerified":true}],"page":1,"perPage":20,"totalItems":1,"totalPages":1}`)
+		case "/api/webhooks":
+			fmt.Fprint(w, `[{"id":"w1","url":"https://example.com/hook","events":["create"],"enabled":true}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"users", "list", "--output", "ndjson"}, `{"id":"u1","email":"a@example.com","emailVerified":true}` + "\n"},
+		{[]string{"users", "list", "--output", "yaml"}, "- id: u1\n  email: a@example.com\n  emailVerified: true\n"},
+		{[]string{"webhooks", "list", "--output", "ndjson"}, `{"id":"w1","url":"https://example.com/hook","events":["create"],"enabled":true}` + "\n"},
+	}
+	for _, tt := range tests {
+		var err error
+		out := captureStdout(t, func() {
+			rootCmd.SetArgs(tt.args)
+			err = rootCmd.Execute()
+		})
+		testutil.NoError(t, err)
+		testutil.Equal(t, tt.want, out)
+	}
+}
+
 // --- API Keys command tests (expanded) ---
 
 func TestAPIKeysCreateFlagDefinitions(t *testing.T) {
@@ -2535,16 +2665,52 @@ func TestAPIKeysNoRunWithoutSubcommand(t *testing.T) {
 
 func TestMCPFlagDefinitions(t *testing.T) {
 	flags := mcpCmd.Flags()
-	for _, name := range []string{"url", "admin-token", "token"} {
+	for name, typ := range map[string]string{
+		"url":          "string",
+		"admin-token":  "string",
+		"token":        "string",
+		"allow-writes": "bool",
+		"write-tables": "stringSlice",
+		"audit-log":    "string",
+	} {
 		f := flags.Lookup(name)
 		if f == nil {
 			t.Errorf("expected flag %q on mcp command", name)
 			continue
 		}
-		if f.Value.Type() != "string" {
-			t.Errorf("flag %q should be string, got %s", name, f.Value.Type())
+		if f.Value.Type() != typ {
+			t.Errorf("flag %q should be %s, got %s", name, typ, f.Value.Type())
 		}
 	}
+	testutil.Equal(t, "false", flags.Lookup("allow-writes").DefValue)
+}
+
+func TestMCPWriteFlagsMustBeUsedTogether(t *testing.T) {
+	t.Cleanup(func() {
+		mcpCmd.Flags().Set("allow-writes", "false")
+		mcpCmd.Flags().Lookup("write-tables").Value.(pflag.SliceValue).Replace(nil)
+	})
+	for _, args := range [][]string{
+		{"mcp", "--allow-writes"},
+		{"mcp", "--write-tables", "posts"},
+	} {
+		mcpCmd.Flags().Set("allow-writes", "false")
+		mcpCmd.Flags().Lookup("write-tables").Value.(pflag.SliceValue).Replace(nil)
+		mcpCmd.Flags().Set("help", "false")
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		testutil.ErrorContains(t, err, "--allow-writes and --write-tables must be used together")
+	}
+}
+
+func TestOpenMCPAuditLogCreatesDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	f, err := openMCPAuditLog(path)
+	testutil.NoError(t, err)
+	f.Close()
+	info, err := os.Stat(path)
+	testutil.NoError(t, err)
+	testutil.Equal(t, os.FileMode(0o600), info.Mode().Perm())
 }
 
 func TestMCPHasRunE(t *testing.T) {
@@ -2693,6 +2859,55 @@ func TestWriteCSVEmpty(t *testing.T) {
 	}
 }
 
+func TestWriteRecordsNDJSON(t *testing.T) {
+	var buf strings.Builder
+	records := []json.RawMessage{
+		json.RawMessage(`{"name": "Alice", "age": 30}`),
+		json.RawMessage(`{"name": "Bob", "age": null}`),
+	}
+	testutil.NoError(t, writeRecords(&buf, "ndjson", records))
+	testutil.Equal(t, "{\"name\":\"Alice\",\"age\":30}\n{\"name\":\"Bob\",\"age\":null}\n", buf.String())
+}
+
+func TestWriteRecordsYAML(t *testing.T) {
+	var buf strings.Builder
+	records := []json.RawMessage{
+		json.RawMessage(`{"name": "Alice", "age": 30, "tags": ["a", "b"], "note": "true"}`),
+		json.RawMessage(`{"name": "Bob", "age": null, "tags": [], "note": "line 1\nline 2"}`),
+	}
+	testutil.NoError(t, writeRecords(&buf, "yaml", records))
+	testutil.Equal(t, `- name: Alice
+  age: 30
+  tags:
+    - a
+    - b
+  note: "true"
+- name: Bob
+  age: null
+  tags: []
+  note: |-
+    line 1
+    line 2
+`, buf.String())
+}
+
+func TestWriteRecordsEmpty(t *testing.T) {
+	var buf strings.Builder
+	testutil.NoError(t, writeRecords(&buf, "ndjson", nil))
+	testutil.Equal(t, "", buf.String())
+	testutil.NoError(t, writeRecords(&buf, "yaml", nil))
+	testutil.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteListRecords(t *testing.T) {
+	for _, body := range []string{`[{"id":"1"},{"id":"2"}]`, `{"items":[{"id":"1"},{"id":"2"}],"totalItems":2}`} {
+		var buf strings.Builder
+		testutil.NoError(t, writeListRecords(&buf, "ndjson", []byte(body)))
+		testutil.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n", buf.String())
+	}
+	testutil.ErrorContains(t, writeListRecords(io.Discard, "ndjson", []byte(`"nope"`)), "parsing response")
+}
+
 // --- Logs command tests (expanded) ---
 
 func TestLogsShorthandFlags(t *testing.T) {
@@ -2785,6 +3000,14 @@ func TestServerErrorWithJSON(t *testing.T) {
 	}
 }
 
+func TestServerErrorWithEnvelope(t *testing.T) {
+	body := []byte(`{"error": {"code": "not_found", "message": "collection not found"}}`)
+	err := serverError(404, body)
+	if err.Error() != "server error (404): collection not found" {
+		t.Errorf("expected envelope message, got %q", err.Error())
+	}
+}
+
 func TestServerErrorWithPlainText(t *testing.T) {
 	body := []byte("plain text error")
 	err := serverError(500, body)
@@ -1,13 +1,16 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/pgmanager"
 	"github.com/spf13/cobra"
 )
 
@@ -43,6 +46,23 @@ Examples:
 	RunE: runDBRestore,
 }
 
+var dbUpgradeEmbeddedCmd = &cobra.Command{
+	Use:   "upgrade-embedded",
+	Short: "Upgrade the managed PostgreSQL data directory to database.embedded_version",
+	Long: `Move the managed PostgreSQL data directory to the major version set by
+database.embedded_version. The data is dumped from a server of the version
+that created the directory and restored into a new one. The old directory
+is kept next to it with a .pg<version> suffix.
+
+Stop the server before upgrading. Requires pg_dump and psql, from the
+downloaded PostgreSQL binaries or PATH.
+
+Examples:
+  ayb db upgrade-embedded
+  ayb db upgrade-embedded --config ./ayb.toml`,
+	RunE: runDBUpgradeEmbedded,
+}
+
 func init() {
 	dbBackupCmd.Flags().String("output", "", "Output file path (default: ayb-backup-{timestamp}.sql)")
 	dbBackupCmd.Flags().String("format", "plain", "Backup format: plain, custom, tar, directory")
@@ -52,8 +72,11 @@ func init() {
 	dbRestoreCmd.Flags().String("database-url", "", "Database URL (overrides config)")
 	dbRestoreCmd.Flags().String("config", "", "Path to ayb.toml config file")
 
+	dbUpgradeEmbeddedCmd.Flags().String("config", "", "Path to ayb.toml config file")
+
 	dbCmd.AddCommand(dbBackupCmd)
 	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbUpgradeEmbeddedCmd)
 }
 
 func resolveDBURL(cmd *cobra.Command) (string, error) {
@@ -197,3 +220,38 @@ func runDBRestore(cmd *cobra.Command, args []string) error {
 	fmt.Println("Restore complete.")
 	return nil
 }
+
+func runDBUpgradeEmbedded(cmd *cobra.Command, args []string) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	if configPath == "" {
+		configPath = "ayb.toml"
+	}
+	cfg, err := config.Load(configPath, nil)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if cfg.Database.URL != "" {
+		return fmt.Errorf("database.url is set: upgrade-embedded only applies to managed PostgreSQL")
+	}
+	if isServerRunning() {
+		return fmt.Errorf("AYB server is running: stop it with 'ayb stop' before upgrading")
+	}
+
+	mgr := pgmanager.New(pgmanager.Config{
+		Port:    uint32(cfg.Database.EmbeddedPort),
+		Version: cfg.Database.EmbeddedVersion,
+		DataDir: cfg.Database.EmbeddedDataDir,
+		Logger:  slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	})
+	fmt.Printf("Upgrading managed PostgreSQL to version %s...\n", cfg.Database.EmbeddedVersion)
+	backupDir, err := mgr.Upgrade(cmd.Context())
+	if errors.Is(err, pgmanager.ErrUpToDate) {
+		fmt.Printf("Managed PostgreSQL is already at version %s.\n", cfg.Database.EmbeddedVersion)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("upgrading managed PostgreSQL: %w", err)
+	}
+	fmt.Printf("Upgrade complete. The old data directory is kept at %s.\n", backupDir)
+	return nil
+}
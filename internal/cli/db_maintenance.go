@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/cobra"
+)
+
+var dbMaintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Run VACUUM, ANALYZE or REINDEX on the database tables",
+	Long: `Run maintenance on every user table and materialized view, one table at
+a time. Without an operation flag, runs VACUUM and ANALYZE.
+
+Only variants that let reads and writes continue are used: VACUUM and ANALYZE
+skip tables that are locked, and --reindex uses REINDEX CONCURRENTLY. Never
+runs VACUUM FULL.
+
+To run maintenance on a schedule, enable the db_maintenance_weekly job
+schedule (see 'ayb schedules list').
+
+Examples:
+  ayb db maintenance
+  ayb db maintenance --analyze
+  ayb db maintenance --reindex --table posts --dry-run`,
+	RunE: runDBMaintenance,
+}
+
+// reindexWarning explains the cost of REINDEX CONCURRENTLY, which still takes
+// a lock that blocks schema changes and other maintenance on each table.
+const reindexWarning = `Warning: REINDEX CONCURRENTLY holds a SHARE UPDATE EXCLUSIVE lock on each
+table while it runs. Reads and writes continue, but schema changes, VACUUM
+and other reindexes on that table wait. It also waits for open transactions
+and needs disk space for a second copy of each index. If it fails, drop the
+leftover invalid indexes (named with a _ccnew suffix).
+`
+
+func init() {
+	dbMaintenanceCmd.Flags().Bool("vacuum", false, "Reclaim dead rows with VACUUM")
+	dbMaintenanceCmd.Flags().Bool("analyze", false, "Refresh planner statistics with ANALYZE")
+	dbMaintenanceCmd.Flags().Bool("reindex", false, "Rebuild indexes with REINDEX CONCURRENTLY")
+	dbMaintenanceCmd.Flags().StringSlice("table", nil, "Only these tables, as table or schema.table (default: all)")
+	dbMaintenanceCmd.Flags().Bool("dry-run", false, "Print the statements without running them")
+	dbMaintenanceCmd.Flags().String("database-url", "", "Database URL (overrides config)")
+	dbMaintenanceCmd.Flags().String("config", "", "Path to ayb.toml config file")
+
+	dbCmd.AddCommand(dbMaintenanceCmd)
+}
+
+func runDBMaintenance(cmd *cobra.Command, args []string) error {
+	var opts postgres.MaintenanceOptions
+	opts.Vacuum, _ = cmd.Flags().GetBool("vacuum")
+	opts.Analyze, _ = cmd.Flags().GetBool("analyze")
+	opts.Reindex, _ = cmd.Flags().GetBool("reindex")
+	opts = opts.WithDefault()
+	tableFlags, _ := cmd.Flags().GetStringSlice("table")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	tables, err := parseMaintenanceTables(tableFlags)
+	if err != nil {
+		return err
+	}
+	if opts.Reindex {
+		fmt.Fprint(os.Stderr, reindexWarning)
+	}
+
+	// Named tables don't need the database for a dry run.
+	if dryRun && len(tables) > 0 {
+		printMaintenancePlan(postgres.MaintenancePlan(tables, opts))
+		return nil
+	}
+
+	cfg, err := loadMigrateConfig(cmd)
+	if err != nil {
+		return err
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	pool, cleanup, err := connectForMigrate(cmd, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	ctx := cmd.Context()
+
+	if len(tables) == 0 {
+		if tables, err = postgres.MaintenanceTables(ctx, pool.DB()); err != nil {
+			return err
+		}
+	}
+	steps := postgres.MaintenancePlan(tables, opts)
+	if dryRun {
+		printMaintenancePlan(steps)
+		return nil
+	}
+
+	start := time.Now()
+	err = postgres.RunMaintenance(ctx, pool.DB(), steps, func(i int, step postgres.MaintenanceStep, took time.Duration) {
+		fmt.Printf("[%d/%d] %s (%s)\n", i+1, len(steps), step.SQL, took.Round(time.Millisecond))
+	})
+	if err != nil {
+		return fmt.Errorf("maintenance failed: %w", err)
+	}
+	fmt.Printf("Maintenance complete: %d statements on %d tables in %s.\n",
+		len(steps), len(tables), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// parseMaintenanceTables quotes --table values given as table or
+// schema.table, defaulting to the public schema.
+func parseMaintenanceTables(values []string) ([]string, error) {
+	var tables []string
+	for _, v := range values {
+		schema, name, ok := strings.Cut(v, ".")
+		if !ok {
+			schema, name = "public", v
+		}
+		if schema == "" || name == "" || strings.Contains(name, ".") {
+			return nil, fmt.Errorf("invalid --table %q: use table or schema.table", v)
+		}
+		tables = append(tables, pgx.Identifier{schema, name}.Sanitize())
+	}
+	return tables, nil
+}
+
+func printMaintenancePlan(steps []postgres.MaintenanceStep) {
+	for _, step := range steps {
+		fmt.Println(step.SQL + ";")
+	}
+}
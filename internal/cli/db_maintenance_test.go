@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/spf13/pflag"
+)
+
+func resetDBMaintenanceFlags(t *testing.T) {
+	t.Cleanup(func() {
+		dbMaintenanceCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if v, ok := f.Value.(pflag.SliceValue); ok {
+				_ = v.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	})
+}
+
+func TestDBMaintenanceFlags(t *testing.T) {
+	for _, name := range []string{"vacuum", "analyze", "reindex", "table", "dry-run", "database-url", "config"} {
+		testutil.NotNil(t, dbMaintenanceCmd.Flags().Lookup(name))
+	}
+}
+
+func TestDBMaintenanceDryRunDefaultsToVacuumAnalyze(t *testing.T) {
+	resetDBMaintenanceFlags(t)
+	rootCmd.SetArgs([]string{"db", "maintenance", "--dry-run", "--table", "posts,audit.events"})
+	var err error
+	out := captureStdout(t, func() { err = rootCmd.Execute() })
+	testutil.NoError(t, err)
+	testutil.Equal(t, `VACUUM (SKIP_LOCKED, ANALYZE) "public"."posts";
+VACUUM (SKIP_LOCKED, ANALYZE) "audit"."events";
+`, out)
+}
+
+func TestDBMaintenanceDryRunSelectedOperations(t *testing.T) {
+	resetDBMaintenanceFlags(t)
+	rootCmd.SetArgs([]string{"db", "maintenance", "--dry-run", "--analyze", "--reindex", "--table", "posts"})
+	var err error
+	out := captureStdout(t, func() { err = rootCmd.Execute() })
+	testutil.NoError(t, err)
+	testutil.Equal(t, `ANALYZE (SKIP_LOCKED) "public"."posts";
+REINDEX TABLE CONCURRENTLY "public"."posts";
+`, out)
+}
+
+func TestDBMaintenanceRejectsInvalidTable(t *testing.T) {
+	for _, table := range []string{".posts", "public.", "a.b.c"} {
+		t.Run(table, func(t *testing.T) {
+			resetDBMaintenanceFlags(t)
+			rootCmd.SetArgs([]string{"db", "maintenance", "--dry-run", "--table", table})
+			err := rootCmd.Execute()
+			testutil.ErrorContains(t, err, "invalid --table")
+		})
+	}
+}
+
+func TestDBMaintenanceRequiresDBURL(t *testing.T) {
+	resetDBMaintenanceFlags(t)
+	t.Chdir(t.TempDir()) // no ayb.toml
+	t.Setenv("AYB_DATABASE_URL", "")
+	rootCmd.SetArgs([]string{"db", "maintenance", "--dry-run"})
+	err := rootCmd.Execute()
+	testutil.ErrorContains(t, err, "no database URL configured")
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/allyourbase/ayb/examples"
 	"github.com/allyourbase/ayb/internal/cli/ui"
+	aybhttputil "github.com/allyourbase/ayb/internal/httputil"
 	"github.com/spf13/cobra"
 )
 
@@ -284,14 +285,11 @@ func applyDemoSchema(baseURL, name string) (string, error) {
 			return "exists", nil
 		}
 		// Parse error message if possible
-		var errResp map[string]any
-		if json.Unmarshal(respBody, &errResp) == nil {
-			if msg, ok := errResp["message"].(string); ok {
-				if strings.Contains(msg, "already exists") {
-					return "exists", nil
-				}
-				return "", fmt.Errorf("SQL error: %s", msg)
+		if msg, ok := aybhttputil.ErrorMessage(respBody); ok {
+			if strings.Contains(msg, "already exists") {
+				return "exists", nil
 			}
+			return "", fmt.Errorf("SQL error: %s", msg)
 		}
 		return "", fmt.Errorf("server returned %d: %s", resp.StatusCode, bodyStr)
 	}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/cli/ui"
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/migrations"
+	"github.com/allyourbase/ayb/internal/postgres"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check configuration and connectivity before starting",
+	Long: `Load the configuration and check that AYB can start cleanly:
+database connectivity and migrations, auth secrets, email delivery,
+storage, SMS credentials, and TLS DNS. Each check reports pass, warn,
+or fail with a hint on how to fix it. Exits non-zero if any check fails.
+
+Examples:
+  ayb doctor
+  ayb doctor --config ./prod.toml --json`,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().String("config", "", "Path to ayb.toml config file")
+}
+
+// doctorTimeout bounds each network check.
+const doctorTimeout = 5 * time.Second
+
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is one line of the doctor checklist.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var checks []doctorCheck
+	cfg, err := config.Load(configPath, nil)
+	if err != nil {
+		checks = append(checks, doctorCheck{
+			Name: "config", Status: doctorFail, Detail: err.Error(),
+			Hint: "fix the setting named above in ayb.toml or its AYB_* environment variable",
+		})
+	} else {
+		checks = runDoctorChecks(ctx, cfg)
+	}
+
+	out := cmd.OutOrStdout()
+	if outputFormat(cmd) == "json" {
+		if err := json.NewEncoder(out).Encode(checks); err != nil {
+			return err
+		}
+	} else {
+		printDoctorChecks(out, checks)
+	}
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check against a config that has passed Validate.
+func runDoctorChecks(ctx context.Context, cfg *config.Config) []doctorCheck {
+	checks := []doctorCheck{{Name: "config", Status: doctorPass, Detail: "loaded and valid"}}
+	checks = append(checks, checkDoctorDatabase(ctx, cfg)...)
+	checks = append(checks,
+		checkDoctorJWT(cfg),
+		checkDoctorEmail(ctx, cfg),
+		checkDoctorStorage(ctx, cfg),
+		checkDoctorSMS(cfg),
+		checkDoctorTLS(ctx, cfg),
+	)
+	return checks
+}
+
+func printDoctorChecks(w io.Writer, checks []doctorCheck) {
+	symbols := map[string]string{
+		doctorPass: ui.StyleSuccess.Render(ui.SymbolCheck),
+		doctorWarn: ui.StyleWarning.Render(ui.SymbolWarning),
+		doctorFail: ui.StyleError.Render(ui.SymbolCross),
+	}
+	for _, c := range checks {
+		fmt.Fprintf(w, "  %s %-12s %s\n", symbols[c.Status], c.Name, c.Detail)
+		if c.Hint != "" && c.Status != doctorPass {
+			fmt.Fprintf(w, "    %s %s\n", ui.StyleHint.Render(ui.SymbolArrow), c.Hint)
+		}
+	}
+}
+
+// checkDoctorDatabase connects to database.url and reports pending system
+// and user migrations. Without a URL, ayb start runs managed PostgreSQL.
+func checkDoctorDatabase(ctx context.Context, cfg *config.Config) []doctorCheck {
+	if cfg.Database.URL == "" {
+		return []doctorCheck{{
+			Name: "database", Status: doctorPass,
+			Detail: "no database.url; ayb start will run managed PostgreSQL",
+		}}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pool, err := postgres.New(ctx, postgres.Config{URL: cfg.Database.URL, MaxConns: 1, SSL: databaseSSL(cfg)}, logger)
+	if err != nil {
+		return []doctorCheck{{
+			Name: "database", Status: doctorFail, Detail: err.Error(),
+			Hint: "check database.url (AYB_DATABASE_URL) and that PostgreSQL is running and reachable",
+		}}
+	}
+	defer pool.Close()
+	checks := []doctorCheck{{Name: "database", Status: doctorPass, Detail: "connected"}}
+
+	migrationsCheck := doctorCheck{Name: "migrations", Status: doctorPass, Detail: "up to date"}
+	systemPending, err := migrations.NewRunner(pool.DB(), logger).Pending(ctx)
+	if err != nil {
+		return append(checks, doctorCheck{Name: "migrations", Status: doctorFail, Detail: err.Error()})
+	}
+	userPending, err := migrations.NewUserRunner(pool.DB(), cfg.Database.MigrationsDir, logger).Pending(ctx)
+	if err != nil {
+		return append(checks, doctorCheck{Name: "migrations", Status: doctorFail, Detail: err.Error()})
+	}
+	var hints []string
+	if len(systemPending) > 0 {
+		hints = append(hints, fmt.Sprintf("%d system migration(s) are applied automatically by ayb start", len(systemPending)))
+	}
+	if len(userPending) > 0 {
+		hints = append(hints, fmt.Sprintf("run ayb migrate up to apply %d migration(s) in %s", len(userPending), cfg.Database.MigrationsDir))
+	}
+	if len(hints) > 0 {
+		migrationsCheck.Status = doctorWarn
+		migrationsCheck.Detail = fmt.Sprintf("%d system, %d user migration(s) pending", len(systemPending), len(userPending))
+		migrationsCheck.Hint = strings.Join(hints, "; ")
+	}
+	return append(checks, migrationsCheck)
+}
+
+// checkDoctorJWT reports on the JWT secret. Validate has already enforced
+// presence and minimum length when auth is enabled.
+func checkDoctorJWT(cfg *config.Config) doctorCheck {
+	if !cfg.Auth.Enabled {
+		return doctorCheck{Name: "jwt secret", Status: doctorPass, Detail: "auth disabled"}
+	}
+	return doctorCheck{Name: "jwt secret", Status: doctorPass, Detail: fmt.Sprintf("%d characters", len(cfg.Auth.JWTSecret))}
+}
+
+// checkDoctorEmail checks that the SMTP server or email webhook accepts
+// TCP connections.
+func checkDoctorEmail(ctx context.Context, cfg *config.Config) doctorCheck {
+	var addr string
+	switch cfg.Email.Backend {
+	case "smtp":
+		addr = net.JoinHostPort(cfg.Email.SMTP.Host, strconv.Itoa(smtpPort(cfg)))
+	case "webhook":
+		var err error
+		if addr, err = urlHostPort(cfg.Email.Webhook.URL); err != nil {
+			return doctorCheck{Name: "email", Status: doctorFail, Detail: err.Error(), Hint: "check email.webhook.url"}
+		}
+	default:
+		return doctorCheck{
+			Name: "email", Status: doctorWarn, Detail: "log backend: emails are printed, not sent",
+			Hint: `set email.backend to "smtp" or "webhook" for production`,
+		}
+	}
+
+	if err := dialDoctor(ctx, addr); err != nil {
+		return doctorCheck{
+			Name: "email", Status: doctorFail, Detail: fmt.Sprintf("%s backend unreachable: %v", cfg.Email.Backend, err),
+			Hint: "check the host and port, and that outbound connections are allowed",
+		}
+	}
+	return doctorCheck{Name: "email", Status: doctorPass, Detail: fmt.Sprintf("%s backend reachable at %s", cfg.Email.Backend, addr)}
+}
+
+// doctorProbeName is the object written and deleted by the storage check.
+const doctorProbeName = ".ayb-doctor-probe"
+
+// checkDoctorStorage writes and deletes a probe object through the same
+// backend ayb start would use.
+func checkDoctorStorage(ctx context.Context, cfg *config.Config) doctorCheck {
+	if !cfg.Storage.Enabled {
+		return doctorCheck{Name: "storage", Status: doctorPass, Detail: "storage disabled"}
+	}
+
+	hint := "check storage.local_path permissions"
+	if cfg.Storage.Backend == "s3" {
+		hint = "check the storage.s3_* endpoint, bucket, and credentials"
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	backend, err := buildStorageBackend(ctx, cfg)
+	if err != nil {
+		return doctorCheck{Name: "storage", Status: doctorFail, Detail: err.Error(), Hint: hint}
+	}
+	if _, err := backend.Put(ctx, "", doctorProbeName, strings.NewReader("ok")); err != nil {
+		return doctorCheck{Name: "storage", Status: doctorFail, Detail: "not writable: " + err.Error(), Hint: hint}
+	}
+	if err := backend.Delete(ctx, "", doctorProbeName); err != nil {
+		return doctorCheck{Name: "storage", Status: doctorWarn, Detail: "probe object not deleted: " + err.Error(), Hint: hint}
+	}
+	backendName := cfg.Storage.Backend
+	if backendName != "s3" {
+		backendName = "local"
+	}
+	return doctorCheck{Name: "storage", Status: doctorPass, Detail: backendName + " backend writable"}
+}
+
+// checkDoctorSMS reports on SMS provider credentials. Validate has already
+// required every credential of the selected provider.
+func checkDoctorSMS(cfg *config.Config) doctorCheck {
+	switch {
+	case !cfg.Auth.SMSEnabled:
+		return doctorCheck{Name: "sms", Status: doctorPass, Detail: "SMS disabled"}
+	case cfg.Auth.SMSProvider == "" || cfg.Auth.SMSProvider == "log":
+		return doctorCheck{
+			Name: "sms", Status: doctorWarn, Detail: "log provider: codes are printed, not sent",
+			Hint: "set auth.sms_provider and its credentials for production",
+		}
+	}
+	return doctorCheck{Name: "sms", Status: doctorPass, Detail: cfg.Auth.SMSProvider + " credentials present"}
+}
+
+// checkDoctorTLS checks that server.tls_domain resolves, which Let's Encrypt
+// needs before it will issue a certificate.
+func checkDoctorTLS(ctx context.Context, cfg *config.Config) doctorCheck {
+	if cfg.Server.TLSDomain == "" {
+		return doctorCheck{Name: "tls", Status: doctorPass, Detail: "TLS not configured"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, cfg.Server.TLSDomain)
+	if err != nil || len(addrs) == 0 {
+		return doctorCheck{
+			Name: "tls", Status: doctorFail, Detail: fmt.Sprintf("%s does not resolve", cfg.Server.TLSDomain),
+			Hint: "create an A or AAAA record pointing the domain at this server",
+		}
+	}
+	return doctorCheck{Name: "tls", Status: doctorPass, Detail: fmt.Sprintf("%s resolves to %s", cfg.Server.TLSDomain, strings.Join(addrs, ", "))}
+}
+
+// urlHostPort returns host:port for an http(s) URL, defaulting the port from
+// the scheme.
+func urlHostPort(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid URL %q", raw)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+func dialDoctor(ctx context.Context, addr string) error {
+	d := net.Dialer{Timeout: doctorTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
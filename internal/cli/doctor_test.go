@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestDoctorCommandJSON(t *testing.T) {
+	resetJSONFlag()
+	defer resetJSONFlag()
+	path := filepath.Join(t.TempDir(), "ayb.toml")
+	testutil.NoError(t, os.WriteFile(path, []byte("[server]\nport = 8090\n"), 0o600))
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"doctor", "--config", path, "--json"})
+		testutil.NoError(t, rootCmd.Execute())
+	})
+
+	var checks []doctorCheck
+	testutil.NoError(t, json.Unmarshal([]byte(output), &checks))
+	byName := map[string]doctorCheck{}
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+	testutil.Equal(t, doctorPass, byName["config"].Status)
+	testutil.Equal(t, doctorPass, byName["database"].Status) // managed PostgreSQL
+	testutil.Equal(t, doctorWarn, byName["email"].Status)    // log backend
+	testutil.Contains(t, byName["email"].Hint, "smtp")
+}
+
+func TestDoctorCommandInvalidConfigFails(t *testing.T) {
+	resetJSONFlag()
+	defer resetJSONFlag()
+	path := filepath.Join(t.TempDir(), "ayb.toml")
+	testutil.NoError(t, os.WriteFile(path, []byte("[auth]\nenabled = true\njwt_secret = \"short\"\n"), 0o600))
+
+	var err error
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"doctor", "--config", path})
+		err = rootCmd.Execute()
+	})
+	testutil.ErrorContains(t, err, "1 check(s) failed")
+	testutil.Contains(t, output, "config")
+	testutil.Contains(t, output, "auth.jwt_secret must be at least 32 characters")
+}
+
+func TestCheckDoctorEmail(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.NoError(t, err)
+	defer ln.Close()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	cfg := config.Default()
+	cfg.Email.Backend = "smtp"
+	cfg.Email.SMTP.Host = host
+	cfg.Email.SMTP.Port, _ = net.LookupPort("tcp", port)
+	testutil.Equal(t, doctorPass, checkDoctorEmail(context.Background(), cfg).Status)
+
+	cfg.Email.Backend = "webhook"
+	cfg.Email.Webhook.URL = "http://" + ln.Addr().String() + "/hook"
+	testutil.Equal(t, doctorPass, checkDoctorEmail(context.Background(), cfg).Status)
+
+	ln.Close()
+	c := checkDoctorEmail(context.Background(), cfg)
+	testutil.Equal(t, doctorFail, c.Status)
+	testutil.Contains(t, c.Detail, "unreachable")
+}
+
+func TestCheckDoctorStorage(t *testing.T) {
+	cfg := config.Default()
+	testutil.Equal(t, doctorPass, checkDoctorStorage(context.Background(), cfg).Status)
+
+	dir := t.TempDir()
+	cfg.Storage.Enabled = true
+	cfg.Storage.Backend = "local"
+	cfg.Storage.LocalPath = dir
+	c := checkDoctorStorage(context.Background(), cfg)
+	testutil.Equal(t, doctorPass, c.Status)
+	_, err := os.Stat(filepath.Join(dir, doctorProbeName))
+	testutil.True(t, os.IsNotExist(err), "probe object should be cleaned up")
+
+	// A regular file where the directory should be is not writable.
+	file := filepath.Join(dir, "not-a-dir")
+	testutil.NoError(t, os.WriteFile(file, nil, 0o600))
+	cfg.Storage.LocalPath = file
+	testutil.Equal(t, doctorFail, checkDoctorStorage(context.Background(), cfg).Status)
+}
+
+func TestCheckDoctorSMS(t *testing.T) {
+	cfg := config.Default()
+	testutil.Equal(t, doctorPass, checkDoctorSMS(cfg).Status)
+
+	cfg.Auth.SMSEnabled = true
+	cfg.Auth.SMSProvider = "log"
+	testutil.Equal(t, doctorWarn, checkDoctorSMS(cfg).Status)
+
+	cfg.Auth.SMSProvider = "twilio"
+	c := checkDoctorSMS(cfg)
+	testutil.Equal(t, doctorPass, c.Status)
+	testutil.Contains(t, c.Detail, "twilio")
+}
+
+func TestCheckDoctorTLS(t *testing.T) {
+	cfg := config.Default()
+	testutil.Equal(t, doctorPass, checkDoctorTLS(context.Background(), cfg).Status)
+
+	cfg.Server.TLSDomain = "localhost"
+	testutil.Equal(t, doctorPass, checkDoctorTLS(context.Background(), cfg).Status)
+
+	cfg.Server.TLSDomain = "does-not-exist.invalid"
+	c := checkDoctorTLS(context.Background(), cfg)
+	testutil.Equal(t, doctorFail, c.Status)
+	testutil.Contains(t, c.Hint, "A or AAAA record")
+}
+
+func TestURLHostPort(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"https://hooks.example.com/email", "hooks.example.com:443"},
+		{"http://hooks.example.com/email", "hooks.example.com:80"},
+		{"https://hooks.example.com:8443/email", "hooks.example.com:8443"},
+	}
+	for _, tt := range tests {
+		got, err := urlHostPort(tt.in)
+		testutil.NoError(t, err)
+		testutil.Equal(t, tt.want, got)
+	}
+	_, err := urlHostPort("not a url")
+	testutil.NotNil(t, err)
+}
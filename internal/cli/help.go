@@ -56,6 +56,7 @@ func initHelp() {
 		"migrate": groupMigrate,
 
 		"config":    groupConfig,
+		"doctor":    groupConfig,
 		"init":      groupConfig,
 		"mcp":       groupConfig,
 		"version":   groupConfig,
@@ -2,10 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/allyourbase/ayb/internal/cli/ui"
+	"github.com/allyourbase/ayb/internal/migrate"
+	"github.com/allyourbase/ayb/internal/pbmigrate"
 	"github.com/allyourbase/ayb/internal/scaffold"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/typegen"
 	"github.com/spf13/cobra"
 )
 
@@ -15,13 +20,19 @@ var initCmd = &cobra.Command{
 	Long: fmt.Sprintf(`Scaffold a new project with AYB configuration, schema, SDK client,
 and context files for AI coding tools.
 
+With --from, the source database is introspected and schema.sql and
+src/types/ayb.d.ts are generated to match it instead of the starter schema.
+
 Available templates: %s
 
 Examples:
   ayb init my-app                         # React (default)
   ayb init my-app --template next         # Next.js
+  ayb init my-app --template svelte       # SvelteKit
   ayb init my-app --template express      # Express/Node backend
-  ayb init my-app --template plain        # Minimal TypeScript`, strings.Join(templateNames(), ", ")),
+  ayb init my-app --template plain        # Minimal TypeScript
+  ayb init my-app --from ./pb_data        # Schema and types from PocketBase
+  ayb init my-app --from postgres://...   # Schema and types from PostgreSQL`, strings.Join(templateNames(), ", ")),
 	Args: cobra.ExactArgs(1),
 	RunE: runInit,
 }
@@ -29,6 +40,7 @@ Examples:
 func init() {
 	initCmd.Flags().StringP("template", "t", "react",
 		fmt.Sprintf("Project template (%s)", strings.Join(templateNames(), ", ")))
+	initCmd.Flags().String("from", "", "Generate schema.sql and types from an existing database (path to pb_data or postgres:// URL)")
 }
 
 func templateNames() []string {
@@ -43,6 +55,7 @@ func templateNames() []string {
 func runInit(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	tmpl, _ := cmd.Flags().GetString("template")
+	from, _ := cmd.Flags().GetString("from")
 
 	if !scaffold.IsValidTemplate(tmpl) {
 		return fmt.Errorf("unknown template %q (available: %s)", tmpl, strings.Join(templateNames(), ", "))
@@ -58,11 +71,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Creating %s project: %s\n", ui.BrandEmoji, tmpl, name)
 	}
 
-	err := scaffold.Run(scaffold.Options{
+	opts := scaffold.Options{
 		Name:     name,
 		Template: scaffold.Template(tmpl),
-	})
-	if err != nil {
+	}
+	if from != "" {
+		var err error
+		if opts.SchemaSQL, opts.Types, err = schemaFromSource(from); err != nil {
+			return err
+		}
+	}
+	if err := scaffold.Run(opts); err != nil {
 		return err
 	}
 
@@ -74,10 +93,82 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  %s\n", dim("Next steps:", useColor))
 	fmt.Printf("  cd %s\n", name)
-	fmt.Printf("  ayb start\n")
-	fmt.Printf("  ayb sql < schema.sql\n")
+	if from != "" && migrate.DetectSource(from) == migrate.SourcePocketBase {
+		// The migration creates the tables in schema.sql and imports the data.
+		fmt.Printf("  ayb start --from %s\n", from)
+	} else {
+		fmt.Printf("  ayb start\n")
+		fmt.Printf("  ayb sql < schema.sql\n")
+	}
 	fmt.Printf("  npm install\n")
 	fmt.Printf("  npm run dev\n")
 
 	return nil
 }
+
+// schemaFromSource introspects the database a project is created from and
+// returns its schema as a SQL script and as TypeScript types.
+func schemaFromSource(from string) (schemaSQL, types string, err error) {
+	var sc *schema.SchemaCache
+	source := migrate.DetectSource(from)
+	switch source {
+	case migrate.SourcePocketBase:
+		report, err := pbmigrate.Analyze(from)
+		if err != nil {
+			return "", "", fmt.Errorf("analysis failed: %w", err)
+		}
+		report.PrintReport(os.Stderr)
+
+		reader, err := pbmigrate.NewReader(from)
+		if err != nil {
+			return "", "", err
+		}
+		defer reader.Close()
+		collections, err := reader.ReadCollections()
+		if err != nil {
+			return "", "", fmt.Errorf("reading collections: %w", err)
+		}
+		schemaSQL = pbmigrate.BuildSchemaSQL(collections)
+		sc = pbmigrate.BuildSchemaCache(collections)
+
+	case migrate.SourcePostgres, migrate.SourceSupabase:
+		if sc, err = introspectDatabase(from); err != nil {
+			return "", "", err
+		}
+		if source == migrate.SourceSupabase {
+			// Supabase's own auth, storage and realtime schemas aren't
+			// part of the app.
+			sc = onlySchema(sc, "public")
+		}
+		schemaSQL = schema.DDL(sc)
+
+	default:
+		return "", "", fmt.Errorf("--from supports a PocketBase pb_data directory or a postgres:// URL, got %q", from)
+	}
+
+	if schemaSQL == "" {
+		return "", "", fmt.Errorf("no tables found in %s", redactURL(from))
+	}
+	return schemaSQL, typegen.TypeScript(sc), nil
+}
+
+// onlySchema returns a copy of sc restricted to the tables and enums of one
+// schema.
+func onlySchema(sc *schema.SchemaCache, name string) *schema.SchemaCache {
+	out := &schema.SchemaCache{
+		Tables:  map[string]*schema.Table{},
+		Enums:   map[uint32]*schema.EnumType{},
+		Schemas: []string{name},
+	}
+	for key, t := range sc.Tables {
+		if t.Schema == name {
+			out.Tables[key] = t
+		}
+	}
+	for oid, e := range sc.Enums {
+		if e.Schema == name {
+			out.Enums[oid] = e
+		}
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+// writePocketBaseFixture creates a pb_data directory whose data.db holds a
+// users auth collection, a posts collection and a view.
+func writePocketBaseFixture(t *testing.T) string {
+	t.Helper()
+	pbData := filepath.Join(t.TempDir(), "pb_data")
+	testutil.NoError(t, os.MkdirAll(pbData, 0o755))
+
+	db, err := sql.Open("sqlite", filepath.Join(pbData, "data.db"))
+	testutil.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE _collections (
+			id TEXT PRIMARY KEY, name TEXT, type TEXT, system INTEGER, schema TEXT, indexes TEXT,
+			listRule TEXT, viewRule TEXT, createRule TEXT, updateRule TEXT, deleteRule TEXT,
+			options TEXT, created TEXT
+		);
+		INSERT INTO _collections (id, name, type, system, schema, indexes, options, created) VALUES
+			('c1', 'users', 'auth', 0, '[{"name":"name","type":"text"}]', '[]', '{}', '2024-01-01'),
+			('c2', 'blog_posts', 'base', 0,
+			 '[{"name":"title","type":"text","required":true},{"name":"rating","type":"number"},{"name":"published","type":"bool"},{"name":"tags","type":"select","maxSelect":5}]',
+			 '[]', '{}', '2024-01-02'),
+			('c3', 'top_posts', 'view', 0, '[]', '[]', '{"query":"SELECT id, title FROM blog_posts"}', '2024-01-03');
+		CREATE TABLE blog_posts (id TEXT PRIMARY KEY, title TEXT, rating REAL, published INTEGER, tags TEXT);
+		INSERT INTO blog_posts (id, title) VALUES ('p1', 'Hello');
+		CREATE TABLE users (id TEXT PRIMARY KEY, name TEXT);`)
+	testutil.NoError(t, err)
+	return pbData
+}
+
+func runInitFrom(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	resetJSONFlag()
+	initCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		initCmd.Flags().Set("from", "")
+		initCmd.Flags().Set("template", "react")
+	})
+
+	var err error
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs(append([]string{"init"}, args...))
+		err = rootCmd.Execute()
+	})
+	return out, err
+}
+
+func TestInitFromPocketBaseGeneratesSchemaAndTypes(t *testing.T) {
+	pbData := writePocketBaseFixture(t)
+	t.Chdir(t.TempDir())
+
+	out, err := runInitFrom(t, "imported", "--template", "plain", "--from", pbData)
+	testutil.NoError(t, err)
+	testutil.Contains(t, out, "ayb start --from "+pbData)
+
+	schemaSQL, err := os.ReadFile(filepath.Join("imported", "schema.sql"))
+	testutil.NoError(t, err)
+	testutil.Contains(t, string(schemaSQL), `CREATE TABLE "blog_posts"`)
+	testutil.Contains(t, string(schemaSQL), `"title" TEXT NOT NULL`)
+	testutil.Contains(t, string(schemaSQL), `CREATE VIEW "top_posts" AS SELECT id, title FROM blog_posts;`)
+	testutil.False(t, strings.Contains(string(schemaSQL), "CREATE TABLE IF NOT EXISTS items"), "starter schema should be replaced")
+	testutil.False(t, strings.Contains(string(schemaSQL), `"users"`), "auth collections migrate to AYB users")
+
+	types, err := os.ReadFile(filepath.Join("imported", "src", "types", "ayb.d.ts"))
+	testutil.NoError(t, err)
+	testutil.Contains(t, string(types), "export interface BlogPosts {")
+	testutil.Contains(t, string(types), "  title: string;")
+	testutil.Contains(t, string(types), "  rating: number | null;")
+	testutil.Contains(t, string(types), "  published: boolean | null;")
+	testutil.Contains(t, string(types), "  tags: unknown[] | null;")
+	testutil.Contains(t, string(types), `export type BlogPostsCreate = Omit<BlogPosts, "id" | "created" | "updated">;`)
+}
+
+func TestInitFromPocketBaseWithoutCollections(t *testing.T) {
+	pbData := filepath.Join(t.TempDir(), "pb_data")
+	testutil.NoError(t, os.MkdirAll(pbData, 0o755))
+	db, err := sql.Open("sqlite", filepath.Join(pbData, "data.db"))
+	testutil.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE _collections (
+		id TEXT, name TEXT, type TEXT, system INTEGER, schema TEXT, indexes TEXT,
+		listRule TEXT, viewRule TEXT, createRule TEXT, updateRule TEXT, deleteRule TEXT,
+		options TEXT, created TEXT)`)
+	testutil.NoError(t, err)
+	db.Close()
+	t.Chdir(t.TempDir())
+
+	_, err = runInitFrom(t, "empty", "--from", pbData)
+	testutil.ErrorContains(t, err, "no tables found")
+	_, statErr := os.Stat("empty")
+	testutil.True(t, os.IsNotExist(statErr), "no project should be created")
+}
+
+func TestInitFromRejectsUnsupportedSource(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := runInitFrom(t, "fb", "--from", "firebase://my-project")
+	testutil.ErrorContains(t, err, "--from supports a PocketBase pb_data directory or a postgres:// URL")
+}
@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"text/tabwriter"
+	"time"
 
+	"github.com/allyourbase/ayb/internal/jobs"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +27,28 @@ var jobsListCmd = &cobra.Command{
 	RunE:  runJobsList,
 }
 
+var jobsEnqueueCmd = &cobra.Command{
+	Use:   "enqueue <type>",
+	Short: "Enqueue a job",
+	Long: `Enqueue a job for a type with a registered handler. The server rejects
+unknown job types.
+
+Examples:
+  ayb jobs enqueue stale_session_cleanup
+  ayb jobs enqueue webhook_delivery_prune --payload '{"retention_hours":24}'
+  ayb jobs enqueue stale_session_cleanup --priority 10 --run-at 15m
+  ayb jobs enqueue stale_session_cleanup --run-at 2026-03-01T02:00:00Z`,
+	Args: cobra.ExactArgs(1),
+	RunE: runJobsEnqueue,
+}
+
+var jobsStatusCmd = &cobra.Command{
+	Use:   "status <job-id>",
+	Short: "Show a job's state, attempts and last error",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsStatus,
+}
+
 var jobsRetryCmd = &cobra.Command{
 	Use:   "retry <job-id>",
 	Short: "Retry a failed job",
@@ -89,7 +116,14 @@ func init() {
 	jobsListCmd.Flags().String("type", "", "Filter by job type")
 	jobsListCmd.Flags().Int("limit", 50, "Maximum results")
 
+	jobsEnqueueCmd.Flags().String("payload", "", "JSON payload passed to the handler")
+	jobsEnqueueCmd.Flags().Int("max-attempts", 0, "Attempts before the job fails permanently (default 3)")
+	jobsEnqueueCmd.Flags().Int("priority", 0, "Priority; higher-priority jobs are claimed first")
+	jobsEnqueueCmd.Flags().String("run-at", "", "When to run: an RFC3339 time or a delay such as 15m (default now)")
+
 	jobsCmd.AddCommand(jobsListCmd)
+	jobsCmd.AddCommand(jobsEnqueueCmd)
+	jobsCmd.AddCommand(jobsStatusCmd)
 	jobsCmd.AddCommand(jobsRetryCmd)
 	jobsCmd.AddCommand(jobsCancelCmd)
 
@@ -180,6 +214,107 @@ func runJobsList(cmd *cobra.Command, _ []string) error {
 	return w.Flush()
 }
 
+func runJobsEnqueue(cmd *cobra.Command, args []string) error {
+	payloadStr, _ := cmd.Flags().GetString("payload")
+	maxAttempts, _ := cmd.Flags().GetInt("max-attempts")
+	priority, _ := cmd.Flags().GetInt("priority")
+	runAtStr, _ := cmd.Flags().GetString("run-at")
+
+	req := map[string]any{"type": args[0], "priority": priority}
+	if payloadStr != "" {
+		if !json.Valid([]byte(payloadStr)) {
+			return fmt.Errorf("invalid --payload JSON")
+		}
+		req["payload"] = json.RawMessage(payloadStr)
+	}
+	if maxAttempts != 0 {
+		req["maxAttempts"] = maxAttempts
+	}
+	if runAtStr != "" {
+		runAt, err := parseRunAt(runAtStr, time.Now())
+		if err != nil {
+			return err
+		}
+		req["runAt"] = runAt
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("serializing job: %w", err)
+	}
+	resp, respBody, err := adminRequest(cmd, "POST", "/api/admin/jobs", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return serverError(resp.StatusCode, respBody)
+	}
+
+	if outputFormat(cmd) == "json" {
+		fmt.Println(string(respBody))
+		return nil
+	}
+	var job jobs.Job
+	if err := json.Unmarshal(respBody, &job); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	fmt.Printf("Job %s enqueued (%s, runs at %s)\n", job.ID, job.Type, job.RunAt.Local().Format(time.RFC3339))
+	return nil
+}
+
+// parseRunAt parses --run-at: an RFC3339 time, or a delay from now.
+func parseRunAt(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid --run-at %q: use an RFC3339 time or a delay such as 15m", s)
+	}
+	return now.Add(d), nil
+}
+
+func runJobsStatus(cmd *cobra.Command, args []string) error {
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/jobs/"+url.PathEscape(args[0]), nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return serverError(resp.StatusCode, body)
+	}
+
+	if outputFormat(cmd) == "json" {
+		fmt.Println(string(body))
+		return nil
+	}
+	var job jobs.Job
+	if err := json.Unmarshal(body, &job); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", job.ID)
+	fmt.Fprintf(w, "Type:\t%s\n", job.Type)
+	fmt.Fprintf(w, "State:\t%s\n", job.State)
+	fmt.Fprintf(w, "Attempts:\t%d/%d\n", job.Attempts, job.MaxAttempts)
+	fmt.Fprintf(w, "Priority:\t%d\n", job.Priority)
+	fmt.Fprintf(w, "Run at:\t%s\n", job.RunAt.Local().Format(time.RFC3339))
+	if job.LastRunAt != nil {
+		fmt.Fprintf(w, "Last run:\t%s\n", job.LastRunAt.Local().Format(time.RFC3339))
+	}
+	if job.CompletedAt != nil {
+		fmt.Fprintf(w, "Completed:\t%s\n", job.CompletedAt.Local().Format(time.RFC3339))
+	}
+	if job.CanceledAt != nil {
+		fmt.Fprintf(w, "Canceled:\t%s\n", job.CanceledAt.Local().Format(time.RFC3339))
+	}
+	if job.LastError != nil {
+		fmt.Fprintf(w, "Last error:\t%s\n", *job.LastError)
+	}
+	fmt.Fprintf(w, "Payload:\t%s\n", job.Payload)
+	return w.Flush()
+}
+
 func runJobsRetry(cmd *cobra.Command, args []string) error {
 	jobID := args[0]
 	resp, body, err := adminRequest(cmd, "POST", "/api/admin/jobs/"+jobID+"/retry", nil)
@@ -405,3 +540,42 @@ func runSchedulesDisable(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Schedule %q disabled\n", sched["name"])
 	return nil
 }
+
+// fetchJobStats returns the queue stats from /api/admin/jobs/stats, or nil
+// when the server runs without the job queue.
+func fetchJobStats(cmd *cobra.Command) (*jobs.QueueStats, error) {
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/jobs/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusServiceUnavailable, http.StatusNotFound:
+		return nil, nil // jobs disabled, or a server without the endpoint
+	default:
+		return nil, serverError(resp.StatusCode, body)
+	}
+	var stats jobs.QueueStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, fmt.Errorf("parsing job stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// writeJobStats writes the jobs section of ayb stats.
+func writeJobStats(w io.Writer, s *jobs.QueueStats, now time.Time) {
+	oldest := "-"
+	if s.OldestQueuedAt != nil {
+		oldest = fmt.Sprintf("enqueued %s ago", now.Sub(*s.OldestQueuedAt).Round(time.Second))
+	}
+	fmt.Fprintln(w, "Jobs")
+	fmt.Fprintln(w, "─────────────────────")
+	fmt.Fprintf(w, "  %-20s %d\n", "queued:", s.Queued)
+	fmt.Fprintf(w, "  %-20s %s\n", "oldest queued:", oldest)
+	fmt.Fprintf(w, "  %-20s %d\n", "running:", s.Running)
+	fmt.Fprintf(w, "  %-20s %d\n", "failed (dead):", s.Failed)
+	fmt.Fprintf(w, "  %-20s %.1f%% (%d of %d finished)\n", "failure rate (1h):",
+		s.FailureRate*100, s.FailedLastHour, s.CompletedLastHour+s.FailedLastHour)
+	fmt.Fprintf(w, "  %-20s %.0f%% (%d of %d workers busy)\n", "worker utilization:",
+		s.WorkerUtilization*100, s.BusyWorkers, s.Workers)
+}
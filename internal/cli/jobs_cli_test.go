@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/allyourbase/ayb/internal/testutil"
 )
@@ -143,6 +144,145 @@ func TestJobsListFilterType(t *testing.T) {
 	testutil.Equal(t, "stale_session_cleanup", receivedType)
 }
 
+// --- jobs enqueue ---
+
+func resetJobsEnqueueFlags(t *testing.T) {
+	t.Cleanup(func() {
+		for name, def := range map[string]string{"payload": "", "max-attempts": "0", "priority": "0", "run-at": ""} {
+			jobsEnqueueCmd.Flags().Set(name, def)
+		}
+	})
+}
+
+func TestJobsEnqueueSuccess(t *testing.T) {
+	resetJSONFlag()
+	resetJobsEnqueueFlags(t)
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "POST", r.Method)
+		testutil.Equal(t, "/api/admin/jobs", r.URL.Path)
+		testutil.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":      "44444444-4444-4444-4444-444444444444",
+			"type":    "webhook_delivery_prune",
+			"state":   "queued",
+			"runAt":   "2026-03-01T02:00:00Z",
+			"payload": map[string]any{"retention_hours": 24},
+		})
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"jobs", "enqueue", "webhook_delivery_prune",
+			"--url", srv.URL, "--admin-token", "tok",
+			"--payload", `{"retention_hours":24}`,
+			"--max-attempts", "5", "--priority", "10",
+			"--run-at", "2026-03-01T02:00:00Z",
+		})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	testutil.Contains(t, output, "Job 44444444-4444-4444-4444-444444444444 enqueued (webhook_delivery_prune")
+	testutil.Equal[any](t, "webhook_delivery_prune", received["type"])
+	testutil.Equal[any](t, 24.0, received["payload"].(map[string]any)["retention_hours"])
+	testutil.Equal[any](t, 5.0, received["maxAttempts"])
+	testutil.Equal[any](t, 10.0, received["priority"])
+	testutil.Equal[any](t, "2026-03-01T02:00:00Z", received["runAt"])
+}
+
+func TestJobsEnqueueUnknownType(t *testing.T) {
+	resetJSONFlag()
+	resetJobsEnqueueFlags(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{
+			"code":    400,
+			"message": `unknown job type "send_invoice"; registered types: stale_session_cleanup`,
+		})
+	}))
+	defer srv.Close()
+
+	rootCmd.SetArgs([]string{"jobs", "enqueue", "send_invoice", "--url", srv.URL, "--admin-token", "tok"})
+	err := rootCmd.Execute()
+	testutil.ErrorContains(t, err, `unknown job type "send_invoice"`)
+}
+
+func TestJobsEnqueueInvalidPayloadJSON(t *testing.T) {
+	resetJSONFlag()
+	resetJobsEnqueueFlags(t)
+	rootCmd.SetArgs([]string{"jobs", "enqueue", "test", "--url", "http://localhost:0", "--admin-token", "tok",
+		"--payload", "{bad"})
+	err := rootCmd.Execute()
+	testutil.ErrorContains(t, err, "invalid --payload JSON")
+}
+
+func TestParseRunAt(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseRunAt("2026-03-02T08:30:00Z", now)
+	testutil.NoError(t, err)
+	testutil.True(t, got.Equal(time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)), "got %v", got)
+
+	got, err = parseRunAt("15m", now)
+	testutil.NoError(t, err)
+	testutil.True(t, got.Equal(now.Add(15*time.Minute)), "got %v", got)
+
+	for _, bad := range []string{"tomorrow", "-5m"} {
+		_, err = parseRunAt(bad, now)
+		testutil.ErrorContains(t, err, "invalid --run-at")
+	}
+}
+
+// --- jobs status ---
+
+func TestJobsStatus(t *testing.T) {
+	resetJSONFlag()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "/api/admin/jobs/33333333-3333-3333-3333-333333333333", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":          "33333333-3333-3333-3333-333333333333",
+			"type":        "stale_session_cleanup",
+			"state":       "failed",
+			"attempts":    3,
+			"maxAttempts": 3,
+			"priority":    2,
+			"runAt":       "2026-02-22T10:00:00Z",
+			"lastError":   "connection refused",
+			"payload":     map[string]any{},
+		})
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"jobs", "status", "33333333-3333-3333-3333-333333333333", "--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	testutil.Contains(t, output, "State:       failed")
+	testutil.Contains(t, output, "Attempts:    3/3")
+	testutil.Contains(t, output, "Priority:    2")
+	testutil.Contains(t, output, "Last error:  connection refused")
+}
+
+func TestJobsStatusNotFound(t *testing.T) {
+	resetJSONFlag()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "job not found"})
+	}))
+	defer srv.Close()
+
+	rootCmd.SetArgs([]string{"jobs", "status", "99999999-9999-9999-9999-999999999999", "--url", srv.URL, "--admin-token", "tok"})
+	err := rootCmd.Execute()
+	testutil.ErrorContains(t, err, "job not found")
+}
+
 // --- jobs retry ---
 
 func TestJobsRetrySuccess(t *testing.T) {
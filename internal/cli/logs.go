@@ -1,15 +1,26 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/logfile"
+	"github.com/allyourbase/ayb/internal/server"
 	"github.com/spf13/cobra"
 )
 
@@ -22,42 +33,74 @@ Examples:
   ayb logs                   # Show last 100 log lines
   ayb logs -n 50             # Show last 50 log lines
   ayb logs --follow          # Stream logs in real-time
-  ayb logs --level error     # Filter by log level`,
+  ayb logs --level error     # Show errors only
+  ayb logs --since 15m       # Show lines from the last 15 minutes
+  ayb logs --since 2026-01-02T15:00:00Z --until 2026-01-02T16:00:00Z
+
+--since and --until take an RFC3339 time or a duration before now. The
+filters are applied by the server, so --follow streams only matching lines.
+
+When logging.output is a file path, the logs are read from that file
+instead of the running server.`,
 	RunE: runLogs,
 }
 
 func init() {
 	logsCmd.Flags().IntP("lines", "n", 100, "Number of log lines to show")
 	logsCmd.Flags().BoolP("follow", "f", false, "Stream logs in real-time")
-	logsCmd.Flags().String("level", "", "Filter by log level (debug, info, warn, error)")
+	logsCmd.Flags().String("level", "", "Minimum log level (debug, info, warn, error)")
+	logsCmd.Flags().String("since", "", "Show lines logged at or after this time (RFC3339 or duration, e.g. 1h)")
+	logsCmd.Flags().String("until", "", "Show lines logged at or before this time (RFC3339 or duration, e.g. 5m)")
+	logsCmd.Flags().String("config", "", "Path to ayb.toml config file")
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
 	lines, _ := cmd.Flags().GetInt("lines")
 	follow, _ := cmd.Flags().GetBool("follow")
 	level, _ := cmd.Flags().GetString("level")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
 
-	url := serverURL()
-	if url == "" {
+	// The server parses the same options; check them first for a clear error.
+	filter, err := logfile.ParseFilter(level, since, until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	configPath, _ := cmd.Flags().GetString("config")
+	cfg, err := config.Load(configPath, nil)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if path := cfg.Logging.LogFile(); path != "" {
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return tailLogFile(ctx, os.Stdout, path, lines, follow, filter)
+	}
+
+	baseURL := serverURL()
+	if baseURL == "" {
 		return fmt.Errorf("cannot determine server URL (is AYB running?)")
 	}
 
-	// Build request URL
-	endpoint := url + "/api/admin/logs"
-	params := "?lines=" + strconv.Itoa(lines)
+	params := url.Values{"lines": {strconv.Itoa(lines)}}
 	if follow {
-		params += "&follow=true"
+		params.Set("follow", "true")
 	}
-	if level != "" {
-		params += "&level=" + level
+	for name, v := range map[string]string{"level": level, "since": since, "until": until} {
+		if v != "" {
+			params.Set(name, v)
+		}
 	}
 
-	client := &http.Client{Timeout: 0} // no timeout for streaming
-	if !follow {
-		client.Timeout = 10 * time.Second
+	client := cliHTTPClient
+	if follow {
+		streaming := *cliHTTPClient
+		streaming.Timeout = 0 // the stream runs until interrupted
+		client = &streaming
 	}
 
-	req, err := http.NewRequest("GET", endpoint+params, nil)
+	req, err := http.NewRequest("GET", baseURL+"/api/admin/logs?"+params.Encode(), nil)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
@@ -84,16 +127,159 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		return serverError(resp.StatusCode, body)
 	}
 
-	format := outputFormat(cmd)
-	if format == "json" {
-		// Pass through raw JSON
+	if outputFormat(cmd) == "json" {
+		// Pass through the raw JSON or NDJSON stream.
 		_, err = io.Copy(os.Stdout, resp.Body)
 		return err
 	}
+	return printLogEntries(os.Stdout, resp)
+}
+
+// printLogEntries writes the entries of a logs response, either a JSON body
+// or an NDJSON stream, as text lines.
+func printLogEntries(w io.Writer, resp *http.Response) error {
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/x-ndjson") {
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var e server.LogEntry
+			if err := dec.Decode(&e); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("reading log stream: %w", err)
+			}
+			printLogEntry(w, e)
+		}
+	}
 
-	// Stream text output
-	_, err = io.Copy(os.Stdout, resp.Body)
-	return err
+	var body struct {
+		Entries []server.LogEntry `json:"entries"`
+		Message string            `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	if body.Message != "" {
+		fmt.Fprintln(os.Stderr, body.Message)
+	}
+	for _, e := range body.Entries {
+		printLogEntry(w, e)
+	}
+	return nil
+}
+
+// printLogEntry writes e as one text line: time, level, message, then the
+// attributes sorted by key.
+func printLogEntry(w io.Writer, e server.LogEntry) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", e.Time.Format(time.RFC3339), e.Level, e.Message)
+	for _, k := range slices.Sorted(maps.Keys(e.Attrs)) {
+		fmt.Fprintf(&b, " %s=%v", k, e.Attrs[k])
+	}
+	fmt.Fprintln(w, b.String())
+}
+
+// logFollowInterval is how often "ayb logs --follow" polls a log file for
+// new lines.
+var logFollowInterval = 250 * time.Millisecond
+
+// tailLogFile writes the last n lines of the log file at path that match
+// filter to w. With follow it then streams new matching lines, reopening the
+// file after it is rotated, until ctx is done.
+func tailLogFile(ctx context.Context, w io.Writer, path string, n int, follow bool, filter logfile.Filter) error {
+	keep := filter.MatchLine
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	defer func() { f.Close() }()
+
+	var tail []string
+	r := bufio.NewReader(f)
+	var offset int64
+	pending, err := readLogLines(r, "", &offset, func(line string) {
+		if n > 0 && keep(line) {
+			tail = append(tail, line)
+			if len(tail) > n {
+				tail = tail[1:]
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range tail {
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	if !follow {
+		if pending != "" && n > 0 && keep(pending) {
+			_, err = io.WriteString(w, pending+"\n")
+		}
+		return err
+	}
+
+	emit := func(line string) {
+		if keep(line) {
+			io.WriteString(w, line) //nolint:errcheck
+		}
+	}
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if pending, err = readLogLines(r, pending, &offset, emit); err != nil {
+			return err
+		}
+
+		// A rotated file is renamed aside and a new one created at path; a
+		// truncated one shrinks below what has been read.
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // between rename and create
+		}
+		cur, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("reading log file: %w", err)
+		}
+		if os.SameFile(info, cur) && info.Size() >= offset {
+			continue
+		}
+		next, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		// Finish lines written to the old file before it was rotated.
+		if pending, err = readLogLines(r, pending, &offset, emit); err == nil && pending != "" {
+			emit(pending + "\n")
+		}
+		f.Close()
+		f, r, offset, pending = next, bufio.NewReader(next), 0, ""
+	}
+}
+
+// readLogLines reads the complete lines available from r, passing each (with
+// its newline) to emit. pending is a partial line carried over from the
+// previous call; the partial line left at EOF is returned for the next one.
+func readLogLines(r *bufio.Reader, pending string, offset *int64, emit func(string)) (string, error) {
+	for {
+		chunk, err := r.ReadString('\n')
+		*offset += int64(len(chunk))
+		pending += chunk
+		if err == io.EOF {
+			return pending, nil
+		}
+		if err != nil {
+			return pending, fmt.Errorf("reading log file: %w", err)
+		}
+		emit(pending)
+		pending = ""
+	}
 }
 
 // adminToken returns the admin token, checking (in order):
@@ -118,83 +304,218 @@ var statsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show AYB server statistics",
 	Long: `Display current server statistics including uptime, request counts,
-active connections, and database pool info.
+active connections, and database pool info, and the estimated row count,
+size and last ANALYZE time of each table.
+
+With --suggest-indexes, list candidate indexes for columns that logged slow
+queries (database.slow_query_ms) filter on and no index covers. Suggestions
+are advisory: nothing is created.
+
+With --watch, refresh every interval (2s by default) until Ctrl+C, showing
+the request rate, database pool usage, job queue depth and today's SMS counts
+with a trend line for each.
 
 Examples:
-  ayb stats             # Show stats in table format
-  ayb stats --json      # Show stats as JSON`,
+  ayb stats                      # Show stats in table format
+  ayb stats --json               # Show stats as JSON
+  ayb stats --watch              # Refresh every 2 seconds
+  ayb stats --watch=10s          # Refresh every 10 seconds
+  ayb stats --suggest-indexes    # Suggest indexes from slow queries`,
 	RunE: runStats,
 }
 
+func init() {
+	statsCmd.Flags().Bool("suggest-indexes", false, "Suggest indexes for columns filtered on by slow queries")
+	statsCmd.Flags().String("admin-token", "", "Admin token (or set AYB_ADMIN_TOKEN)")
+	statsCmd.Flags().String("url", "", "Server URL (default http://127.0.0.1:8090)")
+	statsCmd.Flags().Duration("watch", 0, "Refresh every interval until interrupted (--watch=5s; --watch alone refreshes every 2s)")
+	statsCmd.Flags().Lookup("watch").NoOptDefVal = "2s"
+}
+
 func runStats(cmd *cobra.Command, args []string) error {
-	url := serverURL()
-	if url == "" {
-		return fmt.Errorf("cannot determine server URL (is AYB running?)")
+	if suggest, _ := cmd.Flags().GetBool("suggest-indexes"); suggest {
+		return runStatsSuggestIndexes(cmd)
+	}
+	if cmd.Flags().Changed("watch") {
+		interval, _ := cmd.Flags().GetDuration("watch")
+		return runStatsWatch(cmd, interval)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	req, err := http.NewRequest("GET", url+"/api/admin/stats", nil)
+	body, err := fetchStats(cmd)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return err
+	}
+	jobStats, err := fetchJobStats(cmd)
+	if err != nil {
+		return err
+	}
+	collections, err := fetchCollectionStats(cmd)
+	if err != nil {
+		return err
 	}
 
-	token := adminToken()
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	var stats map[string]any
+	if err := json.Unmarshal(body, &stats); err != nil {
+		// Not JSON, print raw
+		fmt.Println(string(body))
+		return nil
 	}
+	keys := slices.Sorted(maps.Keys(stats))
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("connecting to server: %w", err)
+	switch outputFormat(cmd) {
+	case "json":
+		if jobStats != nil {
+			stats["jobs"] = jobStats
+		}
+		if collections != nil {
+			stats["collections"] = collections
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case "csv":
+		vals := make([]string, len(keys))
+		for i, k := range keys {
+			vals[i] = fmt.Sprint(stats[k])
+		}
+		return writeCSVStdout(keys, [][]string{vals})
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("stats endpoint not available (server may need to be updated)")
+	// Table format
+	fmt.Println("AYB Server Statistics")
+	fmt.Println("─────────────────────")
+	for _, k := range keys {
+		fmt.Printf("  %-20s %v\n", k+":", stats[k])
 	}
+	if jobStats != nil {
+		fmt.Println()
+		writeJobStats(os.Stdout, jobStats, time.Now())
+	}
+	if len(collections) > 0 {
+		fmt.Println()
+		return writeCollectionStats(os.Stdout, collections)
+	}
+	return nil
+}
 
+// fetchStats returns the body of GET /api/admin/stats.
+func fetchStats(cmd *cobra.Command) ([]byte, error) {
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("stats endpoint not available (server may need to be updated)")
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return serverError(resp.StatusCode, body)
+		return nil, serverError(resp.StatusCode, body)
 	}
+	return body, nil
+}
+
+// collectionStats mirrors api.CollectionStats.
+type collectionStats struct {
+	Schema        string     `json:"schema"`
+	Table         string     `json:"table"`
+	Kind          string     `json:"kind"`
+	EstimatedRows *int64     `json:"estimatedRows"`
+	TotalBytes    int64      `json:"totalBytes"`
+	TableBytes    int64      `json:"tableBytes"`
+	IndexBytes    int64      `json:"indexBytes"`
+	LastAnalyzed  *time.Time `json:"lastAnalyzed"`
+}
 
-	body, err := io.ReadAll(resp.Body)
+// fetchCollectionStats returns the items of GET /api/admin/collections/stats,
+// or nil from a server without a database or without the endpoint.
+func fetchCollectionStats(cmd *cobra.Command) ([]collectionStats, error) {
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/collections/stats", nil)
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, serverError(resp.StatusCode, body)
 	}
+	var result struct {
+		Items []collectionStats `json:"items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parsing collection stats: %w", err)
+	}
+	return result.Items, nil
+}
 
-	format := outputFormat(cmd)
-	if format == "json" {
-		fmt.Println(string(body))
-		return nil
+// writeCollectionStats writes the collections section of ayb stats, largest
+// first as the server sorts them.
+func writeCollectionStats(w io.Writer, stats []collectionStats) error {
+	fmt.Fprintln(w, "Collections")
+	fmt.Fprintln(w, "─────────────────────")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TABLE\tROWS (EST.)\tTOTAL\tTABLE\tINDEXES\tLAST ANALYZED")
+	for _, s := range stats {
+		name := s.Table
+		if s.Schema != "public" {
+			name = s.Schema + "." + s.Table
+		}
+		rows := "-"
+		if s.EstimatedRows != nil {
+			rows = strconv.FormatInt(*s.EstimatedRows, 10)
+		}
+		analyzed := "never"
+		if s.LastAnalyzed != nil {
+			analyzed = s.LastAnalyzed.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n", name, rows,
+			formatBytes(s.TotalBytes), formatBytes(s.TableBytes), formatBytes(s.IndexBytes), analyzed)
 	}
+	return tw.Flush()
+}
 
-	// Parse JSON for table display
-	var stats map[string]interface{}
-	if err := json.Unmarshal(body, &stats); err != nil {
-		// Not JSON, print raw
-		fmt.Println(string(body))
-		return nil
+// indexSuggestion mirrors api.IndexSuggestion.
+type indexSuggestion struct {
+	Schema      string `json:"schema"`
+	Table       string `json:"table"`
+	Column      string `json:"column"`
+	SlowQueries int    `json:"slowQueries"`
+	SQL         string `json:"sql"`
+}
+
+func runStatsSuggestIndexes(cmd *cobra.Command) error {
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/stats/index-suggestions", nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("index suggestions not available (server may need to be updated)")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return serverError(resp.StatusCode, body)
 	}
 
-	if format == "csv" {
-		var cols []string
-		var vals []string
-		for k, v := range stats {
-			cols = append(cols, k)
-			vals = append(vals, fmt.Sprint(v))
-		}
-		return writeCSVStdout(cols, [][]string{vals})
+	var result struct {
+		Items []indexSuggestion `json:"items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
 	}
 
-	// Table format
-	fmt.Println("AYB Server Statistics")
-	fmt.Println("─────────────────────")
-	for k, v := range stats {
-		fmt.Printf("  %-20s %v\n", k+":", v)
+	if outputFormat(cmd) == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result.Items)
 	}
 
+	if len(result.Items) == 0 {
+		fmt.Println("No index suggestions: no logged slow queries filter on an unindexed column.")
+		return nil
+	}
+	for _, s := range result.Items {
+		fmt.Printf("consider an index on %s(%s) — seen in %d slow queries\n", s.Table, s.Column, s.SlowQueries)
+		fmt.Printf("  %s;\n", s.SQL)
+	}
 	return nil
 }
 
@@ -207,19 +528,25 @@ var secretsCmd = &cobra.Command{
 var secretsRotateCmd = &cobra.Command{
 	Use:   "rotate",
 	Short: "Rotate the JWT secret",
-	Long: `Generate a new JWT secret and update the configuration.
-All existing tokens will be invalidated after rotation.
+	Long: `Generate a new JWT secret on the running server. New tokens are signed
+with the new secret; tokens signed with the old secret stay valid for an
+overlap window (auth.jwt_secret_overlap, default 15 minutes) so signed-in
+users aren't interrupted, and are rejected after it.
 
-WARNING: This will sign out all currently authenticated users.
+Use --overlap 0 to invalidate all existing tokens immediately, which signs
+out all currently authenticated users.
 
 Examples:
   ayb secrets rotate                    # Rotate JWT secret
+  ayb secrets rotate --overlap 1h       # Keep old tokens valid for an hour
+  ayb secrets rotate --overlap 0        # Invalidate all tokens now
   ayb secrets rotate --config ayb.toml  # Rotate in specific config file`,
 	RunE: runSecretsRotate,
 }
 
 func init() {
 	secretsRotateCmd.Flags().String("config", "", "Path to ayb.toml config file")
+	secretsRotateCmd.Flags().Duration("overlap", 0, "How long tokens signed with the old secret stay valid (default: auth.jwt_secret_overlap)")
 	secretsCmd.AddCommand(secretsRotateCmd)
 }
 
@@ -234,10 +561,21 @@ func runSecretsRotate(cmd *cobra.Command, args []string) error {
 	// If server is running, use the API
 	if url != "" {
 		client := &http.Client{Timeout: 10 * time.Second}
-		req, err := http.NewRequest("POST", url+"/api/admin/secrets/rotate", nil)
+		var reqBody io.Reader
+		if cmd.Flags().Changed("overlap") {
+			overlap, _ := cmd.Flags().GetDuration("overlap")
+			if overlap < 0 {
+				return fmt.Errorf("--overlap must not be negative")
+			}
+			reqBody = strings.NewReader(fmt.Sprintf(`{"overlapSeconds":%d}`, int(overlap.Seconds())))
+		}
+		req, err := http.NewRequest("POST", url+"/api/admin/secrets/rotate", reqBody)
 		if err != nil {
 			return fmt.Errorf("creating request: %w", err)
 		}
+		if reqBody != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
 
 		token := adminToken()
 		if token != "" {
@@ -259,18 +597,25 @@ func runSecretsRotate(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
 		}
 
-		format := outputFormat(cmd)
-		if format == "json" {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return fmt.Errorf("reading response: %w", err)
-			}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
+		if outputFormat(cmd) == "json" {
 			fmt.Println(string(body))
 			return nil
 		}
 
+		var result struct {
+			OverlapSeconds int `json:"overlapSeconds"`
+		}
+		_ = json.Unmarshal(body, &result)
 		fmt.Println("JWT secret rotated successfully.")
-		fmt.Println("All existing tokens have been invalidated.")
+		if result.OverlapSeconds > 0 {
+			fmt.Printf("Existing tokens remain valid for %s.\n", time.Duration(result.OverlapSeconds)*time.Second)
+		} else {
+			fmt.Println("All existing tokens have been invalidated.")
+		}
 		return nil
 	}
 
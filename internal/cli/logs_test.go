@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/logfile"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func runStatsSuggestIndexesCmd(t *testing.T, extraArgs ...string) string {
+	t.Helper()
+	statsCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		statsCmd.Flags().Set("suggest-indexes", "false")
+		statsCmd.Flags().Set("url", "")
+		statsCmd.Flags().Set("admin-token", "")
+	})
+	return captureStdout(t, func() {
+		args := append([]string{"stats", "--suggest-indexes", "--url", testAdminURL, "--admin-token", "tok"}, extraArgs...)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestStatsSuggestIndexes(t *testing.T) {
+	resetJSONFlag()
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "/api/admin/stats/index-suggestions", r.URL.Path)
+		testutil.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]any{
+			"items": []map[string]any{{
+				"schema": "public", "table": "posts", "column": "author_id", "slowQueries": 7,
+				"sql": `CREATE INDEX ON "public"."posts" ("author_id")`,
+			}},
+			"count": 1,
+		})
+	})
+
+	output := runStatsSuggestIndexesCmd(t)
+	testutil.Contains(t, output, "consider an index on posts(author_id) — seen in 7 slow queries")
+	testutil.Contains(t, output, `CREATE INDEX ON "public"."posts" ("author_id");`)
+}
+
+func TestStatsSuggestIndexesJSON(t *testing.T) {
+	resetJSONFlag()
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"items": []map[string]any{{"schema": "public", "table": "posts", "column": "author_id", "slowQueries": 7}},
+			"count": 1,
+		})
+	})
+
+	output := runStatsSuggestIndexesCmd(t, "--json")
+	var items []map[string]any
+	testutil.NoError(t, json.Unmarshal([]byte(output), &items))
+	testutil.SliceLen(t, items, 1)
+	testutil.Equal(t, "author_id", items[0]["column"])
+}
+
+// stubStatsServer serves /api/admin/stats, /api/admin/collections/stats
+// with collections and, when jobStats isn't nil, /api/admin/jobs/stats;
+// otherwise the job queue is disabled.
+func stubStatsServer(t *testing.T, jobStats *jobs.QueueStats, collections ...map[string]any) {
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/admin/stats":
+			json.NewEncoder(w).Encode(map[string]any{"uptime_seconds": 60, "goroutines": 9})
+		case "/api/admin/collections/stats":
+			json.NewEncoder(w).Encode(map[string]any{"items": append([]map[string]any{}, collections...), "count": len(collections)})
+		case "/api/admin/jobs/stats":
+			if jobStats == nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]any{"message": "job queue is not enabled"})
+				return
+			}
+			json.NewEncoder(w).Encode(jobStats)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+}
+
+func runStatsCmd(t *testing.T, extraArgs ...string) string {
+	t.Helper()
+	statsCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		statsCmd.Flags().Set("url", "")
+		statsCmd.Flags().Set("admin-token", "")
+	})
+	return captureStdout(t, func() {
+		rootCmd.SetArgs(append([]string{"stats", "--url", testAdminURL, "--admin-token", "tok"}, extraArgs...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestStatsShowsJobQueue(t *testing.T) {
+	resetJSONFlag()
+	oldest := time.Now().Add(-90 * time.Second)
+	stubStatsServer(t, &jobs.QueueStats{
+		Queued: 12, Running: 3, Failed: 2, OldestQueuedAt: &oldest,
+		CompletedLastHour: 6, FailedLastHour: 2, FailureRate: 0.25,
+		Workers: 4, BusyWorkers: 3, WorkerUtilization: 0.75,
+	})
+
+	output := runStatsCmd(t)
+	testutil.Contains(t, output, "uptime_seconds:      60")
+	testutil.Contains(t, output, "Jobs\n")
+	testutil.Contains(t, output, "queued:              12")
+	testutil.Contains(t, output, "oldest queued:       enqueued 1m30s ago")
+	testutil.Contains(t, output, "failed (dead):       2")
+	testutil.Contains(t, output, "failure rate (1h):   25.0% (2 of 8 finished)")
+	testutil.Contains(t, output, "worker utilization:  75% (3 of 4 workers busy)")
+}
+
+func TestStatsShowsJobQueueJSON(t *testing.T) {
+	resetJSONFlag()
+	stubStatsServer(t, &jobs.QueueStats{Queued: 5, Workers: 4})
+
+	output := runStatsCmd(t, "--json")
+	var stats map[string]any
+	testutil.NoError(t, json.Unmarshal([]byte(output), &stats))
+	testutil.Equal(t, 60.0, stats["uptime_seconds"].(float64))
+	jobStats := stats["jobs"].(map[string]any)
+	testutil.Equal(t, 5.0, jobStats["queued"].(float64))
+	testutil.Equal(t, 4.0, jobStats["workers"].(float64))
+}
+
+func TestStatsWithoutJobQueue(t *testing.T) {
+	resetJSONFlag()
+	stubStatsServer(t, nil)
+
+	output := runStatsCmd(t)
+	testutil.Contains(t, output, "goroutines:")
+	testutil.False(t, strings.Contains(output, "Jobs"), "jobs section needs the job queue")
+	testutil.False(t, strings.Contains(output, "Collections"), "collections section needs tables")
+}
+
+func TestStatsShowsCollections(t *testing.T) {
+	resetJSONFlag()
+	stubStatsServer(t, nil,
+		map[string]any{
+			"schema": "public", "table": "events", "kind": "table", "estimatedRows": 120000,
+			"totalBytes": 3 << 20, "tableBytes": 2 << 20, "indexBytes": 1 << 20,
+			"lastAnalyzed": "2026-01-02T03:04:00Z",
+		},
+		map[string]any{
+			"schema": "billing", "table": "invoices", "kind": "table", "estimatedRows": nil,
+			"totalBytes": 16384, "tableBytes": 8192, "indexBytes": 8192, "lastAnalyzed": nil,
+		},
+	)
+
+	output := runStatsCmd(t)
+	testutil.Contains(t, output, "Collections\n")
+	testutil.Contains(t, output, "ROWS (EST.)")
+	testutil.Contains(t, output, "  events            120000       3.0 MB   2.0 MB  1.0 MB   "+
+		time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC).Local().Format("2006-01-02 15:04"))
+	testutil.Contains(t, output, "billing.invoices  -            16.0 KB  8.0 KB  8.0 KB   never")
+}
+
+func TestStatsShowsCollectionsJSON(t *testing.T) {
+	resetJSONFlag()
+	stubStatsServer(t, nil, map[string]any{"schema": "public", "table": "events", "estimatedRows": 42})
+
+	output := runStatsCmd(t, "--json")
+	var stats map[string]any
+	testutil.NoError(t, json.Unmarshal([]byte(output), &stats))
+	collections := stats["collections"].([]any)
+	testutil.SliceLen(t, collections, 1)
+	testutil.Equal(t, 42.0, collections[0].(map[string]any)["estimatedRows"].(float64))
+}
+
+func TestStatsSuggestIndexesNone(t *testing.T) {
+	resetJSONFlag()
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"items": []any{}, "count": 0})
+	})
+
+	output := runStatsSuggestIndexesCmd(t)
+	testutil.Contains(t, output, "No index suggestions")
+}
+
+const testLogLines = `{"time":"2026-01-01T00:00:00Z","level":"DEBUG","msg":"one"}
+{"time":"2026-01-01T00:00:01Z","level":"INFO","msg":"two"}
+time=2026-01-01T00:00:02Z level=ERROR msg=three
+{"time":"2026-01-01T00:00:03Z","level":"WARN","msg":"four"}
+`
+
+func writeTestLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	testutil.NoError(t, os.WriteFile(path, []byte(testLogLines), 0o644))
+	return path
+}
+
+func TestTailLogFileLastLines(t *testing.T) {
+	t.Parallel()
+	path := writeTestLog(t)
+
+	var buf bytes.Buffer
+	testutil.NoError(t, tailLogFile(context.Background(), &buf, path, 2, false, logfile.Filter{}))
+	testutil.Equal(t, "time=2026-01-01T00:00:02Z level=ERROR msg=three\n"+
+		`{"time":"2026-01-01T00:00:03Z","level":"WARN","msg":"four"}`+"\n", buf.String())
+}
+
+func TestTailLogFileLevelFilter(t *testing.T) {
+	t.Parallel()
+	path := writeTestLog(t)
+
+	filter, err := logfile.ParseFilter("warn", "", "", time.Now())
+	testutil.NoError(t, err)
+	var buf bytes.Buffer
+	testutil.NoError(t, tailLogFile(context.Background(), &buf, path, 100, false, filter))
+	out := buf.String()
+	testutil.Contains(t, out, "msg=three")
+	testutil.Contains(t, out, `"msg":"four"`)
+	testutil.False(t, strings.Contains(out, `"msg":"two"`), "info line should be filtered: %s", out)
+}
+
+// syncBuffer is a bytes.Buffer safe for one writer and one reader.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailLogFileFollowsRotation(t *testing.T) {
+	orig := logFollowInterval
+	logFollowInterval = 5 * time.Millisecond
+	t.Cleanup(func() { logFollowInterval = orig })
+
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := logfile.Open(path, logfile.Options{})
+	testutil.NoError(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("before\n"))
+	testutil.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- tailLogFile(ctx, &out, path, 10, true, logfile.Filter{}) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %q, got %q", want, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor("before\n")
+	_, err = w.Write([]byte("old file\n"))
+	testutil.NoError(t, err)
+	testutil.NoError(t, w.Rotate())
+	_, err = w.Write([]byte("new file\n"))
+	testutil.NoError(t, err)
+	waitFor("new file\n")
+
+	cancel()
+	testutil.NoError(t, <-done)
+	testutil.Equal(t, "before\nold file\nnew file\n", out.String())
+}
+
+func TestLogsReadsConfiguredFile(t *testing.T) {
+	path := writeTestLog(t)
+	t.Setenv("AYB_LOG_OUTPUT", path)
+	logsCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		logsCmd.Flags().Set("lines", "100")
+		logsCmd.Flags().Set("level", "")
+	})
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"logs", "-n", "1", "--level", "info"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	testutil.Equal(t, `{"time":"2026-01-01T00:00:03Z","level":"WARN","msg":"four"}`+"\n", out)
+}
+
+func runLogsCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AYB_ADMIN_TOKEN", "tok")
+	logsCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		for name, def := range map[string]string{"lines": "100", "follow": "false", "level": "", "since": "", "until": ""} {
+			logsCmd.Flags().Set(name, def)
+		}
+	})
+	var err error
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs(append([]string{"logs"}, args...))
+		err = rootCmd.Execute()
+	})
+	return out, err
+}
+
+func TestLogsPassesFiltersToServer(t *testing.T) {
+	resetJSONFlag()
+	var got url.Values
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "/api/admin/logs", r.URL.Path)
+		testutil.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"entries":[{"time":"2026-01-02T15:04:05Z","level":"ERROR","message":"boom","attrs":{"table":"posts","code":500}}]}`))
+	})
+
+	out, err := runLogsCmd(t, "-n", "20", "--level", "error", "--since", "2026-01-02T15:00:00Z", "--until", "5m")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "20", got.Get("lines"))
+	testutil.Equal(t, "error", got.Get("level"))
+	testutil.Equal(t, "2026-01-02T15:00:00Z", got.Get("since"))
+	testutil.Equal(t, "5m", got.Get("until"))
+	testutil.Equal(t, "", got.Get("follow"))
+	testutil.Equal(t, "2026-01-02T15:04:05Z ERROR boom code=500 table=posts\n", out)
+}
+
+func TestLogsFollowStreamsEntries(t *testing.T) {
+	resetJSONFlag()
+	var got url.Values
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"time":"2026-01-02T15:04:05Z","level":"WARN","message":"one"}` + "\n" +
+			`{"time":"2026-01-02T15:04:06Z","level":"WARN","message":"two"}` + "\n"))
+	})
+
+	out, err := runLogsCmd(t, "--follow", "--level", "warn")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "true", got.Get("follow"))
+	testutil.Equal(t, "warn", got.Get("level"))
+	testutil.Equal(t, "2026-01-02T15:04:05Z WARN  one\n2026-01-02T15:04:06Z WARN  two\n", out)
+}
+
+func TestLogsRejectsInvalidFilter(t *testing.T) {
+	resetJSONFlag()
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be called")
+	})
+
+	_, err := runLogsCmd(t, "--since", "last tuesday")
+	testutil.ErrorContains(t, err, "invalid since")
+}
@@ -3,8 +3,10 @@ package cli
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	aybmcp "github.com/allyourbase/ayb/internal/mcp"
@@ -30,6 +32,14 @@ With explicit server URL:
 With admin token for SQL access:
   ayb mcp --admin-token YOUR_TOKEN
 
+The server is read-only by default: run_sql runs in a read-only transaction
+and no mutation tools are offered. --allow-writes together with
+--write-tables adds create_row, update_row and delete_row for the listed
+tables; --write-tables '*' allows every table and also enables run_sql
+writes and apply_migration. Every mutation is appended to the audit log
+(~/.ayb/mcp-audit.log by default):
+  ayb mcp --allow-writes --write-tables posts,comments
+
 Configuration in Claude Desktop (claude_desktop_config.json):
   {
     "mcpServers": {
@@ -46,6 +56,9 @@ func init() {
 	mcpCmd.Flags().String("url", "", "AYB server URL (default: auto-detect or http://127.0.0.1:8090)")
 	mcpCmd.Flags().String("admin-token", "", "Admin token for privileged operations (or set AYB_ADMIN_TOKEN)")
 	mcpCmd.Flags().String("token", "", "User JWT for RLS-filtered access (or set AYB_TOKEN)")
+	mcpCmd.Flags().Bool("allow-writes", false, "Offer mutation tools for the tables in --write-tables")
+	mcpCmd.Flags().StringSlice("write-tables", nil, "Tables agents may change with --allow-writes ('*' for all)")
+	mcpCmd.Flags().String("audit-log", "", "File that agent mutations are appended to (default ~/.ayb/mcp-audit.log)")
 }
 
 func runMCP(cmd *cobra.Command, args []string) error {
@@ -63,11 +76,29 @@ func runMCP(cmd *cobra.Command, args []string) error {
 		userToken = os.Getenv("AYB_TOKEN")
 	}
 
-	srv := aybmcp.NewServer(aybmcp.Config{
-		BaseURL:    baseURL,
-		AdminToken: adminToken,
-		UserToken:  userToken,
-	})
+	allowWrites, _ := cmd.Flags().GetBool("allow-writes")
+	writeTables, _ := cmd.Flags().GetStringSlice("write-tables")
+	if allowWrites != (len(writeTables) > 0) {
+		return fmt.Errorf("--allow-writes and --write-tables must be used together")
+	}
+
+	cfg := aybmcp.Config{
+		BaseURL:     baseURL,
+		AdminToken:  adminToken,
+		UserToken:   userToken,
+		AllowWrites: allowWrites,
+		WriteTables: writeTables,
+	}
+	if allowWrites {
+		auditPath, _ := cmd.Flags().GetString("audit-log")
+		auditFile, err := openMCPAuditLog(auditPath)
+		if err != nil {
+			return err
+		}
+		defer auditFile.Close()
+		cfg.AuditLog = slog.New(slog.NewJSONHandler(auditFile, nil))
+	}
+	srv := aybmcp.NewServer(cfg)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -77,3 +108,23 @@ func runMCP(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// openMCPAuditLog opens the MCP audit log for appending, defaulting to
+// ~/.ayb/mcp-audit.log.
+func openMCPAuditLog(path string) (*os.File, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("resolving audit log path: %w", err)
+		}
+		path = filepath.Join(home, ".ayb", "mcp-audit.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return f, nil
+}
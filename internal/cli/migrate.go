@@ -10,6 +10,8 @@ import (
 	"github.com/allyourbase/ayb/internal/config"
 	"github.com/allyourbase/ayb/internal/migrations"
 	"github.com/allyourbase/ayb/internal/postgres"
+	"github.com/allyourbase/ayb/internal/tenant"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/cobra"
 )
 
@@ -57,6 +59,7 @@ func init() {
 		cmd.Flags().String("config", "", "Path to ayb.toml config file")
 		cmd.Flags().String("migrations-dir", "", "Migrations directory (overrides config)")
 	}
+	migrateCreateCmd.Flags().Bool("reversible", false, "Generate the file with -- +up and -- +down sections")
 	migrateUpCmd.Flags().String("database-url", "", "PostgreSQL connection URL (overrides config)")
 	migrateStatusCmd.Flags().String("database-url", "", "PostgreSQL connection URL (overrides config)")
 }
@@ -70,7 +73,8 @@ func runMigrateCreate(cmd *cobra.Command, args []string) error {
 	dir := migrationsDir(cmd, cfg)
 	runner := migrations.NewUserRunner(nil, dir, slog.New(slog.NewTextHandler(os.Stderr, nil)))
 
-	path, err := runner.CreateFile(args[0])
+	reversible, _ := cmd.Flags().GetBool("reversible")
+	path, err := runner.CreateFile(args[0], reversible)
 	if err != nil {
 		return fmt.Errorf("creating migration: %w", err)
 	}
@@ -100,7 +104,7 @@ func runMigrateUp(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("bootstrapping: %w", err)
 	}
 
-	applied, err := runner.Up(ctx)
+	applied, err := userMigrations(cfg, pool.DB(), dir, logger)(ctx)
 	if err != nil {
 		return fmt.Errorf("applying migrations: %w", err)
 	}
@@ -173,6 +177,26 @@ func migrationsDir(cmd *cobra.Command, cfg *config.Config) string {
 	return cfg.Database.MigrationsDir
 }
 
+// userMigrations returns a function that applies pending user migrations in
+// dir to the public schema and, when tenants are enabled, to every tenant's
+// schema, returning the number applied in total. The caller bootstraps the
+// migrations table.
+func userMigrations(cfg *config.Config, pool *pgxpool.Pool, dir string, logger *slog.Logger) func(context.Context) (int, error) {
+	runner := migrations.NewUserRunner(pool, dir, logger)
+	if !cfg.Tenants.Enabled {
+		return runner.Up
+	}
+	tenants := tenant.NewService(pool, dir, logger)
+	return func(ctx context.Context) (int, error) {
+		applied, err := runner.Up(ctx)
+		if err != nil {
+			return applied, err
+		}
+		n, err := tenants.MigrateTenants(ctx)
+		return applied + n, err
+	}
+}
+
 func connectForMigrate(cmd *cobra.Command, cfg *config.Config, logger *slog.Logger) (*postgres.Pool, func(), error) {
 	dbURL := cfg.Database.URL
 	if v, _ := cmd.Flags().GetString("database-url"); v != "" {
@@ -188,6 +212,7 @@ func connectForMigrate(cmd *cobra.Command, cfg *config.Config, logger *slog.Logg
 		MaxConns:        5,
 		MinConns:        1,
 		HealthCheckSecs: 0,
+		SSL:             databaseSSL(cfg),
 	}, logger)
 	if err != nil {
 		return nil, nil, fmt.Errorf("connecting to database: %w", err)
@@ -57,6 +57,7 @@ func init() {
 	oauthClientsCreateCmd.Flags().StringSlice("redirect-uris", nil, "Redirect URIs (comma-separated, required)")
 	oauthClientsCreateCmd.Flags().StringSlice("scopes", nil, "Scopes: readonly, readwrite, * (comma-separated, required)")
 	oauthClientsCreateCmd.Flags().String("type", "confidential", "Client type: confidential or public")
+	oauthClientsCreateCmd.Flags().Bool("service-account", false, "Create a service account: a confidential client without redirect URIs that authenticates with client_credentials")
 
 	oauthClientsCmd.AddCommand(oauthClientsCreateCmd)
 	oauthClientsCmd.AddCommand(oauthClientsListCmd)
@@ -75,13 +76,17 @@ func runOAuthClientsCreate(cmd *cobra.Command, args []string) error {
 	redirectURIs, _ := cmd.Flags().GetStringSlice("redirect-uris")
 	scopes, _ := cmd.Flags().GetStringSlice("scopes")
 	clientType, _ := cmd.Flags().GetString("type")
+	serviceAccount, _ := cmd.Flags().GetBool("service-account")
 
 	if name == "" {
 		return fmt.Errorf("--name is required")
 	}
 	redirectURIs = filterEmpty(redirectURIs)
 	scopes = filterEmpty(scopes)
-	if len(redirectURIs) == 0 {
+	if serviceAccount && len(redirectURIs) > 0 {
+		return fmt.Errorf("--redirect-uris can't be used with --service-account")
+	}
+	if !serviceAccount && len(redirectURIs) == 0 {
 		return fmt.Errorf("--redirect-uris is required")
 	}
 	if len(scopes) == 0 {
@@ -95,6 +100,9 @@ func runOAuthClientsCreate(cmd *cobra.Command, args []string) error {
 		"redirectUris": redirectURIs,
 		"scopes":       scopes,
 	}
+	if serviceAccount {
+		payload["serviceAccount"] = true
+	}
 	body, _ := json.Marshal(payload)
 
 	resp, respBody, err := adminRequest(cmd, "POST", "/api/admin/oauth/clients", bytes.NewReader(body))
@@ -152,6 +160,9 @@ func runOAuthClientsList(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var result struct {
 		Items []struct {
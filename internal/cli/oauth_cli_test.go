@@ -18,6 +18,7 @@ func resetOAuthCreateFlags() {
 	f := oauthClientsCreateCmd.Flags()
 	f.Set("name", "")
 	f.Set("type", "confidential")
+	f.Set("service-account", "false")
 	for _, name := range []string{"redirect-uris", "scopes"} {
 		fl := f.Lookup(name)
 		fl.Changed = false
@@ -181,6 +182,77 @@ func TestOAuthClientsCreatePublicNoSecret(t *testing.T) {
 	}
 }
 
+func TestOAuthClientsCreateServiceAccount(t *testing.T) {
+	resetJSONFlag()
+	resetOAuthCreateFlags()
+	var receivedBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedBody)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"clientSecret": "ayb_cs_abcdef1234567890abcdef1234567890abcdef1234567890abcdef12345678",
+			"client": map[string]any{
+				"id":           "11111111-1111-1111-1111-111111111111",
+				"appId":        "22222222-2222-2222-2222-222222222222",
+				"clientId":     "ayb_cid_aabbccddee00112233445566778899aabbccddee0011",
+				"name":         "Nightly Sync",
+				"redirectUris": []string{},
+				"scopes":       []string{"readonly"},
+				"clientType":   "confidential",
+				"createdAt":    "2026-02-22T00:00:00Z",
+				"updatedAt":    "2026-02-22T00:00:00Z",
+			},
+		})
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"oauth", "clients", "create",
+			"22222222-2222-2222-2222-222222222222",
+			"--name", "Nightly Sync",
+			"--service-account",
+			"--scopes", "readonly",
+			"--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	if receivedBody["serviceAccount"] != true {
+		t.Fatalf("expected serviceAccount in request body, got %v", receivedBody["serviceAccount"])
+	}
+	if uris, _ := receivedBody["redirectUris"].([]any); len(uris) != 0 {
+		t.Fatalf("expected no redirectUris in request body, got %v", receivedBody["redirectUris"])
+	}
+	if !strings.Contains(output, "ayb_cs_") {
+		t.Fatalf("expected client secret in output, got %q", output)
+	}
+}
+
+func TestOAuthClientsCreateServiceAccountRejectsRedirectURIs(t *testing.T) {
+	resetJSONFlag()
+	resetOAuthCreateFlags()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("server should not be reached when a service account has redirect URIs")
+	}))
+	defer srv.Close()
+
+	rootCmd.SetArgs([]string{"oauth", "clients", "create",
+		"22222222-2222-2222-2222-222222222222",
+		"--name", "Nightly Sync",
+		"--service-account",
+		"--redirect-uris", "https://example.com/callback",
+		"--scopes", "readonly",
+		"--url", srv.URL, "--admin-token", "tok"})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for service account with redirect URIs")
+	}
+	if !strings.Contains(err.Error(), "--service-account") {
+		t.Fatalf("expected service-account error, got %q", err.Error())
+	}
+}
+
 func TestOAuthClientsCreateMissingName(t *testing.T) {
 	resetJSONFlag()
 	resetOAuthCreateFlags()
@@ -1,15 +1,18 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/spf13/cobra"
 )
 
@@ -18,16 +21,32 @@ var queryCmd = &cobra.Command{
 	Short: "Query records from a table on the running AYB server",
 	Long: `Query records from a collection via the running AYB server's REST API.
 
+Filter operators (values are always bound as query parameters):
+  =  eq          !=  neq        >  gt     >=  gte     <  lt     <=  lte
+  ~  like        !~  (not like) ilike
+  in ('a','b')   nin ('a','b')
+  is null        is not null    is true   is false
+  between 1 and 10
+Combine with AND / && and OR / ||, and group with parentheses.
+
+Repeated --filter flags are ANDed. Repeated --or flags form one group, any
+of which may match, that is ANDed with the filters.
+
 Examples:
   ayb query posts
   ayb query users --filter "email LIKE '%@example.com'" --sort -created_at --limit 5
-  ayb query posts --fields id,title,created_at --json`,
+  ayb query orders --filter "total between 10 and 100 and status nin ('void','refunded')"
+  ayb query orders --filter "total > 100" --or "status='open'" --or "status='pending'"
+  ayb query posts --fields id,title,created_at --json
+  ayb query posts --filter "published=true" --limit 500 --output-file posts.csv
+  ayb query posts --filter "published=true" --count`,
 	Args: cobra.ExactArgs(1),
 	RunE: runQuery,
 }
 
 func init() {
-	queryCmd.Flags().String("filter", "", "Filter expression (e.g. \"status='active' AND age>21\")")
+	queryCmd.Flags().StringArray("filter", nil, "Filter expression (e.g. \"status='active' AND age>21\"); repeatable, ANDed")
+	queryCmd.Flags().StringArray("or", nil, "Filter expression of a group any of which may match; repeatable")
 	queryCmd.Flags().String("sort", "", "Sort fields (e.g. \"-created_at,+title\")")
 	queryCmd.Flags().String("fields", "", "Comma-separated column list")
 	queryCmd.Flags().String("expand", "", "Comma-separated FK relationships to expand")
@@ -35,18 +54,36 @@ func init() {
 	queryCmd.Flags().Int("limit", 20, "Items per page (max 500)")
 	queryCmd.Flags().String("admin-token", "", "Admin/JWT token (or set AYB_ADMIN_TOKEN)")
 	queryCmd.Flags().String("url", "", "Server URL (default http://127.0.0.1:8090)")
+	queryCmd.Flags().String("output-file", "", "Write the results to a file (.json, .csv, .yaml or .ndjson, or set --output)")
+	queryCmd.Flags().Bool("count", false, "Print only the number of matching records")
+	queryCmd.MarkFlagsMutuallyExclusive("count", "output-file")
 }
 
 func runQuery(cmd *cobra.Command, args []string) error {
 	table := args[0]
 	token, _ := cmd.Flags().GetString("admin-token")
 	baseURL, _ := cmd.Flags().GetString("url")
-	filter, _ := cmd.Flags().GetString("filter")
+	filters, _ := cmd.Flags().GetStringArray("filter")
+	orFilters, _ := cmd.Flags().GetStringArray("or")
 	sort, _ := cmd.Flags().GetString("sort")
 	fields, _ := cmd.Flags().GetString("fields")
 	expand, _ := cmd.Flags().GetString("expand")
 	page, _ := cmd.Flags().GetInt("page")
 	limit, _ := cmd.Flags().GetInt("limit")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	count, _ := cmd.Flags().GetBool("count")
+
+	outFmt := outputFormat(cmd)
+	if outputFile != "" {
+		var err error
+		if outFmt, err = queryFileFormat(outFmt, outputFile); err != nil {
+			return err
+		}
+	}
+	if count {
+		// Only totalItems is needed; ask for the smallest page.
+		page, limit = 1, 1
+	}
 
 	if token == "" {
 		token = os.Getenv("AYB_ADMIN_TOKEN")
@@ -56,7 +93,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 
 	qs := url.Values{}
-	if filter != "" {
+	if filter := queryFilter(filters, orFilters); filter != "" {
 		qs.Set("filter", filter)
 	}
 	if sort != "" {
@@ -91,21 +128,20 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("reading response: %w", err)
 	}
 	if resp.StatusCode != http.StatusOK {
-		var errResp map[string]any
-		if json.Unmarshal(respBody, &errResp) == nil {
-			if msg, ok := errResp["message"].(string); ok {
-				return fmt.Errorf("server error (%d): %s", resp.StatusCode, msg)
-			}
+		if msg, ok := httputil.ErrorMessage(respBody); ok {
+			return fmt.Errorf("server error (%d): %s", resp.StatusCode, msg)
 		}
 		return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
 	}
 
-	outFmt := outputFormat(cmd)
-	if outFmt == "json" {
+	if outFmt == "json" && !count && outputFile == "" {
 		os.Stdout.Write(respBody)
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) && !count && outputFile == "" {
+		return writeListRecords(os.Stdout, outFmt, respBody)
+	}
 
 	// Parse list response and display as table.
 	var result struct {
@@ -119,7 +155,29 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("parsing response: %w", err)
 	}
 
-	if len(result.Items) == 0 {
+	if count {
+		switch outFmt {
+		case "json", "ndjson":
+			fmt.Printf("{\"totalItems\":%d}\n", result.TotalItems)
+		case "yaml":
+			fmt.Printf("totalItems: %d\n", result.TotalItems)
+		default:
+			fmt.Println(result.TotalItems)
+		}
+		return nil
+	}
+	if outFmt == "json" {
+		return writeQueryFile(outputFile, append(respBody, '\n'), len(result.Items))
+	}
+	if isRecordFormat(outFmt) {
+		var buf bytes.Buffer
+		if err := writeListRecords(&buf, outFmt, respBody); err != nil {
+			return err
+		}
+		return writeQueryFile(outputFile, buf.Bytes(), len(result.Items))
+	}
+
+	if len(result.Items) == 0 && outputFile == "" {
 		fmt.Println("No records found.")
 		return nil
 	}
@@ -128,7 +186,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	var cols []string
 	if fields != "" {
 		cols = strings.Split(fields, ",")
-	} else {
+	} else if len(result.Items) > 0 {
 		// Use keys from first item, preserving a reasonable order.
 		for k := range result.Items[0] {
 			cols = append(cols, k)
@@ -136,20 +194,14 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	}
 
 	if outFmt == "csv" {
-		rows := make([][]string, len(result.Items))
-		for i, item := range result.Items {
-			vals := make([]string, len(cols))
-			for j, col := range cols {
-				v := item[col]
-				if v == nil {
-					vals[j] = ""
-				} else {
-					vals[j] = fmt.Sprint(v)
-				}
-			}
-			rows[i] = vals
+		if outputFile == "" {
+			return writeCSVStdout(cols, queryCSVRows(cols, result.Items))
+		}
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, cols, queryCSVRows(cols, result.Items)); err != nil {
+			return err
 		}
-		return writeCSVStdout(cols, rows)
+		return writeQueryFile(outputFile, buf.Bytes(), len(result.Items))
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
@@ -171,3 +223,83 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\nPage %d/%d (%d total records)\n", result.Page, result.TotalPages, result.TotalItems)
 	return nil
 }
+
+// queryFilter combines the --filter and --or expressions into one filter:
+// the --filter expressions and the group of --or expressions are ANDed, and
+// the --or expressions are ORed within their group. A lone expression is
+// sent unchanged.
+func queryFilter(filters, orFilters []string) string {
+	terms := nonEmpty(filters)
+	if alts := nonEmpty(orFilters); len(alts) == 1 {
+		terms = append(terms, alts[0])
+	} else if len(alts) > 1 {
+		terms = append(terms, parenthesize(alts, " OR "))
+	}
+	if len(terms) == 1 {
+		return terms[0]
+	}
+	return parenthesize(terms, " AND ")
+}
+
+func nonEmpty(exprs []string) []string {
+	var out []string
+	for _, e := range exprs {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// parenthesize joins exprs with op, wrapping each in parentheses so its own
+// AND/OR operators bind first.
+func parenthesize(exprs []string, op string) string {
+	wrapped := make([]string, len(exprs))
+	for i, e := range exprs {
+		wrapped[i] = "(" + e + ")"
+	}
+	return strings.Join(wrapped, op)
+}
+
+// queryFileFormat returns the format --output-file is written in: --output
+// unless it is table, or else the one the file extension names.
+func queryFileFormat(outFmt, path string) (string, error) {
+	if outFmt != "table" {
+		return outFmt, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json", nil
+	case ".csv":
+		return "csv", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	case ".ndjson", ".jsonl":
+		return "ndjson", nil
+	}
+	return "", fmt.Errorf("can't tell the format of %s: use a .json, .csv, .yaml or .ndjson file or --output", path)
+}
+
+// queryCSVRows formats items as CSV rows of cols; NULLs are empty.
+func queryCSVRows(cols []string, items []map[string]any) [][]string {
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		vals := make([]string, len(cols))
+		for j, col := range cols {
+			if v := item[col]; v != nil {
+				vals[j] = fmt.Sprint(v)
+			}
+		}
+		rows[i] = vals
+	}
+	return rows
+}
+
+// writeQueryFile writes --output-file and reports it on stderr.
+func writeQueryFile(path string, data []byte, records int) error {
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d records to %s\n", records, path)
+	return nil
+}
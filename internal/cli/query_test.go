@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/spf13/pflag"
+)
+
+func TestQueryFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		filters   []string
+		orFilters []string
+		want      string
+	}{
+		{"none", nil, nil, ""},
+		{"single filter unchanged", []string{"status='active' AND age>21"}, nil, "status='active' AND age>21"},
+		{"filters ANDed", []string{"a=1", "b=2 || c=3"}, nil, "(a=1) AND (b=2 || c=3)"},
+		{"single or", nil, []string{"a=1"}, "a=1"},
+		{"or group", nil, []string{"a=1", "b=2"}, "(a=1) OR (b=2)"},
+		{"filter and or group", []string{"total>100"}, []string{"status='open'", "status='pending'"},
+			"(total>100) AND ((status='open') OR (status='pending'))"},
+		{"blank expressions skipped", []string{" ", "a=1"}, []string{""}, "a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			testutil.Equal(t, tt.want, queryFilter(tt.filters, tt.orFilters))
+		})
+	}
+}
+
+func TestQueryFileFormat(t *testing.T) {
+	t.Parallel()
+	for _, tt := range []struct{ outFmt, path, want string }{
+		{"table", "out.csv", "csv"},
+		{"table", "OUT.JSON", "json"},
+		{"csv", "out.txt", "csv"},
+		{"json", "out.csv", "json"},
+		{"table", "out.yml", "yaml"},
+		{"table", "out.jsonl", "ndjson"},
+		{"ndjson", "out.txt", "ndjson"},
+	} {
+		got, err := queryFileFormat(tt.outFmt, tt.path)
+		testutil.NoError(t, err)
+		testutil.Equal(t, tt.want, got)
+	}
+	_, err := queryFileFormat("table", "out.txt")
+	testutil.ErrorContains(t, err, "can't tell the format of out.txt")
+}
+
+// runQueryCmd runs "ayb query" against a stubbed server answering with
+// items, and returns the request's query string and stdout.
+func runQueryCmd(t *testing.T, items []map[string]any, args ...string) (url.Values, string, error) {
+	t.Helper()
+	resetJSONFlag()
+	t.Setenv("AYB_ADMIN_TOKEN", "tok")
+	queryCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() {
+		queryCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				sv.Replace(nil)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+		rootCmd.PersistentFlags().Set("output", "table")
+	})
+	var got url.Values
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "/api/collections/posts", r.URL.Path)
+		got = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"items": items, "page": 1, "perPage": 20, "totalItems": 42, "totalPages": 3,
+		})
+	})
+	var err error
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs(append([]string{"query", "posts"}, args...))
+		err = rootCmd.Execute()
+	})
+	return got, out, err
+}
+
+func TestQueryCombinesFilterFlags(t *testing.T) {
+	got, _, err := runQueryCmd(t, []map[string]any{{"id": 1}},
+		"--filter", "published=true", "--filter", "views>10", "--or", "author_id=1", "--or", "author_id=2")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "(published=true) AND (views>10) AND ((author_id=1) OR (author_id=2))", got.Get("filter"))
+}
+
+func TestQueryCount(t *testing.T) {
+	got, out, err := runQueryCmd(t, []map[string]any{{"id": 1}}, "--filter", "published=true", "--count", "--limit", "100")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "published=true", got.Get("filter"))
+	testutil.Equal(t, "1", got.Get("perPage"))
+	testutil.Equal(t, "42\n", out)
+}
+
+func TestQueryOutputFileCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.csv")
+	items := []map[string]any{{"id": 1, "title": "Hello, world"}, {"id": 2, "title": nil}}
+	_, out, err := runQueryCmd(t, items, "--fields", "id,title", "--output-file", path)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "", out)
+
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "id,title\n1,\"Hello, world\"\n2,\n", string(data))
+}
+
+func TestQueryOutputFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.out")
+	_, _, err := runQueryCmd(t, []map[string]any{{"id": 1}}, "--output", "json", "--output-file", path)
+	testutil.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	var resp struct {
+		Items      []map[string]any `json:"items"`
+		TotalItems int              `json:"totalItems"`
+	}
+	testutil.NoError(t, json.Unmarshal(data, &resp))
+	testutil.SliceLen(t, resp.Items, 1)
+	testutil.Equal(t, 42, resp.TotalItems)
+}
+
+func TestQueryNDJSON(t *testing.T) {
+	_, out, err := runQueryCmd(t, []map[string]any{{"id": 1, "title": "Hello"}}, "--output", "ndjson")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "{\"id\":1,\"title\":\"Hello\"}\n", out)
+}
+
+func TestQueryOutputFileYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "posts.yaml")
+	_, _, err := runQueryCmd(t, []map[string]any{{"id": 1, "title": "Hello"}}, "--output-file", path)
+	testutil.NoError(t, err)
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "- id: 1\n  title: Hello\n", string(data))
+}
+
+func TestQueryOutputFileUnknownFormat(t *testing.T) {
+	_, _, err := runQueryCmd(t, nil, "--output-file", filepath.Join(t.TempDir(), "posts.txt"))
+	testutil.ErrorContains(t, err, "can't tell the format")
+}
+
+func TestQueryCountExcludesOutputFile(t *testing.T) {
+	_, _, err := runQueryCmd(t, nil, "--count", "--output-file", "posts.csv")
+	testutil.ErrorContains(t, err, "none of the others can be")
+}
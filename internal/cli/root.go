@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 // cliHTTPClient is the shared HTTP client for all CLI commands.
@@ -45,7 +48,7 @@ Or with an external database:
 
 func init() {
 	rootCmd.PersistentFlags().Bool("json", false, "Output in JSON format (shorthand for --output json)")
-	rootCmd.PersistentFlags().String("output", "table", "Output format: table, json, or csv")
+	rootCmd.PersistentFlags().String("output", "table", "Output format: table, json, csv, yaml, or ndjson")
 
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
@@ -72,6 +75,7 @@ func init() {
 	rootCmd.AddCommand(secretsCmd)
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(demoCmd)
+	rootCmd.AddCommand(doctorCmd)
 
 	initHelp()
 }
@@ -116,6 +120,71 @@ func writeCSVStdout(cols []string, rows [][]string) error {
 	return writeCSV(os.Stdout, cols, rows)
 }
 
+// isRecordFormat reports whether format writes one record per list item:
+// yaml (a sequence of mappings) or ndjson (one JSON object per line).
+func isRecordFormat(format string) bool {
+	return format == "yaml" || format == "ndjson"
+}
+
+// writeListRecords writes the items of a list response body, either a JSON
+// array or an object with an "items" array, in a record format.
+func writeListRecords(w io.Writer, format string, body []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return fmt.Errorf("parsing response: %w", err)
+		}
+		items = list.Items
+	}
+	return writeRecords(w, format, items)
+}
+
+// writeRecords writes JSON records as NDJSON or YAML, keeping the key order
+// of each record.
+func writeRecords(w io.Writer, format string, records []json.RawMessage) error {
+	if format == "ndjson" {
+		for _, rec := range records {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, rec); err != nil {
+				return fmt.Errorf("encoding record: %w", err)
+			}
+			buf.WriteByte('\n')
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// JSON is YAML, so decoding into a node keeps key order; clearing the
+	// flow and quoting styles re-encodes it in block style.
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{}}
+	for _, rec := range records {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(rec, &doc); err != nil {
+			return fmt.Errorf("encoding record: %w", err)
+		}
+		seq.Content = append(seq.Content, blockStyle(doc.Content[0]))
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(seq); err != nil {
+		return fmt.Errorf("encoding YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+func blockStyle(n *yaml.Node) *yaml.Node {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+	return n
+}
+
 // adminRequest makes an authenticated admin HTTP request to the AYB server.
 // It resolves the admin token from --admin-token flag, AYB_ADMIN_TOKEN env,
 // or ~/.ayb/admin-token (auto-login); and the URL from --url flag or default.
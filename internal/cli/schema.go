@@ -24,7 +24,8 @@ With a table name, shows full detail: columns, types, foreign keys, indexes.
 Examples:
   ayb schema
   ayb schema posts
-  ayb schema --json`,
+  ayb schema --json
+  ayb schema diff`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSchema,
 }
@@ -81,9 +82,26 @@ func runSchema(cmd *cobra.Command, args []string) error {
 		baseURL = serverURL()
 	}
 
+	tables, err := fetchSchemaTables(baseURL, token)
+	if err != nil {
+		return err
+	}
+
+	// If a specific table was requested, show detail.
+	if len(args) == 1 {
+		return showTableDetail(os.Stdout, args[0], tables, outFmt)
+	}
+
+	// Otherwise, list all tables.
+	return listTables(os.Stdout, tables, outFmt)
+}
+
+// fetchSchemaTables returns the server's tables from /api/schema, keyed by
+// schema-qualified name.
+func fetchSchemaTables(baseURL, token string) (map[string]schemaTable, error) {
 	req, err := http.NewRequest("GET", baseURL+"/api/schema", nil)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -91,16 +109,16 @@ func runSchema(cmd *cobra.Command, args []string) error {
 
 	resp, err := cliHTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("connecting to server: %w", err)
+		return nil, fmt.Errorf("connecting to server: %w", err)
 	}
 	defer resp.Body.Close()
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return serverError(resp.StatusCode, respBody)
+		return nil, serverError(resp.StatusCode, respBody)
 	}
 
 	var cache struct {
@@ -109,7 +127,7 @@ func runSchema(cmd *cobra.Command, args []string) error {
 		Schemas   []string                   `json:"schemas"`
 	}
 	if err := json.Unmarshal(respBody, &cache); err != nil {
-		return fmt.Errorf("parsing schema: %w", err)
+		return nil, fmt.Errorf("parsing schema: %w", err)
 	}
 
 	// Parse tables into typed structs.
@@ -117,21 +135,14 @@ func runSchema(cmd *cobra.Command, args []string) error {
 	for key, raw := range cache.Tables {
 		var t schemaTable
 		if err := json.Unmarshal(raw, &t); err != nil {
-			return fmt.Errorf("parsing table %s: %w", key, err)
+			return nil, fmt.Errorf("parsing table %s: %w", key, err)
 		}
 		tables[key] = t
 	}
-
-	// If a specific table was requested, show detail.
-	if len(args) == 1 {
-		return showTableDetail(args[0], tables, outFmt)
-	}
-
-	// Otherwise, list all tables.
-	return listTables(tables, outFmt)
+	return tables, nil
 }
 
-func listTables(tables map[string]schemaTable, outFmt string) error {
+func listTables(out io.Writer, tables map[string]schemaTable, outFmt string) error {
 	if outFmt == "json" {
 		// Build sorted list for JSON.
 		list := make([]map[string]any, 0, len(tables))
@@ -148,9 +159,9 @@ func listTables(tables map[string]schemaTable, outFmt string) error {
 			sj := list[j]["schema"].(string) + "." + list[j]["name"].(string)
 			return si < sj
 		})
-		out, _ := json.MarshalIndent(list, "", "  ")
-		os.Stdout.Write(out)
-		fmt.Println()
+		data, _ := json.MarshalIndent(list, "", "  ")
+		out.Write(data)
+		fmt.Fprintln(out)
 		return nil
 	}
 
@@ -162,7 +173,7 @@ func listTables(tables map[string]schemaTable, outFmt string) error {
 	sort.Strings(keys)
 
 	if len(keys) == 0 {
-		fmt.Println("No tables found.")
+		fmt.Fprintln(out, "No tables found.")
 		return nil
 	}
 
@@ -177,10 +188,10 @@ func listTables(tables map[string]schemaTable, outFmt string) error {
 			}
 			rows[i] = []string{t.Schema, t.Name, t.Kind, fmt.Sprintf("%d", len(t.Columns)), pk}
 		}
-		return writeCSVStdout(cols, rows)
+		return writeCSV(out, cols, rows)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
 	fmt.Fprintln(w, "Schema\tName\tKind\tColumns\tPK")
 	fmt.Fprintln(w, "---\t---\t---\t---\t---")
 	for _, key := range keys {
@@ -192,11 +203,11 @@ func listTables(tables map[string]schemaTable, outFmt string) error {
 		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", t.Schema, t.Name, t.Kind, len(t.Columns), pk)
 	}
 	w.Flush()
-	fmt.Printf("\n%d table(s)\n", len(keys))
+	fmt.Fprintf(out, "\n%d table(s)\n", len(keys))
 	return nil
 }
 
-func showTableDetail(name string, tables map[string]schemaTable, outFmt string) error {
+func showTableDetail(out io.Writer, name string, tables map[string]schemaTable, outFmt string) error {
 	// Find the table — try exact key first, then unqualified name.
 	var found *schemaTable
 	if t, ok := tables[name]; ok {
@@ -218,9 +229,9 @@ func showTableDetail(name string, tables map[string]schemaTable, outFmt string)
 	}
 
 	if outFmt == "json" {
-		out, _ := json.MarshalIndent(found, "", "  ")
-		os.Stdout.Write(out)
-		fmt.Println()
+		data, _ := json.MarshalIndent(found, "", "  ")
+		out.Write(data)
+		fmt.Fprintln(out)
 		return nil
 	}
 
@@ -238,19 +249,19 @@ func showTableDetail(name string, tables map[string]schemaTable, outFmt string)
 			}
 			rows[i] = []string{col.Name, col.Type, nullable, col.Default, pk}
 		}
-		return writeCSVStdout(cols, rows)
+		return writeCSV(out, cols, rows)
 	}
 
 	// Header
-	fmt.Printf("%s.%s (%s)\n", found.Schema, found.Name, found.Kind)
+	fmt.Fprintf(out, "%s.%s (%s)\n", found.Schema, found.Name, found.Kind)
 	if found.Comment != "" {
-		fmt.Printf("  %s\n", found.Comment)
+		fmt.Fprintf(out, "  %s\n", found.Comment)
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 
 	// Columns
-	fmt.Println("Columns:")
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "Columns:")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
 	fmt.Fprintln(w, "  Name\tType\tNullable\tDefault\tPK")
 	fmt.Fprintln(w, "  ---\t---\t---\t---\t---")
 	for _, col := range found.Columns {
@@ -272,9 +283,9 @@ func showTableDetail(name string, tables map[string]schemaTable, outFmt string)
 
 	// Foreign keys
 	if len(found.ForeignKeys) > 0 {
-		fmt.Println("\nForeign Keys:")
+		fmt.Fprintln(out, "\nForeign Keys:")
 		for _, fk := range found.ForeignKeys {
-			fmt.Printf("  %s: (%s) → %s.%s(%s)\n",
+			fmt.Fprintf(out, "  %s: (%s) → %s.%s(%s)\n",
 				fk.ConstraintName,
 				strings.Join(fk.Columns, ", "),
 				fk.ReferencedSchema,
@@ -286,7 +297,7 @@ func showTableDetail(name string, tables map[string]schemaTable, outFmt string)
 
 	// Indexes
 	if len(found.Indexes) > 0 {
-		fmt.Println("\nIndexes:")
+		fmt.Fprintln(out, "\nIndexes:")
 		for _, idx := range found.Indexes {
 			flags := ""
 			if idx.IsPrimary {
@@ -294,7 +305,7 @@ func showTableDetail(name string, tables map[string]schemaTable, outFmt string)
 			} else if idx.IsUnique {
 				flags = " [UNIQUE]"
 			}
-			fmt.Printf("  %s (%s)%s\n", idx.Name, idx.Method, flags)
+			fmt.Fprintf(out, "  %s (%s)%s\n", idx.Name, idx.Method, flags)
 		}
 	}
 
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestSchemaDiffSubcommandRegistered(t *testing.T) {
+	var found bool
+	for _, c := range schemaCmd.Commands() {
+		if c.Name() == "diff" {
+			found = true
+		}
+	}
+	testutil.True(t, found, "expected schema subcommand %q", "diff")
+
+	for _, name := range []string{"config", "migrations-dir", "database-url"} {
+		testutil.NotNil(t, schemaDiffCmd.Flags().Lookup(name))
+	}
+}
+
+func TestReplaceDatabaseInURL(t *testing.T) {
+	got, err := replaceDatabaseInURL("postgresql://u:p@localhost:5432/app?sslmode=disable", "ayb_shadow_1")
+	testutil.NoError(t, err)
+	testutil.Equal(t, "postgresql://u:p@localhost:5432/ayb_shadow_1?sslmode=disable", got)
+
+	_, err = replaceDatabaseInURL("host=localhost dbname=app", "ayb_shadow_1")
+	testutil.ErrorContains(t, err, "postgres:// connection URL")
+}
+
+func TestFormatSchemaDiffNoDrift(t *testing.T) {
+	d := schema.Diff(&schema.SchemaCache{}, &schema.SchemaCache{})
+	out := formatSchemaDiff(d, 3)
+	testutil.Contains(t, out, "3 applied migration(s)")
+	testutil.Contains(t, out, "No drift detected.")
+}
+
+func TestFormatSchemaDiffDrift(t *testing.T) {
+	d := &schema.SchemaDiff{
+		AddedTables:   []string{"public.scratch"},
+		RemovedTables: []string{"public.comments"},
+		ChangedTables: []*schema.TableDiff{{
+			Table:          "public.posts",
+			AddedColumns:   []string{"extra"},
+			RemovedColumns: []string{"title"},
+			ChangedColumns: []*schema.ColumnChange{{
+				Column:  "body",
+				Changes: []*schema.FieldChange{{Field: "default", Expected: "", Actual: "'x'::text"}},
+			}},
+		}},
+	}
+	out := formatSchemaDiff(d, 1)
+	for _, want := range []string{
+		"+ table public.scratch (not in migrations)",
+		"- table public.comments (missing from database)",
+		"~ table public.posts",
+		"    + column extra",
+		"    - column title",
+		"    ~ column body default: (none) → 'x'::text",
+		"3 table(s) drifted.",
+	} {
+		testutil.True(t, strings.Contains(out, want), "output missing %q:\n%s", want, out)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/spf13/cobra"
 )
 
@@ -23,16 +24,27 @@ Authentication is resolved in order: --admin-token flag, AYB_ADMIN_TOKEN env var
 ~/.ayb/admin-token file (auto-saved by ayb start). The saved password is
 exchanged for a session token automatically.
 
+With --repl (-i) and a terminal on stdin, opens an interactive prompt
+instead. Statements can span lines and run when they end with ";". Arrow
+keys recall earlier lines, which are kept in ~/.ayb/sql_history.
+Meta-commands:
+  \dt         list tables
+  \d <table>  describe a table
+  \?          show help
+  \q          quit (or Ctrl-D)
+
 Examples:
   ayb sql "SELECT * FROM users LIMIT 10"
   ayb sql "SELECT count(*) FROM posts" --json
-  echo "SELECT 1" | ayb sql`,
+  echo "SELECT 1" | ayb sql
+  ayb sql -i`,
 	RunE: runSQL,
 }
 
 func init() {
 	sqlCmd.Flags().String("admin-token", "", "Admin token (or set AYB_ADMIN_TOKEN)")
 	sqlCmd.Flags().String("url", "", "Server URL (default http://127.0.0.1:8090)")
+	sqlCmd.Flags().BoolP("repl", "i", false, "Open an interactive SQL prompt (when stdin is a terminal)")
 }
 
 func runSQL(cmd *cobra.Command, args []string) error {
@@ -58,6 +70,12 @@ func runSQL(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	outFmt := outputFormat(cmd)
+	repl, _ := cmd.Flags().GetBool("repl")
+	if repl && len(args) == 0 && stdinIsTerminal() {
+		return runSQLREPL(baseURL, token, outFmt)
+	}
+
 	// Get query from args or stdin.
 	var query string
 	if len(args) > 0 {
@@ -73,13 +91,22 @@ func runSQL(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("query is required (pass as argument or pipe to stdin)")
 	}
 
+	respBody, err := execSQL(baseURL, token, query)
+	if err != nil {
+		return err
+	}
+	return printSQLResult(os.Stdout, respBody, outFmt, colorEnabledFd(os.Stdout.Fd()))
+}
+
+// execSQL runs query via the admin SQL endpoint and returns the response body.
+func execSQL(baseURL, token, query string) ([]byte, error) {
 	body, err := json.Marshal(map[string]string{"query": query})
 	if err != nil {
-		return fmt.Errorf("encoding request: %w", err)
+		return nil, fmt.Errorf("encoding request: %w", err)
 	}
 	req, err := http.NewRequest("POST", baseURL+"/api/admin/sql/", bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	if token != "" {
@@ -88,28 +115,28 @@ func runSQL(cmd *cobra.Command, args []string) error {
 
 	resp, err := cliHTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("connecting to server: %w", err)
+		return nil, fmt.Errorf("connecting to server: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("reading server response: %w", err)
+		return nil, fmt.Errorf("reading server response: %w", err)
 	}
 	if resp.StatusCode != http.StatusOK {
-		var errResp map[string]any
-		if json.Unmarshal(respBody, &errResp) == nil {
-			if msg, ok := errResp["message"].(string); ok {
-				return fmt.Errorf("server error (%d): %s", resp.StatusCode, msg)
-			}
+		if msg, ok := httputil.ErrorMessage(respBody); ok {
+			return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, msg)
 		}
-		return fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
 	}
+	return respBody, nil
+}
 
-	outFmt := outputFormat(cmd)
+// printSQLResult writes an admin SQL response to out as a table, CSV or JSON.
+func printSQLResult(out io.Writer, respBody []byte, outFmt string, useColor bool) error {
 	if outFmt == "json" {
-		os.Stdout.Write(respBody)
-		fmt.Println()
+		out.Write(respBody)
+		fmt.Fprintln(out)
 		return nil
 	}
 
@@ -123,6 +150,9 @@ func runSQL(cmd *cobra.Command, args []string) error {
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return fmt.Errorf("parsing response: %w", err)
 	}
+	if isRecordFormat(outFmt) {
+		return writeRecords(out, outFmt, sqlRecords(result.Columns, result.Rows))
+	}
 
 	// Build string rows for both table and CSV output.
 	strRows := make([][]string, len(result.Rows))
@@ -147,21 +177,42 @@ func runSQL(cmd *cobra.Command, args []string) error {
 	}
 
 	if outFmt == "csv" {
-		return writeCSVStdout(result.Columns, strRows)
+		return writeCSV(out, result.Columns, strRows)
 	}
 
-	useColor := colorEnabledFd(os.Stdout.Fd())
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
 	fmt.Fprintln(w, bold(strings.Join(result.Columns, "\t"), useColor))
 	fmt.Fprintln(w, strings.Repeat("---\t", len(result.Columns)))
 	for _, vals := range strRows {
 		fmt.Fprintln(w, strings.Join(vals, "\t"))
 	}
 	w.Flush()
-	fmt.Printf("\n%s\n", dim(fmt.Sprintf("(%d rows, %.1fms)", result.RowCount, result.DurationMs), useColor))
+	fmt.Fprintf(out, "\n%s\n", dim(fmt.Sprintf("(%d rows, %.1fms)", result.RowCount, result.DurationMs), useColor))
 	return nil
 }
 
+// sqlRecords turns result rows into JSON objects keyed by column, in column
+// order.
+func sqlRecords(cols []string, rows [][]json.RawMessage) []json.RawMessage {
+	records := make([]json.RawMessage, len(rows))
+	for i, row := range rows {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for j, cell := range row {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(cols[j])
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(cell)
+		}
+		buf.WriteByte('}')
+		records[i] = buf.Bytes()
+	}
+	return records
+}
+
 // adminLogin exchanges an admin password for a bearer token via /api/admin/auth.
 func adminLogin(baseURL, password string) (string, error) {
 	body, err := json.Marshal(map[string]string{"password": password})
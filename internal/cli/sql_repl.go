@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	sqlPrompt         = "ayb=> "
+	sqlContinuePrompt = "ayb-> "
+	// sqlHistorySize caps the lines kept in ~/.ayb/sql_history.
+	sqlHistorySize = 1000
+)
+
+const sqlREPLHelp = `Enter SQL statements ending with ";". Meta-commands:
+  \dt         list tables
+  \d <table>  describe a table
+  \?          show this help
+  \q          quit (or Ctrl-D)
+`
+
+// lineReader reads prompted lines of input; *term.Terminal implements it.
+type lineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// sqlREPL is the interactive prompt of "ayb sql --repl".
+type sqlREPL struct {
+	in       lineReader
+	out      io.Writer
+	baseURL  string
+	token    string
+	outFmt   string
+	useColor bool
+}
+
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// runSQLREPL puts the terminal in raw mode for line editing and runs the
+// prompt until \q or end of input.
+func runSQLREPL(baseURL, token, outFmt string) error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("opening terminal: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, sqlPrompt)
+	if width, height, err := term.GetSize(fd); err == nil {
+		t.SetSize(width, height)
+	}
+	if path, err := sqlHistoryPath(); err == nil {
+		t.History = loadSQLHistory(path)
+	}
+
+	fmt.Fprintf(t, "Connected to %s. Type \\? for help.\n", baseURL)
+	r := &sqlREPL{in: t, out: t, baseURL: baseURL, token: token, outFmt: outFmt, useColor: colorEnabledFd(os.Stdout.Fd())}
+	return r.run()
+}
+
+// run reads statements until \q or end of input. Lines are buffered until
+// they form a statement ending in ";", which is then sent to the server.
+// Meta-commands are recognized at the start of a statement. Errors from the
+// server are printed and the prompt continues.
+func (r *sqlREPL) run() error {
+	var stmt strings.Builder
+	for {
+		if stmt.Len() == 0 {
+			r.in.SetPrompt(sqlPrompt)
+		} else {
+			r.in.SetPrompt(sqlContinuePrompt)
+		}
+		line, err := r.in.ReadLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+
+		if stmt.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, `\`) {
+				if quit := r.metaCommand(trimmed); quit {
+					return nil
+				}
+				continue
+			}
+		} else {
+			stmt.WriteByte('\n')
+		}
+		stmt.WriteString(line)
+
+		if statementComplete(stmt.String()) {
+			r.exec(stmt.String())
+			stmt.Reset()
+		}
+	}
+}
+
+func (r *sqlREPL) exec(query string) {
+	respBody, err := execSQL(r.baseURL, r.token, query)
+	if err == nil {
+		err = printSQLResult(r.out, respBody, r.outFmt, r.useColor)
+	}
+	if err != nil {
+		fmt.Fprintf(r.out, "ERROR: %v\n", err)
+	}
+}
+
+// metaCommand runs a backslash command and reports whether it was \q.
+func (r *sqlREPL) metaCommand(line string) (quit bool) {
+	fields := strings.Fields(line)
+	var err error
+	switch fields[0] {
+	case `\q`:
+		return true
+	case `\?`:
+		fmt.Fprint(r.out, sqlREPLHelp)
+	case `\dt`, `\d`:
+		var tables map[string]schemaTable
+		if tables, err = fetchSchemaTables(r.baseURL, r.token); err != nil {
+			break
+		}
+		if len(fields) > 1 {
+			err = showTableDetail(r.out, fields[1], tables, r.outFmt)
+		} else {
+			err = listTables(r.out, tables, r.outFmt)
+		}
+	default:
+		fmt.Fprintf(r.out, "unknown command %s; type \\? for help\n", fields[0])
+	}
+	if err != nil {
+		fmt.Fprintf(r.out, "ERROR: %v\n", err)
+	}
+	return false
+}
+
+// statementComplete reports whether sql ends with a ";" that isn't inside a
+// string, quoted identifier, dollar-quoted body or comment, ignoring
+// trailing whitespace and comments.
+func statementComplete(sql string) bool {
+	complete := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				return false
+			}
+			// A doubled quote is an escaped quote; the next iteration
+			// reopens the string.
+			i += end + 1
+			complete = false
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return complete
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		case c == '$':
+			tag, ok := dollarQuoteTag(sql[i:])
+			if !ok {
+				complete = false
+				continue
+			}
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return false
+			}
+			i += len(tag) + end + len(tag) - 1
+			complete = false
+		case c == ';':
+			complete = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			complete = false
+		}
+	}
+	return complete
+}
+
+// dollarQuoteTag returns the opening tag ($$ or $name$) at the start of s.
+func dollarQuoteTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		if c == '$' {
+			return s[:j+1], true
+		}
+		isIdent := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9'
+		if !isIdent {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// sqlHistoryPath returns the path of the REPL history (~/.ayb/sql_history).
+func sqlHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ayb", "sql_history"), nil
+}
+
+// sqlHistory is a term.History that appends each line to a file, so it is
+// recalled in later sessions.
+type sqlHistory struct {
+	path    string
+	entries []string // oldest first
+}
+
+// loadSQLHistory reads the most recent sqlHistorySize lines of path. A
+// missing or unreadable file starts an empty history.
+func loadSQLHistory(path string) *sqlHistory {
+	h := &sqlHistory{path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > sqlHistorySize {
+		h.entries = h.entries[len(h.entries)-sqlHistorySize:]
+		h.rewrite()
+	}
+	return h
+}
+
+// Add records entry, skipping a repeat of the previous line.
+func (h *sqlHistory) Add(entry string) {
+	if entry == "" || len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > sqlHistorySize {
+		h.entries = h.entries[1:]
+	}
+	// History is a convenience: failing to save it shouldn't interrupt the
+	// session.
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, entry)
+	f.Close()
+}
+
+func (h *sqlHistory) Len() int { return len(h.entries) }
+
+// At returns the idx-th most recent entry.
+func (h *sqlHistory) At(idx int) string { return h.entries[len(h.entries)-1-idx] }
+
+// rewrite replaces the history file with the kept entries.
+func (h *sqlHistory) rewrite() {
+	data := strings.Join(h.entries, "\n") + "\n"
+	_ = os.WriteFile(h.path, []byte(data), 0o600)
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestStatementComplete(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT 1", false},
+		{"SELECT 1;", true},
+		{"SELECT 1;  ", true},
+		{"SELECT 1; -- done", true},
+		{"SELECT 1; /* done */", true},
+		{"SELECT 1; SELECT", false},
+		{"SELECT ';'", false},
+		{"SELECT 'it''s';", true},
+		{"SELECT 'unterminated;", false},
+		{`SELECT ";" FROM t`, false},
+		{"SELECT 1 -- ;", false},
+		{"SELECT /* ; */ 1", false},
+		{"SELECT /* unterminated;", false},
+		{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$", false},
+		{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;", true},
+		{"DO $body$ BEGIN PERFORM 1; END $body$;", true},
+		{"DO $body$ BEGIN PERFORM 1; END;", false},
+		{"SELECT $1;", true},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, statementComplete(tt.sql))
+	}
+}
+
+// scriptedLines is a lineReader that returns fixed lines and records the
+// prompt each was read with.
+type scriptedLines struct {
+	lines   []string
+	prompt  string
+	prompts []string
+}
+
+func (s *scriptedLines) SetPrompt(prompt string) { s.prompt = prompt }
+
+func (s *scriptedLines) ReadLine() (string, error) {
+	if len(s.lines) == 0 {
+		return "", io.EOF
+	}
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+	s.prompts = append(s.prompts, s.prompt)
+	return line, nil
+}
+
+// stubSQLServer answers the admin SQL endpoint by echoing the query, and
+// the schema endpoint with a posts table.
+func stubSQLServer(t *testing.T) *[]string {
+	t.Helper()
+	var queries []string
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/admin/sql/":
+			var req struct {
+				Query string `json:"query"`
+			}
+			testutil.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			queries = append(queries, req.Query)
+			if strings.Contains(req.Query, "nope") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code":400,"message":"syntax error at or near \"nope\""}`)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"columns": []string{"query"}, "rows": [][]any{{req.Query}}, "rowCount": 1, "durationMs": 1.5,
+			})
+		case "/api/schema":
+			fmt.Fprint(w, `{"tables":{"public.posts":{"schema":"public","name":"posts","kind":"table",
+				"columns":[{"name":"id","type":"integer","isPrimaryKey":true},{"name":"title","type":"text","nullable":true}],
+				"primaryKey":["id"]}}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	return &queries
+}
+
+func TestSQLREPLRunsStatements(t *testing.T) {
+	queries := stubSQLServer(t)
+	in := &scriptedLines{lines: []string{
+		"",
+		"SELECT 1;",
+		"SELECT 'a;b'",
+		"  FROM t;",
+		"nope;",
+		`\q`,
+		"SELECT 'after quit';",
+	}}
+	var out bytes.Buffer
+	r := &sqlREPL{in: in, out: &out, baseURL: "http://ayb.test", token: "tok", outFmt: "table"}
+	testutil.NoError(t, r.run())
+
+	testutil.Equal(t, 3, len(*queries))
+	testutil.Equal(t, "SELECT 1;", (*queries)[0])
+	testutil.Equal(t, "SELECT 'a;b'\n  FROM t;", (*queries)[1])
+	testutil.Equal(t, "ayb=> ,ayb=> ,ayb=> ,ayb-> ,ayb=> ,ayb=> ", strings.Join(in.prompts, ","))
+	testutil.Contains(t, out.String(), "(1 rows, 1.5ms)")
+	testutil.Contains(t, out.String(), `ERROR: server error (400): syntax error at or near "nope"`)
+	testutil.False(t, strings.Contains(out.String(), "after quit"), "input after \\q should not run")
+}
+
+func TestSQLREPLMetaCommands(t *testing.T) {
+	queries := stubSQLServer(t)
+	in := &scriptedLines{lines: []string{`\dt`, `\d posts`, `\d missing`, `\?`, `\x`}}
+	var out bytes.Buffer
+	r := &sqlREPL{in: in, out: &out, baseURL: "http://ayb.test", token: "tok", outFmt: "table"}
+	testutil.NoError(t, r.run())
+
+	testutil.Equal(t, 0, len(*queries))
+	got := out.String()
+	testutil.Contains(t, got, "1 table(s)")
+	testutil.Contains(t, got, "public.posts (table)")
+	testutil.Contains(t, got, "title")
+	testutil.Contains(t, got, `ERROR: table "missing" not found`)
+	testutil.Contains(t, got, `\d <table>  describe a table`)
+	testutil.Contains(t, got, `unknown command \x`)
+}
+
+func TestSQLREPLRecordFormat(t *testing.T) {
+	stubSQLServer(t)
+	in := &scriptedLines{lines: []string{"SELECT 1;"}}
+	var out bytes.Buffer
+	r := &sqlREPL{in: in, out: &out, baseURL: "http://ayb.test", token: "tok", outFmt: "ndjson"}
+	testutil.NoError(t, r.run())
+	testutil.Equal(t, "{\"query\":\"SELECT 1;\"}\n\n", out.String())
+}
+
+func TestSQLRecords(t *testing.T) {
+	t.Parallel()
+	rows := [][]json.RawMessage{{json.RawMessage(`1`), json.RawMessage(`"a"`), json.RawMessage(`null`)}}
+	got := sqlRecords([]string{"id", "na\"me", "x"}, rows)
+	testutil.SliceLen(t, got, 1)
+	testutil.Equal(t, `{"id":1,"na\"me":"a","x":null}`, string(got[0]))
+}
+
+func TestSQLHistory(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), ".ayb", "sql_history")
+
+	h := loadSQLHistory(path)
+	testutil.Equal(t, 0, h.Len())
+	h.Add("SELECT 1;")
+	h.Add("SELECT 1;") // repeat skipped
+	h.Add("SELECT 2;")
+	testutil.Equal(t, 2, h.Len())
+	testutil.Equal(t, "SELECT 2;", h.At(0))
+	testutil.Equal(t, "SELECT 1;", h.At(1))
+
+	h = loadSQLHistory(path)
+	testutil.Equal(t, 2, h.Len())
+	testutil.Equal(t, "SELECT 2;", h.At(0))
+}
+
+func TestSQLHistoryBounded(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "sql_history")
+	var lines strings.Builder
+	for i := range sqlHistorySize + 5 {
+		fmt.Fprintf(&lines, "SELECT %d;\n", i)
+	}
+	testutil.NoError(t, os.WriteFile(path, []byte(lines.String()), 0o600))
+
+	h := loadSQLHistory(path)
+	testutil.Equal(t, sqlHistorySize, h.Len())
+	testutil.Equal(t, "SELECT 5;", h.At(sqlHistorySize-1))
+	h.Add("SELECT 'new';")
+	testutil.Equal(t, sqlHistorySize, h.Len())
+	testutil.Equal(t, "SELECT 6;", h.At(sqlHistorySize-1))
+
+	// Loading trimmed the file; Add appends to it.
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	testutil.Equal(t, sqlHistorySize+1, strings.Count(string(data), "\n"))
+}
+
+func TestSQLReplFlagWithoutTerminalReadsStdin(t *testing.T) {
+	resetJSONFlag()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AYB_ADMIN_TOKEN", "tok")
+	queries := stubSQLServer(t)
+	sqlCmd.Flags().Set("help", "false") // left set by help tests
+	t.Cleanup(func() { sqlCmd.Flags().Set("repl", "false") })
+
+	oldStdin := os.Stdin
+	r, w, err := os.Pipe()
+	testutil.NoError(t, err)
+	_, err = w.WriteString("SELECT 42;\n")
+	testutil.NoError(t, err)
+	testutil.NoError(t, w.Close())
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = oldStdin
+		_ = r.Close()
+	})
+
+	out := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"sql", "-i"})
+		err = rootCmd.Execute()
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, 1, len(*queries))
+	testutil.Equal(t, "SELECT 42;", (*queries)[0])
+	testutil.Contains(t, out, "(1 rows")
+}
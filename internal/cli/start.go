@@ -15,17 +15,20 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/authevents"
 	"github.com/allyourbase/ayb/internal/cli/ui"
 	"github.com/allyourbase/ayb/internal/config"
 	"github.com/allyourbase/ayb/internal/emailtemplates"
 	"github.com/allyourbase/ayb/internal/fbmigrate"
 	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/logfile"
 	"github.com/allyourbase/ayb/internal/mailer"
 	"github.com/allyourbase/ayb/internal/matview"
 	"github.com/allyourbase/ayb/internal/migrate"
@@ -55,7 +58,10 @@ Migrate and start from PocketBase (single command):
   ayb start --from ./pb_data
 
 Migrate and start from Supabase:
-  ayb start --from postgres://db.xxx.supabase.co:5432/postgres`,
+  ayb start --from postgres://db.xxx.supabase.co:5432/postgres
+
+Apply migrations and reload the schema as you develop:
+  ayb start --watch`,
 	RunE: runStart,
 }
 
@@ -66,6 +72,7 @@ func init() {
 	startCmd.Flags().String("config", "", "Path to ayb.toml config file")
 	startCmd.Flags().String("from", "", "Migrate from another platform and start (path to pb_data, or postgres:// URL)")
 	startCmd.Flags().String("domain", "", "Domain for automatic HTTPS via Let's Encrypt (e.g. api.myapp.com)")
+	startCmd.Flags().Bool("watch", false, "Apply new migrations and reload the schema and ayb.toml on change (development only)")
 	startCmd.Flags().Bool("foreground", false, "Run in foreground (blocks terminal)")
 	startCmd.Flags().MarkHidden("foreground") //nolint:errcheck
 }
@@ -153,8 +160,11 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 
 	// Set up logger. In TTY mode, suppress INFO during startup
 	// (pretty progress lines replace them). Level is restored after server starts.
-	logger, logLevel, logPath, closeLog := newLogger(cfg.Logging.Level, cfg.Logging.Format)
+	logger, logLevel, logPath, closeLog := newLogger(cfg.Logging)
 	defer closeLog()
+	// Keep recent entries for "ayb logs".
+	logBuffer := server.NewLogBuffer(logger.Handler(), logBufferSize)
+	logger = slog.New(logBuffer)
 	if isTTY {
 		logLevel.Set(slog.LevelWarn)
 	}
@@ -197,6 +207,7 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 		logger.Info("no database URL configured, starting managed PostgreSQL")
 		pgMgr = pgmanager.New(pgmanager.Config{
 			Port:    uint32(cfg.Database.EmbeddedPort),
+			Version: cfg.Database.EmbeddedVersion,
 			DataDir: cfg.Database.EmbeddedDataDir,
 			Logger:  logger,
 		})
@@ -221,12 +232,17 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 
 	// Connect to PostgreSQL.
 	sp.step("Connecting to database...")
-	pool, err := postgres.New(ctx, postgres.Config{
+	dbCfg := postgres.Config{
 		URL:             cfg.Database.URL,
 		MaxConns:        int32(cfg.Database.MaxConns),
 		MinConns:        int32(cfg.Database.MinConns),
 		HealthCheckSecs: cfg.Database.HealthCheckSecs,
-	}, logger)
+		ConnectTimeout:  time.Duration(cfg.Database.ConnectTimeout) * time.Second,
+	}
+	if pgMgr == nil {
+		dbCfg.SSL = databaseSSL(cfg)
+	}
+	pool, err := postgres.New(ctx, dbCfg, logger)
 	if err != nil {
 		sp.fail()
 		if pgMgr != nil {
@@ -270,7 +286,7 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 			if err := userRunner.Bootstrap(ctx); err != nil {
 				return fmt.Errorf("bootstrapping user migrations: %w", err)
 			}
-			userApplied, err := userRunner.Up(ctx)
+			userApplied, err := userMigrations(cfg, pool.DB(), cfg.Database.MigrationsDir, logger)(ctx)
 			if err != nil {
 				return fmt.Errorf("running user migrations: %w", err)
 			}
@@ -318,7 +334,8 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 
 	// Conditionally create auth service.
 	var authSvc *auth.Service
-	var smsProvider sms.Provider // nil when SMS disabled; set on both authSvc and server
+	var smsProvider sms.Provider         // nil when SMS disabled; set on both authSvc and server
+	var authEvents *authevents.Publisher // nil when no auth events webhook is configured
 	if cfg.Auth.Enabled {
 		authSvc = auth.NewService(
 			pool.DB(),
@@ -328,6 +345,24 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 			cfg.Auth.MinPasswordLength,
 			logger,
 		)
+		authSvc.SetJWTClaims(cfg.JWTIssuer(), cfg.Auth.JWTAudience)
+		authSvc.SetRLSClaims(cfg.Auth.RLSClaims)
+		if cfg.Tenants.Enabled {
+			authSvc.SetTenantClaim(cfg.Tenants.Claim)
+		}
+		authSvc.SetProfileMetadataKeys(cfg.Auth.ProfileMetadataKeys)
+		authSvc.SetRememberMeDuration(time.Duration(cfg.Auth.RememberMeDuration) * time.Second)
+		authSvc.SetPasswordHash(cfg.Auth.PasswordHash)
+		authSvc.SetEmailNormalization(cfg.Auth.NormalizeEmails)
+		authSvc.SetDisposableEmailBlocking(cfg.Auth.BlockDisposableEmails,
+			cfg.Auth.DisposableEmailDomains, cfg.Auth.DisposableEmailExceptions)
+		if cfg.Auth.Cookies.Enabled {
+			authSvc.SetCookieConfig(&auth.CookieConfig{
+				AccessToken: cfg.Auth.Cookies.AccessToken,
+				SameSite:    cfg.Auth.Cookies.SameSite,
+				Secure:      cfg.Auth.Cookies.Secure,
+			})
+		}
 
 		// Inject mailer into auth service.
 		baseURL := cfg.PublicBaseURL() + "/api"
@@ -345,43 +380,38 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 			authSvc.SetSMSProvider(smsProvider)
 			authSvc.SetSMSConfig(sms.Config{
 				CodeLength:       cfg.Auth.SMSCodeLength,
+				CodeAlphabet:     cfg.Auth.SMSCodeAlphabet,
 				Expiry:           time.Duration(cfg.Auth.SMSCodeExpiry) * time.Second,
 				MaxAttempts:      cfg.Auth.SMSMaxAttempts,
 				DailyLimit:       cfg.Auth.SMSDailyLimit,
+				ResendCooldown:   time.Duration(cfg.Auth.SMSResendCooldown) * time.Second,
 				AllowedCountries: cfg.Auth.SMSAllowedCountries,
+				BlockedCountries: cfg.Auth.SMSBlockedCountries,
 				TestPhoneNumbers: cfg.Auth.SMSTestPhoneNumbers,
+				StatusSecret:     cfg.Auth.SMSStatusSecret,
 			})
 			logger.Info("SMS OTP auth enabled", "provider", cfg.Auth.SMSProvider)
 		}
 		applyOAuthProviderModeConfig(authSvc, cfg)
+		if cfg.Auth.EventsWebhookURL != "" {
+			authEvents = authevents.NewPublisher(cfg.Auth.EventsWebhookURL, cfg.Auth.EventsWebhookSecret, logger)
+			defer authEvents.Close()
+			authSvc.SetEventPublisher(authEvents)
+			logger.Info("auth events enabled", "url", cfg.Auth.EventsWebhookURL)
+		}
 		logger.Info("auth enabled", "email_backend", cfg.Email.Backend)
 	}
 
 	// Conditionally create storage service.
 	var storageSvc *storage.Service
 	if cfg.Storage.Enabled {
-		var storageBackend storage.Backend
-		switch cfg.Storage.Backend {
-		case "s3":
-			s3b, err := storage.NewS3Backend(ctx, storage.S3Config{
-				Endpoint:  cfg.Storage.S3Endpoint,
-				Bucket:    cfg.Storage.S3Bucket,
-				Region:    cfg.Storage.S3Region,
-				AccessKey: cfg.Storage.S3AccessKey,
-				SecretKey: cfg.Storage.S3SecretKey,
-				UseSSL:    cfg.Storage.S3UseSSL,
-			})
-			if err != nil {
-				return fmt.Errorf("initializing S3 storage backend: %w", err)
-			}
-			storageBackend = s3b
+		storageBackend, err := buildStorageBackend(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.Storage.Backend == "s3" {
 			logger.Info("storage enabled", "backend", "s3", "endpoint", cfg.Storage.S3Endpoint, "bucket", cfg.Storage.S3Bucket)
-		default:
-			lb, err := storage.NewLocalBackend(cfg.Storage.LocalPath)
-			if err != nil {
-				return fmt.Errorf("initializing local storage backend: %w", err)
-			}
-			storageBackend = lb
+		} else {
 			logger.Info("storage enabled", "backend", "local", "path", cfg.Storage.LocalPath)
 		}
 		signKey := cfg.Auth.JWTSecret
@@ -399,10 +429,25 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 	// Create and start HTTP server.
 	sp.step("Starting server...")
 	srv := server.New(cfg, logger, schemaCache, pool.DB(), authSvc, storageSvc)
+	srv.SetLogBuffer(logBuffer)
+	srv.SetLogLevel(logLevel)
+
+	// Config import and runtime settings write the file this process loaded
+	// (default ayb.toml).
+	if configPath != "" {
+		srv.SetConfigPath(configPath)
+	} else {
+		srv.SetConfigPath("ayb.toml")
+	}
+
+	// Restore maintenance mode so it survives restarts.
+	if err := srv.LoadMaintenance(ctx); err != nil {
+		logger.Warn("could not load maintenance state", "error", err)
+	}
 
 	// Wire SMS provider into server for the transactional messaging API.
 	if smsProvider != nil {
-		srv.SetSMSProvider(cfg.Auth.SMSProvider, smsProvider, cfg.Auth.SMSAllowedCountries)
+		srv.SetSMSProvider(cfg.Auth.SMSProvider, smsProvider, cfg.Auth.SMSAllowedCountries, cfg.Auth.SMSBlockedCountries)
 	}
 
 	// Wire matview admin service (requires pool for registry table access).
@@ -439,6 +484,10 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 		}
 		jobSvc := jobs.NewService(jobStore, logger, jobCfg)
 		jobs.RegisterBuiltinHandlers(jobSvc, pool.DB(), logger)
+		if authEvents != nil {
+			jobSvc.RegisterHandler(authevents.JobType, authEvents.Deliver)
+			authEvents.SetJobQueue(jobSvc)
+		}
 		srv.SetJobService(jobSvc)
 
 		if err := jobSvc.RegisterDefaultSchedules(ctx); err != nil {
@@ -457,14 +506,19 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 	usrCh := notifyUSR1()
 
 	ready := make(chan struct{})
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 3)
 	go func() {
 		if cfg.Server.TLSEnabled {
-			ln, err := buildTLSListener(ctx, cfg, logger)
+			ln, tlsCfg, err := buildTLSListener(ctx, cfg, logger)
 			if err != nil {
 				errCh <- err
 				return
 			}
+			go func() {
+				if err := srv.StartHTTP3(tlsCfg); err != nil {
+					errCh <- err
+				}
+			}()
 			errCh <- srv.StartTLSWithReady(ln, ready)
 		} else {
 			errCh <- srv.StartWithReady(ready)
@@ -476,9 +530,17 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 	case <-ready:
 		sp.done()
 
+		if srv.GRPCEnabled() {
+			go func() {
+				if err := srv.StartGRPC(); err != nil {
+					errCh <- err
+				}
+			}()
+		}
+
 		// Restore configured log level for runtime (request logging, etc.).
 		if isTTY {
-			logLevel.Set(parseSlogLevel(cfg.Logging.Level))
+			logLevel.Set(cfg.Logging.SlogLevel())
 		}
 
 		// Write PID file so `ayb stop` and `ayb status` can find us.
@@ -503,6 +565,12 @@ func runStartForeground(cmd *cobra.Command, args []string) error {
 			printBanner(cfg, pgMgr != nil, generatedPassword, logPath)
 		}
 
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			if err := startDevWatcher(ctx, cfg, configPath, flags, pool.DB(), schemaCache, srv, logger); err != nil {
+				logger.Error("watch mode disabled", "error", err)
+			}
+		}
+
 		// Handle SIGUSR1 for password reset in background.
 		go func() {
 			for range usrCh {
@@ -814,16 +882,30 @@ func readAYBPID() (int, int, error) {
 	return pid, port, nil
 }
 
+// smtpPort returns email.smtp.port, defaulting to 587 (submission).
+func smtpPort(cfg *config.Config) int {
+	if cfg.Email.SMTP.Port == 0 {
+		return 587
+	}
+	return cfg.Email.SMTP.Port
+}
+
+// databaseSSL returns the TLS settings for an external database.
+func databaseSSL(cfg *config.Config) postgres.SSLConfig {
+	return postgres.SSLConfig{
+		Mode:     cfg.Database.SSLMode,
+		RootCert: cfg.Database.SSLRootCert,
+		Cert:     cfg.Database.SSLCert,
+		Key:      cfg.Database.SSLKey,
+	}
+}
+
 func buildMailer(cfg *config.Config, logger *slog.Logger) mailer.Mailer {
 	switch cfg.Email.Backend {
 	case "smtp":
-		port := cfg.Email.SMTP.Port
-		if port == 0 {
-			port = 587
-		}
 		return mailer.NewSMTPMailer(mailer.SMTPConfig{
 			Host:       cfg.Email.SMTP.Host,
-			Port:       port,
+			Port:       smtpPort(cfg),
 			Username:   cfg.Email.SMTP.Username,
 			Password:   cfg.Email.SMTP.Password,
 			From:       cfg.Email.From,
@@ -846,7 +928,45 @@ func buildMailer(cfg *config.Config, logger *slog.Logger) mailer.Mailer {
 	}
 }
 
+// buildStorageBackend constructs the backend named by storage.backend.
+func buildStorageBackend(ctx context.Context, cfg *config.Config) (storage.Backend, error) {
+	if cfg.Storage.Backend == "s3" {
+		s3b, err := storage.NewS3Backend(ctx, storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Bucket:    cfg.Storage.S3Bucket,
+			Region:    cfg.Storage.S3Region,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			UseSSL:    cfg.Storage.S3UseSSL,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initializing S3 storage backend: %w", err)
+		}
+		return s3b, nil
+	}
+	lb, err := storage.NewLocalBackend(cfg.Storage.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("initializing local storage backend: %w", err)
+	}
+	return lb, nil
+}
+
+// buildSMSProvider constructs the configured SMS provider and applies any
+// per-country sender overrides.
 func buildSMSProvider(cfg *config.Config, logger *slog.Logger) sms.Provider {
+	p := newSMSProvider(cfg, logger)
+	if len(cfg.Auth.SMSSenderIDs) > 0 {
+		if o, ok := p.(sms.SenderOverrider); ok {
+			o.SetSenderOverrides(cfg.Auth.SMSSenderIDs)
+		} else {
+			logger.Warn("auth.sms_sender_ids is ignored: provider has no configurable sender", "provider", cfg.Auth.SMSProvider)
+		}
+	}
+	return p
+}
+
+// newSMSProvider constructs the provider named by auth.sms_provider.
+func newSMSProvider(cfg *config.Config, logger *slog.Logger) sms.Provider {
 	switch cfg.Auth.SMSProvider {
 	case "twilio":
 		return sms.NewTwilioProvider(cfg.Auth.TwilioSID, cfg.Auth.TwilioToken, cfg.Auth.TwilioFrom, "")
@@ -953,38 +1073,62 @@ func (h *multiHandler) WithGroup(name string) slog.Handler {
 	return &multiHandler{handlers: handlers}
 }
 
-// newLogger creates a logger that writes to stderr and optionally to a log file.
-// The log file receives all levels (DEBUG+) while stderr uses the configured level.
-// Returns the logger, the stderr level var (for runtime adjustment), the log file
-// path (empty if file logging failed), and an optional file closer.
-func newLogger(level, format string) (*slog.Logger, *slog.LevelVar, string, func()) {
+// logBufferSize is how many recent log entries the server keeps for the
+// /api/admin/logs endpoint.
+const logBufferSize = 1000
+
+// newLogger creates a logger for the [logging] config. With output "stderr"
+// (the default) or "stdout" it logs there at the configured level and also
+// writes all levels (DEBUG+) to a daily file under ~/.ayb/logs. With a file
+// path as output it logs only to that file, rotating it per the config.
+// Returns the logger, the console level var (for runtime adjustment), the log
+// file path (empty if file logging failed), and a closer.
+func newLogger(cfg config.LoggingConfig) (*slog.Logger, *slog.LevelVar, string, func()) {
 	var lvlVar slog.LevelVar
-	lvlVar.Set(parseSlogLevel(level))
+	lvlVar.Set(cfg.SlogLevel())
 
 	opts := &slog.HandlerOptions{Level: &lvlVar}
+	newHandler := func(w io.Writer) slog.Handler {
+		if cfg.Format == "text" {
+			return slog.NewTextHandler(w, opts)
+		}
+		return slog.NewJSONHandler(w, opts)
+	}
 
-	var stderrHandler slog.Handler
-	if format == "text" {
-		stderrHandler = slog.NewTextHandler(os.Stderr, opts)
-	} else {
-		stderrHandler = slog.NewJSONHandler(os.Stderr, opts)
+	if path := cfg.LogFile(); path != "" {
+		w, err := logfile.Open(path, logfile.Options{
+			MaxSize:    int64(cfg.MaxSizeMB) << 20,
+			MaxAge:     time.Duration(cfg.MaxAgeHours) * time.Hour,
+			MaxBackups: cfg.MaxBackups,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v; logging to stderr\n", err)
+			return slog.New(newHandler(os.Stderr)), &lvlVar, "", func() {}
+		}
+		return slog.New(newHandler(w)), &lvlVar, w.Path(), func() { w.Close() }
 	}
 
+	var console io.Writer = os.Stderr
+	if cfg.Output == "stdout" {
+		console = os.Stdout
+	}
+	consoleHandler := newHandler(console)
+
 	// Try to open a log file for detailed output.
 	logPath := logFilePath()
 	if logPath == "" {
-		return slog.New(stderrHandler), &lvlVar, "", func() {}
+		return slog.New(consoleHandler), &lvlVar, "", func() {}
 	}
 
 	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
 	if err != nil {
-		return slog.New(stderrHandler), &lvlVar, "", func() {}
+		return slog.New(consoleHandler), &lvlVar, "", func() {}
 	}
 
 	fileOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
 	fileHandler := slog.NewJSONHandler(f, fileOpts)
 
-	handler := &multiHandler{handlers: []slog.Handler{stderrHandler, fileHandler}}
+	handler := &multiHandler{handlers: []slog.Handler{consoleHandler, fileHandler}}
 
 	// Clean old logs in the background.
 	go cleanOldLogs()
@@ -992,19 +1136,6 @@ func newLogger(level, format string) (*slog.Logger, *slog.LevelVar, string, func
 	return slog.New(handler), &lvlVar, logPath, func() { f.Close() }
 }
 
-func parseSlogLevel(level string) slog.Level {
-	switch level {
-	case "debug":
-		return slog.LevelDebug
-	case "warn":
-		return slog.LevelWarn
-	case "error":
-		return slog.LevelError
-	default:
-		return slog.LevelInfo
-	}
-}
-
 // startupProgress provides human-readable startup steps for interactive terminals.
 // In TTY mode it shows animated spinners; in non-TTY mode all methods are no-ops.
 type startupProgress struct {
@@ -1037,10 +1168,11 @@ func portInUse(port int) bool {
 }
 
 // healthCheckURL returns the URL to poll for health during background startup.
-// TLS always listens on port 443; plain HTTP uses the configured port.
+// Let's Encrypt TLS always listens on port 443; plain HTTP and TLS with
+// certificate files use the configured port.
 func healthCheckURL(cfg *config.Config) string {
 	if cfg.Server.TLSEnabled {
-		return "https://127.0.0.1:443/health"
+		return fmt.Sprintf("https://127.0.0.1:%d/health", cfg.TLSPort())
 	}
 	port := cfg.Server.Port
 	if port == 0 {
@@ -1113,10 +1245,35 @@ func (sp *startupProgress) fail() {
 	sp.spinner.Fail()
 }
 
-// buildTLSListener uses certmagic to obtain a Let's Encrypt certificate and
-// returns a TLS net.Listener on port 443. It also starts an HTTP-01 challenge
+// buildTLSListener returns a TLS net.Listener on cfg.TLSAddress() and its
+// tls.Config, which the HTTP/3 listener shares. h2 is offered to clients
+// only when server.http2 is set.
+func buildTLSListener(ctx context.Context, cfg *config.Config, logger *slog.Logger) (net.Listener, *tls.Config, error) {
+	var tlsCfg *tls.Config
+	var err error
+	if cfg.Server.TLSCertFile != "" {
+		tlsCfg, err = buildCertFileTLSConfig(ctx, cfg, logger)
+	} else {
+		tlsCfg, err = buildACMETLSConfig(ctx, cfg, logger)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cfg.Server.HTTP2 {
+		tlsCfg.NextProtos = slices.DeleteFunc(slices.Clone(tlsCfg.NextProtos), func(p string) bool { return p == "h2" })
+	}
+
+	ln, err := tls.Listen("tcp", cfg.TLSAddress(), tlsCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("TLS listen on %s: %w", cfg.TLSAddress(), err)
+	}
+	return ln, tlsCfg, nil
+}
+
+// buildACMETLSConfig uses certmagic to obtain a Let's Encrypt certificate
+// and returns a tls.Config serving it. It also starts an HTTP-01 challenge
 // responder + HTTP→HTTPS redirect on port 80 in a background goroutine.
-func buildTLSListener(ctx context.Context, cfg *config.Config, logger *slog.Logger) (net.Listener, error) {
+func buildACMETLSConfig(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*tls.Config, error) {
 	certDir := cfg.Server.TLSCertDir
 	if certDir == "" {
 		home, _ := os.UserHomeDir()
@@ -1155,11 +1312,7 @@ func buildTLSListener(ctx context.Context, cfg *config.Config, logger *slog.Logg
 		}
 	}()
 
-	ln, err := tls.Listen("tcp", fmt.Sprintf("%s:443", cfg.Server.Host), tlsCfg)
-	if err != nil {
-		return nil, fmt.Errorf("TLS listen on :443: %w", err)
-	}
-	return ln, nil
+	return tlsCfg, nil
 }
 
 // portError wraps common listen errors with actionable suggestions.
@@ -1211,6 +1364,9 @@ func printBannerBodyTo(w io.Writer, cfg *config.Config, embeddedPG bool, useColo
 		fmt.Fprintf(w, "  %s %s\n", padLabel("Admin:", 10), cyan(adminURL, useColor))
 	}
 	fmt.Fprintf(w, "  %s %s\n", padLabel("Database:", 10), dbMode)
+	if cfg.GRPC.Enabled {
+		fmt.Fprintf(w, "  %s %s\n", padLabel("gRPC:", 10), cyan(cfg.GRPCAddress(), useColor))
+	}
 	if cfg.Auth.MinPasswordLength > 0 && cfg.Auth.MinPasswordLength < 8 {
 		fmt.Fprintln(w)
 		fmt.Fprintf(w, "  %s\n", yellow(fmt.Sprintf(
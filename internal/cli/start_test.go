@@ -146,7 +146,7 @@ func TestCleanOldLogsNoDir(t *testing.T) {
 // --- newLogger ---
 
 func TestNewLoggerReturnsComponents(t *testing.T) {
-	logger, lvl, logPath, closer := newLogger("info", "json")
+	logger, lvl, logPath, closer := newLogger(config.LoggingConfig{Level: "info", Format: "json"})
 	defer closer()
 
 	testutil.NotNil(t, logger)
@@ -158,19 +158,36 @@ func TestNewLoggerReturnsComponents(t *testing.T) {
 }
 
 func TestNewLoggerTextFormat(t *testing.T) {
-	logger, _, _, closer := newLogger("info", "text")
+	logger, _, _, closer := newLogger(config.LoggingConfig{Level: "info", Format: "text"})
 	defer closer()
 	testutil.NotNil(t, logger)
 }
 
 func TestNewLoggerLevelAdjustable(t *testing.T) {
-	_, lvl, _, closer := newLogger("info", "json")
+	_, lvl, _, closer := newLogger(config.LoggingConfig{Level: "info", Format: "json"})
 	defer closer()
 
 	lvl.Set(slog.LevelWarn)
 	testutil.Equal(t, slog.LevelWarn, lvl.Level())
 }
 
+func TestNewLoggerFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "ayb.log")
+	logger, _, logPath, closer := newLogger(config.LoggingConfig{
+		Level: "info", Format: "json", Output: path, MaxSizeMB: 1,
+	})
+	testutil.Equal(t, path, logPath)
+
+	logger.Debug("hidden")
+	logger.Info("hello", "k", "v")
+	closer()
+
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	testutil.Contains(t, string(data), `"msg":"hello"`)
+	testutil.False(t, strings.Contains(string(data), "hidden"), "debug line should be filtered: %s", data)
+}
+
 // --- Banner body-only path ---
 
 func TestBannerBodyToContainsAPIURL(t *testing.T) {
@@ -330,29 +347,6 @@ func TestBuildChildArgsEmptySubcommand(t *testing.T) {
 	testutil.Equal(t, "--foreground", args[1])
 }
 
-// --- parseSlogLevel ---
-
-func TestParseSlogLevel(t *testing.T) {
-	tests := []struct {
-		input string
-		want  slog.Level
-	}{
-		{"debug", slog.LevelDebug},
-		{"info", slog.LevelInfo},
-		{"warn", slog.LevelWarn},
-		{"error", slog.LevelError},
-		{"", slog.LevelInfo},        // default
-		{"unknown", slog.LevelInfo}, // unknown → default
-		{"DEBUG", slog.LevelInfo},   // case-sensitive, uppercase → default
-	}
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := parseSlogLevel(tt.input)
-			testutil.Equal(t, tt.want, got)
-		})
-	}
-}
-
 // --- multiHandler ---
 
 func TestMultiHandlerFanOut(t *testing.T) {
@@ -548,7 +542,7 @@ func TestCleanupServerFilesIdempotent(t *testing.T) {
 func TestNewLoggerNoHome(t *testing.T) {
 	// Unset HOME to exercise the fallback path
 	t.Setenv("HOME", "/nonexistent-path-that-should-not-exist")
-	logger, lvl, _, closer := newLogger("info", "json")
+	logger, lvl, _, closer := newLogger(config.LoggingConfig{Level: "info", Format: "json"})
 	defer closer()
 
 	testutil.NotNil(t, logger)
@@ -676,6 +670,16 @@ func TestHealthCheckURLWithTLS(t *testing.T) {
 	testutil.Equal(t, "https://127.0.0.1:443/health", got)
 }
 
+func TestHealthCheckURLWithTLSCertFile(t *testing.T) {
+	cfg := defaultTestConfig()
+	cfg.Server.TLSEnabled = true
+	cfg.Server.TLSCertFile = "/etc/ayb/cert.pem"
+	cfg.Server.Port = 8443
+	got := healthCheckURL(cfg)
+	// Certificate files are served on the configured port.
+	testutil.Equal(t, "https://127.0.0.1:8443/health", got)
+}
+
 func TestHealthCheckURLTLSIgnoresPort(t *testing.T) {
 	cfg := defaultTestConfig()
 	cfg.Server.TLSEnabled = true
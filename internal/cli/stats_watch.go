@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/spf13/cobra"
+)
+
+// statsWatchHistory is how many refreshes each trend line covers.
+const statsWatchHistory = 30
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// sparkBlocks draw trend lines, lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// statsWatch renders successive /api/admin/stats snapshots. The endpoint
+// reports a running request count; the rate shown is its change between
+// refreshes.
+type statsWatch struct {
+	interval  time.Duration
+	prevTotal int64
+	prevAt    time.Time
+	history   map[string][]float64
+}
+
+func newStatsWatch(interval time.Duration) *statsWatch {
+	return &statsWatch{interval: interval, history: map[string][]float64{}}
+}
+
+func runStatsWatch(cmd *cobra.Command, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("--watch interval must be positive")
+	}
+	if format := outputFormat(cmd); format != "table" {
+		return fmt.Errorf("--watch only supports table output, not %s", format)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	watch := newStatsWatch(interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for first := true; ; first = false {
+		stats, jobStats, err := fetchStatsSnapshot(cmd)
+		if err != nil && first {
+			return err
+		}
+		fmt.Print(clearScreen)
+		if err != nil {
+			fmt.Printf("%v (retrying every %s)\n", err, interval)
+		} else {
+			watch.render(os.Stdout, stats, jobStats, time.Now())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchStatsSnapshot fetches and decodes /api/admin/stats and the job queue
+// stats, which are nil when jobs are disabled.
+func fetchStatsSnapshot(cmd *cobra.Command) (map[string]any, *jobs.QueueStats, error) {
+	body, err := fetchStats(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stats map[string]any
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, nil, fmt.Errorf("parsing response: %w", err)
+	}
+	jobStats, err := fetchJobStats(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, jobStats, nil
+}
+
+// render writes one screen of stats. Rows for subsystems the server doesn't
+// report (no database pool, jobs or SMS disabled) are left out.
+func (w *statsWatch) render(out io.Writer, stats map[string]any, jobStats *jobs.QueueStats, now time.Time) {
+	fmt.Fprintf(out, "AYB Server Statistics — every %s, Ctrl+C to exit   %s\n", w.interval, now.Format("15:04:05"))
+	fmt.Fprintln(out, "─────────────────────")
+
+	if total, ok := statInt(stats, "requests_total"); ok {
+		rate := "—"
+		if !w.prevAt.IsZero() && now.After(w.prevAt) && total >= w.prevTotal {
+			perSec := float64(total-w.prevTotal) / now.Sub(w.prevAt).Seconds()
+			rate = fmt.Sprintf("%.1f/s", perSec)
+			w.record("requests", perSec)
+		}
+		w.prevTotal, w.prevAt = total, now
+		inFlight, _ := statInt(stats, "requests_in_flight")
+		w.row(out, "Requests", "requests", fmt.Sprintf("%s (%d total, %d in flight)", rate, total, inFlight))
+	}
+
+	if inUse, ok := statInt(stats, "db_pool_in_use"); ok {
+		poolMax, _ := statInt(stats, "db_pool_max")
+		idle, _ := statInt(stats, "db_pool_idle")
+		w.record("db_pool", float64(inUse))
+		w.row(out, "DB pool", "db_pool", fmt.Sprintf("%d/%d in use, %d idle", inUse, poolMax, idle))
+	}
+
+	if jobStats != nil {
+		w.record("jobs", float64(jobStats.Queued))
+		w.row(out, "Job queue", "jobs", fmt.Sprintf("%d queued, %d running, %d/%d workers busy",
+			jobStats.Queued, jobStats.Running, jobStats.BusyWorkers, jobStats.Workers))
+	}
+
+	if sent, ok := statInt(stats, "sms_sent_today"); ok {
+		confirmed, _ := statInt(stats, "sms_confirmed_today")
+		failed, _ := statInt(stats, "sms_failed_today")
+		w.record("sms", float64(sent))
+		w.row(out, "SMS today", "sms", fmt.Sprintf("%d sent, %d confirmed, %d failed", sent, confirmed, failed))
+	}
+
+	uptime, _ := statInt(stats, "uptime_seconds")
+	alloc, _ := statInt(stats, "memory_alloc")
+	goroutines, _ := statInt(stats, "goroutines")
+	w.row(out, "Uptime", "", (time.Duration(uptime) * time.Second).String())
+	w.row(out, "Memory", "", fmt.Sprintf("%.1f MB, %d goroutines", float64(alloc)/(1<<20), goroutines))
+}
+
+// record appends a sample to a metric's trend, keeping the last
+// statsWatchHistory samples.
+func (w *statsWatch) record(metric string, v float64) {
+	h := append(w.history[metric], v)
+	if len(h) > statsWatchHistory {
+		h = h[len(h)-statsWatchHistory:]
+	}
+	w.history[metric] = h
+}
+
+func (w *statsWatch) row(out io.Writer, label, metric, value string) {
+	line := fmt.Sprintf("  %-11s %-42s %s", label, value, sparkline(w.history[metric]))
+	fmt.Fprintln(out, strings.TrimRight(line, " "))
+}
+
+// sparkline draws values scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// statInt reads a JSON number from the stats response.
+func statInt(stats map[string]any, key string) (int64, bool) {
+	v, ok := stats[key].(float64)
+	return int64(v), ok
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestStatsWatchFlagParses(t *testing.T) {
+	t.Cleanup(func() {
+		statsCmd.Flags().Set("watch", "0s")
+		statsCmd.Flags().Lookup("watch").Changed = false
+	})
+	flag := statsCmd.Flags().Lookup("watch")
+	testutil.Equal(t, "duration", flag.Value.Type())
+
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"--watch"}, 2 * time.Second},
+		{[]string{"--watch=10s"}, 10 * time.Second},
+		{[]string{"--watch=500ms"}, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		testutil.NoError(t, statsCmd.ParseFlags(tt.args))
+		got, err := statsCmd.Flags().GetDuration("watch")
+		testutil.NoError(t, err)
+		testutil.Equal(t, tt.want, got)
+	}
+	testutil.ErrorContains(t, statsCmd.ParseFlags([]string{"--watch=often"}), "invalid argument")
+}
+
+func TestStatsWatchRejectsInvalidInterval(t *testing.T) {
+	resetJSONFlag()
+	testutil.ErrorContains(t, runStatsWatch(statsCmd, 0), "--watch interval must be positive")
+}
+
+func TestStatsWatchRender(t *testing.T) {
+	resetJSONFlag()
+	total := 100
+	stubAdminHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/admin/jobs/stats" {
+			json.NewEncoder(w).Encode(jobs.QueueStats{Queued: 7, Running: 1, Workers: 4, BusyWorkers: 1})
+			return
+		}
+		testutil.Equal(t, "/api/admin/stats", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]any{
+			"uptime_seconds": 3725, "goroutines": 12, "memory_alloc": 3 << 20,
+			"requests_total": total, "requests_in_flight": 2,
+			"db_pool_total": 6, "db_pool_idle": 2, "db_pool_in_use": 4, "db_pool_max": 20,
+		})
+	})
+	statsCmd.Flags().Set("url", testAdminURL)
+	statsCmd.Flags().Set("admin-token", "tok")
+	t.Cleanup(func() {
+		statsCmd.Flags().Set("url", "")
+		statsCmd.Flags().Set("admin-token", "")
+	})
+
+	watch := newStatsWatch(2 * time.Second)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	render := func(at time.Time) string {
+		stats, jobStats, err := fetchStatsSnapshot(statsCmd)
+		testutil.NoError(t, err)
+		var buf bytes.Buffer
+		watch.render(&buf, stats, jobStats, at)
+		return buf.String()
+	}
+
+	out := render(start)
+	testutil.Contains(t, out, "every 2s")
+	testutil.Contains(t, out, "Requests    — (100 total, 2 in flight)")
+	testutil.Contains(t, out, "DB pool     4/20 in use, 2 idle")
+	testutil.Contains(t, out, "Job queue   7 queued, 1 running, 1/4 workers busy")
+	testutil.Contains(t, out, "Uptime      1h2m5s")
+	testutil.Contains(t, out, "Memory      3.0 MB, 12 goroutines")
+	testutil.False(t, strings.Contains(out, "SMS today"), "SMS row needs SMS stats")
+
+	total = 150
+	out = render(start.Add(2 * time.Second))
+	testutil.Contains(t, out, "Requests    25.0/s (150 total, 2 in flight)")
+}
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "", sparkline(nil))
+	testutil.Equal(t, "▁▁▁", sparkline([]float64{5, 5, 5}))
+	testutil.Equal(t, "▁▄█", sparkline([]float64{0, 5, 10}))
+}
@@ -69,20 +69,25 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Probe health endpoint.
 	healthy := false
+	var health struct {
+		Maintenance bool `json:"maintenance"`
+	}
 	healthURL := fmt.Sprintf("http://127.0.0.1:%d/health", port)
 	client := &http.Client{Timeout: 2 * time.Second}
 	resp, err := client.Get(healthURL)
 	if err == nil {
 		healthy = resp.StatusCode == http.StatusOK
+		_ = json.NewDecoder(resp.Body).Decode(&health)
 		resp.Body.Close()
 	}
 
 	if jsonOut {
 		return json.NewEncoder(out).Encode(map[string]any{
-			"status":  "running",
-			"pid":     pid,
-			"port":    port,
-			"healthy": healthy,
+			"status":      "running",
+			"pid":         pid,
+			"port":        port,
+			"healthy":     healthy,
+			"maintenance": health.Maintenance,
 		})
 	}
 
@@ -103,5 +108,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(out, "  Health:  %s unreachable\n", ui.SymbolCross)
 		}
 	}
+	if health.Maintenance {
+		if useColor {
+			fmt.Fprintf(out, "  Mode:    %s %s\n", ui.StyleWarning.Render(ui.SymbolDot), "maintenance")
+		} else {
+			fmt.Fprintf(out, "  Mode:    %s maintenance\n", ui.SymbolWarning)
+		}
+	}
 	return nil
 }
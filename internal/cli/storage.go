@@ -110,6 +110,9 @@ func runStorageLs(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var result struct {
 		Items []struct {
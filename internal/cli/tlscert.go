@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// certReloader serves the certificate in server.tls_cert_file and
+// server.tls_key_file, reloading it when the files change so a renewed
+// certificate takes effect without a restart.
+type certReloader struct {
+	certFile, keyFile string
+	logger            *slog.Logger
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// newCertReloader loads the key pair, failing if it can't be read.
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the key pair from disk and reports whether the certificate
+// changed. On error the current certificate stays in use.
+func (r *certReloader) reload() (bool, error) {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := r.cert == nil || !bytes.Equal(r.cert.Certificate[0], cert.Certificate[0])
+	r.cert = &cert
+	return changed, nil
+}
+
+// getCertificate is the tls.Config GetCertificate callback.
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// watch reloads the certificate whenever the directories holding the files
+// change, until ctx is canceled. Watching the directories rather than the
+// files catches renames over the old files and Kubernetes secret updates,
+// which swap a symlink.
+func (r *certReloader) watch(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating certificate watcher: %w", err)
+	}
+	for _, dir := range uniqueDirs(r.certFile, r.keyFile) {
+		if err := fw.Add(dir); err != nil {
+			fw.Close()
+			return fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+	go r.run(ctx, fw)
+	return nil
+}
+
+// run handles file events until ctx is canceled, then closes fw. Events are
+// debounced, since a renewal usually writes both files.
+func (r *certReloader) run(ctx context.Context, fw *fsnotify.Watcher) {
+	defer fw.Close()
+
+	var reloadAfter <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			reloadAfter = time.After(watchDebounce)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Warn("certificate watcher error", "error", err)
+		case <-reloadAfter:
+			reloadAfter = nil
+			changed, err := r.reload()
+			if err != nil {
+				r.logger.Warn("keeping the current TLS certificate", "error", err)
+			} else if changed {
+				r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile)
+			}
+		}
+	}
+}
+
+// uniqueDirs returns the directories of paths, without duplicates.
+func uniqueDirs(paths ...string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		dir := filepath.Dir(p)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// buildCertFileTLSConfig returns a tls.Config that serves
+// server.tls_cert_file, reloading it as it changes.
+func buildCertFileTLSConfig(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*tls.Config, error) {
+	reloader, err := newCertReloader(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := reloader.watch(ctx); err != nil {
+		return nil, err
+	}
+	logger.Info("serving TLS certificate from file", "cert_file", cfg.Server.TLSCertFile)
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: reloader.getCertificate,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+// servedCert returns the DER bytes of the certificate r serves.
+func servedCert(t *testing.T, r *certReloader) []byte {
+	t.Helper()
+	cert, err := r.getCertificate(&tls.ClientHelloInfo{})
+	testutil.NoError(t, err)
+	return cert.Certificate[0]
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutil.WriteKeyPair(t, dir, "api.internal")
+	r, err := newCertReloader(certFile, keyFile, testutil.DiscardLogger())
+	testutil.NoError(t, err)
+	first := servedCert(t, r)
+
+	changed, err := r.reload()
+	testutil.NoError(t, err)
+	testutil.False(t, changed, "reloading the same files should not report a change")
+
+	testutil.WriteKeyPair(t, dir, "api.internal")
+	changed, err = r.reload()
+	testutil.NoError(t, err)
+	testutil.True(t, changed, "a new certificate should be reported")
+	testutil.False(t, bytes.Equal(first, servedCert(t, r)), "the new certificate should be served")
+}
+
+func TestCertReloaderKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutil.WriteKeyPair(t, dir, "api.internal")
+	r, err := newCertReloader(certFile, keyFile, testutil.DiscardLogger())
+	testutil.NoError(t, err)
+	first := servedCert(t, r)
+
+	testutil.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	_, err = r.reload()
+	testutil.ErrorContains(t, err, "loading TLS certificate")
+	testutil.True(t, bytes.Equal(first, servedCert(t, r)), "the old certificate should still be served")
+}
+
+func TestNewCertReloaderMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := newCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), testutil.DiscardLogger())
+	testutil.ErrorContains(t, err, "loading TLS certificate")
+}
+
+func TestCertReloaderWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := testutil.WriteKeyPair(t, dir, "api.internal")
+	r, err := newCertReloader(certFile, keyFile, testutil.DiscardLogger())
+	testutil.NoError(t, err)
+	first := servedCert(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	testutil.NoError(t, r.watch(ctx))
+
+	testutil.WriteKeyPair(t, dir, "api.internal")
+	deadline := time.Now().Add(5 * time.Second)
+	for bytes.Equal(first, servedCert(t, r)) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	testutil.False(t, bytes.Equal(first, servedCert(t, r)), "the certificate should be reloaded after the files change")
+}
+
+// dialTLSListener builds the TLS listener for a certificate-file config and
+// returns the protocol a client offering h2 and http/1.1 negotiates.
+func dialTLSListener(t *testing.T, http2 bool) tls.ConnectionState {
+	t.Helper()
+	certFile, keyFile := testutil.WriteKeyPair(t, t.TempDir(), "api.internal")
+	cfg := defaultTestConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = 0
+	cfg.Server.TLSCertFile = certFile
+	cfg.Server.TLSKeyFile = keyFile
+	cfg.Server.HTTP2 = http2
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, _, err := buildTLSListener(ctx, cfg, testutil.DiscardLogger())
+	testutil.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // self-signed test certificate
+		NextProtos:         []string{"h2", "http/1.1"},
+	})
+	testutil.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState()
+}
+
+func TestBuildTLSListenerCertFile(t *testing.T) {
+	state := dialTLSListener(t, true)
+	testutil.Equal(t, "api.internal", state.PeerCertificates[0].Subject.CommonName)
+	testutil.Equal(t, "h2", state.NegotiatedProtocol)
+}
+
+func TestBuildTLSListenerHTTP2Disabled(t *testing.T) {
+	state := dialTLSListener(t, false)
+	testutil.Equal(t, "http/1.1", state.NegotiatedProtocol)
+}
+
+func TestUniqueDirs(t *testing.T) {
+	testutil.Equal(t, "/etc/tls", strings.Join(uniqueDirs("/etc/tls/cert.pem", "/etc/tls/key.pem"), ","))
+	testutil.Equal(t, "/etc/tls,/etc/keys", strings.Join(uniqueDirs("/etc/tls/cert.pem", "/etc/keys/key.pem"), ","))
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -30,6 +33,56 @@ var usersDeleteCmd = &cobra.Command{
 	RunE:  runUsersDelete,
 }
 
+var usersDisableCmd = &cobra.Command{
+	Use:   "disable <id>",
+	Short: "Disable a user: block login and sign them out, keeping their data",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersDisable,
+}
+
+var usersEnableCmd = &cobra.Command{
+	Use:   "enable <id>",
+	Short: "Re-enable a disabled user",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersEnable,
+}
+
+var usersLogoutCmd = &cobra.Command{
+	Use:   "logout <id>",
+	Short: "Sign a user out everywhere by revoking their refresh tokens",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUsersLogout,
+}
+
+var usersExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export everything stored about a user as JSON",
+	Long: `Export everything stored about a user as one JSON document: their profile,
+linked OAuth accounts, API keys (metadata only), MFA methods and sessions.
+Use it to answer data access requests. Secrets such as password and token
+hashes are never included.
+
+Examples:
+  ayb users export 5f0c...
+  ayb users export 5f0c... --file user-export.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUsersExport,
+}
+
+var usersBulkDeleteCmd = &cobra.Command{
+	Use:   "bulk-delete",
+	Short: "Delete all users matching a filter",
+	Long: `Delete all users matching a filter. Filters are combined, and at least
+one is required. The number of matching users is shown before anything is
+deleted.
+
+Examples:
+  ayb users bulk-delete --unverified --created-before 2026-01-01
+  ayb users bulk-delete --disabled --yes`,
+	Args: cobra.NoArgs,
+	RunE: runUsersBulkDelete,
+}
+
 func init() {
 	usersCmd.PersistentFlags().String("admin-token", "", "Admin token (or set AYB_ADMIN_TOKEN)")
 	usersCmd.PersistentFlags().String("url", "", "Server URL (default http://127.0.0.1:8090)")
@@ -38,8 +91,21 @@ func init() {
 	usersListCmd.Flags().Int("page", 1, "Page number")
 	usersListCmd.Flags().Int("per-page", 20, "Items per page")
 
+	usersExportCmd.Flags().String("file", "", "Write the export to this file instead of stdout")
+
+	usersBulkDeleteCmd.Flags().String("search", "", "Only users whose email contains this text")
+	usersBulkDeleteCmd.Flags().Bool("disabled", false, "Only disabled users")
+	usersBulkDeleteCmd.Flags().Bool("unverified", false, "Only users with an unverified email")
+	usersBulkDeleteCmd.Flags().String("created-before", "", "Only users created before this date (YYYY-MM-DD or RFC 3339)")
+	usersBulkDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
 	usersCmd.AddCommand(usersListCmd)
 	usersCmd.AddCommand(usersDeleteCmd)
+	usersCmd.AddCommand(usersDisableCmd)
+	usersCmd.AddCommand(usersEnableCmd)
+	usersCmd.AddCommand(usersLogoutCmd)
+	usersCmd.AddCommand(usersExportCmd)
+	usersCmd.AddCommand(usersBulkDeleteCmd)
 }
 
 func runUsersList(cmd *cobra.Command, args []string) error {
@@ -68,12 +134,16 @@ func runUsersList(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var result struct {
 		Items []struct {
 			ID            string `json:"id"`
 			Email         string `json:"email"`
 			EmailVerified bool   `json:"emailVerified"`
+			DisabledAt    string `json:"disabledAt"`
 			CreatedAt     string `json:"createdAt"`
 		} `json:"items"`
 		Page       int `json:"page"`
@@ -91,14 +161,18 @@ func runUsersList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build string rows for table and CSV output.
-	cols := []string{"ID", "Email", "Verified", "Created"}
+	cols := []string{"ID", "Email", "Verified", "Disabled", "Created"}
 	rows := make([][]string, len(result.Items))
 	for i, u := range result.Items {
 		verified := "no"
 		if u.EmailVerified {
 			verified = "yes"
 		}
-		rows[i] = []string{u.ID, u.Email, verified, u.CreatedAt}
+		disabled := "no"
+		if u.DisabledAt != "" {
+			disabled = "yes"
+		}
+		rows[i] = []string{u.ID, u.Email, verified, disabled, u.CreatedAt}
 	}
 
 	if outFmt == "csv" {
@@ -129,3 +203,161 @@ func runUsersDelete(cmd *cobra.Command, args []string) error {
 	}
 	return serverError(resp.StatusCode, body)
 }
+
+func runUsersDisable(cmd *cobra.Command, args []string) error {
+	return runUsersAction(cmd, args[0], "disable", "User %s disabled.\n")
+}
+
+func runUsersEnable(cmd *cobra.Command, args []string) error {
+	return runUsersAction(cmd, args[0], "enable", "User %s enabled.\n")
+}
+
+// runUsersAction posts to /api/admin/users/{id}/{action}, which responds 204
+// on success.
+func runUsersAction(cmd *cobra.Command, id, action, done string) error {
+	resp, body, err := adminRequest(cmd, "POST", "/api/admin/users/"+id+"/"+action, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		fmt.Printf(done, id)
+		return nil
+	}
+	return serverError(resp.StatusCode, body)
+}
+
+func runUsersLogout(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	resp, body, err := adminRequest(cmd, "POST", "/api/admin/users/"+id+"/logout", nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return serverError(resp.StatusCode, body)
+	}
+
+	var result struct {
+		RevokedSessions int `json:"revokedSessions"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	fmt.Printf("User %s logged out (%d sessions revoked).\n", id, result.RevokedSessions)
+	return nil
+}
+
+func runUsersExport(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	file, _ := cmd.Flags().GetString("file")
+
+	resp, body, err := adminRequest(cmd, "GET", "/api/admin/users/"+id+"/export", nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return serverError(resp.StatusCode, body)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	out.WriteByte('\n')
+
+	if file == "" {
+		fmt.Print(out.String())
+		return nil
+	}
+	// The export holds personal data, so keep it private to the current user.
+	if err := os.WriteFile(file, out.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing export: %w", err)
+	}
+	fmt.Printf("Exported user %s to %s\n", id, file)
+	return nil
+}
+
+func runUsersBulkDelete(cmd *cobra.Command, args []string) error {
+	search, _ := cmd.Flags().GetString("search")
+	disabled, _ := cmd.Flags().GetBool("disabled")
+	unverified, _ := cmd.Flags().GetBool("unverified")
+	createdBefore, _ := cmd.Flags().GetString("created-before")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	filter := map[string]any{}
+	if search != "" {
+		filter["search"] = search
+	}
+	if disabled {
+		filter["disabled"] = true
+	}
+	if unverified {
+		filter["unverified"] = true
+	}
+	if createdBefore != "" {
+		t, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, createdBefore); err != nil {
+				return fmt.Errorf("invalid --created-before %q: use YYYY-MM-DD or RFC 3339", createdBefore)
+			}
+		}
+		filter["createdBefore"] = t
+	}
+	if len(filter) == 0 {
+		return fmt.Errorf("at least one of --search, --disabled, --unverified or --created-before is required")
+	}
+
+	if !yes {
+		matched, err := bulkDeleteUsers(cmd, filter, true)
+		if err != nil {
+			return err
+		}
+		if matched == 0 {
+			fmt.Println("No users match.")
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Delete %d users? This cannot be undone. [y/N] ", matched)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.TrimSpace(strings.ToLower(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	deleted, err := bulkDeleteUsers(cmd, filter, false)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d users deleted.\n", deleted)
+	return nil
+}
+
+// bulkDeleteUsers posts filter to /api/admin/users/bulk-delete and returns
+// the number of users matched (dryRun) or deleted.
+func bulkDeleteUsers(cmd *cobra.Command, filter map[string]any, dryRun bool) (int, error) {
+	filter["dryRun"] = dryRun
+	reqBody, err := json.Marshal(filter)
+	if err != nil {
+		return 0, err
+	}
+	resp, body, err := adminRequest(cmd, "POST", "/api/admin/users/bulk-delete", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, serverError(resp.StatusCode, body)
+	}
+
+	var result struct {
+		Matched int `json:"matched"`
+		Deleted int `json:"deleted"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("parsing response: %w", err)
+	}
+	if dryRun {
+		return result.Matched, nil
+	}
+	return result.Deleted, nil
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func resetUsersBulkDeleteFlags() {
+	resetJSONFlag()
+	_ = usersBulkDeleteCmd.Flags().Set("search", "")
+	_ = usersBulkDeleteCmd.Flags().Set("disabled", "false")
+	_ = usersBulkDeleteCmd.Flags().Set("unverified", "false")
+	_ = usersBulkDeleteCmd.Flags().Set("created-before", "")
+	_ = usersBulkDeleteCmd.Flags().Set("yes", "false")
+}
+
+func TestUsersDisable(t *testing.T) {
+	resetJSONFlag()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/admin/users/u1/disable" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"users", "disable", "u1", "--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "User u1 disabled.") {
+		t.Fatalf("unexpected output %q", output)
+	}
+}
+
+func TestUsersExport(t *testing.T) {
+	resetJSONFlag()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/admin/users/u1/export" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"user":{"id":"u1","email":"a@example.com"},"apiKeys":[],"sessions":[]}`))
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"users", "export", "u1", "--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"email": "a@example.com"`) {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	file := filepath.Join(t.TempDir(), "export.json")
+	output = captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"users", "export", "u1", "--url", srv.URL, "--admin-token", "tok", "--file", file})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	_ = usersExportCmd.Flags().Set("file", "")
+	if !strings.Contains(output, "Exported user u1 to "+file) {
+		t.Fatalf("unexpected output %q", output)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export map[string]any
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("export file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestUsersLogout(t *testing.T) {
+	resetJSONFlag()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/admin/users/u1/logout" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"revokedSessions": 3})
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"users", "logout", "u1", "--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "3 sessions revoked") {
+		t.Fatalf("unexpected output %q", output)
+	}
+}
+
+func TestUsersBulkDeleteRequiresFilter(t *testing.T) {
+	resetUsersBulkDeleteFlags()
+	rootCmd.SetArgs([]string{"users", "bulk-delete", "--url", "http://127.0.0.1:1"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "at least one of") {
+		t.Fatalf("expected missing filter error, got %v", err)
+	}
+}
+
+func TestUsersBulkDeleteSendsFilter(t *testing.T) {
+	resetUsersBulkDeleteFlags()
+	defer resetUsersBulkDeleteFlags()
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/admin/users/bulk-delete" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(map[string]any{"matched": 4, "deleted": 4})
+	}))
+	defer srv.Close()
+
+	output := captureStdout(t, func() {
+		rootCmd.SetArgs([]string{"users", "bulk-delete", "--unverified", "--created-before", "2026-01-01",
+			"--yes", "--url", srv.URL, "--admin-token", "tok"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(output, "4 users deleted.") {
+		t.Fatalf("unexpected output %q", output)
+	}
+	if received["unverified"] != true || received["dryRun"] != false {
+		t.Fatalf("unexpected request body %v", received)
+	}
+	if received["createdBefore"] != "2026-01-01T00:00:00Z" {
+		t.Fatalf("unexpected createdBefore %v", received["createdBefore"])
+	}
+	if _, ok := received["search"]; ok {
+		t.Fatalf("unset filter sent: %v", received)
+	}
+}
+
+func TestUsersBulkDeleteInvalidDate(t *testing.T) {
+	resetUsersBulkDeleteFlags()
+	defer resetUsersBulkDeleteFlags()
+	rootCmd.SetArgs([]string{"users", "bulk-delete", "--created-before", "last week", "--url", "http://127.0.0.1:1"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --created-before") {
+		t.Fatalf("expected invalid date error, got %v", err)
+	}
+}
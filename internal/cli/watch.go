@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/migrations"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/fsnotify/fsnotify"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// watchDebounce is how long --watch waits after the last file event before
+// acting, so an editor saving a file in several steps triggers one reload.
+const watchDebounce = 300 * time.Millisecond
+
+// devWatcher implements `ayb start --watch`: it applies new migrations and
+// reloads the schema cache when .sql files appear in the migrations
+// directory, and re-reads ayb.toml when it changes.
+type devWatcher struct {
+	migrationsDir string
+	configPath    string
+	flags         map[string]string
+	cfg           *config.Config // config as last applied from disk
+
+	migrate      func(ctx context.Context) (int, error)
+	reloadSchema func(ctx context.Context) error
+	applyConfig  func(next *config.Config) []string // applies runtime keys to the server
+	logger       *slog.Logger
+}
+
+// startDevWatcher sets up --watch for a started server and runs it until
+// ctx is canceled.
+func startDevWatcher(ctx context.Context, cfg *config.Config, configPath string, flags map[string]string,
+	pool *pgxpool.Pool, schemaCache *schema.CacheHolder, srv *server.Server, logger *slog.Logger) error {
+	if hints := productionHints(cfg); len(hints) > 0 {
+		logger.Warn("--watch is meant for development, but this config looks like production",
+			"reasons", strings.Join(hints, "; "))
+	}
+	if configPath == "" {
+		configPath = "ayb.toml"
+	}
+	if cfg.Database.MigrationsDir == "" {
+		return fmt.Errorf("database.migrations_dir is not set")
+	}
+	if err := os.MkdirAll(cfg.Database.MigrationsDir, 0o755); err != nil {
+		return fmt.Errorf("creating migrations directory: %w", err)
+	}
+
+	runner := migrations.NewUserRunner(pool, cfg.Database.MigrationsDir, logger)
+	if err := runner.Bootstrap(ctx); err != nil {
+		return fmt.Errorf("bootstrapping user migrations: %w", err)
+	}
+	// The running config has generated values, such as the admin password,
+	// so changes are compared against the config as loaded from disk.
+	baseline, err := config.Load(configPath, flags)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	w := &devWatcher{
+		migrationsDir: cfg.Database.MigrationsDir,
+		configPath:    configPath,
+		flags:         flags,
+		cfg:           baseline,
+		migrate:       userMigrations(cfg, pool, cfg.Database.MigrationsDir, logger),
+		reloadSchema:  schemaCache.ReloadWait,
+		applyConfig:   srv.ApplyRuntimeConfig,
+		logger:        logger,
+	}
+	fw, err := w.newFileWatcher()
+	if err != nil {
+		return err
+	}
+	go w.run(ctx, fw)
+	return nil
+}
+
+// newFileWatcher watches the migrations directory and the directory holding
+// the config file. Watching the directory rather than the file catches
+// editors that save by renaming a new file over the old one.
+func (w *devWatcher) newFileWatcher() (*fsnotify.Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	for _, dir := range []string{w.migrationsDir, filepath.Dir(w.configPath)} {
+		if err := fw.Add(dir); err != nil {
+			fw.Close()
+			return nil, fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+	w.logger.Info("watching for changes", "migrations_dir", w.migrationsDir, "config", w.configPath)
+	return fw, nil
+}
+
+// run handles file events until ctx is canceled, then closes fw.
+func (w *devWatcher) run(ctx context.Context, fw *fsnotify.Watcher) {
+	defer fw.Close()
+
+	var migrateAfter, configAfter <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if w.isMigrationEvent(ev) {
+				migrateAfter = time.After(watchDebounce)
+			}
+			if w.isConfigEvent(ev) {
+				configAfter = time.After(watchDebounce)
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("file watcher error", "error", err)
+		case <-migrateAfter:
+			migrateAfter = nil
+			w.applyMigrations(ctx)
+		case <-configAfter:
+			configAfter = nil
+			w.reloadConfig()
+		}
+	}
+}
+
+// isMigrationEvent reports whether ev adds or changes a .sql file in the
+// migrations directory.
+func (w *devWatcher) isMigrationEvent(ev fsnotify.Event) bool {
+	return ev.Has(fsnotify.Create|fsnotify.Write) &&
+		filepath.Ext(ev.Name) == ".sql" &&
+		filepath.Clean(filepath.Dir(ev.Name)) == filepath.Clean(w.migrationsDir)
+}
+
+// isConfigEvent reports whether ev creates or changes the config file.
+func (w *devWatcher) isConfigEvent(ev fsnotify.Event) bool {
+	return ev.Has(fsnotify.Create|fsnotify.Write) &&
+		filepath.Clean(ev.Name) == filepath.Clean(w.configPath)
+}
+
+// applyMigrations applies pending migrations and, if any were applied,
+// reloads the schema cache.
+func (w *devWatcher) applyMigrations(ctx context.Context) {
+	applied, err := w.migrate(ctx)
+	if err != nil {
+		w.logger.Error("applying migrations failed", "error", err)
+	}
+	if applied == 0 {
+		return
+	}
+	w.logger.Info("applied user migrations", "count", applied)
+	if err := w.reloadSchema(ctx); err != nil {
+		w.logger.Error("schema reload failed", "error", err)
+		return
+	}
+	w.logger.Info("schema reloaded")
+}
+
+// reloadConfig re-reads the config file and applies the runtime keys (see
+// config.IsRuntimeKey) that changed. An invalid file leaves the running
+// config unchanged.
+func (w *devWatcher) reloadConfig() {
+	next, err := config.Load(w.configPath, w.flags)
+	if err != nil {
+		w.logger.Error("config reload failed, keeping current config", "path", w.configPath, "error", err)
+		return
+	}
+
+	var restart []string
+	for _, key := range config.ChangedKeys(w.cfg, next) {
+		if !config.IsRuntimeKey(key) {
+			restart = append(restart, key)
+			continue
+		}
+		config.CopyRuntimeKey(w.cfg, next, key)
+	}
+	// Changes made through the admin API are already running, so the
+	// server reports what it applied rather than the diff against disk.
+	applied := w.applyConfig(next)
+	if len(applied) > 0 {
+		w.logger.Info("config reloaded", "path", w.configPath, "applied", strings.Join(applied, ", "))
+	}
+	if len(restart) > 0 {
+		w.logger.Warn("config changes require a restart", "keys", strings.Join(restart, ", "))
+	}
+}
+
+// productionHints returns the reasons cfg looks like a production
+// deployment rather than local development.
+func productionHints(cfg *config.Config) []string {
+	var hints []string
+	if cfg.Server.TLSEnabled {
+		hints = append(hints, "TLS is enabled")
+	}
+	if u, err := url.Parse(cfg.Server.SiteURL); cfg.Server.SiteURL != "" && err == nil && !isLocalHost(u.Hostname()) {
+		hints = append(hints, "server.site_url is not localhost")
+	}
+	if u, err := url.Parse(cfg.Database.URL); cfg.Database.URL != "" && err == nil && !isLocalHost(u.Hostname()) {
+		hints = append(hints, "the database is not on localhost")
+	}
+	return hints
+}
+
+// isLocalHost reports whether host names this machine. An empty host, as in
+// a Unix socket database URL, counts as local.
+func isLocalHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/spf13/cobra"
 )
 
@@ -45,6 +46,9 @@ func init() {
 	webhooksCreateCmd.Flags().String("tables", "", "Comma-separated table filter (default all tables)")
 	webhooksCreateCmd.Flags().String("secret", "", "HMAC-SHA256 signing secret")
 	webhooksCreateCmd.Flags().Bool("disabled", false, "Create in disabled state")
+	webhooksCreateCmd.Flags().String("include-columns", "", "Comma-separated columns to send (default all)")
+	webhooksCreateCmd.Flags().String("exclude-columns", "", "Comma-separated columns to leave out of the payload")
+	webhooksCreateCmd.Flags().String("filter", "", "Only fire for rows matching this collection filter, e.g. \"status='published'\" (requires --tables)")
 
 	webhooksCmd.AddCommand(webhooksListCmd)
 	webhooksCmd.AddCommand(webhooksCreateCmd)
@@ -67,6 +71,9 @@ func runWebhooksList(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		return nil
 	}
+	if isRecordFormat(outFmt) {
+		return writeListRecords(os.Stdout, outFmt, body)
+	}
 
 	var hooks []struct {
 		ID        string   `json:"id"`
@@ -123,6 +130,9 @@ func runWebhooksCreate(cmd *cobra.Command, args []string) error {
 	tables, _ := cmd.Flags().GetString("tables")
 	secret, _ := cmd.Flags().GetString("secret")
 	disabled, _ := cmd.Flags().GetBool("disabled")
+	includeColumns, _ := cmd.Flags().GetString("include-columns")
+	excludeColumns, _ := cmd.Flags().GetString("exclude-columns")
+	filter, _ := cmd.Flags().GetString("filter")
 
 	if whURL == "" {
 		return fmt.Errorf("--webhook-url is required")
@@ -141,6 +151,15 @@ func runWebhooksCreate(cmd *cobra.Command, args []string) error {
 	if tables != "" {
 		payload["tables"] = strings.Split(tables, ",")
 	}
+	if includeColumns != "" {
+		payload["includeColumns"] = strings.Split(includeColumns, ",")
+	}
+	if excludeColumns != "" {
+		payload["excludeColumns"] = strings.Split(excludeColumns, ",")
+	}
+	if filter != "" {
+		payload["filter"] = filter
+	}
 
 	body, _ := json.Marshal(payload)
 	resp, respBody, err := adminRequest(cmd, "POST", "/api/webhooks", bytes.NewReader(body))
@@ -193,11 +212,8 @@ func serverError(status int, body []byte) error {
 			"  Or reset the admin password:\n" +
 			"    ayb admin reset-password")
 	}
-	var errResp map[string]any
-	if json.Unmarshal(body, &errResp) == nil {
-		if msg, ok := errResp["message"].(string); ok {
-			return fmt.Errorf("server error (%d): %s", status, msg)
-		}
+	if msg, ok := httputil.ErrorMessage(body); ok {
+		return fmt.Errorf("server error (%d): %s", status, msg)
 	}
 	return fmt.Errorf("server error (%d): %s", status, string(body))
 }
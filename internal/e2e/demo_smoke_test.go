@@ -68,8 +68,8 @@ func TestDemoSmoke_SchemaApply(t *testing.T) {
 				map[string]string{"query": string(schemaSQL)}, token)
 			testutil.StatusCode(t, http.StatusOK, resp.StatusCode)
 
-			if msg, ok := body["message"].(string); ok {
-				t.Fatalf("schema apply returned error: %s", msg)
+			if e, ok := body["error"].(map[string]any); ok {
+				t.Fatalf("schema apply returned error: %v", e["message"])
 			}
 		})
 	}
@@ -92,7 +92,8 @@ func TestDemoSmoke_SchemaIdempotent(t *testing.T) {
 			resp2, body2 := httpJSON(t, "POST", ts.URL+"/api/admin/sql/",
 				map[string]string{"query": string(schemaSQL)}, token)
 			if resp2.StatusCode != http.StatusOK {
-				msg, _ := body2["message"].(string)
+				e, _ := body2["error"].(map[string]any)
+				msg, _ := e["message"].(string)
 				if !strings.Contains(msg, "already exists") {
 					t.Fatalf("second schema apply: expected 200 or 'already exists' error, got %d: %s",
 						resp2.StatusCode, msg)
@@ -309,7 +310,7 @@ func smokeTestLivePolls(t *testing.T, baseURL, adminToken, userID string) {
 			pollID, optionID, userID)},
 		adminToken)
 	testutil.StatusCode(t, http.StatusBadRequest, resp2.StatusCode)
-	msg := body2["message"].(string)
+	msg := errorBody(t, body2)["message"].(string)
 	testutil.Contains(t, msg, "duplicate key")
 
 	resp, body = httpJSON(t, "POST", baseURL+"/api/admin/sql/",
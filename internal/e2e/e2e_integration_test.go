@@ -180,6 +180,16 @@ func httpJSON(t *testing.T, method, url string, body any, token string) (*http.R
 	return resp, result
 }
 
+// errorBody returns the error object of an {"error": {...}} response body.
+func errorBody(t *testing.T, body map[string]any) map[string]any {
+	t.Helper()
+	e, ok := body["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected error envelope, got %v", body)
+	}
+	return e
+}
+
 func httpJSONArray(t *testing.T, method, url string, body any, token string) (*http.Response, []any) {
 	t.Helper()
 	var reqBody io.Reader
@@ -343,7 +353,32 @@ func TestE2E_AdminAuth(t *testing.T) {
 		resp, body := httpJSON(t, "POST", ts.URL+"/api/admin/sql/",
 			map[string]string{"query": "SELECT * FROM nonexistent_table"}, token)
 		testutil.StatusCode(t, http.StatusBadRequest, resp.StatusCode)
-		testutil.True(t, len(body["message"].(string)) > 0, "should have error message")
+		testutil.True(t, len(errorBody(t, body)["message"].(string)) > 0, "should have error message")
+	})
+
+	t.Run("read-only SQL rejects writes", func(t *testing.T) {
+		token := adminToken(t, ts.URL)
+		resp, body := httpJSON(t, "POST", ts.URL+"/api/admin/sql/",
+			map[string]any{"query": "DELETE FROM authors", "readOnly": true}, token)
+		testutil.StatusCode(t, http.StatusBadRequest, resp.StatusCode)
+		testutil.Contains(t, errorBody(t, body)["message"].(string), "read-only transaction")
+
+		// A second statement can't end the transaction or make it
+		// read-write before writing.
+		for _, query := range []string{
+			"COMMIT; DROP TABLE authors",
+			"SET TRANSACTION READ WRITE; DELETE FROM authors",
+		} {
+			resp, body = httpJSON(t, "POST", ts.URL+"/api/admin/sql/",
+				map[string]any{"query": query, "readOnly": true}, token)
+			testutil.StatusCode(t, http.StatusBadRequest, resp.StatusCode)
+			testutil.Contains(t, errorBody(t, body)["message"].(string), "multiple commands")
+		}
+
+		resp, body = httpJSON(t, "POST", ts.URL+"/api/admin/sql/",
+			map[string]any{"query": "SELECT count(*) AS n FROM authors", "readOnly": true}, token)
+		testutil.StatusCode(t, http.StatusOK, resp.StatusCode)
+		testutil.Equal(t, float64(3), body["rows"].([]any)[0].([]any)[0].(float64))
 	})
 }
 
@@ -400,7 +435,7 @@ func TestE2E_CRUDLifecycle(t *testing.T) {
 	t.Run("404 nonexistent record", func(t *testing.T) {
 		resp, body := httpJSON(t, "GET", ts.URL+"/api/collections/authors/99999", nil, "")
 		testutil.StatusCode(t, http.StatusNotFound, resp.StatusCode)
-		testutil.Equal(t, float64(404), body["code"].(float64))
+		testutil.Equal(t, "not_found", errorBody(t, body)["code"])
 	})
 
 	t.Run("404 nonexistent table", func(t *testing.T) {
@@ -414,14 +449,14 @@ func TestE2E_CRUDLifecycle(t *testing.T) {
 		// Missing NOT NULL column returns 400 (bad request) or 422 depending on driver.
 		testutil.True(t, resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity,
 			"expected 400 or 422 for NOT NULL violation")
-		testutil.NotNil(t, body["code"])
+		testutil.Equal(t, "validation_failed", errorBody(t, body)["code"])
 	})
 
 	t.Run("UNIQUE violation 409", func(t *testing.T) {
 		resp, body := httpJSON(t, "POST", ts.URL+"/api/collections/tags",
 			map[string]any{"name": "go"}, "")
 		testutil.StatusCode(t, http.StatusConflict, resp.StatusCode)
-		testutil.Equal(t, float64(409), body["code"].(float64))
+		testutil.Equal(t, "conflict", errorBody(t, body)["code"])
 	})
 }
 
@@ -503,9 +538,9 @@ func TestE2E_QueryFeatures(t *testing.T) {
 		resp, body := httpJSON(t, "GET",
 			ts.URL+"/api/collections/posts?filter="+url.QueryEscape("((broken"), nil, "")
 		testutil.StatusCode(t, http.StatusBadRequest, resp.StatusCode)
-		code, ok := body["code"].(float64)
-		testutil.True(t, ok, "response should have numeric code field")
-		testutil.Equal(t, float64(400), code)
+		code, ok := errorBody(t, body)["code"].(string)
+		testutil.True(t, ok, "response should have string code field")
+		testutil.Equal(t, "bad_request", code)
 	})
 }
 
@@ -654,7 +689,7 @@ func TestE2E_AuthLifecycle(t *testing.T) {
 		resp, body := httpJSON(t, "POST", ts.URL+"/api/auth/register",
 			map[string]string{"email": "test@example.com", "password": "securepass123"}, "")
 		testutil.StatusCode(t, http.StatusConflict, resp.StatusCode)
-		testutil.Equal(t, float64(409), body["code"].(float64))
+		testutil.Equal(t, "conflict", errorBody(t, body)["code"])
 	})
 
 	t.Run("get me", func(t *testing.T) {
@@ -718,7 +753,7 @@ func TestE2E_AuthProtectedCRUD(t *testing.T) {
 	t.Run("requires auth", func(t *testing.T) {
 		resp, body := httpJSON(t, "GET", ts.URL+"/api/collections/authors", nil, "")
 		testutil.StatusCode(t, http.StatusUnauthorized, resp.StatusCode)
-		testutil.Equal(t, float64(401), body["code"].(float64))
+		testutil.Equal(t, "unauthorized", errorBody(t, body)["code"])
 	})
 
 	t.Run("works with token", func(t *testing.T) {
@@ -1180,7 +1215,7 @@ func TestE2E_ErrorHandling(t *testing.T) {
 		resp, body := httpJSON(t, "PATCH", ts.URL+"/api/collections/authors/99999",
 			map[string]any{"name": "ghost"}, "")
 		testutil.StatusCode(t, http.StatusNotFound, resp.StatusCode)
-		testutil.Equal(t, float64(404), body["code"].(float64))
+		testutil.Equal(t, "not_found", errorBody(t, body)["code"])
 	})
 
 	t.Run("DELETE nonexistent", func(t *testing.T) {
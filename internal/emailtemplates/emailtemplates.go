@@ -88,7 +88,7 @@ type BuiltinTemplate struct {
 // the email template service.
 func DefaultBuiltins() map[string]BuiltinTemplate {
 	systemVars := []string{"AppName", "ActionURL"}
-	builtins := make(map[string]BuiltinTemplate, 3)
+	builtins := make(map[string]BuiltinTemplate, 5)
 
 	keys := []struct {
 		key     string
@@ -98,6 +98,8 @@ func DefaultBuiltins() map[string]BuiltinTemplate {
 		{"auth.password_reset", mailer.DefaultPasswordResetSubject, "password_reset.html"},
 		{"auth.email_verification", mailer.DefaultVerificationSubject, "verification.html"},
 		{"auth.magic_link", mailer.DefaultMagicLinkSubject, "magic_link.html"},
+		{"auth.email_change", mailer.DefaultEmailChangeSubject, "email_change.html"},
+		{"auth.registration_attempt", mailer.DefaultRegistrationAttemptSubject, "registration_attempt.html"},
 	}
 	for _, k := range keys {
 		html, err := mailer.BuiltinHTMLTemplate(k.file)
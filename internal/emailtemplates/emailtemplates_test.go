@@ -427,8 +427,8 @@ func TestDefaultBuiltins(t *testing.T) {
 
 	builtins := DefaultBuiltins()
 
-	// Must have all three system template keys.
-	expectedKeys := []string{"auth.password_reset", "auth.email_verification", "auth.magic_link"}
+	// Must have all five system template keys.
+	expectedKeys := []string{"auth.password_reset", "auth.email_verification", "auth.magic_link", "auth.email_change", "auth.registration_attempt"}
 	for _, key := range expectedKeys {
 		b, ok := builtins[key]
 		testutil.True(t, ok, "DefaultBuiltins should contain %q", key)
@@ -445,6 +445,8 @@ func TestDefaultBuiltins(t *testing.T) {
 	testutil.Equal(t, "Reset your password", builtins["auth.password_reset"].SubjectTemplate)
 	testutil.Equal(t, "Verify your email", builtins["auth.email_verification"].SubjectTemplate)
 	testutil.Equal(t, "Your login link", builtins["auth.magic_link"].SubjectTemplate)
+	testutil.Equal(t, "Confirm your new email", builtins["auth.email_change"].SubjectTemplate)
+	testutil.Equal(t, "Someone tried to sign up with your email", builtins["auth.registration_attempt"].SubjectTemplate)
 
 	// Templates should be parseable.
 	for key, b := range builtins {
@@ -0,0 +1,39 @@
+package httputil
+
+import (
+	"context"
+	"sync"
+)
+
+type accessLogKey struct{}
+
+// AccessLogEntry collects request details that are only known to inner
+// handlers, such as the authenticated subject, for the access log line
+// written when the request completes.
+type AccessLogEntry struct {
+	mu      sync.Mutex
+	subject string
+}
+
+// WithAccessLogEntry returns ctx carrying a new, empty entry.
+func WithAccessLogEntry(ctx context.Context) (context.Context, *AccessLogEntry) {
+	e := &AccessLogEntry{}
+	return context.WithValue(ctx, accessLogKey{}, e), e
+}
+
+// Subject returns the subject recorded by SetAccessLogSubject.
+func (e *AccessLogEntry) Subject() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.subject
+}
+
+// SetAccessLogSubject records the authenticated subject of the request for
+// the access log. It does nothing if ctx has no entry.
+func SetAccessLogSubject(ctx context.Context, subject string) {
+	if e, ok := ctx.Value(accessLogKey{}).(*AccessLogEntry); ok {
+		e.mu.Lock()
+		e.subject = subject
+		e.mu.Unlock()
+	}
+}
@@ -0,0 +1,21 @@
+package httputil
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAccessLogSubject(t *testing.T) {
+	t.Parallel()
+	ctx, entry := WithAccessLogEntry(context.Background())
+	if got := entry.Subject(); got != "" {
+		t.Fatalf("new entry subject = %q, want empty", got)
+	}
+	// Inner handlers record the subject on a derived context.
+	SetAccessLogSubject(context.WithValue(ctx, struct{}{}, 1), "user-1")
+	if got := entry.Subject(); got != "user-1" {
+		t.Fatalf("Subject() = %q, want user-1", got)
+	}
+	// Without an entry it's a no-op.
+	SetAccessLogSubject(context.Background(), "user-2")
+}
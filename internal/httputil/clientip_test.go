@@ -0,0 +1,121 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func mustParseCIDRs(t *testing.T, entries ...string) []netip.Prefix {
+	t.Helper()
+	p, err := ParseCIDRs(entries)
+	if err != nil {
+		t.Fatalf("ParseCIDRs: %v", err)
+	}
+	return p
+}
+
+func TestParseCIDRs(t *testing.T) {
+	t.Parallel()
+	p := mustParseCIDRs(t, "10.0.0.0/8", " 203.0.113.7 ", "::1", "192.168.1.77/24")
+	if len(p) != 4 {
+		t.Fatalf("expected 4 prefixes, got %d", len(p))
+	}
+	if got := p[1].String(); got != "203.0.113.7/32" {
+		t.Fatalf("bare IPv4 should become /32, got %s", got)
+	}
+	if got := p[2].String(); got != "::1/128" {
+		t.Fatalf("bare IPv6 should become /128, got %s", got)
+	}
+	if got := p[3].String(); got != "192.168.1.0/24" {
+		t.Fatalf("prefix should be masked, got %s", got)
+	}
+
+	for _, bad := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if _, err := ParseCIDRs([]string{bad}); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestContainsIP(t *testing.T) {
+	t.Parallel()
+	p := mustParseCIDRs(t, "10.0.0.0/8", "2001:db8::/32")
+	cases := map[string]bool{
+		"10.1.2.3":        true,
+		"::ffff:10.1.2.3": true,
+		"2001:db8::1":     true,
+		"11.0.0.1":        false,
+		"garbage":         false,
+		"2001:db9::1":     false,
+	}
+	for ip, want := range cases {
+		if got := ContainsIP(p, ip); got != want {
+			t.Errorf("ContainsIP(%q) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+	trusted := mustParseCIDRs(t, "127.0.0.0/8", "10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        string
+		xRealIP    string
+		want       string
+	}{
+		{"no headers", "203.0.113.1:1234", "", "", "203.0.113.1"},
+		{"remote addr without port", "203.0.113.1", "", "", "203.0.113.1"},
+		{"XFF from trusted proxy", "127.0.0.1:1234", "203.0.113.50", "", "203.0.113.50"},
+		{"XFF trims whitespace", "10.0.0.1:1234", "  203.0.113.50 ", "", "203.0.113.50"},
+		{"X-Real-IP from trusted proxy", "127.0.0.1:1234", "", "198.51.100.1", "198.51.100.1"},
+		{"spoofed XFF from untrusted source is ignored", "203.0.113.1:1234", "10.0.0.99", "", "203.0.113.1"},
+		{"spoofed X-Real-IP from untrusted source is ignored", "198.51.100.5:1234", "", "10.0.0.99", "198.51.100.5"},
+		{"client-supplied XFF prefix is skipped", "10.0.0.1:1234", "1.2.3.4, 203.0.113.50", "", "203.0.113.50"},
+		{"trusted hops are skipped", "10.0.0.1:1234", "203.0.113.50, 10.0.0.2, 10.0.0.3", "", "203.0.113.50"},
+		{"all hops trusted returns leftmost", "10.0.0.1:1234", "10.0.0.5, 10.0.0.2", "", "10.0.0.5"},
+		{"malformed hop stops the walk", "10.0.0.1:1234", "203.0.113.50, bogus, 10.0.0.2", "", "10.0.0.2"},
+		{"malformed X-Real-IP falls back to peer", "127.0.0.1:1234", "", "bogus", "127.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+			if got := ClientIP(req, trusted); got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPMultipleXFFHeaders(t *testing.T) {
+	t.Parallel()
+	trusted := mustParseCIDRs(t, "10.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Add("X-Forwarded-For", "1.2.3.4")
+	req.Header.Add("X-Forwarded-For", "203.0.113.50")
+	if got := ClientIP(req, trusted); got != "203.0.113.50" {
+		t.Fatalf("ClientIP() = %q, want 203.0.113.50", got)
+	}
+}
+
+func TestClientIPNoTrustedProxies(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.50")
+	if got := ClientIP(req, nil); got != "127.0.0.1" {
+		t.Fatalf("ClientIP() = %q, want 127.0.0.1", got)
+	}
+}
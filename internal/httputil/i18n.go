@@ -0,0 +1,144 @@
+package httputil
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed error_messages_en.json
+var defaultErrorMessagesJSON []byte
+
+// defaultErrorMessages is the shipped English message for each error code.
+var defaultErrorMessages = mustParseErrorMessages(defaultErrorMessagesJSON)
+
+func mustParseErrorMessages(data []byte) map[string]string {
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic("httputil: parsing default error messages: " + err.Error())
+	}
+	return m
+}
+
+// DefaultErrorMessage returns the English message for code, or "" for an
+// unknown code.
+func DefaultErrorMessage(code string) string {
+	return defaultErrorMessages[code]
+}
+
+// ErrorTranslations holds localized error messages, keyed by lowercase
+// language tag and then error code. A translation replaces a message only
+// when it is the code's default English message (see DefaultErrorMessage),
+// so specific messages such as "collection not found: posts" are kept.
+type ErrorTranslations map[string]map[string]string
+
+// LoadErrorTranslations reads translations from path, which is either a
+// directory of per-language files such as es.json, each of the form
+// {"not_found": "no encontrado"}, or a single JSON file keyed by language
+// and then code, {"es": {"not_found": "no encontrado"}}. A path that does
+// not exist has no translations, like a missing migrations directory. An
+// "en" file rewords the English defaults.
+func LoadErrorTranslations(path string) (ErrorTranslations, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return ErrorTranslations{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]map[string]string{}
+	if info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			var messages map[string]string
+			if err := readJSONFile(file, &messages); err != nil {
+				return nil, err
+			}
+			raw[strings.TrimSuffix(filepath.Base(file), ".json")] = messages
+		}
+	} else if err := readJSONFile(path, &raw); err != nil {
+		return nil, err
+	}
+
+	t := make(ErrorTranslations, len(raw))
+	for lang, messages := range raw {
+		tag := strings.ToLower(strings.TrimSpace(lang))
+		if tag == "" {
+			return nil, fmt.Errorf("parsing %s: empty language tag", path)
+		}
+		for code, msg := range messages {
+			if msg == "" {
+				return nil, fmt.Errorf("parsing %s: empty %s message for %q", path, tag, code)
+			}
+		}
+		t[tag] = messages
+	}
+	return t, nil
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Negotiate returns the language of t that best matches an Accept-Language
+// header, trying each tag and then its base language ("es-MX", then "es") in
+// order of preference. It returns "" when the client prefers English (and t
+// doesn't reword it) or no translated language is acceptable.
+func (t ErrorTranslations) Negotiate(header string) string {
+	if len(t) == 0 || header == "" {
+		return ""
+	}
+	type pref struct {
+		tag string
+		q   float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && q > 0 {
+			prefs = append(prefs, pref{tag, q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+	for _, p := range prefs {
+		base, _, _ := strings.Cut(p.tag, "-")
+		for _, tag := range []string{p.tag, base} {
+			if _, ok := t[tag]; ok {
+				return tag
+			}
+		}
+		if base == "en" || base == "*" {
+			return ""
+		}
+	}
+	return ""
+}
+
+// Translate returns the lang message for an error with code and message,
+// if there is one. Only the code's default English message is translated.
+func (t ErrorTranslations) Translate(lang, code, message string) (string, bool) {
+	if def, ok := defaultErrorMessages[code]; !ok || message != def {
+		return "", false
+	}
+	msg, ok := t[lang][code]
+	return msg, ok
+}
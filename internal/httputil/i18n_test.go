@@ -0,0 +1,162 @@
+package httputil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTranslations(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "translations.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadErrorTranslations(t *testing.T) {
+	t.Parallel()
+	path := writeTranslations(t, `{"ES": {"not_found": "no encontrado"}, "pt-br": {"unauthorized": "não autorizado"}}`)
+	tr, err := LoadErrorTranslations(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg, ok := tr.Translate("es", CodeNotFound, "not found"); !ok || msg != "no encontrado" {
+		t.Fatalf("expected es not_found translation, got %q, %v", msg, ok)
+	}
+	if _, ok := tr.Translate("es", CodeUnauthorized, DefaultErrorMessage(CodeUnauthorized)); ok {
+		t.Fatal("expected no es unauthorized translation")
+	}
+	if msg, _ := tr.Translate("pt-br", CodeUnauthorized, DefaultErrorMessage(CodeUnauthorized)); msg != "não autorizado" {
+		t.Fatalf("expected pt-br unauthorized translation, got %q", msg)
+	}
+}
+
+func TestLoadErrorTranslationsDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"es.json":    `{"not_found": "no encontrado"}`,
+		"EN.json":    `{"not_found": "nothing here"}`,
+		"README.txt": `not a translation`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr, err := LoadErrorTranslations(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tr) != 2 {
+		t.Fatalf("expected 2 languages, got %v", tr)
+	}
+	if msg, _ := tr.Translate("es", CodeNotFound, "not found"); msg != "no encontrado" {
+		t.Fatalf("expected es not_found translation, got %q", msg)
+	}
+	// An en file rewords the English defaults.
+	if tr.Negotiate("en-US") != "en" {
+		t.Fatal("expected en to be negotiated")
+	}
+	if msg, _ := tr.Translate("en", CodeNotFound, "not found"); msg != "nothing here" {
+		t.Fatalf("expected en not_found override, got %q", msg)
+	}
+
+	bad := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bad, "es.json"), []byte(`{"es": {"not_found": "x"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadErrorTranslations(bad); err == nil {
+		t.Fatal("expected an error for a language file keyed by language")
+	}
+}
+
+func TestLoadErrorTranslationsMissing(t *testing.T) {
+	t.Parallel()
+	tr, err := LoadErrorTranslations(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tr) != 0 {
+		t.Fatalf("expected no translations, got %v", tr)
+	}
+}
+
+func TestDefaultErrorMessages(t *testing.T) {
+	t.Parallel()
+	for _, code := range []string{
+		CodeBadRequest, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeMethodNotAllowed,
+		CodeConflict, CodePayloadTooLarge, CodeValidationFailed, CodeRateLimited, CodeInternal,
+		CodeBadGateway, CodeUnavailable, CodeTimeout, CodeMaintenance,
+	} {
+		if DefaultErrorMessage(code) == "" {
+			t.Errorf("no default message for %s", code)
+		}
+	}
+}
+
+func TestTranslateKeepsSpecificMessages(t *testing.T) {
+	t.Parallel()
+	tr := ErrorTranslations{"es": {CodeNotFound: "no encontrado", "custom": "personalizado"}}
+	if _, ok := tr.Translate("es", CodeNotFound, "collection not found: posts"); ok {
+		t.Fatal("a specific message should not be replaced")
+	}
+	if _, ok := tr.Translate("es", "custom", "custom"); ok {
+		t.Fatal("a code without a default message should not be translated")
+	}
+	if _, ok := tr.Translate("fr", CodeNotFound, "not found"); ok {
+		t.Fatal("expected no fr translation")
+	}
+}
+
+func TestLoadErrorTranslationsErrors(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"invalid json":  `{"es": [}`,
+		"wrong shape":   `{"es": "no encontrado"}`,
+		"empty tag":     `{" ": {"not_found": "no encontrado"}}`,
+		"empty message": `{"es": {"not_found": ""}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := LoadErrorTranslations(writeTranslations(t, content)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestErrorTranslationsNegotiate(t *testing.T) {
+	t.Parallel()
+	tr := ErrorTranslations{
+		"es":    {CodeNotFound: "no encontrado"},
+		"pt-br": {CodeNotFound: "não encontrado"},
+		"fr":    {CodeNotFound: "introuvable"},
+	}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"es", "es"},
+		{"ES-mx", "es"},
+		{"pt-BR", "pt-br"},
+		{"pt", ""},
+		{"de, fr;q=0.8", "fr"},
+		{"en-US, es;q=0.9", ""},
+		{"es;q=0.5, fr;q=0.9", "fr"},
+		{"fr;q=0, es;q=0.1", "es"},
+		{"*", ""},
+		{"de", ""},
+	}
+	for _, tt := range tests {
+		if got := tr.Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+	if got := ErrorTranslations(nil).Negotiate("es"); got != "" {
+		t.Errorf("nil translations negotiated %q", got)
+	}
+}
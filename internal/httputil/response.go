@@ -1,23 +1,84 @@
 package httputil
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"regexp"
 	"strings"
 )
 
-// MaxBodySize is the maximum allowed request body size (1MB).
+// MaxBodySize is the default maximum request body size (1MB). The server
+// applies server.body_limit to each request with WithBodyLimit.
 const MaxBodySize = 1 << 20
 
+type bodyLimitKey struct{}
+
+// WithBodyLimit returns a context in which request bodies are limited to n
+// bytes instead of MaxBodySize.
+func WithBodyLimit(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, bodyLimitKey{}, n)
+}
+
+// BodyLimit returns the maximum body size for r: the limit set with
+// WithBodyLimit, or MaxBodySize.
+func BodyLimit(r *http.Request) int64 {
+	if n, ok := r.Context().Value(bodyLimitKey{}).(int64); ok && n > 0 {
+		return n
+	}
+	return MaxBodySize
+}
+
+// LimitBody caps r's body at BodyLimit(r). See LimitBodyTo.
+func LimitBody(w http.ResponseWriter, r *http.Request) bool {
+	return LimitBodyTo(w, r, BodyLimit(r))
+}
+
+// LimitBodyTo caps r's body at n bytes, so reading past them fails with an
+// *http.MaxBytesError. A body whose Content-Length already exceeds n is
+// rejected with 413 without being read, and LimitBodyTo returns false.
+func LimitBodyTo(w http.ResponseWriter, r *http.Request, n int64) bool {
+	if r.ContentLength > n {
+		WriteBodyTooLarge(w)
+		return false
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, n)
+	return true
+}
+
+// IsBodyTooLarge reports whether err comes from reading past a body limit.
+func IsBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// WriteBodyTooLarge writes the 413 response for a body over its limit.
+func WriteBodyTooLarge(w http.ResponseWriter) {
+	WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+}
+
+// WriteDecodeError writes the response for a JSON body that failed to
+// decode: 413 if it was over the body limit, otherwise 400.
+func WriteDecodeError(w http.ResponseWriter, err error) {
+	if IsBodyTooLarge(err) {
+		WriteBodyTooLarge(w)
+		return
+	}
+	WriteError(w, http.StatusBadRequest, "invalid JSON body")
+}
+
 const baseDocURL = "https://allyourbase.io"
 
 // DecodeJSON reads and decodes a JSON request body with size limiting.
-// Writes a 400 error and returns false on failure.
+// Writes a 413 error for a body over the limit, or a 400 error for one that
+// doesn't decode, and returns false on failure.
 func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
-	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+	if !LimitBody(w, r) {
+		return false
+	}
 	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
-		WriteError(w, http.StatusBadRequest, "invalid JSON body")
+		WriteDecodeError(w, err)
 		return false
 	}
 	return true
@@ -26,25 +87,90 @@ func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
 // ExtractBearerToken extracts a Bearer token from the Authorization header.
 // Returns the token and true if found, or empty string and false otherwise.
 func ExtractBearerToken(r *http.Request) (string, bool) {
-	header := r.Header.Get("Authorization")
-	if header == "" || !strings.HasPrefix(header, "Bearer ") {
-		return "", false
-	}
-	token := header[7:]
-	if token == "" {
+	return ParseBearerToken(r.Header.Get("Authorization"))
+}
+
+// ParseBearerToken extracts the token from an Authorization header value of
+// the Bearer scheme.
+func ParseBearerToken(header string) (string, bool) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
 		return "", false
 	}
 	return token, true
 }
 
-// ErrorResponse is the standard error envelope for all AYB API errors.
+// Error codes identify the kind of error in an ErrorResponse, so clients can
+// branch on the code rather than the message. Codes are stable; messages are
+// not.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeValidationFailed = "validation_failed"
+	CodeRateLimited      = "rate_limited"
+	CodeInternal         = "internal_error"
+	CodeBadGateway       = "bad_gateway"
+	CodeUnavailable      = "unavailable"
+	CodeTimeout          = "timeout"
+	CodeMaintenance      = "maintenance"
+)
+
+// statusCodes maps HTTP statuses to their default error code.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeValidationFailed,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// CodeForStatus returns the default error code for an HTTP status: its own
+// code if it has one, otherwise bad_request for 4xx and internal_error for
+// the rest.
+func CodeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 400 && status < 500 {
+		return CodeBadRequest
+	}
+	return CodeInternal
+}
+
+// ErrorResponse is the standard envelope for all AYB API errors:
+// {"error": {"code": ..., "message": ..., "details": ...}}.
 type ErrorResponse struct {
-	Code    int            `json:"code"`
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an API error. Details holds per-field detail for
+// validation errors, keyed by field or constraint name.
+type ErrorBody struct {
+	Code    string         `json:"code"`
 	Message string         `json:"message"`
-	Data    map[string]any `json:"data,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
 	DocURL  string         `json:"doc_url,omitempty"`
 }
 
+// FieldError is the detail for one field of a validation error.
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 // WriteJSON writes a JSON response with the given status code.
 func WriteJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -52,37 +178,60 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-// WriteError writes a standard error response.
+// WriteErrorBody writes body as an error response with status.
+func WriteErrorBody(w http.ResponseWriter, status int, body ErrorBody) {
+	WriteJSON(w, status, ErrorResponse{Error: body})
+}
+
+// WriteError writes a standard error response with the status's default code.
 func WriteError(w http.ResponseWriter, status int, message string) {
-	WriteJSON(w, status, ErrorResponse{
-		Code:    status,
-		Message: message,
-	})
+	WriteErrorBody(w, status, ErrorBody{Code: CodeForStatus(status), Message: message})
 }
 
 // WriteErrorWithDocURL writes an error response with a documentation URL.
 func WriteErrorWithDocURL(w http.ResponseWriter, status int, message, docURL string) {
-	WriteJSON(w, status, ErrorResponse{
-		Code:    status,
-		Message: message,
-		DocURL:  docURL,
-	})
+	WriteErrorBody(w, status, ErrorBody{Code: CodeForStatus(status), Message: message, DocURL: docURL})
 }
 
-// WriteFieldError writes an error response with field-level validation detail.
+// WriteFieldError writes an error response with detail for one field.
 func WriteFieldError(w http.ResponseWriter, status int, message string, field, fieldCode, fieldMsg string) {
-	WriteJSON(w, status, ErrorResponse{
-		Code:    status,
+	WriteFieldErrorWithDocURL(w, status, message, field, fieldCode, fieldMsg, "")
+}
+
+// WriteFieldErrorWithDocURL writes an error response with detail for one
+// field and a doc URL. The code is conflict for a 409 (a unique violation)
+// and validation_failed otherwise.
+func WriteFieldErrorWithDocURL(w http.ResponseWriter, status int, message string, field, fieldCode, fieldMsg, docURL string) {
+	code := CodeValidationFailed
+	if status == http.StatusConflict {
+		code = CodeConflict
+	}
+	WriteErrorBody(w, status, ErrorBody{
+		Code:    code,
 		Message: message,
-		Data: map[string]any{
-			field: map[string]string{
-				"code":    fieldCode,
-				"message": fieldMsg,
-			},
-		},
+		Details: map[string]any{field: FieldError{Code: fieldCode, Message: fieldMsg}},
+		DocURL:  docURL,
 	})
 }
 
+// ErrorMessage returns the message of an API error body. It reads the error
+// envelope and, for older servers, a top-level "message", and reports false
+// for any other body.
+func ErrorMessage(body []byte) (string, bool) {
+	var resp struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return "", false
+	}
+	var e ErrorBody
+	if json.Unmarshal(resp.Error, &e) == nil && e.Message != "" {
+		return e.Message, true
+	}
+	return resp.Message, resp.Message != ""
+}
+
 // DocURL constructs a documentation URL from a path fragment.
 // Example: DocURL("/guide/authentication") -> "https://allyourbase.io/guide/authentication"
 func DocURL(path string) string {
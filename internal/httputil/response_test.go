@@ -49,48 +49,133 @@ func TestWriteError(t *testing.T) {
 	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
-	if resp.Code != http.StatusNotFound {
-		t.Fatalf("expected code 404, got %d", resp.Code)
+	if resp.Error.Code != CodeNotFound {
+		t.Fatalf("expected code %q, got %q", CodeNotFound, resp.Error.Code)
 	}
-	if resp.Message != "not found" {
-		t.Fatalf("expected 'not found', got %q", resp.Message)
+	if resp.Error.Message != "not found" {
+		t.Fatalf("expected 'not found', got %q", resp.Error.Message)
 	}
-	if resp.Data != nil {
-		t.Fatalf("expected nil data, got %v", resp.Data)
+	if resp.Error.Details != nil {
+		t.Fatalf("expected nil details, got %v", resp.Error.Details)
+	}
+}
+
+func TestWriteErrorEnvelope(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	WriteError(w, http.StatusTooManyRequests, "too many requests")
+
+	var raw map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&raw); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(raw) != 1 {
+		t.Fatalf("expected only an error key, got %v", raw)
+	}
+	body, ok := raw["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected error object, got %v", raw["error"])
+	}
+	if body["code"] != CodeRateLimited {
+		t.Fatalf("expected code %q, got %v", CodeRateLimited, body["code"])
+	}
+	if _, exists := body["details"]; exists {
+		t.Fatal("expected details to be omitted when empty")
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	t.Parallel()
+	tests := map[int]string{
+		http.StatusBadRequest:          CodeBadRequest,
+		http.StatusUnauthorized:        CodeUnauthorized,
+		http.StatusForbidden:           CodeForbidden,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusConflict:            CodeConflict,
+		http.StatusUnprocessableEntity: CodeValidationFailed,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusServiceUnavailable:  CodeUnavailable,
+		http.StatusTeapot:              CodeBadRequest,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusNotImplemented:      CodeInternal,
+	}
+	for status, want := range tests {
+		if got := CodeForStatus(status); got != want {
+			t.Errorf("CodeForStatus(%d) = %q, want %q", status, got, want)
+		}
 	}
 }
 
 func TestWriteFieldError(t *testing.T) {
 	t.Parallel()
 	w := httptest.NewRecorder()
-	WriteFieldError(w, http.StatusConflict, "unique violation", "email", "unique", "already exists")
+	WriteFieldError(w, http.StatusBadRequest, "invalid value", "email", "invalid_format", "not an email")
 
-	if w.Code != http.StatusConflict {
-		t.Fatalf("expected 409, got %d", w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
 	}
 	var resp ErrorResponse
 	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
-	if resp.Code != http.StatusConflict {
-		t.Fatalf("expected code 409, got %d", resp.Code)
+	if resp.Error.Code != CodeValidationFailed {
+		t.Fatalf("expected code %q, got %q", CodeValidationFailed, resp.Error.Code)
 	}
-	if resp.Data == nil {
-		t.Fatal("expected data to be non-nil")
-	}
-	emailField, ok := resp.Data["email"]
+	emailField, ok := resp.Error.Details["email"]
 	if !ok {
-		t.Fatal("expected 'email' key in data")
+		t.Fatal("expected 'email' key in details")
 	}
 	fieldMap, ok := emailField.(map[string]any)
 	if !ok {
 		t.Fatalf("expected map for field, got %T", emailField)
 	}
-	if fieldMap["code"] != "unique" {
-		t.Fatalf("expected code 'unique', got %q", fieldMap["code"])
+	if fieldMap["code"] != "invalid_format" {
+		t.Fatalf("expected code 'invalid_format', got %q", fieldMap["code"])
 	}
-	if fieldMap["message"] != "already exists" {
-		t.Fatalf("expected message 'already exists', got %q", fieldMap["message"])
+	if fieldMap["message"] != "not an email" {
+		t.Fatalf("expected message 'not an email', got %q", fieldMap["message"])
+	}
+}
+
+func TestWriteFieldErrorConflict(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	WriteFieldError(w, http.StatusConflict, "unique violation", "email", "unique", "already exists")
+
+	var resp ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if resp.Error.Code != CodeConflict {
+		t.Fatalf("expected code %q, got %q", CodeConflict, resp.Error.Code)
+	}
+	if _, ok := resp.Error.Details["email"]; !ok {
+		t.Fatal("expected 'email' key in details")
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{"envelope", `{"error":{"code":"not_found","message":"collection not found"}}`, "collection not found", true},
+		{"legacy", `{"code":404,"message":"collection not found"}`, "collection not found", true},
+		{"oauth error string", `{"error":"invalid_request"}`, "", false},
+		{"no message", `{"items":[]}`, "", false},
+		{"plain text", "upstream down", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ErrorMessage([]byte(tt.body))
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("ErrorMessage(%q) = %q, %v; want %q, %v", tt.body, got, ok, tt.want, tt.wantOK)
+			}
+		})
 	}
 }
 
@@ -195,14 +280,14 @@ func TestWriteErrorWithDocURL(t *testing.T) {
 	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
-	if resp.Code != http.StatusUnauthorized {
-		t.Fatalf("expected code 401, got %d", resp.Code)
+	if resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("expected code %q, got %q", CodeUnauthorized, resp.Error.Code)
 	}
-	if resp.Message != "missing or invalid JWT" {
-		t.Fatalf("expected 'missing or invalid JWT', got %q", resp.Message)
+	if resp.Error.Message != "missing or invalid JWT" {
+		t.Fatalf("expected 'missing or invalid JWT', got %q", resp.Error.Message)
 	}
-	if resp.DocURL != "https://allyourbase.io/errors/401" {
-		t.Fatalf("expected doc_url 'https://allyourbase.io/errors/401', got %q", resp.DocURL)
+	if resp.Error.DocURL != "https://allyourbase.io/errors/401" {
+		t.Fatalf("expected doc_url 'https://allyourbase.io/errors/401', got %q", resp.Error.DocURL)
 	}
 }
 
@@ -212,11 +297,13 @@ func TestWriteErrorOmitsDocURLWhenEmpty(t *testing.T) {
 	WriteError(w, http.StatusNotFound, "not found")
 
 	// Verify doc_url is omitted from JSON (not present as empty string).
-	var raw map[string]any
+	var raw struct {
+		Error map[string]any `json:"error"`
+	}
 	if err := json.NewDecoder(w.Body).Decode(&raw); err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
-	if _, exists := raw["doc_url"]; exists {
+	if _, exists := raw.Error["doc_url"]; exists {
 		t.Fatal("expected doc_url to be omitted from JSON when empty")
 	}
 }
@@ -227,11 +314,13 @@ func TestWriteErrorDocURLInRawJSON(t *testing.T) {
 	WriteErrorWithDocURL(w, http.StatusBadRequest, "bad request", "https://allyourbase.io/errors/400")
 
 	// Verify doc_url is present in raw JSON.
-	var raw map[string]any
+	var raw struct {
+		Error map[string]any `json:"error"`
+	}
 	if err := json.NewDecoder(w.Body).Decode(&raw); err != nil {
 		t.Fatalf("decode error: %v", err)
 	}
-	docURL, exists := raw["doc_url"]
+	docURL, exists := raw.Error["doc_url"]
 	if !exists {
 		t.Fatal("expected doc_url to be present in JSON")
 	}
@@ -270,6 +359,58 @@ func TestIsValidUUID(t *testing.T) {
 	}
 }
 
+func TestBodyLimit(t *testing.T) {
+	t.Parallel()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":"0123456789"}`))
+	if got := BodyLimit(r); got != MaxBodySize {
+		t.Fatalf("expected default %d, got %d", MaxBodySize, got)
+	}
+
+	r = r.WithContext(WithBodyLimit(r.Context(), 8))
+	if got := BodyLimit(r); got != 8 {
+		t.Fatalf("expected 8, got %d", got)
+	}
+	var data map[string]string
+	w := httptest.NewRecorder()
+	if DecodeJSON(w, r, &data) {
+		t.Fatal("expected DecodeJSON to reject a body over the limit")
+	}
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestDecodeJSONTooLarge(t *testing.T) {
+	t.Parallel()
+	body := `{"a":"` + strings.Repeat("x", 64) + `"}`
+	tests := []struct {
+		name          string
+		contentLength int64
+	}{
+		{"declared length over the limit", int64(len(body))},
+		{"unknown length over the limit", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+			r.ContentLength = tt.contentLength
+			r = r.WithContext(WithBodyLimit(r.Context(), 16))
+			var data map[string]string
+			w := httptest.NewRecorder()
+			if DecodeJSON(w, r, &data) {
+				t.Fatal("expected DecodeJSON to reject a body over the limit")
+			}
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("expected 413, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), "request body too large") {
+				t.Fatalf("unexpected body %q", w.Body.String())
+			}
+		})
+	}
+}
+
 func TestMaxBodySizeConstant(t *testing.T) {
 	t.Parallel()
 	if MaxBodySize != 1<<20 {
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 
 	"github.com/allyourbase/ayb/internal/matview"
+	"github.com/allyourbase/ayb/internal/postgres"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,6 +17,8 @@ func RegisterBuiltinHandlers(svc *Service, pool *pgxpool.Pool, logger *slog.Logg
 	svc.RegisterHandler("webhook_delivery_prune", WebhookDeliveryPruneHandler(pool, logger))
 	svc.RegisterHandler("expired_oauth_cleanup", ExpiredOAuthCleanupHandler(pool, logger))
 	svc.RegisterHandler("expired_auth_cleanup", ExpiredAuthCleanupHandler(pool, logger))
+	svc.RegisterHandler("expired_idempotency_cleanup", ExpiredIdempotencyCleanupHandler(pool, logger))
+	svc.RegisterHandler("db_maintenance", DBMaintenanceHandler(pool, logger))
 
 	mvStore := matview.NewStore(pool)
 	mvSvc := matview.NewService(mvStore)
@@ -115,3 +118,43 @@ func ExpiredAuthCleanupHandler(pool *pgxpool.Pool, logger *slog.Logger) JobHandl
 		return nil
 	}
 }
+
+// ExpiredIdempotencyCleanupHandler deletes expired Idempotency-Key records.
+func ExpiredIdempotencyCleanupHandler(pool *pgxpool.Pool, logger *slog.Logger) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		tag, err := pool.Exec(ctx,
+			`DELETE FROM _ayb_idempotency_keys WHERE expires_at < NOW()`)
+		if err != nil {
+			return fmt.Errorf("expired_idempotency_cleanup: %w", err)
+		}
+		logger.Info("expired_idempotency_cleanup completed", "deleted", tag.RowsAffected())
+		return nil
+	}
+}
+
+// DBMaintenanceHandler runs VACUUM, ANALYZE and REINDEX CONCURRENTLY on the
+// user tables, as selected by a postgres.MaintenanceOptions payload. An empty
+// payload runs VACUUM and ANALYZE.
+func DBMaintenanceHandler(pool *pgxpool.Pool, logger *slog.Logger) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var opts postgres.MaintenanceOptions
+		if len(payload) > 0 && string(payload) != "{}" {
+			if err := json.Unmarshal(payload, &opts); err != nil {
+				return fmt.Errorf("db_maintenance: invalid payload: %w", err)
+			}
+		}
+		opts = opts.WithDefault()
+
+		tables, err := postgres.MaintenanceTables(ctx, pool)
+		if err != nil {
+			return fmt.Errorf("db_maintenance: %w", err)
+		}
+		steps := postgres.MaintenancePlan(tables, opts)
+		if err := postgres.RunMaintenance(ctx, pool, steps, nil); err != nil {
+			return fmt.Errorf("db_maintenance: %w", err)
+		}
+		logger.Info("db_maintenance completed", "tables", len(tables), "statements", len(steps),
+			"vacuum", opts.Vacuum, "analyze", opts.Analyze, "reindex", opts.Reindex)
+		return nil
+	}
+}
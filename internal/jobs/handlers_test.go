@@ -219,6 +219,27 @@ func TestExpiredAuthCleanupHandler(t *testing.T) {
 	testutil.Equal(t, 1, resetCount)
 }
 
+func TestExpiredIdempotencyCleanupHandler(t *testing.T) {
+	setupHandlerDB(t)
+	ctx := context.Background()
+	pool := sharedPG.Pool
+
+	_, err := pool.Exec(ctx,
+		`INSERT INTO _ayb_idempotency_keys (user_id, key, request_hash, expires_at) VALUES
+		 ('', 'expired', 'hash', NOW() - interval '1 hour'),
+		 ('', 'active', 'hash', NOW() + interval '1 hour')`)
+	testutil.NoError(t, err)
+
+	handler := jobs.ExpiredIdempotencyCleanupHandler(pool, testutil.DiscardLogger())
+	err = handler(ctx, nil)
+	testutil.NoError(t, err)
+
+	var key string
+	err = pool.QueryRow(ctx, `SELECT key FROM _ayb_idempotency_keys`).Scan(&key)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "active", key)
+}
+
 func TestMatviewRefreshHandlerIntegration(t *testing.T) {
 	setupHandlerDB(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -363,3 +384,39 @@ func TestHandlersRunThroughService(t *testing.T) {
 	testutil.NoError(t, err)
 	testutil.Equal(t, 0, count)
 }
+
+func TestDBMaintenanceHandler(t *testing.T) {
+	setupHandlerDB(t)
+	ctx := context.Background()
+	pool := sharedPG.Pool
+
+	_, err := pool.Exec(ctx,
+		`CREATE TABLE maint_items (id serial PRIMARY KEY, name text);
+		 CREATE INDEX maint_items_name_idx ON maint_items (name);
+		 INSERT INTO maint_items (name) SELECT 'item' || g FROM generate_series(1, 100) g`)
+	testutil.NoError(t, err)
+
+	handler := jobs.DBMaintenanceHandler(pool, testutil.DiscardLogger())
+	err = handler(ctx, json.RawMessage(`{"vacuum": true, "analyze": true, "reindex": true}`))
+	testutil.NoError(t, err)
+
+	// ANALYZE updates the planner's row estimate.
+	var reltuples float64
+	err = pool.QueryRow(ctx,
+		`SELECT reltuples FROM pg_class WHERE oid = 'public.maint_items'::regclass`).Scan(&reltuples)
+	testutil.NoError(t, err)
+	testutil.Equal(t, float64(100), reltuples)
+
+	// REINDEX CONCURRENTLY leaves every index valid.
+	var invalid int
+	err = pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM pg_index WHERE indrelid = 'public.maint_items'::regclass AND NOT indisvalid`).Scan(&invalid)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, invalid)
+}
+
+func TestDBMaintenanceHandlerInvalidPayload(t *testing.T) {
+	handler := jobs.DBMaintenanceHandler(sharedPG.Pool, testutil.DiscardLogger())
+	err := handler(context.Background(), json.RawMessage(`{"vacuum": "yes"}`))
+	testutil.ErrorContains(t, err, "invalid payload")
+}
@@ -24,6 +24,7 @@ type Job struct {
 	Payload        json.RawMessage `json:"payload"`
 	State          JobState        `json:"state"`
 	RunAt          time.Time       `json:"runAt"`
+	Priority       int             `json:"priority"` // higher is claimed first
 	LeaseUntil     *time.Time      `json:"leaseUntil,omitempty"`
 	WorkerID       *string         `json:"workerId,omitempty"`
 	Attempts       int             `json:"attempts"`
@@ -59,18 +60,33 @@ type EnqueueOpts struct {
 	RunAt          *time.Time
 	IdempotencyKey string
 	MaxAttempts    int // 0 = use service default
+	Priority       int // higher is claimed first; default 0
 	ScheduleID     string
 }
 
 // JobHandler processes a job payload. Implementations must be idempotent.
 type JobHandler func(ctx context.Context, payload json.RawMessage) error
 
-// QueueStats holds aggregate counts by job state.
+// QueueStats holds aggregate counts by job state, the outcomes of recently
+// finished jobs, and how busy this instance's workers are.
 type QueueStats struct {
-	Queued    int       `json:"queued"`
-	Running   int       `json:"running"`
-	Completed int       `json:"completed"`
-	Failed    int       `json:"failed"`
-	Canceled  int       `json:"canceled"`
-	OldestAge *float64  `json:"oldestQueuedAgeSec,omitempty"` // seconds since oldest queued job's run_at
+	Queued         int        `json:"queued"`
+	Running        int        `json:"running"`
+	Completed      int        `json:"completed"`
+	Failed         int        `json:"failed"`
+	Canceled       int        `json:"canceled"`
+	OldestAge      *float64   `json:"oldestQueuedAgeSec,omitempty"` // seconds since oldest queued job's run_at
+	OldestQueuedAt *time.Time `json:"oldestQueuedAt,omitempty"`     // when the oldest queued job was enqueued
+
+	// Jobs that completed or permanently failed in the last hour.
+	// FailureRate is failedLastHour / (completedLastHour + failedLastHour).
+	CompletedLastHour int     `json:"completedLastHour"`
+	FailedLastHour    int     `json:"failedLastHour"`
+	FailureRate       float64 `json:"failureRate"`
+
+	// Workers of this instance, and how many are running a job. Only
+	// Service.Stats fills these in.
+	Workers           int     `json:"workers"`
+	BusyWorkers       int     `json:"busyWorkers"`
+	WorkerUtilization float64 `json:"workerUtilization"` // busyWorkers / workers
 }
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Handlers added with Register run on every Service, alongside those added
+// with Service.RegisterHandler, which take precedence for the same type.
+// Programs that build AYB with their own job types register them from an
+// init function, before the server starts.
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]JobHandler{}
+)
+
+// Register makes handler process jobs of jobType on every Service. Like
+// database/sql.Register, it panics if jobType is empty, handler is nil, or
+// jobType is already registered.
+func Register(jobType string, handler JobHandler) {
+	if jobType == "" {
+		panic("jobs: Register with empty job type")
+	}
+	if handler == nil {
+		panic("jobs: Register handler is nil for " + jobType)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[jobType]; dup {
+		panic("jobs: Register called twice for " + jobType)
+	}
+	registry[jobType] = handler
+}
+
+// registeredHandler returns the handler added with Register for jobType.
+func registeredHandler(jobType string) (JobHandler, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	h, ok := registry[jobType]
+	return h, ok
+}
+
+// Handle adapts fn to a JobHandler that decodes the JSON payload into T
+// first. A payload that doesn't decode fails the job permanently without
+// calling fn, since retrying can't fix it.
+//
+//	jobs.Register("send_invoice", jobs.Handle(func(ctx context.Context, p InvoicePayload) error {
+//		return sendInvoice(ctx, p.CustomerID)
+//	}))
+func Handle[T any](fn func(ctx context.Context, payload T) error) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var p T
+		if len(payload) > 0 {
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return Permanent(fmt.Errorf("invalid payload: %w", err))
+			}
+		}
+		return fn(ctx, p)
+	}
+}
+
+// permanentError is a job failure that retrying can't fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that a handler returning it fails the job without
+// using its remaining attempts. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent reports whether err was wrapped with Permanent.
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func noopHandler(context.Context, json.RawMessage) error { return nil }
+
+func assertRegisterPanics(t *testing.T, jobType string, handler JobHandler, want string) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		testutil.NotNil(t, r)
+		testutil.Contains(t, fmt.Sprint(r), want)
+	}()
+	Register(jobType, handler)
+}
+
+func TestRegisterRejectsInvalidRegistrations(t *testing.T) {
+	assertRegisterPanics(t, "", noopHandler, "empty job type")
+	assertRegisterPanics(t, "registry_test_nil", nil, "handler is nil")
+
+	Register("registry_test_dup", noopHandler)
+	assertRegisterPanics(t, "registry_test_dup", noopHandler, "called twice")
+}
+
+func TestServiceHandlerFallsBackToRegistry(t *testing.T) {
+	var ran string
+	Register("registry_test_fallback", func(context.Context, json.RawMessage) error {
+		ran = "registry"
+		return nil
+	})
+	Register("registry_test_override", func(context.Context, json.RawMessage) error {
+		ran = "registry"
+		return nil
+	})
+
+	svc := NewService(nil, nil, DefaultServiceConfig())
+	svc.RegisterHandler("registry_test_override", func(context.Context, json.RawMessage) error {
+		ran = "service"
+		return nil
+	})
+
+	h, ok := svc.handler("registry_test_fallback")
+	testutil.True(t, ok, "registered handler should be found")
+	testutil.NoError(t, h(context.Background(), nil))
+	testutil.Equal(t, "registry", ran)
+
+	h, ok = svc.handler("registry_test_override")
+	testutil.True(t, ok, "service handler should be found")
+	testutil.NoError(t, h(context.Background(), nil))
+	testutil.Equal(t, "service", ran)
+
+	_, ok = svc.handler("registry_test_missing")
+	testutil.False(t, ok, "unregistered type should have no handler")
+
+	types := strings.Join(svc.JobTypes(), ",")
+	testutil.Contains(t, types, "registry_test_fallback,registry_test_override")
+	testutil.Equal(t, 1, strings.Count(types, "registry_test_override"))
+}
+
+type registryTestPayload struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestHandleDecodesPayload(t *testing.T) {
+	var got registryTestPayload
+	h := Handle(func(_ context.Context, p registryTestPayload) error {
+		got = p
+		return nil
+	})
+
+	testutil.NoError(t, h(context.Background(), json.RawMessage(`{"name":"invoice","count":3}`)))
+	testutil.Equal(t, "invoice", got.Name)
+	testutil.Equal(t, 3, got.Count)
+
+	got = registryTestPayload{Name: "stale"}
+	testutil.NoError(t, h(context.Background(), nil))
+	testutil.Equal(t, "", got.Name)
+}
+
+func TestHandleReturnsHandlerError(t *testing.T) {
+	want := errors.New("smtp unavailable")
+	h := Handle(func(context.Context, registryTestPayload) error { return want })
+
+	err := h(context.Background(), json.RawMessage(`{}`))
+	testutil.True(t, errors.Is(err, want), "expected handler error, got %v", err)
+	testutil.False(t, isPermanent(err), "handler errors should be retried")
+}
+
+func TestHandleInvalidPayloadFailsPermanently(t *testing.T) {
+	called := false
+	h := Handle(func(context.Context, registryTestPayload) error {
+		called = true
+		return nil
+	})
+
+	err := h(context.Background(), json.RawMessage(`"not an object"`))
+	testutil.ErrorContains(t, err, "invalid payload")
+	testutil.True(t, isPermanent(err), "decode errors should not be retried")
+	testutil.False(t, called, "handler should not run on an invalid payload")
+}
+
+func TestPermanent(t *testing.T) {
+	testutil.Nil(t, Permanent(nil))
+
+	cause := errors.New("account deleted")
+	err := fmt.Errorf("charging: %w", Permanent(cause))
+	testutil.True(t, isPermanent(err), "wrapped permanent error should be detected")
+	testutil.True(t, errors.Is(err, cause), "cause should stay reachable")
+	testutil.Equal(t, "charging: account deleted", err.Error())
+	testutil.False(t, isPermanent(cause), "plain errors are not permanent")
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/adhocore/gronx"
@@ -45,6 +48,12 @@ type Service struct {
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+	// abort is cancelled when Stop gives up waiting for in-flight jobs; their
+	// handlers see a cancelled context and the jobs are re-queued.
+	abort       context.Context
+	abortCancel context.CancelFunc
+
+	busy atomic.Int64 // workers running a job
 }
 
 // NewService creates a new job Service.
@@ -64,9 +73,39 @@ func (s *Service) RegisterHandler(jobType string, handler JobHandler) {
 	s.handlers[jobType] = handler
 }
 
+// JobTypes returns the job types with a handler, registered on the service
+// or with Register, sorted.
+func (s *Service) JobTypes() []string {
+	types := map[string]bool{}
+	s.mu.RLock()
+	for t := range s.handlers {
+		types[t] = true
+	}
+	s.mu.RUnlock()
+	registryMu.RLock()
+	for t := range registry {
+		types[t] = true
+	}
+	registryMu.RUnlock()
+	return slices.Sorted(maps.Keys(types))
+}
+
+// handler returns the handler for jobType: the one registered on the
+// service, or else the one added with Register.
+func (s *Service) handler(jobType string) (JobHandler, bool) {
+	s.mu.RLock()
+	h, ok := s.handlers[jobType]
+	s.mu.RUnlock()
+	if ok {
+		return h, true
+	}
+	return registeredHandler(jobType)
+}
+
 // Start launches worker goroutines and the scheduler loop.
 func (s *Service) Start(ctx context.Context) {
 	ctx, s.cancel = context.WithCancel(ctx)
+	s.abort, s.abortCancel = context.WithCancel(context.Background())
 
 	// Start worker goroutines.
 	for i := 0; i < s.cfg.WorkerConcurrency; i++ {
@@ -92,12 +131,30 @@ func (s *Service) Start(ctx context.Context) {
 	)
 }
 
-// Stop signals all goroutines to stop and waits for in-progress jobs to finish.
+// Stop signals all goroutines to stop and waits up to ShutdownTimeout for
+// in-progress jobs to finish. Jobs still running after that have their
+// context cancelled and are re-queued without using up an attempt.
 func (s *Service) Stop() {
-	if s.cancel != nil {
-		s.cancel()
+	if s.cancel == nil {
+		return
 	}
-	s.wg.Wait()
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(s.cfg.ShutdownTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		s.logger.Warn("job shutdown timeout reached, re-queueing running jobs")
+		s.abortCancel()
+		<-done
+	}
+	s.abortCancel()
 	s.logger.Info("job service stopped")
 }
 
@@ -129,13 +186,13 @@ func (s *Service) pollAndProcess(ctx context.Context, workerID string) {
 	if job == nil {
 		return // no jobs available
 	}
+	s.busy.Add(1)
+	defer s.busy.Add(-1)
 
 	s.logger.Info("claimed job", "job_id", job.ID, "type", job.Type,
 		"attempt", job.Attempts, "worker", workerID)
 
-	s.mu.RLock()
-	handler, ok := s.handlers[job.Type]
-	s.mu.RUnlock()
+	handler, ok := s.handler(job.Type)
 
 	// Use a separate context for handler execution so that in-flight jobs
 	// can finish their DB operations during graceful shutdown. The poll loop's
@@ -143,7 +200,7 @@ func (s *Service) pollAndProcess(ctx context.Context, workerID string) {
 	// complete or fail the job cleanly. With lease renewal the handler is no
 	// longer hard-capped at the lease duration — the shutdown timeout bounds
 	// total in-flight execution instead.
-	handlerCtx, handlerCancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	handlerCtx, handlerCancel := context.WithTimeout(s.abort, s.cfg.ShutdownTimeout)
 	defer handlerCancel()
 
 	// Start lease renewal goroutine. It extends the lease every half-period
@@ -154,7 +211,7 @@ func (s *Service) pollAndProcess(ctx context.Context, workerID string) {
 
 	var jobErr error
 	if !ok {
-		jobErr = fmt.Errorf("no handler registered for job type %q", job.Type)
+		jobErr = Permanent(fmt.Errorf("no handler registered for job type %q", job.Type))
 	} else {
 		jobErr = handler(handlerCtx, job.Payload)
 	}
@@ -162,9 +219,32 @@ func (s *Service) pollAndProcess(ctx context.Context, workerID string) {
 	// Stop lease renewal before updating final state.
 	renewCancel()
 
+	stateCtx := handlerCtx
+	if s.abort.Err() != nil {
+		// Stop gave up waiting and cancelled handlerCtx; record the outcome
+		// on a fresh context.
+		var stateCancel context.CancelFunc
+		stateCtx, stateCancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer stateCancel()
+		if jobErr != nil {
+			// The handler was interrupted rather than failing on its own;
+			// hand the job back for the next worker.
+			if _, err := s.store.Release(stateCtx, job.ID); err != nil {
+				s.logger.Error("failed to re-queue interrupted job", "job_id", job.ID, "error", err)
+			} else {
+				s.logger.Warn("job interrupted by shutdown, re-queued", "job_id", job.ID, "type", job.Type)
+			}
+			return
+		}
+	}
+
 	if jobErr != nil {
-		backoff := ComputeBackoff(job.Attempts)
-		_, failErr := s.store.Fail(handlerCtx, job.ID, jobErr.Error(), backoff)
+		var failErr error
+		if isPermanent(jobErr) {
+			_, failErr = s.store.FailPermanently(stateCtx, job.ID, jobErr.Error())
+		} else {
+			_, failErr = s.store.Fail(stateCtx, job.ID, jobErr.Error(), ComputeBackoff(job.Attempts))
+		}
 		if failErr != nil {
 			s.logger.Error("failed to record job failure",
 				"job_id", job.ID, "error", failErr)
@@ -175,7 +255,7 @@ func (s *Service) pollAndProcess(ctx context.Context, workerID string) {
 		return
 	}
 
-	_, completeErr := s.store.Complete(handlerCtx, job.ID)
+	_, completeErr := s.store.Complete(stateCtx, job.ID)
 	if completeErr != nil {
 		s.logger.Error("failed to complete job",
 			"job_id", job.ID, "error", completeErr)
@@ -335,9 +415,19 @@ func (s *Service) List(ctx context.Context, state, jobType string, limit, offset
 	return s.store.List(ctx, state, jobType, limit, offset)
 }
 
-// Stats delegates to the underlying store.
+// Stats returns the store's queue stats with this instance's worker
+// utilization.
 func (s *Service) Stats(ctx context.Context) (*QueueStats, error) {
-	return s.store.Stats(ctx)
+	stats, err := s.store.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.Workers = s.cfg.WorkerConcurrency
+	stats.BusyWorkers = int(s.busy.Load())
+	if stats.Workers > 0 {
+		stats.WorkerUtilization = float64(stats.BusyWorkers) / float64(stats.Workers)
+	}
+	return stats, nil
 }
 
 // Cancel delegates to the underlying store.
@@ -434,6 +524,24 @@ func (s *Service) RegisterDefaultSchedules(ctx context.Context) error {
 			Enabled:     true,
 			MaxAttempts: 3,
 		},
+		{
+			Name:        "idempotency_cleanup_hourly",
+			JobType:     "expired_idempotency_cleanup",
+			CronExpr:    "30 * * * *",
+			Timezone:    "UTC",
+			Enabled:     true,
+			MaxAttempts: 3,
+		},
+		{
+			// Disabled until an operator enables it; see ayb db maintenance.
+			Name:        "db_maintenance_weekly",
+			JobType:     "db_maintenance",
+			Payload:     json.RawMessage(`{"vacuum": true, "analyze": true}`),
+			CronExpr:    "0 2 * * 0",
+			Timezone:    "UTC",
+			Enabled:     false,
+			MaxAttempts: 1,
+		},
 	}
 
 	for i := range defaults {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -97,6 +98,64 @@ func TestWorkerProcessesJob(t *testing.T) {
 	testutil.Equal(t, 1, len(all))
 }
 
+func TestEnqueuedJobStatusTransitions(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	release := make(chan struct{})
+	svc.RegisterHandler("report", func(ctx context.Context, payload json.RawMessage) error {
+		<-release
+		return nil
+	})
+	testutil.True(t, slices.Contains(svc.JobTypes(), "report"), "report should be a registered job type")
+
+	job, err := svc.Enqueue(ctx, "report", json.RawMessage(`{"month":"2026-01"}`), jobs.EnqueueOpts{Priority: 1})
+	testutil.NoError(t, err)
+	got, err := svc.Get(ctx, job.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, jobs.StateQueued, got.State)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	waitForJobState(ctx, t, svc, job.ID, jobs.StateRunning)
+	close(release)
+	waitForJobState(ctx, t, svc, job.ID, jobs.StateCompleted)
+}
+
+func TestServiceStatsWorkerUtilization(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	svc.RegisterHandler("slow_job", func(ctx context.Context, payload json.RawMessage) error {
+		close(started)
+		<-release
+		return nil
+	})
+	_, err := svc.Enqueue(ctx, "slow_job", nil, jobs.EnqueueOpts{})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	select {
+	case <-started:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for job to start")
+	}
+	stats, err := svc.Stats(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, stats.Workers)
+	testutil.Equal(t, 1, stats.BusyWorkers)
+	testutil.Equal(t, 0.5, stats.WorkerUtilization)
+	testutil.Equal(t, 1, stats.Running)
+	close(release)
+}
+
 func TestWorkerRetriesFailedJob(t *testing.T) {
 	svc := setupService(t)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -197,6 +256,129 @@ func TestWorkerUnknownJobTypeFails(t *testing.T) {
 	}
 }
 
+func TestWorkerUnknownJobTypeFailsWithoutRetry(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := svc.Enqueue(ctx, "nonexistent_retryable_type", nil, jobs.EnqueueOpts{MaxAttempts: 3})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	got := waitForJobState(ctx, t, svc, job.ID, jobs.StateFailed)
+	testutil.Equal(t, 1, got.Attempts)
+	testutil.Contains(t, *got.LastError, `no handler registered for job type "nonexistent_retryable_type"`)
+}
+
+type registeredPayload struct {
+	Invoice string `json:"invoice"`
+}
+
+var (
+	registeredInvoices = make(chan string, 1)
+	registeredAttempts atomic.Int32
+)
+
+func init() {
+	jobs.Register("test_registered_invoice", jobs.Handle(func(ctx context.Context, p registeredPayload) error {
+		registeredInvoices <- p.Invoice
+		return nil
+	}))
+	jobs.Register("test_registered_flaky", func(ctx context.Context, payload json.RawMessage) error {
+		if n := registeredAttempts.Add(1); n < 2 {
+			return fmt.Errorf("deliberate failure attempt %d", n)
+		}
+		return nil
+	})
+}
+
+func TestRegisteredHandlerRuns(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	testutil.True(t, slices.Contains(svc.JobTypes(), "test_registered_invoice"), "registered type should be listed")
+
+	job, err := svc.Enqueue(ctx, "test_registered_invoice", json.RawMessage(`{"invoice":"INV-42"}`), jobs.EnqueueOpts{})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	select {
+	case invoice := <-registeredInvoices:
+		testutil.Equal(t, "INV-42", invoice)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for registered handler")
+	}
+	waitForJobState(ctx, t, svc, job.ID, jobs.StateCompleted)
+}
+
+func TestRegisteredHandlerInvalidPayloadFailsWithoutRetry(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := svc.Enqueue(ctx, "test_registered_invoice", json.RawMessage(`["INV-42"]`), jobs.EnqueueOpts{MaxAttempts: 3})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	got := waitForJobState(ctx, t, svc, job.ID, jobs.StateFailed)
+	testutil.Equal(t, 1, got.Attempts)
+	testutil.Contains(t, *got.LastError, "invalid payload")
+}
+
+func TestRegisteredHandlerErrorRetriesWithBackoff(t *testing.T) {
+	svc := setupService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := svc.Enqueue(ctx, "test_registered_flaky", nil, jobs.EnqueueOpts{MaxAttempts: 3})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	defer svc.Stop()
+
+	// The first attempt fails and the job goes back to the queue with a
+	// backoff delay rather than failing outright.
+	for registeredAttempts.Load() < 1 {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for first attempt")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	retrying := waitForJobState(ctx, t, svc, job.ID, jobs.StateQueued)
+	testutil.Equal(t, 1, retrying.Attempts)
+	testutil.Equal(t, "deliberate failure attempt 1", *retrying.LastError)
+	testutil.True(t, time.Until(retrying.RunAt) > 3*time.Second,
+		"retry should be delayed by backoff, run_at is %s", retrying.RunAt)
+
+	waitForJobState(ctx, t, svc, job.ID, jobs.StateCompleted)
+	testutil.Equal(t, int32(2), registeredAttempts.Load())
+}
+
+// waitForJobState polls until the job reaches want and returns it.
+func waitForJobState(ctx context.Context, t *testing.T, svc *jobs.Service, jobID string, want jobs.JobState) *jobs.Job {
+	t.Helper()
+	for {
+		got, err := svc.Get(ctx, jobID)
+		testutil.NoError(t, err)
+		if got.State == want {
+			return got
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %s, job is %s", want, got.State)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
 func TestConcurrentWorkers(t *testing.T) {
 	svc := setupService(t, func(cfg *jobs.ServiceConfig) {
 		cfg.WorkerConcurrency = 4
@@ -551,6 +733,42 @@ func TestGracefulShutdown(t *testing.T) {
 	testutil.Equal(t, int32(1), finished.Load())
 }
 
+func TestShutdownRequeuesInterruptedJob(t *testing.T) {
+	svc := setupService(t, func(cfg *jobs.ServiceConfig) {
+		cfg.WorkerConcurrency = 1
+		cfg.ShutdownTimeout = 300 * time.Millisecond
+	})
+	ctx := context.Background()
+
+	var started atomic.Int32
+	svc.RegisterHandler("stuck_job", func(ctx context.Context, payload json.RawMessage) error {
+		started.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	job, err := svc.Enqueue(ctx, "stuck_job", nil, jobs.EnqueueOpts{})
+	testutil.NoError(t, err)
+
+	svc.Start(ctx)
+	deadline := time.After(2 * time.Second)
+	for started.Load() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for job to start")
+		default:
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	// Stop gives up after ShutdownTimeout and hands the job back.
+	svc.Stop()
+	got, err := svc.Get(ctx, job.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, jobs.StateQueued, got.State)
+	testutil.Equal(t, 0, got.Attempts)
+}
+
 // --- CronNextTime Tests ---
 
 func TestCronNextTime(t *testing.T) {
@@ -595,17 +813,22 @@ func TestRegisterDefaultSchedules(t *testing.T) {
 
 	schedules, err := svc.ListSchedules(ctx)
 	testutil.NoError(t, err)
-	testutil.True(t, len(schedules) >= 4,
-		"expected at least 4 default schedules, got %d", len(schedules))
+	testutil.True(t, len(schedules) >= 5,
+		"expected at least 5 default schedules, got %d", len(schedules))
 
 	names := map[string]bool{}
+	enabled := map[string]bool{}
 	for _, s := range schedules {
 		names[s.Name] = true
+		enabled[s.Name] = s.Enabled
 	}
 	testutil.True(t, names["session_cleanup_hourly"], "missing session_cleanup_hourly")
 	testutil.True(t, names["webhook_delivery_prune_daily"], "missing webhook_delivery_prune_daily")
 	testutil.True(t, names["expired_oauth_cleanup_daily"], "missing expired_oauth_cleanup_daily")
 	testutil.True(t, names["expired_auth_cleanup_daily"], "missing expired_auth_cleanup_daily")
+	testutil.True(t, names["idempotency_cleanup_hourly"], "missing idempotency_cleanup_hourly")
+	testutil.True(t, names["db_maintenance_weekly"], "missing db_maintenance_weekly")
+	testutil.False(t, enabled["db_maintenance_weekly"], "db_maintenance_weekly should start disabled")
 
 	// Idempotent: running again should not error or create duplicates.
 	err = svc.RegisterDefaultSchedules(ctx)
@@ -27,14 +27,14 @@ func NewStore(pool *pgxpool.Pool) *Store {
 	return &Store{pool: pool}
 }
 
-const jobColumns = `id, type, payload, state, run_at, lease_until, worker_id,
+const jobColumns = `id, type, payload, state, run_at, priority, lease_until, worker_id,
 	attempts, max_attempts, last_error, last_run_at, idempotency_key,
 	schedule_id, created_at, updated_at, completed_at, canceled_at`
 
 func scanJob(row pgx.Row) (*Job, error) {
 	var j Job
 	err := row.Scan(
-		&j.ID, &j.Type, &j.Payload, &j.State, &j.RunAt, &j.LeaseUntil,
+		&j.ID, &j.Type, &j.Payload, &j.State, &j.RunAt, &j.Priority, &j.LeaseUntil,
 		&j.WorkerID, &j.Attempts, &j.MaxAttempts, &j.LastError, &j.LastRunAt,
 		&j.IdempotencyKey, &j.ScheduleID, &j.CreatedAt, &j.UpdatedAt,
 		&j.CompletedAt, &j.CanceledAt,
@@ -50,7 +50,7 @@ func scanJobs(rows pgx.Rows) ([]Job, error) {
 	for rows.Next() {
 		var j Job
 		if err := rows.Scan(
-			&j.ID, &j.Type, &j.Payload, &j.State, &j.RunAt, &j.LeaseUntil,
+			&j.ID, &j.Type, &j.Payload, &j.State, &j.RunAt, &j.Priority, &j.LeaseUntil,
 			&j.WorkerID, &j.Attempts, &j.MaxAttempts, &j.LastError, &j.LastRunAt,
 			&j.IdempotencyKey, &j.ScheduleID, &j.CreatedAt, &j.UpdatedAt,
 			&j.CompletedAt, &j.CanceledAt,
@@ -86,15 +86,16 @@ func (s *Store) Enqueue(ctx context.Context, jobType string, payload json.RawMes
 	}
 
 	row := s.pool.QueryRow(ctx,
-		`INSERT INTO _ayb_jobs (type, payload, run_at, max_attempts, idempotency_key, schedule_id)
-		 VALUES ($1, $2, $3, $4, $5, $6)
+		`INSERT INTO _ayb_jobs (type, payload, run_at, priority, max_attempts, idempotency_key, schedule_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
 		 RETURNING `+jobColumns,
-		jobType, payload, runAt, maxAttempts, idempotencyKey, scheduleID,
+		jobType, payload, runAt, opts.Priority, maxAttempts, idempotencyKey, scheduleID,
 	)
 	return scanJob(row)
 }
 
-// Claim atomically claims the next eligible queued job using FOR UPDATE SKIP LOCKED.
+// Claim atomically claims the next eligible queued job, highest priority
+// first, using FOR UPDATE SKIP LOCKED.
 // Returns nil, nil if no job is available.
 func (s *Store) Claim(ctx context.Context, workerID string, leaseDuration time.Duration) (*Job, error) {
 	row := s.pool.QueryRow(ctx,
@@ -108,7 +109,7 @@ func (s *Store) Claim(ctx context.Context, workerID string, leaseDuration time.D
 		WHERE id = (
 			SELECT id FROM _ayb_jobs
 			WHERE state = 'queued' AND run_at <= NOW()
-			ORDER BY run_at
+			ORDER BY priority DESC, run_at
 			LIMIT 1
 			FOR UPDATE SKIP LOCKED
 		)
@@ -167,7 +168,12 @@ func (s *Store) Fail(ctx context.Context, jobID string, errMsg string, backoff t
 	}
 
 	// Terminal failure: attempts >= max_attempts.
-	row = s.pool.QueryRow(ctx,
+	return s.FailPermanently(ctx, jobID, errMsg)
+}
+
+// FailPermanently marks a running job as failed without retrying it.
+func (s *Store) FailPermanently(ctx context.Context, jobID string, errMsg string) (*Job, error) {
+	row := s.pool.QueryRow(ctx,
 		`UPDATE _ayb_jobs SET
 			state = 'failed',
 			last_error = $2,
@@ -178,7 +184,7 @@ func (s *Store) Fail(ctx context.Context, jobID string, errMsg string, backoff t
 		RETURNING `+jobColumns,
 		jobID, errMsg,
 	)
-	j, err = scanJob(row)
+	j, err := scanJob(row)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("job %s not found or not in running state", jobID)
 	}
@@ -203,6 +209,28 @@ func (s *Store) Cancel(ctx context.Context, jobID string) (*Job, error) {
 	return j, err
 }
 
+// Release re-queues a running job interrupted by shutdown to run again now.
+// The interrupted run doesn't count against max_attempts.
+func (s *Store) Release(ctx context.Context, jobID string) (*Job, error) {
+	row := s.pool.QueryRow(ctx,
+		`UPDATE _ayb_jobs SET
+			state = 'queued',
+			run_at = NOW(),
+			attempts = GREATEST(attempts - 1, 0),
+			lease_until = NULL,
+			worker_id = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND state = 'running'
+		RETURNING `+jobColumns,
+		jobID,
+	)
+	j, err := scanJob(row)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("job %s not found or not in running state", jobID)
+	}
+	return j, err
+}
+
 // RetryNow resets a failed job to queued with run_at=now.
 func (s *Store) RetryNow(ctx context.Context, jobID string) (*Job, error) {
 	row := s.pool.QueryRow(ctx,
@@ -312,7 +340,8 @@ func (s *Store) List(ctx context.Context, state string, jobType string, limit, o
 	return jobs, err
 }
 
-// Stats returns aggregate counts by state.
+// Stats returns aggregate counts by state and the outcomes of jobs that
+// finished in the last hour.
 func (s *Store) Stats(ctx context.Context) (*QueueStats, error) {
 	var stats QueueStats
 	err := s.pool.QueryRow(ctx, `
@@ -321,23 +350,27 @@ func (s *Store) Stats(ctx context.Context) (*QueueStats, error) {
 			COALESCE(SUM(CASE WHEN state = 'running' THEN 1 ELSE 0 END), 0),
 			COALESCE(SUM(CASE WHEN state = 'completed' THEN 1 ELSE 0 END), 0),
 			COALESCE(SUM(CASE WHEN state = 'failed' THEN 1 ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN state = 'canceled' THEN 1 ELSE 0 END), 0)
+			COALESCE(SUM(CASE WHEN state = 'canceled' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN state = 'completed' AND completed_at >= NOW() - INTERVAL '1 hour' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN state = 'failed' AND updated_at >= NOW() - INTERVAL '1 hour' THEN 1 ELSE 0 END), 0)
 		FROM _ayb_jobs
-	`).Scan(&stats.Queued, &stats.Running, &stats.Completed, &stats.Failed, &stats.Canceled)
+	`).Scan(&stats.Queued, &stats.Running, &stats.Completed, &stats.Failed, &stats.Canceled,
+		&stats.CompletedLastHour, &stats.FailedLastHour)
 	if err != nil {
 		return nil, err
 	}
+	if finished := stats.CompletedLastHour + stats.FailedLastHour; finished > 0 {
+		stats.FailureRate = float64(stats.FailedLastHour) / float64(finished)
+	}
 
-	// Oldest queued job age.
-	var age *float64
+	// Oldest queued job age and enqueue time.
 	err = s.pool.QueryRow(ctx,
-		`SELECT EXTRACT(EPOCH FROM NOW() - MIN(run_at))
+		`SELECT EXTRACT(EPOCH FROM NOW() - MIN(run_at)), MIN(created_at)
 		 FROM _ayb_jobs WHERE state = 'queued'`,
-	).Scan(&age)
+	).Scan(&stats.OldestAge, &stats.OldestQueuedAt)
 	if err != nil && err != pgx.ErrNoRows {
 		return nil, err
 	}
-	stats.OldestAge = age
 
 	return &stats, nil
 }
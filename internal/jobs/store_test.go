@@ -317,6 +317,78 @@ func TestListAndStats(t *testing.T) {
 	testutil.Equal(t, 0, stats.Running)
 }
 
+func TestClaimHighestPriorityFirst(t *testing.T) {
+	store := setupDB(t)
+	ctx := context.Background()
+
+	earlier := time.Now().Add(-time.Minute)
+	low, err := store.Enqueue(ctx, "low", nil, jobs.EnqueueOpts{RunAt: &earlier})
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, low.Priority)
+	high, err := store.Enqueue(ctx, "high", nil, jobs.EnqueueOpts{Priority: 5})
+	testutil.NoError(t, err)
+	testutil.Equal(t, 5, high.Priority)
+
+	first, err := store.Claim(ctx, "worker-1", time.Minute)
+	testutil.NoError(t, err)
+	testutil.Equal(t, high.ID, first.ID)
+	second, err := store.Claim(ctx, "worker-1", time.Minute)
+	testutil.NoError(t, err)
+	testutil.Equal(t, low.ID, second.ID)
+}
+
+func TestStatsReflectsJobRows(t *testing.T) {
+	store := setupDB(t)
+	ctx := context.Background()
+
+	empty, err := store.Stats(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, empty.Queued)
+	testutil.True(t, empty.OldestAge == nil, "no queued jobs means no oldest age")
+	testutil.True(t, empty.OldestQueuedAt == nil, "no queued jobs means no oldest enqueue time")
+	testutil.Equal(t, 0.0, empty.FailureRate)
+
+	// Five jobs: one completes, one fails permanently, one is left running
+	// and two stay queued.
+	for i := 0; i < 5; i++ {
+		_, err := store.Enqueue(ctx, "stats_job", nil, jobs.EnqueueOpts{MaxAttempts: 1})
+		testutil.NoError(t, err)
+	}
+	claim := func() *jobs.Job {
+		j, err := store.Claim(ctx, "worker-1", time.Minute)
+		testutil.NoError(t, err)
+		testutil.NotNil(t, j)
+		return j
+	}
+	_, err = store.Complete(ctx, claim().ID)
+	testutil.NoError(t, err)
+	_, err = store.Fail(ctx, claim().ID, "boom", time.Second)
+	testutil.NoError(t, err)
+	claim()
+
+	// Backdate the oldest queued job.
+	_, err = sharedPG.Pool.Exec(ctx, `UPDATE _ayb_jobs SET created_at = NOW() - INTERVAL '10 minutes',
+		run_at = NOW() - INTERVAL '5 minutes'
+		WHERE id = (SELECT id FROM _ayb_jobs WHERE state = 'queued' ORDER BY created_at LIMIT 1)`)
+	testutil.NoError(t, err)
+
+	stats, err := store.Stats(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, stats.Queued)
+	testutil.Equal(t, 1, stats.Running)
+	testutil.Equal(t, 1, stats.Completed)
+	testutil.Equal(t, 1, stats.Failed)
+	testutil.Equal(t, 1, stats.CompletedLastHour)
+	testutil.Equal(t, 1, stats.FailedLastHour)
+	testutil.Equal(t, 0.5, stats.FailureRate)
+	testutil.NotNil(t, stats.OldestAge)
+	testutil.True(t, *stats.OldestAge >= 300, "oldest age should be at least 5 minutes, got %v", *stats.OldestAge)
+	testutil.NotNil(t, stats.OldestQueuedAt)
+	testutil.True(t, time.Since(*stats.OldestQueuedAt) >= 10*time.Minute,
+		"oldest enqueue time should be 10 minutes ago, got %v", *stats.OldestQueuedAt)
+	testutil.Equal(t, 0, stats.Workers) // only the service knows its workers
+}
+
 // --- Schedule Tests ---
 
 func TestScheduleCRUD(t *testing.T) {
@@ -493,6 +565,27 @@ func TestExtendLeaseNonRunningFails(t *testing.T) {
 	testutil.NotNil(t, err)
 }
 
+func TestReleaseRequeuesWithoutUsingAttempt(t *testing.T) {
+	store := setupDB(t)
+	ctx := context.Background()
+
+	job, err := store.Enqueue(ctx, "release_job", nil, jobs.EnqueueOpts{})
+	testutil.NoError(t, err)
+	claimed, err := store.Claim(ctx, "w1", time.Minute)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 1, claimed.Attempts)
+
+	released, err := store.Release(ctx, job.ID)
+	testutil.NoError(t, err)
+	testutil.Equal(t, jobs.StateQueued, released.State)
+	testutil.Equal(t, 0, released.Attempts)
+	testutil.Nil(t, released.LeaseUntil)
+
+	// Only running jobs can be released.
+	_, err = store.Release(ctx, job.ID)
+	testutil.NotNil(t, err)
+}
+
 // --- CHECK Constraint Tests ---
 
 func TestInvalidStateRejected(t *testing.T) {
@@ -0,0 +1,118 @@
+package logfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Filter selects log lines by minimum level and time range. The zero Filter
+// matches everything.
+type Filter struct {
+	level        slog.Level
+	hasLevel     bool
+	since, until time.Time
+}
+
+// ParseFilter parses the level, since and until options of "ayb logs". level
+// is a minimum slog level name (debug, info, warn, error). since and until
+// are RFC3339 times or durations before now, such as "15m". Empty options
+// are unbounded.
+func ParseFilter(level, since, until string, now time.Time) (Filter, error) {
+	var f Filter
+	if level != "" {
+		if err := f.level.UnmarshalText([]byte(level)); err != nil {
+			return Filter{}, fmt.Errorf("invalid level %q: must be one of debug, info, warn, error", level)
+		}
+		f.hasLevel = true
+	}
+	var err error
+	if f.since, err = parseTime("since", since, now); err != nil {
+		return Filter{}, err
+	}
+	if f.until, err = parseTime("until", until, now); err != nil {
+		return Filter{}, err
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && f.until.Before(f.since) {
+		return Filter{}, fmt.Errorf("until %s is before since %s", until, since)
+	}
+	return f, nil
+}
+
+func parseTime(name, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: must be an RFC3339 time or a duration such as 15m", name, value)
+}
+
+// IsZero reports whether f matches every line.
+func (f Filter) IsZero() bool {
+	return !f.hasLevel && f.since.IsZero() && f.until.IsZero()
+}
+
+// Match reports whether a line logged at t with level passes the filter.
+func (f Filter) Match(t time.Time, level slog.Level) bool {
+	if f.hasLevel && level < f.level {
+		return false
+	}
+	if !f.since.IsZero() && t.Before(f.since) {
+		return false
+	}
+	return f.until.IsZero() || !t.After(f.until)
+}
+
+// MatchLine reports whether a JSON or text slog line passes the filter. A
+// line without a parseable time and level, such as a panic trace, passes
+// only the zero Filter.
+func (f Filter) MatchLine(line string) bool {
+	if f.IsZero() {
+		return true
+	}
+	t, level, ok := ParseLine(line)
+	return ok && f.Match(t, level)
+}
+
+// ParseLine returns the time and level of a line written by slog's JSON or
+// text handler.
+func ParseLine(line string) (t time.Time, level slog.Level, ok bool) {
+	var levelName string
+	if strings.HasPrefix(line, "{") {
+		var rec struct {
+			Time  time.Time `json:"time"`
+			Level string    `json:"level"`
+		}
+		if json.Unmarshal([]byte(line), &rec) != nil {
+			return time.Time{}, 0, false
+		}
+		t, levelName = rec.Time, rec.Level
+	} else {
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, textField(line, "time")); err != nil {
+			return time.Time{}, 0, false
+		}
+		levelName = textField(line, "level")
+	}
+	if t.IsZero() || level.UnmarshalText([]byte(levelName)) != nil {
+		return time.Time{}, 0, false
+	}
+	return t, level, true
+}
+
+// textField returns the unquoted value of key in a slog text line.
+func textField(line, key string) string {
+	for field := range strings.FieldsSeq(line) {
+		if v, ok := strings.CutPrefix(field, key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
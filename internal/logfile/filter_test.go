@@ -0,0 +1,76 @@
+package logfile
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	f, err := ParseFilter("WARN", "1h", "2026-01-02T14:30:00Z", now)
+	testutil.NoError(t, err)
+	testutil.False(t, f.IsZero(), "filter should not be zero")
+	testutil.True(t, f.Match(now.Add(-45*time.Minute), slog.LevelError), "error inside range should match")
+	testutil.False(t, f.Match(now.Add(-45*time.Minute), slog.LevelInfo), "info is below the level")
+	testutil.False(t, f.Match(now.Add(-61*time.Minute), slog.LevelError), "before since")
+	testutil.False(t, f.Match(now.Add(-29*time.Minute), slog.LevelError), "after until")
+
+	f, err = ParseFilter("", "", "", now)
+	testutil.NoError(t, err)
+	testutil.True(t, f.IsZero(), "empty options should give the zero filter")
+	testutil.True(t, f.Match(time.Time{}, slog.LevelDebug), "zero filter matches everything")
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	tests := []struct {
+		level, since, until string
+		wantErr             string
+	}{
+		{"loud", "", "", "invalid level"},
+		{"", "yesterday", "", "invalid since"},
+		{"", "", "-5m", "invalid until"},
+		{"", "5m", "1h", "is before since"},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.level, tt.since, tt.until, now)
+		testutil.ErrorContains(t, err, tt.wantErr)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+	want := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	ts, level, ok := ParseLine(`{"time":"2026-01-02T15:04:05Z","level":"WARN","msg":"x"}`)
+	testutil.True(t, ok, "JSON line should parse")
+	testutil.True(t, ts.Equal(want), "time = %s", ts)
+	testutil.Equal(t, slog.LevelWarn, level)
+
+	ts, level, ok = ParseLine(`time=2026-01-02T15:04:05.000Z level=ERROR msg="x y" k=v`)
+	testutil.True(t, ok, "text line should parse")
+	testutil.True(t, ts.Equal(want), "time = %s", ts)
+	testutil.Equal(t, slog.LevelError, level)
+
+	for _, line := range []string{"panic: boom", `{"msg":"no level"}`, "{not json"} {
+		_, _, ok = ParseLine(line)
+		testutil.False(t, ok, "%q should not parse", line)
+	}
+}
+
+func TestFilterMatchLine(t *testing.T) {
+	t.Parallel()
+	testutil.True(t, Filter{}.MatchLine("panic: boom"), "zero filter keeps unparseable lines")
+
+	f, err := ParseFilter("info", "", "", time.Now())
+	testutil.NoError(t, err)
+	testutil.False(t, f.MatchLine("panic: boom"), "a filter drops unparseable lines")
+	testutil.True(t, f.MatchLine(`{"time":"2026-01-02T15:04:05Z","level":"INFO","msg":"x"}`), "info line should match")
+	testutil.False(t, f.MatchLine(`{"time":"2026-01-02T15:04:05Z","level":"DEBUG","msg":"x"}`), "debug line should not match")
+}
@@ -0,0 +1,206 @@
+// Package logfile writes log files that rotate by size and age, and filters
+// the slog lines read back from them.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is appended to the log file name of a rotated file. It
+// sorts lexically in time order.
+const backupTimeFormat = "20060102T150405.000"
+
+// Options controls rotation. A zero limit disables it.
+type Options struct {
+	MaxSize    int64         // rotate before a write would take the file past this many bytes
+	MaxAge     time.Duration // rotate once the file has been written to for this long
+	MaxBackups int           // rotated files to keep; older ones are deleted
+}
+
+// Writer is an io.WriteCloser that appends to a log file and rotates it. A
+// rotated file is renamed to "<path>.<timestamp>". Writes are serialized, so
+// a Writer can be shared by concurrent loggers and a line is never split
+// across files.
+type Writer struct {
+	path string
+	opts Options
+	now  func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	started time.Time // when the current file began receiving writes
+}
+
+// Open opens or creates the log file at path for appending.
+func Open(path string, opts Options) (*Writer, error) {
+	w := &Writer{path: filepath.Clean(path), opts: opts, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	// The current file began at the last rotation, so age survives restarts.
+	w.started = w.now()
+	if backups, err := w.backups(); err == nil && len(backups) > 0 {
+		if t, ok := w.backupTime(backups[len(backups)-1]); ok {
+			w.started = t
+		}
+	}
+	return w, nil
+}
+
+// Path returns the path of the current log file.
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Write appends p to the log file, rotating first if p would exceed MaxSize
+// or the file is older than MaxAge.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the log file regardless of the limits.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the log file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *Writer) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.opts.MaxSize > 0 && w.size+n > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.MaxAge > 0 && w.now().Sub(w.started) >= w.opts.MaxAge
+}
+
+// rotate renames the current file aside, opens a fresh one, and prunes old
+// backups. w.mu must be held.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	now := w.now()
+	backup := w.backupName(now)
+	if err := os.Rename(w.path, backup); err != nil {
+		// Keep logging to the current file rather than losing lines.
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.started = now
+	return w.prune()
+}
+
+// backupName returns the name for a file rotated at t, moved past the newest
+// existing backup so that names stay unique and in rotation order.
+func (w *Writer) backupName(t time.Time) string {
+	t = t.UTC().Truncate(time.Millisecond)
+	if backups, err := w.backups(); err == nil && len(backups) > 0 {
+		if last, ok := w.backupTime(backups[len(backups)-1]); ok && !t.After(last) {
+			t = last.Add(time.Millisecond)
+		}
+	}
+	return w.path + "." + t.Format(backupTimeFormat)
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// prune deletes all but the MaxBackups most recent rotated files.
+func (w *Writer) prune() error {
+	if w.opts.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	for _, name := range backups[:max(len(backups)-w.opts.MaxBackups, 0)] {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing old log file: %w", err)
+		}
+	}
+	return nil
+}
+
+// backups returns the paths of rotated files, oldest first.
+func (w *Writer) backups() ([]string, error) {
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, e := range entries {
+		name := filepath.Join(dir, e.Name())
+		if _, ok := w.backupTime(name); ok && !e.IsDir() {
+			backups = append(backups, name)
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+func (w *Writer) backupTime(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, w.path+".")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat, suffix)
+	return t, err == nil
+}
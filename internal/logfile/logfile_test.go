@@ -0,0 +1,161 @@
+package logfile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func readLines(t *testing.T, paths ...string) []string {
+	t.Helper()
+	var lines []string
+	for _, p := range paths {
+		f, err := os.Open(p)
+		testutil.NoError(t, err)
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		f.Close()
+	}
+	return lines
+}
+
+func TestWriterRotatesBySize(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := Open(path, Options{MaxSize: 10})
+	testutil.NoError(t, err)
+	defer w.Close()
+
+	for _, line := range []string{"aaaaaaa\n", "bbbbbbb\n", "ccccccc\n"} {
+		_, err := w.Write([]byte(line))
+		testutil.NoError(t, err)
+	}
+
+	backups, err := w.backups()
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, backups, 2)
+	testutil.Equal(t, "aaaaaaa\n", string(mustRead(t, backups[0])))
+	testutil.Equal(t, "bbbbbbb\n", string(mustRead(t, backups[1])))
+	testutil.Equal(t, "ccccccc\n", string(mustRead(t, path)))
+}
+
+func TestWriterRotatesByAge(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := Open(path, Options{MaxAge: time.Hour})
+	testutil.NoError(t, err)
+	defer w.Close()
+	now := time.Now()
+	w.now = func() time.Time { return now }
+	w.started = now
+
+	_, err = w.Write([]byte("old\n"))
+	testutil.NoError(t, err)
+	now = now.Add(59 * time.Minute)
+	_, err = w.Write([]byte("still current\n"))
+	testutil.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = w.Write([]byte("new\n"))
+	testutil.NoError(t, err)
+
+	backups, err := w.backups()
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, backups, 1)
+	testutil.Equal(t, "old\nstill current\n", string(mustRead(t, backups[0])))
+	testutil.Equal(t, "new\n", string(mustRead(t, path)))
+}
+
+func TestWriterKeepsMaxBackups(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := Open(path, Options{MaxBackups: 2})
+	testutil.NoError(t, err)
+	defer w.Close()
+
+	for i := range 5 {
+		_, err := fmt.Fprintf(w, "line %d\n", i)
+		testutil.NoError(t, err)
+		testutil.NoError(t, w.Rotate())
+	}
+
+	backups, err := w.backups()
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, backups, 2)
+	testutil.Equal(t, "line 3\n", string(mustRead(t, backups[0])))
+	testutil.Equal(t, "line 4\n", string(mustRead(t, backups[1])))
+}
+
+func TestWriterAgeSurvivesReopen(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := Open(path, Options{})
+	testutil.NoError(t, err)
+	_, err = w.Write([]byte("x\n"))
+	testutil.NoError(t, err)
+	rotatedAt := time.Now().Add(-2 * time.Hour)
+	w.now = func() time.Time { return rotatedAt }
+	testutil.NoError(t, w.Rotate())
+	testutil.NoError(t, w.Close())
+
+	w, err = Open(path, Options{MaxAge: time.Hour})
+	testutil.NoError(t, err)
+	defer w.Close()
+	testutil.Equal(t, rotatedAt.UTC().Truncate(time.Millisecond), w.started)
+}
+
+func TestWriterConcurrentWritesDuringRotation(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ayb.log")
+	w, err := Open(path, Options{MaxSize: 512})
+	testutil.NoError(t, err)
+
+	const writers, perWriter = 8, 200
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range perWriter {
+				fmt.Fprintf(w, "writer=%d line=%04d\n", i, j)
+			}
+		}()
+	}
+	wg.Wait()
+	testutil.NoError(t, w.Close())
+
+	backups, err := w.backups()
+	testutil.NoError(t, err)
+	testutil.True(t, len(backups) > 1, "expected rotations, got %d backups", len(backups))
+	lines := readLines(t, append(backups, path)...)
+	testutil.Equal(t, writers*perWriter, len(lines))
+	for _, line := range lines {
+		testutil.True(t, strings.HasPrefix(line, "writer=") && len(line) == len("writer=0 line=0000"),
+			"torn line %q", line)
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+	w, err := Open(filepath.Join(t.TempDir(), "ayb.log"), Options{})
+	testutil.NoError(t, err)
+	testutil.NoError(t, w.Close())
+	testutil.NoError(t, w.Close())
+	_, err = w.Write([]byte("x"))
+	testutil.ErrorContains(t, err, "closed")
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	return data
+}
@@ -164,6 +164,32 @@ func TestRenderMagicLink(t *testing.T) {
 	testutil.True(t, len(text) > 0, "text fallback should not be empty")
 }
 
+func TestRenderEmailChange(t *testing.T) {
+	t.Parallel()
+	html, text, err := RenderEmailChange(TemplateData{
+		AppName:   "MyApp",
+		ActionURL: "https://example.com/auth/email-change/confirm?token=tok123",
+	})
+	testutil.NoError(t, err)
+	testutil.Contains(t, html, "Confirm your new email")
+	testutil.Contains(t, html, "MyApp")
+	testutil.Contains(t, html, "https://example.com/auth/email-change/confirm?token=tok123")
+	testutil.Contains(t, text, "Confirm your new email")
+}
+
+func TestRenderRegistrationAttempt(t *testing.T) {
+	t.Parallel()
+	html, text, err := RenderRegistrationAttempt(TemplateData{
+		AppName:   "MyApp",
+		ActionURL: "https://example.com/auth/password-reset/confirm?token=tok123",
+	})
+	testutil.NoError(t, err)
+	testutil.Contains(t, html, "Someone tried to sign up with your email")
+	testutil.Contains(t, html, "MyApp")
+	testutil.Contains(t, html, "https://example.com/auth/password-reset/confirm?token=tok123")
+	testutil.Contains(t, text, "Someone tried to sign up with your email")
+}
+
 func TestStripHTML(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
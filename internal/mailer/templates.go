@@ -38,6 +38,19 @@ func RenderVerification(data TemplateData) (html string, text string, err error)
 	return render("verification.html", data)
 }
 
+// RenderEmailChange renders the email sent to a new address to confirm an
+// email change and returns HTML and plain text.
+func RenderEmailChange(data TemplateData) (html string, text string, err error) {
+	return render("email_change.html", data)
+}
+
+// RenderRegistrationAttempt renders the email sent to an existing account
+// when someone tries to register with its address, and returns HTML and
+// plain text.
+func RenderRegistrationAttempt(data TemplateData) (html string, text string, err error) {
+	return render("registration_attempt.html", data)
+}
+
 func render(name string, data TemplateData) (string, string, error) {
 	var buf bytes.Buffer
 	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
@@ -51,13 +64,16 @@ func render(name string, data TemplateData) (string, string, error) {
 
 // Default subjects for system email templates.
 const (
-	DefaultPasswordResetSubject  = "Reset your password"
-	DefaultVerificationSubject   = "Verify your email"
-	DefaultMagicLinkSubject      = "Your login link"
+	DefaultPasswordResetSubject       = "Reset your password"
+	DefaultVerificationSubject        = "Verify your email"
+	DefaultMagicLinkSubject           = "Your login link"
+	DefaultEmailChangeSubject         = "Confirm your new email"
+	DefaultRegistrationAttemptSubject = "Someone tried to sign up with your email"
 )
 
 // BuiltinHTMLTemplate returns the raw HTML source for a built-in template.
-// Valid names: "password_reset.html", "verification.html", "magic_link.html".
+// Valid names: "password_reset.html", "verification.html", "magic_link.html",
+// "email_change.html", "registration_attempt.html".
 func BuiltinHTMLTemplate(name string) (string, error) {
 	b, err := templateFS.ReadFile("templates/" + name)
 	if err != nil {
@@ -10,10 +10,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 
+	"github.com/allyourbase/ayb/internal/schema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -25,6 +28,14 @@ type Config struct {
 	AdminToken string
 	// UserToken is a user JWT for RLS-filtered data access.
 	UserToken string
+	// AllowWrites registers the mutation tools. They are only offered for
+	// the tables in WriteTables.
+	AllowWrites bool
+	// WriteTables lists the tables mutation tools may change; "*" allows
+	// every table and also enables apply_migration.
+	WriteTables []string
+	// AuditLog records every mutation an agent makes. Nil discards them.
+	AuditLog *slog.Logger
 }
 
 // apiClient wraps HTTP calls to the AYB REST API.
@@ -32,20 +43,31 @@ type apiClient struct {
 	baseURL    string
 	adminToken string
 	userToken  string
+	writes     *writePolicy
+	audit      *slog.Logger
 	http       *http.Client
 }
 
 func newClient(cfg Config) *apiClient {
+	audit := cfg.AuditLog
+	if audit == nil {
+		audit = slog.New(slog.DiscardHandler)
+	}
 	return &apiClient{
 		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
 		adminToken: cfg.AdminToken,
 		userToken:  cfg.UserToken,
+		writes:     newWritePolicy(cfg),
+		audit:      audit,
 		http:       &http.Client{},
 	}
 }
 
-// doJSON makes an HTTP request and returns the parsed JSON response.
-func (c *apiClient) doJSON(ctx context.Context, method, path string, body any, admin bool) (map[string]any, int, error) {
+// do makes an HTTP request and returns the status and raw response body.
+// Admin requests carry the admin token. Other requests carry the user token,
+// or the admin token when no user token is configured so an admin-only setup
+// can still read the schema and records.
+func (c *apiClient) do(ctx context.Context, method, path string, body any, admin bool) ([]byte, int, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
@@ -64,7 +86,7 @@ func (c *apiClient) doJSON(ctx context.Context, method, path string, body any, a
 	}
 
 	token := c.userToken
-	if admin {
+	if admin || token == "" {
 		token = c.adminToken
 	}
 	if token != "" {
@@ -81,26 +103,63 @@ func (c *apiClient) doJSON(ctx context.Context, method, path string, body any, a
 	if err != nil {
 		return nil, resp.StatusCode, fmt.Errorf("read response: %w", err)
 	}
+	return respBody, resp.StatusCode, nil
+}
+
+// doJSON makes an HTTP request and returns the parsed JSON response.
+func (c *apiClient) doJSON(ctx context.Context, method, path string, body any, admin bool) (map[string]any, int, error) {
+	respBody, status, err := c.do(ctx, method, path, body, admin)
+	if err != nil {
+		return nil, status, err
+	}
 
 	if len(respBody) == 0 {
-		return nil, resp.StatusCode, nil
+		return nil, status, nil
 	}
 
 	var result map[string]any
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		// Return raw text for non-JSON responses
-		return map[string]any{"raw": string(respBody)}, resp.StatusCode, nil
+		return map[string]any{"raw": string(respBody)}, status, nil
 	}
 
-	if resp.StatusCode >= 400 {
-		msg := "unknown error"
-		if m, ok := result["message"].(string); ok {
-			msg = m
-		}
-		return result, resp.StatusCode, fmt.Errorf("AYB error (%d): %s", resp.StatusCode, msg)
+	if status >= 400 {
+		return result, status, apiError(status, result)
 	}
 
-	return result, resp.StatusCode, nil
+	return result, status, nil
+}
+
+// getSchema fetches the server's schema cache.
+func (c *apiClient) getSchema(ctx context.Context) (*schema.SchemaCache, error) {
+	respBody, status, err := c.do(ctx, "GET", "/api/schema", nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 400 {
+		var result map[string]any
+		_ = json.Unmarshal(respBody, &result)
+		return nil, apiError(status, result)
+	}
+
+	var sc schema.SchemaCache
+	if err := json.Unmarshal(respBody, &sc); err != nil {
+		return nil, fmt.Errorf("decode schema: %w", err)
+	}
+	return &sc, nil
+}
+
+// apiError builds the error for a failed request from the message of the
+// response's error envelope, or the top-level message older servers send.
+func apiError(status int, result map[string]any) error {
+	msg := "unknown error"
+	if e, ok := result["error"].(map[string]any); ok {
+		result = e
+	}
+	if m, ok := result["message"].(string); ok {
+		msg = m
+	}
+	return fmt.Errorf("AYB error (%d): %s", status, msg)
 }
 
 // NewServer creates a new MCP server wired to an AYB instance.
@@ -127,34 +186,49 @@ func NewServer(cfg Config) *mcp.Server {
 
 type ListTablesInput struct{}
 type ListTablesOutput struct {
-	Tables []map[string]any `json:"tables"`
+	Tables []TableSummary `json:"tables"`
 }
 
-type DescribeTableInput struct {
-	Table string `json:"table" jsonschema:"Table name to describe"`
+// TableSummary is a table or view as listed by list_tables.
+type TableSummary struct {
+	Schema  string          `json:"schema"`
+	Name    string          `json:"name"`
+	Kind    string          `json:"kind" jsonschema:"table, view, materialized_view or partitioned_table"`
+	Comment string          `json:"comment,omitempty"`
+	Columns []ColumnSummary `json:"columns"`
 }
-type DescribeTableOutput struct {
-	Name    string           `json:"name"`
-	Columns []map[string]any `json:"columns"`
-	PKs     []string         `json:"primary_keys"`
-	FKs     []map[string]any `json:"foreign_keys"`
+
+// ColumnSummary is a column as listed by list_tables.
+type ColumnSummary struct {
+	Name       string `json:"name"`
+	Type       string `json:"type" jsonschema:"PostgreSQL type"`
+	Nullable   bool   `json:"nullable"`
+	PrimaryKey bool   `json:"primaryKey,omitempty"`
+}
+
+type DescribeTableInput struct {
+	Table string `json:"table" jsonschema:"Table name, optionally schema-qualified (e.g. posts or audit.events)"`
 }
 
+// DescribeTableOutput is the table's entry in the schema cache.
+type DescribeTableOutput schema.Table
+
 type ListFunctionsInput struct{}
 type ListFunctionsOutput struct {
-	Functions []map[string]any `json:"functions"`
+	Functions []*schema.Function `json:"functions"`
 }
 
-type QueryRecordsInput struct {
-	Table   string `json:"table" jsonschema:"Table name"`
-	Filter  string `json:"filter,omitempty" jsonschema:"Filter expression (e.g. status='active' AND age>21)"`
-	Sort    string `json:"sort,omitempty" jsonschema:"Sort fields (e.g. -created_at,+title)"`
-	Page    int    `json:"page,omitempty" jsonschema:"Page number (default 1)"`
-	PerPage int    `json:"perPage,omitempty" jsonschema:"Items per page (default 20, max 500)"`
-	Expand  string `json:"expand,omitempty" jsonschema:"FK relationships to expand"`
-	Search  string `json:"search,omitempty" jsonschema:"Full-text search query"`
+type QueryCollectionInput struct {
+	Table  string `json:"table" jsonschema:"Table name"`
+	Filter string `json:"filter,omitempty" jsonschema:"Filter expression (e.g. status='active' AND age>21)"`
+	Sort   string `json:"sort,omitempty" jsonschema:"Sort fields (e.g. -created_at,+title)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum records to return (default 20, max 500)"`
+	Page   int    `json:"page,omitempty" jsonschema:"Page number of limit-sized pages (default 1)"`
+	Fields string `json:"fields,omitempty" jsonschema:"Comma-separated columns to return (default all)"`
+	Expand string `json:"expand,omitempty" jsonschema:"FK relationships to expand"`
+	Search string `json:"search,omitempty" jsonschema:"Full-text search query"`
 }
-type QueryRecordsOutput struct {
+type QueryCollectionOutput struct {
 	Items      []map[string]any `json:"items"`
 	Page       int              `json:"page"`
 	PerPage    int              `json:"perPage"`
@@ -168,25 +242,6 @@ type GetRecordInput struct {
 	Expand string `json:"expand,omitempty" jsonschema:"FK relationships to expand"`
 }
 
-type CreateRecordInput struct {
-	Table string         `json:"table" jsonschema:"Table name"`
-	Data  map[string]any `json:"data" jsonschema:"Record data as key-value pairs"`
-}
-
-type UpdateRecordInput struct {
-	Table string         `json:"table" jsonschema:"Table name"`
-	ID    string         `json:"id" jsonschema:"Record ID"`
-	Data  map[string]any `json:"data" jsonschema:"Fields to update as key-value pairs"`
-}
-
-type DeleteRecordInput struct {
-	Table string `json:"table" jsonschema:"Table name"`
-	ID    string `json:"id" jsonschema:"Record ID"`
-}
-type DeleteRecordOutput struct {
-	Deleted bool `json:"deleted"`
-}
-
 type RunSQLInput struct {
 	Query string `json:"query" jsonschema:"SQL query to execute"`
 }
@@ -222,14 +277,14 @@ func registerTools(s *mcp.Server, c *apiClient) {
 	// Schema tools
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "list_tables",
-		Description: "List all database tables with their columns, types, and row counts",
+		Description: "List all database tables and views with their columns and types",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, in ListTablesInput) (*mcp.CallToolResult, ListTablesOutput, error) {
 		return handleListTables(ctx, c)
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
 		Name:        "describe_table",
-		Description: "Get detailed structure of a table: columns, types, primary keys, foreign keys, and indexes",
+		Description: "Get detailed structure of a table: columns, types, primary keys, foreign keys, indexes, and relationships",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, in DescribeTableInput) (*mcp.CallToolResult, DescribeTableOutput, error) {
 		return handleDescribeTable(ctx, c, in)
 	})
@@ -243,10 +298,10 @@ func registerTools(s *mcp.Server, c *apiClient) {
 
 	// Data tools
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "query_records",
-		Description: "List records from a table with optional filter, sort, pagination, search, and FK expansion",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, in QueryRecordsInput) (*mcp.CallToolResult, QueryRecordsOutput, error) {
-		return handleQueryRecords(ctx, c, in)
+		Name:        "query_collection",
+		Description: "List records from a table with optional filter, sort, limit, field selection, search, and FK expansion",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in QueryCollectionInput) (*mcp.CallToolResult, QueryCollectionOutput, error) {
+		return handleQueryCollection(ctx, c, in)
 	})
 
 	mcp.AddTool(s, &mcp.Tool{
@@ -256,31 +311,11 @@ func registerTools(s *mcp.Server, c *apiClient) {
 		return handleGetRecord(ctx, c, in)
 	})
 
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "create_record",
-		Description: "Insert a new record into a table",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, in CreateRecordInput) (*mcp.CallToolResult, RecordOutput, error) {
-		return handleCreateRecord(ctx, c, in)
-	})
-
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "update_record",
-		Description: "Partially update a record by ID",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, in UpdateRecordInput) (*mcp.CallToolResult, RecordOutput, error) {
-		return handleUpdateRecord(ctx, c, in)
-	})
-
-	mcp.AddTool(s, &mcp.Tool{
-		Name:        "delete_record",
-		Description: "Delete a record by ID",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, in DeleteRecordInput) (*mcp.CallToolResult, DeleteRecordOutput, error) {
-		return handleDeleteRecord(ctx, c, in)
-	})
-
 	// SQL tool
 	mcp.AddTool(s, &mcp.Tool{
-		Name:        "run_sql",
-		Description: "Execute arbitrary SQL against the database (requires admin token)",
+		Name: "run_sql",
+		Description: "Execute SQL against the database (requires the admin token; bypasses row-level security). " +
+			"Runs a single statement in a read-only transaction unless writes are allowed to every table",
 	}, func(ctx context.Context, req *mcp.CallToolRequest, in RunSQLInput) (*mcp.CallToolResult, RunSQLOutput, error) {
 		return handleRunSQL(ctx, c, in)
 	})
@@ -300,88 +335,80 @@ func registerTools(s *mcp.Server, c *apiClient) {
 	}, func(ctx context.Context, req *mcp.CallToolRequest, in GetStatusInput) (*mcp.CallToolResult, GetStatusOutput, error) {
 		return handleGetStatus(ctx, c)
 	})
+
+	if c.writes != nil {
+		registerWriteTools(s, c)
+	}
 }
 
 // --- Tool handlers ---
 
 func handleListTables(ctx context.Context, c *apiClient) (*mcp.CallToolResult, ListTablesOutput, error) {
-	result, _, err := c.doJSON(ctx, "GET", "/api/schema", nil, false)
+	sc, err := c.getSchema(ctx)
 	if err != nil {
 		return nil, ListTablesOutput{}, err
 	}
 
-	tables, _ := result["tables"].([]any)
-	out := ListTablesOutput{Tables: make([]map[string]any, 0, len(tables))}
+	tables := sc.TableList()
+	out := ListTablesOutput{Tables: make([]TableSummary, 0, len(tables))}
 	for _, t := range tables {
-		if tMap, ok := t.(map[string]any); ok {
-			out.Tables = append(out.Tables, tMap)
+		summary := TableSummary{
+			Schema:  t.Schema,
+			Name:    t.Name,
+			Kind:    t.Kind,
+			Comment: t.Comment,
+			Columns: make([]ColumnSummary, 0, len(t.Columns)),
+		}
+		for _, col := range t.Columns {
+			summary.Columns = append(summary.Columns, ColumnSummary{
+				Name:       col.Name,
+				Type:       col.TypeName,
+				Nullable:   col.IsNullable,
+				PrimaryKey: col.IsPrimaryKey,
+			})
 		}
+		out.Tables = append(out.Tables, summary)
 	}
 	return nil, out, nil
 }
 
 func handleDescribeTable(ctx context.Context, c *apiClient, in DescribeTableInput) (*mcp.CallToolResult, DescribeTableOutput, error) {
-	result, _, err := c.doJSON(ctx, "GET", "/api/schema", nil, false)
+	sc, err := c.getSchema(ctx)
 	if err != nil {
 		return nil, DescribeTableOutput{}, err
 	}
 
-	tables, _ := result["tables"].([]any)
-	for _, t := range tables {
-		tMap, ok := t.(map[string]any)
-		if !ok {
-			continue
-		}
-		name, _ := tMap["name"].(string)
-		if name != in.Table {
-			continue
-		}
-
-		out := DescribeTableOutput{Name: name}
-		if cols, ok := tMap["columns"].([]any); ok {
-			for _, col := range cols {
-				if colMap, ok := col.(map[string]any); ok {
-					out.Columns = append(out.Columns, colMap)
-				}
-			}
-		}
-		if pks, ok := tMap["primary_keys"].([]any); ok {
-			for _, pk := range pks {
-				if s, ok := pk.(string); ok {
-					out.PKs = append(out.PKs, s)
-				}
-			}
-		}
-		if fks, ok := tMap["foreign_keys"].([]any); ok {
-			for _, fk := range fks {
-				if fkMap, ok := fk.(map[string]any); ok {
-					out.FKs = append(out.FKs, fkMap)
-				}
-			}
-		}
-		return nil, out, nil
+	t, ok := sc.Tables[in.Table]
+	if !ok {
+		t = sc.TableByName(in.Table)
 	}
-
-	return nil, DescribeTableOutput{}, fmt.Errorf("table %q not found", in.Table)
+	if t == nil {
+		return nil, DescribeTableOutput{}, fmt.Errorf("table %q not found", in.Table)
+	}
+	return nil, DescribeTableOutput(*t), nil
 }
 
 func handleListFunctions(ctx context.Context, c *apiClient) (*mcp.CallToolResult, ListFunctionsOutput, error) {
-	result, _, err := c.doJSON(ctx, "GET", "/api/schema", nil, false)
+	sc, err := c.getSchema(ctx)
 	if err != nil {
 		return nil, ListFunctionsOutput{}, err
 	}
 
-	functions, _ := result["functions"].([]any)
-	out := ListFunctionsOutput{Functions: make([]map[string]any, 0, len(functions))}
-	for _, f := range functions {
-		if fMap, ok := f.(map[string]any); ok {
-			out.Functions = append(out.Functions, fMap)
-		}
+	out := ListFunctionsOutput{Functions: make([]*schema.Function, 0, len(sc.Functions))}
+	for _, f := range sc.Functions {
+		out.Functions = append(out.Functions, f)
 	}
+	sort.Slice(out.Functions, func(i, j int) bool {
+		a, b := out.Functions[i], out.Functions[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
 	return nil, out, nil
 }
 
-func handleQueryRecords(ctx context.Context, c *apiClient, in QueryRecordsInput) (*mcp.CallToolResult, QueryRecordsOutput, error) {
+func handleQueryCollection(ctx context.Context, c *apiClient, in QueryCollectionInput) (*mcp.CallToolResult, QueryCollectionOutput, error) {
 	params := url.Values{}
 	if in.Filter != "" {
 		params.Set("filter", in.Filter)
@@ -392,8 +419,11 @@ func handleQueryRecords(ctx context.Context, c *apiClient, in QueryRecordsInput)
 	if in.Page > 0 {
 		params.Set("page", fmt.Sprintf("%d", in.Page))
 	}
-	if in.PerPage > 0 {
-		params.Set("perPage", fmt.Sprintf("%d", in.PerPage))
+	if in.Limit > 0 {
+		params.Set("perPage", fmt.Sprintf("%d", in.Limit))
+	}
+	if in.Fields != "" {
+		params.Set("fields", in.Fields)
 	}
 	if in.Expand != "" {
 		params.Set("expand", in.Expand)
@@ -409,10 +439,10 @@ func handleQueryRecords(ctx context.Context, c *apiClient, in QueryRecordsInput)
 
 	result, _, err := c.doJSON(ctx, "GET", path, nil, false)
 	if err != nil {
-		return nil, QueryRecordsOutput{}, err
+		return nil, QueryCollectionOutput{}, err
 	}
 
-	out := QueryRecordsOutput{}
+	out := QueryCollectionOutput{Items: []map[string]any{}}
 	if items, ok := result["items"].([]any); ok {
 		for _, item := range items {
 			if m, ok := item.(map[string]any); ok {
@@ -448,35 +478,23 @@ func handleGetRecord(ctx context.Context, c *apiClient, in GetRecordInput) (*mcp
 	return nil, RecordOutput{Record: result}, nil
 }
 
-func handleCreateRecord(ctx context.Context, c *apiClient, in CreateRecordInput) (*mcp.CallToolResult, RecordOutput, error) {
-	path := "/api/collections/" + url.PathEscape(in.Table)
-	result, _, err := c.doJSON(ctx, "POST", path, in.Data, false)
-	if err != nil {
-		return nil, RecordOutput{}, err
-	}
-	return nil, RecordOutput{Record: result}, nil
-}
-
-func handleUpdateRecord(ctx context.Context, c *apiClient, in UpdateRecordInput) (*mcp.CallToolResult, RecordOutput, error) {
-	path := "/api/collections/" + url.PathEscape(in.Table) + "/" + url.PathEscape(in.ID)
-	result, _, err := c.doJSON(ctx, "PATCH", path, in.Data, false)
-	if err != nil {
-		return nil, RecordOutput{}, err
+func handleRunSQL(ctx context.Context, c *apiClient, in RunSQLInput) (_ *mcp.CallToolResult, _ RunSQLOutput, err error) {
+	// SQL isn't scoped to a table, so it may only write when every table
+	// is writable.
+	readOnly := !c.writes.unrestricted()
+	if !readOnly {
+		defer func() { c.auditMutation(ctx, "run_sql", err, "sql", in.Query) }()
 	}
-	return nil, RecordOutput{Record: result}, nil
-}
 
-func handleDeleteRecord(ctx context.Context, c *apiClient, in DeleteRecordInput) (*mcp.CallToolResult, DeleteRecordOutput, error) {
-	path := "/api/collections/" + url.PathEscape(in.Table) + "/" + url.PathEscape(in.ID)
-	_, status, err := c.doJSON(ctx, "DELETE", path, nil, false)
-	if err != nil {
-		return nil, DeleteRecordOutput{}, err
+	// Raw SQL bypasses row-level security, so it is never sent with a
+	// user token.
+	if c.adminToken == "" {
+		return nil, RunSQLOutput{}, fmt.Errorf("run_sql requires an admin token: pass --admin-token to ayb mcp or set AYB_ADMIN_TOKEN")
 	}
-	return nil, DeleteRecordOutput{Deleted: status == http.StatusNoContent}, nil
-}
-
-func handleRunSQL(ctx context.Context, c *apiClient, in RunSQLInput) (*mcp.CallToolResult, RunSQLOutput, error) {
-	result, _, err := c.doJSON(ctx, "POST", "/api/admin/sql", map[string]string{"query": in.Query}, true)
+	result, _, err := c.doJSON(ctx, "POST", "/api/admin/sql", map[string]any{
+		"query":    in.Query,
+		"readOnly": readOnly,
+	}, true)
 	if err != nil {
 		return nil, RunSQLOutput{}, err
 	}
@@ -596,7 +614,7 @@ func registerPrompts(s *mcp.Server) {
 				Content: &mcp.TextContent{
 					Text: fmt.Sprintf(
 						"Describe the structure of the %q table. First use describe_table to get the schema, "+
-							"then use query_records to show 5 sample rows. Summarize the table's purpose, "+
+							"then use query_collection with a limit of 5 to show sample rows. Summarize the table's purpose, "+
 							"column types, relationships, and any notable patterns in the data.", table),
 				},
 			}},
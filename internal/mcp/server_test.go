@@ -5,12 +5,52 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/allyourbase/ayb/internal/schema"
 	"github.com/allyourbase/ayb/internal/testutil"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// fakeSchema is served as the /api/schema response.
+var fakeSchema = &schema.SchemaCache{
+	Tables: map[string]*schema.Table{
+		"public.posts": {
+			Schema: "public", Name: "posts", Kind: "table",
+			Columns: []*schema.Column{
+				{Name: "id", TypeName: "integer", IsPrimaryKey: true, JSONType: "integer"},
+				{Name: "title", TypeName: "text", JSONType: "string"},
+				{Name: "body", TypeName: "text", IsNullable: true, JSONType: "string"},
+				{Name: "published", TypeName: "boolean", JSONType: "boolean"},
+				{Name: "author_id", TypeName: "integer", IsNullable: true, JSONType: "integer"},
+			},
+			PrimaryKey: []string{"id"},
+			ForeignKeys: []*schema.ForeignKey{{
+				ConstraintName: "posts_author_id_fkey", Columns: []string{"author_id"},
+				ReferencedSchema: "public", ReferencedTable: "authors", ReferencedColumns: []string{"id"},
+			}},
+			Indexes: []*schema.Index{{Name: "posts_pkey", IsUnique: true, IsPrimary: true, Columns: []string{"id"}}},
+		},
+		"public.authors": {
+			Schema: "public", Name: "authors", Kind: "table",
+			Columns: []*schema.Column{
+				{Name: "id", TypeName: "integer", IsPrimaryKey: true, JSONType: "integer"},
+				{Name: "name", TypeName: "text", JSONType: "string"},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		"audit.events": {
+			Schema: "audit", Name: "events", Kind: "view",
+			Columns: []*schema.Column{{Name: "id", TypeName: "bigint", JSONType: "integer"}},
+		},
+	},
+	Functions: map[string]*schema.Function{
+		"public.get_post_count": {Schema: "public", Name: "get_post_count", ReturnType: "integer"},
+	},
+	Schemas: []string{"audit", "public"},
+}
+
 // fakeAYB sets up a test HTTP server that mimics the AYB REST API.
 func fakeAYB(t *testing.T) *httptest.Server {
 	t.Helper()
@@ -25,35 +65,7 @@ func fakeAYB(t *testing.T) *httptest.Server {
 			json.NewEncoder(w).Encode(map[string]any{"auth": true})
 
 		case r.URL.Path == "/api/schema":
-			json.NewEncoder(w).Encode(map[string]any{
-				"tables": []any{
-					map[string]any{
-						"name": "posts",
-						"columns": []any{
-							map[string]any{"name": "id", "type": "integer", "nullable": false},
-							map[string]any{"name": "title", "type": "text", "nullable": false},
-							map[string]any{"name": "body", "type": "text", "nullable": true},
-							map[string]any{"name": "published", "type": "boolean", "nullable": false},
-							map[string]any{"name": "author_id", "type": "integer", "nullable": true},
-						},
-						"primary_keys": []any{"id"},
-						"foreign_keys": []any{
-							map[string]any{"column": "author_id", "references_table": "authors", "references_column": "id"},
-						},
-					},
-					map[string]any{
-						"name": "authors",
-						"columns": []any{
-							map[string]any{"name": "id", "type": "integer", "nullable": false},
-							map[string]any{"name": "name", "type": "text", "nullable": false},
-						},
-						"primary_keys": []any{"id"},
-					},
-				},
-				"functions": []any{
-					map[string]any{"name": "get_post_count", "return_type": "integer"},
-				},
-			})
+			json.NewEncoder(w).Encode(fakeSchema)
 
 		case r.URL.Path == "/api/collections/posts" && r.Method == "GET":
 			json.NewEncoder(w).Encode(map[string]any{
@@ -86,7 +98,7 @@ func fakeAYB(t *testing.T) *httptest.Server {
 		case r.URL.Path == "/api/admin/sql" && r.Method == "POST":
 			if r.Header.Get("Authorization") != "Bearer test-admin-token" {
 				w.WriteHeader(http.StatusUnauthorized)
-				json.NewEncoder(w).Encode(map[string]any{"code": 401, "message": "unauthorized"})
+				json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "unauthorized", "message": "unauthorized"}})
 				return
 			}
 			json.NewEncoder(w).Encode(map[string]any{
@@ -101,11 +113,11 @@ func fakeAYB(t *testing.T) *httptest.Server {
 
 		case r.URL.Path == "/api/collections/nonexistent" && r.Method == "GET":
 			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "collection not found"})
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "not_found", "message": "collection not found"}})
 
 		default:
 			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]any{"code": 404, "message": "not found"})
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "not_found", "message": "not found"}})
 		}
 	}))
 }
@@ -127,10 +139,31 @@ func TestListTables(t *testing.T) {
 	c := newClient(Config{BaseURL: ts.URL})
 	_, out, err := handleListTables(context.Background(), c)
 	testutil.NoError(t, err)
-	testutil.Equal(t, 2, len(out.Tables))
+	testutil.Equal(t, 3, len(out.Tables))
+
+	// Sorted by schema, then name.
+	testutil.Equal(t, "audit.events", out.Tables[0].Schema+"."+out.Tables[0].Name)
+	testutil.Equal(t, "view", out.Tables[0].Kind)
+	testutil.Equal(t, "authors", out.Tables[1].Name)
+	posts := out.Tables[2]
+	testutil.Equal(t, "posts", posts.Name)
+	testutil.Equal(t, 5, len(posts.Columns))
+	testutil.Equal(t, ColumnSummary{Name: "id", Type: "integer", PrimaryKey: true}, posts.Columns[0])
+	testutil.Equal(t, ColumnSummary{Name: "body", Type: "text", Nullable: true}, posts.Columns[2])
+}
+
+func TestListTables_SchemaError(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": "unavailable", "message": "schema cache not ready"}})
+	}))
+	defer ts.Close()
 
-	name0, _ := out.Tables[0]["name"].(string)
-	testutil.Equal(t, "posts", name0)
+	c := newClient(Config{BaseURL: ts.URL})
+	_, _, err := handleListTables(context.Background(), c)
+	testutil.ErrorContains(t, err, "AYB error (503): schema cache not ready")
 }
 
 func TestDescribeTable(t *testing.T) {
@@ -145,9 +178,19 @@ func TestDescribeTable(t *testing.T) {
 		testutil.NoError(t, err)
 		testutil.Equal(t, "posts", out.Name)
 		testutil.Equal(t, 5, len(out.Columns))
-		testutil.Equal(t, 1, len(out.PKs))
-		testutil.Equal(t, "id", out.PKs[0])
-		testutil.Equal(t, 1, len(out.FKs))
+		testutil.Equal(t, 1, len(out.PrimaryKey))
+		testutil.Equal(t, "id", out.PrimaryKey[0])
+		testutil.Equal(t, 1, len(out.ForeignKeys))
+		testutil.Equal(t, "authors", out.ForeignKeys[0].ReferencedTable)
+		testutil.Equal(t, 1, len(out.Indexes))
+	})
+
+	t.Run("schema-qualified name", func(t *testing.T) {
+		t.Parallel()
+		_, out, err := handleDescribeTable(context.Background(), c, DescribeTableInput{Table: "audit.events"})
+		testutil.NoError(t, err)
+		testutil.Equal(t, "events", out.Name)
+		testutil.Equal(t, "view", out.Kind)
 	})
 
 	t.Run("nonexistent table", func(t *testing.T) {
@@ -166,17 +209,17 @@ func TestListFunctions(t *testing.T) {
 	_, out, err := handleListFunctions(context.Background(), c)
 	testutil.NoError(t, err)
 	testutil.Equal(t, 1, len(out.Functions))
-	name, _ := out.Functions[0]["name"].(string)
-	testutil.Equal(t, "get_post_count", name)
+	testutil.Equal(t, "get_post_count", out.Functions[0].Name)
+	testutil.Equal(t, "integer", out.Functions[0].ReturnType)
 }
 
-func TestQueryRecords(t *testing.T) {
+func TestQueryCollection(t *testing.T) {
 	t.Parallel()
 	ts := fakeAYB(t)
 	defer ts.Close()
 	c := newClient(Config{BaseURL: ts.URL})
 
-	_, out, err := handleQueryRecords(context.Background(), c, QueryRecordsInput{Table: "posts"})
+	_, out, err := handleQueryCollection(context.Background(), c, QueryCollectionInput{Table: "posts"})
 	testutil.NoError(t, err)
 	testutil.Equal(t, 2, len(out.Items))
 	testutil.Equal(t, 1, out.Page)
@@ -194,47 +237,6 @@ func TestGetRecord(t *testing.T) {
 	testutil.Equal(t, "Hello", out.Record["title"])
 }
 
-func TestCreateRecord(t *testing.T) {
-	t.Parallel()
-	ts := fakeAYB(t)
-	defer ts.Close()
-	c := newClient(Config{BaseURL: ts.URL})
-
-	_, out, err := handleCreateRecord(context.Background(), c, CreateRecordInput{
-		Table: "posts",
-		Data:  map[string]any{"title": "New Post"},
-	})
-	testutil.NoError(t, err)
-	testutil.Equal(t, "New Post", out.Record["title"])
-	id, _ := out.Record["id"].(float64)
-	testutil.Equal(t, float64(3), id)
-}
-
-func TestUpdateRecord(t *testing.T) {
-	t.Parallel()
-	ts := fakeAYB(t)
-	defer ts.Close()
-	c := newClient(Config{BaseURL: ts.URL})
-
-	_, out, err := handleUpdateRecord(context.Background(), c, UpdateRecordInput{
-		Table: "posts", ID: "1",
-		Data: map[string]any{"title": "Updated"},
-	})
-	testutil.NoError(t, err)
-	testutil.Equal(t, "Updated", out.Record["title"])
-}
-
-func TestDeleteRecord(t *testing.T) {
-	t.Parallel()
-	ts := fakeAYB(t)
-	defer ts.Close()
-	c := newClient(Config{BaseURL: ts.URL})
-
-	_, out, err := handleDeleteRecord(context.Background(), c, DeleteRecordInput{Table: "posts", ID: "1"})
-	testutil.NoError(t, err)
-	testutil.True(t, out.Deleted)
-}
-
 func TestRunSQL(t *testing.T) {
 	t.Parallel()
 	ts := fakeAYB(t)
@@ -252,7 +254,14 @@ func TestRunSQL(t *testing.T) {
 
 	t.Run("without admin token", func(t *testing.T) {
 		t.Parallel()
-		c := newClient(Config{BaseURL: ts.URL, AdminToken: ""})
+		c := newClient(Config{BaseURL: ts.URL, UserToken: "user-tok"})
+		_, _, err := handleRunSQL(context.Background(), c, RunSQLInput{Query: "SELECT 1"})
+		testutil.ErrorContains(t, err, "run_sql requires an admin token")
+	})
+
+	t.Run("wrong admin token", func(t *testing.T) {
+		t.Parallel()
+		c := newClient(Config{BaseURL: ts.URL, AdminToken: "wrong"})
 		_, _, err := handleRunSQL(context.Background(), c, RunSQLInput{Query: "SELECT 1"})
 		testutil.ErrorContains(t, err, "401")
 	})
@@ -304,7 +313,7 @@ func TestGetStatus(t *testing.T) {
 	testutil.NotNil(t, out.Admin)
 }
 
-func TestQueryRecords_WithParams(t *testing.T) {
+func TestQueryCollection_WithParams(t *testing.T) {
 	t.Parallel()
 	var capturedURL string
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -321,14 +330,15 @@ func TestQueryRecords_WithParams(t *testing.T) {
 	defer ts.Close()
 	c := newClient(Config{BaseURL: ts.URL})
 
-	_, out, err := handleQueryRecords(context.Background(), c, QueryRecordsInput{
-		Table:   "posts",
-		Filter:  "published=true",
-		Sort:    "-created_at",
-		Page:    1,
-		PerPage: 10,
-		Expand:  "author_id",
-		Search:  "hello",
+	_, out, err := handleQueryCollection(context.Background(), c, QueryCollectionInput{
+		Table:  "posts",
+		Filter: "published=true",
+		Sort:   "-created_at",
+		Limit:  10,
+		Page:   1,
+		Fields: "id,title",
+		Expand: "author_id",
+		Search: "hello",
 	})
 	testutil.NoError(t, err)
 	testutil.Equal(t, 1, len(out.Items))
@@ -338,17 +348,18 @@ func TestQueryRecords_WithParams(t *testing.T) {
 	testutil.Contains(t, capturedURL, "sort=-created_at")
 	testutil.Contains(t, capturedURL, "page=1")
 	testutil.Contains(t, capturedURL, "perPage=10")
+	testutil.Contains(t, capturedURL, "fields=id%2Ctitle")
 	testutil.Contains(t, capturedURL, "expand=author_id")
 	testutil.Contains(t, capturedURL, "search=hello")
 }
 
-func TestQueryRecords_NotFound(t *testing.T) {
+func TestQueryCollection_NotFound(t *testing.T) {
 	t.Parallel()
 	ts := fakeAYB(t)
 	defer ts.Close()
 	c := newClient(Config{BaseURL: ts.URL})
 
-	_, _, err := handleQueryRecords(context.Background(), c, QueryRecordsInput{Table: "nonexistent"})
+	_, _, err := handleQueryCollection(context.Background(), c, QueryCollectionInput{Table: "nonexistent"})
 	testutil.ErrorContains(t, err, "404")
 }
 
@@ -375,19 +386,15 @@ func TestResourceHealth(t *testing.T) {
 	testutil.Equal(t, "ok", result["status"])
 }
 
-func TestServerHasToolsRegistered(t *testing.T) {
-	t.Parallel()
-	ts := fakeAYB(t)
-	defer ts.Close()
-
-	srv := NewServer(Config{BaseURL: ts.URL, AdminToken: "test-admin-token"})
-
-	// Use in-memory transport to list tools
+// listTools starts a server for cfg over an in-memory transport and returns
+// the tools it advertises.
+func listTools(t *testing.T, cfg Config) []*mcp.Tool {
+	t.Helper()
+	srv := NewServer(cfg)
 	clientTransport, serverTransport := mcp.NewInMemoryTransports()
 
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
+	t.Cleanup(cancel)
 	go srv.Connect(ctx, serverTransport, nil)
 
 	client := mcp.NewClient(&mcp.Implementation{
@@ -399,26 +406,95 @@ func TestServerHasToolsRegistered(t *testing.T) {
 
 	tools, err := session.ListTools(ctx, nil)
 	testutil.NoError(t, err)
-	testutil.Equal(t, 11, len(tools.Tools))
+	return tools.Tools
+}
 
-	// Verify specific tool names exist
-	toolNames := make(map[string]bool)
-	for _, tool := range tools.Tools {
-		toolNames[tool.Name] = true
+func toolNameSet(tools []*mcp.Tool) map[string]bool {
+	names := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		names[tool.Name] = true
 	}
+	return names
+}
+
+func TestServerHasToolsRegistered(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+
+	tools := listTools(t, Config{BaseURL: ts.URL, AdminToken: "test-admin-token"})
+	testutil.Equal(t, 8, len(tools))
+
+	toolNames := toolNameSet(tools)
 	testutil.True(t, toolNames["list_tables"])
 	testutil.True(t, toolNames["describe_table"])
-	testutil.True(t, toolNames["query_records"])
+	testutil.True(t, toolNames["query_collection"])
 	testutil.True(t, toolNames["get_record"])
-	testutil.True(t, toolNames["create_record"])
-	testutil.True(t, toolNames["update_record"])
-	testutil.True(t, toolNames["delete_record"])
 	testutil.True(t, toolNames["run_sql"])
 	testutil.True(t, toolNames["call_function"])
 	testutil.True(t, toolNames["get_status"])
 	testutil.True(t, toolNames["list_functions"])
 }
 
+func TestToolSchemas(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+
+	tools := listTools(t, Config{BaseURL: ts.URL, AdminToken: "test-admin-token", AllowWrites: true, WriteTables: []string{"*"}})
+
+	type objectSchema struct {
+		Type       string         `json:"type"`
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+	}
+	schemas := map[string][2]objectSchema{}
+	for _, tool := range tools {
+		var in, out objectSchema
+		b, err := json.Marshal(tool.InputSchema)
+		testutil.NoError(t, err)
+		testutil.NoError(t, json.Unmarshal(b, &in))
+		b, err = json.Marshal(tool.OutputSchema)
+		testutil.NoError(t, err)
+		testutil.NoError(t, json.Unmarshal(b, &out))
+		schemas[tool.Name] = [2]objectSchema{in, out}
+	}
+
+	tests := []struct {
+		tool     string
+		input    []string
+		required []string
+		output   []string
+	}{
+		{tool: "list_tables", output: []string{"tables"}},
+		{tool: "describe_table", input: []string{"table"}, required: []string{"table"},
+			output: []string{"name", "columns", "primaryKey", "foreignKeys", "indexes"}},
+		{tool: "query_collection", input: []string{"table", "filter", "sort", "limit", "page", "fields"},
+			required: []string{"table"}, output: []string{"items", "totalItems"}},
+		{tool: "run_sql", input: []string{"query"}, required: []string{"query"},
+			output: []string{"columns", "rows", "rowCount"}},
+		{tool: "create_row", input: []string{"table", "data"}, required: []string{"table", "data"},
+			output: []string{"record"}},
+		{tool: "update_row", input: []string{"table", "id", "data"}, required: []string{"table", "id", "data"},
+			output: []string{"record"}},
+		{tool: "delete_row", input: []string{"table", "id"}, required: []string{"table", "id"},
+			output: []string{"deleted"}},
+		{tool: "apply_migration", input: []string{"name", "sql"}, required: []string{"name", "sql"},
+			output: []string{"applied"}},
+	}
+	for _, tt := range tests {
+		got := schemas[tt.tool]
+		testutil.Equal(t, "object", got[0].Type)
+		for _, p := range tt.input {
+			testutil.True(t, got[0].Properties[p] != nil, "%s input should have %q", tt.tool, p)
+		}
+		testutil.Equal(t, strings.Join(tt.required, ","), strings.Join(got[0].Required, ","))
+		for _, p := range tt.output {
+			testutil.True(t, got[1].Properties[p] != nil, "%s output should have %q", tt.tool, p)
+		}
+	}
+}
+
 func TestServerHasResourcesRegistered(t *testing.T) {
 	t.Parallel()
 	ts := fakeAYB(t)
@@ -515,18 +591,42 @@ func TestAPIClientAuthHeaders(t *testing.T) {
 	testutil.Equal(t, "Bearer user-tok", gotUserHeader)
 }
 
-func TestAPIClientErrorHandling(t *testing.T) {
+func TestAPIClientFallsBackToAdminToken(t *testing.T) {
 	t.Parallel()
+	var got string
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]any{"code": 400, "message": "bad filter syntax"})
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	}))
 	defer ts.Close()
 
-	c := newClient(Config{BaseURL: ts.URL})
-	_, _, err := c.doJSON(context.Background(), "GET", "/api/collections/posts?filter=bad", nil, false)
-	testutil.ErrorContains(t, err, "bad filter syntax")
+	c := newClient(Config{BaseURL: ts.URL, AdminToken: "admin-tok"})
+	_, _, err := c.doJSON(context.Background(), "GET", "/user", nil, false)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "Bearer admin-tok", got)
+}
+
+func TestAPIClientErrorHandling(t *testing.T) {
+	t.Parallel()
+	for name, body := range map[string]map[string]any{
+		"envelope": {"error": map[string]any{"code": "bad_request", "message": "bad filter syntax"}},
+		"legacy":   {"code": 400, "message": "bad filter syntax"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(body)
+			}))
+			defer ts.Close()
+
+			c := newClient(Config{BaseURL: ts.URL})
+			_, _, err := c.doJSON(context.Background(), "GET", "/api/collections/posts?filter=bad", nil, false)
+			testutil.ErrorContains(t, err, "AYB error (400): bad filter syntax")
+		})
+	}
 }
 
 func TestGetStatus_Unreachable(t *testing.T) {
@@ -539,17 +639,6 @@ func TestGetStatus_Unreachable(t *testing.T) {
 	testutil.Equal(t, "unreachable", out.Status)
 }
 
-func TestDeleteRecord_NotFound(t *testing.T) {
-	t.Parallel()
-	ts := fakeAYB(t)
-	defer ts.Close()
-	c := newClient(Config{BaseURL: ts.URL})
-
-	// Delete a record that doesn't exist (fakeAYB returns 404 for unknown paths)
-	_, _, err := handleDeleteRecord(context.Background(), c, DeleteRecordInput{Table: "posts", ID: "999"})
-	testutil.ErrorContains(t, err, "404")
-}
-
 func TestGetRecord_NotFound(t *testing.T) {
 	t.Parallel()
 	ts := fakeAYB(t)
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// writePolicy is the set of tables agents may change.
+type writePolicy struct {
+	all    bool
+	tables map[string]bool
+}
+
+// newWritePolicy returns the policy for cfg, or nil when writes aren't
+// allowed to any table. Mutation tools are only registered with a policy.
+func newWritePolicy(cfg Config) *writePolicy {
+	if !cfg.AllowWrites || len(cfg.WriteTables) == 0 {
+		return nil
+	}
+	p := &writePolicy{tables: map[string]bool{}}
+	for _, t := range cfg.WriteTables {
+		if t == "*" {
+			p.all = true
+		}
+		p.tables[t] = true
+	}
+	return p
+}
+
+// unrestricted reports whether every table is writable. Only then may an
+// agent run statements that aren't scoped to a table.
+func (p *writePolicy) unrestricted() bool {
+	return p != nil && p.all
+}
+
+func (p *writePolicy) check(table string) error {
+	if p.all || p.tables[table] {
+		return nil
+	}
+	return fmt.Errorf("table %q is not in the write allowlist (ayb mcp --write-tables)", table)
+}
+
+// --- Input/Output types for write tools ---
+
+type CreateRowInput struct {
+	Table string         `json:"table" jsonschema:"Table name; must be in the write allowlist"`
+	Data  map[string]any `json:"data" jsonschema:"Row data as column-value pairs"`
+}
+
+type UpdateRowInput struct {
+	Table string         `json:"table" jsonschema:"Table name; must be in the write allowlist"`
+	ID    string         `json:"id" jsonschema:"Primary key of the row"`
+	Data  map[string]any `json:"data" jsonschema:"Columns to update as column-value pairs"`
+}
+
+type DeleteRowInput struct {
+	Table string `json:"table" jsonschema:"Table name; must be in the write allowlist"`
+	ID    string `json:"id" jsonschema:"Primary key of the row"`
+}
+type DeleteRowOutput struct {
+	Deleted bool `json:"deleted"`
+}
+
+type ApplyMigrationInput struct {
+	Name string `json:"name" jsonschema:"Short name describing the migration (recorded in the audit log)"`
+	SQL  string `json:"sql" jsonschema:"DDL statements to execute"`
+}
+type ApplyMigrationOutput struct {
+	Applied    bool    `json:"applied"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// registerWriteTools adds the mutation tools. Row tools go through the
+// collections API, so they are subject to the same RLS policies and
+// validation as any other client. apply_migration runs arbitrary DDL and
+// is only offered when every table is writable.
+func registerWriteTools(s *mcp.Server, c *apiClient) {
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "create_row",
+		Description: "Insert a row into a table in the write allowlist",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in CreateRowInput) (*mcp.CallToolResult, RecordOutput, error) {
+		return handleCreateRow(ctx, c, in)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "update_row",
+		Description: "Partially update a row by primary key in a table in the write allowlist",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in UpdateRowInput) (*mcp.CallToolResult, RecordOutput, error) {
+		return handleUpdateRow(ctx, c, in)
+	})
+
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "delete_row",
+		Description: "Delete a row by primary key from a table in the write allowlist",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in DeleteRowInput) (*mcp.CallToolResult, DeleteRowOutput, error) {
+		return handleDeleteRow(ctx, c, in)
+	})
+
+	if c.writes.unrestricted() {
+		mcp.AddTool(s, &mcp.Tool{
+			Name:        "apply_migration",
+			Description: "Apply a schema migration (DDL) to the database (requires the admin token)",
+		}, func(ctx context.Context, req *mcp.CallToolRequest, in ApplyMigrationInput) (*mcp.CallToolResult, ApplyMigrationOutput, error) {
+			return handleApplyMigration(ctx, c, in)
+		})
+	}
+}
+
+func handleCreateRow(ctx context.Context, c *apiClient, in CreateRowInput) (_ *mcp.CallToolResult, _ RecordOutput, err error) {
+	defer func() { c.auditMutation(ctx, "create_row", err, "table", in.Table, "columns", dataColumns(in.Data)) }()
+	if err := c.writes.check(in.Table); err != nil {
+		return nil, RecordOutput{}, err
+	}
+
+	path := "/api/collections/" + url.PathEscape(in.Table)
+	result, _, err := c.doJSON(ctx, "POST", path, in.Data, false)
+	if err != nil {
+		return nil, RecordOutput{}, err
+	}
+	return nil, RecordOutput{Record: result}, nil
+}
+
+func handleUpdateRow(ctx context.Context, c *apiClient, in UpdateRowInput) (_ *mcp.CallToolResult, _ RecordOutput, err error) {
+	defer func() {
+		c.auditMutation(ctx, "update_row", err, "table", in.Table, "id", in.ID, "columns", dataColumns(in.Data))
+	}()
+	if err := c.writes.check(in.Table); err != nil {
+		return nil, RecordOutput{}, err
+	}
+
+	path := "/api/collections/" + url.PathEscape(in.Table) + "/" + url.PathEscape(in.ID)
+	result, _, err := c.doJSON(ctx, "PATCH", path, in.Data, false)
+	if err != nil {
+		return nil, RecordOutput{}, err
+	}
+	return nil, RecordOutput{Record: result}, nil
+}
+
+func handleDeleteRow(ctx context.Context, c *apiClient, in DeleteRowInput) (_ *mcp.CallToolResult, _ DeleteRowOutput, err error) {
+	defer func() { c.auditMutation(ctx, "delete_row", err, "table", in.Table, "id", in.ID) }()
+	if err := c.writes.check(in.Table); err != nil {
+		return nil, DeleteRowOutput{}, err
+	}
+
+	path := "/api/collections/" + url.PathEscape(in.Table) + "/" + url.PathEscape(in.ID)
+	_, status, err := c.doJSON(ctx, "DELETE", path, nil, false)
+	if err != nil {
+		return nil, DeleteRowOutput{}, err
+	}
+	return nil, DeleteRowOutput{Deleted: status == http.StatusNoContent}, nil
+}
+
+func handleApplyMigration(ctx context.Context, c *apiClient, in ApplyMigrationInput) (_ *mcp.CallToolResult, _ ApplyMigrationOutput, err error) {
+	defer func() { c.auditMutation(ctx, "apply_migration", err, "name", in.Name, "sql", in.SQL) }()
+	if c.adminToken == "" {
+		return nil, ApplyMigrationOutput{}, fmt.Errorf("apply_migration requires an admin token: pass --admin-token to ayb mcp or set AYB_ADMIN_TOKEN")
+	}
+
+	result, _, err := c.doJSON(ctx, "POST", "/api/admin/sql", map[string]any{"query": in.SQL}, true)
+	if err != nil {
+		return nil, ApplyMigrationOutput{}, err
+	}
+	out := ApplyMigrationOutput{Applied: true}
+	if v, ok := result["durationMs"].(float64); ok {
+		out.DurationMs = v
+	}
+	return nil, out, nil
+}
+
+// auditMutation records a mutation made through a tool, and its outcome.
+func (c *apiClient) auditMutation(ctx context.Context, tool string, err error, args ...any) {
+	args = append([]any{"tool", tool}, args...)
+	if err != nil {
+		c.audit.Log(ctx, slog.LevelWarn, "mcp mutation failed", append(args, "error", err.Error())...)
+		return
+	}
+	c.audit.Log(ctx, slog.LevelInfo, "mcp mutation", args...)
+}
+
+// dataColumns returns the sorted column names of a row payload. Values are
+// left out of the audit log since they may hold personal data.
+func dataColumns(data map[string]any) []string {
+	return slices.Sorted(maps.Keys(data))
+}
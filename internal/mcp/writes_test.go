@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestWriteToolsAbsentByDefault(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no flags", Config{}},
+		{"allowlist without allow-writes", Config{WriteTables: []string{"*"}}},
+		{"allow-writes without allowlist", Config{AllowWrites: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.BaseURL = ts.URL
+			tt.cfg.AdminToken = "test-admin-token"
+			names := toolNameSet(listTools(t, tt.cfg))
+			for _, tool := range []string{"create_row", "update_row", "delete_row", "apply_migration"} {
+				testutil.False(t, names[tool], "%s should not be registered", tool)
+			}
+		})
+	}
+}
+
+func TestWriteToolsRegistered(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+
+	t.Run("table allowlist", func(t *testing.T) {
+		names := toolNameSet(listTools(t, Config{BaseURL: ts.URL, AllowWrites: true, WriteTables: []string{"posts"}}))
+		testutil.True(t, names["create_row"])
+		testutil.True(t, names["update_row"])
+		testutil.True(t, names["delete_row"])
+		testutil.False(t, names["apply_migration"], "apply_migration needs every table writable")
+	})
+
+	t.Run("all tables", func(t *testing.T) {
+		names := toolNameSet(listTools(t, Config{BaseURL: ts.URL, AllowWrites: true, WriteTables: []string{"*"}}))
+		testutil.True(t, names["create_row"])
+		testutil.True(t, names["apply_migration"])
+	})
+}
+
+// auditedClient returns a client allowed to write tables, with its audit
+// log captured in the returned buffer.
+func auditedClient(baseURL string, tables ...string) (*apiClient, *bytes.Buffer) {
+	var buf bytes.Buffer
+	c := newClient(Config{
+		BaseURL:     baseURL,
+		AdminToken:  "test-admin-token",
+		AllowWrites: true,
+		WriteTables: tables,
+		AuditLog:    slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+	return c, &buf
+}
+
+// auditEntries decodes the JSON lines of an audit log.
+func auditEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e map[string]any
+		testutil.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestCreateRow(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "posts")
+
+	_, out, err := handleCreateRow(context.Background(), c, CreateRowInput{
+		Table: "posts",
+		Data:  map[string]any{"title": "New Post", "body": "secret"},
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, "New Post", out.Record["title"])
+	id, _ := out.Record["id"].(float64)
+	testutil.Equal(t, float64(3), id)
+
+	entries := auditEntries(t, audit)
+	testutil.Equal(t, 1, len(entries))
+	testutil.Equal(t, "mcp mutation", entries[0]["msg"])
+	testutil.Equal(t, "create_row", entries[0]["tool"])
+	testutil.Equal(t, "posts", entries[0]["table"])
+	testutil.Equal(t, `["body","title"]`, mustJSON(t, entries[0]["columns"]))
+	testutil.False(t, strings.Contains(audit.String(), "secret"), "row values should not be logged")
+}
+
+func TestUpdateRow(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "posts")
+
+	_, out, err := handleUpdateRow(context.Background(), c, UpdateRowInput{
+		Table: "posts", ID: "1",
+		Data: map[string]any{"title": "Updated"},
+	})
+	testutil.NoError(t, err)
+	testutil.Equal(t, "Updated", out.Record["title"])
+
+	entries := auditEntries(t, audit)
+	testutil.Equal(t, 1, len(entries))
+	testutil.Equal(t, "update_row", entries[0]["tool"])
+	testutil.Equal(t, "1", entries[0]["id"])
+}
+
+func TestDeleteRow(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "*")
+
+	_, out, err := handleDeleteRow(context.Background(), c, DeleteRowInput{Table: "posts", ID: "1"})
+	testutil.NoError(t, err)
+	testutil.True(t, out.Deleted)
+	testutil.Equal(t, "delete_row", auditEntries(t, audit)[0]["tool"])
+}
+
+func TestDeleteRow_NotFound(t *testing.T) {
+	t.Parallel()
+	ts := fakeAYB(t)
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "posts")
+
+	// fakeAYB returns 404 for unknown paths.
+	_, _, err := handleDeleteRow(context.Background(), c, DeleteRowInput{Table: "posts", ID: "999"})
+	testutil.ErrorContains(t, err, "404")
+
+	entries := auditEntries(t, audit)
+	testutil.Equal(t, 1, len(entries))
+	testutil.Equal(t, "mcp mutation failed", entries[0]["msg"])
+	testutil.Contains(t, entries[0]["error"].(string), "404")
+}
+
+func TestWriteRejectedOutsideAllowlist(t *testing.T) {
+	t.Parallel()
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "posts")
+
+	_, _, err := handleDeleteRow(context.Background(), c, DeleteRowInput{Table: "authors", ID: "1"})
+	testutil.ErrorContains(t, err, `table "authors" is not in the write allowlist`)
+	testutil.Equal(t, 0, requests)
+
+	entries := auditEntries(t, audit)
+	testutil.Equal(t, 1, len(entries))
+	testutil.Equal(t, "mcp mutation failed", entries[0]["msg"])
+	testutil.Equal(t, "authors", entries[0]["table"])
+}
+
+func TestApplyMigration(t *testing.T) {
+	t.Parallel()
+	var got map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equal(t, "/api/admin/sql", r.URL.Path)
+		testutil.Equal(t, "Bearer test-admin-token", r.Header.Get("Authorization"))
+		testutil.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"columns": []any{}, "rows": []any{}, "rowCount": 0, "durationMs": 4})
+	}))
+	defer ts.Close()
+	c, audit := auditedClient(ts.URL, "*")
+
+	sql := "ALTER TABLE posts ADD COLUMN slug text"
+	_, out, err := handleApplyMigration(context.Background(), c, ApplyMigrationInput{Name: "add_slug", SQL: sql})
+	testutil.NoError(t, err)
+	testutil.True(t, out.Applied)
+	testutil.Equal(t, float64(4), out.DurationMs)
+	testutil.Equal[any](t, sql, got["query"])
+	testutil.Nil(t, got["readOnly"])
+
+	entries := auditEntries(t, audit)
+	testutil.Equal(t, "apply_migration", entries[0]["tool"])
+	testutil.Equal(t, "add_slug", entries[0]["name"])
+	testutil.Equal[any](t, sql, entries[0]["sql"])
+}
+
+func TestRunSQLReadOnlyUnlessAllTablesWritable(t *testing.T) {
+	t.Parallel()
+	var readOnly []any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		testutil.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		readOnly = append(readOnly, body["readOnly"])
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"columns": []any{}, "rows": []any{}})
+	}))
+	defer ts.Close()
+
+	reader := newClient(Config{BaseURL: ts.URL, AdminToken: "test-admin-token"})
+	scoped, scopedAudit := auditedClient(ts.URL, "posts")
+	writer, writerAudit := auditedClient(ts.URL, "*")
+	for _, c := range []*apiClient{reader, scoped, writer} {
+		_, _, err := handleRunSQL(context.Background(), c, RunSQLInput{Query: "UPDATE posts SET title = 'x'"})
+		testutil.NoError(t, err)
+	}
+
+	testutil.Equal(t, "[true,true,false]", mustJSON(t, readOnly))
+	testutil.Equal(t, "", scopedAudit.String())
+	entries := auditEntries(t, writerAudit)
+	testutil.Equal(t, 1, len(entries))
+	testutil.Equal(t, "run_sql", entries[0]["tool"])
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	testutil.NoError(t, err)
+	return string(b)
+}
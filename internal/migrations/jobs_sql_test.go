@@ -56,3 +56,17 @@ func TestJobsMigrationSQLConstraints(t *testing.T) {
 	testutil.True(t, strings.Contains(sql024, "ON DELETE SET NULL"),
 		"024 FK must clear jobs.schedule_id when a schedule is deleted")
 }
+
+func TestJobsPriorityMigrationSQL(t *testing.T) {
+	t.Parallel()
+
+	b, err := fs.ReadFile(embeddedMigrations, "sql/037_ayb_jobs_priority.sql")
+	testutil.NoError(t, err)
+	sql := string(b)
+	testutil.True(t, strings.Contains(sql, "ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0"),
+		"037 must add a non-null priority defaulting to 0")
+	testutil.True(t, strings.Contains(sql, "ON _ayb_jobs (priority DESC, run_at)"),
+		"037 claimable index must match the claim order")
+	testutil.True(t, strings.Contains(sql, "WHERE state = 'queued'"),
+		"037 claimable index must be partial on queued state")
+}
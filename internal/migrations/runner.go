@@ -55,24 +55,13 @@ func (r *Runner) Bootstrap(ctx context.Context) error {
 
 // Run applies all pending embedded migrations in order.
 func (r *Runner) Run(ctx context.Context) (int, error) {
-	entries, err := fs.ReadDir(r.source, "sql")
+	names, err := r.names()
 	if err != nil {
-		return 0, fmt.Errorf("reading embedded migrations: %w", err)
+		return 0, err
 	}
 
-	// Sort by filename to ensure order.
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Name() < entries[j].Name()
-	})
-
 	applied := 0
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
-			continue
-		}
-
-		name := entry.Name()
-
+	for _, name := range names {
 		// Check if already applied.
 		var exists bool
 		err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM _ayb_migrations WHERE name = $1)", name).Scan(&exists)
@@ -114,6 +103,68 @@ func (r *Runner) Run(ctx context.Context) (int, error) {
 	return applied, nil
 }
 
+// names returns the migration filenames in apply order.
+func (r *Runner) names() ([]string, error) {
+	entries, err := fs.ReadDir(r.source, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("reading embedded migrations: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	// Sort by filename to ensure order.
+	sort.Strings(names)
+	return names, nil
+}
+
+// Pending returns the names of migrations that have not been applied, without
+// applying them or creating the _ayb_migrations table. Against a database AYB
+// has never run on, every migration is pending.
+func (r *Runner) Pending(ctx context.Context) ([]string, error) {
+	names, err := r.names()
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrapped, err := tableExists(ctx, r.pool, "_ayb_migrations")
+	if err != nil {
+		return nil, err
+	}
+	if !bootstrapped {
+		return names, nil
+	}
+
+	applied, err := r.GetApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(applied))
+	for _, m := range applied {
+		done[m.Name] = true
+	}
+	var pending []string
+	for _, name := range names {
+		if !done[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// tableExists reports whether a migration tracking table has been created.
+func tableExists(ctx context.Context, pool *pgxpool.Pool, table string) (bool, error) {
+	var exists bool
+	if err := pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return false, fmt.Errorf("checking %s table: %w", table, err)
+	}
+	return exists, nil
+}
+
 // GetApplied returns the list of applied migrations.
 func (r *Runner) GetApplied(ctx context.Context) ([]AppliedMigration, error) {
 	rows, err := r.pool.Query(ctx, "SELECT name, applied_at FROM _ayb_migrations ORDER BY id")
@@ -418,3 +418,31 @@ func TestGetApplied(t *testing.T) {
 	testutil.Equal(t, "001_ayb_meta.sql", applied[0].Name)
 	testutil.False(t, applied[0].AppliedAt.IsZero(), "applied_at should be set")
 }
+
+func TestPending(t *testing.T) {
+	ctx := context.Background()
+	resetDB(t, ctx)
+
+	source := fstest.MapFS{
+		"sql/001_first.sql":  {Data: []byte("CREATE TABLE pending_first (id INT)")},
+		"sql/002_second.sql": {Data: []byte("CREATE TABLE pending_second (id INT)")},
+	}
+	runner := migrations.NewRunnerWithFS(sharedPG.Pool, testutil.DiscardLogger(), source)
+
+	// Before bootstrap, everything is pending and nothing is created.
+	pending, err := runner.Pending(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, len(pending))
+	var exists bool
+	err = sharedPG.Pool.QueryRow(ctx, "SELECT to_regclass('_ayb_migrations') IS NOT NULL").Scan(&exists)
+	testutil.NoError(t, err)
+	testutil.False(t, exists, "Pending should not create _ayb_migrations")
+
+	testutil.NoError(t, runner.Bootstrap(ctx))
+	_, err = runner.Run(ctx)
+	testutil.NoError(t, err)
+
+	pending, err = runner.Pending(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 0, len(pending))
+}
@@ -0,0 +1,3 @@
+-- Per-session "remember me" flag. Refresh rotation extends a session by
+-- auth.remember_me_duration when set, auth.refresh_token_duration otherwise.
+ALTER TABLE _ayb_sessions ADD COLUMN IF NOT EXISTS remember_me BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- Send time of each SMS code, used to enforce auth.sms_resend_cooldown.
+ALTER TABLE _ayb_sms_codes ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,5 @@
+-- Provider delivery receipts (POST /api/auth/sms/status), reported by the
+-- admin SMS health endpoint alongside OTP confirm/fail counts.
+ALTER TABLE _ayb_sms_daily_counts
+    ADD COLUMN IF NOT EXISTS delivered_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS undelivered_count INTEGER NOT NULL DEFAULT 0;
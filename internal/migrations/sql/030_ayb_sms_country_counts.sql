@@ -0,0 +1,10 @@
+-- Per-country breakdown of _ayb_sms_daily_counts for the admin SMS health
+-- endpoint (country is the ISO 3166-1 alpha-2 code parsed from the phone).
+CREATE TABLE IF NOT EXISTS _ayb_sms_country_counts (
+    date          DATE    NOT NULL,
+    country       TEXT    NOT NULL,
+    count         INTEGER NOT NULL DEFAULT 0,
+    confirm_count INTEGER NOT NULL DEFAULT 0,
+    fail_count    INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (date, country)
+);
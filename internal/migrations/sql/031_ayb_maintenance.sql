@@ -0,0 +1,10 @@
+-- Single-row maintenance mode state, toggled via POST /api/admin/maintenance
+-- and restored on startup.
+CREATE TABLE IF NOT EXISTS _ayb_maintenance (
+    id          BOOLEAN     PRIMARY KEY DEFAULT TRUE CHECK (id),
+    enabled     BOOLEAN     NOT NULL DEFAULT FALSE,
+    allow_reads BOOLEAN     NOT NULL DEFAULT FALSE,
+    message     TEXT        NOT NULL DEFAULT '',
+    retry_after INTEGER     NOT NULL DEFAULT 300,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
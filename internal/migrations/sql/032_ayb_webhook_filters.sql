@@ -0,0 +1,5 @@
+-- Per-webhook payload column lists and a row filter in collection filter
+-- syntax. An empty filter matches every row.
+ALTER TABLE _ayb_webhooks ADD COLUMN IF NOT EXISTS include_columns TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE _ayb_webhooks ADD COLUMN IF NOT EXISTS exclude_columns TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE _ayb_webhooks ADD COLUMN IF NOT EXISTS filter TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,17 @@
+-- Idempotency-Key records for collection create and batch requests. A key is
+-- claimed before the request runs, its response is stored once it finishes,
+-- and retries with the same key replay that response until expires_at.
+-- status_code is NULL while the first request is still running.
+CREATE TABLE IF NOT EXISTS _ayb_idempotency_keys (
+    user_id      TEXT        NOT NULL,
+    key          TEXT        NOT NULL,
+    request_hash TEXT        NOT NULL,
+    status_code  INTEGER,
+    content_type TEXT        NOT NULL DEFAULT '',
+    response     BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ayb_idempotency_keys_expires_at ON _ayb_idempotency_keys (expires_at);
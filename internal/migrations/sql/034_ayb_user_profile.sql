@@ -0,0 +1,14 @@
+-- Self-service profile fields, updated with PATCH /api/auth/me. metadata only
+-- holds the keys allowed by auth.profile_metadata_keys.
+ALTER TABLE _ayb_users ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE _ayb_users ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+-- Pending email changes. The new address only replaces the user's email once
+-- the link sent to it is confirmed; at most one change is pending per user.
+CREATE TABLE IF NOT EXISTS _ayb_email_changes (
+    user_id    UUID        PRIMARY KEY REFERENCES _ayb_users(id) ON DELETE CASCADE,
+    new_email  TEXT        NOT NULL,
+    token_hash TEXT        NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,8 @@
+-- Failed verification attempts per MFA challenge token, keyed by the token's
+-- jti. Once a token reaches the failure limit it is rejected, so the login has
+-- to be restarted. Rows are kept until the token would have expired anyway.
+CREATE TABLE IF NOT EXISTS _ayb_mfa_challenge_failures (
+    token_id   TEXT        PRIMARY KEY,
+    failures   INTEGER     NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL
+);
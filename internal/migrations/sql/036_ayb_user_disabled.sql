@@ -0,0 +1,3 @@
+-- Set when an admin disables a user. Disabled users can't log in or refresh
+-- their session until re-enabled; NULL means the account is active.
+ALTER TABLE _ayb_users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
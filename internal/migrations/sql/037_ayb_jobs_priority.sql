@@ -0,0 +1,7 @@
+-- Queued jobs with a higher priority are claimed first; jobs of equal
+-- priority are claimed in run_at order.
+ALTER TABLE _ayb_jobs ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_ayb_jobs_claimable_priority
+    ON _ayb_jobs (priority DESC, run_at)
+    WHERE state = 'queued';
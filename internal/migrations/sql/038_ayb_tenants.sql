@@ -0,0 +1,7 @@
+-- Tenants provisioned in schema-per-tenant mode. Each tenant's tables live
+-- in schema_name, a copy of the public schema built from the user migrations.
+CREATE TABLE IF NOT EXISTS _ayb_tenants (
+    id          TEXT PRIMARY KEY,
+    schema_name TEXT NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- The provider's userinfo response for each linked OAuth identity, refreshed
+-- at every login.
+ALTER TABLE _ayb_oauth_accounts ADD COLUMN IF NOT EXISTS provider_profile JSONB NOT NULL DEFAULT '{}';
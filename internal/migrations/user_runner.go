@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,16 +27,24 @@ func NewUserRunner(pool *pgxpool.Pool, dir string, logger *slog.Logger) *UserRun
 	return &UserRunner{pool: pool, dir: dir, logger: logger}
 }
 
+// createUserMigrationsTable creates the _ayb_user_migrations tracking table
+// in the first schema on the search_path.
+const createUserMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS _ayb_user_migrations (
+		id          SERIAL PRIMARY KEY,
+		name        TEXT NOT NULL UNIQUE,
+		applied_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)
+`
+
+// querier is the query side shared by *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
 // Bootstrap creates the _ayb_user_migrations tracking table if it doesn't exist.
 func (r *UserRunner) Bootstrap(ctx context.Context) error {
-	_, err := r.pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS _ayb_user_migrations (
-			id          SERIAL PRIMARY KEY,
-			name        TEXT NOT NULL UNIQUE,
-			applied_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		)
-	`)
-	if err != nil {
+	if _, err := r.pool.Exec(ctx, createUserMigrationsTable); err != nil {
 		return fmt.Errorf("creating _ayb_user_migrations table: %w", err)
 	}
 	return nil
@@ -65,38 +74,100 @@ func (r *UserRunner) Up(ctx context.Context) (int, error) {
 			continue
 		}
 
-		sql, err := os.ReadFile(filepath.Join(r.dir, name))
-		if err != nil {
-			return applied, fmt.Errorf("reading migration %s: %w", name, err)
+		if err := r.applyFile(ctx, name); err != nil {
+			return applied, err
 		}
+		applied++
+	}
 
-		tx, err := r.pool.Begin(ctx)
-		if err != nil {
-			return applied, fmt.Errorf("starting transaction for %s: %w", name, err)
-		}
-		defer tx.Rollback(ctx) // no-op after commit; safety net for panics
+	return applied, nil
+}
 
-		if _, err := tx.Exec(ctx, string(sql)); err != nil {
-			return applied, fmt.Errorf("executing migration %s: %w", name, err)
+// Apply executes the named migration files in the given order and records
+// each one as applied, without checking whether it was applied before.
+// Used to replay a known migration history into a fresh database.
+func (r *UserRunner) Apply(ctx context.Context, names []string) error {
+	for _, name := range names {
+		if err := r.applyFile(ctx, name); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if _, err := tx.Exec(ctx,
-			"INSERT INTO _ayb_user_migrations (name) VALUES ($1)", name,
-		); err != nil {
-			return applied, fmt.Errorf("recording migration %s: %w", name, err)
-		}
+// UpInSchema applies all pending user migrations to schemaName within tx,
+// tracking them in that schema's own _ayb_user_migrations table. The search
+// path is set to schemaName for the rest of tx, so unqualified names in the
+// migration files resolve there. Used to build and migrate tenant schemas.
+// Returns the number of migrations applied.
+func (r *UserRunner) UpInSchema(ctx context.Context, tx pgx.Tx, schemaName string) (int, error) {
+	files, err := r.listFiles()
+	if err != nil {
+		return 0, err
+	}
+	path := "SET LOCAL search_path TO " + pgx.Identifier{schemaName}.Sanitize() + ", public"
+	if _, err := tx.Exec(ctx, path); err != nil {
+		return 0, fmt.Errorf("setting search_path to %s: %w", schemaName, err)
+	}
+	if _, err := tx.Exec(ctx, createUserMigrationsTable); err != nil {
+		return 0, fmt.Errorf("creating %s._ayb_user_migrations table: %w", schemaName, err)
+	}
+	done, err := getApplied(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
 
-		if err := tx.Commit(ctx); err != nil {
-			return applied, fmt.Errorf("committing migration %s: %w", name, err)
+	applied := 0
+	for _, name := range files {
+		if _, ok := done[name]; ok {
+			continue
 		}
-
-		r.logger.Info("applied user migration", "name", name)
+		if err := r.execFile(ctx, tx, name); err != nil {
+			return applied, err
+		}
+		r.logger.Info("applied user migration", "name", name, "schema", schemaName)
 		applied++
 	}
-
 	return applied, nil
 }
 
+// applyFile runs a single migration file and records it in one transaction.
+func (r *UserRunner) applyFile(ctx context.Context, name string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("starting transaction for %s: %w", name, err)
+	}
+	defer tx.Rollback(ctx) // no-op after commit; safety net for panics
+
+	if err := r.execFile(ctx, tx, name); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing migration %s: %w", name, err)
+	}
+
+	r.logger.Info("applied user migration", "name", name)
+	return nil
+}
+
+// execFile runs a single migration file in tx and records it as applied.
+func (r *UserRunner) execFile(ctx context.Context, tx pgx.Tx, name string) error {
+	sql, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		return fmt.Errorf("reading migration %s: %w", name, err)
+	}
+	if _, err := tx.Exec(ctx, upSection(string(sql))); err != nil {
+		return fmt.Errorf("executing migration %s: %w", name, err)
+	}
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO _ayb_user_migrations (name) VALUES ($1)", name,
+	); err != nil {
+		return fmt.Errorf("recording migration %s: %w", name, err)
+	}
+	return nil
+}
+
 // MigrationStatus represents a migration file and whether it has been applied.
 type MigrationStatus struct {
 	Name      string
@@ -111,7 +182,7 @@ func (r *UserRunner) Status(ctx context.Context) ([]MigrationStatus, error) {
 	}
 
 	// Load applied set.
-	applied, err := r.getApplied(ctx)
+	applied, err := getApplied(ctx, r.pool)
 	if err != nil {
 		return nil, err
 	}
@@ -126,9 +197,60 @@ func (r *UserRunner) Status(ctx context.Context) ([]MigrationStatus, error) {
 	return result, nil
 }
 
+// Pending returns the names of migration files that have not been applied,
+// without applying them or creating the _ayb_user_migrations table. Before
+// the table exists every file is pending.
+func (r *UserRunner) Pending(ctx context.Context) ([]string, error) {
+	files, err := r.listFiles()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	bootstrapped, err := tableExists(ctx, r.pool, "_ayb_user_migrations")
+	if err != nil {
+		return nil, err
+	}
+	if !bootstrapped {
+		return files, nil
+	}
+
+	applied, err := getApplied(ctx, r.pool)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, name := range files {
+		if _, ok := applied[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// Section markers for reversible migration files. Everything after the down
+// marker is the rollback SQL and is never run when migrating up.
+const (
+	upMarker   = "-- +up"
+	downMarker = "-- +down"
+)
+
+// upSection returns the SQL to apply when migrating up: the whole file for
+// single-direction migrations, or everything before the down marker for
+// reversible ones.
+func upSection(sql string) string {
+	lines := strings.SplitAfter(sql, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == downMarker {
+			return strings.Join(lines[:i], "")
+		}
+	}
+	return sql
+}
+
 // CreateFile generates a new timestamped migration SQL file in the migrations directory.
+// When reversible is true the file is laid out in up/down sections.
 // Returns the path to the created file.
-func (r *UserRunner) CreateFile(name string) (string, error) {
+func (r *UserRunner) CreateFile(name string, reversible bool) (string, error) {
 	if err := os.MkdirAll(r.dir, 0o755); err != nil {
 		return "", fmt.Errorf("creating migrations directory: %w", err)
 	}
@@ -139,6 +261,10 @@ func (r *UserRunner) CreateFile(name string) (string, error) {
 
 	content := fmt.Sprintf("-- Migration: %s\n-- Created: %s\n\n",
 		name, time.Now().UTC().Format(time.RFC3339))
+	if reversible {
+		content += upMarker + "\n-- SQL to apply the migration.\n\n" +
+			downMarker + "\n-- SQL to revert the migration.\n"
+	}
 
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return "", fmt.Errorf("writing migration file: %w", err)
@@ -168,8 +294,8 @@ func (r *UserRunner) listFiles() ([]string, error) {
 }
 
 // getApplied returns a map of migration name → applied_at for all applied migrations.
-func (r *UserRunner) getApplied(ctx context.Context) (map[string]time.Time, error) {
-	rows, err := r.pool.Query(ctx,
+func getApplied(ctx context.Context, q querier) (map[string]time.Time, error) {
+	rows, err := q.Query(ctx,
 		"SELECT name, applied_at FROM _ayb_user_migrations ORDER BY id")
 	if err != nil {
 		return nil, fmt.Errorf("querying applied user migrations: %w", err)
@@ -190,3 +190,34 @@ func TestUserRunnerStatus(t *testing.T) {
 	testutil.Equal(t, "20260203_c.sql", status[2].Name)
 	testutil.Nil(t, status[2].AppliedAt)
 }
+
+func TestUserRunnerPending(t *testing.T) {
+	ctx := context.Background()
+	resetDB(t, ctx)
+
+	dir := t.TempDir()
+	runner := migrations.NewUserRunner(sharedPG.Pool, dir, testutil.DiscardLogger())
+
+	os.WriteFile(filepath.Join(dir, "20260201_a.sql"), []byte("CREATE TABLE a (id INT)"), 0o644)
+	os.WriteFile(filepath.Join(dir, "20260202_b.sql"), []byte("CREATE TABLE b (id INT)"), 0o644)
+
+	// Before bootstrap every file is pending, and the table isn't created.
+	pending, err := runner.Pending(ctx)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, pending, 2)
+
+	var exists bool
+	err = sharedPG.Pool.QueryRow(ctx, "SELECT to_regclass('_ayb_user_migrations') IS NOT NULL").Scan(&exists)
+	testutil.NoError(t, err)
+	testutil.False(t, exists, "Pending should not create _ayb_user_migrations")
+
+	testutil.NoError(t, runner.Bootstrap(ctx))
+	_, err = runner.Up(ctx)
+	testutil.NoError(t, err)
+	os.WriteFile(filepath.Join(dir, "20260203_c.sql"), []byte("CREATE TABLE c (id INT)"), 0o644)
+
+	pending, err = runner.Pending(ctx)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, pending, 1)
+	testutil.Equal(t, "20260203_c.sql", pending[0])
+}
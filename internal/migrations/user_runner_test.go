@@ -36,7 +36,7 @@ func TestCreateFile(t *testing.T) {
 	dir := t.TempDir()
 	r := NewUserRunner(nil, dir, testutil.DiscardLogger())
 
-	path, err := r.CreateFile("create_posts")
+	path, err := r.CreateFile("create_posts", false)
 	testutil.NoError(t, err)
 
 	// File should exist.
@@ -56,12 +56,49 @@ func TestCreateFile(t *testing.T) {
 	testutil.Contains(t, string(data), "-- Created:")
 }
 
+func TestCreateFileReversible(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	r := NewUserRunner(nil, dir, testutil.DiscardLogger())
+
+	path, err := r.CreateFile("create_posts", true)
+	testutil.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	testutil.NoError(t, err)
+	content := string(data)
+	testutil.Contains(t, content, "-- Migration: create_posts")
+	testutil.Contains(t, content, "\n-- +up\n")
+	testutil.Contains(t, content, "\n-- +down\n")
+	testutil.True(t, strings.Index(content, "-- +up") < strings.Index(content, "-- +down"), "up section should precede down section")
+}
+
+func TestUpSection(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{"single direction", "CREATE TABLE a (id int);\n", "CREATE TABLE a (id int);\n"},
+		{"reversible", "-- +up\nCREATE TABLE a (id int);\n-- +down\nDROP TABLE a;\n", "-- +up\nCREATE TABLE a (id int);\n"},
+		{"indented marker", "CREATE TABLE a (id int);\n  -- +down  \nDROP TABLE a;\n", "CREATE TABLE a (id int);\n"},
+		{"marker inside line", "SELECT 1; -- +down\n", "SELECT 1; -- +down\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			testutil.Equal(t, tt.want, upSection(tt.sql))
+		})
+	}
+}
+
 func TestCreateFileCreatesDir(t *testing.T) {
 	t.Parallel()
 	dir := filepath.Join(t.TempDir(), "subdir", "migrations")
 	r := NewUserRunner(nil, dir, testutil.DiscardLogger())
 
-	path, err := r.CreateFile("init")
+	path, err := r.CreateFile("init", false)
 	testutil.NoError(t, err)
 
 	_, err = os.Stat(path)
@@ -73,7 +110,7 @@ func TestCreateFileSanitizesName(t *testing.T) {
 	dir := t.TempDir()
 	r := NewUserRunner(nil, dir, testutil.DiscardLogger())
 
-	path, err := r.CreateFile("add user-roles")
+	path, err := r.CreateFile("add user-roles", false)
 	testutil.NoError(t, err)
 
 	name := filepath.Base(path)
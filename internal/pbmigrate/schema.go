@@ -0,0 +1,81 @@
+package pbmigrate
+
+import (
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// schemaCollections returns the collections the migrator creates tables
+// and views for. System collections are internal to PocketBase, and auth
+// collections migrate to AYB users instead.
+func schemaCollections(collections []PBCollection) []PBCollection {
+	var out []PBCollection
+	for _, coll := range collections {
+		if coll.System || coll.Type == "auth" {
+			continue
+		}
+		out = append(out, coll)
+	}
+	return out
+}
+
+// BuildSchemaSQL returns the CREATE statements the migrator runs for
+// collections, as a script that can be applied to a fresh database.
+func BuildSchemaSQL(collections []PBCollection) string {
+	var stmts []string
+	for _, coll := range schemaCollections(collections) {
+		if coll.Type == "view" {
+			stmts = append(stmts, BuildCreateViewSQL(coll))
+		} else {
+			stmts = append(stmts, BuildCreateTableSQL(coll))
+		}
+	}
+	return strings.Join(stmts, "\n\n")
+}
+
+// BuildSchemaCache describes the tables the migrator creates for
+// collections, for generating client types before anything is migrated.
+// View collections are left out: their columns are only known once the
+// view query runs in PostgreSQL.
+func BuildSchemaCache(collections []PBCollection) *schema.SchemaCache {
+	sc := &schema.SchemaCache{
+		Tables:  map[string]*schema.Table{},
+		Schemas: []string{"public"},
+	}
+	for _, coll := range schemaCollections(collections) {
+		if coll.Type == "view" {
+			continue
+		}
+		t := &schema.Table{
+			Schema:     "public",
+			Name:       coll.Name,
+			Kind:       "table",
+			PrimaryKey: []string{"id"},
+			Columns: []*schema.Column{
+				{Name: "id", TypeName: "text", IsPrimaryKey: true},
+				{Name: "created", TypeName: "timestamp with time zone", DefaultExpr: "now()"},
+				{Name: "updated", TypeName: "timestamp with time zone", DefaultExpr: "now()"},
+			},
+		}
+		for _, field := range coll.Schema {
+			if field.System {
+				continue
+			}
+			typeName := strings.ToLower(FieldTypeToPgType(field))
+			t.Columns = append(t.Columns, &schema.Column{
+				Name:       field.Name,
+				TypeName:   typeName,
+				IsNullable: !field.Required,
+				IsJSON:     typeName == "jsonb",
+				IsArray:    strings.HasSuffix(typeName, "[]"),
+			})
+		}
+		for i, col := range t.Columns {
+			col.Position = i + 1
+			col.JSONType = schema.ColumnJSONType(col.TypeName)
+		}
+		sc.Tables["public."+coll.Name] = t
+	}
+	return sc
+}
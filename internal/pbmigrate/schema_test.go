@@ -0,0 +1,60 @@
+package pbmigrate
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func schemaTestCollections() []PBCollection {
+	return []PBCollection{
+		{Name: "_superusers", Type: "auth", System: true},
+		{Name: "users", Type: "auth", Schema: []PBField{{Name: "email", Type: "email", System: true}}},
+		{Name: "posts", Type: "base", Schema: []PBField{
+			{Name: "title", Type: "text", Required: true},
+			{Name: "views", Type: "number"},
+			{Name: "tags", Type: "select", MaxSelect: 3},
+			{Name: "meta", Type: "json"},
+		}},
+		{Name: "recent", Type: "view", ViewQuery: "SELECT id, title FROM posts"},
+	}
+}
+
+func TestBuildSchemaSQL(t *testing.T) {
+	t.Parallel()
+	got := BuildSchemaSQL(schemaTestCollections())
+	want := BuildCreateTableSQL(schemaTestCollections()[2]) + "\n\n" +
+		`CREATE VIEW "recent" AS SELECT id, title FROM posts;`
+	testutil.Equal(t, want, got)
+}
+
+func TestBuildSchemaCache(t *testing.T) {
+	t.Parallel()
+	sc := BuildSchemaCache(schemaTestCollections())
+	testutil.Equal(t, 1, len(sc.Tables))
+
+	posts := sc.TableByName("posts")
+	testutil.NotNil(t, posts)
+	testutil.Equal(t, "table", posts.Kind)
+	testutil.Equal(t, 7, len(posts.Columns))
+
+	id := posts.ColumnByName("id")
+	testutil.True(t, id.IsPrimaryKey, "id should be the primary key")
+	testutil.Equal(t, "string", id.JSONType)
+	testutil.Equal(t, "now()", posts.ColumnByName("created").DefaultExpr)
+
+	title := posts.ColumnByName("title")
+	testutil.False(t, title.IsNullable, "required field should be NOT NULL")
+	testutil.Equal(t, "string", title.JSONType)
+
+	views := posts.ColumnByName("views")
+	testutil.True(t, views.IsNullable, "optional field should be nullable")
+	testutil.Equal(t, "number", views.JSONType)
+
+	tags := posts.ColumnByName("tags")
+	testutil.True(t, tags.IsArray, "multi-select should be an array")
+	testutil.Equal(t, "array", tags.JSONType)
+
+	testutil.Equal(t, "object", posts.ColumnByName("meta").JSONType)
+	testutil.Equal(t, 7, posts.ColumnByName("meta").Position)
+}
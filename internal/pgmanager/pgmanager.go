@@ -1,11 +1,14 @@
 package pgmanager
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -17,6 +20,7 @@ import (
 // Config holds settings for the managed Postgres manager.
 type Config struct {
 	Port        uint32 // default 15432
+	Version     string // PostgreSQL major version (default DefaultVersion)
 	DataDir     string // persistent data directory (default ~/.ayb/data)
 	RuntimeDir  string // ephemeral runtime directory (default ~/.ayb/run)
 	BinCacheDir string // binary cache directory (default ~/.ayb/pg)
@@ -34,12 +38,30 @@ type Manager struct {
 }
 
 const (
-	dbName    = "ayb"
-	dbUser    = "ayb"
-	dbPass    = "ayb"
-	pgVersion = "16"
+	dbName = "ayb"
+	dbUser = "ayb"
+	dbPass = "ayb"
 )
 
+// DefaultVersion is the PostgreSQL major version run when Config.Version
+// is empty.
+const DefaultVersion = "16"
+
+// versions maps the supported PostgreSQL major versions to the releases
+// that are downloaded for them.
+var versions = map[string]embeddedpostgres.PostgresVersion{
+	"14": embeddedpostgres.V14,
+	"15": embeddedpostgres.V15,
+	"16": embeddedpostgres.V16,
+	"17": embeddedpostgres.V17,
+}
+
+// SupportedVersions returns the PostgreSQL major versions that can be run,
+// in ascending order.
+func SupportedVersions() []string {
+	return slices.Sorted(maps.Keys(versions))
+}
+
 // New creates a new Manager. Does not start anything.
 func New(cfg Config) *Manager {
 	if cfg.Logger == nil {
@@ -51,92 +73,126 @@ func New(cfg Config) *Manager {
 	}
 }
 
+// paths are the resolved locations and settings of a Manager.
+type paths struct {
+	home    string
+	data    string
+	runtime string
+	cache   string
+	port    uint32
+	version string
+}
+
+// binDir returns the directory the binaries of a PostgreSQL major version
+// are extracted to. Each version has its own, so a version change doesn't
+// run the previous version's binaries.
+func (p paths) binDir(version string) string {
+	return filepath.Join(p.home, "pgbin", version)
+}
+
+// resolve returns the manager's paths and settings, defaulting to ~/.ayb/
+// subdirectories, and checks that its version is supported.
+func (m *Manager) resolve() (paths, error) {
+	home, err := aybHome()
+	if err != nil {
+		return paths{}, fmt.Errorf("resolving ayb home: %w", err)
+	}
+	p := paths{
+		home:    home,
+		data:    cmp.Or(m.cfg.DataDir, filepath.Join(home, "data")),
+		runtime: cmp.Or(m.cfg.RuntimeDir, filepath.Join(home, "run")),
+		cache:   cmp.Or(m.cfg.BinCacheDir, filepath.Join(home, "pg")),
+		port:    cmp.Or(m.cfg.Port, 15432),
+		version: cmp.Or(m.cfg.Version, DefaultVersion),
+	}
+	if _, ok := versions[p.version]; !ok {
+		return paths{}, fmt.Errorf("unsupported PostgreSQL version %q: supported versions are %s",
+			p.version, strings.Join(SupportedVersions(), ", "))
+	}
+	return p, nil
+}
+
+// newDatabase configures a PostgreSQL server of the given major version.
+func (m *Manager) newDatabase(p paths, version string, port uint32, dataDir, runtimeDir string) *embeddedpostgres.EmbeddedPostgres {
+	return embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(port).
+		DataPath(dataDir).
+		RuntimePath(runtimeDir).
+		BinariesPath(p.binDir(version)).
+		CachePath(p.cache).
+		Version(versions[version]).
+		Database(dbName).
+		Username(dbUser).
+		Password(dbPass).
+		Logger(newLogWriter(m.logger)).
+		StartTimeout(60 * time.Second))
+}
+
 // Start downloads PG binaries (on first run), initializes the data directory,
 // starts the PostgreSQL child process, and returns a connection URL.
+// A data directory created by another major version is refused with a
+// *VersionMismatchError rather than reinitialized.
 func (m *Manager) Start(ctx context.Context) (string, error) {
 	if m.running {
 		return m.connURL, nil
 	}
 
-	// Resolve paths, defaulting to ~/.ayb/ subdirectories.
-	home, err := aybHome()
+	p, err := m.resolve()
 	if err != nil {
-		return "", fmt.Errorf("resolving ayb home: %w", err)
+		return "", err
 	}
 
-	dataDir := m.cfg.DataDir
-	if dataDir == "" {
-		dataDir = filepath.Join(home, "data")
-	}
-	runtimeDir := m.cfg.RuntimeDir
-	if runtimeDir == "" {
-		runtimeDir = filepath.Join(home, "run")
-	}
-	cacheDir := m.cfg.BinCacheDir
-	if cacheDir == "" {
-		cacheDir = filepath.Join(home, "pg")
+	if err := checkDataDirVersion(p.data, p.version); err != nil {
+		return "", err
 	}
 
-	port := m.cfg.Port
-	if port == 0 {
-		port = 15432
-	}
-
-	binDir := filepath.Join(home, "pgbin")
-
 	// Ensure directories exist.
-	for _, dir := range []string{dataDir, runtimeDir, cacheDir, binDir} {
+	for _, dir := range []string{p.data, p.runtime, p.cache, p.binDir(p.version)} {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return "", fmt.Errorf("creating directory %s: %w", dir, err)
 		}
 	}
 
 	// Check for orphaned process.
-	m.pidFile = filepath.Join(home, "pg.pid")
+	m.pidFile = filepath.Join(p.home, "pg.pid")
 	cleanupOrphan(m.pidFile, m.logger)
 
 	// Check if first run (no cached binaries).
-	if _, err := os.Stat(cacheDir); err == nil {
-		entries, _ := os.ReadDir(cacheDir)
+	if _, err := os.Stat(p.cache); err == nil {
+		entries, _ := os.ReadDir(p.cache)
 		if len(entries) == 0 {
 			m.logger.Info("downloading PostgreSQL binaries (first run only)...")
 		}
 	}
 
-	m.db = embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
-		Port(port).
-		DataPath(dataDir).
-		RuntimePath(runtimeDir).
-		BinariesPath(binDir).
-		CachePath(cacheDir).
-		Version(embeddedpostgres.V16).
-		Database(dbName).
-		Username(dbUser).
-		Password(dbPass).
-		Logger(newLogWriter(m.logger)).
-		StartTimeout(60 * time.Second))
-
+	m.db = m.newDatabase(p, p.version, p.port, p.data, p.runtime)
 	if err := m.db.Start(); err != nil {
 		return "", fmt.Errorf("starting managed postgres: %w", err)
 	}
 
 	// Write our PID file by reading the Postgres postmaster.pid.
-	pgPidFile := filepath.Join(dataDir, "postmaster.pid")
+	pgPidFile := filepath.Join(p.data, "postmaster.pid")
 	if pid, err := readPostmasterPID(pgPidFile); err == nil && pid > 0 {
 		_ = writePID(m.pidFile, pid)
 	}
 
-	m.connURL = fmt.Sprintf("postgresql://%s:%s@127.0.0.1:%d/%s?sslmode=disable",
-		dbUser, dbPass, port, dbName)
+	m.connURL = connURL(p.port)
 	m.running = true
 
 	m.logger.Info("managed postgres started",
-		"port", port,
-		"data", dataDir,
+		"port", p.port,
+		"version", p.version,
+		"data", p.data,
 	)
 	return m.connURL, nil
 }
 
+// connURL returns the URL of the managed database on port.
+func connURL(port uint32) string {
+	return fmt.Sprintf("postgresql://%s:%s@127.0.0.1:%d/%s?sslmode=disable",
+		dbUser, dbPass, port, dbName)
+}
+
 // Stop gracefully shuts down the managed PostgreSQL child process.
 func (m *Manager) Stop() error {
 	if !m.running || m.db == nil {
@@ -0,0 +1,181 @@
+package pgmanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUpToDate is returned by Upgrade when the data directory already
+// belongs to the configured version.
+var ErrUpToDate = errors.New("data directory is already at the configured PostgreSQL version")
+
+// VersionMismatchError reports a data directory created by a different
+// PostgreSQL major version than the one configured. Postgres can't open it,
+// so it has to be upgraded first.
+type VersionMismatchError struct {
+	DataDir     string
+	DataVersion string // major version that created DataDir
+	Version     string // configured major version
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("data directory %s was created by PostgreSQL %s, but database.embedded_version is %s: "+
+		"run 'ayb db upgrade-embedded' to move the data to PostgreSQL %s, or set embedded_version = %q to keep using it",
+		e.DataDir, e.DataVersion, e.Version, e.Version, e.DataVersion)
+}
+
+// dataDirVersion returns the major version recorded in dataDir's
+// PG_VERSION file, or "" if dataDir hasn't been initialized.
+func dataDirVersion(dataDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, "PG_VERSION"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading PostgreSQL data directory version: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// checkDataDirVersion returns a *VersionMismatchError if dataDir was
+// initialized by a major version other than version.
+func checkDataDirVersion(dataDir, version string) error {
+	dataVersion, err := dataDirVersion(dataDir)
+	if err != nil {
+		return err
+	}
+	if dataVersion != "" && dataVersion != version {
+		return &VersionMismatchError{DataDir: dataDir, DataVersion: dataVersion, Version: version}
+	}
+	return nil
+}
+
+// Upgrade moves the data directory to the configured PostgreSQL version by
+// dumping it from a server of its own version and restoring the dump into
+// a new data directory. The old directory is kept next to it, and its path
+// is returned. The manager must not be running.
+//
+// pg_dump and psql are taken from the downloaded binaries when they include
+// them, otherwise from PATH.
+func (m *Manager) Upgrade(ctx context.Context) (backupDir string, err error) {
+	p, err := m.resolve()
+	if err != nil {
+		return "", err
+	}
+	from, err := dataDirVersion(p.data)
+	if err != nil {
+		return "", err
+	}
+	if from == "" {
+		return "", fmt.Errorf("no PostgreSQL data directory to upgrade at %s", p.data)
+	}
+	if from == p.version {
+		return "", ErrUpToDate
+	}
+	if _, ok := versions[from]; !ok {
+		return "", fmt.Errorf("data directory %s is from PostgreSQL %s, which can't be run to upgrade it: supported versions are %s",
+			p.data, from, strings.Join(SupportedVersions(), ", "))
+	}
+
+	newDir := p.data + ".pg" + p.version
+	backupDir = p.data + ".pg" + from
+	if _, err := os.Stat(backupDir); err == nil {
+		return "", fmt.Errorf("%s already exists: move it away before upgrading", backupDir)
+	}
+	if err := os.RemoveAll(newDir); err != nil {
+		return "", fmt.Errorf("removing %s: %w", newDir, err)
+	}
+
+	cleanupOrphan(filepath.Join(p.home, "pg.pid"), m.logger)
+
+	m.logger.Info("starting PostgreSQL to dump the data directory", "version", from)
+	oldDB := m.newDatabase(p, from, p.port, p.data, filepath.Join(p.runtime, "upgrade-"+from))
+	if err := oldDB.Start(); err != nil {
+		return "", fmt.Errorf("starting PostgreSQL %s: %w", from, err)
+	}
+	defer func() { _ = oldDB.Stop() }()
+
+	m.logger.Info("starting PostgreSQL to restore into a new data directory", "version", p.version)
+	newDB := m.newDatabase(p, p.version, p.port+1, newDir, filepath.Join(p.runtime, "upgrade-"+p.version))
+	if err := newDB.Start(); err != nil {
+		return "", fmt.Errorf("starting PostgreSQL %s: %w", p.version, err)
+	}
+	defer func() { _ = newDB.Stop() }()
+
+	// The binaries of the newer version dump older servers correctly.
+	toolDirs := []string{filepath.Join(p.binDir(p.version), "bin"), filepath.Join(p.binDir(from), "bin")}
+	if err := dumpRestore(ctx, toolDirs, connURL(p.port), connURL(p.port+1)); err != nil {
+		return "", err
+	}
+
+	if err := oldDB.Stop(); err != nil {
+		return "", fmt.Errorf("stopping PostgreSQL %s: %w", from, err)
+	}
+	if err := newDB.Stop(); err != nil {
+		return "", fmt.Errorf("stopping PostgreSQL %s: %w", p.version, err)
+	}
+	if err := os.Rename(p.data, backupDir); err != nil {
+		return "", fmt.Errorf("moving the old data directory: %w", err)
+	}
+	if err := os.Rename(newDir, p.data); err != nil {
+		return "", fmt.Errorf("moving the new data directory into place: %w", err)
+	}
+	m.logger.Info("managed postgres upgraded", "from", from, "to", p.version, "backup", backupDir)
+	return backupDir, nil
+}
+
+// dumpRestore pipes pg_dump of fromURL into psql on toURL, stopping at the
+// first error.
+func dumpRestore(ctx context.Context, toolDirs []string, fromURL, toURL string) error {
+	pgDump, err := findTool("pg_dump", toolDirs)
+	if err != nil {
+		return err
+	}
+	psql, err := findTool("psql", toolDirs)
+	if err != nil {
+		return err
+	}
+
+	var dumpErr, restoreErr bytes.Buffer
+	dump := exec.CommandContext(ctx, pgDump, "--dbname="+fromURL)
+	restore := exec.CommandContext(ctx, psql, "--dbname="+toURL, "--quiet", "--set=ON_ERROR_STOP=1")
+	dump.Stderr = &dumpErr
+	restore.Stdout = &restoreErr
+	restore.Stderr = &restoreErr
+	if restore.Stdin, err = dump.StdoutPipe(); err != nil {
+		return err
+	}
+	if err := restore.Start(); err != nil {
+		return fmt.Errorf("starting psql: %w", err)
+	}
+	if err := dump.Run(); err != nil {
+		_ = restore.Wait()
+		return fmt.Errorf("pg_dump failed: %w: %s", err, strings.TrimSpace(dumpErr.String()))
+	}
+	if err := restore.Wait(); err != nil {
+		return fmt.Errorf("psql failed: %w: %s", err, strings.TrimSpace(restoreErr.String()))
+	}
+	return nil
+}
+
+// findTool returns the path of a PostgreSQL client program from the first
+// of dirs that has it, or from PATH.
+func findTool(name string, dirs []string) (string, error) {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s not found in the PostgreSQL binaries or PATH: install PostgreSQL client tools", name)
+	}
+	return path, nil
+}
@@ -0,0 +1,97 @@
+package pgmanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+// dataDirOfVersion returns a data directory whose PG_VERSION is version.
+func dataDirOfVersion(t *testing.T, version string) string {
+	t.Helper()
+	dir := t.TempDir()
+	testutil.NoError(t, os.WriteFile(filepath.Join(dir, "PG_VERSION"), []byte(version+"\n"), 0o600))
+	return dir
+}
+
+func TestCheckDataDirVersion(t *testing.T) {
+	t.Parallel()
+	testutil.NoError(t, checkDataDirVersion(t.TempDir(), "16"))
+	testutil.NoError(t, checkDataDirVersion(filepath.Join(t.TempDir(), "missing"), "16"))
+	testutil.NoError(t, checkDataDirVersion(dataDirOfVersion(t, "16"), "16"))
+
+	dir := dataDirOfVersion(t, "15")
+	err := checkDataDirVersion(dir, "16")
+	var mismatch *VersionMismatchError
+	testutil.True(t, errors.As(err, &mismatch), "expected a VersionMismatchError")
+	testutil.Equal(t, dir, mismatch.DataDir)
+	testutil.Equal(t, "15", mismatch.DataVersion)
+	testutil.Equal(t, "16", mismatch.Version)
+	testutil.Contains(t, err.Error(), "ayb db upgrade-embedded")
+	testutil.Contains(t, err.Error(), `embedded_version = "15"`)
+}
+
+func TestStartRefusesOtherVersionDataDir(t *testing.T) {
+	t.Parallel()
+	dir := dataDirOfVersion(t, "15")
+	m := New(Config{Version: "16", DataDir: dir, Logger: testutil.DiscardLogger()})
+
+	_, err := m.Start(context.Background())
+	var mismatch *VersionMismatchError
+	testutil.True(t, errors.As(err, &mismatch), "expected a VersionMismatchError")
+	testutil.False(t, m.IsRunning(), "should not be running")
+
+	// The data directory is left as it was.
+	data, err := os.ReadFile(filepath.Join(dir, "PG_VERSION"))
+	testutil.NoError(t, err)
+	testutil.Equal(t, "15\n", string(data))
+}
+
+func TestStartRejectsUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+	m := New(Config{Version: "9", DataDir: t.TempDir(), Logger: testutil.DiscardLogger()})
+	_, err := m.Start(context.Background())
+	testutil.ErrorContains(t, err, `unsupported PostgreSQL version "9"`)
+}
+
+func TestSupportedVersions(t *testing.T) {
+	t.Parallel()
+	got := SupportedVersions()
+	testutil.SliceLen(t, got, len(versions))
+	testutil.Equal(t, "14", got[0])
+	testutil.Equal(t, "17", got[len(got)-1])
+	_, ok := versions[DefaultVersion]
+	testutil.True(t, ok, "default version should be supported")
+}
+
+func TestUpgradeChecksDataDir(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	_, err := New(Config{Version: "16", DataDir: dataDirOfVersion(t, "16"), Logger: testutil.DiscardLogger()}).Upgrade(ctx)
+	testutil.True(t, errors.Is(err, ErrUpToDate), "expected ErrUpToDate")
+
+	_, err = New(Config{Version: "16", DataDir: t.TempDir(), Logger: testutil.DiscardLogger()}).Upgrade(ctx)
+	testutil.ErrorContains(t, err, "no PostgreSQL data directory to upgrade")
+
+	_, err = New(Config{Version: "16", DataDir: dataDirOfVersion(t, "12"), Logger: testutil.DiscardLogger()}).Upgrade(ctx)
+	testutil.ErrorContains(t, err, "is from PostgreSQL 12")
+}
+
+func TestFindTool(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pg_dump")
+	testutil.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+
+	got, err := findTool("pg_dump", []string{filepath.Join(t.TempDir(), "bin"), dir})
+	testutil.NoError(t, err)
+	testutil.Equal(t, path, got)
+
+	_, err = findTool("ayb-no-such-tool", []string{dir})
+	testutil.ErrorContains(t, err, "ayb-no-such-tool not found")
+}
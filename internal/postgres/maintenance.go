@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaintenanceOptions selects the operations RunMaintenance performs. The JSON
+// form is the db_maintenance job payload.
+type MaintenanceOptions struct {
+	Vacuum  bool `json:"vacuum"`
+	Analyze bool `json:"analyze"`
+	Reindex bool `json:"reindex"`
+}
+
+// WithDefault returns o, or VACUUM and ANALYZE when no operation is
+// selected.
+func (o MaintenanceOptions) WithDefault() MaintenanceOptions {
+	if !o.Vacuum && !o.Analyze && !o.Reindex {
+		return MaintenanceOptions{Vacuum: true, Analyze: true}
+	}
+	return o
+}
+
+// MaintenanceStep is one statement run against one table.
+type MaintenanceStep struct {
+	Table string // schema-qualified, quoted
+	SQL   string
+}
+
+// maintenanceTablesQuery lists the tables and materialized views outside the
+// system schemas. Partitions are listed individually rather than through
+// their parent, so each step only touches one table's storage.
+const maintenanceTablesQuery = `
+SELECT n.nspname, c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'm')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+  AND n.nspname NOT LIKE 'pg_toast%'
+  AND n.nspname NOT LIKE 'pg_temp%'
+ORDER BY n.nspname, c.relname`
+
+// MaintenanceTables returns the quoted, schema-qualified names of the tables
+// RunMaintenance works on.
+func MaintenanceTables(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	rows, err := pool.Query(ctx, maintenanceTablesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			return nil, fmt.Errorf("scanning table: %w", err)
+		}
+		tables = append(tables, pgx.Identifier{schema, name}.Sanitize())
+	}
+	return tables, rows.Err()
+}
+
+// MaintenancePlan returns the statements opts runs on tables, in order. Only
+// the variants that let reads and writes continue are used: VACUUM and
+// ANALYZE skip tables whose lock they can't take immediately, and indexes
+// are rebuilt with REINDEX CONCURRENTLY. VACUUM FULL is never used.
+func MaintenancePlan(tables []string, opts MaintenanceOptions) []MaintenanceStep {
+	var steps []MaintenanceStep
+	for _, table := range tables {
+		switch {
+		case opts.Vacuum && opts.Analyze:
+			steps = append(steps, MaintenanceStep{table, "VACUUM (SKIP_LOCKED, ANALYZE) " + table})
+		case opts.Vacuum:
+			steps = append(steps, MaintenanceStep{table, "VACUUM (SKIP_LOCKED) " + table})
+		case opts.Analyze:
+			steps = append(steps, MaintenanceStep{table, "ANALYZE (SKIP_LOCKED) " + table})
+		}
+		if opts.Reindex {
+			steps = append(steps, MaintenanceStep{table, "REINDEX TABLE CONCURRENTLY " + table})
+		}
+	}
+	return steps
+}
+
+// RunMaintenance runs steps one at a time, calling progress after each
+// successful step with its position and duration. It stops at the first
+// failure. A failed REINDEX CONCURRENTLY can leave an invalid index behind
+// (named with a _ccnew suffix) that should be dropped.
+func RunMaintenance(ctx context.Context, pool *pgxpool.Pool, steps []MaintenanceStep, progress func(i int, step MaintenanceStep, took time.Duration)) error {
+	for i, step := range steps {
+		start := time.Now()
+		if _, err := pool.Exec(ctx, step.SQL); err != nil {
+			return fmt.Errorf("%s: %w", step.SQL, err)
+		}
+		if progress != nil {
+			progress(i, step, time.Since(start))
+		}
+	}
+	return nil
+}
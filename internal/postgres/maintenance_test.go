@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestMaintenanceOptionsWithDefault(t *testing.T) {
+	testutil.Equal(t, MaintenanceOptions{Vacuum: true, Analyze: true}, MaintenanceOptions{}.WithDefault())
+	testutil.Equal(t, MaintenanceOptions{Reindex: true}, MaintenanceOptions{Reindex: true}.WithDefault())
+}
+
+func TestMaintenancePlan(t *testing.T) {
+	tables := []string{`"public"."posts"`, `"public"."users"`}
+	tests := []struct {
+		name string
+		opts MaintenanceOptions
+		want []string
+	}{
+		{"vacuum and analyze", MaintenanceOptions{Vacuum: true, Analyze: true}, []string{
+			`VACUUM (SKIP_LOCKED, ANALYZE) "public"."posts"`,
+			`VACUUM (SKIP_LOCKED, ANALYZE) "public"."users"`,
+		}},
+		{"vacuum", MaintenanceOptions{Vacuum: true}, []string{
+			`VACUUM (SKIP_LOCKED) "public"."posts"`,
+			`VACUUM (SKIP_LOCKED) "public"."users"`,
+		}},
+		{"analyze", MaintenanceOptions{Analyze: true}, []string{
+			`ANALYZE (SKIP_LOCKED) "public"."posts"`,
+			`ANALYZE (SKIP_LOCKED) "public"."users"`,
+		}},
+		{"reindex after vacuum on each table", MaintenanceOptions{Vacuum: true, Reindex: true}, []string{
+			`VACUUM (SKIP_LOCKED) "public"."posts"`,
+			`REINDEX TABLE CONCURRENTLY "public"."posts"`,
+			`VACUUM (SKIP_LOCKED) "public"."users"`,
+			`REINDEX TABLE CONCURRENTLY "public"."users"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := MaintenancePlan(tables, tt.opts)
+			var got []string
+			for _, s := range steps {
+				got = append(got, s.SQL)
+			}
+			testutil.Equal(t, len(tt.want), len(got))
+			for i := range tt.want {
+				testutil.Equal(t, tt.want[i], got[i])
+			}
+		})
+	}
+}
@@ -2,14 +2,25 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Delays between connection attempts while waiting for the database, doubling
+// from connectRetryBase up to connectRetryMax.
+const (
+	connectRetryBase = 100 * time.Millisecond
+	connectRetryMax  = 5 * time.Second
+)
+
 // Pool wraps a pgx connection pool with health checking and lifecycle management.
 type Pool struct {
 	pool            *pgxpool.Pool
@@ -27,6 +38,36 @@ type Config struct {
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
 	HealthCheckSecs int
+	SSL             SSLConfig
+	// ConnectTimeout is how long New keeps retrying, with backoff, while the
+	// database is unreachable or still starting up; 0 means a single attempt.
+	ConnectTimeout time.Duration
+}
+
+// SSLConfig holds TLS settings that override the matching sslmode,
+// sslrootcert, sslcert, and sslkey parameters of the URL. Empty fields leave
+// the URL's value, or pgx's default, in place.
+type SSLConfig struct {
+	Mode     string // disable, allow, prefer, require, verify-ca, or verify-full
+	RootCert string // CA certificate file for verify-ca and verify-full
+	Cert     string // client certificate file
+	Key      string // client key file
+}
+
+// params returns the connection parameters for the non-empty fields.
+func (c SSLConfig) params() [][2]string {
+	var params [][2]string
+	for _, p := range [][2]string{
+		{"sslmode", c.Mode},
+		{"sslrootcert", c.RootCert},
+		{"sslcert", c.Cert},
+		{"sslkey", c.Key},
+	} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+	return params
 }
 
 // New creates a new Pool, validates the connection, and starts health checking.
@@ -35,9 +76,9 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Pool, error) {
 		return nil, fmt.Errorf("database URL is required")
 	}
 
-	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	poolCfg, err := parseConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("parsing database URL: %w", err)
+		return nil, err
 	}
 
 	poolCfg.MaxConns = cfg.MaxConns
@@ -49,6 +90,70 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Pool, error) {
 		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 	}
 
+	pool, err := connectWithRetry(ctx, poolCfg, cfg.ConnectTimeout, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		pool:            pool,
+		healthCheckStop: make(chan struct{}),
+		logger:          logger,
+	}
+
+	// Start periodic health checks.
+	if cfg.HealthCheckSecs > 0 {
+		p.startHealthCheck(time.Duration(cfg.HealthCheckSecs) * time.Second)
+	}
+
+	return p, nil
+}
+
+// connectWithRetry calls connect until it succeeds, fails with an error
+// retrying can't fix, or timeout has passed.
+func connectWithRetry(ctx context.Context, poolCfg *pgxpool.Config, timeout time.Duration, logger *slog.Logger) (*pgxpool.Pool, error) {
+	if timeout <= 0 {
+		return connect(ctx, poolCfg, logger)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := connectRetryBase
+	for attempt := 1; ; attempt++ {
+		pool, err := connect(ctx, poolCfg, logger)
+		if err == nil || !retryableConnectError(err) {
+			return pool, err
+		}
+		deadline, _ := ctx.Deadline()
+		if time.Until(deadline) < delay {
+			return nil, fmt.Errorf("database not ready within connect timeout of %s (%d attempts): %w", timeout, attempt, err)
+		}
+		logger.Warn("database not ready, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for database: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, connectRetryMax)
+	}
+}
+
+// retryableConnectError reports whether a failed connection may succeed
+// later: the server was unreachable, or answered that it is starting up.
+// Other errors from the server, such as a wrong password, are final.
+func retryableConnectError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "57P03" // cannot_connect_now
+	}
+	return true
+}
+
+// connect opens a pool and checks that the database answers.
+func connect(ctx context.Context, poolCfg *pgxpool.Config, logger *slog.Logger) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating connection pool: %w", err)
@@ -67,19 +172,48 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (*Pool, error) {
 		return nil, fmt.Errorf("querying server version: %w", err)
 	}
 	logger.Info("connected to PostgreSQL", "version", version)
+	return pool, nil
+}
 
-	p := &Pool{
-		pool:            pool,
-		healthCheckStop: make(chan struct{}),
-		logger:          logger,
+// parseConfig parses cfg.URL with cfg.SSL applied on top.
+func parseConfig(cfg Config) (*pgxpool.Config, error) {
+	connString, err := withParams(cfg.URL, cfg.SSL.params())
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
 	}
-
-	// Start periodic health checks.
-	if cfg.HealthCheckSecs > 0 {
-		p.startHealthCheck(time.Duration(cfg.HealthCheckSecs) * time.Second)
+	poolCfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
 	}
+	return poolCfg, nil
+}
 
-	return p, nil
+// withParams sets params on a connection string in either URL or
+// keyword/value form, replacing any the string already has.
+func withParams(connString string, params [][2]string) (string, error) {
+	if len(params) == 0 {
+		return connString, nil
+	}
+	if strings.HasPrefix(connString, "postgres://") || strings.HasPrefix(connString, "postgresql://") {
+		u, err := url.Parse(connString)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p[0], p[1])
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	// In keyword/value form a later setting wins over an earlier one.
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	var b strings.Builder
+	b.WriteString(connString)
+	for _, p := range params {
+		fmt.Fprintf(&b, " %s='%s'", p[0], quote.Replace(p[1]))
+	}
+	return b.String(), nil
 }
 
 // DB returns the underlying pgxpool.Pool for executing queries.
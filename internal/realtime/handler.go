@@ -3,6 +3,7 @@ package realtime
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,6 +23,7 @@ type Handler struct {
 	pool        *pgxpool.Pool // nil when RLS filtering unavailable
 	authSvc     *auth.Service // nil when auth disabled
 	schemaCache *schema.CacheHolder
+	tenants     tenant.Checker // nil when schema-per-tenant mode is off
 	logger      *slog.Logger
 }
 
@@ -36,6 +39,13 @@ func NewHandler(hub *Hub, pool *pgxpool.Pool, authSvc *auth.Service, schemaCache
 	}
 }
 
+// SetTenants enables schema-per-tenant mode: clients authenticated with a
+// JWT only receive events from their tenant's schema, and are refused if
+// it doesn't exist.
+func (h *Handler) SetTenants(c tenant.Checker) {
+	h.tenants = c
+}
+
 // ServeHTTP handles GET /api/realtime with Server-Sent Events.
 //
 // Query parameters:
@@ -78,6 +88,22 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var tenantID string
+	if h.tenants != nil && claims != nil {
+		var err error
+		tenantID, err = tenant.Resolve(r.Context(), h.tenants, claims)
+		if errors.Is(err, tenant.ErrNoTenant) || errors.Is(err, tenant.ErrNotFound) {
+			httputil.WriteErrorWithDocURL(w, http.StatusForbidden, err.Error(),
+				"https://allyourbase.io/guide/tenants")
+			return
+		}
+		if err != nil {
+			h.logger.Error("realtime tenant lookup error", "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+	}
+
 	// Parse and validate table subscriptions.
 	tablesParam := r.URL.Query().Get("tables")
 	if tablesParam == "" {
@@ -107,7 +133,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Subscribe and ensure cleanup on disconnect.
-	client := h.hub.Subscribe(tables)
+	client := h.hub.SubscribeTenant(tables, tenantID)
 	defer h.hub.Unsubscribe(client.ID)
 
 	// Set SSE headers.
@@ -124,6 +150,9 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Stream events until the client disconnects.
 	ctx := r.Context()
+	if tenantID != "" {
+		ctx = tenant.WithID(ctx, tenantID)
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -186,7 +215,7 @@ func (h *Handler) serveOAuthSSE(w http.ResponseWriter, r *http.Request, flusher
 }
 
 // canSeeRecord checks whether the authenticated user can see the event's record
-// via an RLS-scoped SELECT. This per-event SELECT is evaluated by Postgres
+// via an RLS-scoped SELECT, in the tenant's schema when ctx has a tenant. This per-event SELECT is evaluated by Postgres
 // under the ayb_authenticated role, so full RLS policy logic applies, including
 // join/EXISTS-based policies on related tables.
 //
@@ -208,6 +237,7 @@ func (h *Handler) canSeeRecord(ctx context.Context, claims *auth.Claims, event *
 	if tbl == nil || len(tbl.PrimaryKey) == 0 {
 		return true
 	}
+	tbl = tenant.Table(ctx, tbl)
 
 	query, args := buildVisibilityCheck(tbl, event.Record)
 	if query == "" {
@@ -225,6 +255,10 @@ func (h *Handler) canSeeRecord(ctx context.Context, claims *auth.Claims, event *
 		h.logger.Error("rls filter: set rls context", "error", err)
 		return false
 	}
+	if err := tenant.SetSearchPath(ctx, tx); err != nil {
+		h.logger.Error("rls filter: set tenant search_path", "error", err)
+		return false
+	}
 
 	var one int
 	err = tx.QueryRow(ctx, query, args...).Scan(&one)
@@ -17,6 +17,7 @@ type Event struct {
 	Action string         `json:"action"` // "create", "update", "delete"
 	Table  string         `json:"table"`
 	Record map[string]any `json:"record"`
+	Tenant string         `json:"tenant,omitempty"` // set in schema-per-tenant mode
 }
 
 // Hub manages realtime SSE client connections and broadcasts events.
@@ -32,6 +33,7 @@ type Hub struct {
 type Client struct {
 	ID      string
 	tables  map[string]bool
+	tenant  string // receives only this tenant's events; "" = non-tenant events
 	events  chan *Event
 	oauthCh chan *auth.OAuthEvent // non-nil only for OAuth SSE clients
 }
@@ -56,10 +58,17 @@ func NewHub(logger *slog.Logger) *Hub {
 
 // Subscribe creates a new client subscribed to the given tables and registers it.
 func (h *Hub) Subscribe(tables map[string]bool) *Client {
+	return h.SubscribeTenant(tables, "")
+}
+
+// SubscribeTenant is Subscribe for a client that only receives events for
+// changes in the given tenant's schema.
+func (h *Hub) SubscribeTenant(tables map[string]bool, tenantID string) *Client {
 	id := fmt.Sprintf("c%d", h.nextID.Add(1))
 	client := &Client{
 		ID:     id,
 		tables: tables,
+		tenant: tenantID,
 		events: make(chan *Event, eventBufferSize),
 	}
 
@@ -140,14 +149,15 @@ func (h *Hub) Unsubscribe(clientID string) {
 	}
 }
 
-// Publish sends an event to all clients subscribed to the event's table.
-// Uses non-blocking sends — events are dropped for clients with full buffers.
+// Publish sends an event to all clients of the event's tenant subscribed to
+// the event's table. Uses non-blocking sends — events are dropped for
+// clients with full buffers.
 func (h *Hub) Publish(event *Event) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	for _, client := range h.clients {
-		if !client.tables[event.Table] {
+		if !client.tables[event.Table] || client.tenant != event.Tenant {
 			continue
 		}
 		select {
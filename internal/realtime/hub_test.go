@@ -56,6 +56,35 @@ func TestPublishToNonSubscribedTable(t *testing.T) {
 	}
 }
 
+func TestPublishOnlyToEventTenant(t *testing.T) {
+	t.Parallel()
+	hub := realtime.NewHub(testutil.DiscardLogger())
+
+	tables := map[string]bool{"posts": true}
+	acme := hub.SubscribeTenant(tables, "acme")
+	defer hub.Unsubscribe(acme.ID)
+	globex := hub.SubscribeTenant(tables, "globex")
+	defer hub.Unsubscribe(globex.ID)
+	shared := hub.Subscribe(tables)
+	defer hub.Unsubscribe(shared.ID)
+
+	hub.Publish(&realtime.Event{Action: "create", Table: "posts", Record: map[string]any{"id": 1}, Tenant: "acme"})
+
+	select {
+	case got := <-acme.Events():
+		testutil.Equal(t, "acme", got.Tenant)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for tenant event")
+	}
+	for _, c := range []*realtime.Client{globex, shared} {
+		select {
+		case <-c.Events():
+			t.Fatalf("client %s received another tenant's event", c.ID)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
 func TestUnsubscribeRemovesClient(t *testing.T) {
 	t.Parallel()
 	hub := realtime.NewHub(testutil.DiscardLogger())
@@ -15,13 +15,17 @@ type Template string
 const (
 	TemplateReact   Template = "react"
 	TemplateNext    Template = "next"
+	TemplateSvelte  Template = "svelte"
 	TemplateExpress Template = "express"
 	TemplatePlain   Template = "plain"
 )
 
+// typesPath is where generated TypeScript types live in a project.
+const typesPath = "src/types/ayb.d.ts"
+
 // ValidTemplates returns all valid template names.
 func ValidTemplates() []Template {
-	return []Template{TemplateReact, TemplateNext, TemplateExpress, TemplatePlain}
+	return []Template{TemplateReact, TemplateNext, TemplateSvelte, TemplateExpress, TemplatePlain}
 }
 
 // IsValidTemplate checks if a template name is valid.
@@ -42,6 +46,11 @@ type Options struct {
 	Template Template
 	// Dir is the parent directory (defaults to ".").
 	Dir string
+	// SchemaSQL replaces the starter schema.sql when set, e.g. with the
+	// schema of a database the project is created from.
+	SchemaSQL string
+	// Types is written to src/types/ayb.d.ts when set.
+	Types string
 }
 
 // Run creates the scaffolded project.
@@ -84,6 +93,12 @@ func generateFiles(opts Options) map[string]string {
 	// Common files for all templates
 	files["ayb.toml"] = aybToml(opts)
 	files["schema.sql"] = schemaSQLFile()
+	if opts.SchemaSQL != "" {
+		files["schema.sql"] = opts.SchemaSQL
+	}
+	if opts.Types != "" {
+		files[typesPath] = opts.Types
+	}
 	files[".env"] = envFile()
 	files[".gitignore"] = gitignoreFile(opts.Template)
 	files["CLAUDE.md"] = claudeMD(opts)
@@ -94,6 +109,8 @@ func generateFiles(opts Options) map[string]string {
 		addReactFiles(files, opts)
 	case TemplateNext:
 		addNextFiles(files, opts)
+	case TemplateSvelte:
+		addSvelteFiles(files, opts)
 	case TemplateExpress:
 		addExpressFiles(files, opts)
 	case TemplatePlain:
@@ -191,6 +208,8 @@ dist/
 	switch tmpl {
 	case TemplateNext:
 		base += ".next/\n"
+	case TemplateSvelte:
+		base += ".svelte-kit/\nbuild/\n"
 	}
 	return base
 }
@@ -249,7 +268,7 @@ func addReactFiles(files map[string]string, opts Options) {
 	files["index.html"] = indexHTML(opts)
 	files["src/main.tsx"] = reactMain()
 	files["src/App.tsx"] = reactApp()
-	files["src/lib/ayb.ts"] = aybClient()
+	files["src/lib/ayb.ts"] = aybClient(viteAYBURL)
 	files["src/index.css"] = minimalCSS()
 }
 
@@ -259,7 +278,19 @@ func addNextFiles(files map[string]string, opts Options) {
 	files["next.config.js"] = nextConfig()
 	files["src/app/layout.tsx"] = nextLayout(opts)
 	files["src/app/page.tsx"] = nextPage()
-	files["src/lib/ayb.ts"] = aybClient()
+	files["src/app/login/page.tsx"] = nextLoginPage()
+	files["src/lib/ayb.ts"] = aybClient(nextAYBURL)
+}
+
+func addSvelteFiles(files map[string]string, opts Options) {
+	files["package.json"] = packageJSON(opts, "svelte")
+	files["tsconfig.json"] = svelteTSConfig()
+	files["svelte.config.js"] = svelteConfig()
+	files["vite.config.ts"] = svelteViteConfig()
+	files["src/app.html"] = svelteAppHTML(opts)
+	files["src/routes/+page.svelte"] = sveltePage()
+	files["src/routes/login/+page.svelte"] = svelteLoginPage()
+	files["src/lib/ayb.ts"] = aybClient(viteAYBURL)
 }
 
 func addExpressFiles(files map[string]string, opts Options) {
@@ -327,6 +358,32 @@ func packageJSON(opts Options, tmpl string) string {
     "typescript": "^5.0.0"
   }
 }
+`, name)
+	case "svelte":
+		return fmt.Sprintf(`{
+  "name": "%s",
+  "private": true,
+  "version": "0.0.1",
+  "type": "module",
+  "scripts": {
+    "dev": "vite dev",
+    "build": "vite build",
+    "preview": "vite preview",
+    "check": "svelte-kit sync && svelte-check --tsconfig ./tsconfig.json"
+  },
+  "dependencies": {
+    "@allyourbase/js": "^0.1.0"
+  },
+  "devDependencies": {
+    "@sveltejs/adapter-auto": "^6.0.0",
+    "@sveltejs/kit": "^2.0.0",
+    "@sveltejs/vite-plugin-svelte": "^5.0.0",
+    "svelte": "^5.0.0",
+    "svelte-check": "^4.0.0",
+    "typescript": "^5.0.0",
+    "vite": "^6.0.0"
+  }
+}
 `, name)
 	case "express":
 		return fmt.Sprintf(`{
@@ -371,10 +428,19 @@ func packageJSON(opts Options, tmpl string) string {
 	}
 }
 
-func aybClient() string {
+// Expressions browser clients read the AYB server URL from: Vite exposes
+// VITE_-prefixed variables and Next.js NEXT_PUBLIC_-prefixed ones.
+const (
+	viteAYBURL = "import.meta.env.VITE_AYB_URL"
+	nextAYBURL = "process.env.NEXT_PUBLIC_AYB_URL"
+)
+
+// aybClient returns a browser client module that reads the server URL from
+// urlExpr.
+func aybClient(urlExpr string) string {
 	return `import { AYBClient } from "@allyourbase/js";
 
-const AYB_URL = import.meta.env.VITE_AYB_URL || "http://localhost:8090";
+const AYB_URL = ` + urlExpr + ` || "http://localhost:8090";
 
 export const ayb = new AYBClient(AYB_URL);
 
@@ -608,12 +674,183 @@ export default function Home() {
           <li key={item.id}>{item.name}</li>
         ))}
       </ul>
+      <p><a href="/login">Log in</a></p>
     </main>
   );
 }
 `
 }
 
+func nextLoginPage() string {
+	return `"use client";
+
+import { FormEvent, useState } from "react";
+import { useRouter } from "next/navigation";
+import { ayb, persistTokens } from "@/lib/ayb";
+
+export default function Login() {
+  const router = useRouter();
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState("");
+
+  async function login(e: FormEvent) {
+    e.preventDefault();
+    setError("");
+    try {
+      await ayb.auth.login(email, password);
+      persistTokens(ayb.token!, ayb.refreshToken!);
+      router.push("/");
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Login failed");
+    }
+  }
+
+  return (
+    <main style={{ maxWidth: 400, margin: "2rem auto", fontFamily: "system-ui" }}>
+      <h1>Log in</h1>
+      <form onSubmit={login}>
+        <input type="email" placeholder="Email" value={email} onChange={(e) => setEmail(e.target.value)} required />
+        <input type="password" placeholder="Password" value={password} onChange={(e) => setPassword(e.target.value)} required />
+        <button type="submit">Log in</button>
+      </form>
+      {error && <p style={{ color: "crimson" }}>{error}</p>}
+    </main>
+  );
+}
+`
+}
+
+func svelteTSConfig() string {
+	return `{
+  "extends": "./.svelte-kit/tsconfig.json",
+  "compilerOptions": {
+    "allowJs": true,
+    "checkJs": true,
+    "esModuleInterop": true,
+    "forceConsistentCasingInFileNames": true,
+    "resolveJsonModule": true,
+    "skipLibCheck": true,
+    "sourceMap": true,
+    "strict": true,
+    "moduleResolution": "bundler"
+  }
+}
+`
+}
+
+func svelteConfig() string {
+	return `import adapter from "@sveltejs/adapter-auto";
+import { vitePreprocess } from "@sveltejs/vite-plugin-svelte";
+
+/** @type {import('@sveltejs/kit').Config} */
+const config = {
+  preprocess: vitePreprocess(),
+  kit: {
+    adapter: adapter(),
+  },
+};
+
+export default config;
+`
+}
+
+func svelteViteConfig() string {
+	return `import { sveltekit } from "@sveltejs/kit/vite";
+import { defineConfig } from "vite";
+
+export default defineConfig({
+  plugins: [sveltekit()],
+});
+`
+}
+
+func svelteAppHTML(opts Options) string {
+	return fmt.Sprintf(`<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width, initial-scale=1" />
+    <title>%s</title>
+    %%sveltekit.head%%
+  </head>
+  <body data-sveltekit-preload-data="hover">
+    <div style="display: contents">%%sveltekit.body%%</div>
+  </body>
+</html>
+`, opts.Name)
+}
+
+func sveltePage() string {
+	return `<script lang="ts">
+  import { onMount } from "svelte";
+  import { ayb } from "$lib/ayb";
+
+  let items = $state<any[]>([]);
+  let status = $state("loading...");
+
+  onMount(() => {
+    ayb.health()
+      .then(() => (status = "connected"))
+      .catch(() => (status = "disconnected — run 'ayb start'"));
+
+    ayb.records
+      .list("items")
+      .then((res) => (items = res.items))
+      .catch(() => {});
+  });
+</script>
+
+<main style="max-width: 600px; margin: 2rem auto; font-family: system-ui">
+  <h1>Welcome to your AYB app</h1>
+  <p>Server: <strong>{status}</strong></p>
+  <h2>Items ({items.length})</h2>
+  <ul>
+    {#each items as item (item.id)}
+      <li>{item.name}</li>
+    {/each}
+  </ul>
+  <p><a href="/login">Log in</a></p>
+</main>
+`
+}
+
+func svelteLoginPage() string {
+	return `<script lang="ts">
+  import { goto } from "$app/navigation";
+  import { ayb, persistTokens } from "$lib/ayb";
+
+  let email = $state("");
+  let password = $state("");
+  let error = $state("");
+
+  async function login(e: SubmitEvent) {
+    e.preventDefault();
+    error = "";
+    try {
+      await ayb.auth.login(email, password);
+      persistTokens(ayb.token!, ayb.refreshToken!);
+      await goto("/");
+    } catch (err) {
+      error = err instanceof Error ? err.message : "Login failed";
+    }
+  }
+</script>
+
+<main style="max-width: 400px; margin: 2rem auto; font-family: system-ui">
+  <h1>Log in</h1>
+  <form onsubmit={login}>
+    <input type="email" placeholder="Email" bind:value={email} required />
+    <input type="password" placeholder="Password" bind:value={password} required />
+    <button type="submit">Log in</button>
+  </form>
+  {#if error}
+    <p style="color: crimson">{error}</p>
+  {/if}
+</main>
+`
+}
+
 func expressTSConfig() string {
 	return `{
   "compilerOptions": {
@@ -12,9 +12,10 @@ import (
 func TestValidTemplates(t *testing.T) {
 	t.Parallel()
 	templates := ValidTemplates()
-	testutil.Equal(t, 4, len(templates))
+	testutil.Equal(t, 5, len(templates))
 	testutil.True(t, IsValidTemplate("react"))
 	testutil.True(t, IsValidTemplate("next"))
+	testutil.True(t, IsValidTemplate("svelte"))
 	testutil.True(t, IsValidTemplate("express"))
 	testutil.True(t, IsValidTemplate("plain"))
 	testutil.False(t, IsValidTemplate("invalid"))
@@ -70,11 +71,47 @@ func TestRun_Next(t *testing.T) {
 	assertFileExists(t, projectDir, "next.config.js")
 	assertFileExists(t, projectDir, "src/app/layout.tsx")
 	assertFileExists(t, projectDir, "src/app/page.tsx")
+	assertFileExists(t, projectDir, "src/app/login/page.tsx")
 	assertFileExists(t, projectDir, "src/lib/ayb.ts")
+	assertFileExists(t, projectDir, "ayb.toml")
+	assertFileExists(t, projectDir, "schema.sql")
+	assertFileExists(t, projectDir, "CLAUDE.md")
 
 	assertFileContains(t, projectDir, "package.json", `"next"`)
 	assertFileContains(t, projectDir, ".gitignore", ".next/")
 	assertFileContains(t, projectDir, "src/app/layout.tsx", "nextapp")
+	assertFileContains(t, projectDir, "src/lib/ayb.ts", "process.env.NEXT_PUBLIC_AYB_URL")
+	assertFileContains(t, projectDir, "src/app/login/page.tsx", "ayb.auth.login")
+}
+
+func TestRun_Svelte(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := Run(Options{Name: "svelteapp", Template: TemplateSvelte, Dir: dir})
+	testutil.NoError(t, err)
+
+	projectDir := filepath.Join(dir, "svelteapp")
+
+	assertFileExists(t, projectDir, "ayb.toml")
+	assertFileExists(t, projectDir, "schema.sql")
+	assertFileExists(t, projectDir, "CLAUDE.md")
+	assertFileExists(t, projectDir, "package.json")
+	assertFileExists(t, projectDir, "tsconfig.json")
+	assertFileExists(t, projectDir, "svelte.config.js")
+	assertFileExists(t, projectDir, "vite.config.ts")
+	assertFileExists(t, projectDir, "src/app.html")
+	assertFileExists(t, projectDir, "src/routes/+page.svelte")
+	assertFileExists(t, projectDir, "src/routes/login/+page.svelte")
+	assertFileExists(t, projectDir, "src/lib/ayb.ts")
+
+	assertFileContains(t, projectDir, "package.json", `"@sveltejs/kit"`)
+	assertFileContains(t, projectDir, "package.json", `"@allyourbase/js"`)
+	assertFileContains(t, projectDir, ".gitignore", ".svelte-kit/")
+	assertFileContains(t, projectDir, "src/app.html", "<title>svelteapp</title>")
+	assertFileContains(t, projectDir, "src/app.html", "%sveltekit.body%")
+	assertFileContains(t, projectDir, "src/lib/ayb.ts", "import.meta.env.VITE_AYB_URL")
+	assertFileContains(t, projectDir, "src/routes/+page.svelte", `from "$lib/ayb"`)
+	assertFileContains(t, projectDir, "src/routes/login/+page.svelte", "ayb.auth.login")
 }
 
 func TestRun_Express(t *testing.T) {
@@ -107,6 +144,35 @@ func TestRun_Plain(t *testing.T) {
 	assertFileExists(t, projectDir, "ayb.toml")
 }
 
+func TestRun_GeneratedSchemaAndTypes(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	err := Run(Options{
+		Name:      "imported",
+		Template:  TemplatePlain,
+		Dir:       dir,
+		SchemaSQL: "CREATE TABLE posts (id TEXT PRIMARY KEY);",
+		Types:     "export interface Posts {\n  id: string;\n}\n",
+	})
+	testutil.NoError(t, err)
+
+	projectDir := filepath.Join(dir, "imported")
+	schemaSQL, err := os.ReadFile(filepath.Join(projectDir, "schema.sql"))
+	testutil.NoError(t, err)
+	testutil.Equal(t, "CREATE TABLE posts (id TEXT PRIMARY KEY);", string(schemaSQL))
+	types, err := os.ReadFile(filepath.Join(projectDir, "src/types/ayb.d.ts"))
+	testutil.NoError(t, err)
+	testutil.Contains(t, string(types), "export interface Posts")
+}
+
+func TestRun_NoTypesByDefault(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	testutil.NoError(t, Run(Options{Name: "fresh", Template: TemplatePlain, Dir: dir}))
+	_, err := os.Stat(filepath.Join(dir, "fresh", "src/types/ayb.d.ts"))
+	testutil.True(t, os.IsNotExist(err), "types file should only be written when provided")
+}
+
 func TestRun_EmptyName(t *testing.T) {
 	t.Parallel()
 	err := Run(Options{Name: "", Template: TemplateReact})
@@ -193,6 +259,14 @@ func TestGitignoreReactTemplate(t *testing.T) {
 	testutil.Contains(t, content, "node_modules/")
 }
 
+func TestGitignoreSvelteTemplate(t *testing.T) {
+	t.Parallel()
+	content := gitignoreFile(TemplateSvelte)
+	testutil.Contains(t, content, ".svelte-kit/")
+	testutil.Contains(t, content, "build/")
+	testutil.False(t, strings.Contains(content, ".next/"))
+}
+
 func TestClaudeMD(t *testing.T) {
 	t.Parallel()
 	content := claudeMD(Options{Name: "my-project"})
@@ -203,7 +277,7 @@ func TestClaudeMD(t *testing.T) {
 
 func TestAybClientBrowser(t *testing.T) {
 	t.Parallel()
-	content := aybClient()
+	content := aybClient(viteAYBURL)
 	testutil.Contains(t, content, "import.meta.env.VITE_AYB_URL")
 	testutil.Contains(t, content, "localStorage")
 	testutil.Contains(t, content, "persistTokens")
@@ -274,6 +348,24 @@ func TestNextPageContent(t *testing.T) {
 	testutil.Contains(t, content, "ayb.records")
 }
 
+func TestNextLoginPageContent(t *testing.T) {
+	t.Parallel()
+	content := nextLoginPage()
+	testutil.True(t, strings.HasPrefix(content, "\"use client\""),
+		"Next.js login page must start with \"use client\" directive")
+	testutil.Contains(t, content, "ayb.auth.login(email, password)")
+	testutil.Contains(t, content, "persistTokens")
+}
+
+func TestSvelteLoginPageContent(t *testing.T) {
+	t.Parallel()
+	content := svelteLoginPage()
+	testutil.Contains(t, content, `<script lang="ts">`)
+	testutil.Contains(t, content, "ayb.auth.login(email, password)")
+	testutil.Contains(t, content, "persistTokens")
+	testutil.Contains(t, content, `goto("/")`)
+}
+
 func TestNextLayoutContent(t *testing.T) {
 	t.Parallel()
 	content := nextLayout(Options{Name: "myapp"})
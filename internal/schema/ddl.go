@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DDL renders the tables, enums and indexes in sc as a SQL script that
+// recreates them in an empty database. Foreign keys are added after all
+// tables exist so creation order doesn't matter. Views are listed as
+// comments: the cache doesn't hold their definitions.
+func DDL(sc *SchemaCache) string {
+	var b strings.Builder
+	tables := sc.TableList()
+
+	for _, s := range ddlSchemas(sc, tables) {
+		fmt.Fprintf(&b, "CREATE SCHEMA IF NOT EXISTS %s;\n\n", quoteIdent(s))
+	}
+
+	enums := make([]*EnumType, 0, len(sc.Enums))
+	for _, e := range sc.Enums {
+		enums = append(enums, e)
+	}
+	sort.Slice(enums, func(i, j int) bool {
+		return qualifiedName(enums[i].Schema, enums[i].Name) < qualifiedName(enums[j].Schema, enums[j].Name)
+	})
+	for _, e := range enums {
+		values := make([]string, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = quoteLiteral(v)
+		}
+		fmt.Fprintf(&b, "CREATE TYPE %s AS ENUM (%s);\n\n", qualifiedName(e.Schema, e.Name), strings.Join(values, ", "))
+	}
+
+	var foreignKeys []string
+	for _, t := range tables {
+		if t.Kind != "table" && t.Kind != "partitioned_table" {
+			fmt.Fprintf(&b, "-- %s %s is not included; recreate it from its definition.\n\n",
+				strings.ReplaceAll(t.Kind, "_", " "), qualifiedName(t.Schema, t.Name))
+			continue
+		}
+		writeCreateTable(&b, t)
+		for _, fk := range t.ForeignKeys {
+			foreignKeys = append(foreignKeys, foreignKeySQL(t, fk))
+		}
+	}
+
+	for _, fk := range foreignKeys {
+		b.WriteString(fk)
+		b.WriteString("\n")
+	}
+	if len(foreignKeys) > 0 {
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// ddlSchemas returns the non-public schemas that tables or enums live in.
+func ddlSchemas(sc *SchemaCache, tables []*Table) []string {
+	seen := map[string]bool{}
+	for _, t := range tables {
+		seen[t.Schema] = true
+	}
+	for _, e := range sc.Enums {
+		seen[e.Schema] = true
+	}
+	delete(seen, "public")
+	schemas := make([]string, 0, len(seen))
+	for s := range seen {
+		schemas = append(schemas, s)
+	}
+	sort.Strings(schemas)
+	return schemas
+}
+
+func writeCreateTable(b *strings.Builder, t *Table) {
+	if t.Comment != "" {
+		fmt.Fprintf(b, "-- %s\n", t.Comment)
+	}
+	fmt.Fprintf(b, "CREATE TABLE %s (\n", qualifiedName(t.Schema, t.Name))
+	lines := make([]string, 0, len(t.Columns)+1)
+	for _, col := range t.Columns {
+		lines = append(lines, "  "+columnDefinition(col))
+	}
+	if len(t.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", quoteIdents(t.PrimaryKey)))
+	}
+	b.WriteString(strings.Join(lines, ",\n"))
+	b.WriteString("\n);\n")
+
+	for _, idx := range t.Indexes {
+		if idx.IsPrimary || idx.Definition == "" {
+			continue
+		}
+		fmt.Fprintf(b, "%s;\n", idx.Definition)
+	}
+	b.WriteString("\n")
+}
+
+// serialTypes maps integer types to the serial pseudo-type that creates
+// their sequence, so nextval() defaults survive the move to a new database.
+var serialTypes = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
+
+func columnDefinition(col *Column) string {
+	typeName := col.TypeName
+	def := col.DefaultExpr
+	if serial, ok := serialTypes[typeName]; ok && strings.HasPrefix(def, "nextval(") {
+		typeName, def = serial, ""
+	}
+
+	s := quoteIdent(col.Name) + " " + typeName
+	if !col.IsNullable {
+		s += " NOT NULL"
+	}
+	if def != "" {
+		s += " DEFAULT " + def
+	}
+	return s
+}
+
+func foreignKeySQL(t *Table, fk *ForeignKey) string {
+	s := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		qualifiedName(t.Schema, t.Name), quoteIdent(fk.ConstraintName), quoteIdents(fk.Columns),
+		qualifiedName(fk.ReferencedSchema, fk.ReferencedTable), quoteIdents(fk.ReferencedColumns))
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	return s + ";"
+}
+
+func qualifiedName(schemaName, name string) string {
+	return quoteIdent(schemaName) + "." + quoteIdent(name)
+}
+
+func quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
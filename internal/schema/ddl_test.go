@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestDDL(t *testing.T) {
+	t.Parallel()
+	sc := &SchemaCache{
+		Enums: map[uint32]*EnumType{
+			1: {Schema: "public", Name: "status", Values: []string{"draft", "it's live"}},
+		},
+		Tables: map[string]*Table{
+			"public.authors": {
+				Schema: "public", Name: "authors", Kind: "table",
+				Columns: []*Column{
+					{Name: "id", TypeName: "bigint", DefaultExpr: "nextval('authors_id_seq'::regclass)"},
+					{Name: "name", TypeName: "text"},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []*Index{
+					{Name: "authors_pkey", IsPrimary: true, Definition: "CREATE UNIQUE INDEX authors_pkey ON public.authors USING btree (id)"},
+				},
+			},
+			"public.posts": {
+				Schema: "public", Name: "posts", Kind: "table", Comment: "Blog posts",
+				Columns: []*Column{
+					{Name: "id", TypeName: "uuid", DefaultExpr: "gen_random_uuid()"},
+					{Name: "author_id", TypeName: "bigint", IsNullable: true},
+					{Name: "status", TypeName: "status", DefaultExpr: "'draft'::status"},
+					{Name: "tags", TypeName: "text[]", IsNullable: true},
+				},
+				PrimaryKey: []string{"id"},
+				ForeignKeys: []*ForeignKey{{
+					ConstraintName: "posts_author_id_fkey", Columns: []string{"author_id"},
+					ReferencedSchema: "public", ReferencedTable: "authors", ReferencedColumns: []string{"id"},
+					OnUpdate: "NO ACTION", OnDelete: "CASCADE",
+				}},
+				Indexes: []*Index{
+					{Name: "posts_status_idx", Definition: "CREATE INDEX posts_status_idx ON public.posts USING btree (status)"},
+				},
+			},
+			"reporting.post_counts": {Schema: "reporting", Name: "post_counts", Kind: "materialized_view"},
+		},
+	}
+
+	want := `CREATE SCHEMA IF NOT EXISTS "reporting";
+
+CREATE TYPE "public"."status" AS ENUM ('draft', 'it''s live');
+
+CREATE TABLE "public"."authors" (
+  "id" bigserial NOT NULL,
+  "name" text NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- Blog posts
+CREATE TABLE "public"."posts" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "author_id" bigint,
+  "status" status NOT NULL DEFAULT 'draft'::status,
+  "tags" text[],
+  PRIMARY KEY ("id")
+);
+CREATE INDEX posts_status_idx ON public.posts USING btree (status);
+
+-- materialized view "reporting"."post_counts" is not included; recreate it from its definition.
+
+ALTER TABLE "public"."posts" ADD CONSTRAINT "posts_author_id_fkey" FOREIGN KEY ("author_id") REFERENCES "public"."authors" ("id") ON DELETE CASCADE;
+`
+	testutil.Equal(t, want, DDL(sc))
+}
+
+func TestDDLEmpty(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "", DDL(&SchemaCache{Tables: map[string]*Table{}}))
+}
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+)
+
+// SchemaDiff describes how an actual schema differs from an expected one.
+// Added entries exist only in the actual schema; removed entries exist only
+// in the expected schema.
+type SchemaDiff struct {
+	AddedTables   []string     `json:"addedTables"`
+	RemovedTables []string     `json:"removedTables"`
+	ChangedTables []*TableDiff `json:"changedTables"`
+}
+
+// TableDiff describes column-level differences for a table present in both schemas.
+type TableDiff struct {
+	Table          string          `json:"table"` // "schema.table"
+	KindChange     *FieldChange    `json:"kindChange,omitempty"`
+	AddedColumns   []string        `json:"addedColumns,omitempty"`
+	RemovedColumns []string        `json:"removedColumns,omitempty"`
+	ChangedColumns []*ColumnChange `json:"changedColumns,omitempty"`
+}
+
+// ColumnChange lists the attributes that differ for a single column.
+type ColumnChange struct {
+	Column  string         `json:"column"`
+	Changes []*FieldChange `json:"changes"`
+}
+
+// FieldChange is a single attribute difference (e.g. type, nullable, default).
+type FieldChange struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// Empty reports whether the diff contains no differences.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+// Diff compares two schema snapshots table by table and column by column.
+// Output is sorted for deterministic rendering.
+func Diff(expected, actual *SchemaCache) *SchemaDiff {
+	d := &SchemaDiff{
+		AddedTables:   []string{},
+		RemovedTables: []string{},
+		ChangedTables: []*TableDiff{},
+	}
+
+	for key := range actual.Tables {
+		if _, ok := expected.Tables[key]; !ok {
+			d.AddedTables = append(d.AddedTables, key)
+		}
+	}
+	for key, exp := range expected.Tables {
+		act, ok := actual.Tables[key]
+		if !ok {
+			d.RemovedTables = append(d.RemovedTables, key)
+			continue
+		}
+		if td := diffTable(key, exp, act); td != nil {
+			d.ChangedTables = append(d.ChangedTables, td)
+		}
+	}
+
+	sort.Strings(d.AddedTables)
+	sort.Strings(d.RemovedTables)
+	sort.Slice(d.ChangedTables, func(i, j int) bool {
+		return d.ChangedTables[i].Table < d.ChangedTables[j].Table
+	})
+	return d
+}
+
+// diffTable returns nil when the two tables are equivalent.
+func diffTable(key string, expected, actual *Table) *TableDiff {
+	td := &TableDiff{Table: key}
+
+	if expected.Kind != actual.Kind {
+		td.KindChange = &FieldChange{Field: "kind", Expected: expected.Kind, Actual: actual.Kind}
+	}
+
+	for _, col := range actual.Columns {
+		if expected.ColumnByName(col.Name) == nil {
+			td.AddedColumns = append(td.AddedColumns, col.Name)
+		}
+	}
+	for _, exp := range expected.Columns {
+		act := actual.ColumnByName(exp.Name)
+		if act == nil {
+			td.RemovedColumns = append(td.RemovedColumns, exp.Name)
+			continue
+		}
+		if changes := diffColumn(exp, act); len(changes) > 0 {
+			td.ChangedColumns = append(td.ChangedColumns, &ColumnChange{Column: exp.Name, Changes: changes})
+		}
+	}
+
+	if td.KindChange == nil && len(td.AddedColumns) == 0 && len(td.RemovedColumns) == 0 && len(td.ChangedColumns) == 0 {
+		return nil
+	}
+	return td
+}
+
+func diffColumn(expected, actual *Column) []*FieldChange {
+	var changes []*FieldChange
+	if expected.TypeName != actual.TypeName {
+		changes = append(changes, &FieldChange{Field: "type", Expected: expected.TypeName, Actual: actual.TypeName})
+	}
+	if expected.IsNullable != actual.IsNullable {
+		changes = append(changes, &FieldChange{
+			Field:    "nullable",
+			Expected: strconv.FormatBool(expected.IsNullable),
+			Actual:   strconv.FormatBool(actual.IsNullable),
+		})
+	}
+	if expected.DefaultExpr != actual.DefaultExpr {
+		changes = append(changes, &FieldChange{Field: "default", Expected: expected.DefaultExpr, Actual: actual.DefaultExpr})
+	}
+	return changes
+}
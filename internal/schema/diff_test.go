@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestDiffIdenticalSchemas(t *testing.T) {
+	t.Parallel()
+	sc := &SchemaCache{Tables: map[string]*Table{
+		"public.posts": {Schema: "public", Name: "posts", Kind: "table", Columns: []*Column{
+			{Name: "id", TypeName: "integer"},
+		}},
+	}}
+	d := Diff(sc, sc)
+	testutil.True(t, d.Empty(), "identical schemas should produce an empty diff")
+}
+
+func TestDiffTables(t *testing.T) {
+	t.Parallel()
+	expected := &SchemaCache{Tables: map[string]*Table{
+		"public.posts":    {Schema: "public", Name: "posts", Kind: "table"},
+		"public.comments": {Schema: "public", Name: "comments", Kind: "table"},
+	}}
+	actual := &SchemaCache{Tables: map[string]*Table{
+		"public.posts":   {Schema: "public", Name: "posts", Kind: "table"},
+		"public.scratch": {Schema: "public", Name: "scratch", Kind: "table"},
+	}}
+
+	d := Diff(expected, actual)
+	testutil.False(t, d.Empty())
+	testutil.SliceLen(t, d.AddedTables, 1)
+	testutil.Equal(t, "public.scratch", d.AddedTables[0])
+	testutil.SliceLen(t, d.RemovedTables, 1)
+	testutil.Equal(t, "public.comments", d.RemovedTables[0])
+	testutil.SliceLen(t, d.ChangedTables, 0)
+}
+
+func TestDiffColumns(t *testing.T) {
+	t.Parallel()
+	expected := &SchemaCache{Tables: map[string]*Table{
+		"public.posts": {Schema: "public", Name: "posts", Kind: "table", Columns: []*Column{
+			{Name: "id", TypeName: "integer"},
+			{Name: "title", TypeName: "text"},
+			{Name: "body", TypeName: "text", IsNullable: true},
+		}},
+	}}
+	actual := &SchemaCache{Tables: map[string]*Table{
+		"public.posts": {Schema: "public", Name: "posts", Kind: "table", Columns: []*Column{
+			{Name: "id", TypeName: "integer"},
+			{Name: "body", TypeName: "character varying(100)", DefaultExpr: "''::character varying"},
+			{Name: "extra", TypeName: "text"},
+		}},
+	}}
+
+	d := Diff(expected, actual)
+	testutil.SliceLen(t, d.ChangedTables, 1)
+	td := d.ChangedTables[0]
+	testutil.Equal(t, "public.posts", td.Table)
+	testutil.Nil(t, td.KindChange)
+	testutil.SliceLen(t, td.AddedColumns, 1)
+	testutil.Equal(t, "extra", td.AddedColumns[0])
+	testutil.SliceLen(t, td.RemovedColumns, 1)
+	testutil.Equal(t, "title", td.RemovedColumns[0])
+	testutil.SliceLen(t, td.ChangedColumns, 1)
+
+	cc := td.ChangedColumns[0]
+	testutil.Equal(t, "body", cc.Column)
+	testutil.SliceLen(t, cc.Changes, 3)
+	testutil.Equal(t, "type", cc.Changes[0].Field)
+	testutil.Equal(t, "text", cc.Changes[0].Expected)
+	testutil.Equal(t, "character varying(100)", cc.Changes[0].Actual)
+	testutil.Equal(t, "nullable", cc.Changes[1].Field)
+	testutil.Equal(t, "true", cc.Changes[1].Expected)
+	testutil.Equal(t, "false", cc.Changes[1].Actual)
+	testutil.Equal(t, "default", cc.Changes[2].Field)
+}
+
+func TestDiffKindChange(t *testing.T) {
+	t.Parallel()
+	expected := &SchemaCache{Tables: map[string]*Table{
+		"public.stats": {Schema: "public", Name: "stats", Kind: "view"},
+	}}
+	actual := &SchemaCache{Tables: map[string]*Table{
+		"public.stats": {Schema: "public", Name: "stats", Kind: "materialized_view"},
+	}}
+
+	d := Diff(expected, actual)
+	testutil.SliceLen(t, d.ChangedTables, 1)
+	testutil.NotNil(t, d.ChangedTables[0].KindChange)
+	testutil.Equal(t, "view", d.ChangedTables[0].KindChange.Expected)
+	testutil.Equal(t, "materialized_view", d.ChangedTables[0].KindChange.Actual)
+}
@@ -12,6 +12,15 @@ import (
 // excludedSchemas are system schemas that are never introspected.
 var excludedSchemas = []string{"information_schema", "pg_catalog", "pg_toast"}
 
+// excludedSchemaPatterns are LIKE patterns for schemas that are never
+// introspected: other system schemas and tenant schemas.
+var excludedSchemaPatterns = []string{"pg_%", strings.ReplaceAll(TenantSchemaPrefix, "_", `\_`) + "%"}
+
+// TenantSchemaPrefix prefixes the schema of each tenant in schema-per-tenant
+// mode. Tenant schemas are copies of the public schema, so the schema cache
+// only describes public and requests are redirected per tenant.
+const TenantSchemaPrefix = "ayb_tenant_"
+
 // BuildCache introspects the database and returns a complete SchemaCache.
 func BuildCache(ctx context.Context, pool *pgxpool.Pool) (*SchemaCache, error) {
 	enums, err := loadEnums(ctx, pool)
@@ -32,36 +41,78 @@ func BuildCache(ctx context.Context, pool *pgxpool.Pool) (*SchemaCache, error) {
 		return nil, fmt.Errorf("loading foreign keys: %w", err)
 	}
 
+	if err := loadUniqueKeys(ctx, pool, tables); err != nil {
+		return nil, fmt.Errorf("loading unique constraints: %w", err)
+	}
+
 	if err := loadIndexes(ctx, pool, tables); err != nil {
 		return nil, fmt.Errorf("loading indexes: %w", err)
 	}
 
 	buildRelationships(tables)
 
+	hasPostGIS, err := loadHasPostGIS(ctx, pool)
+	if err != nil {
+		return nil, fmt.Errorf("detecting postgis: %w", err)
+	}
+	if hasPostGIS {
+		markGeometryColumns(tables)
+	}
+
 	functions, err := loadFunctions(ctx, pool)
 	if err != nil {
 		return nil, fmt.Errorf("loading functions: %w", err)
 	}
 
 	return &SchemaCache{
-		Tables:    tables,
-		Functions: functions,
-		Enums:     enums,
-		Schemas:   schemas,
-		BuiltAt:   time.Now(),
+		Tables:     tables,
+		Functions:  functions,
+		Enums:      enums,
+		Schemas:    schemas,
+		HasPostGIS: hasPostGIS,
+		BuiltAt:    time.Now(),
 	}, nil
 }
 
+// loadHasPostGIS reports whether the postgis extension is installed.
+func loadHasPostGIS(ctx context.Context, pool *pgxpool.Pool) (bool, error) {
+	var ok bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')`).Scan(&ok)
+	return ok, err
+}
+
+// markGeometryColumns flags PostGIS geometry and geography columns. The API
+// serves them as GeoJSON objects.
+func markGeometryColumns(tables map[string]*Table) {
+	for _, tbl := range tables {
+		for _, col := range tbl.Columns {
+			if col.IsArray {
+				continue
+			}
+			base := strings.ToLower(col.TypeName)
+			if idx := strings.Index(base, "("); idx > 0 {
+				base = base[:idx]
+			}
+			if base == "geometry" || base == "geography" {
+				col.IsGeometry = true
+				col.JSONType = "object"
+			}
+		}
+	}
+}
+
 // schemaFilter returns SQL clauses and args for excluding system schemas.
 // paramOffset is the starting $N parameter number.
 func schemaFilter(alias string, paramOffset int) (clause string, args []any) {
-	conditions := make([]string, 0, len(excludedSchemas)+1)
-	for i, s := range excludedSchemas {
-		conditions = append(conditions, fmt.Sprintf("%s.nspname != $%d", alias, paramOffset+i))
+	conditions := make([]string, 0, len(excludedSchemas)+len(excludedSchemaPatterns))
+	for _, s := range excludedSchemas {
+		conditions = append(conditions, fmt.Sprintf("%s.nspname != $%d", alias, paramOffset+len(args)))
 		args = append(args, s)
 	}
-	conditions = append(conditions, fmt.Sprintf("%s.nspname NOT LIKE $%d", alias, paramOffset+len(excludedSchemas)))
-	args = append(args, "pg_%")
+	for _, p := range excludedSchemaPatterns {
+		conditions = append(conditions, fmt.Sprintf("%s.nspname NOT LIKE $%d", alias, paramOffset+len(args)))
+		args = append(args, p)
+	}
 	return strings.Join(conditions, " AND "), args
 }
 
@@ -107,6 +158,7 @@ func loadTablesAndColumns(ctx context.Context, pool *pgxpool.Pool, enums map[uin
 		       c.relname                              AS table_name,
 		       c.relkind::text                        AS table_kind,
 		       COALESCE(obj_description(c.oid), '')   AS table_comment,
+		       c.relrowsecurity                       AS rls_enabled,
 		       a.attname                              AS column_name,
 		       a.attnum                               AS column_position,
 		       format_type(a.atttypid, a.atttypmod)   AS column_type,
@@ -141,12 +193,12 @@ func loadTablesAndColumns(ctx context.Context, pool *pgxpool.Pool, enums map[uin
 			colName, colType, colDefault, colComment        string
 			colPosition                                     int
 			typeOID                                         uint32
-			isNullable                                      bool
+			rlsEnabled, isNullable                          bool
 			typeCategory                                    string
 		)
 
 		if err := rows.Scan(
-			&tableSchema, &tableName, &tableKind, &tableComment,
+			&tableSchema, &tableName, &tableKind, &tableComment, &rlsEnabled,
 			&colName, &colPosition, &colType, &typeOID,
 			&isNullable, &colDefault, &colComment, &typeCategory,
 		); err != nil {
@@ -159,10 +211,11 @@ func loadTablesAndColumns(ctx context.Context, pool *pgxpool.Pool, enums map[uin
 		tbl, ok := tables[key]
 		if !ok {
 			tbl = &Table{
-				Schema:  tableSchema,
-				Name:    tableName,
-				Kind:    relkindToString(tableKind),
-				Comment: tableComment,
+				Schema:     tableSchema,
+				Name:       tableName,
+				Kind:       relkindToString(tableKind),
+				Comment:    tableComment,
+				RLSEnabled: rlsEnabled,
 			}
 			tables[key] = tbl
 		}
@@ -318,6 +371,51 @@ func loadForeignKeys(ctx context.Context, pool *pgxpool.Pool, tables map[string]
 	return rows.Err()
 }
 
+func loadUniqueKeys(ctx context.Context, pool *pgxpool.Pool, tables map[string]*Table) error {
+	filter, args := schemaFilter("n", 1)
+
+	query := fmt.Sprintf(`
+		SELECT cn.conname,
+		       n.nspname, c.relname,
+		       (SELECT array_agg(a.attname ORDER BY ord.n)
+		        FROM unnest(cn.conkey) WITH ORDINALITY AS ord(attnum, n)
+		        JOIN pg_attribute a ON a.attrelid = cn.conrelid AND a.attnum = ord.attnum
+		       )
+		FROM pg_constraint cn
+		  JOIN pg_class c ON c.oid = cn.conrelid
+		  JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE cn.contype = 'u' AND %s
+		ORDER BY n.nspname, c.relname, cn.conname`, filter)
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying unique constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			constraintName, schema, name string
+			columns                      []string
+		)
+		if err := rows.Scan(&constraintName, &schema, &name, &columns); err != nil {
+			return fmt.Errorf("scanning unique constraint: %w", err)
+		}
+
+		key := schema + "." + name
+		tbl, ok := tables[key]
+		if !ok {
+			continue
+		}
+
+		tbl.UniqueKeys = append(tbl.UniqueKeys, &UniqueKey{
+			ConstraintName: constraintName,
+			Columns:        columns,
+		})
+	}
+	return rows.Err()
+}
+
 func loadIndexes(ctx context.Context, pool *pgxpool.Pool, tables map[string]*Table) error {
 	filter, args := schemaFilter("tn", 1)
 
@@ -326,7 +424,9 @@ func loadIndexes(ctx context.Context, pool *pgxpool.Pool, tables map[string]*Tab
 		       tn.nspname, tc.relname,
 		       i.indisunique, i.indisprimary,
 		       am.amname,
-		       pg_get_indexdef(i.indexrelid, 0, true)
+		       pg_get_indexdef(i.indexrelid, 0, true),
+		       ARRAY(SELECT pg_get_indexdef(i.indexrelid, k, true)
+		             FROM generate_series(1, i.indnkeyatts) AS k ORDER BY k)
 		FROM pg_index i
 		  JOIN pg_class ic ON ic.oid = i.indexrelid
 		  JOIN pg_class tc ON tc.oid = i.indrelid
@@ -346,8 +446,9 @@ func loadIndexes(ctx context.Context, pool *pgxpool.Pool, tables map[string]*Tab
 			indexName, schema, tableName string
 			isUnique, isPrimary          bool
 			method, definition           string
+			columns                      []string
 		)
-		if err := rows.Scan(&indexName, &schema, &tableName, &isUnique, &isPrimary, &method, &definition); err != nil {
+		if err := rows.Scan(&indexName, &schema, &tableName, &isUnique, &isPrimary, &method, &definition, &columns); err != nil {
 			return fmt.Errorf("scanning index: %w", err)
 		}
 
@@ -363,6 +464,7 @@ func loadIndexes(ctx context.Context, pool *pgxpool.Pool, tables map[string]*Tab
 			IsPrimary:  isPrimary,
 			Method:     method,
 			Definition: definition,
+			Columns:    columns,
 		})
 	}
 	return rows.Err()
@@ -55,6 +55,10 @@ func createTestSchema(t *testing.T, ctx context.Context) {
 		`CREATE INDEX idx_posts_author ON posts(author_id)`,
 		`CREATE INDEX idx_posts_published ON posts(published) WHERE published = true`,
 
+		// Multi-column unique constraint and RLS on posts.
+		`ALTER TABLE posts ADD CONSTRAINT posts_author_title_key UNIQUE (author_id, title)`,
+		`ALTER TABLE posts ENABLE ROW LEVEL SECURITY`,
+
 		// View.
 		`CREATE VIEW active_users AS SELECT id, name, email FROM users WHERE is_active = true`,
 
@@ -225,6 +229,45 @@ func TestBuildCacheForeignKeys(t *testing.T) {
 	testutil.SliceLen(t, comments.ForeignKeys, 2)
 }
 
+func TestBuildCacheUniqueConstraints(t *testing.T) {
+	ctx := context.Background()
+	resetDB(t, ctx)
+	createTestSchema(t, ctx)
+
+	cache, err := schema.BuildCache(ctx, sharedPG.Pool)
+	testutil.NoError(t, err)
+
+	users := cache.Tables["public.users"]
+	testutil.NotNil(t, users)
+	testutil.SliceLen(t, users.UniqueKeys, 1)
+	testutil.Equal(t, "users_email_key", users.UniqueKeys[0].ConstraintName)
+	testutil.SliceLen(t, users.UniqueKeys[0].Columns, 1)
+	testutil.Equal(t, "email", users.UniqueKeys[0].Columns[0])
+
+	posts := cache.Tables["public.posts"]
+	testutil.NotNil(t, posts)
+	testutil.SliceLen(t, posts.UniqueKeys, 1)
+	testutil.Equal(t, "posts_author_title_key", posts.UniqueKeys[0].ConstraintName)
+	testutil.SliceLen(t, posts.UniqueKeys[0].Columns, 2)
+	testutil.Equal(t, "author_id", posts.UniqueKeys[0].Columns[0])
+	testutil.Equal(t, "title", posts.UniqueKeys[0].Columns[1])
+
+	// Primary keys are not reported as unique constraints.
+	testutil.SliceLen(t, cache.Tables["public.comments"].UniqueKeys, 0)
+}
+
+func TestBuildCacheRLSEnabled(t *testing.T) {
+	ctx := context.Background()
+	resetDB(t, ctx)
+	createTestSchema(t, ctx)
+
+	cache, err := schema.BuildCache(ctx, sharedPG.Pool)
+	testutil.NoError(t, err)
+
+	testutil.True(t, cache.Tables["public.posts"].RLSEnabled, "posts should have RLS enabled")
+	testutil.False(t, cache.Tables["public.users"].RLSEnabled, "users should not have RLS enabled")
+}
+
 func TestBuildCacheIndexes(t *testing.T) {
 	ctx := context.Background()
 	resetDB(t, ctx)
@@ -250,6 +293,8 @@ func TestBuildCacheIndexes(t *testing.T) {
 	testutil.NotNil(t, authorIdx)
 	testutil.False(t, authorIdx.IsUnique, "idx_posts_author should not be unique")
 	testutil.False(t, authorIdx.IsPrimary, "idx_posts_author should not be primary")
+	testutil.Equal(t, 1, len(authorIdx.Columns))
+	testutil.Equal(t, "author_id", authorIdx.Columns[0])
 }
 
 func TestBuildCacheRelationships(t *testing.T) {
@@ -7,12 +7,18 @@ import (
 
 // SchemaCache is an immutable snapshot of the database schema.
 // A new one is built on each reload and swapped in atomically.
+// Its JSON form is what GET /api/schema serves, and type generation and
+// schema diff are built from it, so JSON keys are part of the public
+// contract (see SchemaCache in openapi/openapi.yaml).
 type SchemaCache struct {
 	Tables    map[string]*Table    `json:"tables"`    // key: "schema.table"
 	Functions map[string]*Function `json:"functions"` // key: "schema.function"
 	Enums     map[uint32]*EnumType `json:"-"`         // lookup by OID (internal)
 	Schemas   []string             `json:"schemas"`
-	BuiltAt   time.Time            `json:"builtAt"`
+	// HasPostGIS is true when the postgis extension is installed; geometry
+	// and geography columns are only recognized then.
+	HasPostGIS bool      `json:"hasPostGIS"`
+	BuiltAt    time.Time `json:"builtAt"`
 }
 
 // TableByName returns a table by unqualified name, defaulting to the public schema.
@@ -50,9 +56,11 @@ type Table struct {
 	Name          string          `json:"name"`
 	Kind          string          `json:"kind"` // table, view, materialized_view, partitioned_table
 	Comment       string          `json:"comment,omitempty"`
+	RLSEnabled    bool            `json:"rlsEnabled"`
 	Columns       []*Column       `json:"columns"`
 	PrimaryKey    []string        `json:"primaryKey"`
 	ForeignKeys   []*ForeignKey   `json:"foreignKeys,omitempty"`
+	UniqueKeys    []*UniqueKey    `json:"uniqueConstraints,omitempty"`
 	Indexes       []*Index        `json:"indexes,omitempty"`
 	Relationships []*Relationship `json:"relationships,omitempty"`
 }
@@ -80,10 +88,17 @@ type Column struct {
 	IsJSON       bool     `json:"-"`
 	IsEnum       bool     `json:"-"`
 	IsArray      bool     `json:"-"`
+	IsGeometry   bool     `json:"-"` // PostGIS geometry or geography
 	JSONType     string   `json:"jsonType"`
 	EnumValues   []string `json:"enumValues,omitempty"`
 }
 
+// OptionalOnInsert reports whether the column may be omitted on insert because
+// the database fills it in: primary keys, columns with defaults, and nullable columns.
+func (c *Column) OptionalOnInsert() bool {
+	return c.IsPrimaryKey || c.DefaultExpr != "" || c.IsNullable
+}
+
 // ForeignKey represents a foreign key constraint.
 type ForeignKey struct {
 	ConstraintName    string   `json:"constraintName"`
@@ -95,13 +110,21 @@ type ForeignKey struct {
 	OnDelete          string   `json:"onDelete,omitempty"`
 }
 
+// UniqueKey represents a UNIQUE constraint. Unique indexes created without a
+// constraint appear only in Indexes.
+type UniqueKey struct {
+	ConstraintName string   `json:"constraintName"`
+	Columns        []string `json:"columns"`
+}
+
 // Index represents a database index.
 type Index struct {
-	Name       string `json:"name"`
-	IsUnique   bool   `json:"isUnique"`
-	IsPrimary  bool   `json:"isPrimary"`
-	Method     string `json:"method"`
-	Definition string `json:"definition"`
+	Name       string   `json:"name"`
+	IsUnique   bool     `json:"isUnique"`
+	IsPrimary  bool     `json:"isPrimary"`
+	Method     string   `json:"method"`
+	Definition string   `json:"definition"`
+	Columns    []string `json:"columns"` // key columns in order; expressions as written
 }
 
 // EnumType represents a PostgreSQL enum type.
@@ -226,10 +226,11 @@ func TestSchemaFilter(t *testing.T) {
 	t.Parallel()
 	clause, args := schemaFilter("n", 1)
 
-	// Should exclude information_schema, pg_catalog, pg_toast, and pg_% pattern.
+	// Should exclude information_schema, pg_catalog, pg_toast, and the pg_%
+	// and tenant schema patterns.
 	testutil.Contains(t, clause, "n.nspname != $1")
 	testutil.Contains(t, clause, "n.nspname NOT LIKE")
-	testutil.Equal(t, 4, len(args))
+	testutil.Equal(t, 5, len(args))
 
 	// Args should contain the excluded schema names.
 	found := map[string]bool{}
@@ -242,6 +243,7 @@ func TestSchemaFilter(t *testing.T) {
 	testutil.True(t, found["pg_catalog"], "missing pg_catalog")
 	testutil.True(t, found["pg_toast"], "missing pg_toast")
 	testutil.True(t, found["pg_%"], "missing pg_% pattern")
+	testutil.True(t, found[`ayb\_tenant\_%`], "missing tenant schema pattern")
 }
 
 func TestSchemaFilterParamOffset(t *testing.T) {
@@ -252,7 +254,8 @@ func TestSchemaFilterParamOffset(t *testing.T) {
 	testutil.Contains(t, clause, "s.nspname != $6")
 	testutil.Contains(t, clause, "s.nspname != $7")
 	testutil.Contains(t, clause, "s.nspname NOT LIKE $8")
-	testutil.Equal(t, 4, len(args))
+	testutil.Contains(t, clause, "s.nspname NOT LIKE $9")
+	testutil.Equal(t, 5, len(args))
 }
 
 // TestSetForTestingSignalsReady verifies that SetForTesting closes the ready
@@ -321,3 +324,26 @@ func TestSetForTestingIdempotent(t *testing.T) {
 	// Cache should now reflect the second value.
 	testutil.Equal(t, sc2, h.Get())
 }
+
+func TestMarkGeometryColumns(t *testing.T) {
+	t.Parallel()
+	tables := map[string]*Table{
+		"public.places": {
+			Name: "places",
+			Columns: []*Column{
+				{Name: "id", TypeName: "integer", JSONType: "integer"},
+				{Name: "location", TypeName: "geometry(Point,4326)", JSONType: "string"},
+				{Name: "area", TypeName: "geography", JSONType: "string"},
+				{Name: "paths", TypeName: "geometry[]", JSONType: "array", IsArray: true},
+			},
+		},
+	}
+	markGeometryColumns(tables)
+
+	cols := tables["public.places"].Columns
+	testutil.False(t, cols[0].IsGeometry, "integer is not geometry")
+	testutil.True(t, cols[1].IsGeometry, "geometry(Point,4326) should be geometry")
+	testutil.Equal(t, "object", cols[1].JSONType)
+	testutil.True(t, cols[2].IsGeometry, "geography should be geometry")
+	testutil.False(t, cols[3].IsGeometry, "arrays of geometry are not handled")
+}
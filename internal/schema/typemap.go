@@ -53,3 +53,9 @@ func pgTypeToJSON(typeName string, isArray bool, isEnum bool, isJSON bool) strin
 		return "string"
 	}
 }
+
+// ColumnJSONType maps a column type name such as "text" or "text[]" to its
+// JSON type, for columns described without introspecting a database.
+func ColumnJSONType(typeName string) string {
+	return pgTypeToJSON(typeName, strings.HasSuffix(typeName, "[]"), false, false)
+}
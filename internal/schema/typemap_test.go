@@ -97,3 +97,18 @@ func TestPgTypeToJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestColumnJSONType(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"text":                     "string",
+		"double precision":         "number",
+		"boolean":                  "boolean",
+		"timestamp with time zone": "string",
+		"jsonb":                    "object",
+		"text[]":                   "array",
+	}
+	for typ, want := range tests {
+		testutil.Equal(t, want, ColumnJSONType(typ))
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/httputil"
 )
 
+// adminSubject is the access log subject for requests made with the admin token.
+const adminSubject = "admin"
+
 // adminAuth handles simple password-based admin dashboard authentication.
 // Stateless: tokens are HMAC-derived from a per-boot secret, so no storage needed.
 type adminAuth struct {
@@ -101,6 +105,7 @@ func (s *Server) requireAdminToken(next http.Handler) http.Handler {
 			return
 		}
 
+		httputil.SetAccessLogSubject(r.Context(), adminSubject)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -145,9 +150,14 @@ func (s *Server) isAdminToken(r *http.Request) bool {
 // requireAdminOrUserAuth returns middleware that accepts either a valid admin
 // HMAC token or a valid user JWT / API key.  This is used on the auto-generated
 // CRUD API so that the admin dashboard (which holds an admin token) can read and
-// write collection data when user-auth is enabled.
+// write collection data when user-auth is enabled. Reads of the tables in
+// auth.public_read_tables are also let through without a credential.
 func (s *Server) requireAdminOrUserAuth(authSvc *auth.Service) func(http.Handler) http.Handler {
 	userAuth := auth.RequireAuth(authSvc)
+	publicRead := make(map[string]bool, len(s.publicReadTables))
+	for _, t := range s.publicReadTables {
+		publicRead[t] = true
+	}
 	return func(next http.Handler) http.Handler {
 		userNext := next
 		if s.appRL != nil {
@@ -158,11 +168,32 @@ func (s *Server) requireAdminOrUserAuth(authSvc *auth.Service) func(http.Handler
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Fast path: admin token bypasses user-auth entirely.
 			if s.isAdminToken(r) {
+				httputil.SetAccessLogSubject(r.Context(), adminSubject)
 				next.ServeHTTP(w, r)
 				return
 			}
+			// Anonymous reads of public tables run with read-only claims
+			// limited to those tables. A presented credential is still checked.
+			if isPublicRead(r, publicRead) && !auth.HasCredential(authSvc, r) {
+				ctx := auth.ContextWithClaims(r.Context(), auth.PublicReadClaims(s.publicReadTables))
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
 			// Fall back to the standard user-auth middleware chain.
 			userHandler.ServeHTTP(w, r)
 		})
 	}
 }
+
+// isPublicRead reports whether r reads a collection listed in publicRead.
+func isPublicRead(r *http.Request, publicRead map[string]bool) bool {
+	if len(publicRead) == 0 || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/api/collections/")
+	if !ok {
+		return false
+	}
+	table, _, _ := strings.Cut(rest, "/")
+	return publicRead[table]
+}
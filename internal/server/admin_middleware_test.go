@@ -90,3 +90,51 @@ func TestRequireAdminOrUserAuthAdminBypassesRateLimit(t *testing.T) {
 		testutil.Equal(t, "", w.Header().Get("X-App-RateLimit-Limit"))
 	}
 }
+
+func TestRequireAdminOrUserAuthPublicReadTables(t *testing.T) {
+	t.Parallel()
+
+	authSvc := auth.NewService(nil, "middleware-test-secret", time.Hour, 24*time.Hour, 8, testutil.DiscardLogger())
+	s := &Server{publicReadTables: []string{"posts"}}
+
+	var gotClaims *auth.Claims
+	h := s.requireAdminOrUserAuth(authSvc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims = auth.ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"list public table", http.MethodGet, "/api/collections/posts", "", http.StatusOK},
+		{"read public record", http.MethodGet, "/api/collections/posts/42", "", http.StatusOK},
+		{"head public table", http.MethodHead, "/api/collections/posts/", "", http.StatusOK},
+		{"write public table", http.MethodPost, "/api/collections/posts", "", http.StatusUnauthorized},
+		{"delete public record", http.MethodDelete, "/api/collections/posts/42", "", http.StatusUnauthorized},
+		{"read other table", http.MethodGet, "/api/collections/secrets", "", http.StatusUnauthorized},
+		{"table name prefix", http.MethodGet, "/api/collections/posts_private", "", http.StatusUnauthorized},
+		{"rpc", http.MethodGet, "/api/rpc/posts", "", http.StatusUnauthorized},
+		{"invalid token still checked", http.MethodGet, "/api/collections/posts", "not-a-jwt", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		gotClaims = nil
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		testutil.Equal(t, tt.want, w.Code)
+		if tt.want == http.StatusOK {
+			testutil.NotNil(t, gotClaims)
+			testutil.Equal(t, "", gotClaims.Subject)
+			testutil.False(t, gotClaims.IsWriteAllowed(), "%s: anonymous claims must be read-only", tt.name)
+			testutil.True(t, gotClaims.IsTableAllowed("posts"), "%s: posts should be allowed", tt.name)
+			testutil.False(t, gotClaims.IsTableAllowed("secrets"), "%s: secrets should not be allowed", tt.name)
+		}
+	}
+}
@@ -402,8 +402,10 @@ func TestAdminCreateAPIKeyInvalidJSON(t *testing.T) {
 
 	var resp map[string]any
 	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
-	msg, ok := resp["message"].(string)
-	testutil.True(t, ok, "response should contain a 'message' string field")
+	body, ok := resp["error"].(map[string]any)
+	testutil.True(t, ok, "response should contain an 'error' object")
+	msg, ok := body["message"].(string)
+	testutil.True(t, ok, "error should contain a 'message' string field")
 	testutil.Contains(t, msg, "invalid JSON")
 }
 
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/andybalholm/brotli"
+)
+
+// compressEncoder is a pooled response encoder.
+type compressEncoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressEncodings are the supported Content-Encodings, most preferred first.
+var compressEncodings = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{"br", &sync.Pool{New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }}},
+	{"gzip", &sync.Pool{New: func() any { return gzip.NewWriter(nil) }}},
+}
+
+// incompressibleTypes are media types that are already compressed, or
+// streamed, and pass through unchanged. A trailing "/" matches the whole
+// type, except image/svg+xml, which is text.
+var incompressibleTypes = []string{
+	"image/", "video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/x-bzip2",
+	"application/octet-stream", "application/pdf", "application/wasm",
+	"text/event-stream",
+}
+
+// compressMiddleware compresses responses with brotli or gzip, as the
+// client's Accept-Encoding allows, once they reach minSize bytes. Smaller
+// responses, responses a handler flushes before then, already-compressed
+// content types, downloads, range responses and connection upgrades pass
+// through unchanged.
+func compressMiddleware(cfg config.ServerConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Compression {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding < 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: cfg.CompressionMinSize}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the index in compressEncodings of the most
+// preferred encoding the Accept-Encoding header allows, or -1 for none.
+func negotiateEncoding(header string) int {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		if q > 0 {
+			accepted[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+	for i, enc := range compressEncodings {
+		if accepted[enc.name] || accepted["*"] {
+			return i
+		}
+	}
+	return -1
+}
+
+// compressible reports whether a response with header h may be compressed.
+func compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	if ar := h.Get("Accept-Ranges"); ar != "" && ar != "none" {
+		return false // byte ranges refer to the uncompressed body
+	}
+	if disposition, _, _ := mime.ParseMediaType(h.Get("Content-Disposition")); disposition == "attachment" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if mediaType == "image/svg+xml" {
+		return true
+	}
+	for _, t := range incompressibleTypes {
+		if mediaType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// compressWriter buffers a response until it reaches minSize bytes, then
+// either compresses it or, if the response isn't compressible, passes it
+// through.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding int
+	minSize  int
+
+	status  int
+	buf     bytes.Buffer
+	started bool            // headers sent
+	enc     compressEncoder // nil when passing through
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.started || w.status != 0 {
+		return
+	}
+	// Informational responses go straight through.
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.started {
+		w.buf.Write(b)
+		if w.buf.Len() < w.minSize {
+			return len(b), nil
+		}
+		if err := w.start(compressible(w.Header())); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// start sends the headers, compressing the rest of the response if compress
+// is set, and writes out the buffered body.
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	h := w.Header()
+	if compress {
+		enc := compressEncodings[w.encoding]
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", enc.name)
+		w.enc = enc.pool.Get().(compressEncoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	if compress || compressible(h) {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// Flush sends what has been written so far. A response flushed before it
+// reaches minSize is streamed uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.started {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		_ = w.start(false)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close finishes the response: a body still buffered was smaller than
+// minSize and is sent uncompressed.
+func (w *compressWriter) close() {
+	if !w.started {
+		if w.status == 0 {
+			return // nothing written; net/http sends the default response
+		}
+		_ = w.start(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		compressEncodings[w.encoding].pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0, gzip", "gzip"},
+		{"GZIP;q=0.5", "gzip"},
+		{"*", "br"},
+	} {
+		got := ""
+		if i := negotiateEncoding(tc.header); i >= 0 {
+			got = compressEncodings[i].name
+		}
+		testutil.Equal(t, tc.want, got)
+	}
+}
+
+func TestCompressible(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		header http.Header
+		want   bool
+	}{
+		{http.Header{"Content-Type": {"application/json"}}, true},
+		{http.Header{"Content-Type": {"text/html; charset=utf-8"}}, true},
+		{http.Header{"Content-Type": {"image/svg+xml"}}, true},
+		{http.Header{"Content-Type": {"image/png"}}, false},
+		{http.Header{"Content-Type": {"application/zip"}}, false},
+		{http.Header{"Content-Type": {"text/event-stream"}}, false},
+		{http.Header{"Content-Type": {"text/plain"}, "Content-Encoding": {"gzip"}}, false},
+		{http.Header{"Content-Type": {"text/plain"}, "Accept-Ranges": {"bytes"}}, false},
+		{http.Header{"Content-Type": {"text/csv"}, "Content-Disposition": {`attachment; filename="x.csv"`}}, false},
+	} {
+		testutil.Equal(t, tc.want, compressible(tc.header))
+	}
+}
+
+// compressTestServer serves body with contentType through compressMiddleware.
+func compressTestServer(cfg config.ServerConfig, contentType, body string) http.Handler {
+	return compressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = io.WriteString(w, body)
+	}))
+}
+
+func doCompressRequest(h http.Handler, acceptEncoding string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressLargeResponse(t *testing.T) {
+	t.Parallel()
+	body := `{"items":[` + strings.Repeat(`{"title":"hello"},`, 200) + `{}]}`
+	h := compressTestServer(config.Default().Server, "application/json", body)
+
+	w := doCompressRequest(h, "gzip")
+	testutil.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	testutil.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	testutil.True(t, w.Body.Len() < len(body), "gzip body not smaller")
+	zr, err := gzip.NewReader(w.Body)
+	testutil.NoError(t, err)
+	got, err := io.ReadAll(zr)
+	testutil.NoError(t, err)
+	testutil.Equal(t, body, string(got))
+
+	w = doCompressRequest(h, "gzip, br")
+	testutil.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	got, err = io.ReadAll(brotli.NewReader(w.Body))
+	testutil.NoError(t, err)
+	testutil.Equal(t, body, string(got))
+
+	// Without Accept-Encoding the body is sent as is.
+	w = doCompressRequest(h, "")
+	testutil.Equal(t, "", w.Header().Get("Content-Encoding"))
+	testutil.Equal(t, body, w.Body.String())
+}
+
+func TestCompressSkipsResponses(t *testing.T) {
+	t.Parallel()
+	large := strings.Repeat("x", 4096)
+	for _, tc := range []struct {
+		name   string
+		h      http.Handler
+		header []string
+	}{
+		{"below min size", compressTestServer(config.Default().Server, "application/json", `{"ok":true}`), nil},
+		{"image", compressTestServer(config.Default().Server, "image/png", large), nil},
+		{"range request", compressTestServer(config.Default().Server, "text/plain", large), []string{"Range", "bytes=0-9"}},
+		{"websocket upgrade", compressTestServer(config.Default().Server, "text/plain", large), []string{"Upgrade", "websocket"}},
+		{"disabled", compressTestServer(config.ServerConfig{CompressionMinSize: 1}, "text/plain", large), nil},
+	} {
+		w := doCompressRequest(tc.h, "gzip", tc.header...)
+		testutil.Equal(t, "", w.Header().Get("Content-Encoding"))
+		testutil.True(t, w.Body.Len() > 0, "%s: empty body", tc.name)
+	}
+}
+
+func TestCompressFlushStreamsUncompressed(t *testing.T) {
+	t.Parallel()
+	h := compressMiddleware(config.Default().Server)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "data: hello\n\n")
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, strings.Repeat("x", 4096))
+	}))
+
+	w := doCompressRequest(h, "gzip")
+	testutil.Equal(t, "", w.Header().Get("Content-Encoding"))
+	testutil.True(t, w.Flushed, "response not flushed")
+	testutil.True(t, strings.HasPrefix(w.Body.String(), "data: hello"), "unexpected body")
+}
+
+func TestCompressKeepsStatus(t *testing.T) {
+	t.Parallel()
+	h := compressMiddleware(config.Default().Server)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, strings.Repeat(" ", 2048))
+	}))
+
+	w := doCompressRequest(h, "gzip")
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	h = compressMiddleware(config.Default().Server)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w = doCompressRequest(h, "gzip")
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, "", w.Header().Get("Content-Encoding"))
+}
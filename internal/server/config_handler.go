@@ -0,0 +1,117 @@
+package server
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// maxConfigImportSize caps the body of a config import.
+const maxConfigImportSize = 1 << 20
+
+// SetConfigPath sets the config file written by POST /api/admin/config.
+// Config import is unavailable until this is set.
+func (s *Server) SetConfigPath(path string) {
+	s.configPath = path
+}
+
+// requireAdminPassword rejects requests when no admin password is set, for
+// endpoints too sensitive to expose on an unprotected admin API.
+func (s *Server) requireAdminPassword(w http.ResponseWriter) bool {
+	s.adminMu.RLock()
+	aa := s.adminAuth
+	s.adminMu.RUnlock()
+	if aa == nil {
+		httputil.WriteErrorWithDocURL(w, http.StatusForbidden, "admin.password must be set to export secrets or import config",
+			"https://allyourbase.io/guide/configuration")
+		return false
+	}
+	return true
+}
+
+// handleAdminConfigExport returns the running config as TOML. Secrets are
+// masked unless ?reveal_secrets=true is passed.
+func (s *Server) handleAdminConfigExport(w http.ResponseWriter, r *http.Request) {
+	reveal := r.URL.Query().Get("reveal_secrets") == "true"
+	if reveal {
+		if !s.requireAdminPassword(w) {
+			return
+		}
+		s.logger.Warn("admin exported config with secrets")
+	}
+
+	s.runtimeMu.RLock()
+	cfg := s.cfg
+	if !reveal {
+		cfg = cfg.MaskedCopy()
+	}
+	out, err := cfg.ToTOML()
+	s.runtimeMu.RUnlock()
+	if err != nil {
+		s.logger.Error("config export error", "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to serialize config")
+		return
+	}
+	w.Header().Set("Content-Type", "application/toml")
+	w.Header().Set("Content-Disposition", `attachment; filename="ayb.toml"`)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = io.WriteString(w, out)
+}
+
+// handleAdminConfigImport validates a posted TOML config and writes it to the
+// config file. Secrets left as "***" keep their current values. The running
+// server is not reconfigured; changes apply on restart.
+func (s *Server) handleAdminConfigImport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdminPassword(w) {
+		return
+	}
+	if s.configPath == "" {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "config import is not available")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxConfigImportSize))
+	if err != nil {
+		if httputil.IsBodyTooLarge(err) {
+			httputil.WriteError(w, http.StatusRequestEntityTooLarge, "config too large")
+			return
+		}
+		httputil.WriteError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid TOML: "+err.Error())
+		return
+	}
+	s.runtimeMu.RLock()
+	cfg.RestoreMaskedSecrets(s.cfg)
+	s.runtimeMu.RUnlock()
+	if err := cfg.Validate(); err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := cfg.WriteFile(s.configPath); err != nil {
+		s.logger.Error("config import write error", "path", s.configPath, "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to write config file")
+		return
+	}
+
+	s.runtimeMu.RLock()
+	changed := config.ChangedKeys(s.cfg, cfg)
+	s.runtimeMu.RUnlock()
+	if changed == nil {
+		changed = []string{}
+	}
+	s.logger.Info("admin imported config", "path", s.configPath, "changed", len(changed))
+
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"path":             s.configPath,
+		"changed":          changed,
+		"restart_required": len(changed) > 0,
+	})
+}
@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+// newConfigTestServer creates an admin-protected server whose config import
+// writes to a temp file, returning the server, an admin token, and the path.
+func newConfigTestServer(t *testing.T) (*server.Server, string, string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = "this-is-a-secret-that-is-at-least-32-characters-long"
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := server.New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	path := filepath.Join(t.TempDir(), "ayb.toml")
+	srv.SetConfigPath(path)
+	return srv, adminLogin(t, srv), path
+}
+
+func configRequest(t *testing.T, srv *server.Server, token, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	srv.Router().ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminConfigExport(t *testing.T) {
+	t.Parallel()
+	srv, token, _ := newConfigTestServer(t)
+
+	w := configRequest(t, srv, "", http.MethodGet, "/api/admin/config/", "")
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = configRequest(t, srv, token, http.MethodGet, "/api/admin/config/", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "application/toml", w.Header().Get("Content-Type"))
+	testutil.Contains(t, w.Body.String(), "jwt_secret = '***'")
+	testutil.False(t, strings.Contains(w.Body.String(), "at-least-32-characters"), "secrets should be masked")
+
+	w = configRequest(t, srv, token, http.MethodGet, "/api/admin/config/?reveal_secrets=true", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "this-is-a-secret-that-is-at-least-32-characters-long")
+}
+
+func TestAdminConfigExportRevealRequiresPassword(t *testing.T) {
+	t.Parallel()
+	srv := newTestServerWithPassword(t, "") // admin API unprotected
+
+	w := configRequest(t, srv, "", http.MethodGet, "/api/admin/config/?reveal_secrets=true", "")
+	testutil.Equal(t, http.StatusForbidden, w.Code)
+	testutil.Contains(t, w.Body.String(), "admin.password must be set")
+
+	w = configRequest(t, srv, "", http.MethodPost, "/api/admin/config/", "[server]\nport = 3000\n")
+	testutil.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminConfigImport(t *testing.T) {
+	t.Parallel()
+	srv, token, path := newConfigTestServer(t)
+
+	// Round-trip the masked export with one change.
+	w := configRequest(t, srv, token, http.MethodGet, "/api/admin/config/", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	exported := strings.Replace(w.Body.String(), "port = 8090", "port = 3000", 1)
+
+	w = configRequest(t, srv, token, http.MethodPost, "/api/admin/config/", exported)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Path            string   `json:"path"`
+		Changed         []string `json:"changed"`
+		RestartRequired bool     `json:"restart_required"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	testutil.Equal(t, path, resp.Path)
+	testutil.Equal(t, "server.port", strings.Join(resp.Changed, ","))
+	testutil.True(t, resp.RestartRequired, "changed config should require restart")
+
+	// Masked secrets were restored from the running config.
+	written, err := config.Load(path, nil)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 3000, written.Server.Port)
+	testutil.Equal(t, "this-is-a-secret-that-is-at-least-32-characters-long", written.Auth.JWTSecret)
+	testutil.Equal(t, "testpass", written.Admin.Password)
+}
+
+func TestAdminConfigImportRejectsInvalid(t *testing.T) {
+	t.Parallel()
+	srv, token, path := newConfigTestServer(t)
+
+	tests := []struct {
+		name, body, contains string
+	}{
+		{"malformed TOML", "[server\n", "invalid TOML"},
+		{"fails validation", "[server]\nport = 0\n", "server.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := configRequest(t, srv, token, http.MethodPost, "/api/admin/config/", tt.body)
+			testutil.Equal(t, http.StatusBadRequest, w.Code)
+			testutil.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+
+	_, err := os.Stat(path)
+	testutil.True(t, os.IsNotExist(err), "rejected imports should not write the config file")
+}
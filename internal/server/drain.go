@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// drainTracker counts in-flight requests and, once shutdown begins, rejects
+// new ones. http.Server.Shutdown stops accepting connections, but a request
+// can still arrive on a kept-alive connection before it is closed; those get
+// a 503 so load balancers retry them elsewhere.
+type drainTracker struct {
+	inFlight atomic.Int64
+	draining atomic.Bool
+}
+
+func (d *drainTracker) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Count before checking so a request can't slip in after Shutdown
+		// reads the count.
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+		if d.draining.Load() {
+			w.Header().Set("Connection", "close")
+			httputil.WriteError(w, http.StatusServiceUnavailable, "server is shutting down")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startDraining makes the middleware reject new requests.
+func (d *drainTracker) startDraining() {
+	d.draining.Store(true)
+}
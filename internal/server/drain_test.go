@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestDrainTrackerRejectsWhileDraining(t *testing.T) {
+	t.Parallel()
+	d := &drainTracker{}
+	var inFlight int64
+	handler := d.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight = d.inFlight.Load()
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, int64(1), inFlight)
+	testutil.Equal(t, int64(0), d.inFlight.Load())
+
+	d.startDraining()
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	testutil.Equal(t, http.StatusServiceUnavailable, w.Code)
+	testutil.Equal(t, "close", w.Header().Get("Connection"))
+	testutil.Equal(t, int64(0), d.inFlight.Load())
+}
+
+// startDrainTestServer serves a server with a /slow route that blocks until
+// release is closed or the request is cancelled.
+func startDrainTestServer(t *testing.T, port, shutdownTimeout int) (srv *Server, entered <-chan struct{}, release chan struct{}) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = port
+	cfg.Server.ShutdownTimeout = shutdownTimeout
+	logger := testutil.DiscardLogger()
+	srv = New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+
+	enteredCh := make(chan struct{}, 1)
+	release = make(chan struct{})
+	srv.Router().Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		enteredCh <- struct{}{}
+		select {
+		case <-release:
+			w.Write([]byte("done"))
+		case <-r.Context().Done():
+		}
+	})
+
+	ready := make(chan struct{})
+	go srv.StartWithReady(ready) //nolint:errcheck
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not start")
+	}
+	return srv, enteredCh, release
+}
+
+type slowResult struct {
+	status int
+	body   string
+	err    error
+}
+
+func getSlow(url string) <-chan slowResult {
+	ch := make(chan slowResult, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			ch <- slowResult{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		ch <- slowResult{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	return ch
+}
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	t.Parallel()
+	const base = "http://127.0.0.1:19877"
+	srv, entered, release := startDrainTestServer(t, 19877, 10)
+
+	slow := getSlow(base + "/slow")
+	<-entered
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- srv.Shutdown(context.Background()) }()
+
+	// New requests are rejected once draining starts.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: time.Second}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := client.Get(base + "/health")
+		if err != nil {
+			break
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new requests still accepted during drain")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The in-flight request completes within the drain window.
+	close(release)
+	res := <-slow
+	testutil.NoError(t, res.err)
+	testutil.Equal(t, http.StatusOK, res.status)
+	testutil.Equal(t, "done", res.body)
+	testutil.NoError(t, <-shutdownErr)
+}
+
+func TestShutdownClosesRequestsAfterTimeout(t *testing.T) {
+	t.Parallel()
+	srv, entered, _ := startDrainTestServer(t, 19878, 1)
+
+	slow := getSlow("http://127.0.0.1:19878/slow")
+	<-entered
+
+	start := time.Now()
+	err := srv.Shutdown(context.Background())
+	testutil.True(t, err != nil, "expected a timeout error from Shutdown")
+	testutil.True(t, time.Since(start) < 5*time.Second, "Shutdown took %s", time.Since(start))
+
+	res := <-slow
+	testutil.True(t, res.err != nil || res.status != http.StatusOK, "force-closed request should not succeed: %+v", res)
+}
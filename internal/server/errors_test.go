@@ -0,0 +1,82 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestErrorResponsesUseEnvelope(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		srv    func(t *testing.T) *server.Server
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{
+			name:   "unknown api route",
+			srv:    newCollectionsTestServer,
+			method: http.MethodGet, path: "/api/nope",
+			status: http.StatusNotFound, code: httputil.CodeNotFound,
+		},
+		{
+			name:   "method not allowed",
+			srv:    newCollectionsTestServer,
+			method: http.MethodPut, path: "/api/collections/posts/42",
+			status: http.StatusMethodNotAllowed, code: httputil.CodeMethodNotAllowed,
+		},
+		{
+			name:   "schema not ready",
+			srv:    func(t *testing.T) *server.Server { return newTestServer(t, newCacheHolderWithSchema(nil)) },
+			method: http.MethodGet, path: "/api/schema",
+			status: http.StatusServiceUnavailable, code: httputil.CodeUnavailable,
+		},
+		{
+			name:   "admin token missing",
+			srv:    func(t *testing.T) *server.Server { return newTestServerWithPassword(t, "testpass") },
+			method: http.MethodGet, path: "/api/admin/maintenance/",
+			status: http.StatusUnauthorized, code: httputil.CodeUnauthorized,
+		},
+		{
+			name:   "invalid json",
+			srv:    func(t *testing.T) *server.Server { return newTestServerWithPassword(t, "testpass") },
+			method: http.MethodPost, path: "/api/admin/auth", body: `{bad`,
+			status: http.StatusBadRequest, code: httputil.CodeBadRequest,
+		},
+		{
+			name:   "wrong admin password",
+			srv:    func(t *testing.T) *server.Server { return newTestServerWithPassword(t, "testpass") },
+			method: http.MethodPost, path: "/api/admin/auth", body: `{"password":"nope"}`,
+			status: http.StatusUnauthorized, code: httputil.CodeUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			tt.srv(t).Router().ServeHTTP(w, req)
+
+			testutil.Equal(t, tt.status, w.Code)
+			testutil.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			var raw map[string]json.RawMessage
+			testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+			testutil.Equal(t, 1, len(raw))
+			var resp httputil.ErrorResponse
+			testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			testutil.Equal(t, tt.code, resp.Error.Code)
+			testutil.True(t, resp.Error.Message != "", "error should have a message")
+		})
+	}
+}
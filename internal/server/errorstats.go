@@ -0,0 +1,231 @@
+package server
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// errorStatsWindow is how far back /api/admin/errors can look. Request
+	// counts are kept in one bucket per minute over this window.
+	errorStatsWindow = 24 * time.Hour
+	// serverErrorLogSize caps the 5xx responses kept; older ones are dropped
+	// first.
+	serverErrorLogSize = 1000
+	// maxErrorBody is how much of a 5xx response body is kept to find its
+	// error message.
+	maxErrorBody = 1024
+)
+
+// statusBucket counts the responses completed in one minute.
+type statusBucket struct {
+	minute       int64 // Unix minute the counts are for
+	total        int
+	clientErrors int // 4xx
+	serverErrors int // 5xx
+}
+
+// serverError is a 5xx response.
+type serverError struct {
+	time      time.Time
+	method    string
+	route     string
+	status    int
+	message   string
+	requestID string
+}
+
+// errorStats counts responses by status class and keeps the most recent 5xx
+// responses for /api/admin/errors. Memory is bounded: a fixed ring of
+// per-minute buckets and a fixed ring of 5xx responses.
+type errorStats struct {
+	mu      sync.Mutex
+	buckets [int(errorStatsWindow / time.Minute)]statusBucket
+	errors  []serverError
+	next    int   // index the next 5xx overwrites once errors is full
+	served  int64 // responses completed since start
+	now     func() time.Time
+}
+
+func newErrorStats() *errorStats {
+	return &errorStats{now: time.Now}
+}
+
+// middleware records the status of every response, and the route, error
+// message and request ID of 5xx ones. It must run after middleware.RequestID
+// and before middleware.Recoverer, so panics count as 500s.
+func (e *errorStats) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		body := &errorBodyCapture{ww: ww}
+		ww.Tee(body)
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK // nothing written; net/http sends 200
+			}
+			e.record(r, status, body.buf)
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// errorBodyCapture keeps the start of a 5xx response body.
+type errorBodyCapture struct {
+	ww  middleware.WrapResponseWriter
+	buf []byte
+}
+
+func (c *errorBodyCapture) Write(p []byte) (int, error) {
+	if c.ww.Status() >= 500 && len(c.buf) < maxErrorBody {
+		c.buf = append(c.buf, p[:min(len(p), maxErrorBody-len(c.buf))]...)
+	}
+	return len(p), nil
+}
+
+func (e *errorStats) record(r *http.Request, status int, body []byte) {
+	now := e.now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	minute := now.Unix() / 60
+	b := &e.buckets[minute%int64(len(e.buckets))]
+	if b.minute != minute {
+		*b = statusBucket{minute: minute}
+	}
+	e.served++
+	b.total++
+	switch {
+	case status >= 500:
+		b.serverErrors++
+	case status >= 400:
+		b.clientErrors++
+	}
+	if status < 500 {
+		return
+	}
+
+	se := serverError{
+		time:      now,
+		method:    r.Method,
+		route:     routePattern(r),
+		status:    status,
+		message:   errorMessage(status, body),
+		requestID: middleware.GetReqID(r.Context()),
+	}
+	if len(e.errors) < serverErrorLogSize {
+		e.errors = append(e.errors, se)
+		return
+	}
+	e.errors[e.next] = se
+	e.next = (e.next + 1) % serverErrorLogSize
+}
+
+// requestsServed returns the number of responses completed since start.
+func (e *errorStats) requestsServed() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.served
+}
+
+// errorMessage returns the message of an httputil.ErrorResponse body, or the
+// status text if the body isn't one.
+func errorMessage(status int, body []byte) string {
+	if msg, ok := httputil.ErrorMessage(body); ok {
+		return msg
+	}
+	if msg := strings.TrimSpace(string(body)); msg != "" && !strings.HasPrefix(msg, "{") {
+		return msg
+	}
+	return http.StatusText(status)
+}
+
+// ErrorRoute summarizes the 5xx responses of one route.
+type ErrorRoute struct {
+	Method        string    `json:"method"`
+	Route         string    `json:"route"`
+	Count         int       `json:"count"`
+	LastStatus    int       `json:"lastStatus"`
+	LastMessage   string    `json:"lastMessage"`
+	LastRequestID string    `json:"lastRequestId"`
+	LastSeen      time.Time `json:"lastSeen"`
+}
+
+// ErrorsResponse is the response of GET /api/admin/errors.
+type ErrorsResponse struct {
+	Since           time.Time    `json:"since"`
+	Requests        int          `json:"requests"`
+	ClientErrors    int          `json:"clientErrors"`
+	ServerErrors    int          `json:"serverErrors"`
+	ClientErrorRate float64      `json:"clientErrorRate"` // clientErrors / requests
+	ServerErrorRate float64      `json:"serverErrorRate"` // serverErrors / requests
+	Routes          []ErrorRoute `json:"routes"`          // most 5xx responses first
+}
+
+// summary returns the request counts and 5xx routes since the given time.
+func (e *errorStats) summary(since time.Time) ErrorsResponse {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	resp := ErrorsResponse{Since: since, Routes: []ErrorRoute{}}
+	sinceMinute := since.Unix() / 60
+	for _, b := range e.buckets {
+		if b.minute >= sinceMinute {
+			resp.Requests += b.total
+			resp.ClientErrors += b.clientErrors
+			resp.ServerErrors += b.serverErrors
+		}
+	}
+	if resp.Requests > 0 {
+		resp.ClientErrorRate = float64(resp.ClientErrors) / float64(resp.Requests)
+		resp.ServerErrorRate = float64(resp.ServerErrors) / float64(resp.Requests)
+	}
+
+	type key struct{ method, route string }
+	routes := make(map[key]*ErrorRoute)
+	for _, se := range e.errors {
+		if se.time.Before(since) {
+			continue
+		}
+		k := key{se.method, se.route}
+		er := routes[k]
+		if er == nil {
+			er = &ErrorRoute{Method: se.method, Route: se.route}
+			routes[k] = er
+		}
+		er.Count++
+		if !se.time.Before(er.LastSeen) {
+			er.LastStatus, er.LastMessage, er.LastRequestID, er.LastSeen = se.status, se.message, se.requestID, se.time
+		}
+	}
+	for _, er := range routes {
+		resp.Routes = append(resp.Routes, *er)
+	}
+	slices.SortFunc(resp.Routes, func(a, b ErrorRoute) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), b.LastSeen.Compare(a.LastSeen))
+	})
+	return resp
+}
+
+// handleAdminErrors handles GET /api/admin/errors: response rates and the
+// routes that returned 5xx over the last since (a duration of up to 24h;
+// default 1h).
+func (s *Server) handleAdminErrors(w http.ResponseWriter, r *http.Request) {
+	window := time.Hour
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > errorStatsWindow {
+			httputil.WriteError(w, http.StatusBadRequest, "since must be a positive duration of at most 24h, such as 1h")
+			return
+		}
+		window = d
+	}
+	httputil.WriteJSON(w, http.StatusOK, s.errorStats.summary(s.errorStats.now().Add(-window)))
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestErrorStatsMiddleware(t *testing.T) {
+	t.Parallel()
+	e := newErrorStats()
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(e.middleware)
+	r.Use(middleware.Recoverer)
+	r.Get("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	r.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		httputil.WriteError(w, http.StatusNotFound, "not found")
+	})
+	r.Get("/db/{table}", func(w http.ResponseWriter, r *http.Request) {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "database unavailable: "+chi.URLParam(r, "table"))
+	})
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+
+	for _, path := range []string{"/ok", "/ok", "/ok", "/items/1", "/items/2", "/db/posts", "/db/users", "/panic"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	got := e.summary(time.Now().Add(-time.Minute))
+	testutil.Equal(t, 8, got.Requests)
+	testutil.Equal(t, 2, got.ClientErrors)
+	testutil.Equal(t, 3, got.ServerErrors)
+	testutil.Equal(t, 0.25, got.ClientErrorRate)
+	testutil.Equal(t, 0.375, got.ServerErrorRate)
+
+	testutil.SliceLen(t, got.Routes, 2)
+	db := got.Routes[0]
+	testutil.Equal(t, "GET", db.Method)
+	testutil.Equal(t, "/db/{table}", db.Route)
+	testutil.Equal(t, 2, db.Count)
+	testutil.Equal(t, http.StatusServiceUnavailable, db.LastStatus)
+	testutil.Equal(t, "database unavailable: users", db.LastMessage)
+	testutil.True(t, db.LastRequestID != "", "request ID should be recorded")
+
+	testutil.Equal(t, "/panic", got.Routes[1].Route)
+	testutil.Equal(t, http.StatusInternalServerError, got.Routes[1].LastStatus)
+	testutil.Equal(t, "Internal Server Error", got.Routes[1].LastMessage)
+}
+
+func TestErrorStatsSince(t *testing.T) {
+	t.Parallel()
+	e := newErrorStats()
+	now := time.Now()
+	e.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	e.record(req, http.StatusInternalServerError, nil)
+	now = now.Add(2 * time.Hour)
+	e.record(req, http.StatusOK, nil)
+	e.record(req, http.StatusBadGateway, []byte(`{"error":{"code":"bad_gateway","message":"upstream failed"}}`))
+
+	got := e.summary(now.Add(-time.Hour))
+	testutil.Equal(t, 2, got.Requests)
+	testutil.Equal(t, 1, got.ServerErrors)
+	testutil.SliceLen(t, got.Routes, 1)
+	testutil.Equal(t, 1, got.Routes[0].Count)
+	testutil.Equal(t, "upstream failed", got.Routes[0].LastMessage)
+
+	// A day later the buckets have been reused.
+	now = now.Add(errorStatsWindow)
+	e.record(req, http.StatusOK, nil)
+	got = e.summary(now.Add(-errorStatsWindow))
+	testutil.Equal(t, 1, got.Requests)
+}
+
+func TestErrorStatsBounded(t *testing.T) {
+	t.Parallel()
+	e := newErrorStats()
+	for i := range serverErrorLogSize + 10 {
+		e.record(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/r%d", i), nil), http.StatusInternalServerError, nil)
+	}
+	testutil.SliceLen(t, e.errors, serverErrorLogSize)
+
+	got := e.summary(time.Now().Add(-time.Hour))
+	testutil.Equal(t, serverErrorLogSize+10, got.ServerErrors)
+	testutil.SliceLen(t, got.Routes, serverErrorLogSize)
+}
+
+func TestErrorMessage(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "db down", errorMessage(500, []byte(`{"error":{"code":"internal_error","message":"db down"}}`)))
+	testutil.Equal(t, "db down", errorMessage(500, []byte(`{"code":500,"message":"db down"}`)))
+	testutil.Equal(t, "upstream timeout", errorMessage(504, []byte("upstream timeout\n")))
+	testutil.Equal(t, "Internal Server Error", errorMessage(500, nil))
+	testutil.Equal(t, "Internal Server Error", errorMessage(500, []byte(`{"code":500,"mess`)))
+}
+
+func TestHandleAdminErrors(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	logger := testutil.DiscardLogger()
+	srv := New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	token := srv.adminAuth.token()
+
+	get := func(query, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/errors"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	testutil.Equal(t, http.StatusUnauthorized, get("", "").Code)
+	for _, since := range []string{"?since=soon", "?since=-1h", "?since=48h"} {
+		w := get(since, token)
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), "since must be")
+	}
+
+	w := get("?since=30m", token)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var resp ErrorsResponse
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	// The rejected requests above are counted.
+	testutil.Equal(t, 4, resp.ClientErrors)
+	testutil.NotNil(t, resp.Routes)
+	testutil.True(t, time.Since(resp.Since) > 29*time.Minute, "since = %s", resp.Since)
+}
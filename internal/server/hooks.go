@@ -0,0 +1,48 @@
+package server
+
+import "github.com/allyourbase/ayb/internal/api"
+
+// OnBeforeCreate registers fn to run before each record is created in table.
+// The hook may modify e.Record, or return an error to reject the create.
+// Hooks run in the request's transaction; see api.Handler.AddHook.
+// Register hooks before the server starts; without a database they are
+// ignored.
+func (s *Server) OnBeforeCreate(table string, fn api.HookFunc) {
+	s.addHook(api.HookBefore, api.HookCreate, table, fn)
+}
+
+// OnAfterCreate registers fn to run after each record is created in table,
+// with e.Record holding the new row. An error rolls the create back.
+func (s *Server) OnAfterCreate(table string, fn api.HookFunc) {
+	s.addHook(api.HookAfter, api.HookCreate, table, fn)
+}
+
+// OnBeforeUpdate registers fn to run before each record in table is updated.
+// The hook may modify e.Record, or return an error to reject the update.
+func (s *Server) OnBeforeUpdate(table string, fn api.HookFunc) {
+	s.addHook(api.HookBefore, api.HookUpdate, table, fn)
+}
+
+// OnAfterUpdate registers fn to run after each record in table is updated,
+// with e.Record holding the updated row. An error rolls the update back.
+func (s *Server) OnAfterUpdate(table string, fn api.HookFunc) {
+	s.addHook(api.HookAfter, api.HookUpdate, table, fn)
+}
+
+// OnBeforeDelete registers fn to run before each record in table is
+// deleted. An error rejects the delete.
+func (s *Server) OnBeforeDelete(table string, fn api.HookFunc) {
+	s.addHook(api.HookBefore, api.HookDelete, table, fn)
+}
+
+// OnAfterDelete registers fn to run after each record in table is deleted.
+// An error rolls the delete back.
+func (s *Server) OnAfterDelete(table string, fn api.HookFunc) {
+	s.addHook(api.HookAfter, api.HookDelete, table, fn)
+}
+
+func (s *Server) addHook(timing api.HookTiming, action, table string, fn api.HookFunc) {
+	if s.apiHandler != nil {
+		s.apiHandler.AddHook(timing, action, table, fn)
+	}
+}
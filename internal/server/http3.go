@@ -0,0 +1,59 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// altSvcMaxAge is how long, in seconds, clients may remember that HTTP/3 is
+// available.
+const altSvcMaxAge = 86400
+
+// altSvcMiddleware advertises the HTTP/3 listener on port to clients
+// connected over TCP, so they can switch to QUIC on their next request.
+func altSvcMiddleware(port int) func(http.Handler) http.Handler {
+	value := fmt.Sprintf(`h3=":%d"; ma=%d`, port, altSvcMaxAge)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor < 3 {
+				w.Header().Set("Alt-Svc", value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StartHTTP3 serves HTTP/3 over QUIC on the TLS address (UDP) with tlsCfg,
+// blocking until Shutdown. It returns nil immediately unless server.http3 is
+// set.
+func (s *Server) StartHTTP3(tlsCfg *tls.Config) error {
+	if s.h3 == nil {
+		return nil
+	}
+	conn, err := net.ListenPacket("udp", s.cfg.TLSAddress())
+	if err != nil {
+		return fmt.Errorf("http/3 listen: %w", err)
+	}
+	defer conn.Close()
+
+	s.h3.TLSConfig = http3.ConfigureTLSConfig(tlsCfg)
+	s.logger.Info("http/3 server starting", "address", conn.LocalAddr())
+	if err := s.h3.Serve(conn); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("http/3 server error: %w", err)
+	}
+	return nil
+}
+
+// newHTTP3Server returns the HTTP/3 server for handler, or nil unless
+// server.http3 is set.
+func newHTTP3Server(http3Enabled bool, handler http.Handler) *http3.Server {
+	if !http3Enabled {
+		return nil
+	}
+	return &http3.Server{Handler: handler, IdleTimeout: 120 * time.Second}
+}
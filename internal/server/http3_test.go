@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestAltSvcHeader(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.Server.TLSDomain = "api.example.com"
+	cfg.Server.HTTP3 = true
+	srv := server.New(cfg, testutil.DiscardLogger(), newCacheHolderWithSchema(nil), nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, `h3=":443"; ma=86400`, w.Header().Get("Alt-Svc"))
+}
+
+func TestAltSvcHeaderAbsentWithoutHTTP3(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t, newCacheHolderWithSchema(nil))
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	testutil.Equal(t, "", w.Header().Get("Alt-Svc"))
+}
+
+// TestStartHTTP3 serves HTTP/3 next to the TLS listener on the same port,
+// as ayb start does, with a self-signed cert.
+func TestStartHTTP3(t *testing.T) {
+	tlsCfg := generateTestTLSConfig(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", tlsCfg)
+	testutil.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = ln.Addr().(*net.TCPAddr).Port
+	cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile = testutil.WriteKeyPair(t, t.TempDir(), "localhost")
+	cfg.Server.HTTP3 = true
+	testutil.NoError(t, cfg.Validate())
+	srv := server.New(cfg, testutil.DiscardLogger(), newCacheHolderWithSchema(nil), nil, nil, nil)
+
+	ready := make(chan struct{})
+	errCh := make(chan error, 2)
+	go func() { errCh <- srv.StartTLSWithReady(ln, ready) }()
+	go func() { errCh <- srv.StartHTTP3(tlsCfg) }()
+	<-ready
+
+	insecure := &tls.Config{InsecureSkipVerify: true} //nolint:gosec — test only
+	resp, err := (&http.Client{Transport: &http.Transport{TLSClientConfig: insecure}}).Get(cfg.PublicBaseURL() + "/health")
+	testutil.NoError(t, err)
+	resp.Body.Close()
+	altSvc := resp.Header.Get("Alt-Svc")
+	testutil.Contains(t, altSvc, fmt.Sprintf(`h3=":%d"`, cfg.Server.Port))
+
+	tr := &http3.Transport{TLSClientConfig: insecure}
+	defer tr.Close()
+	client := &http.Client{Transport: tr, Timeout: 2 * time.Second}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err = client.Get(cfg.PublicBaseURL() + "/health")
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	testutil.NoError(t, err)
+	resp.Body.Close()
+	testutil.Equal(t, http.StatusOK, resp.StatusCode)
+	testutil.Equal(t, 3, resp.ProtoMajor)
+	testutil.Equal(t, "", resp.Header.Get("Alt-Svc"))
+
+	if err := srv.Shutdown(t.Context()); err != nil {
+		t.Logf("shutdown: %v", err)
+	}
+	for range 2 {
+		select {
+		case err := <-errCh:
+			testutil.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for servers to exit")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// localizeErrorsMiddleware translates the message of error responses into
+// the language the client's Accept-Language prefers, when translations has
+// one for the error code and the message is the code's default. Codes and
+// every other field are unchanged.
+func localizeErrorsMiddleware(translations httputil.ErrorTranslations) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(translations) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang := translations.Negotiate(r.Header.Get("Accept-Language"))
+			if lang == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			lw := &localizeWriter{ResponseWriter: w, translations: translations, lang: lang}
+			defer lw.close()
+			next.ServeHTTP(lw, r)
+		})
+	}
+}
+
+// localizeWriter holds back JSON error responses until the handler is done,
+// so their message can be replaced. Other responses pass straight through.
+type localizeWriter struct {
+	http.ResponseWriter
+	translations httputil.ErrorTranslations
+	lang         string
+
+	status    int
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *localizeWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	if code < 400 {
+		if code >= 200 {
+			w.status = code
+		}
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	w.Header().Add("Vary", "Accept-Language")
+	if mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type")); mediaType == "application/json" {
+		w.buffering = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *localizeWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// close writes a held-back error response, with its message translated if
+// it is an error envelope with its code's default message and the code has a
+// translation.
+func (w *localizeWriter) close() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf.Bytes()
+	var resp httputil.ErrorResponse
+	if json.Unmarshal(body, &resp) == nil {
+		if msg, ok := w.translations.Translate(w.lang, resp.Error.Code, resp.Error.Message); ok {
+			resp.Error.Message = msg
+			if b, err := json.Marshal(resp); err == nil {
+				body = append(b, '\n')
+				w.Header().Set("Content-Language", w.lang)
+			}
+		}
+	}
+	if w.Header().Get("Content-Length") != "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// Flush lets streaming handlers flush through the wrapper. A held-back
+// error response is written when the handler returns.
+func (w *localizeWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *localizeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
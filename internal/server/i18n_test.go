@@ -0,0 +1,83 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func newTranslatedTestServer(t *testing.T) *server.Server {
+	t.Helper()
+	dir := t.TempDir()
+	testutil.NoError(t, os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{
+		"not_found": "recurso no encontrado",
+		"method_not_allowed": "método no permitido",
+		"unavailable": "servicio no disponible"
+	}`), 0o600))
+	cfg := config.Default()
+	cfg.Server.ErrorTranslations = dir
+	testutil.NoError(t, cfg.Validate())
+	logger := testutil.DiscardLogger()
+	return server.New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+}
+
+func TestErrorMessagesLocalized(t *testing.T) {
+	t.Parallel()
+	srv := newTranslatedTestServer(t)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptLanguage string
+		status         int
+		code           string
+		message        string
+		contentLang    string
+	}{
+		{"spanish", http.MethodGet, "/api/nope", "es-ES,es;q=0.9", http.StatusNotFound, httputil.CodeNotFound, "recurso no encontrado", "es"},
+		{"spanish 405", http.MethodPut, "/api/schema", "es", http.StatusMethodNotAllowed, httputil.CodeMethodNotAllowed, "método no permitido", "es"},
+		{"no header", http.MethodGet, "/api/nope", "", http.StatusNotFound, httputil.CodeNotFound, "not found", ""},
+		{"english preferred", http.MethodGet, "/api/nope", "en-US, es;q=0.8", http.StatusNotFound, httputil.CodeNotFound, "not found", ""},
+		{"specific message kept", http.MethodGet, "/api/schema", "es", http.StatusServiceUnavailable, httputil.CodeUnavailable, "schema cache not ready", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			srv.Router().ServeHTTP(w, req)
+
+			testutil.Equal(t, tt.status, w.Code)
+			testutil.Equal(t, tt.contentLang, w.Header().Get("Content-Language"))
+			var resp httputil.ErrorResponse
+			testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			testutil.Equal(t, tt.code, resp.Error.Code)
+			testutil.Equal(t, tt.message, resp.Error.Message)
+		})
+	}
+}
+
+func TestLocalizedSuccessResponsesUnchanged(t *testing.T) {
+	t.Parallel()
+	srv := newTranslatedTestServer(t)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/status", nil)
+	req.Header.Set("Accept-Language", "es")
+	srv.Router().ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "", w.Header().Get("Content-Language"))
+	testutil.Contains(t, w.Body.String(), `"auth"`)
+}
@@ -3,7 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +20,8 @@ import (
 // jobAdmin is the interface for job queue admin operations.
 // jobs.Service satisfies this interface.
 type jobAdmin interface {
+	JobTypes() []string
+	Enqueue(ctx context.Context, jobType string, payload json.RawMessage, opts jobs.EnqueueOpts) (*jobs.Job, error)
 	List(ctx context.Context, state, jobType string, limit, offset int) ([]jobs.Job, error)
 	Get(ctx context.Context, jobID string) (*jobs.Job, error)
 	RetryNow(ctx context.Context, jobID string) (*jobs.Job, error)
@@ -41,6 +46,14 @@ type scheduleListResponse struct {
 	Count int             `json:"count"` // number of items returned
 }
 
+type enqueueJobRequest struct {
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	MaxAttempts int             `json:"maxAttempts"` // 0 = default (3)
+	Priority    int             `json:"priority"`    // higher is claimed first
+	RunAt       *time.Time      `json:"runAt"`       // nil = now
+}
+
 type createScheduleRequest struct {
 	Name        string          `json:"name"`
 	JobType     string          `json:"jobType"`
@@ -96,6 +109,49 @@ func handleAdminListJobs(svc jobAdmin) http.HandlerFunc {
 	}
 }
 
+// handleAdminEnqueueJob enqueues a job of a type that has a registered
+// handler, so external systems can schedule work.
+func handleAdminEnqueueJob(svc jobAdmin) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req enqueueJobRequest
+		if !httputil.DecodeJSON(w, r, &req) {
+			return
+		}
+		if req.Type == "" {
+			httputil.WriteError(w, http.StatusBadRequest, "type is required")
+			return
+		}
+		if types := svc.JobTypes(); !slices.Contains(types, req.Type) {
+			httputil.WriteError(w, http.StatusBadRequest, fmt.Sprintf(
+				"unknown job type %q; registered types: %s", req.Type, strings.Join(types, ", ")))
+			return
+		}
+		if req.MaxAttempts < 0 {
+			httputil.WriteError(w, http.StatusBadRequest, "maxAttempts must be at least 1")
+			return
+		}
+		if req.Priority < math.MinInt32 || req.Priority > math.MaxInt32 {
+			httputil.WriteError(w, http.StatusBadRequest, "priority is out of range")
+			return
+		}
+		if string(req.Payload) == "null" {
+			req.Payload = nil // stored as {}
+		}
+
+		job, err := svc.Enqueue(r.Context(), req.Type, req.Payload, jobs.EnqueueOpts{
+			RunAt:       req.RunAt,
+			MaxAttempts: req.MaxAttempts,
+			Priority:    req.Priority,
+		})
+		if err != nil {
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to enqueue job")
+			return
+		}
+
+		httputil.WriteJSON(w, http.StatusCreated, job)
+	}
+}
+
 // handleAdminGetJob returns a single job by ID.
 func handleAdminGetJob(svc jobAdmin) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
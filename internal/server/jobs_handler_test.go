@@ -17,13 +17,14 @@ import (
 
 // fakeJobService is an in-memory fake for testing jobs admin handlers.
 type fakeJobService struct {
-	jobs      []jobs.Job
-	schedules []jobs.Schedule
-	listErr   error
-	getErr    error
-	retryErr  error
-	cancelErr error
-	statsErr  error
+	jobs       []jobs.Job
+	schedules  []jobs.Schedule
+	listErr    error
+	enqueueErr error
+	getErr     error
+	retryErr   error
+	cancelErr  error
+	statsErr   error
 
 	schedCreateErr error
 	schedUpdateErr error
@@ -35,6 +36,36 @@ type fakeJobService struct {
 	lastUpdateNextRunAt  *time.Time
 }
 
+func (f *fakeJobService) JobTypes() []string {
+	return []string{"stale_session_cleanup", "webhook_delivery_prune"}
+}
+
+func (f *fakeJobService) Enqueue(_ context.Context, jobType string, payload json.RawMessage, opts jobs.EnqueueOpts) (*jobs.Job, error) {
+	if f.enqueueErr != nil {
+		return nil, f.enqueueErr
+	}
+	now := time.Now()
+	job := jobs.Job{
+		ID:          fmt.Sprintf("44444444-4444-4444-4444-%012d", len(f.jobs)),
+		Type:        jobType,
+		Payload:     payload,
+		State:       jobs.StateQueued,
+		RunAt:       now,
+		Priority:    opts.Priority,
+		MaxAttempts: 3,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if opts.RunAt != nil {
+		job.RunAt = *opts.RunAt
+	}
+	if opts.MaxAttempts > 0 {
+		job.MaxAttempts = opts.MaxAttempts
+	}
+	f.jobs = append(f.jobs, job)
+	return &job, nil
+}
+
 func (f *fakeJobService) List(_ context.Context, state, jobType string, limit, offset int) ([]jobs.Job, error) {
 	if f.listErr != nil {
 		return nil, f.listErr
@@ -314,6 +345,102 @@ func TestHandleAdminListJobsFilterType(t *testing.T) {
 	testutil.Equal(t, 2, resp.Count)
 }
 
+// --- Jobs Enqueue ---
+
+func enqueueJobRouter(svc jobAdmin) chi.Router {
+	r := chi.NewRouter()
+	r.Post("/api/admin/jobs", handleAdminEnqueueJob(svc))
+	r.Get("/api/admin/jobs/{id}", handleAdminGetJob(svc))
+	r.Post("/api/admin/jobs/{id}/cancel", handleAdminCancelJob(svc))
+	return r
+}
+
+func TestHandleAdminEnqueueJob(t *testing.T) {
+	svc := newFakeJobService()
+	r := enqueueJobRouter(svc)
+
+	runAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := fmt.Sprintf(`{"type":"webhook_delivery_prune","payload":{"retention_hours":24},"maxAttempts":5,"priority":10,"runAt":%q}`,
+		runAt.Format(time.RFC3339))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/jobs", strings.NewReader(body)))
+	testutil.Equal(t, http.StatusCreated, w.Code)
+
+	var created jobs.Job
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	testutil.Equal(t, "webhook_delivery_prune", created.Type)
+	testutil.Equal(t, jobs.StateQueued, created.State)
+	testutil.Equal(t, 5, created.MaxAttempts)
+	testutil.Equal(t, 10, created.Priority)
+	testutil.True(t, created.RunAt.Equal(runAt), "runAt = %v", created.RunAt)
+	testutil.Equal(t, `{"retention_hours":24}`, string(created.Payload))
+
+	// The status endpoint reports the queued job, then its cancellation.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/jobs/"+created.ID, nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var status jobs.Job
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	testutil.Equal(t, jobs.StateQueued, status.State)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/jobs/"+created.ID+"/cancel", nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/jobs/"+created.ID, nil))
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	testutil.Equal(t, jobs.StateCanceled, status.State)
+}
+
+func TestHandleAdminEnqueueJobDefaults(t *testing.T) {
+	svc := newFakeJobService()
+	w := httptest.NewRecorder()
+	enqueueJobRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/jobs",
+		strings.NewReader(`{"type":"stale_session_cleanup","payload":null}`)))
+	testutil.Equal(t, http.StatusCreated, w.Code)
+
+	var created jobs.Job
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	testutil.Equal(t, 3, created.MaxAttempts)
+	testutil.Equal(t, 0, created.Priority)
+	testutil.True(t, svc.jobs[3].Payload == nil, "a null payload is left to the store default")
+}
+
+func TestHandleAdminEnqueueJobValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"missing type", `{}`, "type is required"},
+		{"unknown type", `{"type":"send_invoice"}`,
+			`unknown job type \"send_invoice\"; registered types: stale_session_cleanup, webhook_delivery_prune`},
+		{"negative max attempts", `{"type":"stale_session_cleanup","maxAttempts":-1}`, "maxAttempts must be at least 1"},
+		{"priority out of range", `{"type":"stale_session_cleanup","priority":3000000000}`, "priority is out of range"},
+		{"bad run time", `{"type":"stale_session_cleanup","runAt":"tomorrow"}`, "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newFakeJobService()
+			w := httptest.NewRecorder()
+			enqueueJobRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/jobs", strings.NewReader(tt.body)))
+			testutil.Equal(t, http.StatusBadRequest, w.Code)
+			testutil.Contains(t, w.Body.String(), tt.want)
+			testutil.Equal(t, 3, len(svc.jobs)) // nothing enqueued
+		})
+	}
+}
+
+func TestHandleAdminEnqueueJobStoreError(t *testing.T) {
+	svc := newFakeJobService()
+	svc.enqueueErr = fmt.Errorf("db down")
+	w := httptest.NewRecorder()
+	enqueueJobRouter(svc).ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/jobs",
+		strings.NewReader(`{"type":"stale_session_cleanup"}`)))
+	testutil.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
 // --- Jobs Get ---
 
 func TestHandleAdminGetJob(t *testing.T) {
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
@@ -13,25 +14,43 @@ type LogEntry struct {
 	Level   string         `json:"level"`
 	Message string         `json:"message"`
 	Attrs   map[string]any `json:"attrs,omitempty"`
+
+	level slog.Level
 }
 
+// logFollowBuffer is how many entries a follower may fall behind by before
+// new entries are dropped for it.
+const logFollowBuffer = 256
+
 // LogBuffer is a ring-buffer slog.Handler that captures recent log entries
-// while forwarding them to a wrapped handler.
+// while forwarding them to a wrapped handler. Handlers derived with WithAttrs
+// and WithGroup share the ring.
 type LogBuffer struct {
-	inner   slog.Handler
-	mu      sync.Mutex
-	entries []LogEntry
-	maxSize int
-	pos     int
-	full    bool
+	inner slog.Handler
+	attrs []slog.Attr // from WithAttrs, added to each entry
+	ring  *logRing
+}
+
+// logRing holds the captured entries and the followers waiting for new ones.
+type logRing struct {
+	mu        sync.Mutex
+	entries   []LogEntry
+	maxSize   int
+	pos       int
+	full      bool
+	followers map[chan LogEntry]struct{}
+	closed    bool // followers have been ended by CloseFollowers
 }
 
 // NewLogBuffer creates a LogBuffer wrapping the given handler, retaining up to maxSize entries.
 func NewLogBuffer(inner slog.Handler, maxSize int) *LogBuffer {
 	return &LogBuffer{
-		inner:   inner,
-		entries: make([]LogEntry, maxSize),
-		maxSize: maxSize,
+		inner: inner,
+		ring: &logRing{
+			entries:   make([]LogEntry, maxSize),
+			maxSize:   maxSize,
+			followers: make(map[chan LogEntry]struct{}),
+		},
 	}
 }
 
@@ -46,64 +65,115 @@ func (lb *LogBuffer) Handle(ctx context.Context, r slog.Record) error {
 		Time:    r.Time,
 		Level:   r.Level.String(),
 		Message: r.Message,
+		level:   r.Level,
 	}
 
-	if r.NumAttrs() > 0 {
-		entry.Attrs = make(map[string]any, r.NumAttrs())
+	if n := len(lb.attrs) + r.NumAttrs(); n > 0 {
+		entry.Attrs = make(map[string]any, n)
+		for _, a := range lb.attrs {
+			entry.Attrs[a.Key] = a.Value.Any()
+		}
 		r.Attrs(func(a slog.Attr) bool {
 			entry.Attrs[a.Key] = a.Value.Any()
 			return true
 		})
 	}
 
-	lb.mu.Lock()
-	lb.entries[lb.pos] = entry
-	lb.pos++
-	if lb.pos >= lb.maxSize {
-		lb.pos = 0
-		lb.full = true
-	}
-	lb.mu.Unlock()
-
+	lb.ring.add(entry)
 	return lb.inner.Handle(ctx, r)
 }
 
 // WithAttrs delegates to the inner handler.
 func (lb *LogBuffer) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &LogBuffer{
-		inner:   lb.inner.WithAttrs(attrs),
-		entries: lb.entries,
-		maxSize: lb.maxSize,
-		pos:     lb.pos,
-		full:    lb.full,
+		inner: lb.inner.WithAttrs(attrs),
+		attrs: append(slices.Clip(lb.attrs), attrs...),
+		ring:  lb.ring,
 	}
 }
 
 // WithGroup delegates to the inner handler.
 func (lb *LogBuffer) WithGroup(name string) slog.Handler {
 	return &LogBuffer{
-		inner:   lb.inner.WithGroup(name),
-		entries: lb.entries,
-		maxSize: lb.maxSize,
-		pos:     lb.pos,
-		full:    lb.full,
+		inner: lb.inner.WithGroup(name),
+		attrs: lb.attrs,
+		ring:  lb.ring,
 	}
 }
 
 // Entries returns the buffered log entries in chronological order.
 func (lb *LogBuffer) Entries() []LogEntry {
-	lb.mu.Lock()
-	defer lb.mu.Unlock()
+	lb.ring.mu.Lock()
+	defer lb.ring.mu.Unlock()
+	return lb.ring.snapshot()
+}
+
+// Follow returns the buffered entries and a channel that receives each entry
+// logged after them. A follower that falls behind misses entries rather than
+// blocking logging. stop must be called to release the channel; the channel
+// is closed by stop or CloseFollowers.
+func (lb *LogBuffer) Follow() (entries []LogEntry, ch <-chan LogEntry, stop func()) {
+	ring := lb.ring
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	c := make(chan LogEntry, logFollowBuffer)
+	if ring.closed {
+		close(c)
+		return ring.snapshot(), c, func() {}
+	}
+	ring.followers[c] = struct{}{}
+	return ring.snapshot(), c, func() {
+		ring.mu.Lock()
+		defer ring.mu.Unlock()
+		if _, ok := ring.followers[c]; ok {
+			delete(ring.followers, c)
+			close(c)
+		}
+	}
+}
+
+// CloseFollowers ends all current and future Follow channels, so streaming
+// requests finish during shutdown.
+func (lb *LogBuffer) CloseFollowers() {
+	ring := lb.ring
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+	ring.closed = true
+	for c := range ring.followers {
+		delete(ring.followers, c)
+		close(c)
+	}
+}
+
+func (r *logRing) add(entry LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.pos] = entry
+	r.pos++
+	if r.pos >= r.maxSize {
+		r.pos = 0
+		r.full = true
+	}
+	for c := range r.followers {
+		select {
+		case c <- entry:
+		default:
+		}
+	}
+}
 
-	if !lb.full {
-		result := make([]LogEntry, lb.pos)
-		copy(result, lb.entries[:lb.pos])
+// snapshot returns the entries in chronological order. r.mu must be held.
+func (r *logRing) snapshot() []LogEntry {
+	if !r.full {
+		result := make([]LogEntry, r.pos)
+		copy(result, r.entries[:r.pos])
 		return result
 	}
 
 	// Ring buffer is full: entries from pos..end, then 0..pos.
-	result := make([]LogEntry, lb.maxSize)
-	copy(result, lb.entries[lb.pos:])
-	copy(result[lb.maxSize-lb.pos:], lb.entries[:lb.pos])
+	result := make([]LogEntry, r.maxSize)
+	copy(result, r.entries[r.pos:])
+	copy(result[r.maxSize-r.pos:], r.entries[:r.pos])
 	return result
 }
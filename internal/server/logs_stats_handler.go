@@ -1,15 +1,37 @@
 package server
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/logfile"
 )
 
-// handleAdminLogs returns recent server log entries.
+// handleAdminLogs returns recent server log entries. Query parameters:
+// level (minimum level), since and until (RFC3339 or a duration before now),
+// and lines (the most recent n matching entries; 0 or absent for all). With
+// follow=true the matching entries are streamed as NDJSON, followed by each
+// new matching entry until the client disconnects.
 func (s *Server) handleAdminLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := logfile.ParseFilter(q.Get("level"), q.Get("since"), q.Get("until"), time.Now())
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lines := 0
+	if v := q.Get("lines"); v != "" {
+		if lines, err = strconv.Atoi(v); err != nil || lines < 0 {
+			httputil.WriteError(w, http.StatusBadRequest, "lines must be a non-negative integer")
+			return
+		}
+	}
+
 	// Return log buffer entries if available, otherwise a helpful message.
 	if s.logBuffer == nil {
 		httputil.WriteJSON(w, http.StatusOK, map[string]any{
@@ -19,12 +41,69 @@ func (s *Server) handleAdminLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if q.Get("follow") == "true" {
+		s.followLogs(w, r, filter, lines)
+		return
+	}
 	httputil.WriteJSON(w, http.StatusOK, map[string]any{
-		"entries": s.logBuffer.Entries(),
+		"entries": filterLogEntries(s.logBuffer.Entries(), filter, lines),
 	})
 }
 
-// handleAdminStats returns server runtime statistics.
+// followLogs streams the last lines matching entries and then each new one.
+func (s *Server) followLogs(w http.ResponseWriter, r *http.Request, filter logfile.Filter, lines int) {
+	entries, ch, stop := s.logBuffer.Follow()
+	defer stop()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for _, e := range filterLogEntries(entries, filter, lines) {
+		if enc.Encode(e) != nil {
+			return
+		}
+	}
+	rc.Flush() //nolint:errcheck
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !filter.Match(e.Time, e.level) {
+				continue
+			}
+			if enc.Encode(e) != nil {
+				return
+			}
+			rc.Flush() //nolint:errcheck
+		}
+	}
+}
+
+// filterLogEntries returns the last n entries matching filter, or all
+// matching entries when n is 0.
+func filterLogEntries(entries []LogEntry, filter logfile.Filter, n int) []LogEntry {
+	matched := make([]LogEntry, 0, len(entries))
+	for _, e := range entries {
+		if filter.Match(e.Time, e.level) {
+			matched = append(matched, e)
+		}
+	}
+	if n > 0 && len(matched) > n {
+		matched = matched[len(matched)-n:]
+	}
+	return matched
+}
+
+// handleAdminStats returns server runtime statistics, request counts, and
+// database pool and today's SMS counts when those are enabled. Job queue
+// stats are served by /api/admin/jobs/stats.
 func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
 	var mem runtime.MemStats
 	runtime.ReadMemStats(&mem)
@@ -36,6 +115,9 @@ func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
 		"memory_alloc":   mem.Alloc,
 		"memory_sys":     mem.Sys,
 		"gc_cycles":      mem.NumGC,
+
+		"requests_total":     s.errorStats.requestsServed(),
+		"requests_in_flight": s.drain.inFlight.Load(),
 	}
 
 	if s.pool != nil {
@@ -46,22 +128,61 @@ func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
 		stats["db_pool_max"] = poolStat.MaxConns()
 	}
 
+	if s.smsProvider != nil && s.pool != nil {
+		var sent, confirmed, failed int
+		err := s.pool.QueryRow(r.Context(), `SELECT COALESCE(SUM(count), 0), COALESCE(SUM(confirm_count), 0), COALESCE(SUM(fail_count), 0)
+			FROM _ayb_sms_daily_counts WHERE date = CURRENT_DATE`).Scan(&sent, &confirmed, &failed)
+		if err != nil {
+			s.logger.Warn("stats: SMS counts unavailable", "error", err)
+		} else {
+			stats["sms_sent_today"] = sent
+			stats["sms_confirmed_today"] = confirmed
+			stats["sms_failed_today"] = failed
+		}
+	}
+
 	httputil.WriteJSON(w, http.StatusOK, stats)
 }
 
-// handleAdminSecretsRotate generates a new JWT secret, invalidating all tokens.
+// rotateSecretRequest is the optional body of POST /api/admin/secrets/rotate.
+type rotateSecretRequest struct {
+	OverlapSeconds *int `json:"overlapSeconds"` // default: auth.jwt_secret_overlap
+}
+
+// handleAdminSecretsRotate generates a new JWT secret. Tokens signed with the
+// old secret stay valid for the overlap window, then are rejected.
 // Route is only registered when authSvc != nil (see server.go).
 func (s *Server) handleAdminSecretsRotate(w http.ResponseWriter, r *http.Request) {
-	_, err := s.authSvc.RotateJWTSecret()
+	overlap := s.cfg.Auth.JWTSecretOverlap
+	if r.ContentLength != 0 {
+		var req rotateSecretRequest
+		if !httputil.DecodeJSON(w, r, &req) {
+			return
+		}
+		if req.OverlapSeconds != nil {
+			overlap = *req.OverlapSeconds
+		}
+	}
+	if overlap < 0 {
+		httputil.WriteError(w, http.StatusBadRequest, "overlapSeconds must be non-negative")
+		return
+	}
+
+	_, err := s.authSvc.RotateJWTSecret(time.Duration(overlap) * time.Second)
 	if err != nil {
 		s.logger.Error("JWT secret rotation failed", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "failed to rotate secret")
 		return
 	}
 
-	s.logger.Info("JWT secret rotated")
+	s.logger.Info("JWT secret rotated", "overlap_seconds", overlap)
 
-	httputil.WriteJSON(w, http.StatusOK, map[string]string{
-		"message": "JWT secret rotated successfully. All existing tokens have been invalidated.",
+	message := "JWT secret rotated successfully. All existing tokens have been invalidated."
+	if overlap > 0 {
+		message = fmt.Sprintf("JWT secret rotated successfully. Existing tokens remain valid for %d seconds.", overlap)
+	}
+	httputil.WriteJSON(w, http.StatusOK, map[string]any{
+		"message":        message,
+		"overlapSeconds": overlap,
 	})
 }
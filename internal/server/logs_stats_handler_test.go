@@ -1,6 +1,7 @@
 package server_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -129,6 +130,175 @@ func TestAdminLogsRequiresAuth(t *testing.T) {
 	testutil.Contains(t, w.Body.String(), "admin authentication required")
 }
 
+// newLogBufferServer returns a server with a log buffer holding entries
+// logged an hour ago (debug, info) and a minute ago (warn, error), and an
+// admin token for it.
+func newLogBufferServer(t *testing.T) (*server.Server, *server.LogBuffer, string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	lb := server.NewLogBuffer(slog.NewTextHandler(io.Discard, nil), 100)
+	logger := slog.New(lb)
+	srv := server.New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	srv.SetLogBuffer(lb)
+	token := adminLogin(t, srv)
+
+	now := time.Now()
+	for _, r := range []slog.Record{
+		slog.NewRecord(now.Add(-time.Hour), slog.LevelDebug, "old debug", 0),
+		slog.NewRecord(now.Add(-time.Hour), slog.LevelInfo, "old info", 0),
+		slog.NewRecord(now.Add(-time.Minute), slog.LevelWarn, "new warn", 0),
+		slog.NewRecord(now.Add(-time.Minute), slog.LevelError, "new error", 0),
+	} {
+		testutil.NoError(t, lb.Handle(context.Background(), r))
+	}
+	return srv, lb, token
+}
+
+func adminLogMessages(t *testing.T, srv *server.Server, token, query string) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/logs/?"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	srv.Router().ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Entries []server.LogEntry `json:"entries"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	var msgs []string
+	for _, e := range body.Entries {
+		if strings.HasPrefix(e.Message, "old ") || strings.HasPrefix(e.Message, "new ") {
+			msgs = append(msgs, e.Message)
+		}
+	}
+	return msgs
+}
+
+func TestAdminLogsFilters(t *testing.T) {
+	t.Parallel()
+	srv, _, token := newLogBufferServer(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"level=info", []string{"old info", "new warn", "new error"}},
+		{"level=warn", []string{"new warn", "new error"}},
+		{"since=30m", []string{"new warn", "new error"}},
+		{"until=30m", []string{"old debug", "old info"}},
+		{"level=info&until=30m", []string{"old info"}},
+		{"level=warn&lines=1", []string{"new error"}},
+		{"since=" + time.Now().Add(-2*time.Minute).UTC().Format(time.RFC3339), []string{"new warn", "new error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			testutil.Equal(t, strings.Join(tt.want, ","), strings.Join(adminLogMessages(t, srv, token, tt.query), ","))
+		})
+	}
+}
+
+func TestAdminLogsInvalidFilter(t *testing.T) {
+	t.Parallel()
+	srv, _, token := newLogBufferServer(t)
+
+	for query, wantErr := range map[string]string{
+		"level=loud":    "invalid level",
+		"since=monday":  "invalid since",
+		"until=-1h":     "invalid until",
+		"lines=-1":      "lines must be a non-negative integer",
+		"lines=several": "lines must be a non-negative integer",
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/logs/?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		srv.Router().ServeHTTP(w, req)
+		testutil.Equal(t, http.StatusBadRequest, w.Code)
+		testutil.Contains(t, w.Body.String(), wantErr)
+	}
+}
+
+func TestAdminLogsFollowStreamsMatchingEntries(t *testing.T) {
+	t.Parallel()
+	srv, lb, token := newLogBufferServer(t)
+	ts := httptest.NewServer(srv.Router())
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/admin/logs/?follow=true&level=warn&lines=1", nil)
+	testutil.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	testutil.NoError(t, err)
+	defer resp.Body.Close()
+	testutil.Equal(t, http.StatusOK, resp.StatusCode)
+	testutil.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	dec := json.NewDecoder(resp.Body)
+	next := func() server.LogEntry {
+		t.Helper()
+		var e server.LogEntry
+		testutil.NoError(t, dec.Decode(&e))
+		return e
+	}
+	testutil.Equal(t, "new error", next().Message)
+
+	logger := slog.New(lb)
+	logger.Info("streamed info")
+	logger.Warn("streamed warn", "k", "v")
+	e := next()
+	testutil.Equal(t, "streamed warn", e.Message)
+	testutil.Equal(t, "v", e.Attrs["k"])
+
+	// Shutdown ends the stream.
+	lb.CloseFollowers()
+	var extra server.LogEntry
+	testutil.Equal(t, io.EOF, dec.Decode(&extra))
+}
+
+func TestLogBufferWithAttrsSharesRing(t *testing.T) {
+	t.Parallel()
+	lb := server.NewLogBuffer(slog.NewTextHandler(io.Discard, nil), 3)
+	logger := slog.New(lb)
+	child := logger.With("component", "jobs")
+
+	logger.Info("a")
+	child.Info("b", "id", 1)
+	logger.Info("c")
+	child.Info("d")
+
+	entries := lb.Entries()
+	testutil.Equal(t, 3, len(entries))
+	testutil.Equal(t, "b", entries[0].Message)
+	testutil.Equal(t, "jobs", entries[0].Attrs["component"])
+	testutil.Equal(t, any(int64(1)), entries[0].Attrs["id"])
+	testutil.Equal(t, "c", entries[1].Message)
+	testutil.Equal(t, "d", entries[2].Message)
+}
+
+func TestLogBufferFollow(t *testing.T) {
+	t.Parallel()
+	lb := server.NewLogBuffer(slog.NewTextHandler(io.Discard, nil), 10)
+	logger := slog.New(lb)
+	logger.Info("before")
+
+	entries, ch, stop := lb.Follow()
+	testutil.Equal(t, 1, len(entries))
+	logger.Info("after")
+	testutil.Equal(t, "after", (<-ch).Message)
+
+	stop()
+	_, ok := <-ch
+	testutil.False(t, ok, "channel should be closed by stop")
+	stop() // idempotent
+
+	lb.CloseFollowers()
+	_, ch, stop = lb.Follow()
+	defer stop()
+	_, ok = <-ch
+	testutil.False(t, ok, "Follow after CloseFollowers should return a closed channel")
+}
+
 // --- Stats endpoint tests ---
 
 func TestAdminStatsReturnsRuntimeInfo(t *testing.T) {
@@ -157,6 +327,9 @@ func TestAdminStatsReturnsRuntimeInfo(t *testing.T) {
 	testutil.True(t, memSys > 0, "memory_sys should be positive")
 	gcCycles := stats["gc_cycles"].(float64)
 	testutil.True(t, gcCycles >= 0, "gc_cycles should be non-negative")
+	// The admin login has completed; the stats request itself is in flight.
+	testutil.Equal(t, 1.0, stats["requests_total"].(float64))
+	testutil.Equal(t, 1.0, stats["requests_in_flight"].(float64))
 }
 
 func TestAdminStatsNoDBPoolFields(t *testing.T) {
@@ -173,8 +346,9 @@ func TestAdminStatsNoDBPoolFields(t *testing.T) {
 	var stats map[string]any
 	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
 
-	// Without a pool, DB stats should not be present.
+	// Without a pool, DB and SMS stats should not be present.
 	testutil.Nil(t, stats["db_pool_total"])
+	testutil.Nil(t, stats["sms_sent_today"])
 }
 
 func TestAdminStatsRequiresAuth(t *testing.T) {
@@ -202,9 +376,10 @@ func TestAdminSecretsRotateSuccess(t *testing.T) {
 	srv.Router().ServeHTTP(w, req)
 
 	testutil.Equal(t, http.StatusOK, w.Code)
-	var body map[string]string
+	var body map[string]any
 	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
-	testutil.Contains(t, body["message"], "rotated successfully")
+	testutil.Contains(t, body["message"].(string), "rotated successfully")
+	testutil.Equal(t, 900.0, body["overlapSeconds"])
 }
 
 func TestAdminSecretsRotateInvalidatesOldTokens(t *testing.T) {
@@ -217,12 +392,13 @@ func TestAdminSecretsRotateInvalidatesOldTokens(t *testing.T) {
 	testutil.NoError(t, err)
 	testutil.True(t, oldJWT != "", "should have generated a token")
 
-	// Rotate.
+	// Rotate without an overlap window.
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest(http.MethodPost, "/api/admin/secrets/rotate", nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/secrets/rotate", strings.NewReader(`{"overlapSeconds":0}`))
 	req.Header.Set("Authorization", "Bearer "+token)
 	srv.Router().ServeHTTP(w, req)
 	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "invalidated")
 
 	// Old JWT should no longer validate.
 	_, err = authSvc.ValidateToken(oldJWT)
@@ -236,6 +412,41 @@ func TestAdminSecretsRotateInvalidatesOldTokens(t *testing.T) {
 	testutil.Equal(t, "new@example.com", claims.Email)
 }
 
+func TestAdminSecretsRotateKeepsOldTokensDuringOverlap(t *testing.T) {
+	t.Parallel()
+	srv, authSvc := newTestServerWithAuth(t, "testpass")
+	token := adminLogin(t, srv)
+
+	oldJWT, err := authSvc.IssueTestToken("user-1", "test@example.com")
+	testutil.NoError(t, err)
+
+	// The default overlap is auth.jwt_secret_overlap.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/secrets/rotate", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	srv.Router().ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), "remain valid for 900 seconds")
+
+	claims, err := authSvc.ValidateToken(oldJWT)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "test@example.com", claims.Email)
+}
+
+func TestAdminSecretsRotateRejectsNegativeOverlap(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServerWithAuth(t, "testpass")
+	token := adminLogin(t, srv)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/secrets/rotate", strings.NewReader(`{"overlapSeconds":-1}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	srv.Router().ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "overlapSeconds must be non-negative")
+}
+
 func TestAdminSecretsRotateRequiresAuth(t *testing.T) {
 	t.Parallel()
 	srv, _ := newTestServerWithAuth(t, "testpass")
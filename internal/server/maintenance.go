@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultMaintenanceRetryAfter is the Retry-After (seconds) sent while in
+// maintenance mode when the toggle request doesn't specify one.
+const defaultMaintenanceRetryAfter = 300
+
+// maintenanceState is the maintenance mode toggle, persisted in _ayb_maintenance.
+type maintenanceState struct {
+	Enabled    bool      `json:"enabled"`
+	AllowReads bool      `json:"allow_reads"` // let GETs on collections through
+	Message    string    `json:"message"`
+	RetryAfter int       `json:"retry_after"` // seconds
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// maintenanceStore abstracts maintenance state persistence for testability.
+type maintenanceStore interface {
+	LoadMaintenance(ctx context.Context) (maintenanceState, error)
+	SaveMaintenance(ctx context.Context, st maintenanceState) error
+}
+
+// pgMaintenanceStore implements maintenanceStore using a PostgreSQL connection pool.
+type pgMaintenanceStore struct {
+	pool *pgxpool.Pool
+}
+
+func (s *pgMaintenanceStore) LoadMaintenance(ctx context.Context) (maintenanceState, error) {
+	var st maintenanceState
+	err := s.pool.QueryRow(ctx,
+		`SELECT enabled, allow_reads, message, retry_after, updated_at FROM _ayb_maintenance`,
+	).Scan(&st.Enabled, &st.AllowReads, &st.Message, &st.RetryAfter, &st.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return maintenanceState{}, nil
+	}
+	return st, err
+}
+
+func (s *pgMaintenanceStore) SaveMaintenance(ctx context.Context, st maintenanceState) error {
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO _ayb_maintenance (id, enabled, allow_reads, message, retry_after, updated_at)
+		 VALUES (TRUE, $1, $2, $3, $4, $5)
+		 ON CONFLICT (id) DO UPDATE
+		 SET enabled = $1, allow_reads = $2, message = $3, retry_after = $4, updated_at = $5`,
+		st.Enabled, st.AllowReads, st.Message, st.RetryAfter, st.UpdatedAt,
+	)
+	return err
+}
+
+// LoadMaintenance restores the persisted maintenance state. Call once at
+// startup; without a database the server starts out of maintenance mode.
+func (s *Server) LoadMaintenance(ctx context.Context) error {
+	if s.maintStore == nil {
+		return nil
+	}
+	st, err := s.maintStore.LoadMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+	s.setMaintenance(st)
+	if st.Enabled {
+		s.logger.Warn("server is in maintenance mode", "allow_reads", st.AllowReads)
+	}
+	return nil
+}
+
+func (s *Server) getMaintenance() maintenanceState {
+	s.maintMu.RLock()
+	defer s.maintMu.RUnlock()
+	return s.maint
+}
+
+func (s *Server) setMaintenance(st maintenanceState) {
+	s.maintMu.Lock()
+	s.maint = st
+	s.maintMu.Unlock()
+}
+
+// maintenanceGate returns 503 for the public data API and auth writes while
+// maintenance mode is on. Admin, health, and everything else stay available.
+func (s *Server) maintenanceGate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := s.getMaintenance()
+		if !st.Enabled || !maintenanceBlocks(st, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		msg := st.Message
+		if msg == "" {
+			msg = "service is under maintenance"
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(st.RetryAfter))
+		httputil.WriteErrorBody(w, http.StatusServiceUnavailable, httputil.ErrorBody{Code: httputil.CodeMaintenance, Message: msg})
+	})
+}
+
+// maintenanceBlocks reports whether r is gated by maintenance mode.
+func maintenanceBlocks(st maintenanceState, r *http.Request) bool {
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/collections/"):
+		return !(read && st.AllowReads)
+	case strings.HasPrefix(path, "/api/rpc/"):
+		return true
+	case strings.HasPrefix(path, "/api/auth/"):
+		return !read
+	}
+	return false
+}
+
+func (s *Server) handleAdminMaintenanceGet(w http.ResponseWriter, r *http.Request) {
+	httputil.WriteJSON(w, http.StatusOK, s.getMaintenance())
+}
+
+type maintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	AllowReads bool   `json:"allow_reads"`
+	Message    string `json:"message"`
+	RetryAfter *int   `json:"retry_after"`
+}
+
+// state returns the maintenance state req asks for.
+func (req maintenanceRequest) state() (maintenanceState, error) {
+	retryAfter := defaultMaintenanceRetryAfter
+	if req.RetryAfter != nil {
+		if *req.RetryAfter < 1 {
+			return maintenanceState{}, errors.New("retry_after must be at least 1 second")
+		}
+		retryAfter = *req.RetryAfter
+	}
+	return maintenanceState{
+		Enabled:    req.Enabled,
+		AllowReads: req.AllowReads,
+		Message:    strings.TrimSpace(req.Message),
+		RetryAfter: retryAfter,
+		UpdatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// saveMaintenance persists st and makes it the current maintenance state.
+func (s *Server) saveMaintenance(ctx context.Context, st maintenanceState) error {
+	if s.maintStore != nil {
+		if err := s.maintStore.SaveMaintenance(ctx, st); err != nil {
+			return err
+		}
+	}
+	s.setMaintenance(st)
+	s.logger.Warn("admin toggled maintenance mode", "enabled", st.Enabled, "allow_reads", st.AllowReads)
+	return nil
+}
+
+func (s *Server) handleAdminMaintenanceSet(w http.ResponseWriter, r *http.Request) {
+	var req maintenanceRequest
+	if !httputil.DecodeJSON(w, r, &req) {
+		return
+	}
+	st, err := req.state()
+	if err != nil {
+		httputil.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.saveMaintenance(r.Context(), st); err != nil {
+		s.logger.Error("maintenance save error", "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to save maintenance state")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, st)
+}
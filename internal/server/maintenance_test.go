@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+type fakeMaintenanceStore struct {
+	saved maintenanceState
+	saves int
+}
+
+func (f *fakeMaintenanceStore) LoadMaintenance(context.Context) (maintenanceState, error) {
+	return f.saved, nil
+}
+
+func (f *fakeMaintenanceStore) SaveMaintenance(_ context.Context, st maintenanceState) error {
+	f.saved = st
+	f.saves++
+	return nil
+}
+
+func newMaintenanceTestServer(t *testing.T, store *fakeMaintenanceStore) (*Server, string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	logger := testutil.DiscardLogger()
+	s := New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	s.maintStore = store
+	testutil.NoError(t, s.LoadMaintenance(context.Background()))
+	return s, s.adminAuth.token()
+}
+
+func doMaintenanceRequest(s *Server, token, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	s.Router().ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminMaintenanceToggle(t *testing.T) {
+	t.Parallel()
+	store := &fakeMaintenanceStore{}
+	s, token := newMaintenanceTestServer(t, store)
+
+	w := doMaintenanceRequest(s, "", http.MethodPost, "/api/admin/maintenance/", `{"enabled":true}`)
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/maintenance/",
+		`{"enabled":true,"message":"upgrading database","retry_after":120}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 1, store.saves)
+	testutil.True(t, store.saved.Enabled, "state should be persisted")
+	testutil.Equal(t, 120, store.saved.RetryAfter)
+
+	w = doMaintenanceRequest(s, token, http.MethodGet, "/api/admin/maintenance/", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var got maintenanceState
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	testutil.True(t, got.Enabled, "GET should report maintenance enabled")
+	testutil.Equal(t, "upgrading database", got.Message)
+
+	w = doMaintenanceRequest(s, token, http.MethodGet, "/health", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), `"maintenance":true`)
+
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/maintenance/", `{"enabled":false}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.False(t, s.getMaintenance().Enabled, "maintenance should be disabled")
+	testutil.Equal(t, defaultMaintenanceRetryAfter, store.saved.RetryAfter)
+}
+
+func TestAdminMaintenanceRejectsBadRetryAfter(t *testing.T) {
+	t.Parallel()
+	store := &fakeMaintenanceStore{}
+	s, token := newMaintenanceTestServer(t, store)
+
+	w := doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/maintenance/", `{"enabled":true,"retry_after":0}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Equal(t, 0, store.saves)
+}
+
+func TestMaintenanceGate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		allowReads bool
+		method     string
+		path       string
+		blocked    bool
+	}{
+		{"collection read", false, http.MethodGet, "/api/collections/posts/", true},
+		{"collection read with allow_reads", true, http.MethodGet, "/api/collections/posts/", false},
+		{"collection write with allow_reads", true, http.MethodPost, "/api/collections/posts/", true},
+		{"rpc", true, http.MethodPost, "/api/rpc/do_thing", true},
+		{"auth write", false, http.MethodPost, "/api/auth/login", true},
+		{"auth read", false, http.MethodGet, "/api/auth/me", false},
+		{"admin", false, http.MethodGet, "/api/admin/status", false},
+		{"health", false, http.MethodGet, "/health", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &fakeMaintenanceStore{saved: maintenanceState{
+				Enabled: true, AllowReads: tt.allowReads, RetryAfter: 60,
+			}}
+			// State is restored from the store, as on restart.
+			s, _ := newMaintenanceTestServer(t, store)
+
+			w := doMaintenanceRequest(s, "", tt.method, tt.path, "{}")
+			if tt.blocked {
+				testutil.Equal(t, http.StatusServiceUnavailable, w.Code)
+				testutil.Equal(t, "60", w.Header().Get("Retry-After"))
+				testutil.Contains(t, w.Body.String(), "service is under maintenance")
+				testutil.Contains(t, w.Body.String(), `"code":"maintenance"`)
+			} else {
+				testutil.True(t, w.Code != http.StatusServiceUnavailable, "request should not be gated")
+			}
+		})
+	}
+}
+
+func TestMaintenanceGateDisabled(t *testing.T) {
+	t.Parallel()
+	s, _ := newMaintenanceTestServer(t, &fakeMaintenanceStore{})
+
+	w := doMaintenanceRequest(s, "", http.MethodPost, "/api/auth/login", "{}")
+	testutil.True(t, w.Code != http.StatusServiceUnavailable, "auth should not be gated")
+	testutil.Equal(t, "", w.Header().Get("Retry-After"))
+}
@@ -218,7 +218,8 @@ type smsSendInput struct {
 	Body  string
 }
 
-// validateSMSSendBody decodes the JSON request body and validates phone + body.
+// validateSMSSendBody decodes the JSON request body, limited by the caller
+// with httputil.LimitBody, and validates phone + body.
 // Returns (input, httpStatus, errorMessage). Status is 0 on success.
 func (s *Server) validateSMSSendBody(r *http.Request) (*smsSendInput, int, string) {
 	var body struct {
@@ -226,6 +227,9 @@ func (s *Server) validateSMSSendBody(r *http.Request) (*smsSendInput, int, strin
 		Body string `json:"body"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if httputil.IsBodyTooLarge(err) {
+			return nil, http.StatusRequestEntityTooLarge, "request body too large"
+		}
 		return nil, http.StatusBadRequest, "invalid request body"
 	}
 	if body.To == "" {
@@ -235,7 +239,7 @@ func (s *Server) validateSMSSendBody(r *http.Request) (*smsSendInput, int, strin
 	if err != nil {
 		return nil, http.StatusBadRequest, "invalid phone number"
 	}
-	if !sms.IsAllowedCountry(phone, s.smsAllowedCountries) {
+	if !sms.IsAllowedCountry(phone, s.smsAllowedCountries) || sms.IsBlockedCountry(phone, s.smsBlockedCountries) {
 		return nil, http.StatusBadRequest, "phone number country not allowed"
 	}
 	if body.Body == "" {
@@ -267,6 +271,9 @@ func (s *Server) handleMessagingSMSSend(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !httputil.LimitBody(w, r) {
+		return
+	}
 	input, status, errMsg := s.validateSMSSendBody(r)
 	if status != 0 {
 		httputil.WriteError(w, status, errMsg)
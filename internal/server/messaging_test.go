@@ -443,6 +443,20 @@ func TestMessagingSMSSend_CountryBlocked(t *testing.T) {
 	testutil.Contains(t, w.Body.String(), "phone number country not allowed")
 }
 
+func TestMessagingSMSSend_CountryBlocklisted(t *testing.T) {
+	t.Parallel()
+	srv := newMessagingTestServer(t, func(s *Server) {
+		s.smsBlockedCountries = []string{"US"} // everywhere except US
+	})
+
+	w := httptest.NewRecorder()
+	req := sendReq(t, `{"to":"+12025551234","body":"Hello"}`, validClaims())
+	srv.handleMessagingSMSSend(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "phone number country not allowed")
+}
+
 func TestMessagingSMSSend_ProviderError(t *testing.T) {
 	t.Parallel()
 	store := &fakeMsgStore{}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/go-chi/chi/v5"
+)
+
+// allowOrder is the order methods are listed in an Allow header.
+var allowOrder = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// methodsMiddleware serves HEAD requests with the route's GET handler, whose
+// body net/http discards, and completes the 405 chi sends for a known route
+// that doesn't serve the method: OPTIONS is answered with 204, and other
+// methods with a JSON error. Both carry the route's methods in Allow. CORS
+// preflights are answered earlier by corsMiddleware.
+func methodsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				rctx.RouteMethod = http.MethodGet
+			}
+		}
+		next.ServeHTTP(&allowWriter{ResponseWriter: w, method: r.Method}, r)
+	})
+}
+
+// allowHeader builds an Allow header value from the methods chi found for a
+// route: HEAD wherever GET is served, and OPTIONS always.
+func allowHeader(methods []string) string {
+	allowed := make([]string, 0, len(allowOrder)+1)
+	for _, m := range allowOrder {
+		if slices.Contains(methods, m) || m == http.MethodHead && slices.Contains(methods, http.MethodGet) {
+			allowed = append(allowed, m)
+		}
+	}
+	return strings.Join(append(allowed, http.MethodOptions), ", ")
+}
+
+// allowWriter rewrites chi's default 405 response, which lists the route's
+// methods as separate Allow headers and has no body. A 405 a handler writes
+// itself carries a Content-Type and passes through unchanged.
+type allowWriter struct {
+	http.ResponseWriter
+	method    string
+	rewritten bool
+}
+
+func (w *allowWriter) WriteHeader(code int) {
+	h := w.Header()
+	if code != http.StatusMethodNotAllowed || h.Get("Content-Type") != "" || w.rewritten {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.rewritten = true
+	h.Set("Allow", allowHeader(h.Values("Allow")))
+	if w.method == http.MethodOptions {
+		w.ResponseWriter.WriteHeader(http.StatusNoContent)
+		return
+	}
+	httputil.WriteError(w.ResponseWriter, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (w *allowWriter) Write(b []byte) (int, error) {
+	if w.rewritten {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the wrapper.
+func (w *allowWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *allowWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,115 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newCollectionsTestServer returns a server with the collection routes
+// mounted. The pool is never connected; these tests don't reach a handler
+// that queries.
+func newCollectionsTestServer(t *testing.T) *server.Server {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Jobs.Enabled = true // no legacy webhook pruner goroutine
+	logger := testutil.DiscardLogger()
+	return server.New(cfg, logger, schema.NewCacheHolder(nil, logger), &pgxpool.Pool{}, nil, nil)
+}
+
+func TestOptionsListsRouteMethods(t *testing.T) {
+	t.Parallel()
+	srv := newCollectionsTestServer(t)
+
+	for _, tc := range []struct {
+		path, allow string
+	}{
+		{"/api/collections/posts/", "GET, HEAD, POST, PATCH, DELETE, OPTIONS"},
+		{"/api/collections/posts/42", "GET, HEAD, PATCH, DELETE, OPTIONS"},
+		{"/api/rpc/do_thing", "POST, OPTIONS"},
+		{"/health", "GET, HEAD, OPTIONS"},
+	} {
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodOptions, tc.path, nil))
+		testutil.Equal(t, http.StatusNoContent, w.Code)
+		testutil.Equal(t, tc.allow, w.Header().Get("Allow"))
+	}
+}
+
+func TestOptionsUnknownRoute(t *testing.T) {
+	t.Parallel()
+	srv := newCollectionsTestServer(t)
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/no/such/route", nil))
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+	testutil.Equal(t, "", w.Header().Get("Allow"))
+}
+
+func TestMethodNotAllowedOnCollectionRoute(t *testing.T) {
+	t.Parallel()
+	srv := newCollectionsTestServer(t)
+
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/collections/posts/42", nil))
+	testutil.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, PATCH, DELETE, OPTIONS", w.Header().Get("Allow"))
+
+	var resp httputil.ErrorResponse
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	testutil.Equal(t, httputil.CodeMethodNotAllowed, resp.Error.Code)
+	testutil.Equal(t, "method not allowed", resp.Error.Message)
+
+	w = httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/collections/posts/", nil))
+	testutil.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, POST, PATCH, DELETE, OPTIONS", w.Header().Get("Allow"))
+}
+
+func TestHeadServedByGetHandler(t *testing.T) {
+	t.Parallel()
+	logger := testutil.DiscardLogger()
+	srv := server.New(config.Default(), logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+
+	get := httptest.NewRecorder()
+	srv.Router().ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/health", nil))
+	head := httptest.NewRecorder()
+	srv.Router().ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/health", nil))
+
+	testutil.Equal(t, http.StatusOK, head.Code)
+	testutil.Equal(t, get.Header().Get("Content-Type"), head.Header().Get("Content-Type"))
+}
+
+func TestMethodNotAllowedOnServerRoute(t *testing.T) {
+	t.Parallel()
+	srv := newCollectionsTestServer(t)
+
+	// POST /health is answered by chi's 405, rewritten with a JSON body.
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/health", nil))
+	testutil.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	testutil.Equal(t, "GET, HEAD, OPTIONS", w.Header().Get("Allow"))
+	testutil.Equal(t, "application/json", w.Header().Get("Content-Type"))
+}
+
+func TestCORSPreflightStillAnsweredByCORS(t *testing.T) {
+	t.Parallel()
+	srv := newCollectionsTestServer(t)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/collections/posts/", nil)
+	req.Header.Set("Origin", "http://any-origin.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -3,33 +3,65 @@ package server
 import (
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"mime"
 	"net/http"
+	"net/netip"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/ui"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// requestLogger returns middleware that logs each request as structured JSON.
-func requestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+// accessLogger returns middleware that writes one structured access log line
+// per request through logger, so it follows logging.format. The path is the
+// matched route pattern rather than the raw URL, keeping record IDs out of
+// the log. It must run after clientIPMiddleware and middleware.RequestID.
+func accessLogger(logger *slog.Logger, cfg config.LoggingConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		if !cfg.AccessLog {
+			return next
+		}
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.AccessLogHealthChecks && r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			start := time.Now()
+			ctx, entry := httputil.WithAccessLogEntry(r.Context())
+			r = r.WithContext(ctx)
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
 			defer func() {
-				logger.Info("request",
-					"method", r.Method,
-					"path", r.URL.Path,
-					"status", ww.Status(),
-					"duration_ms", time.Since(start).Milliseconds(),
-					"bytes", ww.BytesWritten(),
-					"request_id", middleware.GetReqID(r.Context()),
-					"remote", r.RemoteAddr,
-				)
+				status := ww.Status()
+				if status == 0 {
+					status = http.StatusOK // nothing written; net/http sends 200
+				}
+				if status < 500 && !sampleAccessLog(cfg.AccessLogSampleRate) {
+					return
+				}
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("path", routePattern(r)),
+					slog.Int("status", status),
+					slog.Int("bytes", ww.BytesWritten()),
+					slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+					slog.String("client_ip", r.RemoteAddr),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				}
+				if subject := entry.Subject(); subject != "" {
+					attrs = append(attrs, slog.String("subject", subject))
+				}
+				logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
 			}()
 
 			next.ServeHTTP(ww, r)
@@ -37,6 +69,49 @@ func requestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// routePattern returns the chi route pattern r matched, such as
+// "/api/collections/{table}/{id}", or the raw path if no route matched.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// sampleAccessLog reports whether to log a request at the given sample rate.
+func sampleAccessLog(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// clientIPMiddleware rewrites r.RemoteAddr to the originating client IP,
+// honouring forwarding headers only from trusted proxies, so rate limiters and
+// logs downstream see the real client rather than the proxy.
+func clientIPMiddleware(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.RemoteAddr = httputil.ClientIP(r, trusted)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ipFilterMiddleware rejects clients outside a non-empty allowlist or inside
+// the blocklist with 403. It must run after clientIPMiddleware.
+func ipFilterMiddleware(allow, block []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := httputil.RemoteIP(r)
+			if (len(allow) > 0 && !httputil.ContainsIP(allow, ip)) || httputil.ContainsIP(block, ip) {
+				httputil.WriteError(w, http.StatusForbidden, "access denied")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // staticSPAHandler serves the embedded admin SPA with index.html fallback
 // for client-side routing support. Files are served directly from the
 // embedded FS to avoid http.FileServer's index.html redirect behavior.
@@ -88,35 +163,76 @@ func serveEmbeddedFile(w http.ResponseWriter, path string, mustExist bool) bool
 	return true
 }
 
-// corsMiddleware returns middleware that sets CORS headers.
+// corsAllowedHeaders and corsExposedHeaders are the request and response
+// headers the API itself uses; server.cors_allowed_headers and
+// server.cors_exposed_headers add to them.
+var (
+	corsAllowedHeaders = []string{"Content-Type", "Authorization", "X-Request-Id", "Idempotency-Key", "X-CSRF-Token", "Prefer"}
+	corsExposedHeaders = []string{"X-Total-Count", "Idempotent-Replayed", "Preference-Applied"}
+)
+
+// corsPolicy is the CORS configuration corsMiddleware applies, precomputed
+// from [server] settings so it can be swapped when they change at runtime.
+type corsPolicy struct {
+	wildcard         bool
+	origins          map[string]struct{}
+	allowCredentials bool
+	allowHeaders     string
+	exposeHeaders    string
+	maxAge           string
+}
+
+// newCORSPolicy builds the CORS policy for cfg.
 // Per the spec, Access-Control-Allow-Origin must be either "*" or a single
 // origin. When multiple origins are configured, the middleware echoes back
 // only the matching origin and adds Vary: Origin so caches key correctly.
-func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
-	wildcard := len(allowedOrigins) == 1 && allowedOrigins[0] == "*"
-	originSet := make(map[string]struct{}, len(allowedOrigins))
+// With server.cors_allow_credentials or auth cookie mode a matched origin may
+// also send credentials; browsers never send them to a "*" origin, and
+// config validation rejects "*" with cors_allow_credentials.
+func newCORSPolicy(cfg config.ServerConfig, cookieMode bool) *corsPolicy {
+	allowedOrigins := cfg.CORSAllowedOrigins
+	origins := make(map[string]struct{}, len(allowedOrigins))
 	for _, o := range allowedOrigins {
-		originSet[o] = struct{}{}
+		origins[o] = struct{}{}
+	}
+	return &corsPolicy{
+		wildcard:         len(allowedOrigins) == 1 && allowedOrigins[0] == "*",
+		origins:          origins,
+		allowCredentials: cfg.CORSAllowCredentials || cookieMode,
+		allowHeaders:     strings.Join(append(slices.Clone(corsAllowedHeaders), cfg.CORSAllowedHeaders...), ", "),
+		exposeHeaders:    strings.Join(append(slices.Clone(corsExposedHeaders), cfg.CORSExposedHeaders...), ", "),
+		maxAge:           strconv.Itoa(cfg.CORSMaxAge),
 	}
+}
 
+// corsMiddleware returns middleware that sets CORS headers from the current
+// policy.
+func corsMiddleware(policy *atomic.Pointer[corsPolicy]) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := policy.Load()
 			origin := r.Header.Get("Origin")
 
-			if wildcard {
+			if p.wildcard {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
 			} else if origin != "" {
-				if _, ok := originSet[origin]; ok {
+				if _, ok := p.origins[origin]; ok {
 					w.Header().Set("Access-Control-Allow-Origin", origin)
 					w.Header().Add("Vary", "Origin")
+					if p.allowCredentials {
+						w.Header().Set("Access-Control-Allow-Credentials", "true")
+					}
 				}
 			}
 
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-Id")
-			w.Header().Set("Access-Control-Max-Age", "86400")
+			w.Header().Set("Access-Control-Allow-Headers", p.allowHeaders)
+			w.Header().Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			w.Header().Set("Access-Control-Max-Age", p.maxAge)
 
-			if r.Method == http.MethodOptions {
+			// Browser preflights are answered here. Other OPTIONS requests
+			// reach methodsMiddleware, which reports the route's methods.
+			if r.Method == http.MethodOptions && origin != "" {
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
@@ -125,3 +241,14 @@ func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// bodyLimitMiddleware records the current server.body_limit in the request
+// context, where httputil.LimitBody applies it.
+func bodyLimitMiddleware(limit *atomic.Int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(httputil.WithBodyLimit(r.Context(), limit.Load()))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package server_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,6 +42,7 @@ func TestCORSHeaders(t *testing.T) {
 	testutil.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "DELETE")
 	testutil.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Content-Type")
 	testutil.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	testutil.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Total-Count")
 }
 
 func TestCORSMultiOriginSecondMatch(t *testing.T) {
@@ -58,6 +62,77 @@ func TestCORSMultiOriginSecondMatch(t *testing.T) {
 	testutil.Contains(t, w.Header().Get("Vary"), "Origin")
 }
 
+func TestCORSCredentialsInCookieMode(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ch := schema.NewCacheHolder(nil, logger)
+	for _, cookies := range []bool{false, true} {
+		cfg := config.Default()
+		cfg.Server.CORSAllowedOrigins = []string{"http://example.com"}
+		cfg.Auth.Cookies.Enabled = cookies
+		srv := server.New(cfg, logger, ch, nil, nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", "http://example.com")
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		want := ""
+		if cookies {
+			want = "true"
+		}
+		testutil.Equal(t, want, w.Header().Get("Access-Control-Allow-Credentials"))
+		testutil.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-CSRF-Token")
+	}
+}
+
+func TestCORSAllowCredentials(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.Server.CORSAllowedOrigins = []string{"http://example.com"}
+	cfg.Server.CORSAllowCredentials = true
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ch := schema.NewCacheHolder(nil, logger)
+	srv := server.New(cfg, logger, ch, nil, nil, nil)
+
+	for _, tt := range []struct{ origin, wantOrigin, wantCredentials string }{
+		{"http://example.com", "http://example.com", "true"},
+		{"http://evil.com", "", ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", tt.origin)
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+
+		testutil.Equal(t, tt.wantOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+		testutil.Equal(t, tt.wantCredentials, w.Header().Get("Access-Control-Allow-Credentials"))
+	}
+}
+
+func TestCORSCustomHeadersAndMaxAge(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.Server.CORSAllowedOrigins = []string{"http://example.com"}
+	cfg.Server.CORSAllowedHeaders = []string{"X-Tenant-Id"}
+	cfg.Server.CORSExposedHeaders = []string{"X-RateLimit-Remaining", "ETag"}
+	cfg.Server.CORSMaxAge = 600
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ch := schema.NewCacheHolder(nil, logger)
+	srv := server.New(cfg, logger, ch, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/schema", nil)
+	req.Header.Set("Origin", "http://example.com")
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.Equal(t, "Content-Type, Authorization, X-Request-Id, Idempotency-Key, X-CSRF-Token, Prefer, X-Tenant-Id",
+		w.Header().Get("Access-Control-Allow-Headers"))
+	testutil.Equal(t, "X-Total-Count, Idempotent-Replayed, Preference-Applied, X-RateLimit-Remaining, ETag",
+		w.Header().Get("Access-Control-Expose-Headers"))
+	testutil.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
 func TestCORSNonMatchingOrigin(t *testing.T) {
 	t.Parallel()
 	cfg := config.Default()
@@ -313,3 +388,202 @@ func TestStartWithReadySignalsReady(t *testing.T) {
 		t.Fatalf("server failed to start: %v", err)
 	}
 }
+
+// --- Client IP and IP filter tests ---
+
+func newIPTestServer(t *testing.T, mutate func(*config.Config)) *server.Server {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	cfg.Admin.LoginRateLimit = 1
+	mutate(cfg)
+	testutil.NoError(t, cfg.Validate())
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := server.New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	return srv
+}
+
+func adminLoginAttempt(srv *server.Server, remoteAddr, xff string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/auth", strings.NewReader(`{"password":"wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	if xff != "" {
+		req.Header.Set("X-Forwarded-For", xff)
+	}
+	srv.Router().ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitIgnoresSpoofedXFFFromUntrustedSource(t *testing.T) {
+	t.Parallel()
+	srv := newIPTestServer(t, func(*config.Config) {})
+
+	// A direct client rotating X-Forwarded-For must still share one bucket.
+	testutil.Equal(t, http.StatusUnauthorized, adminLoginAttempt(srv, "203.0.113.1:1111", "198.51.100.1"))
+	testutil.Equal(t, http.StatusTooManyRequests, adminLoginAttempt(srv, "203.0.113.1:2222", "198.51.100.2"))
+}
+
+func TestRateLimitUsesXFFFromTrustedProxy(t *testing.T) {
+	t.Parallel()
+	srv := newIPTestServer(t, func(*config.Config) {})
+
+	// Behind a trusted proxy, each forwarded client gets its own bucket.
+	testutil.Equal(t, http.StatusUnauthorized, adminLoginAttempt(srv, "10.0.0.1:1111", "198.51.100.1"))
+	testutil.Equal(t, http.StatusUnauthorized, adminLoginAttempt(srv, "10.0.0.1:2222", "198.51.100.2"))
+	testutil.Equal(t, http.StatusTooManyRequests, adminLoginAttempt(srv, "10.0.0.1:3333", "198.51.100.2"))
+}
+
+func TestRateLimitEmptyTrustedProxiesIgnoresXFF(t *testing.T) {
+	t.Parallel()
+	srv := newIPTestServer(t, func(cfg *config.Config) { cfg.Server.TrustedProxies = nil })
+
+	testutil.Equal(t, http.StatusUnauthorized, adminLoginAttempt(srv, "10.0.0.1:1111", "198.51.100.1"))
+	testutil.Equal(t, http.StatusTooManyRequests, adminLoginAttempt(srv, "10.0.0.1:2222", "198.51.100.2"))
+}
+
+func TestIPFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		allow      []string
+		block      []string
+		remoteAddr string
+		xff        string
+		want       int
+	}{
+		{"no lists", nil, nil, "203.0.113.1:1234", "", http.StatusOK},
+		{"in allowlist", []string{"203.0.113.0/24"}, nil, "203.0.113.1:1234", "", http.StatusOK},
+		{"outside allowlist", []string{"203.0.113.0/24"}, nil, "198.51.100.1:1234", "", http.StatusForbidden},
+		{"in blocklist", nil, []string{"198.51.100.7"}, "198.51.100.7:1234", "", http.StatusForbidden},
+		{"blocklist carves out of allowlist", []string{"203.0.113.0/24"}, []string{"203.0.113.9"}, "203.0.113.9:1234", "", http.StatusForbidden},
+		{"forwarded client checked behind trusted proxy", nil, []string{"198.51.100.7"}, "10.0.0.1:1234", "198.51.100.7", http.StatusForbidden},
+		{"spoofed XFF cannot bypass allowlist", []string{"203.0.113.0/24"}, nil, "198.51.100.1:1234", "203.0.113.1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := newIPTestServer(t, func(cfg *config.Config) {
+				cfg.Server.IPAllowlist = tt.allow
+				cfg.Server.IPBlocklist = tt.block
+			})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			srv.Router().ServeHTTP(w, req)
+			testutil.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+// --- Access log tests ---
+
+func newAccessLogTestServer(t *testing.T, mutate func(*config.LoggingConfig)) (*server.Server, *bytes.Buffer) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	mutate(&cfg.Logging)
+	testutil.NoError(t, cfg.Validate())
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	return server.New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil), &buf
+}
+
+// accessLogLines returns the access log entries written to buf.
+func accessLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if line == "" || json.Unmarshal([]byte(line), &entry) != nil || entry["msg"] != "request" {
+			continue
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func adminToken(t *testing.T, srv *server.Server) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/auth", strings.NewReader(`{"password":"testpass"}`))
+	srv.Router().ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var login map[string]string
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+	return login["token"]
+}
+
+func serve(srv *server.Server, method, target, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = "203.0.113.9:4321"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	srv.Router().ServeHTTP(w, req)
+	return w
+}
+
+func TestAccessLog(t *testing.T) {
+	t.Parallel()
+	srv, buf := newAccessLogTestServer(t, func(*config.LoggingConfig) {})
+
+	serve(srv, http.MethodGet, "/api/admin/jobs/123", "")
+	lines := accessLogLines(t, buf)
+	testutil.SliceLen(t, lines, 1)
+	entry := lines[0]
+	testutil.Equal(t, "GET", entry["method"].(string))
+	// Rejected before the subrouter resolves {id}; the ID is never logged.
+	testutil.Equal(t, "/api/admin/jobs/*", entry["path"].(string))
+	testutil.Equal(t, 401.0, entry["status"].(float64))
+	testutil.Equal(t, "203.0.113.9", entry["client_ip"].(string))
+	testutil.True(t, entry["bytes"].(float64) > 0, "bytes should be logged")
+	testutil.NotNil(t, entry["duration_ms"])
+	testutil.True(t, entry["request_id"].(string) != "", "request id should be logged")
+	testutil.Nil(t, entry["subject"])
+}
+
+func TestAccessLogAdminSubject(t *testing.T) {
+	t.Parallel()
+	srv, buf := newAccessLogTestServer(t, func(*config.LoggingConfig) {})
+
+	token := adminToken(t, srv)
+	buf.Reset()
+
+	serve(srv, http.MethodGet, "/api/admin/jobs/123", token)
+	lines := accessLogLines(t, buf)
+	testutil.SliceLen(t, lines, 1)
+	testutil.Equal(t, "admin", lines[0]["subject"].(string))
+	// The route pattern is logged, not the record ID.
+	testutil.Equal(t, "/api/admin/jobs/{id}", lines[0]["path"].(string))
+}
+
+func TestAccessLogHealthChecksAndSampling(t *testing.T) {
+	t.Parallel()
+	srv, buf := newAccessLogTestServer(t, func(c *config.LoggingConfig) {
+		c.AccessLogHealthChecks = false
+		c.AccessLogSampleRate = 0
+	})
+
+	token := adminToken(t, srv)
+	buf.Reset()
+
+	serve(srv, http.MethodGet, "/health", "")
+	serve(srv, http.MethodGet, "/api/admin/jobs/1", "")    // 401: sampled out
+	serve(srv, http.MethodGet, "/api/admin/jobs/1", token) // 503: server errors are always logged
+	lines := accessLogLines(t, buf)
+	testutil.SliceLen(t, lines, 1)
+	testutil.Equal(t, 503.0, lines[0]["status"].(float64))
+}
+
+func TestAccessLogDisabled(t *testing.T) {
+	t.Parallel()
+	srv, buf := newAccessLogTestServer(t, func(c *config.LoggingConfig) { c.AccessLog = false })
+
+	serve(srv, http.MethodGet, "/health", "")
+	testutil.SliceLen(t, accessLogLines(t, buf), 0)
+}
@@ -28,6 +28,9 @@ type createOAuthClientRequest struct {
 	ClientType   string   `json:"clientType"`
 	RedirectURIs []string `json:"redirectUris"`
 	Scopes       []string `json:"scopes"`
+	// ServiceAccount creates a confidential client without redirect URIs,
+	// which only authenticates with the client_credentials grant.
+	ServiceAccount bool `json:"serviceAccount"`
 }
 
 type createOAuthClientResponse struct {
@@ -104,7 +107,16 @@ func handleAdminCreateOAuthClient(svc oauthClientManager) http.HandlerFunc {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		if err := auth.ValidateRedirectURIs(req.RedirectURIs); err != nil {
+		if req.ServiceAccount {
+			if req.ClientType != auth.OAuthClientTypeConfidential {
+				httputil.WriteError(w, http.StatusBadRequest, "service accounts must be confidential clients")
+				return
+			}
+			if len(req.RedirectURIs) > 0 {
+				httputil.WriteError(w, http.StatusBadRequest, auth.ErrOAuthServiceAccountRedirectURI.Error())
+				return
+			}
+		} else if err := auth.ValidateRedirectURIs(req.RedirectURIs); err != nil {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -211,9 +223,13 @@ func handleAdminUpdateOAuthClient(svc oauthClientManager) http.HandlerFunc {
 			httputil.WriteError(w, http.StatusBadRequest, "name is required")
 			return
 		}
-		if err := auth.ValidateRedirectURIs(req.RedirectURIs); err != nil {
-			httputil.WriteError(w, http.StatusBadRequest, err.Error())
-			return
+		// Whether redirect URIs may be empty depends on the client, which
+		// UpdateOAuthClient checks.
+		if len(req.RedirectURIs) > 0 {
+			if err := auth.ValidateRedirectURIs(req.RedirectURIs); err != nil {
+				httputil.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 		}
 		if err := auth.ValidateOAuthScopes(req.Scopes); err != nil {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
@@ -230,6 +246,10 @@ func handleAdminUpdateOAuthClient(svc oauthClientManager) http.HandlerFunc {
 				httputil.WriteError(w, http.StatusBadRequest, "oauth client has been revoked")
 				return
 			}
+			if errors.Is(err, auth.ErrOAuthRedirectURIRequired) || errors.Is(err, auth.ErrOAuthServiceAccountRedirectURI) {
+				httputil.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			httputil.WriteError(w, http.StatusInternalServerError, "failed to update oauth client")
 			return
 		}
@@ -33,7 +33,7 @@ func (f *fakeOAuthClientManager) RegisterOAuthClient(_ context.Context, appID, n
 	if err := auth.ValidateClientType(clientType); err != nil {
 		return "", nil, err
 	}
-	if err := auth.ValidateRedirectURIs(redirectURIs); err != nil {
+	if err := auth.ValidateClientRedirectURIs(clientType, redirectURIs); err != nil {
 		return "", nil, err
 	}
 	if err := auth.ValidateOAuthScopes(scopes); err != nil {
@@ -455,6 +455,57 @@ func TestAdminCreateOAuthClientPublicSuccess(t *testing.T) {
 	testutil.Equal(t, "public", resp.Client.ClientType)
 }
 
+func TestAdminCreateOAuthClientServiceAccountSuccess(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeOAuthClientManager{clients: []auth.OAuthClient{}}
+	handler := handleAdminCreateOAuthClient(mgr)
+
+	body := `{"appId":"00000000-0000-0000-0000-0000000000a1","name":"Nightly Sync","serviceAccount":true,"scopes":["readonly"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/oauth/clients", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusCreated, w.Code)
+
+	var resp createOAuthClientResponse
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	testutil.True(t, resp.ClientSecret != "", "service account should return secret")
+	testutil.Equal(t, "confidential", resp.Client.ClientType)
+	testutil.SliceLen(t, resp.Client.RedirectURIs, 0)
+	testutil.True(t, resp.Client.IsServiceAccount(), "client should be a service account")
+}
+
+func TestAdminCreateOAuthClientServiceAccountRejectsRedirectURIs(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeOAuthClientManager{}
+	handler := handleAdminCreateOAuthClient(mgr)
+
+	body := `{"appId":"00000000-0000-0000-0000-0000000000a1","name":"Nightly Sync","serviceAccount":true,"redirectUris":["https://example.com/callback"],"scopes":["readonly"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/oauth/clients", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "service accounts have no redirect URIs")
+}
+
+func TestAdminCreateOAuthClientServiceAccountMustBeConfidential(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeOAuthClientManager{}
+	handler := handleAdminCreateOAuthClient(mgr)
+
+	body := `{"appId":"00000000-0000-0000-0000-0000000000a1","name":"Nightly Sync","clientType":"public","serviceAccount":true,"scopes":["readonly"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/oauth/clients", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "must be confidential")
+}
+
 func TestAdminCreateOAuthClientDefaultsToConfidential(t *testing.T) {
 	t.Parallel()
 	mgr := &fakeOAuthClientManager{clients: []auth.OAuthClient{}}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// Rate limits used when auth.rate_limit or admin.login_rate_limit is unset.
+const (
+	defaultAuthRateLimit       = 10
+	defaultAdminLoginRateLimit = 20
+)
+
+// authRateLimit returns the auth endpoint requests allowed per minute per IP.
+func authRateLimit(cfg *config.Config) int {
+	if cfg.Auth.RateLimit <= 0 {
+		return defaultAuthRateLimit
+	}
+	return cfg.Auth.RateLimit
+}
+
+// adminLoginRateLimit returns the admin login attempts allowed per minute
+// per IP.
+func adminLoginRateLimit(cfg *config.Config) int {
+	if cfg.Admin.LoginRateLimit <= 0 {
+		return defaultAdminLoginRateLimit
+	}
+	return cfg.Admin.LoginRateLimit
+}
+
+// SetLogLevel sets the level that changes to logging.level are applied to.
+func (s *Server) SetLogLevel(level *slog.LevelVar) {
+	s.logLevel = level
+}
+
+// ApplyRuntimeConfig applies the runtime keys (see config.IsRuntimeKey) that
+// differ between next and the running config, and returns them. Other
+// changes in next are ignored; they need a restart.
+func (s *Server) ApplyRuntimeConfig(next *config.Config) []string {
+	s.runtimeMu.Lock()
+	defer s.runtimeMu.Unlock()
+	return s.applyRuntimeConfig(next)
+}
+
+// applyRuntimeConfig is ApplyRuntimeConfig with s.runtimeMu held.
+func (s *Server) applyRuntimeConfig(next *config.Config) []string {
+	var applied []string
+	for _, key := range config.ChangedKeys(s.cfg, next) {
+		if !config.IsRuntimeKey(key) {
+			continue
+		}
+		config.CopyRuntimeKey(s.cfg, next, key)
+		s.applyRuntimeKey(key)
+		applied = append(applied, key)
+	}
+	return applied
+}
+
+// applyRuntimeKey makes the running server use the value of key in s.cfg.
+func (s *Server) applyRuntimeKey(key string) {
+	switch key {
+	case "logging.level":
+		if s.logLevel != nil {
+			s.logLevel.Set(s.cfg.Logging.SlogLevel())
+		}
+	case "server.cors_allowed_origins":
+		s.cors.Store(newCORSPolicy(s.cfg.Server, s.cfg.Auth.Cookies.Enabled))
+	case "server.body_limit":
+		s.bodyLimit.Store(s.cfg.Server.BodyLimitBytes())
+	case "auth.rate_limit":
+		if s.authRL != nil {
+			s.authRL.SetLimit(authRateLimit(s.cfg))
+		}
+	case "admin.login_rate_limit":
+		s.adminRL.SetLimit(adminLoginRateLimit(s.cfg))
+	case "auth.registration_enabled":
+		if s.authHandler != nil {
+			s.authHandler.SetRegistrationEnabled(s.cfg.Auth.RegistrationEnabled)
+		}
+	case "auth.password_login_enabled":
+		if s.authHandler != nil {
+			s.authHandler.SetPasswordLoginEnabled(s.cfg.Auth.PasswordLoginEnabled)
+		}
+	}
+}
+
+// runtimeResponse is the body returned by GET and PUT /api/admin/runtime.
+type runtimeResponse struct {
+	Settings    map[string]json.RawMessage `json:"settings"`
+	Maintenance maintenanceState           `json:"maintenance"`
+	Applied     []string                   `json:"applied,omitempty"` // PUT only
+}
+
+// runtimeRequest is the body of PUT /api/admin/runtime. Both parts are
+// optional.
+type runtimeRequest struct {
+	Settings    map[string]json.RawMessage `json:"settings"`
+	Maintenance *maintenanceRequest        `json:"maintenance"`
+}
+
+// runtimeSettings returns the current value of each runtime key. The caller
+// holds s.runtimeMu.
+func (s *Server) runtimeSettings() map[string]json.RawMessage {
+	settings := make(map[string]json.RawMessage)
+	for _, key := range config.RuntimeKeys() {
+		// Runtime fields are strings, ints, bools, and string slices.
+		settings[key], _ = json.Marshal(config.RuntimeField(s.cfg, key))
+	}
+	return settings
+}
+
+func (s *Server) handleAdminRuntimeGet(w http.ResponseWriter, r *http.Request) {
+	s.runtimeMu.RLock()
+	settings := s.runtimeSettings()
+	s.runtimeMu.RUnlock()
+	httputil.WriteJSON(w, http.StatusOK, runtimeResponse{Settings: settings, Maintenance: s.getMaintenance()})
+}
+
+// handleAdminRuntimeSet changes runtime settings and maintenance mode.
+// Settings are validated with the rest of the running config, written to
+// the config file, and applied before the response is sent. Keys that need
+// a restart are rejected.
+func (s *Server) handleAdminRuntimeSet(w http.ResponseWriter, r *http.Request) {
+	var req runtimeRequest
+	if !httputil.DecodeJSON(w, r, &req) {
+		return
+	}
+	var maint *maintenanceState
+	if req.Maintenance != nil {
+		st, err := req.Maintenance.state()
+		if err != nil {
+			httputil.WriteError(w, http.StatusBadRequest, "maintenance: "+err.Error())
+			return
+		}
+		maint = &st
+	}
+	if len(req.Settings) > 0 && s.configPath == "" {
+		httputil.WriteError(w, http.StatusServiceUnavailable, "runtime settings are not available")
+		return
+	}
+
+	s.runtimeMu.Lock()
+	defer s.runtimeMu.Unlock()
+
+	next, err := s.runtimeConfigWith(req.Settings)
+	if err != nil {
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, err.Error(),
+			"https://allyourbase.io/guide/configuration")
+		return
+	}
+	if len(req.Settings) > 0 {
+		values := make(map[string]string, len(req.Settings))
+		for key := range req.Settings {
+			v, _ := config.GetValue(next, key)
+			values[key] = fmt.Sprint(v)
+		}
+		if err := config.SetValues(s.configPath, values); err != nil {
+			s.logger.Error("runtime config write error", "path", s.configPath, "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to write config file")
+			return
+		}
+	}
+	applied := s.applyRuntimeConfig(next)
+	if len(applied) > 0 {
+		s.logger.Info("admin changed runtime config", "keys", strings.Join(applied, ", "))
+	}
+
+	if maint != nil {
+		if err := s.saveMaintenance(r.Context(), *maint); err != nil {
+			s.logger.Error("maintenance save error", "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to save maintenance state")
+			return
+		}
+	}
+
+	httputil.WriteJSON(w, http.StatusOK, runtimeResponse{
+		Settings:    s.runtimeSettings(),
+		Maintenance: s.getMaintenance(),
+		Applied:     applied,
+	})
+}
+
+// runtimeConfigWith returns a copy of the running config with the runtime
+// settings in update, after validating it. The caller holds s.runtimeMu.
+func (s *Server) runtimeConfigWith(update map[string]json.RawMessage) (*config.Config, error) {
+	next := *s.cfg
+	for key, raw := range update {
+		if !config.IsValidKey(key) {
+			return nil, fmt.Errorf("unknown config key %q", key)
+		}
+		if !config.IsRuntimeKey(key) {
+			return nil, fmt.Errorf("%s can't be changed at runtime; set it in ayb.toml and restart", key)
+		}
+		field := config.RuntimeField(&next, key)
+		// The copy shares slices with s.cfg, which decoding would reuse.
+		reflect.ValueOf(field).Elem().SetZero()
+		if err := json.Unmarshal(raw, field); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %v", key, err)
+		}
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
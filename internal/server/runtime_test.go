@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func newRuntimeTestServer(t *testing.T) (*Server, string, string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	logger := testutil.DiscardLogger()
+	s := New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, nil, nil)
+	path := filepath.Join(t.TempDir(), "ayb.toml")
+	s.SetConfigPath(path)
+	return s, s.adminAuth.token(), path
+}
+
+func TestAdminRuntimeGet(t *testing.T) {
+	t.Parallel()
+	s, token, _ := newRuntimeTestServer(t)
+
+	w := doMaintenanceRequest(s, "", http.MethodGet, "/api/admin/runtime/", "")
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doMaintenanceRequest(s, token, http.MethodGet, "/api/admin/runtime/", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var got struct {
+		Settings    map[string]any   `json:"settings"`
+		Maintenance maintenanceState `json:"maintenance"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	testutil.Equal(t, len(config.RuntimeKeys()), len(got.Settings))
+	testutil.Equal(t, "info", got.Settings["logging.level"].(string))
+	testutil.Equal(t, float64(20), got.Settings["admin.login_rate_limit"].(float64))
+	testutil.False(t, got.Maintenance.Enabled, "maintenance should be off")
+}
+
+func TestAdminRuntimeRateLimitAppliesToNextRequest(t *testing.T) {
+	t.Parallel()
+	s, token, path := newRuntimeTestServer(t)
+
+	login := func() *httptest.ResponseRecorder {
+		return doMaintenanceRequest(s, "", http.MethodPost, "/api/admin/auth", `{"password":"wrong"}`)
+	}
+	w := login()
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+	testutil.Equal(t, "20", w.Header().Get("X-RateLimit-Limit"))
+
+	w = doMaintenanceRequest(s, token, http.MethodPut, "/api/admin/runtime/",
+		`{"settings":{"admin.login_rate_limit":2}}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), `"applied":["admin.login_rate_limit"]`)
+
+	// The attempt made before the change still counts against the new limit.
+	w = login()
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+	testutil.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	w = login()
+	testutil.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	saved, err := config.Load(path, nil)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, saved.Admin.LoginRateLimit)
+}
+
+func TestAdminRuntimeCORSAndBodyLimit(t *testing.T) {
+	t.Parallel()
+	s, token, path := newRuntimeTestServer(t)
+
+	w := doMaintenanceRequest(s, token, http.MethodPut, "/api/admin/runtime/",
+		`{"settings":{"server.cors_allowed_origins":["https://app.example"],"server.body_limit":"1KB"}}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Origin", "https://app.example")
+	w = httptest.NewRecorder()
+	s.Router().ServeHTTP(w, req)
+	testutil.Equal(t, "https://app.example", w.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Origin", "https://other.example")
+	w = httptest.NewRecorder()
+	s.Router().ServeHTTP(w, req)
+	testutil.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	big := `{"enabled":false,"message":"` + strings.Repeat("a", 2048) + `"}`
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/maintenance/", big)
+	testutil.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	saved, err := config.Load(path, nil)
+	testutil.NoError(t, err)
+	testutil.Equal(t, "https://app.example", strings.Join(saved.Server.CORSAllowedOrigins, ","))
+	testutil.Equal(t, "1KB", saved.Server.BodyLimit)
+}
+
+func TestAdminRuntimeMaintenance(t *testing.T) {
+	t.Parallel()
+	s, token, path := newRuntimeTestServer(t)
+	store := &fakeMaintenanceStore{}
+	s.maintStore = store
+
+	w := doMaintenanceRequest(s, token, http.MethodPut, "/api/admin/runtime/",
+		`{"maintenance":{"enabled":true,"message":"upgrading"}}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, 1, store.saves)
+	testutil.True(t, s.getMaintenance().Enabled, "maintenance should be on")
+
+	// Maintenance alone doesn't touch the config file.
+	_, err := os.Stat(path)
+	testutil.True(t, os.IsNotExist(err), "config file should not be written")
+
+	w = doMaintenanceRequest(s, token, http.MethodPut, "/api/admin/runtime/",
+		`{"maintenance":{"enabled":true,"retry_after":0}}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "retry_after")
+}
+
+func TestAdminRuntimeRejectsInvalidChanges(t *testing.T) {
+	t.Parallel()
+	s, token, path := newRuntimeTestServer(t)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"restart key", `{"settings":{"server.port":9000}}`, "server.port can't be changed at runtime"},
+		{"unknown key", `{"settings":{"server.nope":1}}`, `unknown config key \"server.nope\"`},
+		{"wrong type", `{"settings":{"auth.rate_limit":"lots"}}`, "invalid value for auth.rate_limit"},
+		{"invalid value", `{"settings":{"logging.level":"loud"}}`, "logging.level must be one of"},
+		{"invalid size", `{"settings":{"server.body_limit":"big"}}`, "server.body_limit must be a size"},
+		{"runtime and restart keys", `{"settings":{"auth.rate_limit":5,"database.url":"postgres://x"}}`, "database.url can't be changed at runtime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doMaintenanceRequest(s, token, http.MethodPut, "/api/admin/runtime/", tt.body)
+			testutil.Equal(t, http.StatusBadRequest, w.Code)
+			testutil.Contains(t, w.Body.String(), tt.wantErr)
+		})
+	}
+
+	// Nothing was applied or written.
+	testutil.Equal(t, 10, s.cfg.Auth.RateLimit)
+	testutil.Equal(t, "info", s.cfg.Logging.Level)
+	_, err := os.Stat(path)
+	testutil.True(t, os.IsNotExist(err), "config file should not be written")
+}
+
+func TestApplyRuntimeConfig(t *testing.T) {
+	t.Parallel()
+	s, _, _ := newRuntimeTestServer(t)
+
+	next := config.Default()
+	next.Admin.LoginRateLimit = 5
+	next.Server.Port = 9000
+	applied := s.ApplyRuntimeConfig(next)
+	testutil.Equal(t, "admin.login_rate_limit", strings.Join(applied, ","))
+	testutil.Equal(t, 5, s.adminRL.Limit())
+	testutil.Equal(t, 8090, s.cfg.Server.Port)
+
+	// Applying the same config again changes nothing.
+	testutil.SliceLen(t, s.ApplyRuntimeConfig(next), 0)
+}
@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/api"
@@ -14,20 +17,24 @@ import (
 	"github.com/allyourbase/ayb/internal/config"
 	"github.com/allyourbase/ayb/internal/httputil"
 	"github.com/allyourbase/ayb/internal/jobs"
+	"github.com/allyourbase/ayb/internal/migrations"
 	"github.com/allyourbase/ayb/internal/realtime"
 	"github.com/allyourbase/ayb/internal/schema"
 	"github.com/allyourbase/ayb/internal/sms"
 	"github.com/allyourbase/ayb/internal/storage"
+	"github.com/allyourbase/ayb/internal/tenant"
 	"github.com/allyourbase/ayb/internal/webhooks"
 	"github.com/allyourbase/ayb/openapi"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/quic-go/quic-go/http3"
 )
 
 // Server is the main HTTP server for AYB.
 type Server struct {
 	cfg                 *config.Config
+	configPath          string // ayb.toml written by config import; "" = import disabled
 	router              *chi.Mux
 	http                *http.Server
 	logger              *slog.Logger
@@ -35,26 +42,43 @@ type Server struct {
 	pool                *pgxpool.Pool
 	authSvc             *auth.Service     // nil when auth disabled
 	authRL              *auth.RateLimiter // nil when auth disabled
+	authHandler         *auth.Handler     // nil when auth disabled
 	appRL               *auth.AppRateLimiter
+	publicReadTables    []string          // collections readable without a credential
 	adminRL             *auth.RateLimiter // admin login rate limiter
 	hub                 *realtime.Hub
-	webhookDispatcher   webhookDispatcher // nil when pool is nil
-	jobService          *jobs.Service     // nil when jobs disabled or pool is nil
-	matviewSvc          matviewAdmin      // nil when pool is nil
+	webhookDispatcher   webhookDispatcher  // nil when pool is nil
+	jobService          *jobs.Service      // nil when jobs disabled or pool is nil
+	matviewSvc          matviewAdmin       // nil when pool is nil
 	emailTplSvc         emailTemplateAdmin // nil when pool is nil
 	adminMu             sync.RWMutex
 	adminAuth           *adminAuth // nil when admin.password not set
 	startTime           time.Time
-	logBuffer           *LogBuffer   // nil when not using buffered logging
-	smsProvider         sms.Provider // nil when SMS disabled
-	smsProviderName     string       // "twilio", "plivo", etc. — stored in messages for audit
-	smsAllowedCountries []string     // country allowlist from config
-	msgStore            messageStore // nil when pool is nil
+	logBuffer           *LogBuffer       // nil when not using buffered logging
+	smsProvider         sms.Provider     // nil when SMS disabled
+	smsProviderName     string           // "twilio", "plivo", etc. — stored in messages for audit
+	smsAllowedCountries []string         // country allowlist from config
+	smsBlockedCountries []string         // country blocklist from config
+	msgStore            messageStore     // nil when pool is nil
+	maintStore          maintenanceStore // nil when pool is nil
+	maintMu             sync.RWMutex
+	maint               maintenanceState
+	grpc                *api.GRPCServer // nil unless grpc.enabled
+	h3                  *http3.Server   // nil unless server.http3
+	drain               *drainTracker
+	errorStats          *errorStats
+	runtimeMu           sync.RWMutex                // guards runtime keys in cfg (see ApplyRuntimeConfig)
+	cors                *atomic.Pointer[corsPolicy] // swapped when CORS settings change at runtime
+	bodyLimit           *atomic.Int64               // server.body_limit in bytes
+	logLevel            *slog.LevelVar              // nil until SetLogLevel
+	tenants             tenantAdmin                 // nil unless tenants.enabled
+	apiHandler          *api.Handler                // nil when pool is nil
 }
 
 type webhookDispatcher interface {
 	Enqueue(event *realtime.Event)
 	SetDeliveryStore(ds webhooks.DeliveryStore)
+	SetRecordFilter(f webhooks.RecordFilter)
 	StartPruner(interval, retention time.Duration)
 	Close()
 }
@@ -68,11 +92,45 @@ var newWebhookDispatcher = func(store webhooks.WebhookLister, logger *slog.Logge
 func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolder, pool *pgxpool.Pool, authSvc *auth.Service, storageSvc *storage.Service) *Server {
 	r := chi.NewRouter()
 
+	// IP lists were validated by config.Validate.
+	trustedProxies, _ := httputil.ParseCIDRs(cfg.Server.TrustedProxies)
+	ipAllow, _ := httputil.ParseCIDRs(cfg.Server.IPAllowlist)
+	ipBlock, _ := httputil.ParseCIDRs(cfg.Server.IPBlocklist)
+	var errorTranslations httputil.ErrorTranslations
+	if cfg.Server.ErrorTranslations != "" {
+		var err error
+		if errorTranslations, err = httputil.LoadErrorTranslations(cfg.Server.ErrorTranslations); err != nil {
+			logger.Warn("error translations not loaded", "path", cfg.Server.ErrorTranslations, "error", err)
+		}
+	}
+
 	// Global middleware (applies to all routes including admin SPA).
 	r.Use(middleware.RequestID)
-	r.Use(requestLogger(logger))
+	r.Use(clientIPMiddleware(trustedProxies))
+	r.Use(accessLogger(logger, cfg.Logging))
+	errStats := newErrorStats()
+	r.Use(errStats.middleware)
 	r.Use(middleware.Recoverer)
-	r.Use(corsMiddleware(cfg.Server.CORSAllowedOrigins))
+	drain := &drainTracker{}
+	r.Use(drain.middleware)
+	if len(ipAllow) > 0 || len(ipBlock) > 0 {
+		r.Use(ipFilterMiddleware(ipAllow, ipBlock))
+	}
+	cors := new(atomic.Pointer[corsPolicy])
+	cors.Store(newCORSPolicy(cfg.Server, cfg.Auth.Cookies.Enabled))
+	r.Use(corsMiddleware(cors))
+	if cfg.Server.HTTP3 {
+		r.Use(altSvcMiddleware(cfg.TLSPort()))
+	}
+	r.Use(compressMiddleware(cfg.Server))
+	r.Use(localizeErrorsMiddleware(errorTranslations))
+	r.Use(trailingSlashMiddleware(cfg.Server.TrailingSlash))
+	r.Use(methodsMiddleware)
+	r.Use(timeoutMiddleware(newRequestTimeouts(cfg.Server)))
+	r.Use(statementTimeoutMiddleware(newStatementTimeouts(cfg.Server)))
+	bodyLimit := new(atomic.Int64)
+	bodyLimit.Store(cfg.Server.BodyLimitBytes())
+	r.Use(bodyLimitMiddleware(bodyLimit))
 
 	hub := realtime.NewHub(logger)
 
@@ -90,6 +148,21 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 		}
 	}
 
+	// CRUD handler, shared by the REST routes and the optional gRPC gateway.
+	var apiHandler *api.Handler
+	if pool != nil {
+		apiHandler = api.NewHandler(pool, schemaCache, logger, hub, webhookDispatcher)
+		apiHandler.SetTransactionalWrites(cfg.Database.TransactionalWrites)
+		apiHandler.SetCheckReferences(cfg.Database.CheckReferences)
+		apiHandler.SetAutoTimestamps(cfg.Database.AutoTimestamps, cfg.Database.ManualTimestampTables)
+		apiHandler.SetPageSizes(pageSizes(cfg.Server))
+		apiHandler.SetFieldRules(fieldRules(cfg.Database.FieldRules))
+		apiHandler.SetManagedFields(managedFields(cfg.Database.ManagedFields))
+		apiHandler.SetIdempotencyKeyTTL(time.Duration(cfg.Server.IdempotencyKeyTTL) * time.Second)
+		apiHandler.SetSlowQueryThreshold(time.Duration(cfg.Database.SlowQueryMs) * time.Millisecond)
+		webhookDispatcher.SetRecordFilter(apiHandler)
+	}
+
 	s := &Server{
 		cfg:               cfg,
 		router:            r,
@@ -100,12 +173,33 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 		hub:               hub,
 		webhookDispatcher: webhookDispatcher,
 		startTime:         time.Now(),
+		drain:             drain,
+		errorStats:        errStats,
+		cors:              cors,
+		bodyLimit:         bodyLimit,
+		apiHandler:        apiHandler,
+		h3:                newHTTP3Server(cfg.Server.HTTP3, r),
 	}
 	if authSvc != nil {
 		s.appRL = auth.NewAppRateLimiter()
+		s.publicReadTables = cfg.Auth.PublicReadTables
+	}
+	if cfg.GRPC.Enabled && apiHandler != nil && authSvc != nil {
+		s.grpc = api.NewGRPCServer(apiHandler, authSvc, logger)
+		// gRPC calls are collection calls, so they get that path's
+		// statement timeout.
+		s.grpc.SetStatementTimeout(newStatementTimeouts(cfg.Server).forPath("/api/collections"))
+		s.grpc.SetMaintenance(func() api.Maintenance {
+			st := s.getMaintenance()
+			return api.Maintenance{Enabled: st.Enabled, AllowReads: st.AllowReads, Message: st.Message, RetryAfter: st.RetryAfter}
+		})
 	}
 	if pool != nil {
 		s.msgStore = &pgMessageStore{pool: pool}
+		s.maintStore = &pgMaintenanceStore{pool: pool}
+	}
+	if cfg.Tenants.Enabled && pool != nil && authSvc != nil {
+		s.tenants = tenant.NewService(pool, cfg.Database.MigrationsDir, logger)
 	}
 	if cfg.Admin.Password != "" {
 		s.adminAuth = newAdminAuth(cfg.Admin.Password)
@@ -114,11 +208,7 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 	}
 
 	// Admin login rate limiter (always created, independent of auth service).
-	adminRateLimit := cfg.Admin.LoginRateLimit
-	if adminRateLimit <= 0 {
-		adminRateLimit = 20
-	}
-	s.adminRL = auth.NewRateLimiter(adminRateLimit, time.Minute)
+	s.adminRL = auth.NewRateLimiter(adminLoginRateLimit(cfg), time.Minute)
 
 	// Health check (no content-type restriction).
 	r.Get("/health", s.handleHealth)
@@ -130,6 +220,14 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 	r.Get("/api/openapi.yaml", handleOpenAPISpec)
 
 	r.Route("/api", func(r chi.Router) {
+		r.Use(s.maintenanceGate)
+		r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			httputil.WriteError(w, http.StatusNotFound, "not found")
+		})
+		if authSvc != nil && cfg.Auth.Cookies.Enabled {
+			r.Use(auth.RequireCSRF(authSvc))
+		}
+
 		// Admin auth endpoints (no content-type enforcement — login needs JSON, status is GET).
 		r.Get("/admin/status", s.handleAdminStatus)
 		r.With(s.adminRL.Middleware).Post("/admin/auth", s.handleAdminLogin)
@@ -162,7 +260,12 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			r.Route("/admin/users", func(r chi.Router) {
 				r.Use(s.requireAdminToken)
 				r.Get("/", handleAdminListUsers(authSvc))
+				r.Post("/bulk-delete", handleAdminBulkDeleteUsers(authSvc))
 				r.Delete("/{id}", handleAdminDeleteUser(authSvc))
+				r.Get("/{id}/export", handleAdminExportUser(authSvc))
+				r.Post("/{id}/disable", handleAdminSetUserDisabled(authSvc, true))
+				r.Post("/{id}/enable", handleAdminSetUserDisabled(authSvc, false))
+				r.Post("/{id}/logout", handleAdminLogoutUser(authSvc))
 			})
 
 			// Admin API key management.
@@ -201,10 +304,40 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			r.Get("/", s.handleAdminLogs)
 		})
 
+		// Admin error rates (admin-auth gated).
+		r.Route("/admin/errors", func(r chi.Router) {
+			r.Use(s.requireAdminToken)
+			r.Get("/", s.handleAdminErrors)
+		})
+
 		// Admin stats (admin-auth gated).
 		r.Route("/admin/stats", func(r chi.Router) {
 			r.Use(s.requireAdminToken)
 			r.Get("/", s.handleAdminStats)
+			if apiHandler != nil {
+				r.Get("/index-suggestions", apiHandler.HandleIndexSuggestions)
+			}
+		})
+
+		// Admin config export/import (admin-auth gated).
+		r.Route("/admin/config", func(r chi.Router) {
+			r.Use(s.requireAdminToken)
+			r.Get("/", s.handleAdminConfigExport)
+			r.Post("/", s.handleAdminConfigImport)
+		})
+
+		// Admin runtime settings (admin-auth gated).
+		r.Route("/admin/runtime", func(r chi.Router) {
+			r.Use(s.requireAdminToken)
+			r.Get("/", s.handleAdminRuntimeGet)
+			r.With(middleware.AllowContentType("application/json")).Put("/", s.handleAdminRuntimeSet)
+		})
+
+		// Admin maintenance mode toggle (admin-auth gated).
+		r.Route("/admin/maintenance", func(r chi.Router) {
+			r.Use(s.requireAdminToken)
+			r.Get("/", s.handleAdminMaintenanceGet)
+			r.With(middleware.AllowContentType("application/json")).Post("/", s.handleAdminMaintenanceSet)
 		})
 
 		// Admin secrets management (admin-auth gated, requires auth service).
@@ -229,6 +362,7 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 		r.Route("/admin/jobs", func(r chi.Router) {
 			r.Use(s.requireAdminToken)
 			r.Get("/", s.handleJobsList)
+			r.With(middleware.AllowContentType("application/json")).Post("/", s.handleJobsEnqueue)
 			r.Get("/stats", s.handleJobsStats)
 			r.Get("/{id}", s.handleJobsGet)
 			r.Post("/{id}/retry", s.handleJobsRetry)
@@ -245,6 +379,15 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			r.Post("/{id}/disable", s.handleSchedulesDisable)
 		})
 
+		// Admin tenant provisioning (admin-auth gated, tenants mode only).
+		if cfg.Tenants.Enabled {
+			r.Route("/admin/tenants", func(r chi.Router) {
+				r.Use(s.requireAdminToken)
+				r.Get("/", s.handleAdminTenantsList)
+				r.With(middleware.AllowContentType("application/json")).Post("/", s.handleAdminTenantsCreate)
+			})
+		}
+
 		// Admin materialized view management (admin-auth gated).
 		// Routes registered unconditionally; SetMatviewAdmin wires the service at startup.
 		r.Route("/admin/matviews", func(r chi.Router) {
@@ -257,6 +400,13 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			r.Post("/{id}/refresh", s.handleMatviewsRefresh)
 		})
 
+		// Admin collection size stats and query plans for collection list requests
+		// (admin-auth gated, requires pool).
+		if apiHandler != nil {
+			r.With(s.requireAdminToken).Get("/admin/collections/stats", apiHandler.HandleCollectionStats)
+			r.With(s.requireAdminToken).Get("/admin/collections/{table}/explain", apiHandler.HandleExplain)
+		}
+
 		// Admin email template management (admin-auth gated).
 		// Routes registered unconditionally; SetEmailTemplateService wires the service at startup.
 		r.Route("/admin/email/templates", func(r chi.Router) {
@@ -273,6 +423,7 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 		// Storage routes accept multipart/form-data, mounted outside JSON content-type enforcement.
 		if storageSvc != nil {
 			storageHandler := storage.NewHandler(storageSvc, logger, cfg.Storage.MaxFileSizeBytes())
+			storageHandler.SetMultipartMemory(cfg.Storage.MultipartMemoryBytes())
 			r.Route("/storage", func(r chi.Router) {
 				if authSvc != nil {
 					// Read operations: auth optional (supports signed URLs).
@@ -312,6 +463,7 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			if cfg.Auth.OAuthRedirectURL != "" {
 				authHandler.SetOAuthRedirectURL(cfg.Auth.OAuthRedirectURL)
 			}
+			authHandler.SetOAuthRedirectURLs(cfg.Auth.OAuthRedirectURLs)
 			authHandler.SetOAuthPublisher(hub)
 			if cfg.Auth.MagicLinkEnabled {
 				authHandler.SetMagicLinkEnabled(true)
@@ -319,14 +471,18 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			if cfg.Auth.SMSEnabled {
 				authHandler.SetSMSEnabled(true)
 			}
-			rl := cfg.Auth.RateLimit
-			if rl <= 0 {
-				rl = 10
-			}
-			s.authRL = auth.NewRateLimiter(rl, time.Minute)
+			authHandler.SetHideRegistrationConflicts(cfg.Auth.HideRegConflicts)
+			authHandler.SetRegistrationEnabled(cfg.Auth.RegistrationEnabled)
+			authHandler.SetPasswordLoginEnabled(cfg.Auth.PasswordLoginEnabled)
+			s.authHandler = authHandler
+			s.authRL = auth.NewRateLimiter(authRateLimit(cfg), time.Minute)
+			// Provider delivery receipts are HMAC-signed and bypass the per-IP limiter.
+			r.With(middleware.AllowContentType("application/json")).
+				Post("/auth/sms/status", authHandler.HandleSMSStatus)
 			r.Route("/auth", func(r chi.Router) {
 				r.Use(s.authRL.Middleware)
 				r.Use(middleware.AllowContentType("application/json", "application/x-www-form-urlencoded"))
+				r.Method(http.MethodGet, "/whoami", s.handleWhoami(authSvc))
 				r.Mount("/", authHandler.Routes())
 			})
 		}
@@ -351,12 +507,16 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 
 			// Realtime SSE (handles its own auth for EventSource compatibility).
 			rtHandler := realtime.NewHandler(hub, pool, authSvc, schemaCache, logger)
+			if s.tenants != nil {
+				rtHandler.SetTenants(s.tenants)
+			}
 			r.Get("/realtime", rtHandler.ServeHTTP)
 
 			// Webhook management (admin-only).
 			if pool != nil {
 				whStore := webhooks.NewStore(pool)
 				whHandler := webhooks.NewHandler(whStore, whStore, logger)
+				whHandler.SetRecordFilter(apiHandler)
 				r.Route("/webhooks", func(r chi.Router) {
 					r.Use(s.requireAdminToken)
 					r.Mount("/", whHandler.Routes())
@@ -364,12 +524,11 @@ func New(cfg *config.Config, logger *slog.Logger, schemaCache *schema.CacheHolde
 			}
 
 			// Mount auto-generated CRUD API.
-			if pool != nil {
-				apiHandler := api.NewHandler(pool, schemaCache, logger, hub, webhookDispatcher)
+			if apiHandler != nil {
 				if authSvc != nil {
 					r.Group(func(r chi.Router) {
 						// Accept either a valid admin HMAC token or a user JWT/API-key.
-						r.Use(s.requireAdminOrUserAuth(authSvc))
+						r.Use(s.requireAdminOrUserAuth(authSvc), s.requireTenant)
 						r.Mount("/", apiHandler.Routes())
 					})
 				} else {
@@ -408,10 +567,11 @@ func (s *Server) SetLogBuffer(lb *LogBuffer) {
 }
 
 // SetSMSProvider configures the SMS provider for the messaging API.
-func (s *Server) SetSMSProvider(name string, p sms.Provider, allowedCountries []string) {
+func (s *Server) SetSMSProvider(name string, p sms.Provider, allowedCountries, blockedCountries []string) {
 	s.smsProvider = p
 	s.smsProviderName = name
 	s.smsAllowedCountries = allowedCountries
+	s.smsBlockedCountries = blockedCountries
 	if s.pool != nil {
 		s.msgStore = &pgMessageStore{pool: s.pool}
 	}
@@ -488,6 +648,12 @@ func (s *Server) StartTLSWithReady(ln net.Listener, ready chan<- struct{}) error
 		IdleTimeout:       120 * time.Second,
 	}
 
+	// HTTP/2 is only negotiated when the listener's tls.Config offers h2.
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(s.cfg.Server.HTTP2)
+	s.http.Protocols = &protocols
+
 	s.logger.Info("server starting with TLS", "address", ln.Addr())
 	close(ready)
 
@@ -497,13 +663,91 @@ func (s *Server) StartTLSWithReady(ln net.Listener, ready chan<- struct{}) error
 	return nil
 }
 
-// Shutdown gracefully stops the server.
+// StartGRPC serves the gRPC gateway on the configured gRPC address, blocking
+// until Shutdown. It returns nil immediately when the gateway is disabled.
+func (s *Server) StartGRPC() error {
+	if s.grpc == nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", s.cfg.GRPCAddress())
+	if err != nil {
+		return fmt.Errorf("grpc listen: %w", err)
+	}
+	s.logger.Info("grpc gateway starting", "address", s.cfg.GRPCAddress())
+	if err := s.grpc.Serve(ln); err != nil {
+		return fmt.Errorf("grpc server error: %w", err)
+	}
+	return nil
+}
+
+// GRPCEnabled reports whether the gRPC gateway is configured to run.
+func (s *Server) GRPCEnabled() bool {
+	return s.grpc != nil
+}
+
+// Shutdown gracefully stops the server. It stops accepting requests, waits
+// up to server.shutdown_timeout for in-flight ones to finish, then closes the
+// connections of any still running. The job service drains in parallel: a
+// running job gets the same window to finish before it is re-queued.
 func (s *Server) Shutdown(ctx context.Context) error {
 	timeout := time.Duration(s.cfg.Server.ShutdownTimeout) * time.Second
 	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	s.logger.Info("shutting down server", "timeout", timeout)
+	s.logger.Info("shutting down server", "timeout", timeout, "in_flight", s.drain.inFlight.Load())
+	s.drain.startDraining()
+
+	jobsStopped := make(chan struct{})
+	go func() {
+		if s.jobService != nil {
+			s.jobService.Stop()
+		}
+		close(jobsStopped)
+	}()
+
+	if s.grpc != nil {
+		// Drain in-flight gRPC calls, forcing them closed at the deadline.
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			s.grpc.Stop()
+		}
+	}
+
+	if s.h3 != nil {
+		// Sends GOAWAY, closing the connections still open at the deadline.
+		if err := s.h3.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			s.logger.Warn("http/3 shutdown", "error", err)
+		}
+	}
+
+	// Realtime and log streams never finish on their own; end them so they
+	// don't hold up the drain.
+	s.hub.Close()
+	if s.logBuffer != nil {
+		s.logBuffer.CloseFollowers()
+	}
+	err := s.http.Shutdown(shutdownCtx)
+	if err != nil {
+		s.logger.Warn("shutdown timeout reached, closing in-flight requests",
+			"in_flight", s.drain.inFlight.Load())
+		if closeErr := s.http.Close(); closeErr != nil {
+			s.logger.Error("closing server", "error", closeErr)
+		}
+	} else {
+		s.logger.Info("in-flight requests drained")
+	}
+
+	<-jobsStopped
+	// Requests that finished during the drain may have queued webhook events.
+	if s.webhookDispatcher != nil {
+		s.webhookDispatcher.Close()
+	}
 	if s.authRL != nil {
 		s.authRL.Stop()
 	}
@@ -513,14 +757,45 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	if s.adminRL != nil {
 		s.adminRL.Stop()
 	}
-	if s.jobService != nil {
-		s.jobService.Stop()
+	return err
+}
+
+// pageSizes converts the server page size settings for api.Handler.
+func pageSizes(cfg config.ServerConfig) (api.PageSize, map[string]api.PageSize) {
+	tables := make(map[string]api.PageSize, len(cfg.TablePageSizes))
+	for name, ps := range cfg.TablePageSizes {
+		tables[name] = api.PageSize{Default: ps.DefaultPageSize, Max: ps.MaxPageSize}
 	}
-	if s.webhookDispatcher != nil {
-		s.webhookDispatcher.Close()
+	return api.PageSize{Default: cfg.DefaultPageSize, Max: cfg.MaxPageSize}, tables
+}
+
+// fieldRules converts the configured field rules for the API handler.
+// Patterns were validated by config.Validate.
+func fieldRules(cfg map[string]map[string]config.FieldRule) map[string]map[string]api.FieldRule {
+	rules := make(map[string]map[string]api.FieldRule, len(cfg))
+	for table, columns := range cfg {
+		rules[table] = make(map[string]api.FieldRule, len(columns))
+		for column, r := range columns {
+			rule := api.FieldRule{MinLength: r.MinLength, MaxLength: r.MaxLength, Min: r.Min, Max: r.Max, Enum: r.Enum}
+			if r.Pattern != "" {
+				rule.Pattern = regexp.MustCompile(r.Pattern)
+			}
+			rules[table][column] = rule
+		}
 	}
-	s.hub.Close()
-	return s.http.Shutdown(shutdownCtx)
+	return rules
+}
+
+// managedFields converts the configured managed fields for the API handler.
+func managedFields(cfg map[string]map[string]config.ManagedField) map[string]map[string]api.ManagedField {
+	fields := make(map[string]map[string]api.ManagedField, len(cfg))
+	for table, columns := range cfg {
+		fields[table] = make(map[string]api.ManagedField, len(columns))
+		for column, f := range columns {
+			fields[table][column] = api.ManagedField{Value: f.Value, From: f.From, OnWrite: f.On == "write", Default: f.Default}
+		}
+	}
+	return fields
 }
 
 // jobsNotEnabled returns a 503 response when the job service is not running.
@@ -560,6 +835,14 @@ func (s *Server) handleJobsCancel(w http.ResponseWriter, r *http.Request) {
 	handleAdminCancelJob(s.jobService).ServeHTTP(w, r)
 }
 
+func (s *Server) handleJobsEnqueue(w http.ResponseWriter, r *http.Request) {
+	if s.jobService == nil {
+		jobsNotEnabled(w)
+		return
+	}
+	handleAdminEnqueueJob(s.jobService).ServeHTTP(w, r)
+}
+
 func (s *Server) handleJobsStats(w http.ResponseWriter, r *http.Request) {
 	if s.jobService == nil {
 		jobsNotEnabled(w)
@@ -731,16 +1014,23 @@ func (s *Server) handleEmailSend(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	type migrationsHealth struct {
+		Pending int `json:"pending"`
+	}
 	type healthResponse struct {
-		Status   string `json:"status"`
-		Database string `json:"database"`
+		Status      string            `json:"status"`
+		Database    string            `json:"database"`
+		Migrations  *migrationsHealth `json:"migrations,omitempty"`
+		Maintenance bool              `json:"maintenance,omitempty"`
 	}
+	maintenance := s.getMaintenance().Enabled
 
 	if s.pool == nil {
 		// No database pool — server is up but database-dependent endpoints will not work.
 		httputil.WriteJSON(w, http.StatusOK, healthResponse{
-			Status:   "ok",
-			Database: "not configured",
+			Status:      "ok",
+			Database:    "not configured",
+			Maintenance: maintenance,
 		})
 		return
 	}
@@ -750,16 +1040,39 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.pool.Ping(ctx); err != nil {
 		httputil.WriteJSON(w, http.StatusServiceUnavailable, healthResponse{
-			Status:   "degraded",
-			Database: "unreachable",
+			Status:      "degraded",
+			Database:    "unreachable",
+			Maintenance: maintenance,
 		})
 		return
 	}
 
-	httputil.WriteJSON(w, http.StatusOK, healthResponse{
-		Status:   "ok",
-		Database: "ok",
-	})
+	resp := healthResponse{Status: "ok", Database: "ok", Maintenance: maintenance}
+	// Pending migrations mean the running code may expect schema the database
+	// doesn't have yet. The server can still serve, so this stays a 200.
+	if pending, err := s.pendingMigrations(ctx); err != nil {
+		s.logger.Warn("health: checking migrations", "error", err)
+	} else {
+		resp.Migrations = &migrationsHealth{Pending: pending}
+		if pending > 0 {
+			resp.Status = "degraded"
+		}
+	}
+	httputil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// pendingMigrations counts the system and user migrations that have not been
+// applied to the database.
+func (s *Server) pendingMigrations(ctx context.Context) (int, error) {
+	system, err := migrations.NewRunner(s.pool, s.logger).Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	user, err := migrations.NewUserRunner(s.pool, s.cfg.Database.MigrationsDir, s.logger).Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(system) + len(user), nil
 }
 
 func handleFavicon(w http.ResponseWriter, r *http.Request) {
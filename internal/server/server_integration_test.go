@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/migrations"
 	"github.com/allyourbase/ayb/internal/schema"
 	"github.com/allyourbase/ayb/internal/server"
 	"github.com/allyourbase/ayb/internal/testutil"
@@ -78,6 +80,51 @@ func TestSchemaEndpointReturnsValidJSON(t *testing.T) {
 	testutil.NotNil(t, result.Tables["public.users"])
 }
 
+func TestHealthReportsPendingMigrations(t *testing.T) {
+	ctx := context.Background()
+	createIntegrationTestSchema(t, ctx)
+
+	logger := testutil.DiscardLogger()
+	runner := migrations.NewRunner(sharedPG.Pool, logger)
+	testutil.NoError(t, runner.Bootstrap(ctx))
+	_, err := runner.Run(ctx)
+	testutil.NoError(t, err)
+
+	dir := t.TempDir()
+	testutil.NoError(t, os.WriteFile(filepath.Join(dir, "20260101000000_add_notes.sql"),
+		[]byte("CREATE TABLE notes (id SERIAL PRIMARY KEY);"), 0o644))
+
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	cfg := config.Default()
+	cfg.Database.MigrationsDir = dir
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, nil, nil)
+
+	health := func() map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+		var body map[string]any
+		testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body
+	}
+
+	body := health()
+	testutil.Equal(t, "degraded", body["status"])
+	testutil.Equal(t, "ok", body["database"])
+	testutil.Equal(t, 1.0, body["migrations"].(map[string]any)["pending"])
+
+	userRunner := migrations.NewUserRunner(sharedPG.Pool, dir, logger)
+	testutil.NoError(t, userRunner.Bootstrap(ctx))
+	_, err = userRunner.Up(ctx)
+	testutil.NoError(t, err)
+
+	body = health()
+	testutil.Equal(t, "ok", body["status"])
+	testutil.Equal(t, 0.0, body["migrations"].(map[string]any)["pending"])
+}
+
 // TestRealtimeSSEReceivesCreateEvent verifies the full end-to-end flow:
 // connect SSE → create record via API → receive the realtime event.
 func TestRealtimeSSEReceivesCreateEvent(t *testing.T) {
@@ -102,13 +102,15 @@ func TestSchemaEndpointNotReady(t *testing.T) {
 	testutil.Equal(t, http.StatusServiceUnavailable, w.Code)
 
 	var body struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
 	}
 	err := json.Unmarshal(w.Body.Bytes(), &body)
 	testutil.NoError(t, err)
-	testutil.Equal(t, 503, body.Code)
-	testutil.Contains(t, body.Message, "schema cache not ready")
+	testutil.Equal(t, "unavailable", body.Error.Code)
+	testutil.Contains(t, body.Error.Message, "schema cache not ready")
 }
 
 func TestSchemaEndpointReady(t *testing.T) {
@@ -145,6 +147,79 @@ func TestSchemaEndpointReady(t *testing.T) {
 	testutil.Equal(t, "table", usersRaw["kind"])
 }
 
+func TestSchemaEndpointTableMetadata(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ch := schema.NewCacheHolder(nil, logger)
+	ch.SetForTesting(&schema.SchemaCache{
+		Tables: map[string]*schema.Table{
+			"public.posts": {
+				Schema:     "public",
+				Name:       "posts",
+				Kind:       "table",
+				RLSEnabled: true,
+				Columns: []*schema.Column{
+					{Name: "id", Position: 1, TypeName: "integer", DefaultExpr: "nextval('posts_id_seq'::regclass)", IsPrimaryKey: true, JSONType: "integer"},
+					{Name: "author_id", Position: 2, TypeName: "integer", IsNullable: true, JSONType: "integer"},
+					{Name: "slug", Position: 3, TypeName: "text", JSONType: "string"},
+				},
+				PrimaryKey: []string{"id"},
+				ForeignKeys: []*schema.ForeignKey{{
+					ConstraintName: "posts_author_id_fkey", Columns: []string{"author_id"},
+					ReferencedSchema: "public", ReferencedTable: "users", ReferencedColumns: []string{"id"},
+					OnDelete: "CASCADE",
+				}},
+				UniqueKeys: []*schema.UniqueKey{{ConstraintName: "posts_slug_key", Columns: []string{"slug"}}},
+			},
+		},
+		Schemas: []string{"public"},
+		BuiltAt: time.Now(),
+	})
+	srv := newTestServer(t, ch)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/schema", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+	testutil.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Tables map[string]struct {
+			RLSEnabled bool     `json:"rlsEnabled"`
+			PrimaryKey []string `json:"primaryKey"`
+			Columns    []struct {
+				Name     string `json:"name"`
+				Type     string `json:"type"`
+				Nullable bool   `json:"nullable"`
+				Default  string `json:"default"`
+			} `json:"columns"`
+			ForeignKeys []struct {
+				Columns         []string `json:"columns"`
+				ReferencedTable string   `json:"referencedTable"`
+			} `json:"foreignKeys"`
+			UniqueConstraints []struct {
+				ConstraintName string   `json:"constraintName"`
+				Columns        []string `json:"columns"`
+			} `json:"uniqueConstraints"`
+		} `json:"tables"`
+	}
+	testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	posts, ok := body.Tables["public.posts"]
+	testutil.True(t, ok, "public.posts should be present")
+	testutil.True(t, posts.RLSEnabled, "rlsEnabled should be true")
+	testutil.SliceLen(t, posts.PrimaryKey, 1)
+	testutil.SliceLen(t, posts.Columns, 3)
+	testutil.Equal(t, "integer", posts.Columns[0].Type)
+	testutil.Equal(t, "nextval('posts_id_seq'::regclass)", posts.Columns[0].Default)
+	testutil.False(t, posts.Columns[0].Nullable, "id should not be nullable")
+	testutil.True(t, posts.Columns[1].Nullable, "author_id should be nullable")
+	testutil.SliceLen(t, posts.ForeignKeys, 1)
+	testutil.Equal(t, "users", posts.ForeignKeys[0].ReferencedTable)
+	testutil.SliceLen(t, posts.UniqueConstraints, 1)
+	testutil.Equal(t, "posts_slug_key", posts.UniqueConstraints[0].ConstraintName)
+	testutil.Equal(t, "slug", posts.UniqueConstraints[0].Columns[0])
+}
+
 func TestRouterSetup(t *testing.T) {
 	t.Parallel()
 	ch := newCacheHolderWithSchema(nil)
@@ -484,3 +559,16 @@ func TestCORSPreflightOnOAuthRevokeEndpoint(t *testing.T) {
 	testutil.Equal(t, "https://spa.example.com", w.Header().Get("Access-Control-Allow-Origin"))
 	testutil.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "POST")
 }
+
+func TestGRPCGatewayRequiresDatabase(t *testing.T) {
+	t.Parallel()
+	cfg := config.Default()
+	cfg.GRPC.Enabled = true
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ch := schema.NewCacheHolder(nil, logger)
+	srv := server.New(cfg, logger, ch, nil, nil, nil)
+
+	// Without a pool there is no CRUD handler to expose, so the gateway stays off.
+	testutil.False(t, srv.GRPCEnabled(), "grpc gateway should be disabled without a database")
+	testutil.NoError(t, srv.StartGRPC())
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// trailingSlashMiddleware applies server.trailing_slash to a path ending in
+// "/" that would be routed without the slash. In "match" mode such a path
+// that doesn't route as is is routed as if the slash were absent; in
+// "redirect" mode the request is redirected with 308, which keeps the method
+// and body, to the path without the slash. "strict" routes paths as given.
+func trailingSlashMiddleware(mode string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if mode == "strict" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			path := r.URL.RawPath
+			if path == "" {
+				path = r.URL.Path
+			}
+			trimmed := strings.TrimSuffix(path, "/")
+			// "//host" would redirect off-site as a protocol-relative URL.
+			if rctx == nil || trimmed == path || trimmed == "" || strings.HasPrefix(trimmed, "//") ||
+				!routeExists(rctx.Routes, trimmed) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if mode == "redirect" {
+				if r.URL.RawQuery != "" {
+					trimmed += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, trimmed, http.StatusPermanentRedirect)
+				return
+			}
+			if !routeExists(rctx.Routes, path) {
+				rctx.RoutePath = trimmed
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeExists reports whether routes serves path for any method.
+func routeExists(routes chi.Routes, path string) bool {
+	for _, m := range allowOrder {
+		if routes.Match(chi.NewRouteContext(), m, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/go-chi/chi/v5"
+)
+
+// slashTestRouter mirrors the collection routes: a list route mounted with
+// Route and a record route without a trailing slash.
+func slashTestRouter(mode string) http.Handler {
+	r := chi.NewRouter()
+	r.Use(trailingSlashMiddleware(mode))
+	r.Route("/api/collections/{table}", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "list "+chi.URLParam(r, "table"))
+		})
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "read "+chi.URLParam(r, "id"))
+		})
+	})
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+	return r
+}
+
+func serveSlash(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestTrailingSlashMatch(t *testing.T) {
+	t.Parallel()
+	h := slashTestRouter("match")
+
+	for _, tc := range []struct {
+		path, body string
+	}{
+		{"/api/collections/posts", "list posts"},
+		{"/api/collections/posts/", "list posts"},
+		{"/api/collections/posts/42", "read 42"},
+		{"/api/collections/posts/42/", "read 42"},
+		{"/health/", "ok"},
+	} {
+		w := serveSlash(h, http.MethodGet, tc.path)
+		testutil.Equal(t, http.StatusOK, w.Code)
+		testutil.Equal(t, tc.body, w.Body.String())
+	}
+
+	// Only a single trailing slash is dropped.
+	testutil.Equal(t, http.StatusNotFound, serveSlash(h, http.MethodGet, "/health//").Code)
+	testutil.Equal(t, http.StatusNotFound, serveSlash(h, http.MethodGet, "/missing/").Code)
+}
+
+func TestTrailingSlashRedirect(t *testing.T) {
+	t.Parallel()
+	h := slashTestRouter("redirect")
+
+	w := serveSlash(h, http.MethodPost, "/api/collections/posts/42/?fields=id")
+	testutil.Equal(t, http.StatusPermanentRedirect, w.Code)
+	testutil.Equal(t, "/api/collections/posts/42?fields=id", w.Header().Get("Location"))
+
+	w = serveSlash(h, http.MethodGet, "/api/collections/posts/")
+	testutil.Equal(t, http.StatusPermanentRedirect, w.Code)
+	testutil.Equal(t, "/api/collections/posts", w.Header().Get("Location"))
+
+	// The canonical form and unknown paths are served as usual.
+	w = serveSlash(h, http.MethodGet, "/api/collections/posts")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, http.StatusNotFound, serveSlash(h, http.MethodGet, "/missing/").Code)
+	testutil.Equal(t, http.StatusNotFound, serveSlash(h, http.MethodGet, "//health/").Code)
+}
+
+func TestTrailingSlashStrict(t *testing.T) {
+	t.Parallel()
+	h := slashTestRouter("strict")
+
+	testutil.Equal(t, http.StatusOK, serveSlash(h, http.MethodGet, "/api/collections/posts/42").Code)
+	testutil.Equal(t, http.StatusNotFound, serveSlash(h, http.MethodGet, "/api/collections/posts/42/").Code)
+}
@@ -1,79 +1,202 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/allyourbase/ayb/internal/httputil"
 )
 
-// smsWindowStats holds aggregated SMS stats for a time window.
+// smsWindowStats holds aggregated SMS stats for a time window. Confirmed and
+// Failed track OTP verification outcomes; Delivered and Undelivered track
+// provider delivery receipts, which surface carrier problems separately from
+// users mistyping codes.
 type smsWindowStats struct {
-	Sent           int     `json:"sent"`
-	Confirmed      int     `json:"confirmed"`
-	Failed         int     `json:"failed"`
-	ConversionRate float64 `json:"conversion_rate"`
+	Sent                int     `json:"sent"`
+	Confirmed           int     `json:"confirmed"`
+	Failed              int     `json:"failed"`
+	Delivered           int     `json:"delivered"`
+	Undelivered         int     `json:"undelivered"`
+	ConversionRate      float64 `json:"conversion_rate"`
+	DeliveryFailureRate float64 `json:"delivery_failure_rate"`
+	// EstimatedCost is the spend on countries with a configured price
+	// (auth.sms_prices); omitted when no prices are configured.
+	EstimatedCost *float64 `json:"estimated_cost,omitempty"`
+}
+
+// smsCountryStats holds 30-day SMS stats for one country.
+type smsCountryStats struct {
+	Country        string   `json:"country"`
+	Sent           int      `json:"sent"`
+	Confirmed      int      `json:"confirmed"`
+	Failed         int      `json:"failed"`
+	ConversionRate float64  `json:"conversion_rate"`
+	EstimatedCost  *float64 `json:"estimated_cost,omitempty"` // nil when the country has no price
+}
+
+// smsCountryRow is one country's counts from _ayb_sms_country_counts, with
+// sends broken out per smsHealthWindows entry.
+type smsCountryRow struct {
+	country   string
+	sent      []int // indexed like smsHealthWindows
+	confirmed int   // over the widest window
+	failed    int   // over the widest window
+}
+
+// smsHealthWindows are the reporting windows, as days before today.
+var smsHealthWindows = []struct {
+	key  string
+	days int
+}{
+	{"today", 0},
+	{"last_7d", 6},
+	{"last_30d", 29},
 }
 
 // handleAdminSMSHealth returns SMS delivery stats for today, last 7 days, and last 30 days.
 func (s *Server) handleAdminSMSHealth(w http.ResponseWriter, r *http.Request) {
 	if s.pool == nil {
-		http.NotFound(w, r)
+		httputil.WriteError(w, http.StatusNotFound, "not found")
 		return
 	}
 	ctx := r.Context()
 
-	query := `
-		SELECT
-			COALESCE(SUM(count) FILTER (WHERE date = CURRENT_DATE), 0),
-			COALESCE(SUM(confirm_count) FILTER (WHERE date = CURRENT_DATE), 0),
-			COALESCE(SUM(fail_count) FILTER (WHERE date = CURRENT_DATE), 0),
-			COALESCE(SUM(count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '6 days'), 0),
-			COALESCE(SUM(confirm_count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '6 days'), 0),
-			COALESCE(SUM(fail_count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '6 days'), 0),
-			COALESCE(SUM(count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '29 days'), 0),
-			COALESCE(SUM(confirm_count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '29 days'), 0),
-			COALESCE(SUM(fail_count) FILTER (WHERE date >= CURRENT_DATE - INTERVAL '29 days'), 0)
+	stats := make([]smsWindowStats, len(smsHealthWindows))
+	var cols []string
+	var dest []any
+	for i, win := range smsHealthWindows {
+		for _, c := range []struct {
+			column string
+			field  *int
+		}{
+			{"count", &stats[i].Sent},
+			{"confirm_count", &stats[i].Confirmed},
+			{"fail_count", &stats[i].Failed},
+			{"delivered_count", &stats[i].Delivered},
+			{"undelivered_count", &stats[i].Undelivered},
+		} {
+			cols = append(cols, fmt.Sprintf(
+				"COALESCE(SUM(%s) FILTER (WHERE date >= CURRENT_DATE - %d), 0)", c.column, win.days))
+			dest = append(dest, c.field)
+		}
+	}
+	query := "SELECT " + strings.Join(cols, ", ") + `
 		FROM _ayb_sms_daily_counts
 		WHERE date >= CURRENT_DATE - INTERVAL '29 days'`
 
-	var todaySent, todayConfirmed, todayFailed int
-	var weekSent, weekConfirmed, weekFailed int
-	var monthSent, monthConfirmed, monthFailed int
-
-	err := s.pool.QueryRow(ctx, query).Scan(
-		&todaySent, &todayConfirmed, &todayFailed,
-		&weekSent, &weekConfirmed, &weekFailed,
-		&monthSent, &monthConfirmed, &monthFailed,
-	)
-	if err != nil {
+	if err := s.pool.QueryRow(ctx, query).Scan(dest...); err != nil {
 		s.logger.Error("SMS health query error", "error", err)
 		httputil.WriteError(w, http.StatusInternalServerError, "failed to query SMS stats")
 		return
 	}
 
-	today := smsWindowStats{Sent: todaySent, Confirmed: todayConfirmed, Failed: todayFailed, ConversionRate: conversionRate(todaySent, todayConfirmed)}
-	week := smsWindowStats{Sent: weekSent, Confirmed: weekConfirmed, Failed: weekFailed, ConversionRate: conversionRate(weekSent, weekConfirmed)}
-	month := smsWindowStats{Sent: monthSent, Confirmed: monthConfirmed, Failed: monthFailed, ConversionRate: conversionRate(monthSent, monthConfirmed)}
+	rows, err := s.querySMSCountryRows(ctx)
+	if err != nil {
+		s.logger.Error("SMS country health query error", "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to query SMS stats")
+		return
+	}
+	countries, windowCosts := smsCountryBreakdown(rows, s.cfg.Auth.SMSPrices)
 
-	resp := map[string]any{
-		"today":   today,
-		"last_7d": week,
-		"last_30d": month,
+	resp := map[string]any{"countries": countries}
+	for i, win := range smsHealthWindows {
+		st := &stats[i]
+		st.ConversionRate = conversionRate(st.Sent, st.Confirmed)
+		st.DeliveryFailureRate = deliveryFailureRate(st.Delivered, st.Undelivered)
+		st.EstimatedCost = windowCosts[i]
+		resp[win.key] = *st
 	}
 
+	today := stats[0]
 	// Warn when today's conversion rate is below 10% with meaningful volume.
-	if todaySent > 0 && today.ConversionRate < 10 {
+	if today.Sent > 0 && today.ConversionRate < 10 {
 		resp["warning"] = "low conversion rate"
 	}
+	// Warn when more than 20% of today's receipts report non-delivery.
+	if today.Undelivered > 0 && today.DeliveryFailureRate > 20 {
+		resp["delivery_warning"] = "high delivery failure rate"
+	}
 
 	httputil.WriteJSON(w, http.StatusOK, resp)
 }
 
+// querySMSCountryRows aggregates _ayb_sms_country_counts over the health
+// windows, busiest country first.
+func (s *Server) querySMSCountryRows(ctx context.Context) ([]smsCountryRow, error) {
+	var cols []string
+	for _, win := range smsHealthWindows {
+		cols = append(cols, fmt.Sprintf(
+			"COALESCE(SUM(count) FILTER (WHERE date >= CURRENT_DATE - %d), 0)", win.days))
+	}
+	query := "SELECT country, " + strings.Join(cols, ", ") + `,
+			COALESCE(SUM(confirm_count), 0), COALESCE(SUM(fail_count), 0)
+		FROM _ayb_sms_country_counts
+		WHERE date >= CURRENT_DATE - INTERVAL '29 days'
+		GROUP BY country
+		ORDER BY SUM(count) DESC, country`
+
+	rows, err := s.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []smsCountryRow
+	for rows.Next() {
+		row := smsCountryRow{sent: make([]int, len(smsHealthWindows))}
+		dest := []any{&row.country}
+		for i := range row.sent {
+			dest = append(dest, &row.sent[i])
+		}
+		dest = append(dest, &row.confirmed, &row.failed)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// smsCountryBreakdown turns per-country rows into the response breakdown and
+// estimates spend per health window from prices (ISO country → price per SMS).
+// Window costs are nil when no prices are configured.
+func smsCountryBreakdown(rows []smsCountryRow, prices map[string]float64) ([]smsCountryStats, []*float64) {
+	windowCosts := make([]*float64, len(smsHealthWindows))
+	if len(prices) > 0 {
+		for i := range windowCosts {
+			windowCosts[i] = new(float64)
+		}
+	}
+
+	widest := len(smsHealthWindows) - 1
+	countries := make([]smsCountryStats, 0, len(rows))
+	for _, row := range rows {
+		st := smsCountryStats{
+			Country:        row.country,
+			Sent:           row.sent[widest],
+			Confirmed:      row.confirmed,
+			Failed:         row.failed,
+			ConversionRate: conversionRate(row.sent[widest], row.confirmed),
+		}
+		if price, ok := prices[row.country]; ok {
+			cost := price * float64(st.Sent)
+			st.EstimatedCost = &cost
+			for i, n := range row.sent {
+				*windowCosts[i] += price * float64(n)
+			}
+		}
+		countries = append(countries, st)
+	}
+	return countries, windowCosts
+}
+
 // handleAdminSMSMessages returns a paginated list of all SMS messages for admin.
 func (s *Server) handleAdminSMSMessages(w http.ResponseWriter, r *http.Request) {
 	if s.msgStore == nil {
-		http.NotFound(w, r)
+		httputil.WriteError(w, http.StatusNotFound, "not found")
 		return
 	}
 
@@ -129,6 +252,9 @@ func (s *Server) handleAdminSMSSend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !httputil.LimitBody(w, r) {
+		return
+	}
 	input, status, errMsg := s.validateSMSSendBody(r)
 	if status != 0 {
 		httputil.WriteError(w, status, errMsg)
@@ -154,6 +280,16 @@ func (s *Server) handleAdminSMSSend(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// deliveryFailureRate calculates undelivered/(delivered+undelivered) * 100,
+// returning 0 when no receipts have arrived.
+func deliveryFailureRate(delivered, undelivered int) float64 {
+	total := delivered + undelivered
+	if total == 0 {
+		return 0
+	}
+	return float64(undelivered) / float64(total) * 100
+}
+
 // conversionRate calculates confirmed/sent * 100, returning 0 when sent is 0.
 func conversionRate(sent, confirmed int) float64 {
 	if sent == 0 {
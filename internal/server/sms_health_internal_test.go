@@ -23,6 +23,45 @@ func TestConversionRate_PartialConversion(t *testing.T) {
 	testutil.Equal(t, 25.0, conversionRate(4, 1))
 }
 
+func TestDeliveryFailureRate(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, 0.0, deliveryFailureRate(0, 0))
+	testutil.Equal(t, 0.0, deliveryFailureRate(10, 0))
+	testutil.Equal(t, 25.0, deliveryFailureRate(3, 1))
+	testutil.Equal(t, 100.0, deliveryFailureRate(0, 4))
+}
+
+func TestSMSCountryBreakdown(t *testing.T) {
+	t.Parallel()
+	rows := []smsCountryRow{
+		{country: "US", sent: []int{2, 10, 100}, confirmed: 50, failed: 5},
+		{country: "GB", sent: []int{0, 1, 4}, confirmed: 1},
+	}
+
+	t.Run("no prices", func(t *testing.T) {
+		countries, costs := smsCountryBreakdown(rows, nil)
+		testutil.SliceLen(t, countries, 2)
+		testutil.Equal(t, "US", countries[0].Country)
+		testutil.Equal(t, 100, countries[0].Sent)
+		testutil.Equal(t, 50, countries[0].Confirmed)
+		testutil.Equal(t, 5, countries[0].Failed)
+		testutil.Equal(t, 50.0, countries[0].ConversionRate)
+		testutil.Nil(t, countries[0].EstimatedCost)
+		for _, c := range costs {
+			testutil.Nil(t, c)
+		}
+	})
+
+	t.Run("partial prices", func(t *testing.T) {
+		countries, costs := smsCountryBreakdown(rows, map[string]float64{"US": 0.5})
+		testutil.Equal(t, 50.0, *countries[0].EstimatedCost)
+		testutil.Nil(t, countries[1].EstimatedCost) // GB has no price
+		testutil.Equal(t, 1.0, *costs[0])
+		testutil.Equal(t, 5.0, *costs[1])
+		testutil.Equal(t, 50.0, *costs[2])
+	})
+}
+
 func TestDeliveryStatusRank_Ordering(t *testing.T) {
 	t.Parallel()
 	// Each step in the lifecycle must have a higher or equal rank than the previous.
@@ -18,6 +18,11 @@ import (
 // sqlRequest is the request body for the SQL editor endpoint.
 type sqlRequest struct {
 	Query string `json:"query"`
+	// ReadOnly runs the query in a read-only transaction, so statements
+	// that write fail instead of taking effect. The query must then be a
+	// single statement, so it can't end the transaction or make it
+	// read-write first.
+	ReadOnly bool `json:"readOnly"`
 }
 
 // sqlResponse is the response body for the SQL editor endpoint.
@@ -69,7 +74,25 @@ func handleAdminSQL(pool *pgxpool.Pool, sc *schema.CacheHolder) http.HandlerFunc
 
 		start := time.Now()
 
-		rows, err := pool.Query(ctx, req.Query, pgx.QueryExecModeSimpleProtocol)
+		var q interface {
+			Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+		} = pool
+		// The simple protocol runs every statement in the query. The
+		// extended protocol accepts only one, which read-only mode relies
+		// on to rule out "COMMIT; DROP TABLE ...".
+		mode := pgx.QueryExecModeSimpleProtocol
+		if req.ReadOnly {
+			mode = pgx.QueryExecModeExec
+			tx, err := pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+			if err != nil {
+				httputil.WriteError(w, http.StatusInternalServerError, "starting read-only transaction: "+err.Error())
+				return
+			}
+			defer tx.Rollback(context.WithoutCancel(ctx))
+			q = tx
+		}
+
+		rows, err := q.Query(ctx, req.Query, mode)
 		if err != nil {
 			httputil.WriteError(w, http.StatusBadRequest, err.Error())
 			return
@@ -109,7 +132,7 @@ func handleAdminSQL(pool *pgxpool.Pool, sc *schema.CacheHolder) http.HandlerFunc
 
 		// Reload schema cache synchronously after DDL so the next
 		// /api/schema request returns the updated schema.
-		if isDDL(req.Query) && sc != nil {
+		if isDDL(req.Query) && !req.ReadOnly && sc != nil {
 			if err := sc.ReloadWait(r.Context()); err != nil {
 				// Log but don't fail the request — the DDL itself succeeded.
 				slog.Default().Warn("schema reload after DDL failed", "error", err)
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/httputil"
+	"github.com/allyourbase/ayb/internal/tenant"
+)
+
+// tenantAdmin is the tenant service used by the tenant gate and admin
+// endpoints. *tenant.Service implements it.
+type tenantAdmin interface {
+	tenant.Checker
+	CreateTenant(ctx context.Context, id string) (*tenant.Tenant, error)
+	ListTenants(ctx context.Context) ([]tenant.Tenant, error)
+}
+
+type tenantListResponse struct {
+	Items []tenant.Tenant `json:"items"`
+}
+
+const tenantsDocURL = "https://allyourbase.io/guide/tenants"
+
+// requireTenant runs user requests against the schema of the tenant named in
+// their JWT, refusing them with 403 if it names none or an unknown tenant.
+// Admin requests carry no claims and use the public schema. It runs after
+// requireAdminOrUserAuth and does nothing unless tenants are enabled.
+func (s *Server) requireTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := auth.ClaimsFromContext(r.Context())
+		if s.tenants == nil || claims == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := tenant.Resolve(r.Context(), s.tenants, claims)
+		if errors.Is(err, tenant.ErrNoTenant) || errors.Is(err, tenant.ErrNotFound) {
+			httputil.WriteErrorWithDocURL(w, http.StatusForbidden, err.Error(), tenantsDocURL)
+			return
+		}
+		if err != nil {
+			s.logger.Error("tenant lookup error", "error", err)
+			httputil.WriteError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+	})
+}
+
+// tenantsNotEnabled returns a 503 when the tenant service is not wired.
+func tenantsNotEnabled(w http.ResponseWriter) {
+	httputil.WriteError(w, http.StatusServiceUnavailable, "tenants require a database connection")
+}
+
+func (s *Server) handleAdminTenantsList(w http.ResponseWriter, r *http.Request) {
+	if s.tenants == nil {
+		tenantsNotEnabled(w)
+		return
+	}
+	tenants, err := s.tenants.ListTenants(r.Context())
+	if err != nil {
+		s.logger.Error("list tenants error", "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to list tenants")
+		return
+	}
+	httputil.WriteJSON(w, http.StatusOK, tenantListResponse{Items: tenants})
+}
+
+// handleAdminTenantsCreate provisions a tenant: its schema is created and
+// the user migrations are run into it before the response is sent.
+func (s *Server) handleAdminTenantsCreate(w http.ResponseWriter, r *http.Request) {
+	if s.tenants == nil {
+		tenantsNotEnabled(w)
+		return
+	}
+	var req struct {
+		ID string `json:"id"`
+	}
+	if !httputil.DecodeJSON(w, r, &req) {
+		return
+	}
+	t, err := s.tenants.CreateTenant(r.Context(), req.ID)
+	switch {
+	case errors.Is(err, tenant.ErrInvalidID):
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, err.Error(), tenantsDocURL)
+	case errors.Is(err, tenant.ErrExists):
+		httputil.WriteError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		s.logger.Error("create tenant error", "tenant", req.ID, "error", err)
+		httputil.WriteError(w, http.StatusInternalServerError, "failed to create tenant")
+	default:
+		httputil.WriteJSON(w, http.StatusCreated, t)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/tenant"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const tenantTestSecret = "test-secret-that-is-at-least-32-chars!!"
+
+type fakeTenantAdmin struct {
+	tenants map[string]bool
+	err     error
+}
+
+func (f *fakeTenantAdmin) Exists(_ context.Context, id string) (bool, error) {
+	return f.tenants[id], f.err
+}
+
+func (f *fakeTenantAdmin) CreateTenant(_ context.Context, id string) (*tenant.Tenant, error) {
+	if !tenant.ValidID(id) {
+		return nil, tenant.ErrInvalidID
+	}
+	if f.tenants[id] {
+		return nil, tenant.ErrExists
+	}
+	f.tenants[id] = true
+	return &tenant.Tenant{ID: id, Schema: tenant.SchemaName(id)}, nil
+}
+
+func (f *fakeTenantAdmin) ListTenants(context.Context) ([]tenant.Tenant, error) {
+	var out []tenant.Tenant
+	for id := range f.tenants {
+		out = append(out, tenant.Tenant{ID: id, Schema: tenant.SchemaName(id)})
+	}
+	return out, nil
+}
+
+func newTenantTestServer(t *testing.T) (*Server, *auth.Service, *fakeTenantAdmin) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Admin.Password = "testpass"
+	cfg.Tenants.Enabled = true
+	logger := testutil.DiscardLogger()
+	authSvc := auth.NewService(nil, tenantTestSecret, time.Hour, 7*24*time.Hour, 8, logger)
+	authSvc.SetTenantClaim("tenant_id")
+	s := New(cfg, logger, schema.NewCacheHolder(nil, logger), nil, authSvc, nil)
+	fake := &fakeTenantAdmin{tenants: map[string]bool{"acme": true}}
+	s.tenants = fake
+	return s, authSvc, fake
+}
+
+// tenantClaims returns validated claims for a token with the given tenant
+// claim; "" omits the claim.
+func tenantClaims(t *testing.T, authSvc *auth.Service, tenantID string) *auth.Claims {
+	t.Helper()
+	mc := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+	if tenantID != "" {
+		mc["tenant_id"] = tenantID
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, mc).SignedString([]byte(tenantTestSecret))
+	testutil.NoError(t, err)
+	claims, err := authSvc.ValidateToken(token)
+	testutil.NoError(t, err)
+	return claims
+}
+
+func TestRequireTenant(t *testing.T) {
+	t.Parallel()
+	s, authSvc, fake := newTenantTestServer(t)
+
+	var gotTenant string
+	h := s.requireTenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = tenant.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(claims *auth.Claims) *httptest.ResponseRecorder {
+		gotTenant = ""
+		req := httptest.NewRequest(http.MethodGet, "/api/collections/posts", nil)
+		if claims != nil {
+			req = req.WithContext(auth.ContextWithClaims(req.Context(), claims))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(tenantClaims(t, authSvc, "acme"))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "acme", gotTenant)
+
+	w = serve(tenantClaims(t, authSvc, "globex"))
+	testutil.Equal(t, http.StatusForbidden, w.Code)
+	testutil.Contains(t, w.Body.String(), "tenant not found")
+
+	w = serve(tenantClaims(t, authSvc, ""))
+	testutil.Equal(t, http.StatusForbidden, w.Code)
+	testutil.Contains(t, w.Body.String(), "no tenant claim")
+
+	// Admin requests carry no claims and use the public schema.
+	w = serve(nil)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "", gotTenant)
+
+	fake.err = errors.New("db down")
+	w = serve(tenantClaims(t, authSvc, "acme"))
+	testutil.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAdminTenants(t *testing.T) {
+	t.Parallel()
+	s, _, _ := newTenantTestServer(t)
+	token := s.adminAuth.token()
+
+	w := doMaintenanceRequest(s, "", http.MethodGet, "/api/admin/tenants/", "")
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/tenants/", `{"id":"globex"}`)
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	testutil.Contains(t, w.Body.String(), `"schema":"ayb_tenant_globex"`)
+
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/tenants/", `{"id":"globex"}`)
+	testutil.Equal(t, http.StatusConflict, w.Code)
+
+	w = doMaintenanceRequest(s, token, http.MethodPost, "/api/admin/tenants/", `{"id":"Bad-ID"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doMaintenanceRequest(s, token, http.MethodGet, "/api/admin/tenants/", "")
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Contains(t, w.Body.String(), `"id":"globex"`)
+
+	s.tenants = nil
+	w = doMaintenanceRequest(s, token, http.MethodGet, "/api/admin/tenants/", "")
+	testutil.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
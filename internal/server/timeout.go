@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/api"
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// untimedPaths hold the connection open by design and are never timed out.
+var untimedPaths = []string{"/api/realtime", "/api/admin/logs"}
+
+// routeTimeout is a server.route_timeouts or
+// server.route_statement_timeouts entry.
+type routeTimeout struct {
+	prefix  string
+	timeout time.Duration
+}
+
+// requestTimeouts resolves a global timeout and its per-prefix overrides,
+// such as server.request_timeout and server.route_timeouts, for a request
+// path.
+type requestTimeouts struct {
+	global time.Duration
+	routes []routeTimeout // longest prefix first
+}
+
+func newRequestTimeouts(cfg config.ServerConfig) *requestTimeouts {
+	return newRouteTimeouts(cfg.RequestTimeout, cfg.RouteTimeouts, untimedPaths)
+}
+
+func newStatementTimeouts(cfg config.ServerConfig) *requestTimeouts {
+	return newRouteTimeouts(cfg.StatementTimeout, cfg.RouteStatementTimeouts, nil)
+}
+
+// newRouteTimeouts builds a resolver from a global timeout and overrides in
+// seconds. Paths under untimed get no timeout.
+func newRouteTimeouts(global int, overrides map[string]int, untimed []string) *requestTimeouts {
+	t := &requestTimeouts{global: time.Duration(global) * time.Second}
+	for prefix, seconds := range overrides {
+		t.routes = append(t.routes, routeTimeout{
+			prefix:  strings.TrimSuffix(prefix, "/"),
+			timeout: time.Duration(seconds) * time.Second,
+		})
+	}
+	for _, prefix := range untimed {
+		t.routes = append(t.routes, routeTimeout{prefix: prefix})
+	}
+	sort.SliceStable(t.routes, func(i, j int) bool {
+		return len(t.routes[i].prefix) > len(t.routes[j].prefix)
+	})
+	return t
+}
+
+// forPath returns the timeout for path; 0 means none.
+func (t *requestTimeouts) forPath(path string) time.Duration {
+	for _, rt := range t.routes {
+		if path == rt.prefix || strings.HasPrefix(path, rt.prefix+"/") {
+			return rt.timeout
+		}
+	}
+	return t.global
+}
+
+// statementTimeoutMiddleware records the path's statement timeout in the
+// request context, where the API applies it to the request's transaction.
+func statementTimeoutMiddleware(timeouts *requestTimeouts) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timeout := timeouts.forPath(r.URL.Path); timeout > 0 {
+				r = r.WithContext(api.WithStatementTimeout(r.Context(), timeout))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// timeoutMiddleware cancels the request context after the path's timeout,
+// which also cancels the handler's database queries. A response not started
+// by then is replaced with 504, including the error a handler writes when
+// its query is cancelled.
+func timeoutMiddleware(timeouts *requestTimeouts) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := timeouts.forPath(r.URL.Path)
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.WriteHeader(http.StatusGatewayTimeout)
+			}
+		})
+	}
+}
+
+// timeoutWriter sends 504 in place of a response started after the request
+// deadline, discarding the handler's own status and body.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		w.Header().Del("Content-Length")
+		httputil.WriteError(w.ResponseWriter, http.StatusGatewayTimeout, "request timed out")
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the wrapper.
+func (w *timeoutWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/api"
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestRequestTimeoutsForPath(t *testing.T) {
+	t.Parallel()
+	timeouts := newRequestTimeouts(config.ServerConfig{
+		RequestTimeout: 30,
+		RouteTimeouts: map[string]int{
+			"/api/admin":     60,
+			"/api/admin/sql": 300,
+			"/api/storage/":  0,
+		},
+	})
+
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"/api/collections/posts", 30 * time.Second},
+		{"/api/admin/jobs", 60 * time.Second},
+		{"/api/admin/sql", 300 * time.Second},
+		{"/api/admin/sql/", 300 * time.Second},
+		{"/api/admin/sqlx", 60 * time.Second}, // prefixes match whole path segments
+		{"/api/storage/avatars/a.png", 0},
+		{"/api/realtime", 0}, // streams are never timed out
+		{"/api/admin/logs", 0},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, timeouts.forPath(tt.path))
+	}
+
+	// No global timeout: only overrides apply.
+	timeouts = newRequestTimeouts(config.ServerConfig{RouteTimeouts: map[string]int{"/api/rpc": 5}})
+	testutil.Equal(t, time.Duration(0), timeouts.forPath("/api/collections/posts"))
+	testutil.Equal(t, 5*time.Second, timeouts.forPath("/api/rpc/slow"))
+}
+
+func TestStatementTimeoutsForPath(t *testing.T) {
+	t.Parallel()
+	timeouts := newStatementTimeouts(config.ServerConfig{
+		RequestTimeout:         60,
+		RouteTimeouts:          map[string]int{"/api/rpc": 600},
+		StatementTimeout:       5,
+		RouteStatementTimeouts: map[string]int{"/api/rpc/monthly_report": 120},
+	})
+	testutil.Equal(t, 5*time.Second, timeouts.forPath("/api/collections/posts"))
+	testutil.Equal(t, 5*time.Second, timeouts.forPath("/api/rpc/lookup"))
+	testutil.Equal(t, 120*time.Second, timeouts.forPath("/api/rpc/monthly_report"))
+	// Long-lived paths aren't exempt: they run no request transaction.
+	testutil.Equal(t, 5*time.Second, timeouts.forPath("/api/realtime"))
+}
+
+func TestStatementTimeoutMiddleware(t *testing.T) {
+	t.Parallel()
+	timeouts := newStatementTimeouts(config.ServerConfig{
+		StatementTimeout:       5,
+		RouteStatementTimeouts: map[string]int{"/api/rpc/export": 0},
+	})
+
+	var got time.Duration
+	h := statementTimeoutMiddleware(timeouts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = api.StatementTimeout(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/collections/posts", nil))
+	testutil.Equal(t, 5*time.Second, got)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/rpc/export", nil))
+	testutil.Equal(t, time.Duration(0), got)
+
+	// Under a request timeout, the statement timeout never outlasts the
+	// request.
+	h = timeoutMiddleware(&requestTimeouts{global: time.Second})(h)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/collections/posts", nil))
+	testutil.True(t, got > 0 && got <= time.Second, "statement timeout %s should be capped by the request timeout", got)
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Parallel()
+	timeouts := &requestTimeouts{global: 20 * time.Millisecond}
+
+	// A handler whose work is cancelled writes its own error; the client
+	// gets a clean 504 instead.
+	slow := timeoutMiddleware(timeouts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		http.Error(w, "internal error: "+r.Context().Err().Error(), http.StatusInternalServerError)
+	}))
+	w := httptest.NewRecorder()
+	slow.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/rpc/slow", nil))
+	testutil.Equal(t, http.StatusGatewayTimeout, w.Code)
+	testutil.Contains(t, w.Body.String(), "request timed out")
+	testutil.False(t, strings.Contains(w.Body.String(), "internal error"), "handler body should be discarded")
+
+	// A handler that returns without writing after the deadline also gets 504.
+	silent := timeoutMiddleware(timeouts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	w = httptest.NewRecorder()
+	silent.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	testutil.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	// Fast handlers are unaffected.
+	fast := timeoutMiddleware(timeouts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	w = httptest.NewRecorder()
+	fast.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	testutil.Equal(t, "ok", w.Body.String())
+}
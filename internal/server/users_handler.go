@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/allyourbase/ayb/internal/auth"
 	"github.com/allyourbase/ayb/internal/httputil"
@@ -16,6 +17,11 @@ import (
 type userManager interface {
 	ListUsers(ctx context.Context, page, perPage int, search string) (*auth.UserListResult, error)
 	DeleteUser(ctx context.Context, id string) error
+	SetUserDisabled(ctx context.Context, id string, disabled bool) error
+	LogoutUser(ctx context.Context, id string) (int64, error)
+	CountUsers(ctx context.Context, filter auth.UserFilter) (int64, error)
+	DeleteUsers(ctx context.Context, filter auth.UserFilter) (int64, error)
+	ExportUser(ctx context.Context, id string) (*auth.UserExport, error)
 }
 
 // handleAdminListUsers returns a paginated list of auth users.
@@ -35,16 +41,26 @@ func handleAdminListUsers(svc userManager) http.HandlerFunc {
 	}
 }
 
+// adminUserID returns the {id} URL parameter, writing a 400 response and
+// returning false if it is missing or not a UUID.
+func adminUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		httputil.WriteError(w, http.StatusBadRequest, "user id is required")
+		return "", false
+	}
+	if !httputil.IsValidUUID(id) {
+		httputil.WriteError(w, http.StatusBadRequest, "invalid user id format")
+		return "", false
+	}
+	return id, true
+}
+
 // handleAdminDeleteUser deletes a user by ID.
 func handleAdminDeleteUser(svc userManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id := chi.URLParam(r, "id")
-		if id == "" {
-			httputil.WriteError(w, http.StatusBadRequest, "user id is required")
-			return
-		}
-		if !httputil.IsValidUUID(id) {
-			httputil.WriteError(w, http.StatusBadRequest, "invalid user id format")
+		id, ok := adminUserID(w, r)
+		if !ok {
 			return
 		}
 
@@ -61,3 +77,117 @@ func handleAdminDeleteUser(svc userManager) http.HandlerFunc {
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// handleAdminSetUserDisabled disables a user (blocking login and signing them
+// out) or re-enables them.
+func handleAdminSetUserDisabled(svc userManager, disabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := adminUserID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := svc.SetUserDisabled(r.Context(), id, disabled); err != nil {
+			if errors.Is(err, auth.ErrUserNotFound) {
+				httputil.WriteError(w, http.StatusNotFound, "user not found")
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to update user")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAdminLogoutUser revokes all of a user's refresh tokens.
+func handleAdminLogoutUser(svc userManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := adminUserID(w, r)
+		if !ok {
+			return
+		}
+
+		n, err := svc.LogoutUser(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, auth.ErrUserNotFound) {
+				httputil.WriteError(w, http.StatusNotFound, "user not found")
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to log out user")
+			return
+		}
+
+		httputil.WriteJSON(w, http.StatusOK, map[string]int64{"revokedSessions": n})
+	}
+}
+
+// handleAdminExportUser returns everything stored about a user as one JSON
+// document, for data access requests.
+func handleAdminExportUser(svc userManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := adminUserID(w, r)
+		if !ok {
+			return
+		}
+
+		export, err := svc.ExportUser(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, auth.ErrUserNotFound) {
+				httputil.WriteError(w, http.StatusNotFound, "user not found")
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to export user")
+			return
+		}
+
+		httputil.WriteJSON(w, http.StatusOK, export)
+	}
+}
+
+type bulkDeleteUsersRequest struct {
+	Search        string     `json:"search"`
+	Disabled      bool       `json:"disabled"`
+	Unverified    bool       `json:"unverified"`
+	CreatedBefore *time.Time `json:"createdBefore"`
+	DryRun        bool       `json:"dryRun"`
+}
+
+type bulkDeleteUsersResponse struct {
+	Matched int64 `json:"matched"`
+	Deleted int64 `json:"deleted"`
+}
+
+// handleAdminBulkDeleteUsers deletes every user matching a filter. With
+// dryRun set it only reports how many users match.
+func handleAdminBulkDeleteUsers(svc userManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bulkDeleteUsersRequest
+		if !httputil.DecodeJSON(w, r, &req) {
+			return
+		}
+		filter := auth.UserFilter{Search: req.Search, Disabled: req.Disabled, Unverified: req.Unverified}
+		if req.CreatedBefore != nil {
+			filter.CreatedBefore = *req.CreatedBefore
+		}
+
+		var resp bulkDeleteUsersResponse
+		var err error
+		if req.DryRun {
+			resp.Matched, err = svc.CountUsers(r.Context(), filter)
+		} else {
+			resp.Deleted, err = svc.DeleteUsers(r.Context(), filter)
+			resp.Matched = resp.Deleted
+		}
+		if err != nil {
+			if errors.Is(err, auth.ErrValidation) {
+				httputil.WriteError(w, http.StatusBadRequest, "at least one filter is required")
+				return
+			}
+			httputil.WriteError(w, http.StatusInternalServerError, "failed to delete users")
+			return
+		}
+
+		httputil.WriteJSON(w, http.StatusOK, resp)
+	}
+}
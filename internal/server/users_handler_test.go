@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,10 +17,11 @@ import (
 
 // fakeUserManager is an in-memory fake for testing user management handlers.
 type fakeUserManager struct {
-	users   []auth.AdminUser
-	deleted []string
-	listErr error
-	delErr  error
+	users     []auth.AdminUser
+	deleted   []string
+	loggedOut []string
+	listErr   error
+	delErr    error
 }
 
 func (f *fakeUserManager) ListUsers(_ context.Context, page, perPage int, search string) (*auth.UserListResult, error) {
@@ -83,6 +85,78 @@ func (f *fakeUserManager) DeleteUser(_ context.Context, id string) error {
 	return auth.ErrUserNotFound
 }
 
+func (f *fakeUserManager) SetUserDisabled(_ context.Context, id string, disabled bool) error {
+	for i, u := range f.users {
+		if u.ID == id {
+			f.users[i].DisabledAt = nil
+			if disabled {
+				now := time.Now()
+				f.users[i].DisabledAt = &now
+			}
+			return nil
+		}
+	}
+	return auth.ErrUserNotFound
+}
+
+func (f *fakeUserManager) LogoutUser(_ context.Context, id string) (int64, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			f.loggedOut = append(f.loggedOut, id)
+			return 2, nil
+		}
+	}
+	return 0, auth.ErrUserNotFound
+}
+
+func (f *fakeUserManager) ExportUser(_ context.Context, id string) (*auth.UserExport, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return &auth.UserExport{
+				User: auth.ExportedUser{
+					User:          auth.User{ID: u.ID, Email: u.Email, CreatedAt: u.CreatedAt},
+					EmailVerified: u.EmailVerified,
+				},
+				APIKeys:  []auth.APIKey{{ID: "key-1", UserID: u.ID, Name: "ci", KeyPrefix: "ayb_abc"}},
+				Sessions: []auth.ExportedSession{{ID: "sess-1"}},
+			}, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func (f *fakeUserManager) matching(filter auth.UserFilter) ([]auth.AdminUser, error) {
+	if filter == (auth.UserFilter{}) {
+		return nil, fmt.Errorf("%w: at least one filter is required", auth.ErrValidation)
+	}
+	var matched []auth.AdminUser
+	for _, u := range f.users {
+		if (filter.Search == "" || contains(u.Email, filter.Search)) &&
+			(!filter.Disabled || u.DisabledAt != nil) &&
+			(!filter.Unverified || !u.EmailVerified) &&
+			(filter.CreatedBefore.IsZero() || u.CreatedAt.Before(filter.CreatedBefore)) {
+			matched = append(matched, u)
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeUserManager) CountUsers(_ context.Context, filter auth.UserFilter) (int64, error) {
+	matched, err := f.matching(filter)
+	return int64(len(matched)), err
+}
+
+func (f *fakeUserManager) DeleteUsers(_ context.Context, filter auth.UserFilter) (int64, error) {
+	matched, err := f.matching(filter)
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range matched {
+		f.deleted = append(f.deleted, u.ID)
+	}
+	return int64(len(matched)), nil
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && searchContains(s, substr)))
@@ -300,3 +374,111 @@ func TestListUsersResponseIncludesEmailVerified(t *testing.T) {
 	testutil.True(t, result.Items[0].EmailVerified, "alice should be verified")
 	testutil.True(t, !result.Items[1].EmailVerified, "bob should not be verified")
 }
+
+// --- Disable, logout and bulk delete tests ---
+
+func TestDisableAndEnableUser(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeUserManager{users: sampleUsers()}
+	r := chi.NewRouter()
+	r.Post("/api/admin/users/{id}/disable", handleAdminSetUserDisabled(mgr, true))
+	r.Post("/api/admin/users/{id}/enable", handleAdminSetUserDisabled(mgr, false))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/00000000-0000-0000-0000-000000000022/disable", nil))
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.NotNil(t, mgr.users[1].DisabledAt)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/00000000-0000-0000-0000-000000000022/enable", nil))
+	testutil.Equal(t, http.StatusNoContent, w.Code)
+	testutil.Nil(t, mgr.users[1].DisabledAt)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/00000000-0000-0000-0000-000000000099/disable", nil))
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/not-a-uuid/disable", nil))
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLogoutUser(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeUserManager{users: sampleUsers()}
+	r := chi.NewRouter()
+	r.Post("/api/admin/users/{id}/logout", handleAdminLogoutUser(mgr))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/00000000-0000-0000-0000-000000000021/logout", nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var body map[string]int64
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	testutil.Equal(t, int64(2), body["revokedSessions"])
+	testutil.Equal(t, "00000000-0000-0000-0000-000000000021", mgr.loggedOut[0])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/00000000-0000-0000-0000-000000000099/logout", nil))
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestExportUser(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeUserManager{users: sampleUsers()}
+	r := chi.NewRouter()
+	r.Get("/api/admin/users/{id}/export", handleAdminExportUser(mgr))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/users/00000000-0000-0000-0000-000000000021/export", nil))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	var body map[string]any
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	user := body["user"].(map[string]any)
+	testutil.Equal(t, "00000000-0000-0000-0000-000000000021", user["id"].(string))
+	testutil.SliceLen(t, body["apiKeys"].([]any), 1)
+	testutil.SliceLen(t, body["sessions"].([]any), 1)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/users/00000000-0000-0000-0000-000000000099/export", nil))
+	testutil.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/users/not-a-uuid/export", nil))
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBulkDeleteUsers(t *testing.T) {
+	t.Parallel()
+	mgr := &fakeUserManager{users: sampleUsers()}
+	handler := handleAdminBulkDeleteUsers(mgr)
+
+	do := func(body string) (*httptest.ResponseRecorder, bulkDeleteUsersResponse) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/users/bulk-delete", strings.NewReader(body)))
+		var resp bulkDeleteUsersResponse
+		if w.Code == http.StatusOK {
+			testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		}
+		return w, resp
+	}
+
+	// A dry run only counts.
+	w, resp := do(`{"unverified": true, "dryRun": true}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, int64(1), resp.Matched)
+	testutil.Equal(t, int64(0), resp.Deleted)
+	testutil.SliceLen(t, mgr.deleted, 0)
+
+	w, resp = do(`{"unverified": true}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, int64(1), resp.Deleted)
+	testutil.Equal(t, "00000000-0000-0000-0000-000000000022", mgr.deleted[0])
+
+	// Deleting everyone takes an explicit filter.
+	w, _ = do(`{}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "at least one filter is required")
+
+	w, _ = do(`{"createdBefore": "yesterday"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+}
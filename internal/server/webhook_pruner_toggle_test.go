@@ -27,6 +27,8 @@ func (f *fakeWebhookDispatcher) SetDeliveryStore(_ webhooks.DeliveryStore) {
 	f.setDeliveryStoreCalls++
 }
 
+func (f *fakeWebhookDispatcher) SetRecordFilter(_ webhooks.RecordFilter) {}
+
 func (f *fakeWebhookDispatcher) StartPruner(interval, retention time.Duration) {
 	f.startPrunerCalls++
 	f.startPrunerInterval = interval
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/httputil"
+)
+
+// whoamiResponse describes the authenticated caller. Type is one of the
+// auth.Principal* values or "admin" for the admin token. ExpiresAt is null
+// for credentials that don't expire.
+type whoamiResponse struct {
+	Type          string     `json:"type"`
+	Subject       string     `json:"subject"`
+	Email         string     `json:"email,omitempty"`
+	Scope         string     `json:"scope"`
+	AllowedTables []string   `json:"allowedTables,omitempty"`
+	AppID         string     `json:"appId,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+}
+
+// handleWhoami returns a handler for GET /api/auth/whoami, which reports
+// what the request is authenticated as. It accepts the admin token and
+// every credential auth.RequireAuth does.
+func (s *Server) handleWhoami(authSvc *auth.Service) http.Handler {
+	userHandler := auth.RequireAuth(authSvc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httputil.WriteJSON(w, http.StatusOK, whoamiFromClaims(auth.ClaimsFromContext(r.Context())))
+	}))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isAdminToken(r) {
+			httputil.SetAccessLogSubject(r.Context(), adminSubject)
+			httputil.WriteJSON(w, http.StatusOK, whoamiResponse{
+				Type:    adminSubject,
+				Subject: adminSubject,
+				Scope:   auth.ScopeFullAccess,
+			})
+			return
+		}
+		userHandler.ServeHTTP(w, r)
+	})
+}
+
+// whoamiFromClaims describes the caller identified by claims. User JWTs
+// carry no scope and are reported with full access.
+func whoamiFromClaims(claims *auth.Claims) whoamiResponse {
+	resp := whoamiResponse{
+		Type:          claims.PrincipalType(),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		Scope:         claims.APIKeyScope,
+		AllowedTables: claims.AllowedTables,
+		AppID:         claims.AppID,
+	}
+	if resp.Scope == "" {
+		resp.Scope = auth.ScopeFullAccess
+	}
+	if claims.ExpiresAt != nil {
+		t := claims.ExpiresAt.Time.UTC()
+		resp.ExpiresAt = &t
+	}
+	return resp
+}
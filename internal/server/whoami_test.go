@@ -0,0 +1,72 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+type whoamiBody struct {
+	Type      string     `json:"type"`
+	Subject   string     `json:"subject"`
+	Email     string     `json:"email"`
+	Scope     string     `json:"scope"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func doWhoami(t *testing.T, h http.Handler, token string) (*httptest.ResponseRecorder, whoamiBody) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/whoami", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	h.ServeHTTP(w, req)
+	var body whoamiBody
+	if w.Code == http.StatusOK {
+		testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	}
+	return w, body
+}
+
+func TestWhoamiUser(t *testing.T) {
+	t.Parallel()
+	srv, authSvc := newTestServerWithAuth(t, "testpass")
+	token, err := authSvc.IssueTestToken("user-1", "user@example.com")
+	testutil.NoError(t, err)
+
+	w, body := doWhoami(t, srv.Router(), token)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "user", body.Type)
+	testutil.Equal(t, "user-1", body.Subject)
+	testutil.Equal(t, "user@example.com", body.Email)
+	testutil.Equal(t, "*", body.Scope)
+	testutil.NotNil(t, body.ExpiresAt)
+	testutil.True(t, body.ExpiresAt.After(time.Now()), "expiry should be in the future, got %v", body.ExpiresAt)
+}
+
+func TestWhoamiAdmin(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServerWithAuth(t, "testpass")
+
+	w, body := doWhoami(t, srv.Router(), adminLogin(t, srv))
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "admin", body.Type)
+	testutil.Equal(t, "admin", body.Subject)
+	testutil.Equal(t, "*", body.Scope)
+	testutil.Nil(t, body.ExpiresAt)
+}
+
+func TestWhoamiUnauthenticated(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServerWithAuth(t, "testpass")
+
+	w, _ := doWhoami(t, srv.Router(), "")
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+	w, _ = doWhoami(t, srv.Router(), "not-a-token")
+	testutil.Equal(t, http.StatusUnauthorized, w.Code)
+}
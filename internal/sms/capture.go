@@ -25,19 +25,22 @@ func (c *CaptureProvider) Send(_ context.Context, to, body string) (*SendResult,
 	return &SendResult{Status: "captured"}, nil
 }
 
-// LastCode extracts a 4-8 digit OTP from the last captured SMS body.
+// otpPattern matches an OTP in an SMS body: 4-8 digits or uppercase
+// alphanumerics, optionally grouped with "-".
+var otpPattern = regexp.MustCompile(`\b([0-9A-Z]{2,4}-[0-9A-Z]{2,4}|[0-9A-Z]{4,8})\b`)
+
+// LastCode extracts the OTP, as sent, from the last captured SMS body.
 func (c *CaptureProvider) LastCode() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if len(c.Calls) == 0 {
 		return ""
 	}
-	re := regexp.MustCompile(`\b(\d{4,8})\b`)
-	matches := re.FindStringSubmatch(c.Calls[len(c.Calls)-1].Body)
-	if len(matches) < 2 {
+	matches := otpPattern.FindAllString(c.Calls[len(c.Calls)-1].Body, -1)
+	if len(matches) == 0 {
 		return ""
 	}
-	return matches[1]
+	return matches[len(matches)-1]
 }
 
 // Reset clears all recorded calls.
@@ -2,6 +2,7 @@ package sms
 
 import (
 	"errors"
+	"slices"
 
 	"github.com/nyaruka/phonenumbers"
 )
@@ -9,9 +10,52 @@ import (
 // ErrInvalidPhoneNumber is returned when a phone number cannot be parsed or validated.
 var ErrInvalidPhoneNumber = errors.New("invalid phone number")
 
+// Phone number types reported by ParsePhone.
+const (
+	PhoneTypeMobile           = "mobile"
+	PhoneTypeLandline         = "landline"
+	PhoneTypeLandlineOrMobile = "landline_or_mobile" // numbering plan doesn't distinguish (e.g. US, CA)
+	PhoneTypeTollFree         = "toll_free"
+	PhoneTypePremiumRate      = "premium_rate"
+	PhoneTypeVoIP             = "voip"
+	PhoneTypeOther            = "other"
+)
+
+var phoneTypes = map[phonenumbers.PhoneNumberType]string{
+	phonenumbers.FIXED_LINE:           PhoneTypeLandline,
+	phonenumbers.MOBILE:               PhoneTypeMobile,
+	phonenumbers.FIXED_LINE_OR_MOBILE: PhoneTypeLandlineOrMobile,
+	phonenumbers.TOLL_FREE:            PhoneTypeTollFree,
+	phonenumbers.PREMIUM_RATE:         PhoneTypePremiumRate,
+	phonenumbers.VOIP:                 PhoneTypeVoIP,
+}
+
+// PhoneInfo describes a validated phone number.
+type PhoneInfo struct {
+	E164    string
+	Country string // ISO 3166-1 alpha-2
+	Type    string // one of the PhoneType constants
+}
+
+// Landline reports whether the number is known to be a fixed line, which
+// cannot receive SMS.
+func (p PhoneInfo) Landline() bool {
+	return p.Type == PhoneTypeLandline
+}
+
 // NormalizePhone parses and validates a phone number using libphonenumber,
 // returning E.164 format. Requires a '+' prefix (no default region).
 func NormalizePhone(input string) (string, error) {
+	info, err := ParsePhone(input)
+	if err != nil {
+		return "", err
+	}
+	return info.E164, nil
+}
+
+// ParsePhone validates a phone number like NormalizePhone and also reports
+// its country and line type.
+func ParsePhone(input string) (PhoneInfo, error) {
 	// Pre-screen: only ASCII digits, '+', and formatting chars allowed.
 	// Reject non-ASCII, multiple '+' signs, or missing '+' prefix.
 	plusCount := 0
@@ -22,21 +66,29 @@ func NormalizePhone(input string) (string, error) {
 		case r >= '0' && r <= '9', r == ' ', r == '-', r == '(', r == ')', r == '.':
 			// ok
 		default:
-			return "", ErrInvalidPhoneNumber
+			return PhoneInfo{}, ErrInvalidPhoneNumber
 		}
 	}
 	if plusCount != 1 {
-		return "", ErrInvalidPhoneNumber
+		return PhoneInfo{}, ErrInvalidPhoneNumber
 	}
 
 	num, err := phonenumbers.Parse(input, "")
 	if err != nil {
-		return "", ErrInvalidPhoneNumber
+		return PhoneInfo{}, ErrInvalidPhoneNumber
 	}
 	if !phonenumbers.IsValidNumber(num) {
-		return "", ErrInvalidPhoneNumber
+		return PhoneInfo{}, ErrInvalidPhoneNumber
+	}
+	typ, ok := phoneTypes[phonenumbers.GetNumberType(num)]
+	if !ok {
+		typ = PhoneTypeOther
 	}
-	return phonenumbers.Format(num, phonenumbers.E164), nil
+	return PhoneInfo{
+		E164:    phonenumbers.Format(num, phonenumbers.E164),
+		Country: phonenumbers.GetRegionCodeForNumber(num),
+		Type:    typ,
+	}, nil
 }
 
 // PhoneCountry returns the ISO 3166-1 alpha-2 country code for an E.164
@@ -66,3 +118,13 @@ func IsAllowedCountry(phone string, allowed []string) bool {
 	}
 	return false
 }
+
+// IsBlockedCountry checks whether the phone's country matches one of the
+// blocked country codes. Unparseable phones are not treated as blocked;
+// callers reject them during normalization.
+func IsBlockedCountry(phone string, blocked []string) bool {
+	if len(blocked) == 0 {
+		return false
+	}
+	return slices.Contains(blocked, PhoneCountry(phone))
+}
@@ -105,6 +105,31 @@ func TestNormalizePhone_AcceptsGlobalNumbers(t *testing.T) {
 	}
 }
 
+func TestParsePhone(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		input, e164, country, typ string
+		landline                  bool
+	}{
+		{"+44 20 7946 0958", "+442079460958", "GB", PhoneTypeLandline, true},
+		{"+44 7400 123456", "+447400123456", "GB", PhoneTypeMobile, false},
+		{"+1 (415) 555-2671", "+14155552671", "US", PhoneTypeLandlineOrMobile, false},
+		{"+4915112345678", "+4915112345678", "DE", PhoneTypeMobile, false},
+	}
+	for _, c := range cases {
+		info, err := ParsePhone(c.input)
+		testutil.NoError(t, err)
+		testutil.Equal(t, c.e164, info.E164)
+		testutil.Equal(t, c.country, info.Country)
+		testutil.Equal(t, c.typ, info.Type)
+		testutil.Equal(t, c.landline, info.Landline())
+	}
+
+	if _, err := ParsePhone("+449999999999"); !errors.Is(err, ErrInvalidPhoneNumber) {
+		t.Errorf("ParsePhone: got %v, want ErrInvalidPhoneNumber", err)
+	}
+}
+
 // --- Phone country detection ---
 
 func TestPhoneCountry(t *testing.T) {
@@ -171,6 +196,19 @@ func TestIsAllowedCountry(t *testing.T) {
 	testutil.False(t, IsAllowedCountry("+14155552671", []string{"XX"}), "unknown code should block")
 }
 
+func TestIsBlockedCountry(t *testing.T) {
+	t.Parallel()
+	// Empty list blocks nothing.
+	testutil.False(t, IsBlockedCountry("+14155552671", nil), "empty list should block nothing")
+
+	blocked := []string{"IN", "DE"}
+	testutil.False(t, IsBlockedCountry("+14155552671", blocked), "US number should not be blocked")
+	testutil.False(t, IsBlockedCountry("+442079460958", blocked), "UK number should not be blocked")
+	testutil.True(t, IsBlockedCountry("+919876543210", blocked), "IN number should be blocked")
+	testutil.True(t, IsBlockedCountry("+4915112345678", blocked), "DE number should be blocked")
+	testutil.False(t, IsBlockedCountry("garbage", blocked), "unparseable phone is left to normalization")
+}
+
 func TestIsAllowedCountry_WithPhoneNumbers(t *testing.T) {
 	t.Parallel()
 	// US number allowed when only US in list.
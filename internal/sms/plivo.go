@@ -18,6 +18,8 @@ type PlivoProvider struct {
 	fromNumber string
 	baseURL    string
 	client     http.Client
+
+	senderOverrides
 }
 
 // NewPlivoProvider creates a PlivoProvider. If baseURL is empty, the Plivo
@@ -38,7 +40,7 @@ func (p *PlivoProvider) Send(ctx context.Context, to, body string) (*SendResult,
 	endpoint := fmt.Sprintf("%s/v1/Account/%s/Message/", p.baseURL, p.authID)
 
 	reqBody, err := json.Marshal(map[string]string{
-		"src":  p.fromNumber,
+		"src":  p.senderFor(to, p.fromNumber),
 		"dst":  to,
 		"text": body,
 	})
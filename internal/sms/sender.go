@@ -0,0 +1,30 @@
+package sms
+
+// SenderOverrider is implemented by providers that accept a "from" number or
+// alphanumeric sender ID, allowing it to vary by destination country.
+type SenderOverrider interface {
+	SetSenderOverrides(byCountry map[string]string)
+}
+
+// senderOverrides picks a per-country sender for a destination, for embedding
+// in providers that send with a configurable "from".
+type senderOverrides struct {
+	byCountry map[string]string // ISO 3166-1 alpha-2 → sender
+}
+
+// SetSenderOverrides sets the per-country senders used instead of the
+// provider's default "from" (e.g. an alphanumeric ID for GB numbers).
+func (s *senderOverrides) SetSenderOverrides(byCountry map[string]string) {
+	s.byCountry = byCountry
+}
+
+// senderFor returns the override for the country of to, or def when none matches.
+func (s *senderOverrides) senderFor(to, def string) string {
+	if len(s.byCountry) == 0 {
+		return def
+	}
+	if from := s.byCountry[PhoneCountry(to)]; from != "" {
+		return from
+	}
+	return def
+}
@@ -19,9 +19,13 @@ type Provider interface {
 // Config holds SMS verification settings.
 type Config struct {
 	CodeLength       int
+	CodeAlphabet     string // "numeric" (default) or "alphanumeric"
 	Expiry           time.Duration
 	MaxAttempts      int
 	DailyLimit       int
+	ResendCooldown   time.Duration // minimum gap between codes sent to one phone; 0 = none
 	AllowedCountries []string
+	BlockedCountries []string          // used instead of AllowedCountries; the two are mutually exclusive
 	TestPhoneNumbers map[string]string // phone → predetermined code (skip provider send)
+	StatusSecret     string            // HMAC key for delivery receipts; "" = receipts rejected
 }
@@ -17,6 +17,8 @@ type TelnyxProvider struct {
 	fromNumber string
 	baseURL    string
 	client     http.Client
+
+	senderOverrides
 }
 
 // NewTelnyxProvider creates a TelnyxProvider. If baseURL is empty, the Telnyx
@@ -36,7 +38,7 @@ func (p *TelnyxProvider) Send(ctx context.Context, to, body string) (*SendResult
 	endpoint := p.baseURL + "/v2/messages"
 
 	reqBody, err := json.Marshal(map[string]string{
-		"from": p.fromNumber,
+		"from": p.senderFor(to, p.fromNumber),
 		"to":   to,
 		"text": body,
 	})
@@ -82,3 +82,25 @@ func TestTelnyxSendNetworkError(t *testing.T) {
 func TestTelnyxImplementsInterface(t *testing.T) {
 	var _ sms.Provider = (*sms.TelnyxProvider)(nil)
 }
+
+func TestTelnyxSendSenderOverride(t *testing.T) {
+	var gotFrom string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqBody))
+		gotFrom = reqBody["from"]
+		w.Write([]byte(`{"data":{"id":"msg-telnyx-123"}}`))
+	}))
+	defer srv.Close()
+
+	p := sms.NewTelnyxProvider("TELNYX_API_KEY", "+15550000000", srv.URL)
+	p.SetSenderOverrides(map[string]string{"GB": "MyApp", "DE": "+4915123456789"})
+
+	_, err := p.Send(t.Context(), "+4915112345678", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "+4915123456789", gotFrom)
+
+	_, err = p.Send(t.Context(), "+15551234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "+15550000000", gotFrom)
+}
@@ -19,6 +19,8 @@ type TwilioProvider struct {
 	fromNumber string
 	baseURL    string
 	client     http.Client
+
+	senderOverrides
 }
 
 // NewTwilioProvider creates a TwilioProvider. If baseURL is empty, the Twilio
@@ -40,7 +42,7 @@ func (p *TwilioProvider) Send(ctx context.Context, to, body string) (*SendResult
 
 	form := url.Values{}
 	form.Set("To", to)
-	form.Set("From", p.fromNumber)
+	form.Set("From", p.senderFor(to, p.fromNumber))
 	form.Set("Body", body)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
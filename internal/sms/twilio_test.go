@@ -75,3 +75,22 @@ func TestTwilioSendNetworkError(t *testing.T) {
 func TestTwilioImplementsInterface(t *testing.T) {
 	var _ sms.Provider = (*sms.TwilioProvider)(nil)
 }
+
+func TestTwilioSendSenderOverride(t *testing.T) {
+	var gotFrom []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFrom = append(gotFrom, r.FormValue("From"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer srv.Close()
+
+	p := sms.NewTwilioProvider("ACtest", "token", "+15550000000", srv.URL)
+	p.SetSenderOverrides(map[string]string{"GB": "MyApp"})
+
+	_, err := p.Send(t.Context(), "+442079460958", "hello")
+	require.NoError(t, err)
+	_, err = p.Send(t.Context(), "+15551234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MyApp", "+15550000000"}, gotFrom)
+}
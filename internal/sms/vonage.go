@@ -19,6 +19,8 @@ type VonageProvider struct {
 	fromNumber string
 	baseURL    string
 	client     http.Client
+
+	senderOverrides
 }
 
 // NewVonageProvider creates a VonageProvider. If baseURL is empty, the Vonage
@@ -41,7 +43,7 @@ func (p *VonageProvider) Send(ctx context.Context, to, body string) (*SendResult
 	form := url.Values{}
 	form.Set("api_key", p.apiKey)
 	form.Set("api_secret", p.apiSecret)
-	form.Set("from", p.fromNumber)
+	form.Set("from", p.senderFor(to, p.fromNumber))
 	form.Set("to", to)
 	form.Set("text", body)
 
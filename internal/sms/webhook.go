@@ -13,6 +13,28 @@ import (
 	"time"
 )
 
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of a webhook body. It is
+// set on outgoing webhook sends and checked on incoming delivery receipts.
+const SignatureHeader = "X-Webhook-Signature"
+
+// Sign returns the hex-encoded HMAC-SHA256 of body keyed by secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether sig is the valid signature of body under
+// secret, using a constant-time comparison.
+func VerifySignature(secret string, body []byte, sig string) bool {
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(Sign(secret, body))
+	return hmac.Equal(got, want)
+}
+
 // WebhookProvider sends SMS by POSTing to a custom webhook URL with HMAC signing.
 type WebhookProvider struct {
 	url    string
@@ -38,17 +60,12 @@ func (p *WebhookProvider) Send(ctx context.Context, to, body string) (*SendResul
 		return nil, fmt.Errorf("webhook: marshal request: %w", err)
 	}
 
-	// Compute HMAC-SHA256 signature
-	mac := hmac.New(sha256.New, []byte(p.secret))
-	mac.Write(reqBody)
-	sig := hex.EncodeToString(mac.Sum(nil))
-
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("webhook: build request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", sig)
+	req.Header.Set(SignatureHeader, Sign(p.secret, reqBody))
 
 	resp, err := p.client.Do(req)
 	if err != nil {
@@ -102,3 +102,14 @@ func TestWebhookSendNetworkError(t *testing.T) {
 func TestWebhookImplementsInterface(t *testing.T) {
 	var _ sms.Provider = (*sms.WebhookProvider)(nil)
 }
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"message_id":"m1","status":"delivered"}`)
+	sig := sms.Sign("s3cret", body)
+
+	assert.True(t, sms.VerifySignature("s3cret", body, sig))
+	assert.False(t, sms.VerifySignature("other", body, sig), "wrong secret")
+	assert.False(t, sms.VerifySignature("s3cret", []byte(`{"status":"failed"}`), sig), "tampered body")
+	assert.False(t, sms.VerifySignature("s3cret", body, ""), "missing signature")
+	assert.False(t, sms.VerifySignature("s3cret", body, "not-hex"), "malformed signature")
+}
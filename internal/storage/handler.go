@@ -6,6 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -17,22 +18,40 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// Upload limits beyond storage.max_file_size.
+const (
+	// DefaultMultipartMemory is how much of an upload is parsed in memory;
+	// the rest goes to a temporary file.
+	DefaultMultipartMemory = 1 << 20
+	// maxMultipartOverhead is the room in an upload body for the multipart
+	// headers and the name field around a file of the maximum size.
+	maxMultipartOverhead = 64 << 10
+)
+
 // Handler serves storage HTTP endpoints.
 type Handler struct {
-	svc         *Service
-	logger      *slog.Logger
-	maxFileSize int64
+	svc             *Service
+	logger          *slog.Logger
+	maxFileSize     int64
+	multipartMemory int64
 }
 
 // NewHandler creates a new storage handler.
 func NewHandler(svc *Service, logger *slog.Logger, maxFileSize int64) *Handler {
 	return &Handler{
-		svc:         svc,
-		logger:      logger,
-		maxFileSize: maxFileSize,
+		svc:             svc,
+		logger:          logger,
+		maxFileSize:     maxFileSize,
+		multipartMemory: DefaultMultipartMemory,
 	}
 }
 
+// SetMultipartMemory sets how many bytes of an upload are parsed in memory
+// before the rest is written to a temporary file.
+func (h *Handler) SetMultipartMemory(n int64) {
+	h.multipartMemory = n
+}
+
 // Routes returns a chi.Router with storage endpoints mounted.
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
@@ -74,11 +93,16 @@ func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	bucket := chi.URLParam(r, "bucket")
 
-	// Limit request body size.
-	r.Body = http.MaxBytesReader(w, r.Body, h.maxFileSize)
-
-	if err := r.ParseMultipartForm(h.maxFileSize); err != nil {
-		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, "invalid multipart form or file too large",
+	// Limit request body size, and how much of it is held in memory.
+	if !httputil.LimitBodyTo(w, r, h.maxFileSize+maxMultipartOverhead) {
+		return
+	}
+	if err := r.ParseMultipartForm(h.multipartMemory); err != nil {
+		if httputil.IsBodyTooLarge(err) || errors.Is(err, multipart.ErrMessageTooLarge) {
+			h.writeFileTooLarge(w)
+			return
+		}
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, "invalid multipart form",
 			"https://allyourbase.io/guide/file-storage")
 		return
 	}
@@ -90,6 +114,10 @@ func (h *Handler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer file.Close()
+	if header.Size > h.maxFileSize {
+		h.writeFileTooLarge(w)
+		return
+	}
 
 	// Use provided name or fall back to uploaded filename.
 	name := r.FormValue("name")
@@ -129,6 +157,14 @@ func (h *Handler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	httputil.WriteJSON(w, http.StatusCreated, obj)
 }
 
+// writeFileTooLarge writes the 413 response for an upload over
+// storage.max_file_size.
+func (h *Handler) writeFileTooLarge(w http.ResponseWriter) {
+	httputil.WriteErrorWithDocURL(w, http.StatusRequestEntityTooLarge,
+		"file too large: max "+strconv.FormatInt(h.maxFileSize, 10)+" bytes",
+		"https://allyourbase.io/guide/file-storage#upload-limits")
+}
+
 func (h *Handler) HandleServe(w http.ResponseWriter, r *http.Request) {
 	bucket := chi.URLParam(r, "bucket")
 	name := chi.URLParam(r, "*")
@@ -85,9 +85,13 @@ func TestHandleUploadMissingFile(t *testing.T) {
 	rec := httptest.NewRecorder()
 	router.ServeHTTP(rec, req)
 	testutil.Equal(t, http.StatusBadRequest, rec.Code)
-	var errResp map[string]any
+	var errResp struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
 	testutil.NoError(t, json.NewDecoder(rec.Body).Decode(&errResp))
-	testutil.Contains(t, errResp["message"].(string), `missing "file" field`)
+	testutil.Contains(t, errResp.Error.Message, `missing "file" field`)
 }
 
 func TestHandleUploadInvalidBucket(t *testing.T) {
@@ -122,8 +126,10 @@ func TestHandleSignedURLInvalid(t *testing.T) {
 
 	var resp map[string]any
 	testutil.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
-	msg, ok := resp["message"].(string)
-	testutil.True(t, ok, "response should contain a 'message' string field")
+	body, ok := resp["error"].(map[string]any)
+	testutil.True(t, ok, "response should contain an 'error' object")
+	msg, ok := body["message"].(string)
+	testutil.True(t, ok, "error should contain a 'message' string field")
 	testutil.Contains(t, msg, "invalid or expired signed URL")
 }
 
@@ -155,6 +161,65 @@ func TestHandleUploadNoContentType(t *testing.T) {
 	testutil.Contains(t, rec.Body.String(), "invalid multipart form")
 }
 
+func TestHandleUploadFileTooLarge(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(newTestService(), testutil.DiscardLogger(), 1<<10)
+	router := testRouter(h)
+
+	upload := func(size int, chunked bool) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		fw, _ := w.CreateFormFile("file", "big.bin")
+		fw.Write(bytes.Repeat([]byte("x"), size))
+		w.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/storage/images", body)
+		if chunked {
+			req.ContentLength = -1
+		}
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name    string
+		size    int
+		chunked bool
+	}{
+		{"file just over the limit", 1<<10 + 1, false},
+		{"Content-Length over the body limit", 1 << 20, false},
+		{"chunked body over the body limit", 1 << 20, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := upload(tt.size, tt.chunked)
+			testutil.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		})
+	}
+}
+
+func TestHandleUploadSmallMultipartMemory(t *testing.T) {
+	t.Parallel()
+	h := NewHandler(newTestService(), testutil.DiscardLogger(), 10<<20)
+	h.SetMultipartMemory(16)
+	router := testRouter(h)
+
+	// A file larger than the memory cap is parsed through a temporary file
+	// and reaches the bucket check.
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	fw, _ := w.CreateFormFile("file", "test.txt")
+	fw.Write(bytes.Repeat([]byte("x"), 1<<10))
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/storage/INVALID", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	testutil.Equal(t, http.StatusBadRequest, rec.Code)
+	testutil.Contains(t, rec.Body.String(), "invalid bucket name")
+}
+
 // Note: Tests that exercise full upload/serve/delete/list flows (which require
 // database metadata operations) belong in integration tests with a real DB.
 // See storage_integration_test.go (requires TEST_DATABASE_URL).
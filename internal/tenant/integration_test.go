@@ -0,0 +1,166 @@
+//go:build integration
+
+package tenant_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/config"
+	"github.com/allyourbase/ayb/internal/migrations"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/server"
+	"github.com/allyourbase/ayb/internal/tenant"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "test-secret-that-is-at-least-32-chars!!"
+
+var sharedPG *testutil.PGContainer
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	pg, cleanup := testutil.StartPostgresForTestMain(ctx)
+	sharedPG = pg
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+// setupTemplate resets the database, runs the system migrations, and applies
+// the user migrations in dir to public, the template tenants mirror.
+func setupTemplate(t *testing.T, ctx context.Context, dir string) {
+	t.Helper()
+	_, err := sharedPG.Pool.Exec(ctx, `
+		DROP SCHEMA IF EXISTS ayb_tenant_acme CASCADE;
+		DROP SCHEMA IF EXISTS ayb_tenant_globex CASCADE;
+		DROP SCHEMA public CASCADE;
+		CREATE SCHEMA public`)
+	testutil.NoError(t, err)
+
+	logger := testutil.DiscardLogger()
+	runner := migrations.NewRunner(sharedPG.Pool, logger)
+	testutil.NoError(t, runner.Bootstrap(ctx))
+	_, err = runner.Run(ctx)
+	testutil.NoError(t, err)
+
+	userRunner := migrations.NewUserRunner(sharedPG.Pool, dir, logger)
+	testutil.NoError(t, userRunner.Bootstrap(ctx))
+	_, err = userRunner.Up(ctx)
+	testutil.NoError(t, err)
+}
+
+func writeMigration(t *testing.T, dir, name, sql string) {
+	t.Helper()
+	testutil.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(sql), 0o644))
+}
+
+func TestTenantIsolation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeMigration(t, dir, "001_posts.sql",
+		`CREATE TABLE posts (id SERIAL PRIMARY KEY, title TEXT NOT NULL)`)
+	setupTemplate(t, ctx, dir)
+
+	logger := testutil.DiscardLogger()
+	ch := schema.NewCacheHolder(sharedPG.Pool, logger)
+	testutil.NoError(t, ch.Load(ctx))
+	// Tenant schemas are never introspected; only the template is.
+	testutil.NotNil(t, ch.Get().Tables["public.posts"])
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWTSecret = testJWTSecret
+	cfg.Tenants.Enabled = true
+	cfg.Database.MigrationsDir = dir
+	authSvc := auth.NewService(sharedPG.Pool, testJWTSecret, time.Hour, 7*24*time.Hour, 8, logger)
+	authSvc.SetTenantClaim(cfg.Tenants.Claim)
+	srv := server.New(cfg, logger, ch, sharedPG.Pool, authSvc, nil)
+
+	svc := tenant.NewService(sharedPG.Pool, dir, logger)
+	for _, id := range []string{"acme", "globex"} {
+		_, err := svc.CreateTenant(ctx, id)
+		testutil.NoError(t, err)
+	}
+	_, err := svc.CreateTenant(ctx, "acme")
+	testutil.True(t, errors.Is(err, tenant.ErrExists), "duplicate tenant should be ErrExists")
+
+	tokenFor := func(tenantID string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":       "00000000-0000-0000-0000-000000000001",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+			"tenant_id": tenantID,
+		}).SignedString([]byte(testJWTSecret))
+		testutil.NoError(t, err)
+		return token
+	}
+	do := func(method, token string, body any) *httptest.ResponseRecorder {
+		var b []byte
+		if body != nil {
+			b, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, "/api/collections/posts/", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		srv.Router().ServeHTTP(w, req)
+		return w
+	}
+	titles := func(tenantID string) []string {
+		w := do(http.MethodGet, tokenFor(tenantID), nil)
+		testutil.StatusCode(t, http.StatusOK, w.Code)
+		var list struct {
+			Items []map[string]any `json:"items"`
+		}
+		testutil.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		var out []string
+		for _, item := range list.Items {
+			out = append(out, item["title"].(string))
+		}
+		return out
+	}
+
+	w := do(http.MethodPost, tokenFor("acme"), map[string]any{"title": "acme post"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+	w = do(http.MethodPost, tokenFor("globex"), map[string]any{"title": "globex post"})
+	testutil.StatusCode(t, http.StatusCreated, w.Code)
+
+	acme := titles("acme")
+	testutil.SliceLen(t, acme, 1)
+	testutil.Equal(t, "acme post", acme[0])
+	globex := titles("globex")
+	testutil.SliceLen(t, globex, 1)
+	testutil.Equal(t, "globex post", globex[0])
+
+	// Rows landed in the tenant schemas, not the template.
+	var n int
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, "SELECT count(*) FROM public.posts").Scan(&n))
+	testutil.Equal(t, 0, n)
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, "SELECT count(*) FROM ayb_tenant_acme.posts").Scan(&n))
+	testutil.Equal(t, 1, n)
+
+	// Unknown tenants are refused.
+	w = do(http.MethodGet, tokenFor("initech"), nil)
+	testutil.StatusCode(t, http.StatusForbidden, w.Code)
+
+	// New user migrations reach every tenant.
+	writeMigration(t, dir, "002_posts_body.sql", `ALTER TABLE posts ADD COLUMN body TEXT`)
+	applied, err := svc.MigrateTenants(ctx)
+	testutil.NoError(t, err)
+	testutil.Equal(t, 2, applied)
+	testutil.NoError(t, sharedPG.Pool.QueryRow(ctx, `
+		SELECT count(*) FROM information_schema.columns
+		WHERE table_name = 'posts' AND column_name = 'body'
+		  AND table_schema IN ('ayb_tenant_acme', 'ayb_tenant_globex')`).Scan(&n))
+	testutil.Equal(t, 2, n)
+}
@@ -0,0 +1,193 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/migrations"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Service provisions tenants and looks them up.
+type Service struct {
+	pool   *pgxpool.Pool
+	runner *migrations.UserRunner
+	logger *slog.Logger
+	known  sync.Map // tenant IDs seen to exist; tenants are never removed
+}
+
+// NewService creates a tenant service whose schemas are built from the user
+// migrations in migrationsDir.
+func NewService(pool *pgxpool.Pool, migrationsDir string, logger *slog.Logger) *Service {
+	return &Service{
+		pool:   pool,
+		runner: migrations.NewUserRunner(pool, migrationsDir, logger),
+		logger: logger,
+	}
+}
+
+// CreateTenant registers tenant id, creates its schema, and runs the user
+// migrations into it, all in one transaction.
+func (s *Service) CreateTenant(ctx context.Context, id string) (*Tenant, error) {
+	if !ValidID(id) {
+		return nil, ErrInvalidID
+	}
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var t Tenant
+	err = tx.QueryRow(ctx,
+		`INSERT INTO _ayb_tenants (id, schema_name) VALUES ($1, $2)
+		 RETURNING id, schema_name, created_at`,
+		id, SchemaName(id),
+	).Scan(&t.ID, &t.Schema, &t.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("inserting tenant: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "CREATE SCHEMA "+pgx.Identifier{t.Schema}.Sanitize()); err != nil {
+		return nil, fmt.Errorf("creating schema %s: %w", t.Schema, err)
+	}
+	applied, err := s.migrate(ctx, tx, t.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing tenant: %w", err)
+	}
+
+	s.known.Store(id, struct{}{})
+	s.logger.Info("created tenant", "id", id, "schema", t.Schema, "migrations", applied)
+	return &t, nil
+}
+
+// Exists reports whether tenant id has been provisioned.
+func (s *Service) Exists(ctx context.Context, id string) (bool, error) {
+	if _, ok := s.known.Load(id); ok {
+		return true, nil
+	}
+	var exists bool
+	err := s.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM _ayb_tenants WHERE id = $1)", id,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("looking up tenant: %w", err)
+	}
+	if exists {
+		s.known.Store(id, struct{}{})
+	}
+	return exists, nil
+}
+
+// ListTenants returns all tenants ordered by ID.
+func (s *Service) ListTenants(ctx context.Context) ([]Tenant, error) {
+	rows, err := s.pool.Query(ctx,
+		"SELECT id, schema_name, created_at FROM _ayb_tenants ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("querying tenants: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []Tenant{}
+	for rows.Next() {
+		var t Tenant
+		if err := rows.Scan(&t.ID, &t.Schema, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// MigrateTenants applies pending user migrations to every tenant's schema,
+// one transaction per tenant, and returns the number applied in total.
+func (s *Service) MigrateTenants(ctx context.Context) (int, error) {
+	tenants, err := s.ListTenants(ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, t := range tenants {
+		n, err := s.migrateTenant(ctx, t)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("migrating tenant %s: %w", t.ID, err)
+		}
+	}
+	return total, nil
+}
+
+func (s *Service) migrateTenant(ctx context.Context, t Tenant) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	applied, err := s.migrate(ctx, tx, t.Schema)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// migrate runs pending user migrations into schemaName and grants the
+// authenticated role access to what they created.
+func (s *Service) migrate(ctx context.Context, tx pgx.Tx, schemaName string) (int, error) {
+	applied, err := s.runner.UpInSchema(ctx, tx, schemaName)
+	if err != nil {
+		return 0, err
+	}
+	if err := grantAuthenticated(ctx, tx, schemaName); err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// grantAuthenticated gives the role API requests switch to for RLS the same
+// kind of access to a tenant schema as it typically has to public. It does
+// nothing if the role doesn't exist.
+func grantAuthenticated(ctx context.Context, tx pgx.Tx, schemaName string) error {
+	var exists bool
+	if err := tx.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM pg_roles WHERE rolname = $1)", auth.AuthenticatedRole,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("checking role %s: %w", auth.AuthenticatedRole, err)
+	}
+	if !exists {
+		return nil
+	}
+	for _, stmt := range grantStatements(schemaName) {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("granting access to %s: %w", schemaName, err)
+		}
+	}
+	return nil
+}
+
+// grantStatements returns the GRANT statements grantAuthenticated runs.
+func grantStatements(schemaName string) []string {
+	s := pgx.Identifier{schemaName}.Sanitize()
+	role := pgx.Identifier{auth.AuthenticatedRole}.Sanitize()
+	return []string{
+		"GRANT USAGE ON SCHEMA " + s + " TO " + role,
+		"GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA " + s + " TO " + role,
+		"GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA " + s + " TO " + role,
+		"GRANT EXECUTE ON ALL FUNCTIONS IN SCHEMA " + s + " TO " + role,
+	}
+}
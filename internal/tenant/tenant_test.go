@@ -0,0 +1,112 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/allyourbase/ayb/internal/auth"
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"acme", true},
+		{"acme_2", true},
+		{"42", true},
+		{"", false},
+		{"_acme", false},
+		{"Acme", false},
+		{"acme-co", false},
+		{`acme"; DROP SCHEMA public; --`, false},
+		{"a23456789012345678901234567890123456789012345678", true},
+		{"a234567890123456789012345678901234567890123456789", false},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, ValidID(tt.id))
+	}
+}
+
+func TestTableRedirect(t *testing.T) {
+	t.Parallel()
+	posts := &schema.Table{Schema: "public", Name: "posts"}
+	audit := &schema.Table{Schema: "audit", Name: "log"}
+
+	ctx := context.Background()
+	testutil.True(t, Table(ctx, posts) == posts, "no tenant should keep the table")
+
+	ctx = WithID(ctx, "acme")
+	testutil.Equal(t, "acme", FromContext(ctx))
+	got := Table(ctx, posts)
+	testutil.Equal(t, "ayb_tenant_acme", got.Schema)
+	testutil.Equal(t, "posts", got.Name)
+	testutil.Equal(t, "public", posts.Schema) // the cached table is untouched
+	testutil.True(t, Table(ctx, audit) == audit, "non-public schemas are shared")
+
+	fn := Function(ctx, &schema.Function{Schema: "public", Name: "search"})
+	testutil.Equal(t, "ayb_tenant_acme", fn.Schema)
+}
+
+func TestSearchPathStatement(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, `SET LOCAL search_path TO "ayb_tenant_acme", public`, searchPathStatement("acme"))
+}
+
+func TestGrantStatements(t *testing.T) {
+	t.Parallel()
+	stmts := grantStatements("ayb_tenant_acme")
+	testutil.SliceLen(t, stmts, 4)
+	testutil.Equal(t, `GRANT USAGE ON SCHEMA "ayb_tenant_acme" TO "ayb_authenticated"`, stmts[0])
+}
+
+type fakeChecker struct {
+	tenants map[string]bool
+	err     error
+}
+
+func (f fakeChecker) Exists(_ context.Context, id string) (bool, error) {
+	return f.tenants[id], f.err
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	const secret = "test-secret-that-is-at-least-32-chars!!"
+	svc := auth.NewService(nil, secret, time.Hour, time.Hour, 8, testutil.DiscardLogger())
+	svc.SetTenantClaim("tenant_id")
+	claimsFor := func(tenantID any) *auth.Claims {
+		mc := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+		if tenantID != nil {
+			mc["tenant_id"] = tenantID
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, mc).SignedString([]byte(secret))
+		testutil.NoError(t, err)
+		claims, err := svc.ValidateToken(token)
+		testutil.NoError(t, err)
+		return claims
+	}
+	ctx := context.Background()
+	c := fakeChecker{tenants: map[string]bool{"acme": true}}
+
+	id, err := Resolve(ctx, c, claimsFor("acme"))
+	testutil.NoError(t, err)
+	testutil.Equal(t, "acme", id)
+
+	_, err = Resolve(ctx, c, claimsFor(nil))
+	testutil.True(t, errors.Is(err, ErrNoTenant), "missing claim should be ErrNoTenant")
+
+	_, err = Resolve(ctx, c, claimsFor("globex"))
+	testutil.True(t, errors.Is(err, ErrNotFound), "unknown tenant should be ErrNotFound")
+
+	_, err = Resolve(ctx, c, claimsFor("../public"))
+	testutil.True(t, errors.Is(err, ErrNotFound), "invalid id should be ErrNotFound")
+
+	_, err = Resolve(ctx, fakeChecker{err: errors.New("db down")}, claimsFor("acme"))
+	testutil.ErrorContains(t, err, "db down")
+}
@@ -0,0 +1,172 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// clientRuntime is the framework-agnostic, fetch-based runtime shared by every
+// generated collection. It mirrors the REST API under /api/collections.
+const clientRuntime = `// Typed API client
+
+export interface ListParams {
+  page?: number;
+  perPage?: number;
+  sort?: string;
+  filter?: string;
+  search?: string;
+  fields?: string;
+  expand?: string;
+  skipTotal?: boolean;
+}
+
+export interface GetParams {
+  fields?: string;
+  expand?: string;
+}
+
+export interface ListResponse<T> {
+  page: number;
+  perPage: number;
+  totalItems: number;
+  totalPages: number;
+  items: T[];
+}
+
+export interface AybClientOptions {
+  /** Server base URL, e.g. "http://localhost:8090". */
+  baseURL: string;
+  /** Bearer token (JWT or API key), or a function returning the current one. */
+  token?: string | (() => string | null | undefined);
+  /** Custom fetch implementation (defaults to globalThis.fetch). */
+  fetch?: typeof fetch;
+}
+
+export class AybError extends Error {
+  readonly status: number;
+  /** Stable error code, e.g. "not_found" or "validation_failed". */
+  readonly code?: string;
+  /** Error details, such as per-field validation errors. */
+  readonly data?: Record<string, unknown>;
+
+  constructor(status: number, message: string, data?: Record<string, unknown>, code?: string) {
+    super(message);
+    this.name = "AybError";
+    this.status = status;
+    this.code = code;
+    this.data = data;
+  }
+}
+
+type RecordID = string | number;
+
+function toQuery(params?: object): string {
+  if (!params) return "";
+  const qs = new URLSearchParams();
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== null && value !== "") qs.set(key, String(value));
+  }
+  const s = qs.toString();
+  return s ? "?" + s : "";
+}
+
+export class Collection<Row, Insert, Update> {
+  private readonly client: AybClient;
+  private readonly path: string;
+
+  constructor(client: AybClient, table: string) {
+    this.client = client;
+    this.path = "/api/collections/" + encodeURIComponent(table);
+  }
+
+  list(params?: ListParams): Promise<ListResponse<Row>> {
+    return this.client.request("GET", this.path + "/" + toQuery(params));
+  }
+
+  get(id: RecordID, params?: GetParams): Promise<Row> {
+    return this.client.request("GET", this.path + "/" + encodeURIComponent(String(id)) + toQuery(params));
+  }
+
+  create(data: Insert): Promise<Row> {
+    return this.client.request("POST", this.path + "/", data);
+  }
+
+  update(id: RecordID, data: Update): Promise<Row> {
+    return this.client.request("PATCH", this.path + "/" + encodeURIComponent(String(id)), data);
+  }
+
+  delete(id: RecordID): Promise<void> {
+    return this.client.request("DELETE", this.path + "/" + encodeURIComponent(String(id)));
+  }
+}
+
+`
+
+// writeClient emits the client runtime and an AybClient class exposing one
+// typed Collection property per table, using the generated row, Create, and
+// Update types.
+func writeClient(b *strings.Builder, tables []*schema.Table) {
+	b.WriteString(clientRuntime)
+
+	b.WriteString("export class AybClient {\n")
+	b.WriteString("  private readonly baseURL: string;\n")
+	b.WriteString("  private readonly token?: AybClientOptions[\"token\"];\n")
+	b.WriteString("  private readonly fetchFn: typeof fetch;\n\n")
+	for _, t := range tables {
+		name := pascalCase(t.Name)
+		fmt.Fprintf(b, "  readonly %s: Collection<%s, %sCreate, %sUpdate>;\n", camelCase(t.Name), name, name, name)
+	}
+	if len(tables) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("  constructor(options: AybClientOptions) {\n")
+	b.WriteString("    this.baseURL = options.baseURL.replace(/\\/+$/, \"\");\n")
+	b.WriteString("    this.token = options.token;\n")
+	b.WriteString("    this.fetchFn = options.fetch ?? globalThis.fetch.bind(globalThis);\n")
+	for _, t := range tables {
+		fmt.Fprintf(b, "    this.%s = new Collection(this, %q);\n", camelCase(t.Name), t.Name)
+	}
+	b.WriteString("  }\n\n")
+
+	b.WriteString(`  async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers: Record<string, string> = {};
+    const token = typeof this.token === "function" ? this.token() : this.token;
+    if (token) headers["Authorization"] = "Bearer " + token;
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+
+    const res = await this.fetchFn(this.baseURL + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (res.status === 204) return undefined as T;
+
+    const payload = await res.json().catch(() => null);
+    if (!res.ok) {
+      // Errors are {error: {code, message, details}}; older servers sent
+      // {code, message, data} at the top level.
+      const err = payload && typeof payload.error === "object" && payload.error !== null ? payload.error : payload;
+      const message = err && typeof err.message === "string" ? err.message : res.statusText;
+      const code = err && typeof err.code === "string" ? err.code : undefined;
+      throw new AybError(res.status, message, err?.details ?? err?.data, code);
+    }
+    return payload as T;
+  }
+}
+`)
+}
+
+// camelCase converts a snake_case name to camelCase for use as a property name.
+func camelCase(s string) string {
+	p := pascalCase(s)
+	if p == "" {
+		return p
+	}
+	r := []rune(p)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
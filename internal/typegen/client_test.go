@@ -0,0 +1,83 @@
+package typegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func clientTestCache() *schema.SchemaCache {
+	return newCache(map[string]*schema.Table{
+		"public.blog_posts": {
+			Schema: "public", Name: "blog_posts", Kind: "table",
+			Columns: []*schema.Column{
+				{Name: "id", Position: 1, JSONType: "integer", IsPrimaryKey: true, DefaultExpr: "nextval('blog_posts_id_seq')"},
+				{Name: "title", Position: 2, JSONType: "string"},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		"public.tags": {
+			Schema: "public", Name: "tags", Kind: "table",
+			Columns: []*schema.Column{{Name: "name", Position: 1, JSONType: "string"}},
+		},
+	})
+}
+
+func TestTypeScriptWithoutClientHasNoRuntime(t *testing.T) {
+	t.Parallel()
+	out := TypeScript(clientTestCache())
+	testutil.False(t, strings.Contains(out, "class AybClient"), "default output should not include the client")
+}
+
+func TestTypeScriptClientRuntime(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(clientTestCache(), TypeScriptOptions{Client: true})
+
+	testutil.Contains(t, out, "export interface ListParams {")
+	testutil.Contains(t, out, "export interface ListResponse<T> {")
+	testutil.Contains(t, out, "export class AybError extends Error {")
+	testutil.Contains(t, out, "  readonly code?: string;")
+	testutil.Contains(t, out, "export class Collection<Row, Insert, Update> {")
+	testutil.Contains(t, out, `this.path = "/api/collections/" + encodeURIComponent(table);`)
+	testutil.Contains(t, out, "list(params?: ListParams): Promise<ListResponse<Row>> {")
+	testutil.Contains(t, out, "create(data: Insert): Promise<Row> {")
+	testutil.Contains(t, out, "update(id: RecordID, data: Update): Promise<Row> {")
+	testutil.Contains(t, out, "delete(id: RecordID): Promise<void> {")
+	testutil.False(t, strings.Contains(out, "zod"), "client alone should not import zod")
+}
+
+func TestTypeScriptClientReadsErrorEnvelope(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(clientTestCache(), TypeScriptOptions{Client: true})
+
+	// {error: {code, message, details}}, falling back to the old top-level
+	// {message, data} shape.
+	testutil.Contains(t, out, `const err = payload && typeof payload.error === "object" && payload.error !== null ? payload.error : payload;`)
+	testutil.Contains(t, out, `const message = err && typeof err.message === "string" ? err.message : res.statusText;`)
+	testutil.Contains(t, out, `const code = err && typeof err.code === "string" ? err.code : undefined;`)
+	testutil.Contains(t, out, "throw new AybError(res.status, message, err?.details ?? err?.data, code);")
+	testutil.False(t, strings.Contains(out, "payload.message"), "client should not read the message from the top level")
+}
+
+func TestTypeScriptClientCollections(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(clientTestCache(), TypeScriptOptions{Client: true})
+
+	testutil.Contains(t, out, "  readonly blogPosts: Collection<BlogPosts, BlogPostsCreate, BlogPostsUpdate>;")
+	testutil.Contains(t, out, "  readonly tags: Collection<Tags, TagsCreate, TagsUpdate>;")
+	testutil.Contains(t, out, `    this.blogPosts = new Collection(this, "blog_posts");`)
+	testutil.Contains(t, out, `    this.tags = new Collection(this, "tags");`)
+
+	// Client comes after the interfaces it references.
+	testutil.True(t, strings.Index(out, "export interface BlogPosts {") < strings.Index(out, "export class AybClient {"),
+		"interfaces should precede the client")
+}
+
+func TestCamelCase(t *testing.T) {
+	t.Parallel()
+	testutil.Equal(t, "blogPosts", camelCase("blog_posts"))
+	testutil.Equal(t, "users", camelCase("users"))
+	testutil.Equal(t, "", camelCase(""))
+}
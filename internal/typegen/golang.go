@@ -0,0 +1,124 @@
+package typegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// Go generates a Go source file from a schema cache: a named string type with
+// constants per PostgreSQL enum and a struct per table with json and db tags.
+// Nullable columns become pointers. System tables (prefixed _ayb_) are excluded.
+func Go(sc *schema.SchemaCache, pkg string) (string, error) {
+	if pkg == "" {
+		pkg = "models"
+	}
+	tables := userTables(sc)
+	enums := collectEnums(tables)
+
+	var body strings.Builder
+	imports := map[string]bool{}
+
+	for _, e := range enums {
+		enumName := goIdent(e.name)
+		fmt.Fprintf(&body, "type %s string\n\n", enumName)
+		body.WriteString("const (\n")
+		for _, v := range e.values {
+			fmt.Fprintf(&body, "\t%s%s %s = %q\n", enumName, goIdent(v), enumName, v)
+		}
+		body.WriteString(")\n\n")
+	}
+
+	for _, t := range tables {
+		name := goIdent(t.Name)
+		if t.Comment != "" {
+			fmt.Fprintf(&body, "// %s %s\n", name, t.Comment)
+		} else {
+			fmt.Fprintf(&body, "// %s is a row of the %s.%s table.\n", name, t.Schema, t.Name)
+		}
+		fmt.Fprintf(&body, "type %s struct {\n", name)
+		for _, col := range t.Columns {
+			goType := goColumnType(col, imports)
+			if col.IsNullable && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") {
+				goType = "*" + goType
+			}
+			if col.Comment != "" {
+				fmt.Fprintf(&body, "\t// %s\n", col.Comment)
+			}
+			fmt.Fprintf(&body, "\t%s %s `json:%q db:%q`\n", goIdent(col.Name), goType, col.Name, col.Name)
+		}
+		body.WriteString("}\n\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by ayb types go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if imports["time"] {
+		b.WriteString("import \"time\"\n\n")
+	}
+	b.WriteString(body.String())
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("formatting generated Go: %w", err)
+	}
+	return string(src), nil
+}
+
+// goColumnType maps a column to a Go type (without nullability), recording
+// any packages that must be imported.
+func goColumnType(col *schema.Column, imports map[string]bool) string {
+	if col.IsEnum && len(col.EnumValues) > 0 {
+		return goIdent(pascalCase(col.TypeName))
+	}
+	switch col.JSONType {
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "object":
+		return "map[string]any"
+	case "array":
+		return "[]any"
+	}
+	if strings.HasPrefix(strings.ToLower(col.TypeName), "timestamp") {
+		imports["time"] = true
+		return "time.Time"
+	}
+	return "string"
+}
+
+// goInitialisms are name segments rendered in all caps, per Go naming conventions.
+var goInitialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+// goIdent converts a database identifier to an exported Go identifier,
+// splitting on any non-alphanumeric rune and upper-casing common initialisms
+// (user_id → UserID).
+func goIdent(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		if goInitialisms[strings.ToLower(p)] {
+			b.WriteString(strings.ToUpper(p))
+			continue
+		}
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	out := b.String()
+	if out == "" || unicode.IsDigit([]rune(out)[0]) {
+		out = "X" + out
+	}
+	return out
+}
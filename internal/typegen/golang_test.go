@@ -0,0 +1,69 @@
+package typegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func TestGoStructs(t *testing.T) {
+	t.Parallel()
+	out, err := Go(langTestCache(), "")
+	testutil.NoError(t, err)
+
+	testutil.Contains(t, out, "// Code generated by ayb types go. DO NOT EDIT.")
+	testutil.Contains(t, out, "package models")
+	testutil.Contains(t, out, `import "time"`)
+	testutil.Contains(t, out, "type Orders struct {")
+	testutil.Contains(t, out, "ID        string         `json:\"id\" db:\"id\"`")
+	testutil.Contains(t, out, "UserID    int64          `json:\"user_id\" db:\"user_id\"`")
+	testutil.Contains(t, out, "Total     float64")
+	testutil.Contains(t, out, "Paid      bool")
+	testutil.Contains(t, out, "Note      *string")
+	testutil.Contains(t, out, "Meta      map[string]any")
+	testutil.Contains(t, out, "Tags      []any")
+	testutil.Contains(t, out, "CreatedAt time.Time")
+	testutil.Contains(t, out, "ShipOn    *string")
+	testutil.Contains(t, out, "Status    OrderStatus")
+	testutil.False(t, strings.Contains(out, "AybUsers"), "system tables should be excluded")
+}
+
+func TestGoEnums(t *testing.T) {
+	t.Parallel()
+	out, err := Go(langTestCache(), "db")
+	testutil.NoError(t, err)
+
+	testutil.Contains(t, out, "package db")
+	testutil.Contains(t, out, "type OrderStatus string")
+	testutil.Contains(t, out, `OrderStatusOpen    OrderStatus = "open"`)
+	testutil.Contains(t, out, `OrderStatusShipped OrderStatus = "shipped"`)
+}
+
+func TestGoNoTimeImportWhenUnused(t *testing.T) {
+	t.Parallel()
+	sc := newCache(map[string]*schema.Table{
+		"public.tags": {Schema: "public", Name: "tags", Kind: "table", Columns: []*schema.Column{
+			{Name: "name", Position: 1, TypeName: "text", JSONType: "string"},
+		}},
+	})
+	out, err := Go(sc, "models")
+	testutil.NoError(t, err)
+	testutil.False(t, strings.Contains(out, `import "time"`), "time should only be imported when used")
+}
+
+func TestGoIdent(t *testing.T) {
+	t.Parallel()
+	tests := []struct{ in, want string }{
+		{"user_id", "UserID"},
+		{"avatar_url", "AvatarURL"},
+		{"blog_posts", "BlogPosts"},
+		{"in-progress", "InProgress"},
+		{"2fa", "X2fa"},
+		{"", "X"},
+	}
+	for _, tt := range tests {
+		testutil.Equal(t, tt.want, goIdent(tt.in))
+	}
+}
@@ -0,0 +1,138 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// PythonOptions controls the style of the generated Python models.
+type PythonOptions struct {
+	// Pydantic emits pydantic BaseModel classes (with datetime, date, and UUID
+	// fields parsed into Python objects) instead of TypedDicts.
+	Pydantic bool
+}
+
+// Python generates Python type definitions from a schema cache: a Literal
+// alias per PostgreSQL enum and a TypedDict (or pydantic model) per table.
+// System tables (prefixed _ayb_) are excluded.
+func Python(sc *schema.SchemaCache, opts PythonOptions) string {
+	tables := userTables(sc)
+	enums := collectEnums(tables)
+
+	var body strings.Builder
+	imports := map[string]bool{}
+
+	for _, e := range enums {
+		quoted := make([]string, len(e.values))
+		for i, v := range e.values {
+			quoted[i] = fmt.Sprintf("%q", v)
+		}
+		imports["Literal"] = true
+		fmt.Fprintf(&body, "%s = Literal[%s]\n\n\n", e.name, strings.Join(quoted, ", "))
+	}
+
+	for _, t := range tables {
+		name := pascalCase(t.Name)
+		if opts.Pydantic {
+			fmt.Fprintf(&body, "class %s(BaseModel):\n", name)
+		} else {
+			fmt.Fprintf(&body, "class %s(TypedDict):\n", name)
+		}
+		if t.Comment != "" {
+			fmt.Fprintf(&body, "    %q\n\n", t.Comment)
+		}
+		if len(t.Columns) == 0 {
+			body.WriteString("    pass\n")
+		}
+		for _, col := range t.Columns {
+			pyType := pythonType(col, opts.Pydantic, imports)
+			if col.IsNullable {
+				imports["Optional"] = true
+				pyType = "Optional[" + pyType + "]"
+			}
+			if col.Comment != "" {
+				fmt.Fprintf(&body, "    # %s\n", col.Comment)
+			}
+			fmt.Fprintf(&body, "    %s: %s\n", col.Name, pyType)
+		}
+		body.WriteString("\n\n")
+	}
+
+	var b strings.Builder
+	b.WriteString("# Auto-generated by ayb types python — DO NOT EDIT\n\n")
+	b.WriteString("from __future__ import annotations\n\n")
+	if imports["datetime"] || imports["date"] {
+		var names []string
+		if imports["date"] {
+			names = append(names, "date")
+		}
+		if imports["datetime"] {
+			names = append(names, "datetime")
+		}
+		fmt.Fprintf(&b, "from datetime import %s\n", strings.Join(names, ", "))
+	}
+	typingNames := []string{}
+	for _, n := range []string{"Any", "Literal", "Optional"} {
+		if imports[n] {
+			typingNames = append(typingNames, n)
+		}
+	}
+	if !opts.Pydantic {
+		typingNames = append(typingNames, "TypedDict")
+	}
+	fmt.Fprintf(&b, "from typing import %s\n", strings.Join(typingNames, ", "))
+	if imports["UUID"] {
+		b.WriteString("from uuid import UUID\n")
+	}
+	if opts.Pydantic {
+		b.WriteString("\nfrom pydantic import BaseModel\n")
+	}
+	b.WriteString("\n\n")
+	b.WriteString(strings.TrimRight(body.String(), "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// pythonType maps a column to a Python type annotation (without nullability),
+// recording any names that must be imported.
+func pythonType(col *schema.Column, pydantic bool, imports map[string]bool) string {
+	if col.IsEnum && len(col.EnumValues) > 0 {
+		return pascalCase(col.TypeName)
+	}
+	switch col.JSONType {
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "object":
+		imports["Any"] = true
+		return "dict[str, Any]"
+	case "array":
+		imports["Any"] = true
+		return "list[Any]"
+	}
+
+	// TypedDicts describe the raw JSON, so temporal and UUID values stay
+	// strings; pydantic parses them into richer types.
+	if !pydantic {
+		return "str"
+	}
+	typeName := strings.ToLower(col.TypeName)
+	switch {
+	case strings.HasPrefix(typeName, "timestamp"):
+		imports["datetime"] = true
+		return "datetime"
+	case typeName == "date":
+		imports["date"] = true
+		return "date"
+	case typeName == "uuid":
+		imports["UUID"] = true
+		return "UUID"
+	default:
+		return "str"
+	}
+}
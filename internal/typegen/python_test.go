@@ -0,0 +1,76 @@
+package typegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func langTestCache() *schema.SchemaCache {
+	return newCache(map[string]*schema.Table{
+		"public.orders": {
+			Schema: "public", Name: "orders", Kind: "table",
+			Columns: []*schema.Column{
+				{Name: "id", Position: 1, TypeName: "uuid", JSONType: "string", IsPrimaryKey: true},
+				{Name: "user_id", Position: 2, TypeName: "bigint", JSONType: "integer"},
+				{Name: "total", Position: 3, TypeName: "numeric", JSONType: "number"},
+				{Name: "paid", Position: 4, TypeName: "boolean", JSONType: "boolean"},
+				{Name: "note", Position: 5, TypeName: "text", JSONType: "string", IsNullable: true},
+				{Name: "meta", Position: 6, TypeName: "jsonb", JSONType: "object", IsNullable: true},
+				{Name: "tags", Position: 7, TypeName: "text[]", JSONType: "array", IsArray: true},
+				{Name: "created_at", Position: 8, TypeName: "timestamp with time zone", JSONType: "string"},
+				{Name: "ship_on", Position: 9, TypeName: "date", JSONType: "string", IsNullable: true},
+				{Name: "status", Position: 10, TypeName: "order_status", JSONType: "string", IsEnum: true, EnumValues: []string{"open", "shipped"}},
+			},
+			PrimaryKey: []string{"id"},
+		},
+		"public._ayb_users": {
+			Schema: "public", Name: "_ayb_users", Kind: "table",
+			Columns: []*schema.Column{{Name: "id", Position: 1, JSONType: "string"}},
+		},
+	})
+}
+
+func TestPythonTypedDict(t *testing.T) {
+	t.Parallel()
+	out := Python(langTestCache(), PythonOptions{})
+
+	testutil.Contains(t, out, "DO NOT EDIT")
+	testutil.Contains(t, out, "from typing import Any, Literal, Optional, TypedDict")
+	testutil.Contains(t, out, `OrderStatus = Literal["open", "shipped"]`)
+	testutil.Contains(t, out, "class Orders(TypedDict):")
+	testutil.Contains(t, out, "    id: str\n")
+	testutil.Contains(t, out, "    user_id: int\n")
+	testutil.Contains(t, out, "    total: float\n")
+	testutil.Contains(t, out, "    paid: bool\n")
+	testutil.Contains(t, out, "    note: Optional[str]\n")
+	testutil.Contains(t, out, "    meta: Optional[dict[str, Any]]\n")
+	testutil.Contains(t, out, "    tags: list[Any]\n")
+	testutil.Contains(t, out, "    created_at: str\n")
+	testutil.Contains(t, out, "    status: OrderStatus\n")
+	testutil.False(t, strings.Contains(out, "pydantic"), "TypedDict output should not import pydantic")
+	testutil.False(t, strings.Contains(out, "AybUsers"), "system tables should be excluded")
+}
+
+func TestPythonPydantic(t *testing.T) {
+	t.Parallel()
+	out := Python(langTestCache(), PythonOptions{Pydantic: true})
+
+	testutil.Contains(t, out, "from datetime import date, datetime")
+	testutil.Contains(t, out, "from uuid import UUID")
+	testutil.Contains(t, out, "from pydantic import BaseModel")
+	testutil.False(t, strings.Contains(out, "TypedDict"), "pydantic output should not use TypedDict")
+	testutil.Contains(t, out, "class Orders(BaseModel):")
+	testutil.Contains(t, out, "    id: UUID\n")
+	testutil.Contains(t, out, "    created_at: datetime\n")
+	testutil.Contains(t, out, "    ship_on: Optional[date]\n")
+}
+
+func TestPythonEmptySchema(t *testing.T) {
+	t.Parallel()
+	out := Python(newCache(map[string]*schema.Table{}), PythonOptions{})
+	testutil.Contains(t, out, "from typing import TypedDict")
+	testutil.False(t, strings.Contains(out, "class "), "empty schema should have no classes")
+}
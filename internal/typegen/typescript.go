@@ -9,62 +9,108 @@ import (
 	"github.com/allyourbase/ayb/internal/schema"
 )
 
+// TypeScriptOptions controls optional sections of the generated TypeScript output.
+type TypeScriptOptions struct {
+	// Zod additionally emits Zod validation schemas for every enum and table,
+	// plus insert types inferred from them. The output then imports "zod" and
+	// must be saved as a .ts file rather than a .d.ts declaration file.
+	Zod bool
+	// Client additionally emits a fetch-based AybClient with one typed
+	// collection accessor (list/get/create/update/delete) per table. Like Zod,
+	// this produces runtime code and must be saved as a .ts file.
+	Client bool
+}
+
 // TypeScript generates TypeScript interface declarations from a schema cache.
 // The output is a self-contained .d.ts file with no external dependencies.
 // System tables (prefixed _ayb_) are excluded.
 func TypeScript(sc *schema.SchemaCache) string {
+	return TypeScriptWithOptions(sc, TypeScriptOptions{})
+}
+
+// TypeScriptWithOptions generates TypeScript declarations from a schema cache,
+// including the optional sections selected in opts. With zero options the
+// output is identical to TypeScript.
+func TypeScriptWithOptions(sc *schema.SchemaCache, opts TypeScriptOptions) string {
 	var b strings.Builder
 	b.WriteString("// Auto-generated by ayb types typescript — DO NOT EDIT\n\n")
+	if opts.Zod {
+		b.WriteString("import { z } from \"zod\";\n\n")
+	}
+
+	tables := userTables(sc)
+	enums := collectEnums(tables)
+
+	// Emit enum types first (sorted for determinism).
+	for _, e := range enums {
+		quoted := make([]string, len(e.values))
+		for i, v := range e.values {
+			quoted[i] = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, "export type %s = %s;\n\n", e.name, strings.Join(quoted, " | "))
+	}
+
+	// Emit interfaces for each table.
+	for _, t := range tables {
+		writeTableInterface(&b, t)
+	}
+
+	if opts.Zod {
+		writeZodSchemas(&b, tables, enums)
+	}
+	if opts.Client {
+		writeClient(&b, tables)
+	}
+
+	return b.String()
+}
+
+// tsEnum is a PostgreSQL enum type as emitted in TypeScript.
+type tsEnum struct {
+	name   string
+	values []string
+}
 
-	// Collect and sort table keys for deterministic output.
+// userTables returns non-system tables sorted by "schema.table" key.
+func userTables(sc *schema.SchemaCache) []*schema.Table {
 	keys := make([]string, 0, len(sc.Tables))
 	for k := range sc.Tables {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
-	// Collect enums across all tables (deduplicated by name).
-	enumsSeen := map[string][]string{}
+	tables := make([]*schema.Table, 0, len(keys))
 	for _, k := range keys {
 		t := sc.Tables[k]
 		if isSystemTable(t.Name) {
 			continue
 		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// collectEnums returns the enum types used by the given tables, deduplicated
+// by generated name and sorted for deterministic output.
+func collectEnums(tables []*schema.Table) []tsEnum {
+	seen := map[string][]string{}
+	for _, t := range tables {
 		for _, col := range t.Columns {
 			if col.IsEnum && len(col.EnumValues) > 0 {
 				enumName := pascalCase(col.TypeName)
-				if _, ok := enumsSeen[enumName]; !ok {
-					enumsSeen[enumName] = col.EnumValues
+				if _, ok := seen[enumName]; !ok {
+					seen[enumName] = col.EnumValues
 				}
 			}
 		}
 	}
 
-	// Emit enum types first (sorted for determinism).
-	enumNames := make([]string, 0, len(enumsSeen))
-	for name := range enumsSeen {
-		enumNames = append(enumNames, name)
-	}
-	sort.Strings(enumNames)
-	for _, name := range enumNames {
-		values := enumsSeen[name]
-		quoted := make([]string, len(values))
-		for i, v := range values {
-			quoted[i] = fmt.Sprintf("%q", v)
-		}
-		fmt.Fprintf(&b, "export type %s = %s;\n\n", name, strings.Join(quoted, " | "))
-	}
-
-	// Emit interfaces for each table.
-	for _, k := range keys {
-		t := sc.Tables[k]
-		if isSystemTable(t.Name) {
-			continue
-		}
-		writeTableInterface(&b, t)
+	enums := make([]tsEnum, 0, len(seen))
+	for name, values := range seen {
+		enums = append(enums, tsEnum{name: name, values: values})
 	}
-
-	return b.String()
+	sort.Slice(enums, func(i, j int) bool { return enums[i].name < enums[j].name })
+	return enums
 }
 
 func writeTableInterface(b *strings.Builder, t *schema.Table) {
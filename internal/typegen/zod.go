@@ -0,0 +1,84 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allyourbase/ayb/internal/schema"
+)
+
+// writeZodSchemas emits a Zod enum for each PostgreSQL enum, then a row schema
+// and an insert schema per table. Insert schemas mark columns the database can
+// fill in (primary keys, defaults, nullable) as optional, and the matching
+// <Table>Insert type is inferred from it.
+func writeZodSchemas(b *strings.Builder, tables []*schema.Table, enums []tsEnum) {
+	b.WriteString("// Zod validation schemas\n\n")
+
+	for _, e := range enums {
+		quoted := make([]string, len(e.values))
+		for i, v := range e.values {
+			quoted[i] = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(b, "export const %sSchema = z.enum([%s]);\n\n", e.name, strings.Join(quoted, ", "))
+	}
+
+	for _, t := range tables {
+		name := pascalCase(t.Name)
+
+		fmt.Fprintf(b, "export const %sSchema = z.object({\n", name)
+		for _, col := range t.Columns {
+			zt := zodType(col)
+			if col.IsNullable {
+				zt += ".nullable()"
+			}
+			fmt.Fprintf(b, "  %s: %s,\n", col.Name, zt)
+		}
+		fmt.Fprintf(b, "});\n\n")
+
+		fmt.Fprintf(b, "export const %sInsertSchema = z.object({\n", name)
+		for _, col := range t.Columns {
+			zt := zodType(col)
+			if col.IsNullable {
+				zt += ".nullable()"
+			}
+			if col.OptionalOnInsert() {
+				zt += ".optional()"
+			}
+			fmt.Fprintf(b, "  %s: %s,\n", col.Name, zt)
+		}
+		fmt.Fprintf(b, "});\n\n")
+
+		fmt.Fprintf(b, "export type %sInsert = z.infer<typeof %sInsertSchema>;\n\n", name, name)
+	}
+}
+
+// zodType maps a column to a Zod schema expression (without nullability).
+func zodType(col *schema.Column) string {
+	if col.IsEnum && len(col.EnumValues) > 0 {
+		return pascalCase(col.TypeName) + "Schema"
+	}
+	switch col.JSONType {
+	case "integer":
+		return "z.number().int()"
+	case "number":
+		return "z.number()"
+	case "boolean":
+		return "z.boolean()"
+	case "object":
+		return "z.record(z.string(), z.unknown())"
+	case "array":
+		return "z.array(z.unknown())"
+	}
+
+	typeName := strings.ToLower(col.TypeName)
+	switch {
+	case strings.HasPrefix(typeName, "timestamp"):
+		return "z.string().datetime({ offset: true })"
+	case typeName == "date":
+		return "z.string().date()"
+	case typeName == "uuid":
+		return "z.string().uuid()"
+	default:
+		return "z.string()"
+	}
+}
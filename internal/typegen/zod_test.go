@@ -0,0 +1,82 @@
+package typegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/allyourbase/ayb/internal/schema"
+	"github.com/allyourbase/ayb/internal/testutil"
+)
+
+func zodTestCache() *schema.SchemaCache {
+	return newCache(map[string]*schema.Table{
+		"public.events": {
+			Schema: "public", Name: "events", Kind: "table",
+			Columns: []*schema.Column{
+				{Name: "id", Position: 1, TypeName: "uuid", JSONType: "string", IsPrimaryKey: true, DefaultExpr: "gen_random_uuid()"},
+				{Name: "title", Position: 2, TypeName: "text", JSONType: "string"},
+				{Name: "seats", Position: 3, TypeName: "integer", JSONType: "integer"},
+				{Name: "price", Position: 4, TypeName: "numeric(10,2)", JSONType: "number", IsNullable: true},
+				{Name: "public", Position: 5, TypeName: "boolean", JSONType: "boolean", DefaultExpr: "true"},
+				{Name: "day", Position: 6, TypeName: "date", JSONType: "string"},
+				{Name: "starts_at", Position: 7, TypeName: "timestamp with time zone", JSONType: "string"},
+				{Name: "meta", Position: 8, TypeName: "jsonb", JSONType: "object", IsNullable: true},
+				{Name: "tags", Position: 9, TypeName: "text[]", JSONType: "array", IsArray: true},
+				{Name: "status", Position: 10, TypeName: "event_status", JSONType: "string", IsEnum: true, EnumValues: []string{"draft", "live"}},
+			},
+			PrimaryKey: []string{"id"},
+		},
+	})
+}
+
+func TestTypeScriptWithoutZodUnchanged(t *testing.T) {
+	t.Parallel()
+	sc := zodTestCache()
+
+	testutil.Equal(t, TypeScript(sc), TypeScriptWithOptions(sc, TypeScriptOptions{}))
+	out := TypeScript(sc)
+	testutil.False(t, strings.Contains(out, "zod"), "default output should not reference zod")
+}
+
+func TestTypeScriptZodImportAndEnum(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(zodTestCache(), TypeScriptOptions{Zod: true})
+
+	testutil.Contains(t, out, `import { z } from "zod";`)
+	testutil.Contains(t, out, `export type EventStatus = "draft" | "live";`)
+	testutil.Contains(t, out, `export const EventStatusSchema = z.enum(["draft", "live"]);`)
+	// Interfaces are still emitted alongside the schemas.
+	testutil.Contains(t, out, "export interface Events {")
+}
+
+func TestTypeScriptZodRowSchema(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(zodTestCache(), TypeScriptOptions{Zod: true})
+
+	testutil.Contains(t, out, "export const EventsSchema = z.object({")
+	testutil.Contains(t, out, "  id: z.string().uuid(),")
+	testutil.Contains(t, out, "  title: z.string(),")
+	testutil.Contains(t, out, "  seats: z.number().int(),")
+	testutil.Contains(t, out, "  price: z.number().nullable(),")
+	testutil.Contains(t, out, "  public: z.boolean(),")
+	testutil.Contains(t, out, "  day: z.string().date(),")
+	testutil.Contains(t, out, "  starts_at: z.string().datetime({ offset: true }),")
+	testutil.Contains(t, out, "  meta: z.record(z.string(), z.unknown()).nullable(),")
+	testutil.Contains(t, out, "  tags: z.array(z.unknown()),")
+	testutil.Contains(t, out, "  status: EventStatusSchema,")
+}
+
+func TestTypeScriptZodInsertSchema(t *testing.T) {
+	t.Parallel()
+	out := TypeScriptWithOptions(zodTestCache(), TypeScriptOptions{Zod: true})
+
+	idx := strings.Index(out, "export const EventsInsertSchema = z.object({")
+	testutil.True(t, idx >= 0, "missing insert schema")
+	insert := out[idx:]
+
+	testutil.Contains(t, insert, "  id: z.string().uuid().optional(),")
+	testutil.Contains(t, insert, "  title: z.string(),")
+	testutil.Contains(t, insert, "  price: z.number().nullable().optional(),")
+	testutil.Contains(t, insert, "  public: z.boolean().optional(),")
+	testutil.Contains(t, out, "export type EventsInsert = z.infer<typeof EventsInsertSchema>;")
+}
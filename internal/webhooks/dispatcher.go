@@ -28,10 +28,18 @@ var defaultBackoff = [maxRetries]time.Duration{
 	25 * time.Second,
 }
 
+// RecordFilter compiles and evaluates collection filter expressions against
+// event records, for webhooks with a filter.
+type RecordFilter interface {
+	ValidateFilter(table, filter string) error
+	MatchFilter(ctx context.Context, table, filter string, record map[string]any) (bool, error)
+}
+
 // Dispatcher receives realtime events and delivers them to matching webhooks.
 type Dispatcher struct {
 	store     WebhookLister
 	deliveryS DeliveryStore // optional — nil disables delivery logging
+	filter    RecordFilter  // optional — nil skips webhooks that have a filter
 	client    *http.Client
 	logger    *slog.Logger
 	queue     chan *realtime.Event
@@ -60,6 +68,11 @@ func (d *Dispatcher) SetDeliveryStore(ds DeliveryStore) {
 	d.deliveryS = ds
 }
 
+// SetRecordFilter enables webhook filters.
+func (d *Dispatcher) SetRecordFilter(f RecordFilter) {
+	d.filter = f
+}
+
 // Enqueue adds an event to the delivery queue.
 // Non-blocking: drops events if the queue is full.
 func (d *Dispatcher) Enqueue(event *realtime.Event) {
@@ -106,11 +119,57 @@ func (d *Dispatcher) processEvent(event *realtime.Event) {
 	}
 
 	for i := range hooks {
-		if !matches(&hooks[i], event) {
+		hook := &hooks[i]
+		if !matches(hook, event) || !d.matchesFilter(hook, event) {
+			continue
+		}
+		hookPayload := payload
+		if len(hook.IncludeColumns) > 0 || len(hook.ExcludeColumns) > 0 {
+			hookPayload, err = json.Marshal(projectColumns(hook, event))
+			if err != nil {
+				d.logger.Error("failed to marshal webhook payload", "error", err, "webhookID", hook.ID)
+				continue
+			}
+		}
+		d.deliver(hook, event, hookPayload)
+	}
+}
+
+// matchesFilter reports whether the event's record matches hook's filter.
+// A filter that can't be evaluated, for example because a column it names
+// was dropped, doesn't match, so a filtered webhook never receives rows it
+// wasn't meant to.
+func (d *Dispatcher) matchesFilter(hook *Webhook, event *realtime.Event) bool {
+	if hook.Filter == "" {
+		return true
+	}
+	if d.filter == nil {
+		d.logger.Warn("webhook filter not evaluated, skipping delivery", "webhookID", hook.ID)
+		return false
+	}
+	ok, err := d.filter.MatchFilter(context.Background(), event.Table, hook.Filter, event.Record)
+	if err != nil {
+		d.logger.Error("webhook filter failed, skipping delivery",
+			"webhookID", hook.ID, "table", event.Table, "error", err)
+		return false
+	}
+	return ok
+}
+
+// projectColumns returns a copy of event with the record limited to hook's
+// include list, or without its exclude list.
+func projectColumns(hook *Webhook, event *realtime.Event) *realtime.Event {
+	record := make(map[string]any, len(event.Record))
+	for col, v := range event.Record {
+		if len(hook.IncludeColumns) > 0 && !contains(hook.IncludeColumns, col) {
+			continue
+		}
+		if contains(hook.ExcludeColumns, col) {
 			continue
 		}
-		d.deliver(&hooks[i], event, payload)
+		record[col] = v
 	}
+	return &realtime.Event{Action: event.Action, Table: event.Table, Record: record}
 }
 
 func matches(hook *Webhook, event *realtime.Event) bool {
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -169,6 +170,110 @@ func TestEventFilteringByTable(t *testing.T) {
 	testutil.Equal(t, int32(1), received.Load())
 }
 
+// fakeRecordFilter matches filters of the form column=value by string
+// comparison against the record.
+type fakeRecordFilter struct {
+	err error
+}
+
+func (f *fakeRecordFilter) ValidateFilter(_, filter string) error {
+	if !strings.Contains(filter, "=") {
+		return fmt.Errorf("expected column=value")
+	}
+	return f.err
+}
+
+func (f *fakeRecordFilter) MatchFilter(_ context.Context, _, filter string, record map[string]any) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	col, value, _ := strings.Cut(filter, "=")
+	return fmt.Sprint(record[col]) == value, nil
+}
+
+func TestFilteredWebhookSkipsNonMatchingRows(t *testing.T) {
+	t.Parallel()
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	lister := &mockLister{hooks: []Webhook{{
+		ID: "wh1", URL: srv.URL, Tables: []string{"posts"}, Filter: "status=published", Enabled: true,
+	}}}
+	d := testDispatcher(lister)
+	d.SetRecordFilter(&fakeRecordFilter{})
+
+	d.processEvent(&realtime.Event{Action: "update", Table: "posts", Record: map[string]any{"status": "draft"}})
+	testutil.Equal(t, int32(0), received.Load())
+
+	d.processEvent(&realtime.Event{Action: "update", Table: "posts", Record: map[string]any{"status": "published"}})
+	testutil.Equal(t, int32(1), received.Load())
+}
+
+func TestFilteredWebhookSkippedWhenFilterFails(t *testing.T) {
+	t.Parallel()
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	lister := &mockLister{hooks: []Webhook{{
+		ID: "wh1", URL: srv.URL, Tables: []string{"posts"}, Filter: "status=published", Enabled: true,
+	}}}
+	event := &realtime.Event{Action: "create", Table: "posts", Record: map[string]any{"status": "published"}}
+
+	// No filter evaluator configured.
+	d := testDispatcher(lister)
+	d.processEvent(event)
+	testutil.Equal(t, int32(0), received.Load())
+
+	// The filter can't be evaluated, e.g. its column was dropped.
+	d.SetRecordFilter(&fakeRecordFilter{err: fmt.Errorf("unknown column: status")})
+	d.processEvent(event)
+	testutil.Equal(t, int32(0), received.Load())
+}
+
+func TestWebhookColumnProjection(t *testing.T) {
+	t.Parallel()
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	event := &realtime.Event{Action: "create", Table: "users", Record: map[string]any{
+		"id": float64(1), "email": "a@example.com", "bio": "long text",
+	}}
+	recordOf := func(hook Webhook) map[string]any {
+		t.Helper()
+		hook.URL = srv.URL
+		hook.Enabled = true
+		testDispatcher(&mockLister{hooks: []Webhook{hook}}).processEvent(event)
+		var got realtime.Event
+		testutil.NoError(t, json.Unmarshal(<-bodies, &got))
+		return got.Record
+	}
+
+	included := recordOf(Webhook{ID: "wh1", IncludeColumns: []string{"id", "email"}})
+	testutil.MapLen(t, included, 2)
+	testutil.Equal(t, "a@example.com", included["email"].(string))
+
+	excluded := recordOf(Webhook{ID: "wh2", ExcludeColumns: []string{"email"}})
+	testutil.MapLen(t, excluded, 2)
+	_, hasEmail := excluded["email"]
+	testutil.False(t, hasEmail, "excluded column should not be delivered")
+
+	// The event shared with other webhooks is left intact.
+	testutil.MapLen(t, event.Record, 3)
+}
+
 func TestEventFilteringByAction(t *testing.T) {
 	t.Parallel()
 	var received atomic.Int32
@@ -9,6 +9,7 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/allyourbase/ayb/internal/httputil"
@@ -19,26 +20,32 @@ import (
 
 // webhookResponse is the API response shape — never exposes secret.
 type webhookResponse struct {
-	ID        string   `json:"id"`
-	URL       string   `json:"url"`
-	HasSecret bool     `json:"hasSecret"`
-	Events    []string `json:"events"`
-	Tables    []string `json:"tables"`
-	Enabled   bool     `json:"enabled"`
-	CreatedAt string   `json:"createdAt"`
-	UpdatedAt string   `json:"updatedAt"`
+	ID             string   `json:"id"`
+	URL            string   `json:"url"`
+	HasSecret      bool     `json:"hasSecret"`
+	Events         []string `json:"events"`
+	Tables         []string `json:"tables"`
+	Enabled        bool     `json:"enabled"`
+	IncludeColumns []string `json:"includeColumns"`
+	ExcludeColumns []string `json:"excludeColumns"`
+	Filter         string   `json:"filter"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
 }
 
 func toResponse(w *Webhook) webhookResponse {
 	return webhookResponse{
-		ID:        w.ID,
-		URL:       w.URL,
-		HasSecret: w.Secret != "",
-		Events:    w.Events,
-		Tables:    w.Tables,
-		Enabled:   w.Enabled,
-		CreatedAt: w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: w.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:             w.ID,
+		URL:            w.URL,
+		HasSecret:      w.Secret != "",
+		Events:         w.Events,
+		Tables:         w.Tables,
+		Enabled:        w.Enabled,
+		IncludeColumns: nonNil(w.IncludeColumns),
+		ExcludeColumns: nonNil(w.ExcludeColumns),
+		Filter:         w.Filter,
+		CreatedAt:      w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      w.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
@@ -46,6 +53,7 @@ func toResponse(w *Webhook) webhookResponse {
 type Handler struct {
 	store     WebhookStore
 	deliveryS DeliveryStore
+	filter    RecordFilter // nil rejects webhooks with a filter
 	logger    *slog.Logger
 }
 
@@ -54,6 +62,12 @@ func NewHandler(store WebhookStore, deliveryStore DeliveryStore, logger *slog.Lo
 	return &Handler{store: store, deliveryS: deliveryStore, logger: logger}
 }
 
+// SetRecordFilter enables webhook filters, validating them against the
+// webhook's tables on create and update.
+func (h *Handler) SetRecordFilter(f RecordFilter) {
+	h.filter = f
+}
+
 // Routes returns a chi.Router with webhook CRUD endpoints.
 func (h *Handler) Routes() chi.Router {
 	r := chi.NewRouter()
@@ -97,11 +111,14 @@ func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
 }
 
 type webhookRequest struct {
-	URL     string   `json:"url"`
-	Secret  string   `json:"secret"`
-	Events  []string `json:"events"`
-	Tables  []string `json:"tables"`
-	Enabled *bool    `json:"enabled"`
+	URL            string   `json:"url"`
+	Secret         string   `json:"secret"`
+	Events         []string `json:"events"`
+	Tables         []string `json:"tables"`
+	Enabled        *bool    `json:"enabled"`
+	IncludeColumns []string `json:"includeColumns"`
+	ExcludeColumns []string `json:"excludeColumns"`
+	Filter         *string  `json:"filter"`
 }
 
 var validEvents = map[string]bool{"create": true, "update": true, "delete": true}
@@ -118,6 +135,29 @@ func validateRequest(req *webhookRequest) string {
 	return ""
 }
 
+// validatePayloadOptions checks a webhook's column lists and filter. A
+// filter names columns, so it needs the tables it applies to.
+func (h *Handler) validatePayloadOptions(hook *Webhook) string {
+	if len(hook.IncludeColumns) > 0 && len(hook.ExcludeColumns) > 0 {
+		return "set includeColumns or excludeColumns, not both"
+	}
+	if hook.Filter == "" {
+		return ""
+	}
+	if len(hook.Tables) == 0 {
+		return "filter requires tables"
+	}
+	if h.filter == nil {
+		return "webhook filters are not available"
+	}
+	for _, table := range hook.Tables {
+		if err := h.filter.ValidateFilter(table, hook.Filter); err != nil {
+			return "invalid filter for table " + table + ": " + err.Error()
+		}
+	}
+	return ""
+}
+
 func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	var req webhookRequest
 	if !httputil.DecodeJSON(w, r, &req) {
@@ -143,11 +183,21 @@ func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	hook := &Webhook{
-		URL:     req.URL,
-		Secret:  req.Secret,
-		Events:  events,
-		Tables:  tables,
-		Enabled: enabled,
+		URL:            req.URL,
+		Secret:         req.Secret,
+		Events:         events,
+		Tables:         tables,
+		Enabled:        enabled,
+		IncludeColumns: nonNil(req.IncludeColumns),
+		ExcludeColumns: nonNil(req.ExcludeColumns),
+	}
+	if req.Filter != nil {
+		hook.Filter = strings.TrimSpace(*req.Filter)
+	}
+	if msg := h.validatePayloadOptions(hook); msg != "" {
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
+			"https://allyourbase.io/guide/api-reference")
+		return
 	}
 	if err := h.store.Create(r.Context(), hook); err != nil {
 		h.logger.Error("create webhook", "error", err)
@@ -200,6 +250,20 @@ func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if req.Enabled != nil {
 		existing.Enabled = *req.Enabled
 	}
+	if req.IncludeColumns != nil {
+		existing.IncludeColumns = req.IncludeColumns
+	}
+	if req.ExcludeColumns != nil {
+		existing.ExcludeColumns = req.ExcludeColumns
+	}
+	if req.Filter != nil {
+		existing.Filter = strings.TrimSpace(*req.Filter)
+	}
+	if msg := h.validatePayloadOptions(existing); msg != "" {
+		httputil.WriteErrorWithDocURL(w, http.StatusBadRequest, msg,
+			"https://allyourbase.io/guide/api-reference")
+		return
+	}
 
 	if err := h.store.Update(r.Context(), id, existing); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
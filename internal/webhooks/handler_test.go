@@ -180,6 +180,69 @@ func TestCreateSuccess(t *testing.T) {
 	testutil.Equal(t, true, resp["hasSecret"].(bool))
 }
 
+func TestCreateWithPayloadOptions(t *testing.T) {
+	t.Parallel()
+	h, _, _ := testHandler()
+	h.SetRecordFilter(&fakeRecordFilter{})
+	w := doHandlerRequest(t, h.Routes(), "POST", "/",
+		`{"url":"http://example.com/hook","tables":["posts"],"excludeColumns":["body"],"filter":" status=published "}`)
+	testutil.Equal(t, http.StatusCreated, w.Code)
+
+	var resp map[string]any
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	testutil.Equal(t, "status=published", resp["filter"].(string))
+	testutil.SliceLen(t, resp["excludeColumns"].([]any), 1)
+	testutil.SliceLen(t, resp["includeColumns"].([]any), 0)
+}
+
+func TestCreateInvalidPayloadOptions(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name, body, want string
+	}{
+		{"both column lists", `{"url":"http://example.com","includeColumns":["id"],"excludeColumns":["body"]}`, "not both"},
+		{"filter without tables", `{"url":"http://example.com","filter":"status=published"}`, "filter requires tables"},
+		{"invalid filter", `{"url":"http://example.com","tables":["posts"],"filter":"status"}`, "invalid filter for table posts"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _, _ := testHandler()
+			h.SetRecordFilter(&fakeRecordFilter{})
+			w := doHandlerRequest(t, h.Routes(), "POST", "/", tc.body)
+			testutil.Equal(t, http.StatusBadRequest, w.Code)
+			testutil.Contains(t, w.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestCreateFilterWithoutRecordFilter(t *testing.T) {
+	t.Parallel()
+	h, _, _ := testHandler()
+	w := doHandlerRequest(t, h.Routes(), "POST", "/",
+		`{"url":"http://example.com","tables":["posts"],"filter":"status=published"}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+	testutil.Contains(t, w.Body.String(), "webhook filters are not available")
+}
+
+func TestUpdateClearsFilter(t *testing.T) {
+	t.Parallel()
+	h, store, _ := testHandler()
+	h.SetRecordFilter(&fakeRecordFilter{})
+	w := doHandlerRequest(t, h.Routes(), "POST", "/",
+		`{"url":"http://example.com/hook","tables":["posts"],"filter":"status=published"}`)
+	testutil.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]any
+	testutil.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	id := created["id"].(string)
+
+	// Dropping the tables while keeping the filter is rejected.
+	w = doHandlerRequest(t, h.Routes(), "PATCH", "/"+id, `{"tables":[]}`)
+	testutil.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doHandlerRequest(t, h.Routes(), "PATCH", "/"+id, `{"tables":[],"filter":""}`)
+	testutil.Equal(t, http.StatusOK, w.Code)
+	testutil.Equal(t, "", store.hooks[id].Filter)
+}
+
 func TestGetNotFound(t *testing.T) {
 	t.Parallel()
 	h, _, _ := testHandler()
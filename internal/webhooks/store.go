@@ -10,12 +10,19 @@ import (
 
 // Webhook is a row from _ayb_webhooks.
 type Webhook struct {
-	ID        string    `json:"id"`
-	URL       string    `json:"url"`
-	Secret    string    `json:"-"`
-	Events    []string  `json:"events"`
-	Tables    []string  `json:"tables"`
-	Enabled   bool      `json:"enabled"`
+	ID      string   `json:"id"`
+	URL     string   `json:"url"`
+	Secret  string   `json:"-"`
+	Events  []string `json:"events"`
+	Tables  []string `json:"tables"`
+	Enabled bool     `json:"enabled"`
+	// IncludeColumns, when set, limits the delivered record to these
+	// columns; ExcludeColumns drops these columns instead.
+	IncludeColumns []string `json:"includeColumns"`
+	ExcludeColumns []string `json:"excludeColumns"`
+	// Filter is a collection filter expression the changed row must match
+	// for the webhook to fire. Empty matches every row.
+	Filter    string    `json:"filter"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -68,11 +75,12 @@ func NewStore(pool *pgxpool.Pool) *Store {
 	return &Store{pool: pool}
 }
 
-const columns = "id, url, secret, events, tables, enabled, created_at, updated_at"
+const columns = "id, url, secret, events, tables, enabled, include_columns, exclude_columns, filter, created_at, updated_at"
 
 func scanWebhook(row pgx.Row) (*Webhook, error) {
 	var w Webhook
-	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.Tables, &w.Enabled, &w.CreatedAt, &w.UpdatedAt)
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.Tables, &w.Enabled,
+		&w.IncludeColumns, &w.ExcludeColumns, &w.Filter, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -88,11 +96,11 @@ func (s *Store) List(ctx context.Context) ([]Webhook, error) {
 
 	var result []Webhook
 	for rows.Next() {
-		var w Webhook
-		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.Tables, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		w, err := scanWebhook(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, w)
+		result = append(result, *w)
 	}
 	if result == nil {
 		result = []Webhook{}
@@ -107,10 +115,10 @@ func (s *Store) Get(ctx context.Context, id string) (*Webhook, error) {
 
 func (s *Store) Create(ctx context.Context, w *Webhook) error {
 	row := s.pool.QueryRow(ctx,
-		`INSERT INTO _ayb_webhooks (url, secret, events, tables, enabled)
-		 VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO _ayb_webhooks (url, secret, events, tables, enabled, include_columns, exclude_columns, filter)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		 RETURNING id, created_at, updated_at`,
-		w.URL, w.Secret, w.Events, w.Tables, w.Enabled,
+		w.URL, w.Secret, w.Events, w.Tables, w.Enabled, nonNil(w.IncludeColumns), nonNil(w.ExcludeColumns), w.Filter,
 	)
 	return row.Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
 }
@@ -118,14 +126,24 @@ func (s *Store) Create(ctx context.Context, w *Webhook) error {
 func (s *Store) Update(ctx context.Context, id string, w *Webhook) error {
 	row := s.pool.QueryRow(ctx,
 		`UPDATE _ayb_webhooks
-		 SET url = $1, secret = $2, events = $3, tables = $4, enabled = $5, updated_at = NOW()
-		 WHERE id = $6
+		 SET url = $1, secret = $2, events = $3, tables = $4, enabled = $5,
+		     include_columns = $6, exclude_columns = $7, filter = $8, updated_at = NOW()
+		 WHERE id = $9
 		 RETURNING id, created_at, updated_at`,
-		w.URL, w.Secret, w.Events, w.Tables, w.Enabled, id,
+		w.URL, w.Secret, w.Events, w.Tables, w.Enabled, nonNil(w.IncludeColumns), nonNil(w.ExcludeColumns), w.Filter, id,
 	)
 	return row.Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
 }
 
+// nonNil returns ss, or an empty slice for nil so NOT NULL array columns
+// get '{}' rather than NULL.
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
 func (s *Store) Delete(ctx context.Context, id string) error {
 	tag, err := s.pool.Exec(ctx, "DELETE FROM _ayb_webhooks WHERE id = $1", id)
 	if err != nil {
@@ -146,11 +164,11 @@ func (s *Store) ListEnabled(ctx context.Context) ([]Webhook, error) {
 
 	var result []Webhook
 	for rows.Next() {
-		var w Webhook
-		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.Tables, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		w, err := scanWebhook(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, w)
+		result = append(result, *w)
 	}
 	return result, rows.Err()
 }
@@ -240,3 +240,37 @@ func TestStoreCreateDefaults(t *testing.T) {
 	testutil.Equal(t, "", got.Secret) // default empty
 	testutil.True(t, got.Enabled)
 }
+
+func TestStorePayloadOptions(t *testing.T) {
+	ctx := context.Background()
+	resetAndMigrate(t, ctx)
+
+	store := webhooks.NewStore(sharedPG.Pool)
+	w := &webhooks.Webhook{
+		URL:            "https://example.com/filtered",
+		Events:         []string{"create"},
+		Tables:         []string{"posts"},
+		Enabled:        true,
+		IncludeColumns: []string{"id", "title"},
+		Filter:         "status='published'",
+	}
+	testutil.NoError(t, store.Create(ctx, w))
+
+	got, err := store.Get(ctx, w.ID)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, got.IncludeColumns, 2)
+	testutil.SliceLen(t, got.ExcludeColumns, 0)
+	testutil.Equal(t, "status='published'", got.Filter)
+
+	got.IncludeColumns = nil
+	got.ExcludeColumns = []string{"body"}
+	got.Filter = ""
+	testutil.NoError(t, store.Update(ctx, w.ID, got))
+
+	enabled, err := store.ListEnabled(ctx)
+	testutil.NoError(t, err)
+	testutil.SliceLen(t, enabled, 1)
+	testutil.SliceLen(t, enabled[0].IncludeColumns, 0)
+	testutil.Equal(t, "body", enabled[0].ExcludeColumns[0])
+	testutil.Equal(t, "", enabled[0].Filter)
+}